@@ -18,15 +18,42 @@ type S3 struct {
 	SenboxFormSubmitBucket SenboxFormSubmitBucket `env-required:"true" yaml:"senbox-form-submit-bucket"`
 }
 
+// GoogleConfig holds the Google credentials, only needed with the google
+// spreadsheet driver, and the layout of the sheets every driver reads
 type GoogleConfig struct {
-	UserCredentialsFilePath     string   `env-required:"true" yaml:"user_credentials_file_path" env:"GOOGLE_CREDENTIALS_USER_FILE_PATH"`
-	UploaderCredentialsFilePath string   `env-required:"true" yaml:"uploader_credentials_file_path" env:"GOOGLE_CREDENTIALS_UPLOADER_FILE_PATH"`
-	Scopes                      []string `env-required:"true" yaml:"scopes" env:"GOOGLE_SCOPES"`
+	UserCredentialsFilePath     string   `yaml:"user_credentials_file_path" env:"GOOGLE_CREDENTIALS_USER_FILE_PATH"`
+	UploaderCredentialsFilePath string   `yaml:"uploader_credentials_file_path" env:"GOOGLE_CREDENTIALS_UPLOADER_FILE_PATH"`
+	Scopes                      []string `yaml:"scopes" env:"GOOGLE_SCOPES"`
 	SpreadsheetId               string   `env-required:"true" yaml:"spreadsheet_id" env:"GOOGLE_SPREADSHEET_ID"`
 	FirstColumn                 string   `env-required:"true" yaml:"first_column" env:"GOOGLE_FIRST_COLUMN"`
 	FirstRow                    int      `env-required:"true" yaml:"first_row" env:"GOOGLE_FIRST_ROW"`
 }
 
+type SpreadsheetConfig struct {
+	Driver         string `yaml:"driver" env:"SPREADSHEET_DRIVER" env-default:"google"`
+	LocalDirectory string `yaml:"local_directory" env:"SPREADSHEET_LOCAL_DIRECTORY" env-default:"./data/spreadsheets"`
+}
+
+// JWTConfig points at the keys tokens are signed with, see jwtkeys.Load.
+// SessionlessTokensUntil is an RFC 3339 time until which the tokens issued
+// before sessions existed are still accepted, empty rejects them.
+type JWTConfig struct {
+	KeysDirectory          string `yaml:"keys_directory" env:"JWT_KEYS_DIRECTORY" env-default:"keys/jwt"`
+	SigningKeyId           string `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	AcceptHS256            bool   `yaml:"accept_hs256" env:"JWT_ACCEPT_HS256" env-default:"true"`
+	SessionlessTokensUntil string `yaml:"sessionless_tokens_until" env:"JWT_SESSIONLESS_TOKENS_UNTIL"`
+}
+
+// DevicePresenceConfig holds the minutes after the last heartbeat of a device at
+// which it turns stale, offline and is alerted about, 0 alert minutes disables
+// the alert
+type DevicePresenceConfig struct {
+	StaleAfterMinutes      int `yaml:"stale_after_minutes" env:"DEVICE_STALE_AFTER_MINUTES" env-default:"5"`
+	OfflineAfterMinutes    int `yaml:"offline_after_minutes" env:"DEVICE_OFFLINE_AFTER_MINUTES" env-default:"15"`
+	AlertAfterMinutes      int `yaml:"alert_after_minutes" env:"DEVICE_ALERT_AFTER_MINUTES" env-default:"30"`
+	HeartbeatRetentionDays int `yaml:"heartbeat_retention_days" env:"DEVICE_HEARTBEAT_RETENTION_DAYS" env-default:"30"`
+}
+
 type SMTPConfig struct {
 	Host     string `env-required:"true" yaml:"host" env:"SMTP_HOST"`
 	Port     int    `env-required:"true" yaml:"port" env:"SMTP_PORT"`
@@ -39,15 +66,19 @@ type Messaging struct {
 }
 
 type AppConfig struct {
-	S3                              S3             `yaml:"s3"`
-	Config                          *common.Config `yaml:"config"`
-	Google                          *GoogleConfig  `yaml:"google_config"`
-	AuthorizeEncryptKey             string         `env-required:"true" yaml:"authorize_encrypt_key" env:"AUTHORIZE_ENCRYPT_KEY"`
-	TokenExpireDurationInHour       int            `env-required:"true" yaml:"token_expire_duration_in_hour" env:"TOKEN_EXPIRE_DURATION_IN_HOUR"`
-	DefaultRequestPageSize          int            `env-required:"true" yaml:"default_request_page_size" env:"DEFAULT_REQUEST_PAGE_SIZE"`
-	OutputSpreadsheetUrl            string         `env-required:"true" yaml:"output_spreadsheet_url" env:"OUTPUT_SPREADSHEET_URL"`
-	CronJobInterval                 string         `env-required:"true" yaml:"cron_job_interval" env:"CRON_JOB_INTERVAL"`
-	DefaultCronJobIntervalInMinutes uint8          `env-required:"true" yaml:"default_cron_job_interval_in_minutes" env:"DEFAULT_CRON_JOB_INTERVAL"`
-	SMTP                            SMTPConfig     `yaml:"smtp"`
-	Messaging                       Messaging      `yaml:"messaging"`
+	S3                               S3                   `yaml:"s3"`
+	Config                           *common.Config       `yaml:"config"`
+	Google                           *GoogleConfig        `yaml:"google_config"`
+	Spreadsheet                      SpreadsheetConfig    `yaml:"spreadsheet"`
+	AuthorizeEncryptKey              string               `env-required:"true" yaml:"authorize_encrypt_key" env:"AUTHORIZE_ENCRYPT_KEY"`
+	TokenExpireDurationInHour        int                  `env-required:"true" yaml:"token_expire_duration_in_hour" env:"TOKEN_EXPIRE_DURATION_IN_HOUR"`
+	RefreshTokenExpireDurationInHour int                  `yaml:"refresh_token_expire_duration_in_hour" env:"REFRESH_TOKEN_EXPIRE_DURATION_IN_HOUR" env-default:"720"`
+	JWT                              JWTConfig            `yaml:"jwt"`
+	DevicePresence                   DevicePresenceConfig `yaml:"device_presence"`
+	DefaultRequestPageSize           int                  `env-required:"true" yaml:"default_request_page_size" env:"DEFAULT_REQUEST_PAGE_SIZE"`
+	OutputSpreadsheetUrl             string               `env-required:"true" yaml:"output_spreadsheet_url" env:"OUTPUT_SPREADSHEET_URL"`
+	CronJobInterval                  string               `env-required:"true" yaml:"cron_job_interval" env:"CRON_JOB_INTERVAL"`
+	DefaultCronJobIntervalInMinutes  uint8                `env-required:"true" yaml:"default_cron_job_interval_in_minutes" env:"DEFAULT_CRON_JOB_INTERVAL"`
+	SMTP                             SMTPConfig           `yaml:"smtp"`
+	Messaging                        Messaging            `yaml:"messaging"`
 }
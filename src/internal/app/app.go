@@ -11,6 +11,7 @@ import (
 	"sen-global-api/docs"
 	"sen-global-api/internal/database"
 	"sen-global-api/internal/middleware"
+	"sen-global-api/internal/migrations"
 	"sen-global-api/internal/router"
 	"sen-global-api/pkg/common"
 	"sen-global-api/pkg/monitor"
@@ -58,6 +59,11 @@ func Run(appConfig *config.AppConfig, fcm *firebase.App) error {
 	//	log.Fatal(err)
 	//}
 
+	err = migrations.MigrateSettingOrganizations(dbConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = database.Seed(dbConn, appConfig.Config, "/internal/database/seed.sql")
 	if err != nil {
 		log.Fatal(err)
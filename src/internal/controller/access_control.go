@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// accessScope returns the scope set by RequirePermission, an empty scope allows
+// no organization
+func accessScope(context *gin.Context) value.AccessScope {
+	scope, ok := context.Get("access_scope")
+	if !ok {
+		return value.AccessScope{}
+	}
+	accessScope, ok := scope.(value.AccessScope)
+	if !ok {
+		return value.AccessScope{}
+	}
+
+	return accessScope
+}
+
+// authorized responds with the failure of an access check and reports whether
+// the request may go on
+func authorized(context *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrOutOfScope):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrOrganizationRequired):
+		code = http.StatusBadRequest
+	case errors.Is(err, repository.ErrOtherOrganization):
+		code = http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	}
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+
+	return false
+}
+
+// owningOrganization resolves the organization the rows created by a request
+// belong to, see usecase.OwningOrganization, it responds with the failure and
+// reports false when the scope does not allow it
+func owningOrganization(context *gin.Context, organizationId *int64) (*int64, bool) {
+	owner, err := usecase.OwningOrganization(accessScope(context), organizationId)
+	if !authorized(context, err) {
+		return nil, false
+	}
+
+	return owner, true
+}
+
+// authorizedDevice checks that the device belongs to an organization of the
+// scope of the request, see usecase.AccessControlUseCase.AuthorizeDevice, it
+// responds with the failure and reports false otherwise
+func authorizedDevice(context *gin.Context, accessControl *usecase.AccessControlUseCase, deviceId string) (int64, bool) {
+	organizationId, err := accessControl.AuthorizeDevice(accessScope(context), deviceId)
+	if !authorized(context, err) {
+		return 0, false
+	}
+
+	return organizationId, true
+}
+
+// authorizedDeviceScope checks that the scope of the request covers the device,
+// group or organization of a device configuration, schedule or override, see
+// usecase.AccessControlUseCase.AuthorizeDeviceScope, it responds with the
+// failure and reports false otherwise
+func authorizedDeviceScope(context *gin.Context, accessControl *usecase.AccessControlUseCase, deviceScope value.DeviceConfigurationScope, scopeId string, change bool) (int64, bool) {
+	organizationId, err := accessControl.AuthorizeDeviceScope(accessScope(context), deviceScope, scopeId, change)
+	if !authorized(context, err) {
+		return 0, false
+	}
+
+	return organizationId, true
+}
+
+// organizationQuery parses the optional organization_id query param, it responds
+// with the failure and reports false when it is not a number
+func organizationQuery(context *gin.Context) (*int64, bool) {
+	if context.Query("organization_id") == "" {
+		return nil, true
+	}
+	organizationId, err := strconv.ParseInt(context.Query("organization_id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return nil, false
+	}
+
+	return &organizationId, true
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogController struct {
+	AccessControl *usecase.AccessControlUseCase
+}
+
+// Get Audit Logs godoc
+// @Summary Get the audit log
+// @Description Get the changes made to users, roles, role claims and role policies newest first. Users without a scope over every organization only see the changes of their organizations.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param organization_id query int false "Organization ID"
+// @Param actor_id query string false "ID of the user who made the change"
+// @Param action query string false "Action, such as role.create or user.role_update"
+// @Param target_type query string false "user, role, role_claim or role_policy"
+// @Param target_id query string false "Target ID"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.AuditLogListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/audit-logs [get]
+func (receiver *AuditLogController) GetAuditLogs(context *gin.Context) {
+	var req request.GetAuditLogsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+
+	auditLogs, paging, err := receiver.AccessControl.GetAuditLogs(accessScope(context), req)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	data := make([]response.AuditLogResponseData, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		var details json.RawMessage = nil
+		if len(auditLog.Details) > 0 {
+			details = json.RawMessage(auditLog.Details)
+		}
+		data = append(data, response.AuditLogResponseData{
+			Id:             auditLog.ID,
+			ActorId:        auditLog.ActorId,
+			OrganizationId: auditLog.OrganizationId,
+			Action:         auditLog.Action,
+			TargetType:     auditLog.TargetType,
+			TargetId:       auditLog.TargetId,
+			Details:        details,
+			IpAddress:      auditLog.IpAddress,
+			CreatedAt:      auditLog.CreatedAt,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.AuditLogListResponse{Data: data, Paging: *paging})
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BulkDeviceController struct {
+	BulkDeviceUseCase *usecase.BulkDeviceUseCase
+	AccessControl     *usecase.AccessControlUseCase
+}
+
+// Bulk Update Devices godoc
+// @Summary Update many devices at once
+// @Description Apply a status (device mode), deactivate message, input mode, screen button type or device component values to the devices of a filter, in one transaction. The filter takes device_ids or any of organization_id, mode, app_version, name_pattern (* as wildcard), group_id and tag, up to 1000 devices, within the organizations the device:write permission is granted in. Every updated device is notified through FCM and the outcome of each device is reported.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.BulkUpdateDevicesRequest true "Bulk Update Devices Request"
+// @Success 200 {object} response.BulkUpdateDevicesResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/devices/bulk [post]
+func (receiver *BulkDeviceController) UpdateDevices(context *gin.Context) {
+	var req request.BulkUpdateDevicesRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := receiver.BulkDeviceUseCase.UpdateDevices(accessScope(context), req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidBulkDeviceRequest) {
+			code = http.StatusBadRequest
+		} else if errors.Is(err, usecase.ErrOutOfScope) {
+			code = http.StatusForbidden
+		}
+		context.JSON(code, response.FailedResponse{
+			Code:  code,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId := int64(0)
+	if req.Filter.OrganizationId != nil {
+		organizationId = *req.Filter.OrganizationId
+	}
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "device.bulk_update", organizationId, "device", "", map[string]interface{}{
+		"filter":                     req.Filter,
+		"status":                     req.Status,
+		"deactivate_message":         req.DeactivateMessage,
+		"input_mode":                 req.InputMode,
+		"screen_button_type":         req.ScreenButtonType,
+		"device_component_values_id": req.DeviceComponentValuesId,
+		"updated":                    result.Updated,
+	})
+
+	context.JSON(http.StatusOK, response.BulkUpdateDevicesResponse{Data: *result})
+}
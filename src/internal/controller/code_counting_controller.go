@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/usecase"
@@ -24,7 +26,7 @@ func GetCodeCounterList(context *gin.Context) {
 		return
 	}
 
-	r, err := usecase.GetCodeCountings(DBConn, rq)
+	r, err := usecase.GetCodeCountings(repository.ScopeAccess(DBConn, accessScope(context)), rq)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -52,7 +54,11 @@ func UpdateCodeCounter(context *gin.Context) {
 		return
 	}
 
-	err = usecase.UpdateCodeCounting(DBConn, rq)
+	err = usecase.UpdateCodeCounting(DBConn, accessScope(context), rq)
+	if errors.Is(err, usecase.ErrOutOfScope) || errors.Is(err, gorm.ErrRecordNotFound) {
+		authorized(context, err)
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
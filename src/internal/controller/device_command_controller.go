@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeviceCommandController struct {
+	DeviceCommandUseCase    *usecase.DeviceCommandUseCase
+	AccessControl           *usecase.AccessControlUseCase
+	GetUserFromTokenUseCase *usecase.GetUserFromTokenUseCase
+	GetUserDeviceUseCase    *usecase.GetUserDeviceUseCase
+}
+
+// Issue Device Command godoc
+// @Summary Queue a command for a device
+// @Description Queue reload_forms, clear_cache, change_mode, show_message, upload_logs or reboot_app for a device. The command is pushed through FCM and handed out by the pull endpoint until the device acknowledges it, it expires after expires_in_minutes (a day by default, a week at most).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Param request body request.IssueDeviceCommandRequest true "Issue Device Command Request"
+// @Success 200 {object} response.DeviceCommandResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device/{device_id}/commands [post]
+func (receiver *DeviceCommandController) IssueCommand(context *gin.Context) {
+	var req request.IssueDeviceCommandRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id"))
+	if !ok {
+		return
+	}
+
+	scope := accessScope(context)
+	command, err := receiver.DeviceCommandUseCase.IssueCommand(context.Param("device_id"), req, scope.UserId)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "device_command.issue", organizationId, "device", command.DeviceId, map[string]interface{}{
+		"command_id": command.ID,
+		"type":       command.Type,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceCommandResponse{Data: toDeviceCommandResponse(*command)})
+}
+
+// Get Device Commands godoc
+// @Summary Get the commands of a device
+// @Description Get the commands of a device newest first with whether they were delivered, acknowledged and executed, optionally only those with the given status
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Param status query string false "pending, delivered, acknowledged, succeeded, failed, expired or cancelled"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DeviceCommandListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device/{device_id}/commands [get]
+func (receiver *DeviceCommandController) GetCommands(context *gin.Context) {
+	var req request.GetDeviceCommandsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+
+	if _, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id")); !ok {
+		return
+	}
+
+	commands, paging, err := receiver.DeviceCommandUseCase.GetCommands(context.Param("device_id"), req)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceCommandListResponse{Data: toDeviceCommandResponses(commands), Paging: *paging})
+}
+
+// Get Device Command godoc
+// @Summary Get a device command
+// @Description Get a device command with its result
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "Command ID"
+// @Success 200 {object} response.DeviceCommandResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-commands/{id} [get]
+func (receiver *DeviceCommandController) GetCommand(context *gin.Context) {
+	command, _, ok := receiver.authorizedCommand(context)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceCommandResponse{Data: toDeviceCommandResponse(*command)})
+}
+
+// Cancel Device Command godoc
+// @Summary Cancel a device command
+// @Description Cancel a command the device has not reported a result for, it is no longer handed out
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "Command ID"
+// @Success 200 {object} response.DeviceCommandResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-commands/{id}/cancel [post]
+func (receiver *DeviceCommandController) CancelCommand(context *gin.Context) {
+	command, organizationId, ok := receiver.authorizedCommand(context)
+	if !ok {
+		return
+	}
+
+	command, err := receiver.DeviceCommandUseCase.CancelCommand(command.ID)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "device_command.cancel", organizationId, "device", command.DeviceId, map[string]interface{}{
+		"command_id": command.ID,
+		"type":       command.Type,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceCommandResponse{Data: toDeviceCommandResponse(*command)})
+}
+
+// Pull Device Commands godoc
+// @Summary Pull the commands of a device
+// @Description Get the commands the device has not acknowledged yet, oldest first, for when the FCM push did not arrive. The user of the token must be a user of the device.
+// @Tags Device
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} response.DeviceCommandQueueResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/device/{device_id}/commands [get]
+func (receiver *DeviceCommandController) PullCommands(context *gin.Context) {
+	deviceId := context.Param("device_id")
+	if !userOwnsDevice(context, receiver.GetUserFromTokenUseCase, receiver.GetUserDeviceUseCase, deviceId) {
+		return
+	}
+
+	commands, err := receiver.DeviceCommandUseCase.PullCommands(deviceId)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceCommandQueueResponse{Data: toDeviceCommandResponses(commands)})
+}
+
+// Acknowledge Device Command godoc
+// @Summary Acknowledge a device command
+// @Description Report that the device received a command and is executing it, it is no longer handed out by the pull endpoint
+// @Tags Device
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "Command ID"
+// @Success 200 {object} response.DeviceCommandResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/device/command/{id}/ack [post]
+func (receiver *DeviceCommandController) AcknowledgeCommand(context *gin.Context) {
+	command, ok := receiver.deviceCommand(context)
+	if !ok {
+		return
+	}
+
+	command, err := receiver.DeviceCommandUseCase.AcknowledgeCommand(command.DeviceId, command.ID)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceCommandResponse{Data: toDeviceCommandResponse(*command)})
+}
+
+// Report Device Command Result godoc
+// @Summary Report the result of a device command
+// @Description Report whether the device executed a command, with its output or error
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "Command ID"
+// @Param request body request.DeviceCommandResultRequest true "Device Command Result Request"
+// @Success 200 {object} response.DeviceCommandResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/device/command/{id}/result [post]
+func (receiver *DeviceCommandController) ReportResult(context *gin.Context) {
+	var req request.DeviceCommandResultRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	command, ok := receiver.deviceCommand(context)
+	if !ok {
+		return
+	}
+
+	command, err := receiver.DeviceCommandUseCase.ReportResult(command.DeviceId, command.ID, req)
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceCommandResponse{Data: toDeviceCommandResponse(*command)})
+}
+
+// authorizedCommand returns the command of the id path parameter and the
+// organization of its device when the device is within the scope of the request
+func (receiver *DeviceCommandController) authorizedCommand(context *gin.Context) (*entity.SDeviceCommand, int64, bool) {
+	command, err := receiver.DeviceCommandUseCase.GetCommand(context.Param("id"))
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return nil, 0, false
+	}
+	organizationId, ok := authorizedDevice(context, receiver.AccessControl, command.DeviceId)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return command, organizationId, true
+}
+
+// deviceCommand returns the command of the id path parameter when the user of
+// the token is a user of its device
+func (receiver *DeviceCommandController) deviceCommand(context *gin.Context) (*entity.SDeviceCommand, bool) {
+	command, err := receiver.DeviceCommandUseCase.GetCommand(context.Param("id"))
+	if err != nil {
+		deviceCommandFailure(context, err)
+		return nil, false
+	}
+	if !userOwnsDevice(context, receiver.GetUserFromTokenUseCase, receiver.GetUserDeviceUseCase, command.DeviceId) {
+		return nil, false
+	}
+
+	return command, true
+}
+
+func deviceCommandFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidDeviceCommand):
+		code = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrDeviceCommandClosed):
+		code = http.StatusConflict
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toDeviceCommandResponses(commands []entity.SDeviceCommand) []response.DeviceCommandResponseData {
+	data := make([]response.DeviceCommandResponseData, 0, len(commands))
+	for _, command := range commands {
+		data = append(data, toDeviceCommandResponse(command))
+	}
+
+	return data
+}
+
+func toDeviceCommandResponse(command entity.SDeviceCommand) response.DeviceCommandResponseData {
+	var payload json.RawMessage
+	if len(command.Payload) > 0 {
+		payload = json.RawMessage(command.Payload)
+	}
+
+	return response.DeviceCommandResponseData{
+		Id:             command.ID,
+		DeviceId:       command.DeviceId,
+		Type:           string(command.Type),
+		Payload:        payload,
+		Status:         string(command.Status),
+		IssuedBy:       command.IssuedBy,
+		ExpiresAt:      command.ExpiresAt,
+		PushedAt:       command.PushedAt,
+		PushError:      command.PushError,
+		DeliveredAt:    command.DeliveredAt,
+		AcknowledgedAt: command.AcknowledgedAt,
+		CompletedAt:    command.CompletedAt,
+		Result:         command.Result,
+		CreatedAt:      command.CreatedAt,
+		UpdatedAt:      command.UpdatedAt,
+	}
+}
@@ -14,6 +14,7 @@ import (
 type DeviceComponentValuesController struct {
 	*usecase.GetDeviceComponentValuesUseCase
 	*usecase.SaveDeviceComponentValuesUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *DeviceComponentValuesController) GetDeviceComponentValuesByOrganization(context *gin.Context) {
@@ -129,6 +130,10 @@ func (receiver *DeviceComponentValuesController) SaveDeviceComponentValuesByOrga
 		return
 	}
 
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), int64(req.Organization))) {
+		return
+	}
+
 	err := receiver.SaveDeviceComponentValuesUseCase.SaveByOrganization(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -152,6 +157,11 @@ func (receiver *DeviceComponentValuesController) SaveDeviceComponentValuesByDevi
 		})
 		return
 	}
+	// the values without an organization are shared by every organization
+	if !accessScope(context).AllOrganizations {
+		authorized(context, usecase.ErrOutOfScope)
+		return
+	}
 	err := receiver.SaveDeviceComponentValuesUseCase.SaveByDevice(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"net/http"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceConfigurationController struct {
+	DeviceConfigurationUseCase *usecase.DeviceConfigurationUseCase
+	AccessControl              *usecase.AccessControlUseCase
+	GetUserFromTokenUseCase    *usecase.GetUserFromTokenUseCase
+	GetUserDeviceUseCase       *usecase.GetUserDeviceUseCase
+}
+
+// Get Device Configuration Of Scope godoc
+// @Summary Get the device configuration set at a scope
+// @Description Get the mode, form_ids and device_component_values_id set for a device, a device group, an organization or globally, a null field is inherited from the scope below. The global scope has no scope_id.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param scope path string true "device, group, organization or global"
+// @Param scope_id path string false "Device ID, device group ID or organization ID"
+// @Success 200 {object} response.DeviceConfigurationResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-configuration/{scope}/{scope_id} [get]
+func (receiver *DeviceConfigurationController) GetConfiguration(context *gin.Context) {
+	scope := value.DeviceConfigurationScope(context.Param("scope"))
+	if _, ok := authorizedDeviceScope(context, receiver.AccessControl, scope, context.Param("scope_id"), false); !ok {
+		return
+	}
+
+	configuration, err := receiver.DeviceConfigurationUseCase.GetConfiguration(scope, context.Param("scope_id"))
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceConfigurationResponse{Data: *configuration})
+}
+
+// Save Device Configuration Of Scope godoc
+// @Summary Set the device configuration of a scope
+// @Description Replace the configuration of a device, a device group, an organization or the global scope. A device runs with the first of device, group, organization and global that sets a field, and with its own status and device component values when none does. Null fields are inherited, a request with every field null clears the scope.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param scope path string true "device, group, organization or global"
+// @Param scope_id path string false "Device ID, device group ID or organization ID"
+// @Param request body request.SaveDeviceConfigurationRequest true "Save Device Configuration Request"
+// @Success 200 {object} response.DeviceConfigurationResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-configuration/{scope}/{scope_id} [put]
+func (receiver *DeviceConfigurationController) SaveConfiguration(context *gin.Context) {
+	var req request.SaveDeviceConfigurationRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := value.DeviceConfigurationScope(context.Param("scope"))
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, scope, context.Param("scope_id"), true)
+	if !ok {
+		return
+	}
+
+	configuration, err := receiver.DeviceConfigurationUseCase.SaveConfiguration(scope, context.Param("scope_id"), req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "device_configuration.save", organizationId, string(scope), configuration.ScopeId, map[string]interface{}{
+		"mode":                       req.Mode,
+		"form_ids":                   req.FormIds,
+		"device_component_values_id": req.DeviceComponentValuesId,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceConfigurationResponse{Data: *configuration})
+}
+
+// Get Effective Device Configuration godoc
+// @Summary Get the configuration a device runs with
+// @Description Get the configuration of a device merged from its device, group, organization and global scopes, with the scope each field comes from
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} response.EffectiveDeviceConfigurationResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device/{device_id}/configuration [get]
+func (receiver *DeviceConfigurationController) GetEffectiveConfiguration(context *gin.Context) {
+	if _, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id")); !ok {
+		return
+	}
+
+	receiver.effectiveConfiguration(context)
+}
+
+func (receiver *DeviceConfigurationController) effectiveConfiguration(context *gin.Context) {
+	configuration, err := receiver.DeviceConfigurationUseCase.ResolveConfiguration(context.Param("device_id"))
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.EffectiveDeviceConfigurationResponse{Data: *configuration})
+}
+
+// Get Device Configuration godoc
+// @Summary Get the configuration of the device
+// @Description Get the configuration the device runs with, merged from its device, group, organization and global scopes. The user of the token must be a user of the device.
+// @Tags Device
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} response.EffectiveDeviceConfigurationResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/device/{device_id}/configuration [get]
+func (receiver *DeviceConfigurationController) GetDeviceConfiguration(context *gin.Context) {
+	deviceId := context.Param("device_id")
+	if !userOwnsDevice(context, receiver.GetUserFromTokenUseCase, receiver.GetUserDeviceUseCase, deviceId) {
+		return
+	}
+
+	receiver.effectiveConfiguration(context)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -38,6 +39,8 @@ type DeviceController struct {
 	*usecase.GetDevicesByUserIdUseCase
 	*usecase.GetUserFromTokenUseCase
 	*usecase.GetUserDeviceUseCase
+	*usecase.DevicePresenceUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *DeviceController) GetDeviceById(c *gin.Context) {
@@ -177,11 +180,15 @@ func (receiver *DeviceController) InitDeviceV1(context *gin.Context) {
 // @Param device_id path string true "Device Id"
 // @Success      200  {object}  response.SucceedResponse
 // @Failure      400  {object}  response.FailedResponse
+// @Failure      403  {object}  response.FailedResponse
 // @Failure      404  {object}  response.FailedResponse
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/device/deactivate/{device_id} [put]
 func (receiver *DeviceController) DeactivateDevice(context *gin.Context) {
 	deviceId := context.Param("device_id")
+	if _, ok := authorizedDevice(context, receiver.AccessControl, deviceId); !ok {
+		return
+	}
 	var req request.DeactivateDeviceRequest
 	if err := context.ShouldBind(&req); err != nil {
 		context.JSON(
@@ -219,11 +226,15 @@ func (receiver *DeviceController) DeactivateDevice(context *gin.Context) {
 // @Param device_id path string true "Device Id"
 // @Success      200  {object}  response.SucceedResponse
 // @Failure      400  {object}  response.FailedResponse
+// @Failure      403  {object}  response.FailedResponse
 // @Failure      404  {object}  response.FailedResponse
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/device/activate/{device_id} [put]
 func (receiver *DeviceController) ActivateDevice(context *gin.Context) {
 	deviceId := context.Param("device_id")
+	if _, ok := authorizedDevice(context, receiver.AccessControl, deviceId); !ok {
+		return
+	}
 	var req request.ReactivateDeviceRequest
 	if err := context.ShouldBind(&req); err != nil {
 		context.JSON(
@@ -261,6 +272,7 @@ func (receiver *DeviceController) ActivateDevice(context *gin.Context) {
 // @Param req body request.UpdateDeviceRequest true "Update Device Params"
 // @Success      200  {object}  response.SucceedResponse
 // @Failure      400  {object}  response.FailedResponse
+// @Failure      403  {object}  response.FailedResponse
 // @Failure      404  {object}  response.FailedResponse
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/device/{device_id}/update/ [put]
@@ -275,6 +287,9 @@ func (receiver *DeviceController) UpdateDevice(context *gin.Context) {
 		)
 		return
 	}
+	if _, ok := authorizedDevice(context, receiver.AccessControl, deviceId); !ok {
+		return
+	}
 	var req request.UpdateDeviceRequest
 	if err := context.BindJSON(&req); err != nil {
 		context.JSON(
@@ -323,6 +338,9 @@ func (receiver *DeviceController) UpdateDeviceV2(context *gin.Context) {
 		)
 		return
 	}
+	if _, ok := authorizedDevice(context, receiver.AccessControl, deviceId); !ok {
+		return
+	}
 	var req request.UpdateDeviceRequestV2
 	if err := context.BindJSON(&req); err != nil {
 		context.JSON(
@@ -443,7 +461,7 @@ func (receiver *DeviceController) TakeNote(context *gin.Context) {
 // @Param Authorization header string true "Bearer {token}"
 // @Param req body request.SubmitFormRequest true "Send Email Params"
 // @Success      200  {object}  response.SucceedResponse
-// @Failure      400  {object}  response.FailedResponse
+// @Failure      400  {object}  response.InvalidAnswersResponse
 // @Failure      404  {object}  response.FailedResponse
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/form/submit [post]
@@ -482,6 +500,15 @@ func (receiver *DeviceController) SubmitForm(context *gin.Context) {
 	// reportMsgBody := fmt.Sprintf("\n[FORM] ID: %d - \nNOTE:%s \n [SUBMITTED by USER]: %s \n %s - \n %v\n", form.ID, form.Note, user.Username, user.Fullname, req)
 	// monitor.SendMessageViaTelegram(reportMsgHeader, reportMsgBody, userInfo)
 	err = receiver.AnswerForm(form.ID, req)
+	var answersErr *usecase.FormAnswersError
+	if errors.As(err, &answersErr) {
+		context.JSON(http.StatusBadRequest, response.InvalidAnswersResponse{
+			Code:   http.StatusBadRequest,
+			Error:  answersErr.Error(),
+			Errors: answersErr.Errors,
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusNotAcceptable, response.FailedResponse{
 			Code:  http.StatusNotAcceptable,
@@ -585,7 +612,14 @@ func (receiver *DeviceController) RefreshAccessToken(context *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := receiver.RefreshAccessTokenUseCase.Execute(request.RefreshToken)
+	accessToken, refreshToken, err := receiver.RefreshAccessTokenUseCase.Execute(request.RefreshToken, context.Request.UserAgent(), context.ClientIP())
+	if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrSessionRevoked) {
+		context.JSON(http.StatusUnauthorized, response.FailedResponse{
+			Code:  http.StatusUnauthorized,
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -1051,7 +1085,7 @@ func (receiver *DeviceController) ResetCodeCounting(context *gin.Context) {
 		return
 	}
 
-	err := receiver.ResetCodeCountingUseCase.Execute(rq)
+	err := receiver.ResetCodeCountingUseCase.Execute(context.GetString("user_id"), rq)
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -1066,3 +1100,143 @@ func (receiver *DeviceController) ResetCodeCounting(context *gin.Context) {
 		Message: "Succeed",
 	})
 }
+
+// Device Heartbeat godoc
+// @Summary      Report a device heartbeat
+// @Description  Report that a device is alive with its battery level, app version, IP address, current form and free storage. The user of the token must be a user of the device.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer {token}"
+// @Param req body request.DeviceHeartbeatRequest true "Heartbeat Params"
+// @Success      200  {object}  response.SucceedResponse
+// @Failure      400  {object}  response.FailedResponse
+// @Failure      403  {object}  response.FailedResponse
+// @Failure      404  {object}  response.FailedResponse
+// @Failure      500  {object}  response.FailedResponse
+// @Router       /v1/device/heartbeat [post]
+func (receiver *DeviceController) Heartbeat(context *gin.Context) {
+	var req request.DeviceHeartbeatRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !userOwnsDevice(context, receiver.GetUserFromTokenUseCase, receiver.GetUserDeviceUseCase, req.DeviceId) {
+		return
+	}
+
+	_, err := receiver.RecordHeartbeat(req, context.ClientIP())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		context.JSON(http.StatusNotFound, response.FailedResponse{
+			Code:  http.StatusNotFound,
+			Error: "device not found",
+		})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "Heartbeat received",
+	})
+}
+
+// List Devices godoc
+// @Summary      List devices with their presence
+// @Description  List the devices of the organizations the permission is granted in, newest first, with their presence (online, stale or offline) and their last heartbeat
+// @Tags         Admin
+// @Produce      json
+// @Param Authorization header string true "Bearer {token}"
+// @Param page query int false "Page, starting at 1"
+// @Param limit query int false "Page size"
+// @Param keyword query string false "Device name or ID"
+// @Param presence query string false "online, stale or offline"
+// @Success      200  {object}  response.DevicePresenceListResponse
+// @Failure      400  {object}  response.FailedResponse
+// @Failure      500  {object}  response.FailedResponse
+// @Router       /v1/admin/devices [get]
+func (receiver *DeviceController) ListDevices(context *gin.Context) {
+	var req request.GetListDeviceRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Presence != "" {
+		if _, err := value.GetDevicePresenceFromString(req.Presence); err != nil {
+			context.JSON(http.StatusBadRequest, response.FailedResponse{
+				Code:  http.StatusBadRequest,
+				Error: err.Error(),
+			})
+			return
+		}
+	}
+
+	devices, paging, err := receiver.GetDeviceListWithPresence(accessScope(context), req)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DevicePresenceListResponse{
+		Data:   devices,
+		Paging: *paging,
+	})
+}
+
+// Get Device Heartbeats godoc
+// @Summary      Get the heartbeats of a device
+// @Description  Get the heartbeats of a device newest first
+// @Tags         Admin
+// @Produce      json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Param from query string false "From, RFC 3339"
+// @Param to query string false "To, RFC 3339"
+// @Param limit query int false "Number of heartbeats, at most 1000"
+// @Success      200  {object}  response.DeviceHeartbeatListResponse
+// @Failure      400  {object}  response.FailedResponse
+// @Failure      403  {object}  response.FailedResponse
+// @Failure      404  {object}  response.FailedResponse
+// @Failure      500  {object}  response.FailedResponse
+// @Router       /v1/admin/device/{device_id}/heartbeats [get]
+func (receiver *DeviceController) GetDeviceHeartbeats(context *gin.Context) {
+	var req request.GetDeviceHeartbeatsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id")); !ok {
+		return
+	}
+
+	heartbeats, err := receiver.GetHeartbeats(context.Param("device_id"), req)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceHeartbeatListResponse{Data: heartbeats})
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeviceEnrollmentController struct {
+	DeviceEnrollmentUseCase *usecase.DeviceEnrollmentUseCase
+	AccessControl           *usecase.AccessControlUseCase
+}
+
+// Get Device Enrollment Codes godoc
+// @Summary Get device enrollment codes
+// @Description Get the enrollment codes of the organizations within the scope of the token, the codes themselves are only shown when they are issued
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param organization_id query int false "Organization ID"
+// @Param active query bool false "Leave out the codes that are revoked, expired or used up"
+// @Success 200 {object} response.DeviceEnrollmentCodeListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/enrollment-codes [get]
+func (receiver *DeviceEnrollmentController) GetCodes(context *gin.Context) {
+	var req request.GetDeviceEnrollmentCodesRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := accessScope(context)
+	if req.OrganizationId != nil && !authorized(context, receiver.AccessControl.AuthorizeOrganization(scope, *req.OrganizationId)) {
+		return
+	}
+
+	codes, err := receiver.DeviceEnrollmentUseCase.GetCodes(req)
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return
+	}
+
+	now := time.Now()
+	data := make([]response.DeviceEnrollmentCodeResponseData, 0, len(codes))
+	for _, code := range codes {
+		if scope.Allows(code.OrganizationId) {
+			data = append(data, toDeviceEnrollmentCodeResponse(code, now))
+		}
+	}
+
+	context.JSON(http.StatusOK, response.DeviceEnrollmentCodeListResponse{Data: data})
+}
+
+// Get Device Enrollment Code godoc
+// @Summary Get a device enrollment code
+// @Description Get a device enrollment code, without the code itself
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Enrollment code ID"
+// @Success 200 {object} response.DeviceEnrollmentCodeResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/enrollment-codes/{id} [get]
+func (receiver *DeviceEnrollmentController) GetCode(context *gin.Context) {
+	code, ok := receiver.authorizedCode(context)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceEnrollmentCodeResponse{Data: toDeviceEnrollmentCodeResponse(*code, time.Now())})
+}
+
+// Create Device Enrollment Code godoc
+// @Summary Issue a device enrollment code
+// @Description Issue a code that fresh devices enroll with, by scanning its QR code or typing it in. The devices join the organization and the device group of the code, start in its mode and sign in as user_id, the admin issuing the code by default. The code and the payload of its QR code are only returned here.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.CreateDeviceEnrollmentCodeRequest true "Create Device Enrollment Code Request"
+// @Success 200 {object} response.DeviceEnrollmentCodeResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/enrollment-codes [post]
+func (receiver *DeviceEnrollmentController) CreateCode(context *gin.Context) {
+	var req request.CreateDeviceEnrollmentCodeRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := accessScope(context)
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(scope, req.OrganizationId)) {
+		return
+	}
+	if req.UserId != "" {
+		if _, err := receiver.AccessControl.AuthorizeUser(scope, req.UserId); !authorized(context, err) {
+			return
+		}
+	}
+
+	code, secret, err := receiver.DeviceEnrollmentUseCase.CreateCode(req, scope.UserId)
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "device_enrollment_code.create", code.OrganizationId, "device_enrollment_code", strconv.FormatUint(code.ID, 10), map[string]interface{}{
+		"code_hint":  code.CodeHint,
+		"group_id":   code.GroupId,
+		"mode":       code.Mode,
+		"user_id":    code.UserId,
+		"max_uses":   code.MaxUses,
+		"expires_at": code.ExpiresAt,
+	})
+
+	data := toDeviceEnrollmentCodeResponse(*code, time.Now())
+	data.Code = secret
+	data.QrPayload = usecase.DeviceEnrollmentQrPayload(secret)
+
+	context.JSON(http.StatusOK, response.DeviceEnrollmentCodeResponse{Data: data})
+}
+
+// Revoke Device Enrollment Code godoc
+// @Summary Revoke a device enrollment code
+// @Description Revoke a device enrollment code so no more devices enroll with it, the devices already enrolled stay enrolled
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Enrollment code ID"
+// @Success 200 {object} response.DeviceEnrollmentCodeResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/enrollment-codes/{id}/revoke [post]
+func (receiver *DeviceEnrollmentController) RevokeCode(context *gin.Context) {
+	code, ok := receiver.authorizedCode(context)
+	if !ok {
+		return
+	}
+
+	code, err := receiver.DeviceEnrollmentUseCase.RevokeCode(code.ID)
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "device_enrollment_code.revoke", code.OrganizationId, "device_enrollment_code", strconv.FormatUint(code.ID, 10), map[string]interface{}{
+		"code_hint": code.CodeHint,
+		"uses":      code.Uses,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceEnrollmentCodeResponse{Data: toDeviceEnrollmentCodeResponse(*code, time.Now())})
+}
+
+// Get Device Enrollments godoc
+// @Summary Get the devices enrolled with an enrollment code
+// @Description Get the devices enrolled with an enrollment code, with when and from where
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Enrollment code ID"
+// @Success 200 {object} response.DeviceEnrollmentListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/enrollment-codes/{id}/enrollments [get]
+func (receiver *DeviceEnrollmentController) GetEnrollments(context *gin.Context) {
+	code, ok := receiver.authorizedCode(context)
+	if !ok {
+		return
+	}
+
+	enrollments, err := receiver.DeviceEnrollmentUseCase.GetEnrollments(code.ID)
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return
+	}
+
+	data := make([]response.DeviceEnrollmentResponseData, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		data = append(data, response.DeviceEnrollmentResponseData{
+			Id:         enrollment.ID,
+			DeviceId:   enrollment.DeviceId,
+			AppVersion: enrollment.AppVersion,
+			IpAddress:  enrollment.IpAddress,
+			EnrolledAt: enrollment.EnrolledAt,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.DeviceEnrollmentListResponse{Data: data})
+}
+
+// Enroll Device godoc
+// @Summary Enroll a device with an enrollment code
+// @Description Register a fresh device with an enrollment code issued by an admin, no login is needed. The device joins the organization and the device group of the code and starts in its mode. It gets a session of the user of the code, refreshed with /v1/device/refresh-token, and the configuration it runs with. A device of another organization cannot be enrolled.
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Param request body request.EnrollDeviceRequest true "Enroll Device Request"
+// @Success 200 {object} response.EnrolledDeviceResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/device/enroll [post]
+func (receiver *DeviceEnrollmentController) Enroll(context *gin.Context) {
+	var req request.EnrollDeviceRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	enrolled, err := receiver.DeviceEnrollmentUseCase.Enroll(req, context.Request.UserAgent(), context.ClientIP())
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.EnrolledDeviceResponse{Data: *enrolled})
+}
+
+func (receiver *DeviceEnrollmentController) authorizedCode(context *gin.Context) (*entity.SDeviceEnrollmentCode, bool) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return nil, false
+	}
+
+	code, err := receiver.DeviceEnrollmentUseCase.GetCode(id)
+	if err != nil {
+		deviceEnrollmentFailure(context, err)
+		return nil, false
+	}
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), code.OrganizationId)) {
+		return nil, false
+	}
+
+	return code, true
+}
+
+func deviceEnrollmentFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidEnrollmentCode):
+		code = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrEnrollmentCodeUnavailable):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrDeviceEnrolledElsewhere):
+		code = http.StatusConflict
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toDeviceEnrollmentCodeResponse(code entity.SDeviceEnrollmentCode, now time.Time) response.DeviceEnrollmentCodeResponseData {
+	data := response.DeviceEnrollmentCodeResponseData{
+		Id:             code.ID,
+		Name:           code.Name,
+		CodeHint:       code.CodeHint,
+		OrganizationId: code.OrganizationId,
+		GroupId:        code.GroupId,
+		UserId:         code.UserId,
+		MaxUses:        code.MaxUses,
+		Uses:           code.Uses,
+		Active:         code.IsActive(now),
+		ExpiresAt:      code.ExpiresAt,
+		RevokedAt:      code.RevokedAt,
+		CreatedBy:      code.CreatedBy,
+		CreatedAt:      code.CreatedAt,
+	}
+	if code.Mode != nil {
+		mode := string(*code.Mode)
+		data.Mode = &mode
+	}
+
+	return data
+}
@@ -0,0 +1,451 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeviceGroupController struct {
+	DeviceGroupUseCase *usecase.DeviceGroupUseCase
+	AccessControl      *usecase.AccessControlUseCase
+}
+
+// Get Device Groups godoc
+// @Summary Get device groups
+// @Description Get the device groups with their number of devices, only those of an organization when organization_id is given
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param organization_id query int false "Organization ID"
+// @Success 200 {object} response.DeviceGroupListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups [get]
+func (receiver *DeviceGroupController) GetGroups(context *gin.Context) {
+	var req request.GetDeviceGroupsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := accessScope(context)
+	if req.OrganizationId != nil && !authorized(context, receiver.AccessControl.AuthorizeOrganization(scope, *req.OrganizationId)) {
+		return
+	}
+
+	groups, err := receiver.DeviceGroupUseCase.GetGroups(req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	scoped := make([]response.DeviceGroupResponseData, 0, len(groups))
+	for _, group := range groups {
+		if group.OrganizationId == nil || scope.Allows(*group.OrganizationId) {
+			scoped = append(scoped, group)
+		}
+	}
+
+	context.JSON(http.StatusOK, response.DeviceGroupListResponse{Data: scoped})
+}
+
+// Get Device Group godoc
+// @Summary Get a device group
+// @Description Get a device group with its number of devices
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Success 200 {object} response.DeviceGroupResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id} [get]
+func (receiver *DeviceGroupController) GetGroup(context *gin.Context) {
+	group, ok := receiver.authorizedGroup(context, false)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceGroupResponse{Data: *group})
+}
+
+// Create Device Group godoc
+// @Summary Create a device group
+// @Description Create a group of devices, such as a classroom, optionally of an organization
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.SaveDeviceGroupRequest true "Save Device Group Request"
+// @Success 200 {object} response.DeviceGroupResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups [post]
+func (receiver *DeviceGroupController) CreateGroup(context *gin.Context) {
+	var req request.SaveDeviceGroupRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	req.OrganizationId = organizationId
+
+	group, err := receiver.DeviceGroupUseCase.CreateGroup(req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "device_group.create", *group, nil)
+
+	context.JSON(http.StatusOK, response.DeviceGroupResponse{Data: *group})
+}
+
+// Update Device Group godoc
+// @Summary Update a device group
+// @Description Update the name, description and organization of a device group
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Param request body request.SaveDeviceGroupRequest true "Save Device Group Request"
+// @Success 200 {object} response.DeviceGroupResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id} [put]
+func (receiver *DeviceGroupController) UpdateGroup(context *gin.Context) {
+	group, ok := receiver.authorizedGroup(context, true)
+	if !ok {
+		return
+	}
+	var req request.SaveDeviceGroupRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	req.OrganizationId = organizationId
+
+	group, err := receiver.DeviceGroupUseCase.UpdateGroup(group.Id, req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "device_group.update", *group, nil)
+
+	context.JSON(http.StatusOK, response.DeviceGroupResponse{Data: *group})
+}
+
+// Delete Device Group godoc
+// @Summary Delete a device group
+// @Description Delete a device group and its configuration, its devices are left without a group
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id} [delete]
+func (receiver *DeviceGroupController) DeleteGroup(context *gin.Context) {
+	group, ok := receiver.authorizedGroup(context, true)
+	if !ok {
+		return
+	}
+
+	err := receiver.DeviceGroupUseCase.DeleteGroup(group.Id)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "device_group.delete", *group, nil)
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "device group deleted",
+	})
+}
+
+// Get Device Group Devices godoc
+// @Summary Get the devices of a device group
+// @Description Get the IDs of the devices of a device group
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Success 200 {object} response.DeviceGroupDevicesResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id}/devices [get]
+func (receiver *DeviceGroupController) GetGroupDevices(context *gin.Context) {
+	group, ok := receiver.authorizedGroup(context, false)
+	if !ok {
+		return
+	}
+
+	devices, err := receiver.DeviceGroupUseCase.GetGroupDevices(group.Id)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceGroupDevicesResponse{Data: *devices})
+}
+
+// Add Device Group Devices godoc
+// @Summary Add devices to a device group
+// @Description Move devices to a device group, a device leaves the group it was in
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Param request body request.DeviceGroupDevicesRequest true "Device Group Devices Request"
+// @Success 200 {object} response.DeviceGroupDevicesResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id}/devices [post]
+func (receiver *DeviceGroupController) AddDevices(context *gin.Context) {
+	receiver.changeDevices(context, "device_group.add_devices", receiver.DeviceGroupUseCase.AddDevices)
+}
+
+// Remove Device Group Devices godoc
+// @Summary Remove devices from a device group
+// @Description Take devices out of a device group, the devices that are not in the group are ignored
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Device Group ID"
+// @Param request body request.DeviceGroupDevicesRequest true "Device Group Devices Request"
+// @Success 200 {object} response.DeviceGroupDevicesResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-groups/{id}/devices/remove [post]
+func (receiver *DeviceGroupController) RemoveDevices(context *gin.Context) {
+	receiver.changeDevices(context, "device_group.remove_devices", receiver.DeviceGroupUseCase.RemoveDevices)
+}
+
+// Get Device Tags godoc
+// @Summary Get the device tags
+// @Description Get every tag in use with its number of devices
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} response.DeviceTagListResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-tags [get]
+func (receiver *DeviceGroupController) GetTags(context *gin.Context) {
+	tags, err := receiver.DeviceGroupUseCase.GetTags()
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceTagListResponse{Data: tags})
+}
+
+// Get Tags Of Device godoc
+// @Summary Get the tags of a device
+// @Description Get the tags of a device
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} response.DeviceTagsResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device/{device_id}/tags [get]
+func (receiver *DeviceGroupController) GetDeviceTags(context *gin.Context) {
+	if _, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id")); !ok {
+		return
+	}
+
+	tags, err := receiver.DeviceGroupUseCase.GetDeviceTags(context.Param("device_id"))
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceTagsResponse{Data: *tags})
+}
+
+// Replace Tags Of Device godoc
+// @Summary Set the tags of a device
+// @Description Replace the tags of a device, up to 32 tags of at most 64 characters. Tags are trimmed and lower cased, an empty list removes them all.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param device_id path string true "Device ID"
+// @Param request body request.ReplaceDeviceTagsRequest true "Replace Device Tags Request"
+// @Success 200 {object} response.DeviceTagsResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device/{device_id}/tags [put]
+func (receiver *DeviceGroupController) ReplaceDeviceTags(context *gin.Context) {
+	var req request.ReplaceDeviceTagsRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := authorizedDevice(context, receiver.AccessControl, context.Param("device_id"))
+	if !ok {
+		return
+	}
+
+	tags, err := receiver.DeviceGroupUseCase.ReplaceDeviceTags(context.Param("device_id"), req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "device.tags", organizationId, "device", tags.DeviceId, map[string]interface{}{
+		"tags": tags.Tags,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceTagsResponse{Data: *tags})
+}
+
+func (receiver *DeviceGroupController) changeDevices(context *gin.Context, action string, change func(uint64, request.DeviceGroupDevicesRequest) (*response.DeviceGroupDevicesResponseData, error)) {
+	group, ok := receiver.authorizedGroup(context, true)
+	if !ok {
+		return
+	}
+	var req request.DeviceGroupDevicesRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	for _, deviceId := range req.DeviceIds {
+		if _, ok := authorizedDevice(context, receiver.AccessControl, deviceId); !ok {
+			return
+		}
+	}
+
+	devices, err := change(group.Id, req)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, action, *group, map[string]interface{}{
+		"device_ids": req.DeviceIds,
+	})
+
+	context.JSON(http.StatusOK, response.DeviceGroupDevicesResponse{Data: *devices})
+}
+
+// authorizedGroup returns the group of the id path parameter when the scope of
+// the request covers its organization. The shared groups, which belong to no
+// organization, can be read by every scope but only changed by a scope over
+// every organization.
+func (receiver *DeviceGroupController) authorizedGroup(context *gin.Context, change bool) (*response.DeviceGroupResponseData, bool) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return nil, false
+	}
+
+	group, err := receiver.DeviceGroupUseCase.GetGroup(id)
+	if err != nil {
+		deviceGroupFailure(context, err)
+		return nil, false
+	}
+	scope := accessScope(context)
+	if (change || group.OrganizationId != nil) && !scope.Owns(group.OrganizationId) {
+		authorized(context, usecase.ErrOutOfScope)
+		return nil, false
+	}
+
+	return group, true
+}
+
+func (receiver *DeviceGroupController) audit(context *gin.Context, action string, group response.DeviceGroupResponseData, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["name"] = group.Name
+
+	organizationId := int64(0)
+	if group.OrganizationId != nil {
+		organizationId = *group.OrganizationId
+	}
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), action, organizationId, "device_group", strconv.FormatUint(group.Id, 10), details)
+}
+
+func deviceGroupFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidDeviceGroup), errors.Is(err, usecase.ErrInvalidDeviceConfiguration):
+		code = http.StatusBadRequest
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
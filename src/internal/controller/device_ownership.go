@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"net/http"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userOwnsDevice reports whether the user of the token is a user of the device,
+// answering 403 or 500 when it is not
+func userOwnsDevice(context *gin.Context, getUser *usecase.GetUserFromTokenUseCase, getUserDevice *usecase.GetUserDeviceUseCase, deviceId string) bool {
+	user, err := getUser.GetUserFromToken(context)
+	if err != nil {
+		context.JSON(http.StatusForbidden, response.FailedResponse{
+			Code:  http.StatusForbidden,
+			Error: err.Error(),
+		})
+		return false
+	}
+	userDevices, err := getUserDevice.GetUserDeviceById(deviceId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return false
+	}
+	for _, userDevice := range *userDevices {
+		if userDevice.UserId.String() == user.ID.String() {
+			return true
+		}
+	}
+
+	context.JSON(http.StatusForbidden, response.FailedResponse{
+		Code:  http.StatusForbidden,
+		Error: "the device does not belong to the user",
+	})
+	return false
+}
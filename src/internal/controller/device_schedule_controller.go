@@ -0,0 +1,513 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeviceScheduleController struct {
+	DeviceScheduleUseCase *usecase.DeviceScheduleUseCase
+	AccessControl         *usecase.AccessControlUseCase
+}
+
+// Get Device Mode Schedules godoc
+// @Summary Get device mode schedules
+// @Description Get the device mode schedules, only those of a scope when scope and scope_id are given
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param scope query string false "device or group"
+// @Param scope_id query string false "Device ID or device group ID"
+// @Success 200 {object} response.DeviceModeScheduleListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-schedules [get]
+func (receiver *DeviceScheduleController) GetSchedules(context *gin.Context) {
+	var req request.GetDeviceModeSchedulesRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if req.Scope != "" && req.ScopeId != "" {
+		if _, ok := authorizedDeviceScope(context, receiver.AccessControl, value.DeviceConfigurationScope(req.Scope), req.ScopeId, false); !ok {
+			return
+		}
+	}
+
+	schedules, err := receiver.DeviceScheduleUseCase.GetSchedules(req)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	data := make([]response.DeviceModeScheduleResponseData, 0, len(schedules))
+	for _, schedule := range schedules {
+		allowed, err := receiver.allows(context, schedule.Scope, schedule.ScopeId)
+		if err != nil {
+			deviceScheduleFailure(context, err)
+			return
+		}
+		if allowed {
+			data = append(data, toDeviceModeScheduleResponse(schedule))
+		}
+	}
+
+	context.JSON(http.StatusOK, response.DeviceModeScheduleListResponse{Data: data})
+}
+
+// Get Device Mode Schedule godoc
+// @Summary Get a device mode schedule
+// @Description Get a device mode schedule
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} response.DeviceModeScheduleResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-schedules/{id} [get]
+func (receiver *DeviceScheduleController) GetSchedule(context *gin.Context) {
+	schedule, _, ok := receiver.authorizedSchedule(context, false)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, response.DeviceModeScheduleResponse{Data: toDeviceModeScheduleResponse(*schedule)})
+}
+
+// Create Device Mode Schedule godoc
+// @Summary Create a device mode schedule
+// @Description Put a device or the devices of a group in a mode on the given days between start_time and end_time (HH:MM) in the timezone of their organization, such as suspended from 20:00 to 08:00 on weekdays. A window ending before it starts runs overnight, start_time and end_time default to outside of the working hours (20:00 to 08:00). Days are sun to sat, weekdays, weekend or everyday.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.SaveDeviceModeScheduleRequest true "Save Device Mode Schedule Request"
+// @Success 200 {object} response.DeviceModeScheduleResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-schedules [post]
+func (receiver *DeviceScheduleController) CreateSchedule(context *gin.Context) {
+	var req request.SaveDeviceModeScheduleRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, value.DeviceConfigurationScope(req.Scope), req.ScopeId, true)
+	if !ok {
+		return
+	}
+
+	scope := accessScope(context)
+	schedule, err := receiver.DeviceScheduleUseCase.CreateSchedule(req, scope.UserId)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.auditSchedule(context, "device_schedule.create", organizationId, *schedule)
+
+	context.JSON(http.StatusOK, response.DeviceModeScheduleResponse{Data: toDeviceModeScheduleResponse(*schedule)})
+}
+
+// Update Device Mode Schedule godoc
+// @Summary Update a device mode schedule
+// @Description Replace a device mode schedule, it is disabled with enabled false
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Schedule ID"
+// @Param request body request.SaveDeviceModeScheduleRequest true "Save Device Mode Schedule Request"
+// @Success 200 {object} response.DeviceModeScheduleResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-schedules/{id} [put]
+func (receiver *DeviceScheduleController) UpdateSchedule(context *gin.Context) {
+	schedule, _, ok := receiver.authorizedSchedule(context, true)
+	if !ok {
+		return
+	}
+	var req request.SaveDeviceModeScheduleRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, value.DeviceConfigurationScope(req.Scope), req.ScopeId, true)
+	if !ok {
+		return
+	}
+
+	schedule, err := receiver.DeviceScheduleUseCase.UpdateSchedule(schedule.ID, req)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.auditSchedule(context, "device_schedule.update", organizationId, *schedule)
+
+	context.JSON(http.StatusOK, response.DeviceModeScheduleResponse{Data: toDeviceModeScheduleResponse(*schedule)})
+}
+
+// Delete Device Mode Schedule godoc
+// @Summary Delete a device mode schedule
+// @Description Delete a device mode schedule, the devices it applies to go back to their configured mode
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-schedules/{id} [delete]
+func (receiver *DeviceScheduleController) DeleteSchedule(context *gin.Context) {
+	schedule, organizationId, ok := receiver.authorizedSchedule(context, true)
+	if !ok {
+		return
+	}
+
+	err := receiver.DeviceScheduleUseCase.DeleteSchedule(schedule.ID)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.auditSchedule(context, "device_schedule.delete", organizationId, *schedule)
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "device mode schedule deleted",
+	})
+}
+
+// Get Device Mode Overrides godoc
+// @Summary Get device mode overrides
+// @Description Get the one-off device mode overrides newest first, only those of a scope when scope and scope_id are given and only those not over yet with upcoming
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param scope query string false "device or group"
+// @Param scope_id query string false "Device ID or device group ID"
+// @Param upcoming query bool false "Leave out the overrides that are over"
+// @Success 200 {object} response.DeviceModeOverrideListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-mode-overrides [get]
+func (receiver *DeviceScheduleController) GetOverrides(context *gin.Context) {
+	var req request.GetDeviceModeOverridesRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if req.Scope != "" && req.ScopeId != "" {
+		if _, ok := authorizedDeviceScope(context, receiver.AccessControl, value.DeviceConfigurationScope(req.Scope), req.ScopeId, false); !ok {
+			return
+		}
+	}
+
+	overrides, err := receiver.DeviceScheduleUseCase.GetOverrides(req)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	data := make([]response.DeviceModeOverrideResponseData, 0, len(overrides))
+	for _, override := range overrides {
+		allowed, err := receiver.allows(context, override.Scope, override.ScopeId)
+		if err != nil {
+			deviceScheduleFailure(context, err)
+			return
+		}
+		if allowed {
+			data = append(data, toDeviceModeOverrideResponse(override))
+		}
+	}
+
+	context.JSON(http.StatusOK, response.DeviceModeOverrideListResponse{Data: data})
+}
+
+// Create Device Mode Override godoc
+// @Summary Override the device mode schedules once
+// @Description Put a device or the devices of a group in a mode until ends_at whatever their schedules say, or leave them in their configured mode with a null mode. It starts now unless starts_at is given and lasts 31 days at most.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.CreateDeviceModeOverrideRequest true "Create Device Mode Override Request"
+// @Success 200 {object} response.DeviceModeOverrideResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-mode-overrides [post]
+func (receiver *DeviceScheduleController) CreateOverride(context *gin.Context) {
+	var req request.CreateDeviceModeOverrideRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, value.DeviceConfigurationScope(req.Scope), req.ScopeId, true)
+	if !ok {
+		return
+	}
+
+	scope := accessScope(context)
+	override, err := receiver.DeviceScheduleUseCase.CreateOverride(req, scope.UserId)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.auditOverride(context, "device_mode_override.create", organizationId, *override)
+
+	context.JSON(http.StatusOK, response.DeviceModeOverrideResponse{Data: toDeviceModeOverrideResponse(*override)})
+}
+
+// Delete Device Mode Override godoc
+// @Summary Delete a device mode override
+// @Description Delete a device mode override, the schedules apply again right away
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Override ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/device-mode-overrides/{id} [delete]
+func (receiver *DeviceScheduleController) DeleteOverride(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	override, err := receiver.DeviceScheduleUseCase.GetOverride(id)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, override.Scope, override.ScopeId, true)
+	if !ok {
+		return
+	}
+	err = receiver.DeviceScheduleUseCase.DeleteOverride(id)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.auditOverride(context, "device_mode_override.delete", organizationId, *override)
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "device mode override deleted",
+	})
+}
+
+// Update Organization Timezone godoc
+// @Summary Set the timezone of an organization
+// @Description Set the IANA timezone, such as Europe/Paris, the device mode schedules of an organization run in. An empty timezone is UTC.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param request body request.UpdateOrganizationTimezoneRequest true "Update Organization Timezone Request"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/organization/{id}/timezone [put]
+func (receiver *DeviceScheduleController) UpdateOrganizationTimezone(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid id",
+		})
+		return
+	}
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), id)) {
+		return
+	}
+	var req request.UpdateOrganizationTimezoneRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organization, err := receiver.DeviceScheduleUseCase.UpdateOrganizationTimezone(id, req)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "organization.timezone", organization.ID, "organization", strconv.FormatInt(organization.ID, 10), map[string]interface{}{
+		"timezone": organization.Timezone,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "organization timezone updated",
+	})
+}
+
+// authorizedSchedule returns the schedule of the id path parameter and the
+// organization it is set in when the scope of the request covers it
+func (receiver *DeviceScheduleController) authorizedSchedule(context *gin.Context, change bool) (*entity.SDeviceModeSchedule, int64, bool) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return nil, 0, false
+	}
+
+	schedule, err := receiver.DeviceScheduleUseCase.GetSchedule(id)
+	if err != nil {
+		deviceScheduleFailure(context, err)
+		return nil, 0, false
+	}
+	organizationId, ok := authorizedDeviceScope(context, receiver.AccessControl, schedule.Scope, schedule.ScopeId, change)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return schedule, organizationId, true
+}
+
+// allows reports whether the scope of the request may read the schedules and
+// overrides set for a device, group or organization, those of deleted devices
+// and groups are left out
+func (receiver *DeviceScheduleController) allows(context *gin.Context, deviceScope value.DeviceConfigurationScope, scopeId string) (bool, error) {
+	scope := accessScope(context)
+	if scope.AllOrganizations {
+		return true, nil
+	}
+
+	_, err := receiver.AccessControl.AuthorizeDeviceScope(scope, deviceScope, scopeId, false)
+	if errors.Is(err, usecase.ErrOutOfScope) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (receiver *DeviceScheduleController) auditSchedule(context *gin.Context, action string, organizationId int64, schedule entity.SDeviceModeSchedule) {
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), action, organizationId, string(schedule.Scope), schedule.ScopeId, map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"mode":        schedule.Mode,
+		"days":        schedule.Days.Strings(),
+		"start_time":  schedule.StartTime,
+		"end_time":    schedule.EndTime,
+		"enabled":     schedule.Enabled,
+	})
+}
+
+func (receiver *DeviceScheduleController) auditOverride(context *gin.Context, action string, organizationId int64, override entity.SDeviceModeOverride) {
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), action, organizationId, string(override.Scope), override.ScopeId, map[string]interface{}{
+		"override_id": override.ID,
+		"mode":        override.Mode,
+		"starts_at":   override.StartsAt,
+		"ends_at":     override.EndsAt,
+		"reason":      override.Reason,
+	})
+}
+
+func deviceScheduleFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidDeviceSchedule):
+		code = http.StatusBadRequest
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toDeviceModeScheduleResponse(schedule entity.SDeviceModeSchedule) response.DeviceModeScheduleResponseData {
+	return response.DeviceModeScheduleResponseData{
+		Id:        schedule.ID,
+		Scope:     string(schedule.Scope),
+		ScopeId:   schedule.ScopeId,
+		Name:      schedule.Name,
+		Mode:      string(schedule.Mode),
+		Days:      schedule.Days.Strings(),
+		StartTime: schedule.StartTime,
+		EndTime:   schedule.EndTime,
+		Enabled:   schedule.Enabled,
+		CreatedBy: schedule.CreatedBy,
+		CreatedAt: schedule.CreatedAt,
+		UpdatedAt: schedule.UpdatedAt,
+	}
+}
+
+func toDeviceModeOverrideResponse(override entity.SDeviceModeOverride) response.DeviceModeOverrideResponseData {
+	data := response.DeviceModeOverrideResponseData{
+		Id:        override.ID,
+		Scope:     string(override.Scope),
+		ScopeId:   override.ScopeId,
+		StartsAt:  override.StartsAt,
+		EndsAt:    override.EndsAt,
+		Reason:    override.Reason,
+		CreatedBy: override.CreatedBy,
+		CreatedAt: override.CreatedAt,
+	}
+	if override.Mode != nil {
+		mode := string(*override.Mode)
+		data.Mode = &mode
+	}
+
+	return data
+}
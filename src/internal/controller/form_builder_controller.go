@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FormBuilderController struct {
+	FormBuilderUseCase *usecase.FormBuilderUseCase
+}
+
+// Create Native Form godoc
+// @Summary Create a form without a spreadsheet
+// @Description Create an empty form whose questions are managed through the form builder endpoints
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.CreateNativeFormRequest true "Create Native Form Request"
+// @Success 200 {object} response.FormBuilderResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/builder [post]
+func (receiver *FormBuilderController) CreateForm(context *gin.Context) {
+	var req request.CreateNativeFormRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	req.OrganizationId = organizationId
+
+	form, err := receiver.FormBuilderUseCase.CreateForm(req)
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormBuilderResponse{Data: response.FormBuilderResponseData{
+		Id:             form.ID,
+		OrganizationId: form.OrganizationId,
+		Note:           form.Note,
+		Name:           form.Name,
+		Password:       form.Password,
+		Questions:      make([]response.FormQuestionResponseData, 0),
+		CreatedAt:      form.CreatedAt,
+		UpdatedAt:      form.UpdatedAt,
+	}})
+}
+
+// Get Form Questions godoc
+// @Summary Get a form with all of its questions
+// @Description Get a form with all of its questions, inactive ones included, in form order
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Success 200 {object} response.FormBuilderResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/questions [get]
+func (receiver *FormBuilderController) GetForm(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	form, questions, err := receiver.FormBuilderUseCase.GetForm(formId)
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormBuilderResponse{Data: response.FormBuilderResponseData{
+		Id:             form.ID,
+		OrganizationId: form.OrganizationId,
+		Note:           form.Note,
+		Name:           form.Name,
+		Password:       form.Password,
+		Questions:      toFormQuestionResponses(questions),
+		CreatedAt:      form.CreatedAt,
+		UpdatedAt:      form.UpdatedAt,
+	}})
+}
+
+// Add Form Question godoc
+// @Summary Add a question to a form
+// @Description Add a question to a form, at the given 1-based position or at the end
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param request body request.SaveFormQuestionRequest true "Save Form Question Request"
+// @Success 200 {object} response.FormQuestionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/question [post]
+func (receiver *FormBuilderController) AddQuestion(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.SaveFormQuestionRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	question, err := receiver.FormBuilderUseCase.AddQuestion(formId, req)
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormQuestionResponse{Data: toFormQuestionResponse(*question)})
+}
+
+// Update Form Question godoc
+// @Summary Update a question of a form
+// @Description Update a question of a form, optionally moving it to a new 1-based position
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param question_id path string true "Question ID"
+// @Param request body request.SaveFormQuestionRequest true "Save Form Question Request"
+// @Success 200 {object} response.FormQuestionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/question/{question_id} [put]
+func (receiver *FormBuilderController) UpdateQuestion(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.SaveFormQuestionRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	question, err := receiver.FormBuilderUseCase.UpdateQuestion(formId, context.Param("question_id"), req)
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormQuestionResponse{Data: toFormQuestionResponse(*question)})
+}
+
+// Reorder Form Questions godoc
+// @Summary Reorder the questions of a form
+// @Description Reorder the questions of a form, the list must contain every question of the form exactly once
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param request body request.ReorderFormQuestionsRequest true "Reorder Form Questions Request"
+// @Success 200 {object} response.FormBuilderResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/questions/order [put]
+func (receiver *FormBuilderController) ReorderQuestions(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.ReorderFormQuestionsRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	_, err := receiver.FormBuilderUseCase.ReorderQuestions(formId, req)
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	receiver.GetForm(context)
+}
+
+// Delete Form Question godoc
+// @Summary Remove a question from a form
+// @Description Remove a question from a form, the question is deleted when no other form uses it
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param question_id path string true "Question ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/question/{question_id} [delete]
+func (receiver *FormBuilderController) DeleteQuestion(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	err := receiver.FormBuilderUseCase.DeleteQuestion(formId, context.Param("question_id"))
+	if err != nil {
+		formBuilderFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "Question removed from form",
+	})
+}
+
+func formIdParam(context *gin.Context) (uint64, bool) {
+	formId, err := strconv.ParseUint(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return 0, false
+	}
+
+	return formId, true
+}
+
+func formBuilderFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidFormQuestion), errors.Is(err, usecase.ErrFormAlreadyExists):
+		code = http.StatusBadRequest
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toFormQuestionResponses(questions []model.FormQuestionItem) []response.FormQuestionResponseData {
+	result := make([]response.FormQuestionResponseData, 0, len(questions))
+	for _, question := range questions {
+		result = append(result, toFormQuestionResponse(question))
+	}
+
+	return result
+}
+
+func toFormQuestionResponse(question model.FormQuestionItem) response.FormQuestionResponseData {
+	return response.FormQuestionResponseData{
+		QuestionId:       question.QuestionId,
+		QuestionType:     question.QuestionType,
+		Question:         question.Question,
+		Attributes:       json.RawMessage(question.Attributes),
+		Status:           value.GetRawStatusValue(question.Status),
+		Order:            question.Order,
+		AnswerRequired:   question.AnswerRequired,
+		EnableOnMobile:   string(question.EnableOnMobile),
+		QuestionUniqueId: question.QuestionUniqueId,
+		Rules:            json.RawMessage(question.Rules),
+		CreatedAt:        question.CreatedAt,
+		UpdatedAt:        question.UpdatedAt,
+	}
+}
@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/usecase"
@@ -43,7 +46,18 @@ func (receiver *FormController) CreateForm(context *gin.Context) {
 		return
 	}
 
-	form, err := receiver.SaveFormUseCase.SaveForm(req)
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+
+	saveForm := receiver.SaveFormUseCase
+	saveForm.FormRepository = saveForm.FormRepository.ForOrganization(organizationId)
+	form, err := saveForm.SaveForm(req)
+	if errors.Is(err, repository.ErrOtherOrganization) {
+		authorized(context, err)
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -53,12 +67,13 @@ func (receiver *FormController) CreateForm(context *gin.Context) {
 	}
 
 	context.JSON(http.StatusOK, response.SaveFormResponse{Data: response.SaveFormResponseData{
-		Id:          form.ID,
-		Spreadsheet: form.SpreadsheetUrl,
-		Password:    form.Password,
-		Note:        form.Note,
-		CreatedAt:   form.CreatedAt,
-		UpdatedAt:   form.UpdatedAt,
+		Id:             form.ID,
+		OrganizationId: form.OrganizationId,
+		Spreadsheet:    form.SpreadsheetUrl,
+		Password:       form.Password,
+		Note:           form.Note,
+		CreatedAt:      form.CreatedAt,
+		UpdatedAt:      form.UpdatedAt,
 	}})
 }
 
@@ -69,6 +84,7 @@ func (receiver *FormController) CreateForm(context *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
+// @Param organization_id query int false "Organization ID, the forms of every organization of the user by default"
 // @Success 200 {object} response.GetFormListResponse
 // @Failure 400 {object} response.FailedResponse
 // @Failure 401 {object} response.FailedResponse
@@ -84,7 +100,11 @@ func (receiver *FormController) GetFormList(context *gin.Context) {
 		})
 		return
 	}
-	forms, paging, err := receiver.GetFormListUseCase.GetFormList(req)
+	formRepository, ok := receiver.formRepository(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	forms, paging, err := (&usecase.GetFormListUseCase{FormRepository: formRepository}).GetFormList(req)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -119,7 +139,11 @@ func (receiver *FormController) DeleteForm(context *gin.Context) {
 		})
 		return
 	}
-	err = receiver.DeleteFormUseCase.DeleteForm(uint64(formId))
+	form, ok := receiver.ownedForm(context, uint64(formId))
+	if !ok {
+		return
+	}
+	err = (&usecase.DeleteFormUseCase{FormRepository: receiver.DeleteFormUseCase.FormRepository.ForOrganization(form.OrganizationId)}).DeleteForm(form.ID)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -173,6 +197,9 @@ func (receiver *FormController) UpdateForm(context *gin.Context) {
 		})
 		return
 	}
+	if _, ok := receiver.ownedForm(context, uint64(formId)); !ok {
+		return
+	}
 	form, err := receiver.UpdateFormUseCase.UpdateForm(formId, req)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -183,12 +210,13 @@ func (receiver *FormController) UpdateForm(context *gin.Context) {
 	}
 	context.JSON(http.StatusOK, response.UpdateFormResponse{
 		Data: response.GetFormListResponseData{
-			Id:          form.ID,
-			Spreadsheet: form.SpreadsheetUrl,
-			Password:    form.Password,
-			Note:        form.Note,
-			CreatedAt:   form.CreatedAt,
-			UpdatedAt:   form.UpdatedAt,
+			Id:             form.ID,
+			OrganizationId: form.OrganizationId,
+			Spreadsheet:    form.SpreadsheetUrl,
+			Password:       form.Password,
+			Note:           form.Note,
+			CreatedAt:      form.CreatedAt,
+			UpdatedAt:      form.UpdatedAt,
 		},
 	})
 }
@@ -201,6 +229,7 @@ func (receiver *FormController) UpdateForm(context *gin.Context) {
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
 // @Param q query string true "Search Query"
+// @Param organization_id query int false "Organization ID, the forms of every organization of the user by default"
 // @Success 200 {object} response.GetFormListResponse
 // @Failure 400 {object} response.FailedResponse
 // @Failure 401 {object} response.FailedResponse
@@ -216,7 +245,15 @@ func (receiver *FormController) SearchForms(context *gin.Context) {
 		})
 		return
 	}
-	forms, err := receiver.SearchFormsUseCase.SearchForms(keyword)
+	organizationId, ok := organizationQuery(context)
+	if !ok {
+		return
+	}
+	formRepository, ok := receiver.formRepository(context, organizationId)
+	if !ok {
+		return
+	}
+	forms, err := (&usecase.SearchFormsUseCase{FormRepository: formRepository}).SearchForms(keyword)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -313,7 +350,11 @@ func (receiver *FormController) importForms(context *gin.Context, index usecase.
 		})
 		return
 	}
-	err := receiver.ImportFormsUseCase.ImportForms(req, index)
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	err := receiver.ImportFormsUseCase.ForOrganization(organizationId).ImportForms(req, index)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -388,3 +429,57 @@ func (receiver *FormController) ImportFormsPartially(context *gin.Context) {
 func (receiver *FormController) ImportSignUpForms(context *gin.Context) {
 	receiver.importForms(context, usecase.FormsUploaderIndexFifth)
 }
+
+// ReadableForm lets the requests on the form of the id param go on when the
+// form is one of the organizations of the scope or a shared form
+func (receiver *FormController) ReadableForm(context *gin.Context) {
+	formId, ok := uintParam(context, "id")
+	if !ok {
+		context.Abort()
+		return
+	}
+	_, err := receiver.GetFormListUseCase.FormRepository.Scoped(accessScope(context)).GetFormById(formId)
+	if !authorized(context, err) {
+		context.Abort()
+	}
+}
+
+// OwnedForm lets the requests on the form of the id param go on when the form
+// is one of the organizations of the scope, the shared forms are only changed
+// with a scope over every organization
+func (receiver *FormController) OwnedForm(context *gin.Context) {
+	formId, ok := uintParam(context, "id")
+	if !ok {
+		context.Abort()
+		return
+	}
+	if _, ok := receiver.ownedForm(context, formId); !ok {
+		context.Abort()
+	}
+}
+
+// formRepository returns the forms of the requested organization, or of every
+// organization of the scope when none is requested
+func (receiver *FormController) formRepository(context *gin.Context, organizationId *int64) (*repository.FormRepository, bool) {
+	if organizationId == nil {
+		return receiver.GetFormListUseCase.FormRepository.Scoped(accessScope(context)), true
+	}
+	if _, ok := owningOrganization(context, organizationId); !ok {
+		return nil, false
+	}
+
+	return receiver.GetFormListUseCase.FormRepository.ForOrganization(organizationId), true
+}
+
+func (receiver *FormController) ownedForm(context *gin.Context, formId uint64) (*entity.SForm, bool) {
+	scope := accessScope(context)
+	form, err := receiver.GetFormListUseCase.FormRepository.Scoped(scope).GetFormById(formId)
+	if err == nil && !scope.Owns(form.OrganizationId) {
+		err = usecase.ErrOutOfScope
+	}
+	if !authorized(context, err) {
+		return nil, false
+	}
+
+	return form, true
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FormRevisionController struct {
+	FormRevisionUseCase *usecase.FormRevisionUseCase
+}
+
+// Get Form Revisions godoc
+// @Summary Get the revisions of a form
+// @Description Get the revisions of a form, newest first, without their questions
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Success 200 {object} response.FormRevisionListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions [get]
+func (receiver *FormRevisionController) GetRevisions(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	revisions, err := receiver.FormRevisionUseCase.GetRevisions(formId)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	data := make([]response.FormRevisionResponseData, 0, len(revisions))
+	for _, revision := range revisions {
+		data = append(data, toFormRevisionResponse(revision, false))
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionListResponse{Data: data})
+}
+
+// Get Form Revision godoc
+// @Summary Get a revision of a form
+// @Description Get a revision of a form with the questions it contains
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} response.FormRevisionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions/{revision} [get]
+func (receiver *FormRevisionController) GetRevision(context *gin.Context) {
+	formId, revision, ok := formRevisionParams(context)
+	if !ok {
+		return
+	}
+
+	result, err := receiver.FormRevisionUseCase.GetRevision(formId, revision)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionResponse{Data: toFormRevisionResponse(*result, true)})
+}
+
+// Create Form Revision godoc
+// @Summary Save the current questions of a form as a draft revision
+// @Description Save the current questions of a form as a draft revision which can be published later
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param request body request.CreateFormRevisionRequest true "Create Form Revision Request"
+// @Success 200 {object} response.FormRevisionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions [post]
+func (receiver *FormRevisionController) CreateDraft(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.CreateFormRevisionRequest
+	if err := context.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := receiver.FormRevisionUseCase.CreateDraft(formId, req)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionResponse{Data: toFormRevisionResponse(*result, true)})
+}
+
+// Publish Form Revision godoc
+// @Summary Publish a draft revision of a form
+// @Description Publish a draft revision, its questions replace the current questions of the form and the previously published revision is archived
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} response.FormRevisionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions/{revision}/publish [post]
+func (receiver *FormRevisionController) Publish(context *gin.Context) {
+	formId, revision, ok := formRevisionParams(context)
+	if !ok {
+		return
+	}
+
+	result, err := receiver.FormRevisionUseCase.Publish(formId, revision)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionResponse{Data: toFormRevisionResponse(*result, true)})
+}
+
+// Rollback Form Revision godoc
+// @Summary Roll a form back to an earlier revision
+// @Description Publish a copy of an earlier revision as a new revision and restore its questions
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param revision path int true "Revision number to roll back to"
+// @Success 200 {object} response.FormRevisionResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions/{revision}/rollback [post]
+func (receiver *FormRevisionController) Rollback(context *gin.Context) {
+	formId, revision, ok := formRevisionParams(context)
+	if !ok {
+		return
+	}
+
+	result, err := receiver.FormRevisionUseCase.Rollback(formId, revision)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionResponse{Data: toFormRevisionResponse(*result, true)})
+}
+
+// Diff Form Revisions godoc
+// @Summary Compare two revisions of a form
+// @Description List the questions added, removed or changed between two revisions of a form
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param from query int true "Revision number to compare from"
+// @Param to query int true "Revision number to compare to"
+// @Success 200 {object} response.FormRevisionDiffResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/revisions/diff [get]
+func (receiver *FormRevisionController) Diff(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.DiffFormRevisionsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	diff, err := receiver.FormRevisionUseCase.Diff(formId, req.From, req.To)
+	if err != nil {
+		formRevisionFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.FormRevisionDiffResponse{Data: *diff})
+}
+
+func formRevisionParams(context *gin.Context) (uint64, int, bool) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return 0, 0, false
+	}
+
+	revision, err := strconv.Atoi(context.Param("revision"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return 0, 0, false
+	}
+
+	return formId, revision, true
+}
+
+func formRevisionFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidFormRevision):
+		code = http.StatusBadRequest
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toFormRevisionResponse(revision entity.SFormRevision, withQuestions bool) response.FormRevisionResponseData {
+	data := response.FormRevisionResponseData{
+		Id:          revision.ID,
+		FormId:      revision.FormId,
+		Revision:    revision.Revision,
+		Status:      revision.Status,
+		Note:        revision.Note,
+		PublishedAt: revision.PublishedAt,
+		CreatedAt:   revision.CreatedAt,
+	}
+
+	if withQuestions {
+		var snapshot entity.FormRevisionSnapshot
+		if err := json.Unmarshal(revision.Snapshot, &snapshot); err == nil {
+			data.Questions = snapshot.Questions
+		}
+	}
+
+	return data
+}
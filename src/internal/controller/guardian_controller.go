@@ -0,0 +1,436 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GuardianController struct {
+	GuardianUseCase *usecase.GuardianUseCase
+	AccessControl   *usecase.AccessControlUseCase
+}
+
+// Get User Guardians godoc
+// @Summary Get the guardian links of a user
+// @Description Get the guardian links of a user, pending, active and past ones, newest first
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Param status query string false "pending, active, declined, cancelled or detached"
+// @Success 200 {object} response.GuardianLinkListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/guardians [get]
+func (receiver *GuardianController) GetGuardians(context *gin.Context) {
+	userId := context.Param("id")
+	if _, err := receiver.AccessControl.AuthorizeUser(accessScope(context), userId); !authorized(context, err) {
+		return
+	}
+
+	receiver.getLinks(context, userId, "")
+}
+
+// Invite Guardian godoc
+// @Summary Invite a guardian for a user
+// @Description Invite a user to be the guardian of the user as a parent, legal_guardian or carer. The guardian gets read access to the submissions, to-do progress and devices of the user once they confirm.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Param request body request.InviteGuardianRequest true "Invite Guardian Request"
+// @Success 200 {object} response.GuardianLinkResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/guardians [post]
+func (receiver *GuardianController) InviteGuardian(context *gin.Context) {
+	userId := context.Param("id")
+	scope := accessScope(context)
+	organizationIds, err := receiver.AccessControl.AuthorizeUser(scope, userId)
+	if !authorized(context, err) {
+		return
+	}
+
+	var req request.InviteGuardianRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	link, err := receiver.GuardianUseCase.Invite(userId, req, scope.UserId)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "guardian.invite", firstOrganization(organizationIds), "guardian_link", strconv.FormatUint(link.ID, 10), map[string]interface{}{
+		"user_id":      link.UserId,
+		"guardian_id":  link.GuardianId,
+		"relationship": link.Relationship,
+	})
+
+	context.JSON(http.StatusOK, response.GuardianLinkResponse{Data: toGuardianLinkResponse(*link)})
+}
+
+// Detach Guardian godoc
+// @Summary Detach a guardian from a user
+// @Description End the link between a user and a guardian, a pending invitation is cancelled and an active guardian loses access. The user, the guardian or an admin can detach, the link is kept as history.
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Param guardian_id path string true "Guardian ID"
+// @Success 200 {object} response.GuardianLinkResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/guardians/{guardian_id}/detach [post]
+func (receiver *GuardianController) DetachGuardian(context *gin.Context) {
+	userId := context.Param("id")
+	guardianId := context.Param("guardian_id")
+	scope := accessScope(context)
+	organizationIds := make([]int64, 0)
+	if scope.UserId == "" || scope.UserId != guardianId {
+		var err error
+		organizationIds, err = receiver.AccessControl.AuthorizeUser(scope, userId)
+		if !authorized(context, err) {
+			return
+		}
+	}
+
+	link, err := receiver.GuardianUseCase.Detach(userId, guardianId, scope.UserId)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "guardian.detach", firstOrganization(organizationIds), "guardian_link", strconv.FormatUint(link.ID, 10), map[string]interface{}{
+		"user_id":     link.UserId,
+		"guardian_id": link.GuardianId,
+		"status":      link.Status,
+	})
+
+	context.JSON(http.StatusOK, response.GuardianLinkResponse{Data: toGuardianLinkResponse(*link)})
+}
+
+// Get Guardian Links godoc
+// @Summary Get the guardian links of the signed in user
+// @Description Get the links where the signed in user is the guardian, the pending ones are the invitations to confirm or decline
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param status query string false "pending, active, declined, cancelled or detached"
+// @Success 200 {object} response.GuardianLinkListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/links [get]
+func (receiver *GuardianController) GetLinks(context *gin.Context) {
+	receiver.getLinks(context, "", context.GetString("user_id"))
+}
+
+// Confirm Guardian Link godoc
+// @Summary Confirm a guardian invitation
+// @Description Confirm a pending invitation of the signed in user, who becomes an active guardian of the child
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Link ID"
+// @Success 200 {object} response.GuardianLinkResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/links/{id}/confirm [post]
+func (receiver *GuardianController) ConfirmLink(context *gin.Context) {
+	receiver.answerLink(context, receiver.GuardianUseCase.Confirm, "guardian.confirm")
+}
+
+// Decline Guardian Link godoc
+// @Summary Decline a guardian invitation
+// @Description Decline a pending invitation of the signed in user, the link is kept as declined
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Link ID"
+// @Success 200 {object} response.GuardianLinkResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/links/{id}/decline [post]
+func (receiver *GuardianController) DeclineLink(context *gin.Context) {
+	receiver.answerLink(context, receiver.GuardianUseCase.Decline, "guardian.decline")
+}
+
+// Get Guardian Children godoc
+// @Summary Get the children of the signed in user
+// @Description Get the users the signed in user is an active guardian of
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} response.UserEntityDataResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/children [get]
+func (receiver *GuardianController) GetChildren(context *gin.Context) {
+	children, err := receiver.GuardianUseCase.GetChildren(context.GetString("user_id"))
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	data := make([]response.UserEntityResponseData, 0, len(children))
+	for _, child := range children {
+		data = append(data, response.UserEntityResponseData{
+			ID:       child.ID.String(),
+			Username: child.Username,
+			Roles:    make([]string, 0),
+		})
+	}
+
+	context.JSON(http.StatusOK, response.UserEntityDataResponse{Data: data})
+}
+
+// Get Guardian Submissions godoc
+// @Summary Get the submissions of the children of the signed in user
+// @Description Get the submissions of the users the signed in user is an active guardian of, newest first
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param user_id query string false "User ID, one of the children"
+// @Param form_id query int false "Form ID"
+// @Param from query string false "First submission date, YYYY-MM-DD"
+// @Param to query string false "Last submission date, YYYY-MM-DD"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.SubmissionListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/submissions [get]
+func (receiver *GuardianController) GetSubmissions(context *gin.Context) {
+	var req request.GetSubmissionListRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+	submissions, paging, err := receiver.GuardianUseCase.GetSubmissions(context.GetString("user_id"), req)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SubmissionListResponse{Data: submissions, Paging: *paging})
+}
+
+// Get Guardian To-Do Completions godoc
+// @Summary Get the to-do progress of the children of the signed in user
+// @Description Get the to-do tasks completed from the devices of the users the signed in user is an active guardian of, newest first
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param user_id query string false "User ID, one of the children"
+// @Param todo_id query string false "To-do ID"
+// @Param from query string false "First completion date, YYYY-MM-DD"
+// @Param to query string false "Last completion date, YYYY-MM-DD"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.ToDoCompletionListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/todo-completions [get]
+func (receiver *GuardianController) GetToDoCompletions(context *gin.Context) {
+	var req request.GetToDoCompletionsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+
+	completions, paging, err := receiver.GuardianUseCase.GetToDoCompletions(context.GetString("user_id"), req)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	data := make([]response.ToDoCompletionResponseData, 0, len(completions))
+	for _, completion := range completions {
+		data = append(data, response.ToDoCompletionResponseData{
+			Id:          completion.ID,
+			ToDoId:      completion.ToDoId,
+			TaskIndex:   completion.TaskIndex,
+			TaskName:    completion.TaskName,
+			Selected:    completion.Selected,
+			DeviceId:    completion.DeviceId,
+			CompletedAt: completion.CompletedAt,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.ToDoCompletionListResponse{Data: data, Paging: *paging})
+}
+
+// Get Guardian Devices godoc
+// @Summary Get the devices of the children of the signed in user
+// @Description Get the devices of the users the signed in user is an active guardian of
+// @Tags Guardian
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param user_id query string false "User ID, one of the children"
+// @Success 200 {object} response.GuardianDeviceListResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/guardian/devices [get]
+func (receiver *GuardianController) GetDevices(context *gin.Context) {
+	devices, err := receiver.GuardianUseCase.GetDevices(context.GetString("user_id"), context.Query("user_id"))
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	data := make([]response.DeviceResponseV2, 0, len(devices))
+	for _, device := range devices {
+		data = append(data, response.DeviceResponseV2{
+			ID:         device.ID,
+			DeviceName: device.DeviceName,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.GuardianDeviceListResponse{Data: data})
+}
+
+func (receiver *GuardianController) getLinks(context *gin.Context, userId string, guardianId string) {
+	var req request.GetGuardianLinksRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	var status *value.GuardianLinkStatus
+	if req.Status != "" {
+		linkStatus, err := value.GetGuardianLinkStatusFromString(req.Status)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, response.FailedResponse{
+				Code:  http.StatusBadRequest,
+				Error: err.Error(),
+			})
+			return
+		}
+		status = &linkStatus
+	}
+
+	links, err := receiver.GuardianUseCase.GetLinks(userId, guardianId, status)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	data := make([]response.GuardianLinkResponseData, 0, len(links))
+	for _, link := range links {
+		data = append(data, toGuardianLinkResponse(link))
+	}
+
+	context.JSON(http.StatusOK, response.GuardianLinkListResponse{Data: data})
+}
+
+func (receiver *GuardianController) answerLink(context *gin.Context, answer func(uint64, string) (*entity.SGuardianLink, error), action string) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	userId := context.GetString("user_id")
+	link, err := answer(id, userId)
+	if err != nil {
+		guardianFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(value.AccessScope{UserId: userId}, context.ClientIP(), action, 0, "guardian_link", strconv.FormatUint(link.ID, 10), map[string]interface{}{
+		"user_id":      link.UserId,
+		"relationship": link.Relationship,
+	})
+
+	context.JSON(http.StatusOK, response.GuardianLinkResponse{Data: toGuardianLinkResponse(*link)})
+}
+
+func toGuardianLinkResponse(link entity.SGuardianLink) response.GuardianLinkResponseData {
+	return response.GuardianLinkResponseData{
+		Id:           link.ID,
+		UserId:       link.UserId,
+		GuardianId:   link.GuardianId,
+		Relationship: string(link.Relationship),
+		Status:       string(link.Status),
+		InvitedBy:    link.InvitedBy,
+		ConfirmedAt:  link.ConfirmedAt,
+		EndedAt:      link.EndedAt,
+		EndedBy:      link.EndedBy,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+func guardianFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidGuardianLink):
+		code = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrNotGuardian):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrGuardianLinkExists), errors.Is(err, usecase.ErrGuardianLinkClosed):
+		code = http.StatusConflict
+	}
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
@@ -2,19 +2,52 @@ package controller
 
 import (
 	"bufio"
+	"errors"
 	"net/http"
+	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/pkg/randx"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ImageController struct {
 	*usecase.GetImageUseCase
 	*usecase.UploadImageUseCase
 	*usecase.DeleteImageUseCase
+	PermissionRepository *repository.PermissionRepository
+}
+
+// memberScope returns the scope of the organizations the user is a member of,
+// widened by the scope set by OptionalPermission. It responds with the failure
+// and reports false when the organizations cannot be read.
+func (receiver *ImageController) memberScope(context *gin.Context) (value.AccessScope, bool) {
+	scope := accessScope(context)
+	scope.UserId = context.GetString("user_id")
+	if scope.AllOrganizations {
+		return scope, true
+	}
+
+	organizationIds, err := receiver.PermissionRepository.GetUserOrganizationIds(scope.UserId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return scope, false
+	}
+	for _, organizationId := range organizationIds {
+		if !scope.Allows(organizationId) {
+			scope.OrganizationIds = append(scope.OrganizationIds, organizationId)
+		}
+	}
+
+	return scope, true
 }
 
 type getUrlByKeyRequest struct {
@@ -31,7 +64,16 @@ func (receiver *ImageController) GetUrlByKey(context *gin.Context) {
 		return
 	}
 
-	url, err := receiver.GetImageUseCase.GetUrlByKey(req.Key)
+	scope, ok := receiver.memberScope(context)
+	if !ok {
+		return
+	}
+
+	url, err := receiver.GetImageUseCase.GetUrlByKey(scope, req.Key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		authorized(context, err)
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -80,7 +122,29 @@ func (receiver *ImageController) CreateImage(context *gin.Context) {
 		return
 	}
 
-	url, img, err := receiver.UploadImageUseCase.UploadImage(dataBytes, folder, fileHeader.Filename, fileName)
+	var organizationId *int64 = nil
+	if context.PostForm("organization_id") != "" {
+		id, err := strconv.ParseInt(context.PostForm("organization_id"), 10, 64)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, response.FailedResponse{
+				Code:  http.StatusBadRequest,
+				Error: err.Error(),
+			})
+			return
+		}
+		organizationId = &id
+	}
+	scope, ok := receiver.memberScope(context)
+	if !ok {
+		return
+	}
+	owner, err := usecase.OwningOrganization(scope, organizationId)
+	if !authorized(context, err) {
+		return
+	}
+	uploadImage := *receiver.UploadImageUseCase
+	uploadImage.ImageRepository = uploadImage.ImageRepository.ForOrganization(owner)
+	url, img, err := uploadImage.UploadImage(dataBytes, folder, fileHeader.Filename, fileName)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -121,7 +185,16 @@ func (receiver *ImageController) DeleteImage(context *gin.Context) {
 		return
 	}
 
-	err := receiver.DeleteImageUseCase.DeleteImage(req.Key)
+	scope, ok := receiver.memberScope(context)
+	if !ok {
+		return
+	}
+
+	err := receiver.DeleteImageUseCase.DeleteImage(scope, req.Key)
+	if errors.Is(err, usecase.ErrOutOfScope) || errors.Is(err, gorm.ErrRecordNotFound) {
+		authorized(context, err)
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -37,7 +37,11 @@ func (c *ImportToDoController) ImportTodos(context *gin.Context) {
 		context.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	err := c.ImportToDoList(request)
+	organizationId, ok := owningOrganization(context, request.OrganizationId)
+	if !ok {
+		return
+	}
+	err := c.ImportToDoListUseCase.ForOrganization(organizationId).ImportToDoList(request)
 	if err != nil {
 		context.JSON(500, response.SucceedResponse{
 			Code:    http.StatusInternalServerError,
@@ -90,7 +94,7 @@ func (c *ImportToDoController) ImportPartiallyTodos(context *gin.Context) {
 	})
 }
 
-func NewImportToDoListController(cfg config.AppConfig, dbConn *gorm.DB, reader *sheet.Reader, writer *sheet.Writer, machine *job.TimeMachine) *ImportToDoController {
+func NewImportToDoListController(cfg config.AppConfig, dbConn *gorm.DB, reader sheet.SpreadsheetReader, writer sheet.SpreadsheetWriter, machine *job.TimeMachine) *ImportToDoController {
 	return &ImportToDoController{
 		ImportToDoListUseCase: usecase.NewImportToDoListUseCase(cfg, dbConn, reader, writer, machine),
 	}
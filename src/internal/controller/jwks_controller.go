@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"net/http"
+	"sen-global-api/pkg/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	KeySet *jwtkeys.KeySet
+}
+
+// Get JWKS godoc
+// @Summary Get the token signing keys
+// @Description Get the public keys tokens are signed with as a JSON Web Key Set, the kid header of a token names its key. Empty while tokens are signed with HS256.
+// @Tags Session
+// @Produce json
+// @Success 200 {object} jwtkeys.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (receiver *JWKSController) GetJWKS(context *gin.Context) {
+	jwks := jwtkeys.JSONWebKeySet{Keys: make([]jwtkeys.JSONWebKey, 0)}
+	if receiver.KeySet != nil {
+		jwks = receiver.KeySet.JWKS()
+	}
+
+	context.Header("Cache-Control", "public, max-age=300")
+	context.JSON(http.StatusOK, jwks)
+}
@@ -26,6 +26,8 @@ func (receiver LoginController) Login(c *gin.Context) {
 		return
 	}
 
+	req.UserAgent = c.Request.UserAgent()
+	req.IpAddress = c.ClientIP()
 	data, err := receiver.LoginInputDao(req)
 
 	if err != nil {
@@ -67,6 +69,8 @@ func (receiver LoginController) UserLogin(c *gin.Context) {
 		return
 	}
 
+	req.UserAgent = c.Request.UserAgent()
+	req.IpAddress = c.ClientIP()
 	data, err := receiver.UserLoginUsecase(req)
 
 	if err != nil {
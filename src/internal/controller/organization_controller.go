@@ -82,6 +82,8 @@ func (receiver OrganizationController) GetOrganizationById(context *gin.Context)
 			OrganizationName: organization.OrganizationName,
 			Address:          organization.Address,
 			Description:      organization.Description,
+			Timezone:         organization.Timezone,
+			OwnerId:          organization.OwnerId,
 		},
 	})
 }
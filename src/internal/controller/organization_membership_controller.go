@@ -0,0 +1,640 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OrganizationMembershipController struct {
+	OrganizationMembershipUseCase *usecase.OrganizationMembershipUseCase
+	AccessControl                 *usecase.AccessControlUseCase
+}
+
+// Get Organization Invitations godoc
+// @Summary Get the invitations of an organization
+// @Description Get the invitations of an organization, the codes themselves are only shown when they are created
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param active query bool false "Leave out the invitations that are revoked, declined, expired or used up"
+// @Success 200 {object} response.OrganizationInvitationListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/invitations [get]
+func (receiver *OrganizationMembershipController) GetInvitations(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	var req request.GetOrganizationInvitationsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	invitations, err := receiver.OrganizationMembershipUseCase.GetInvitations(organizationId, req)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	now := time.Now()
+	data := make([]response.OrganizationInvitationResponseData, 0, len(invitations))
+	for _, invitation := range invitations {
+		data = append(data, toOrganizationInvitationResponse(invitation, now))
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationInvitationListResponse{Data: data})
+}
+
+// Create Organization Invitation godoc
+// @Summary Invite users to an organization
+// @Description Create an invitation to the organization with the roles of role_ids, roles of the organization. An invitation with an email is for the user with that email only, its code and link are sent to that email and not returned. The code and the link of an invitation without an email are only returned here.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param request body request.CreateOrganizationInvitationRequest true "Create Organization Invitation Request"
+// @Success 200 {object} response.OrganizationInvitationResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/invitations [post]
+func (receiver *OrganizationMembershipController) CreateInvitation(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	var req request.CreateOrganizationInvitationRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := accessScope(context)
+	invitation, secret, err := receiver.OrganizationMembershipUseCase.CreateInvitation(organizationId, req, scope.UserId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "organization_invitation.create", organizationId, "organization_invitation", strconv.FormatUint(invitation.ID, 10), map[string]interface{}{
+		"code_hint":  invitation.CodeHint,
+		"email":      invitation.Email,
+		"role_ids":   usecase.InvitationRoleIds(*invitation),
+		"max_uses":   invitation.MaxUses,
+		"expires_at": invitation.ExpiresAt,
+	})
+
+	data := toOrganizationInvitationResponse(*invitation, time.Now())
+	if secret != "" {
+		data.Code = secret
+		data.Link = usecase.OrganizationInvitationLink(secret)
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationInvitationResponse{Data: data})
+}
+
+// Revoke Organization Invitation godoc
+// @Summary Revoke an invitation to an organization
+// @Description Revoke an invitation so no more users join with it, the users who already joined stay members
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param invitation_id path int true "Invitation ID"
+// @Success 200 {object} response.OrganizationInvitationResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/invitations/{invitation_id}/revoke [post]
+func (receiver *OrganizationMembershipController) RevokeInvitation(context *gin.Context) {
+	invitation, ok := receiver.authorizedInvitation(context)
+	if !ok {
+		return
+	}
+
+	invitation, err := receiver.OrganizationMembershipUseCase.RevokeInvitation(invitation.ID)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "organization_invitation.revoke", invitation.OrganizationId, "organization_invitation", strconv.FormatUint(invitation.ID, 10), map[string]interface{}{
+		"code_hint": invitation.CodeHint,
+		"uses":      invitation.Uses,
+	})
+
+	context.JSON(http.StatusOK, response.OrganizationInvitationResponse{Data: toOrganizationInvitationResponse(*invitation, time.Now())})
+}
+
+// Get Organization Invitation Replies godoc
+// @Summary Get the answers to an invitation
+// @Description Get the users who accepted or declined an invitation to an organization
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param invitation_id path int true "Invitation ID"
+// @Success 200 {object} response.OrganizationInvitationReplyListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/invitations/{invitation_id}/replies [get]
+func (receiver *OrganizationMembershipController) GetReplies(context *gin.Context) {
+	invitation, ok := receiver.authorizedInvitation(context)
+	if !ok {
+		return
+	}
+
+	replies, err := receiver.OrganizationMembershipUseCase.GetReplies(invitation.ID)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	data := make([]response.OrganizationInvitationReplyResponseData, 0, len(replies))
+	for _, reply := range replies {
+		data = append(data, response.OrganizationInvitationReplyResponseData{
+			UserId:    reply.UserId,
+			Reply:     string(reply.Reply),
+			RepliedAt: reply.RepliedAt,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationInvitationReplyListResponse{Data: data})
+}
+
+// Preview Organization Invitation godoc
+// @Summary Look at an invitation to an organization
+// @Description Get the organization and the roles an invitation is for, before accepting or declining it
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param code query string true "Invitation code or link"
+// @Success 200 {object} response.OrganizationInvitationPreviewResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/invitation [get]
+func (receiver *OrganizationMembershipController) PreviewInvitation(context *gin.Context) {
+	preview, err := receiver.OrganizationMembershipUseCase.PreviewInvitation(context.Query("code"), context.GetString("user_id"))
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationInvitationPreviewResponse{Data: *preview})
+}
+
+// Accept Organization Invitation godoc
+// @Summary Accept an invitation to an organization
+// @Description Join the organization of an invitation with the roles of the invitation
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.OrganizationInvitationCodeRequest true "Invitation code or link"
+// @Success 200 {object} response.OrganizationMemberResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/invitation/accept [post]
+func (receiver *OrganizationMembershipController) AcceptInvitation(context *gin.Context) {
+	var req request.OrganizationInvitationCodeRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	userId := context.GetString("user_id")
+	invitation, err := receiver.OrganizationMembershipUseCase.AcceptInvitation(req.Code, userId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(value.AccessScope{UserId: userId}, context.ClientIP(), "organization_invitation.accept", invitation.OrganizationId, "organization_invitation", strconv.FormatUint(invitation.ID, 10), map[string]interface{}{
+		"code_hint": invitation.CodeHint,
+		"role_ids":  usecase.InvitationRoleIds(*invitation),
+	})
+
+	member, err := receiver.OrganizationMembershipUseCase.GetMember(invitation.OrganizationId, userId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationMemberResponse{Data: toOrganizationMemberResponse(*member, "")})
+}
+
+// Decline Organization Invitation godoc
+// @Summary Decline an invitation to an organization
+// @Description Decline an invitation, an invitation for the email of the user is closed
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.OrganizationInvitationCodeRequest true "Invitation code or link"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/invitation/decline [post]
+func (receiver *OrganizationMembershipController) DeclineInvitation(context *gin.Context) {
+	var req request.OrganizationInvitationCodeRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	userId := context.GetString("user_id")
+	invitation, err := receiver.OrganizationMembershipUseCase.DeclineInvitation(req.Code, userId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(value.AccessScope{UserId: userId}, context.ClientIP(), "organization_invitation.decline", invitation.OrganizationId, "organization_invitation", strconv.FormatUint(invitation.ID, 10), map[string]interface{}{
+		"code_hint": invitation.CodeHint,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "invitation declined",
+	})
+}
+
+// Get Organization Members godoc
+// @Summary Get the members of an organization
+// @Description Get the members of an organization with their status, the owner is flagged
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param status query string false "active or suspended"
+// @Success 200 {object} response.OrganizationMemberListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/members [get]
+func (receiver *OrganizationMembershipController) GetMembers(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	var req request.GetOrganizationMembersRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	var status *value.MembershipStatus
+	if req.Status != "" {
+		membershipStatus, err := value.GetMembershipStatusFromString(req.Status)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, response.FailedResponse{
+				Code:  http.StatusBadRequest,
+				Error: err.Error(),
+			})
+			return
+		}
+		status = &membershipStatus
+	}
+
+	organization, err := receiver.OrganizationMembershipUseCase.GetOrganization(organizationId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+	members, err := receiver.OrganizationMembershipUseCase.GetMembers(organizationId, status)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	data := make([]response.OrganizationMemberResponseData, 0, len(members))
+	for _, member := range members {
+		data = append(data, toOrganizationMemberResponse(member, organization.OwnerId))
+	}
+
+	context.JSON(http.StatusOK, response.OrganizationMemberListResponse{Data: data})
+}
+
+// Suspend Organization Member godoc
+// @Summary Suspend a member of an organization
+// @Description Suspend a member, the roles of the organization stop counting for them until they are reinstated. The owner cannot be suspended.
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.OrganizationMemberResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/members/{user_id}/suspend [post]
+func (receiver *OrganizationMembershipController) SuspendMember(context *gin.Context) {
+	receiver.setMemberStatus(context, value.MembershipStatus_Suspended, "organization_member.suspend")
+}
+
+// Reinstate Organization Member godoc
+// @Summary Reinstate a suspended member of an organization
+// @Description Reinstate a suspended member, the roles of the organization count for them again
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.OrganizationMemberResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/members/{user_id}/reinstate [post]
+func (receiver *OrganizationMembershipController) ReinstateMember(context *gin.Context) {
+	receiver.setMemberStatus(context, value.MembershipStatus_Active, "organization_member.reinstate")
+}
+
+// Remove Organization Member godoc
+// @Summary Remove a member from an organization
+// @Description Remove a member from an organization together with their roles of the organization. The owner cannot be removed.
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/members/{user_id} [delete]
+func (receiver *OrganizationMembershipController) RemoveMember(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	userId := context.Param("user_id")
+	err := receiver.OrganizationMembershipUseCase.RemoveMember(organizationId, userId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "organization_member.remove", organizationId, "user", userId, nil)
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "member removed",
+	})
+}
+
+// Transfer Organization Ownership godoc
+// @Summary Transfer the ownership of an organization
+// @Description Make an active member the owner of the organization, the owner holds every permission in it. Only the current owner or a super admin can transfer the ownership.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Param request body request.TransferOrganizationOwnershipRequest true "Transfer Organization Ownership Request"
+// @Success 200 {object} response.SucceedResponse{data=response.OrganizationResponse}
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/owner [post]
+func (receiver *OrganizationMembershipController) TransferOwnership(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	var req request.TransferOrganizationOwnershipRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	scope := accessScope(context)
+	previous, err := receiver.OrganizationMembershipUseCase.GetOrganization(organizationId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+	organization, err := receiver.OrganizationMembershipUseCase.TransferOwnership(scope, organizationId, req.UserId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "organization.transfer_ownership", organizationId, "organization", strconv.FormatInt(organizationId, 10), map[string]interface{}{
+		"previous_owner_id": previous.OwnerId,
+		"owner_id":          organization.OwnerId,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code: http.StatusOK,
+		Data: response.OrganizationResponse{
+			ID:               organization.ID,
+			OrganizationName: organization.OrganizationName,
+			Address:          organization.Address,
+			Description:      organization.Description,
+			Timezone:         organization.Timezone,
+			OwnerId:          organization.OwnerId,
+		},
+	})
+}
+
+// Retire Organization Password godoc
+// @Summary Retire the shared password of an organization
+// @Description Clear the shared password of the legacy join, the organization is only joined with invitations from then on
+// @Tags Organization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/organization/{id}/password [delete]
+func (receiver *OrganizationMembershipController) RetirePassword(context *gin.Context) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	err := receiver.OrganizationMembershipUseCase.RetirePassword(organizationId)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), "organization.retire_password", organizationId, "organization", strconv.FormatInt(organizationId, 10), nil)
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "organization password retired",
+	})
+}
+
+func (receiver *OrganizationMembershipController) setMemberStatus(context *gin.Context, status value.MembershipStatus, action string) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return
+	}
+
+	userId := context.Param("user_id")
+	member, err := receiver.OrganizationMembershipUseCase.SetMemberStatus(organizationId, userId, status)
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return
+	}
+
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), action, organizationId, "user", userId, nil)
+
+	context.JSON(http.StatusOK, response.OrganizationMemberResponse{Data: toOrganizationMemberResponse(*member, "")})
+}
+
+func (receiver *OrganizationMembershipController) authorizedOrganization(context *gin.Context) (int64, bool) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return 0, false
+	}
+
+	organizationId := int64(id)
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), organizationId)) {
+		return 0, false
+	}
+
+	return organizationId, true
+}
+
+func (receiver *OrganizationMembershipController) authorizedInvitation(context *gin.Context) (*entity.SOrganizationInvitation, bool) {
+	organizationId, ok := receiver.authorizedOrganization(context)
+	if !ok {
+		return nil, false
+	}
+	id, ok := uintParam(context, "invitation_id")
+	if !ok {
+		return nil, false
+	}
+
+	invitation, err := receiver.OrganizationMembershipUseCase.GetInvitation(id)
+	if err == nil && invitation.OrganizationId != organizationId {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		organizationMembershipFailure(context, err)
+		return nil, false
+	}
+
+	return invitation, true
+}
+
+func organizationMembershipFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidInvitation), errors.Is(err, usecase.ErrInvalidOwner):
+		code = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrInvitationUnavailable), errors.Is(err, usecase.ErrInvitationForSomeone), errors.Is(err, usecase.ErrOwnershipRequired):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrAlreadyMember), errors.Is(err, usecase.ErrOwnerMembership):
+		code = http.StatusConflict
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toOrganizationInvitationResponse(invitation entity.SOrganizationInvitation, now time.Time) response.OrganizationInvitationResponseData {
+	return response.OrganizationInvitationResponseData{
+		Id:             invitation.ID,
+		CodeHint:       invitation.CodeHint,
+		OrganizationId: invitation.OrganizationId,
+		Email:          invitation.Email,
+		RoleIds:        usecase.InvitationRoleIds(invitation),
+		MaxUses:        invitation.MaxUses,
+		Uses:           invitation.Uses,
+		Active:         invitation.IsActive(now),
+		ExpiresAt:      invitation.ExpiresAt,
+		RevokedAt:      invitation.RevokedAt,
+		DeclinedAt:     invitation.DeclinedAt,
+		CreatedBy:      invitation.CreatedBy,
+		CreatedAt:      invitation.CreatedAt,
+	}
+}
+
+func toOrganizationMemberResponse(member entity.SUsersOrganization, ownerId string) response.OrganizationMemberResponseData {
+	return response.OrganizationMemberResponseData{
+		UserId:       member.UserId.String(),
+		Username:     member.User.Username,
+		Fullname:     member.User.Fullname,
+		Email:        member.User.Email,
+		Status:       string(member.Status),
+		Owner:        ownerId != "" && ownerId == member.UserId.String(),
+		InvitationId: member.InvitationId,
+		SuspendedAt:  member.SuspendedAt,
+		JoinedAt:     member.CreatedAt,
+	}
+}
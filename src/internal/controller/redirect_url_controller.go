@@ -1,13 +1,18 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/usecase"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 )
 
 type RedirectUrlController struct {
@@ -17,6 +22,7 @@ type RedirectUrlController struct {
 	*usecase.UpdateRedirectUrlUseCase
 	*usecase.GetRedirectUrlByQRCodeUseCase
 	*usecase.ImportRedirectUrlsUseCase
+	*usecase.RedirectUrlAnalyticsUseCase
 }
 
 // @Summary Create redirect url
@@ -40,7 +46,25 @@ func (receiver *RedirectUrlController) CreateRedirectUrl(context *gin.Context) {
 		return
 	}
 
-	form, err := receiver.Save(req)
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	saveRedirectUrl := &usecase.SaveRedirectUrlUseCase{
+		RedirectUrlRepository: receiver.SaveRedirectUrlUseCase.RedirectUrlRepository.ForOrganization(organizationId),
+	}
+	form, err := saveRedirectUrl.Save(req)
+	if errors.Is(err, repository.ErrOtherOrganization) {
+		authorized(context, err)
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidRedirectUrl) {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -50,12 +74,14 @@ func (receiver *RedirectUrlController) CreateRedirectUrl(context *gin.Context) {
 	}
 
 	context.JSON(http.StatusOK, response.SaveRedirectUrlResponse{Data: response.SaveRedirectUrlResponseData{
-		Id:        form.ID,
-		QRCode:    form.QRCode,
-		TargetUrl: form.TargetUrl,
-		Password:  form.Password,
-		CreatedAt: form.CreatedAt,
-		UpdatedAt: form.UpdatedAt,
+		Id:                      form.ID,
+		OrganizationId:          form.OrganizationId,
+		QRCode:                  form.QRCode,
+		TargetUrl:               form.TargetUrl,
+		Password:                form.Password,
+		RedirectUrlScheduleData: usecase.ToRedirectUrlScheduleData(*form),
+		CreatedAt:               form.CreatedAt,
+		UpdatedAt:               form.UpdatedAt,
 	}})
 }
 
@@ -82,7 +108,14 @@ func (receiver *RedirectUrlController) GetRedirectUrlList(context *gin.Context)
 		})
 		return
 	}
-	forms, paging, err := receiver.GetList(req)
+	redirectUrls := receiver.GetRedirectUrlListUseCase.RedirectUrlRepository.Scoped(accessScope(context))
+	if req.OrganizationId != nil {
+		if _, ok := owningOrganization(context, req.OrganizationId); !ok {
+			return
+		}
+		redirectUrls = receiver.GetRedirectUrlListUseCase.RedirectUrlRepository.ForOrganization(req.OrganizationId)
+	}
+	forms, paging, err := (&usecase.GetRedirectUrlListUseCase{RedirectUrlRepository: redirectUrls}).GetList(req)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -117,6 +150,9 @@ func (receiver *RedirectUrlController) DeleteRedirectUrl(context *gin.Context) {
 		})
 		return
 	}
+	if _, ok := receiver.ownedRedirectUrl(context, uint64(formId)); !ok {
+		return
+	}
 	err = receiver.Delete(uint64(formId))
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -171,7 +207,17 @@ func (receiver *RedirectUrlController) UpdateRedirectUrl(context *gin.Context) {
 		})
 		return
 	}
+	if _, ok := receiver.ownedRedirectUrl(context, uint64(formId)); !ok {
+		return
+	}
 	form, err := receiver.Update(formId, req)
+	if errors.Is(err, usecase.ErrInvalidRedirectUrl) {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -181,30 +227,34 @@ func (receiver *RedirectUrlController) UpdateRedirectUrl(context *gin.Context) {
 	}
 	context.JSON(http.StatusOK, response.UpdateRedirectUrlResponse{
 		Data: response.GetRedirectUrlListResponseData{
-			Id:           form.ID,
-			QRCode:       form.QRCode,
-			TargetUrl:    form.TargetUrl,
-			Password:     form.Password,
-			Hint:         form.Hint,
-			HashPassword: form.HashPassword,
-			CreatedAt:    form.CreatedAt,
-			UpdatedAt:    form.UpdatedAt,
+			Id:                      form.ID,
+			OrganizationId:          form.OrganizationId,
+			QRCode:                  form.QRCode,
+			TargetUrl:               form.TargetUrl,
+			Password:                form.Password,
+			Hint:                    form.Hint,
+			HashPassword:            form.HashPassword,
+			RedirectUrlScheduleData: usecase.ToRedirectUrlScheduleData(*form),
+			CreatedAt:               form.CreatedAt,
+			UpdatedAt:               form.UpdatedAt,
 		},
 	})
 }
 
 // Get Redirect Url by QR Code godoc
 // @Summary Get Redirect Url by QR Code
-// @Description Get Redirect Url by QR Code
+// @Description Get Redirect Url by QR Code, target_url is where the scan goes at the time: the fallback url out of the validity window, a time rule or one of the weighted targets. The scan is recorded with the device or the user of the token when there is one. A redirect url with a password answers 401 with its hint as message without password and 403 with a wrong one, which is recorded as a failed password. The password and its hash are not returned.
 // @Tags Redirect Url
 // @Accept json
 // @Produce json
-// @Param Authorization header string true "Bearer {token}"
-// @Param qrcode query string true "QR Code"
+// @Param Authorization header string false "Bearer {token}"
+// @Param qr_code query string true "QR Code"
+// @Param password query string false "Password of the redirect url, required when it has one"
 // @Success 200 {object} response.GetRedirectUrlResponse
 // @Failure 400 {object} response.FailedResponse
 // @Failure 401 {object} response.FailedResponse
 // @Failure 403 {object} response.FailedResponse
+// @Failure 410 {object} response.FailedResponse
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/redirect-url [get]
 func (receiver *RedirectUrlController) GetRedirectUrlByQRCode(context *gin.Context) {
@@ -216,7 +266,29 @@ func (receiver *RedirectUrlController) GetRedirectUrlByQRCode(context *gin.Conte
 		})
 		return
 	}
-	form, err := receiver.GetByQRCode(req.QRCode)
+	form, targetUrl, err := receiver.GetByQRCode(req, receiver.scanner(context))
+	if errors.Is(err, usecase.ErrRedirectUrlPasswordRequired) {
+		context.JSON(http.StatusUnauthorized, response.FailedResponse{
+			Code:    http.StatusUnauthorized,
+			Message: form.Hint,
+			Error:   err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, usecase.ErrRedirectUrlWrongPassword) {
+		context.JSON(http.StatusForbidden, response.FailedResponse{
+			Code:  http.StatusForbidden,
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, usecase.ErrRedirectUrlInactive) {
+		context.JSON(http.StatusGone, response.FailedResponse{
+			Code:  http.StatusGone,
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -226,18 +298,105 @@ func (receiver *RedirectUrlController) GetRedirectUrlByQRCode(context *gin.Conte
 	}
 	context.JSON(http.StatusOK, response.GetRedirectUrlResponse{
 		Data: response.GetRedirectUrlListResponseData{
-			Id:           form.ID,
-			QRCode:       form.QRCode,
-			TargetUrl:    form.TargetUrl,
-			Password:     form.Password,
-			Hint:         form.Hint,
-			HashPassword: form.HashPassword,
-			CreatedAt:    form.CreatedAt,
-			UpdatedAt:    form.UpdatedAt,
+			Id:        form.ID,
+			QRCode:    form.QRCode,
+			TargetUrl: targetUrl,
+			Hint:      form.Hint,
+			CreatedAt: form.CreatedAt,
+			UpdatedAt: form.UpdatedAt,
 		},
 	})
 }
 
+// Get Redirect Url Analytics godoc
+// @Summary Get the redirect url scan analytics
+// @Description Get the scans of each QR code by day, week or month, the most scanned codes and the last failed password attempts. Without from, the last 30 days.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param qr_code query string false "QR Code"
+// @Param organization_id query int false "Organization ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD, today by default"
+// @Param interval query string false "day (default), week or month"
+// @Param limit query int false "Number of top codes and failed attempts, 10 by default"
+// @Success 200 {object} response.RedirectUrlAnalyticsResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/redirect-url/analytics [get]
+func (receiver *RedirectUrlController) GetRedirectUrlAnalytics(context *gin.Context) {
+	var req request.GetRedirectUrlAnalyticsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	analytics, ok := receiver.scopedAnalytics(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+
+	data, err := analytics.GetAnalytics(req)
+	if err != nil {
+		redirectUrlAnalyticsFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.RedirectUrlAnalyticsResponse{Data: *data})
+}
+
+// Export Redirect Url Analytics godoc
+// @Summary Export the redirect url scan analytics
+// @Description Stream the scans of each QR code by period, or the scans one by one, as CSV
+// @Tags Admin
+// @Produce text/csv
+// @Param Authorization header string true "Bearer {token}"
+// @Param report query string false "timeline (default) or scans"
+// @Param qr_code query string false "QR Code"
+// @Param organization_id query int false "Organization ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD, today by default"
+// @Param interval query string false "day (default), week or month"
+// @Success 200 {file} file
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/redirect-url/analytics/export [get]
+func (receiver *RedirectUrlController) ExportRedirectUrlAnalytics(context *gin.Context) {
+	var req request.ExportRedirectUrlAnalyticsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	analytics, ok := receiver.scopedAnalytics(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+
+	export, err := analytics.PrepareExport(req)
+	if err != nil {
+		redirectUrlAnalyticsFailure(context, err)
+		return
+	}
+
+	context.Header("Content-Type", export.ContentType())
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName()))
+	context.Status(http.StatusOK)
+
+	if err = export.Write(context.Writer); err != nil {
+		log.Error("RedirectUrlController.ExportRedirectUrlAnalytics ", err)
+		_ = context.Error(err)
+	}
+}
+
 // Import Redirect Urls godoc
 // @Summary Import Redirect Urls
 // @Description Import Redirect Urls
@@ -261,7 +420,11 @@ func (receiver *RedirectUrlController) ImportRedirectUrls(context *gin.Context)
 		})
 		return
 	}
-	err := receiver.Import(req)
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+	err := receiver.ImportRedirectUrlsUseCase.ForOrganization(organizationId).Import(req)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -317,3 +480,46 @@ func (receiver *RedirectUrlController) ImportPartiallyRedirectUrls(context *gin.
 		Message: "Redirect Urls imported",
 	})
 }
+
+func (receiver *RedirectUrlController) ownedRedirectUrl(context *gin.Context, id uint64) (*entity.SRedirectUrl, bool) {
+	scope := accessScope(context)
+	redirectUrl, err := receiver.GetRedirectUrlListUseCase.RedirectUrlRepository.Scoped(scope).GetById(id)
+	if err == nil && !scope.Owns(redirectUrl.OrganizationId) {
+		err = usecase.ErrOutOfScope
+	}
+	if !authorized(context, err) {
+		return nil, false
+	}
+
+	return redirectUrl, true
+}
+
+// scanner reads who resolves a QR code from the request, the token is optional
+func (receiver *RedirectUrlController) scanner(context *gin.Context) usecase.RedirectUrlScanner {
+	return receiver.GetRedirectUrlByQRCodeUseCase.Scanner(context.GetHeader("Authorization"), context.Request.UserAgent(), context.ClientIP())
+}
+
+// scopedAnalytics returns the analytics of the scans of the organizations of the
+// user, of the organization when one is given
+func (receiver *RedirectUrlController) scopedAnalytics(context *gin.Context, organizationId *int64) (*usecase.RedirectUrlAnalyticsUseCase, bool) {
+	scans := receiver.RedirectUrlAnalyticsUseCase.RedirectUrlScanRepository.Scoped(accessScope(context))
+	if organizationId != nil {
+		if _, ok := owningOrganization(context, organizationId); !ok {
+			return nil, false
+		}
+		scans = receiver.RedirectUrlAnalyticsUseCase.RedirectUrlScanRepository.ForOrganization(organizationId)
+	}
+
+	return &usecase.RedirectUrlAnalyticsUseCase{RedirectUrlScanRepository: scans}, true
+}
+
+func redirectUrlAnalyticsFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, usecase.ErrInvalidRedirectUrlAnalytics) {
+		code = http.StatusBadRequest
+	}
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
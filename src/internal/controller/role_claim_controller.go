@@ -15,6 +15,7 @@ type RoleClaimController struct {
 	*usecase.CreateRoleClaimUseCase
 	*usecase.UpdateRoleClaimUseCase
 	*usecase.DeleteRoleClaimUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *RoleClaimController) GetAllRoleClaim(context *gin.Context) {
@@ -28,8 +29,17 @@ func (receiver *RoleClaimController) GetAllRoleClaim(context *gin.Context) {
 		return
 	}
 
+	scope := accessScope(context)
+	organizations := make(map[int64]int64)
 	var roleClaimListResponse []response.RoleClaimListResponseData
 	for _, role := range claims {
+		allowed, err := receiver.AccessControl.AllowsRole(scope, role.RoleId, organizations)
+		if !authorized(context, err) {
+			return
+		}
+		if !allowed {
+			continue
+		}
 		roleClaimListResponse = append(roleClaimListResponse, response.RoleClaimListResponseData{
 			ID:        role.ID,
 			ClaimName: role.ClaimName,
@@ -64,6 +74,10 @@ func (receiver *RoleClaimController) GetAllRoleClaimByRole(context *gin.Context)
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeRole(accessScope(context), int64(id)); !authorized(context, err) {
+		return
+	}
+
 	claims, err := receiver.GetRoleClaimUseCase.GetAllRoleClaimByRole(request.GetAllRoleClaimByRoleRequest{RoleId: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -110,6 +124,10 @@ func (receiver *RoleClaimController) GetRoleClaimById(context *gin.Context) {
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeRoleClaim(accessScope(context), int64(id)); !authorized(context, err) {
+		return
+	}
+
 	roleClaim, err := receiver.GetRoleClaimUseCase.GetRoleClaimById(request.GetRoleClaimByIdRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -150,6 +168,9 @@ func (receiver *RoleClaimController) GetRoleClaimByName(context *gin.Context) {
 
 		return
 	}
+	if _, err := receiver.AccessControl.AuthorizeRole(accessScope(context), userRole.RoleId); !authorized(context, err) {
+		return
+	}
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Data: response.RoleClaimResponse{
@@ -170,7 +191,13 @@ func (receiver *RoleClaimController) CreateRoleClaim(context *gin.Context) {
 		return
 	}
 
-	err := receiver.CreateRoleClaimUseCase.CreateRoleClaim(req)
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRole(scope, int64(req.RoleId))
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.CreateRoleClaimUseCase.CreateRoleClaim(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -178,6 +205,7 @@ func (receiver *RoleClaimController) CreateRoleClaim(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_claim.create", organizationId, "role_claim", req.ClaimName, req)
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
 		Message: "role claim was create successfully",
@@ -194,6 +222,16 @@ func (receiver *RoleClaimController) CreateRoleClaims(context *gin.Context) {
 		return
 	}
 
+	scope := accessScope(context)
+	organizationIds := make([]int64, 0, len(req.RoleClaims))
+	for _, claim := range req.RoleClaims {
+		organizationId, err := receiver.AccessControl.AuthorizeRole(scope, int64(claim.RoleId))
+		if !authorized(context, err) {
+			return
+		}
+		organizationIds = append(organizationIds, organizationId)
+	}
+
 	err := receiver.CreateRoleClaimUseCase.CreateRoleClaims(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -202,6 +240,9 @@ func (receiver *RoleClaimController) CreateRoleClaims(context *gin.Context) {
 		})
 		return
 	}
+	for i, claim := range req.RoleClaims {
+		receiver.AccessControl.Audit(scope, context.ClientIP(), "role_claim.create", organizationIds[i], "role_claim", claim.ClaimName, claim)
+	}
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -219,7 +260,13 @@ func (receiver *RoleClaimController) UpdateRoleClaim(context *gin.Context) {
 		return
 	}
 
-	err := receiver.UpdateRoleClaimUseCase.UpdateRoleClaim(req)
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRoleClaim(scope, int64(req.ID))
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.UpdateRoleClaimUseCase.UpdateRoleClaim(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -227,6 +274,7 @@ func (receiver *RoleClaimController) UpdateRoleClaim(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_claim.update", organizationId, "role_claim", strconv.FormatUint(uint64(req.ID), 10), req)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -257,6 +305,12 @@ func (receiver *RoleClaimController) DeleteRoleClaim(context *gin.Context) {
 		return
 	}
 
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRoleClaim(scope, int64(id))
+	if !authorized(context, err) {
+		return
+	}
+
 	err = receiver.DeleteRoleClaimUseCase.DeleteRoleClaim(request.DeleteRoleClaimRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -265,6 +319,7 @@ func (receiver *RoleClaimController) DeleteRoleClaim(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_claim.delete", organizationId, "role_claim", claimId, nil)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -15,6 +15,7 @@ type RoleClaimPermissionController struct {
 	*usecase.CreateRoleClaimPermissionUseCase
 	*usecase.UpdateRoleClaimPermissionUseCase
 	*usecase.DeleteRoleClaimPermissionUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *RoleClaimPermissionController) GetAllRoleClaimPermission(context *gin.Context) {
@@ -28,8 +29,17 @@ func (receiver *RoleClaimPermissionController) GetAllRoleClaimPermission(context
 		return
 	}
 
+	scope := accessScope(context)
+	organizations := make(map[int64]int64)
 	var permissionListResponse []response.RoleClaimPermissionListResponseData
 	for _, permission := range policies {
+		allowed, err := receiver.AccessControl.AllowsRoleClaim(scope, permission.RoleClaimId, organizations)
+		if !authorized(context, err) {
+			return
+		}
+		if !allowed {
+			continue
+		}
 		permissionListResponse = append(permissionListResponse, response.RoleClaimPermissionListResponseData{
 			ID:             permission.ID,
 			PermissionName: permission.PermissionName,
@@ -64,6 +74,10 @@ func (receiver *RoleClaimPermissionController) GetRoleClaimPermissionById(contex
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeRoleClaimPermission(accessScope(context), int64(id)); !authorized(context, err) {
+		return
+	}
+
 	userRoleClaimPermission, err := receiver.GetRoleClaimPermissionUseCase.GetRoleClaimPermissionById(request.GetRoleClaimPermissionByIdRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -104,6 +118,9 @@ func (receiver *RoleClaimPermissionController) GetRoleClaimPermissionByName(cont
 
 		return
 	}
+	if _, err := receiver.AccessControl.AuthorizeRoleClaim(accessScope(context), userRoleClaimPermission.RoleClaimId); !authorized(context, err) {
+		return
+	}
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Data: response.RoleClaimPermissionResponse{
@@ -124,7 +141,13 @@ func (receiver *RoleClaimPermissionController) CreateRoleClaimPermission(context
 		return
 	}
 
-	err := receiver.Create(req)
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRoleClaim(scope, req.RoleClaimId)
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.Create(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -132,6 +155,7 @@ func (receiver *RoleClaimPermissionController) CreateRoleClaimPermission(context
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_policy.create", organizationId, "role_policy", req.PermissionName, req)
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
 		Message: "role claim permission was create successfully",
@@ -148,7 +172,16 @@ func (receiver *RoleClaimPermissionController) UpdateRoleClaimPermission(context
 		return
 	}
 
-	err := receiver.UpdateRoleClaimPermissionUseCase.UpdateRoleClaimPermission(req)
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRoleClaimPermission(scope, int64(req.ID))
+	if !authorized(context, err) {
+		return
+	}
+	if _, err = receiver.AccessControl.AuthorizeRoleClaim(scope, req.RoleClaimId); !authorized(context, err) {
+		return
+	}
+
+	err = receiver.UpdateRoleClaimPermissionUseCase.UpdateRoleClaimPermission(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -156,6 +189,7 @@ func (receiver *RoleClaimPermissionController) UpdateRoleClaimPermission(context
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_policy.update", organizationId, "role_policy", strconv.FormatUint(uint64(req.ID), 10), req)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -186,6 +220,12 @@ func (receiver *RoleClaimPermissionController) DeleteRoleClaimPermission(context
 		return
 	}
 
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRoleClaimPermission(scope, int64(id))
+	if !authorized(context, err) {
+		return
+	}
+
 	err = receiver.DeleteRoleClaimPermissionUseCase.DeleteRoleClaimPermission(request.DeleteRoleClaimPermissionRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -194,6 +234,7 @@ func (receiver *RoleClaimPermissionController) DeleteRoleClaimPermission(context
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role_policy.delete", organizationId, "role_policy", permissionId, nil)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -15,6 +15,7 @@ type RoleController struct {
 	*usecase.CreateRoleUseCase
 	*usecase.UpdateRoleUseCase
 	*usecase.DeleteRoleUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *RoleController) GetAllRoleByOrganization(context *gin.Context) {
@@ -40,6 +41,10 @@ func (receiver *RoleController) GetAllRoleByOrganization(context *gin.Context) {
 		return
 	}
 
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), int64(id))) {
+		return
+	}
+
 	roles, err := receiver.GetRoleUseCase.GetAllRoleByOrganization(int64(id))
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -86,6 +91,10 @@ func (receiver *RoleController) GetRoleById(context *gin.Context) {
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeRole(accessScope(context), int64(id)); !authorized(context, err) {
+		return
+	}
+
 	userRole, err := receiver.GetRoleUseCase.GetRoleById(request.GetRoleByIdRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -126,6 +135,9 @@ func (receiver *RoleController) GetRoleByName(context *gin.Context) {
 
 		return
 	}
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(accessScope(context), userRole.OrganizationId)) {
+		return
+	}
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Data: response.RoleResponse{
@@ -146,6 +158,11 @@ func (receiver *RoleController) CreateRole(context *gin.Context) {
 		return
 	}
 
+	scope := accessScope(context)
+	if !authorized(context, receiver.AccessControl.AuthorizeOrganization(scope, req.OrganizationId)) {
+		return
+	}
+
 	err := receiver.Create(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -154,6 +171,7 @@ func (receiver *RoleController) CreateRole(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role.create", req.OrganizationId, "role", req.RoleName, req)
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
 		Message: "user role was create successfully",
@@ -170,7 +188,13 @@ func (receiver *RoleController) UpdateRole(context *gin.Context) {
 		return
 	}
 
-	err := receiver.UpdateRoleUseCase.UpdateRole(req)
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRole(scope, int64(req.ID))
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.UpdateRoleUseCase.UpdateRole(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -178,6 +202,7 @@ func (receiver *RoleController) UpdateRole(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role.update", organizationId, "role", strconv.FormatUint(uint64(req.ID), 10), req)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -208,6 +233,12 @@ func (receiver *RoleController) DeleteRole(context *gin.Context) {
 		return
 	}
 
+	scope := accessScope(context)
+	organizationId, err := receiver.AccessControl.AuthorizeRole(scope, int64(id))
+	if !authorized(context, err) {
+		return
+	}
+
 	err = receiver.DeleteRoleUseCase.DeleteRole(request.DeleteRoleRequest{ID: uint(id)})
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -216,6 +247,7 @@ func (receiver *RoleController) DeleteRole(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "role.delete", organizationId, "role", roleId, nil)
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"net/http"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	SessionUseCase *usecase.SessionUseCase
+	AccessControl  *usecase.AccessControlUseCase
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the session of the access token, its access and refresh tokens stop being accepted
+// @Tags Session
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/logout [post]
+func (receiver *SessionController) Logout(context *gin.Context) {
+	err := receiver.SessionUseCase.Logout(context.GetString("user_id"), context.GetString("session_id"))
+	if !authorized(context, err) {
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "logged out",
+	})
+}
+
+// Logout Everywhere godoc
+// @Summary Log out everywhere
+// @Description Revoke every session of the user of the access token, including the current one
+// @Tags Session
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} response.RevokedSessionsResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/logout/all [post]
+func (receiver *SessionController) LogoutEverywhere(context *gin.Context) {
+	revoked, err := receiver.SessionUseCase.LogoutEverywhere(context.GetString("user_id"), "logout_everywhere")
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, response.RevokedSessionsResponse{
+		Data: response.RevokedSessionsResponseData{Revoked: revoked},
+	})
+}
+
+// Get User Sessions godoc
+// @Summary Get the active sessions of a user
+// @Description Get the sessions of a user that are neither revoked nor expired, the most recently used first
+// @Tags Session
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SessionListResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/sessions [get]
+func (receiver *SessionController) GetUserSessions(context *gin.Context) {
+	userId := context.Param("id")
+	_, err := receiver.AccessControl.AuthorizeUser(accessScope(context), userId)
+	if !authorized(context, err) {
+		return
+	}
+
+	sessions, err := receiver.SessionUseCase.GetActiveSessions(userId)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	data := make([]response.SessionResponseData, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, response.SessionResponseData{
+			Id:         session.ID,
+			UserId:     session.UserId,
+			DeviceId:   session.DeviceId,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	context.JSON(http.StatusOK, response.SessionListResponse{Data: data})
+}
+
+// Revoke User Session godoc
+// @Summary Revoke a session of a user
+// @Description Revoke one session of a user, such as the session of a lost device
+// @Tags Session
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/sessions/{session_id} [delete]
+func (receiver *SessionController) RevokeUserSession(context *gin.Context) {
+	userId := context.Param("id")
+	sessionId := context.Param("session_id")
+	scope := accessScope(context)
+	organizationIds, err := receiver.AccessControl.AuthorizeUser(scope, userId)
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.SessionUseCase.RevokeUserSession(userId, sessionId, "revoked_by_admin")
+	if !authorized(context, err) {
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "session.revoke", firstOrganization(organizationIds), "user", userId, map[string]interface{}{
+		"session_id": sessionId,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "session revoked",
+	})
+}
+
+// Revoke User Sessions godoc
+// @Summary Revoke every session of a user
+// @Description Log a user out everywhere
+// @Tags Session
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "User ID"
+// @Success 200 {object} response.RevokedSessionsResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/user/{id}/sessions [delete]
+func (receiver *SessionController) RevokeUserSessions(context *gin.Context) {
+	userId := context.Param("id")
+	scope := accessScope(context)
+	organizationIds, err := receiver.AccessControl.AuthorizeUser(scope, userId)
+	if !authorized(context, err) {
+		return
+	}
+
+	revoked, err := receiver.SessionUseCase.LogoutEverywhere(userId, "revoked_by_admin")
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "session.revoke_all", firstOrganization(organizationIds), "user", userId, map[string]interface{}{
+		"revoked": revoked,
+	})
+
+	context.JSON(http.StatusOK, response.RevokedSessionsResponse{
+		Data: response.RevokedSessionsResponseData{Revoked: revoked},
+	})
+}
@@ -21,6 +21,61 @@ type SettingController struct {
 	*usecase.UpdateApiDistributorUseCase
 }
 
+// ForOrganization returns the controller over the settings of the organization,
+// the global settings when organizationId is nil
+func (receiver *SettingController) ForOrganization(organizationId *int64) *SettingController {
+	settingRepository := receiver.GetSettingsUseCase.SettingRepository.ForOrganization(organizationId)
+	adminSignUpUseCases := *receiver.AdminSignUpUseCases
+	adminSignUpUseCases.SettingRepository = settingRepository
+	adminSignUpUseCases.FormRepository = receiver.AdminSignUpUseCases.FormRepository.ForOrganization(organizationId)
+	adminSignUpUseCases.ImportFormsUseCase = receiver.AdminSignUpUseCases.ImportFormsUseCase.ForOrganization(organizationId)
+
+	return &SettingController{
+		GetSettingsUseCase:                   &usecase.GetSettingsUseCase{SettingRepository: settingRepository},
+		UpdateOutputSubmissionSettingUseCase: &usecase.UpdateOutputSubmissionSettingUseCase{SettingRepository: settingRepository},
+		UpdateOutputSummarySettingUseCase:    &usecase.UpdateOutputSummarySettingUseCase{SettingRepository: settingRepository},
+		UpdateEmailHistorySettingUseCase:     &usecase.UpdateEmailHistorySettingUseCase{SettingRepository: settingRepository},
+		UpdateOutputTemplateSettingUseCase: &usecase.UpdateOutputTemplateSettingUseCase{
+			SettingRepository: settingRepository,
+			AppConfig:         receiver.UpdateOutputTemplateSettingUseCase.AppConfig,
+			Files:             receiver.UpdateOutputTemplateSettingUseCase.Files,
+		},
+		UpdateOutputTemplateSettingForTeacherUseCase: &usecase.UpdateOutputTemplateSettingForTeacherUseCase{
+			SettingRepository: settingRepository,
+			AppConfig:         receiver.UpdateOutputTemplateSettingForTeacherUseCase.AppConfig,
+			Files:             receiver.UpdateOutputTemplateSettingForTeacherUseCase.Files,
+		},
+		AdminSignUpUseCases:         &adminSignUpUseCases,
+		UpdateSettingNameUseCase:    receiver.UpdateSettingNameUseCase.ForOrganization(organizationId),
+		UpdateApiDistributorUseCase: receiver.UpdateApiDistributorUseCase,
+	}
+}
+
+// organizationSettings returns the controller over the settings of the
+// organization_id query param, or of the only organization of the scope
+func (receiver *SettingController) organizationSettings(context *gin.Context) (*SettingController, bool) {
+	organizationId, ok := organizationQuery(context)
+	if !ok {
+		return nil, false
+	}
+	organizationId, ok = owningOrganization(context, organizationId)
+	if !ok {
+		return nil, false
+	}
+
+	return receiver.ForOrganization(organizationId), true
+}
+
+// globalSettings reports whether the scope may change the settings shared by
+// every organization, it responds with the failure otherwise
+func globalSettings(context *gin.Context) bool {
+	if accessScope(context).AllOrganizations {
+		return true
+	}
+
+	return authorized(context, usecase.ErrOutOfScope)
+}
+
 // Get Settings godoc
 // @Summary      Retrieve settings
 // @Description  Retrieve settings
@@ -34,7 +89,12 @@ type SettingController struct {
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/settings/ [get]
 func (receiver *SettingController) GetSettings(context *gin.Context) {
-	settings, err := receiver.GetSettingsUseCase.GetSettings()
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
+	settings, err := scoped.GetSettingsUseCase.GetSettings()
 	if err != nil || settings == nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -309,6 +369,11 @@ func (receiver *SettingController) GetSettings(context *gin.Context) {
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/settings/output-sheet [post]
 func (receiver *SettingController) UpdateOutputSubmissionSettings(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateOutputSubmissionSettingsRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -317,7 +382,7 @@ func (receiver *SettingController) UpdateOutputSubmissionSettings(context *gin.C
 		})
 		return
 	}
-	err := receiver.UpdateSubmissionSetting(req.FolderUrl, req.SheetName)
+	err := scoped.UpdateSubmissionSetting(req.FolderUrl, req.SheetName)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -345,6 +410,11 @@ func (receiver *SettingController) UpdateOutputSubmissionSettings(context *gin.C
 // @Failure      500  {object}  response.FailedResponse
 // @Router       /v1/admin/settings/output-summary [post]
 func (receiver *SettingController) UpdateOutputSummarySettings(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateOutputSummarySettingsRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -353,7 +423,7 @@ func (receiver *SettingController) UpdateOutputSummarySettings(context *gin.Cont
 		})
 		return
 	}
-	err := receiver.UpdateOutputSummarySetting(req.SpreadsheetUrl)
+	err := scoped.UpdateOutputSummarySetting(req.SpreadsheetUrl)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -382,6 +452,11 @@ func (receiver *SettingController) UpdateOutputSummarySettings(context *gin.Cont
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/email-history [post]
 func (receiver *SettingController) UpdateEmailHistorySettings(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateEmailHistorySettingsRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -390,7 +465,7 @@ func (receiver *SettingController) UpdateEmailHistorySettings(context *gin.Conte
 		})
 		return
 	}
-	err := receiver.UpdateEmailHistorySettingUseCase.Execute(req)
+	err := scoped.UpdateEmailHistorySettingUseCase.Execute(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -419,6 +494,11 @@ func (receiver *SettingController) UpdateEmailHistorySettings(context *gin.Conte
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/output-template [post]
 func (receiver *SettingController) UpdateOutputTemplateSettings(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateOutputTemplateRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -428,7 +508,7 @@ func (receiver *SettingController) UpdateOutputTemplateSettings(context *gin.Con
 		return
 	}
 
-	err := receiver.UpdateOutputTemplateSettingUseCase.Execute(req)
+	err := scoped.UpdateOutputTemplateSettingUseCase.Execute(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -457,6 +537,11 @@ func (receiver *SettingController) UpdateOutputTemplateSettings(context *gin.Con
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/output-template-teacher [post]
 func (receiver *SettingController) UpdateOutputTemplateSettingsForTeacher(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateOutputTemplateRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -465,7 +550,7 @@ func (receiver *SettingController) UpdateOutputTemplateSettingsForTeacher(contex
 		})
 		return
 	}
-	err := receiver.UpdateOutputTemplateSettingForTeacherUseCase.Execute(req)
+	err := scoped.UpdateOutputTemplateSettingForTeacherUseCase.Execute(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -499,6 +584,11 @@ type updateSignUpTextButtonRequest struct {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-1 [post]
 func (receiver *SettingController) UpdateSignUpButton1(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpTextButtonRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -508,7 +598,7 @@ func (receiver *SettingController) UpdateSignUpButton1(context *gin.Context) {
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButton1(req.Name, req.Value)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButton1(req.Name, req.Value)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -538,6 +628,11 @@ func (receiver *SettingController) UpdateSignUpButton1(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-2 [post]
 func (receiver *SettingController) UpdateSignUpButton2(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpTextButtonRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -547,7 +642,7 @@ func (receiver *SettingController) UpdateSignUpButton2(context *gin.Context) {
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButton2(req.Name, req.Value)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButton2(req.Name, req.Value)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -577,6 +672,11 @@ func (receiver *SettingController) UpdateSignUpButton2(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-3 [post]
 func (receiver *SettingController) UpdateSignUpButton3(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpTextButtonRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -586,7 +686,7 @@ func (receiver *SettingController) UpdateSignUpButton3(context *gin.Context) {
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButton3(req.Name, req.Value)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButton3(req.Name, req.Value)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -616,6 +716,11 @@ func (receiver *SettingController) UpdateSignUpButton3(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-4 [post]
 func (receiver *SettingController) UpdateSignUpButton4(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpTextButtonRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -625,7 +730,7 @@ func (receiver *SettingController) UpdateSignUpButton4(context *gin.Context) {
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButton4(req.Name, req.Value)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButton4(req.Name, req.Value)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -655,6 +760,11 @@ func (receiver *SettingController) UpdateSignUpButton4(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-5 [post]
 func (receiver *SettingController) UpdateSignUpButton5(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpTextButtonRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -664,7 +774,7 @@ func (receiver *SettingController) UpdateSignUpButton5(context *gin.Context) {
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButton5(req.Name, req.Value)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButton5(req.Name, req.Value)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -698,6 +808,11 @@ type updateRegistrationSpreadsheetRequest struct {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/registration-form [post]
 func (receiver *SettingController) UpdateRegistrationForm(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateRegistrationSpreadsheetRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -707,7 +822,7 @@ func (receiver *SettingController) UpdateRegistrationForm(context *gin.Context)
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateRegistrationForm(req.SpreadSheetUrl)
+	err := scoped.AdminSignUpUseCases.UpdateRegistrationForm(req.SpreadSheetUrl)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -741,6 +856,11 @@ type updateSignUpButtonConfigurationRequest struct {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/sign-up-button-configuration [post]
 func (receiver *SettingController) UpdateSignUpButtonConfiguration(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateSignUpButtonConfigurationRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -750,7 +870,7 @@ func (receiver *SettingController) UpdateSignUpButtonConfiguration(context *gin.
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateSignUpButtonConfiguration(req.SpreadSheetUrl)
+	err := scoped.AdminSignUpUseCases.UpdateSignUpButtonConfiguration(req.SpreadSheetUrl)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -780,6 +900,11 @@ func (receiver *SettingController) UpdateSignUpButtonConfiguration(context *gin.
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/registration-submission [post]
 func (receiver *SettingController) UpdateRegistrationSubmission(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateRegistrationSpreadsheetRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -789,7 +914,7 @@ func (receiver *SettingController) UpdateRegistrationSubmission(context *gin.Con
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateRegistrationSubmission(req.SpreadSheetUrl)
+	err := scoped.AdminSignUpUseCases.UpdateRegistrationSubmission(req.SpreadSheetUrl)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -823,6 +948,11 @@ type updateRegistrationPresetRequest struct {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/registration-preset-2 [post]
 func (receiver *SettingController) UpdateRegistrationPreset2(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateRegistrationPresetRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -832,7 +962,7 @@ func (receiver *SettingController) UpdateRegistrationPreset2(context *gin.Contex
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateRegistrationPreset2(req.FormNote)
+	err := scoped.AdminSignUpUseCases.UpdateRegistrationPreset2(req.FormNote)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -862,6 +992,11 @@ func (receiver *SettingController) UpdateRegistrationPreset2(context *gin.Contex
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/registration-preset-1 [post]
 func (receiver *SettingController) UpdateRegistrationPreset1(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req updateRegistrationPresetRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -871,7 +1006,7 @@ func (receiver *SettingController) UpdateRegistrationPreset1(context *gin.Contex
 		return
 	}
 
-	err := receiver.AdminSignUpUseCases.UpdateRegistrationPreset1(req.FormNote)
+	err := scoped.AdminSignUpUseCases.UpdateRegistrationPreset1(req.FormNote)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -905,6 +1040,10 @@ type updateDistributerRequest struct {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/api-distributer [post]
 func (receiver *SettingController) UpdateAPIDistributor(context *gin.Context) {
+	if !globalSettings(context) {
+		return
+	}
+
 	var req updateDistributerRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -946,6 +1085,11 @@ func (receiver *SettingController) UpdateAPIDistributor(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/label/name [post]
 func (receiver *SettingController) SetSettingNames(context *gin.Context) {
+	scoped, ok := receiver.organizationSettings(context)
+	if !ok {
+		return
+	}
+
 	var req request.UpdateSettingNameRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -956,7 +1100,7 @@ func (receiver *SettingController) SetSettingNames(context *gin.Context) {
 		return
 	}
 
-	if err := receiver.UpdateSettingNameUseCase.Execute(req); err != nil {
+	if err := scoped.UpdateSettingNameUseCase.Execute(req); err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
 			Error: err.Error(),
@@ -986,6 +1130,10 @@ func (receiver *SettingController) SetSettingNames(context *gin.Context) {
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/code-counting-data [post]
 func (receiver *SettingController) UpdateCodeCountingData(context *gin.Context) {
+	if !globalSettings(context) {
+		return
+	}
+
 	var req request.UpdateCodeCountingSettingRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -1026,6 +1174,10 @@ func (receiver *SettingController) UpdateCodeCountingData(context *gin.Context)
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/admin/settings/logo-refresh-interval [post]
 func (receiver *SettingController) SetupLogoRefreshInterval(context *gin.Context) {
+	if !globalSettings(context) {
+		return
+	}
+
 	var req request.SetupLogoRefreshIntervalRequest
 	if err := context.ShouldBindJSON(&req); err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SubmissionController struct {
+	SubmissionQueryUseCase *usecase.SubmissionQueryUseCase
+}
+
+// Get Submission List godoc
+// @Summary Get submissions
+// @Description Get submissions, newest first, filtered by form, user, device, organization and submission date
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param form_id query int false "Form ID"
+// @Param user_id query string false "User ID"
+// @Param device_id query string false "Device ID, matches the submissions of the users of the device"
+// @Param organization_id query int false "Organization ID, matches the submissions of the members of the organization"
+// @Param from query string false "First submission date, YYYY-MM-DD"
+// @Param to query string false "Last submission date, YYYY-MM-DD"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.SubmissionListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/submissions [get]
+func (receiver *SubmissionController) GetSubmissions(context *gin.Context) {
+	var req request.GetSubmissionListRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+
+	submissions, paging, err := receiver.SubmissionQueryUseCase.GetSubmissions(req)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, response.FailedResponse{
+			Code:  http.StatusInternalServerError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SubmissionListResponse{Data: submissions, Paging: *paging})
+}
+
+// Export Submissions godoc
+// @Summary Export the submissions of a form
+// @Description Stream the submissions of a form as CSV, XLSX or NDJSON with one column per question. XLSX exports take their styles from the output templates.
+// @Tags Admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Form ID"
+// @Param format query string false "csv (default), xlsx or ndjson"
+// @Param template query string false "output (default) or teacher, the xlsx template"
+// @Param user_id query string false "User ID"
+// @Param device_id query string false "Device ID"
+// @Param organization_id query int false "Organization ID"
+// @Param from query string false "First submission date, YYYY-MM-DD"
+// @Param to query string false "Last submission date, YYYY-MM-DD"
+// @Success 200 {file} file
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/form/{id}/submissions/export [get]
+func (receiver *SubmissionController) ExportSubmissions(context *gin.Context) {
+	formId, ok := formIdParam(context)
+	if !ok {
+		return
+	}
+
+	var req request.ExportSubmissionsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	export, err := receiver.SubmissionQueryUseCase.PrepareExport(formId, req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, usecase.ErrInvalidSubmissionExport):
+			code = http.StatusBadRequest
+		}
+		context.JSON(code, response.FailedResponse{
+			Code:  code,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	context.Header("Content-Type", export.ContentType())
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName()))
+	context.Status(http.StatusOK)
+
+	// The status is sent with the first batch, a failure past that point can
+	// only cut the stream short
+	if err = export.Write(context.Writer); err != nil {
+		log.Error("SubmissionController.ExportSubmissions ", err)
+		_ = context.Error(err)
+	}
+}
@@ -1,13 +1,14 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"sen-global-api/config"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/usecase"
 	"sen-global-api/internal/domain/value"
-	"sen-global-api/pkg/sheet"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -24,15 +25,24 @@ type ToDoController struct {
 
 type getTodoByQRCodeParams struct {
 	QRCode string `form:"qr_code" binding:"required"`
+	// DeviceID shows the tasks assigned to the device and their progress, the
+	// device of the token when it is not given
+	DeviceID string `form:"device_id"`
 }
 
 type task struct {
-	Index     int    `json:"index" binding:"required"`
-	Name      string `json:"name" binding:"required"`
-	DueDate   string `json:"due_date" binding:"required"`
-	Value     string `json:"value" binding:"required"`
-	Selection string `json:"selection" binding:"required"`
-	Selected  string `json:"selected" binding:"required"`
+	Index       int        `json:"index" binding:"required"`
+	Name        string     `json:"name" binding:"required"`
+	DueDate     string     `json:"due_date" binding:"required"`
+	Value       string     `json:"value" binding:"required"`
+	Selection   string     `json:"selection" binding:"required"`
+	Selected    string     `json:"selected" binding:"required"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Overdue     bool       `json:"overdue"`
+	// Recurrence is set on a recurring task, its next occurrence is added when
+	// it is done
+	Recurrence *value.ToDoRecurrence `json:"recurrence,omitempty"`
 }
 
 type toDoResponseData struct {
@@ -52,6 +62,7 @@ type toDoResponse struct {
 // @Accept  json
 // @Produce  json
 // @Param qr_code query string true "QR Code"
+// @Param device_id query string false "Device ID, the device of the token by default"
 // @Param Authorization header string true "Bearer {token}"
 // @Success 200 {object} toDoResponse
 // @Failure 400 {object} response.FailedResponse
@@ -64,7 +75,14 @@ func (c *ToDoController) GetToDoListByQRCode(context *gin.Context) {
 		return
 	}
 
-	todoList, err := c.getToDoListByQRCodeUseCase.Execute(params.QRCode)
+	deviceId := params.DeviceID
+	if deviceId == "" {
+		if device, err := c.findDeviceFromRequestCase.FindDevice(context); err == nil && device != nil {
+			deviceId = device.ID
+		}
+	}
+
+	todoList, err := c.getToDoListByQRCodeUseCase.Execute(params.QRCode, deviceId)
 	if err != nil {
 		context.JSON(500, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -73,15 +91,20 @@ func (c *ToDoController) GetToDoListByQRCode(context *gin.Context) {
 		return
 	}
 
+	now := time.Now()
 	tasks := make([]task, 0)
 	for _, t := range todoList.Tasks.Data.Tasks {
 		tasks = append(tasks, task{
-			Index:     t.Index,
-			Name:      t.Name,
-			DueDate:   t.DueDate,
-			Value:     t.Value,
-			Selection: t.Selection,
-			Selected:  t.Selected,
+			Index:       t.Index,
+			Name:        t.Name,
+			DueDate:     t.DueDate,
+			Value:       t.Value,
+			Selection:   t.Selection,
+			Selected:    t.Selected,
+			CompletedAt: t.CompletedAt,
+			DueAt:       t.DueAt,
+			Overdue:     t.IsOverdue(now),
+			Recurrence:  t.Recurrence,
 		})
 	}
 
@@ -111,6 +134,8 @@ type markToDoAsDoneRequest struct {
 // @Param req body markToDoAsDoneRequest true "Mark ToDo As Done Request"
 // @Success 200 {object} response.SucceedResponse
 // @Failure 400 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
 // @Failure 500 {object} response.FailedResponse
 // @Router /v1/todo [post]
 func (c *ToDoController) MarkToDoAsDone(context *gin.Context) {
@@ -142,6 +167,10 @@ func (c *ToDoController) MarkToDoAsDone(context *gin.Context) {
 	}
 
 	err = c.markToDoAsDoneUseCase.Execute(*device, req.QRCode, req.TaskIndex, req.Select)
+	if errors.Is(err, usecase.ErrToDoTaskNotFound) || errors.Is(err, usecase.ErrToDoNotAssigned) {
+		toDoFailure(context, err)
+		return
+	}
 	if err != nil {
 		context.JSON(500, response.FailedResponse{
 			Code:  http.StatusInternalServerError,
@@ -156,12 +185,12 @@ func (c *ToDoController) MarkToDoAsDone(context *gin.Context) {
 	})
 }
 
-func NewToDoController(cfg config.AppConfig, dbConn *gorm.DB, reader *sheet.Reader, writer *sheet.Writer) *ToDoController {
+func NewToDoController(cfg config.AppConfig, dbConn *gorm.DB) *ToDoController {
 	return &ToDoController{
-		getToDoListByQRCodeUseCase: usecase.NewGetToDoListByQRCodeUseCase(cfg, dbConn, reader),
+		getToDoListByQRCodeUseCase: usecase.NewGetToDoListByQRCodeUseCase(dbConn),
 		findDeviceFromRequestCase:  usecase.NewFindDeviceFromRequestCase(cfg, dbConn),
-		markToDoAsDoneUseCase:      usecase.NewMarkToDoAsDoneUseCase(cfg, dbConn, reader, writer),
-		updateToDoTasksUseCase:     usecase.NewUpdateToDoTasksUseCase(cfg, dbConn, reader, writer),
+		markToDoAsDoneUseCase:      usecase.NewMarkToDoAsDoneUseCase(dbConn),
+		updateToDoTasksUseCase:     usecase.NewUpdateToDoTasksUseCase(dbConn),
 		findTodoByIdUseCase:        usecase.NewFindTodoByIdUseCase(dbConn),
 		getDeviceByIdUseCase:       usecase.NewGetDeviceByIdUseCase(dbConn),
 	}
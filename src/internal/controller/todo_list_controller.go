@@ -0,0 +1,584 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ToDoListController manages the to-do lists kept in the database from the
+// admin API
+type ToDoListController struct {
+	ToDoUseCase   *usecase.ToDoUseCase
+	AccessControl *usecase.AccessControlUseCase
+}
+
+// Get ToDo Lists godoc
+// @Summary Get to-do lists
+// @Description Get the to-do lists with their tasks, by QR code
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param keyword query string false "Part of the QR code or of the name"
+// @Param organization_id query int false "Organization ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} response.ToDoListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo [get]
+func (receiver *ToDoListController) GetToDos(context *gin.Context) {
+	var req request.GetToDoListRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.OrganizationId != nil {
+		if _, ok := owningOrganization(context, req.OrganizationId); !ok {
+			return
+		}
+	}
+
+	todos, paging, err := receiver.ToDoUseCase.GetToDos(accessScope(context), req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	data := make([]response.ToDoResponseData, 0, len(todos))
+	for _, todo := range todos {
+		data = append(data, toToDoResponse(todo))
+	}
+	context.JSON(http.StatusOK, response.ToDoListResponse{Data: data, Paging: *paging})
+}
+
+// Create ToDo List godoc
+// @Summary Create a to-do list
+// @Description Create a to-do list kept in the database, the id is its QR code. The spreadsheets are optional, the tasks are mirrored to them in the background.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.CreateToDoRequest true "Create ToDo Request"
+// @Success 200 {object} response.ToDoResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 409 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo [post]
+func (receiver *ToDoListController) CreateToDo(context *gin.Context) {
+	var req request.CreateToDoRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	organizationId, ok := owningOrganization(context, req.OrganizationId)
+	if !ok {
+		return
+	}
+
+	todo, err := receiver.ToDoUseCase.CreateToDo(organizationId, req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.create", *todo, map[string]interface{}{
+		"name":  todo.Name,
+		"type":  todo.Type,
+		"tasks": len(todo.Tasks.Data.Tasks),
+	})
+
+	context.JSON(http.StatusOK, response.ToDoResponse{Data: toToDoResponse(*todo)})
+}
+
+// Get ToDo List godoc
+// @Summary Get a to-do list
+// @Description Get a to-do list with its tasks and their completion
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Success 200 {object} response.ToDoResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id} [get]
+func (receiver *ToDoListController) GetToDo(context *gin.Context) {
+	todo, err := receiver.ToDoUseCase.GetToDo(accessScope(context), context.Param("id"))
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.ToDoResponse{Data: toToDoResponse(*todo)})
+}
+
+// Update ToDo List godoc
+// @Summary Update a to-do list
+// @Description Rename a to-do list or turn the mirroring of its changes to the spreadsheets off and on
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param request body request.UpdateToDoRequest true "Update ToDo Request"
+// @Success 200 {object} response.ToDoResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id} [put]
+func (receiver *ToDoListController) UpdateToDo(context *gin.Context) {
+	var req request.UpdateToDoRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	todo, err := receiver.ToDoUseCase.UpdateToDo(accessScope(context), context.Param("id"), req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.update", *todo, req)
+
+	context.JSON(http.StatusOK, response.ToDoResponse{Data: toToDoResponse(*todo)})
+}
+
+// Delete ToDo List godoc
+// @Summary Delete a to-do list
+// @Description Delete a to-do list and its changes not mirrored yet, the completions of its tasks are kept
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id} [delete]
+func (receiver *ToDoListController) DeleteToDo(context *gin.Context) {
+	todo, err := receiver.ToDoUseCase.DeleteToDo(accessScope(context), context.Param("id"))
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.delete", *todo, map[string]interface{}{
+		"name": todo.Name,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "ToDo deleted",
+	})
+}
+
+// Add ToDo Task godoc
+// @Summary Add a task to a to-do list
+// @Description Add a task at the end of a to-do list, it gets the next index. A recurring task gets its next occurrence added when it is done, a task with remind_before gets a reminder sent to the devices of the assignees of the list that many minutes before it is due.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param request body request.SaveToDoTask true "Task"
+// @Success 200 {object} response.ToDoTaskResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/tasks [post]
+func (receiver *ToDoListController) AddTask(context *gin.Context) {
+	var req request.SaveToDoTask
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	todo, task, err := receiver.ToDoUseCase.AddTask(accessScope(context), context.Param("id"), req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.task.create", *todo, task)
+
+	context.JSON(http.StatusOK, response.ToDoTaskResponse{Data: toToDoTaskResponse(*task)})
+}
+
+// Update ToDo Task godoc
+// @Summary Update a task of a to-do list
+// @Description Change the name, due date, value, selection, recurrence and reminder of a task, its completion is kept and its reminder is sent again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param index path int true "Task index"
+// @Param request body request.SaveToDoTask true "Task"
+// @Success 200 {object} response.ToDoTaskResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/tasks/{index} [put]
+func (receiver *ToDoListController) UpdateTask(context *gin.Context) {
+	index, ok := taskIndexParam(context)
+	if !ok {
+		return
+	}
+	var req request.SaveToDoTask
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	todo, task, err := receiver.ToDoUseCase.UpdateTask(accessScope(context), context.Param("id"), index, req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.task.update", *todo, task)
+
+	context.JSON(http.StatusOK, response.ToDoTaskResponse{Data: toToDoTaskResponse(*task)})
+}
+
+// Delete ToDo Task godoc
+// @Summary Delete a task of a to-do list
+// @Description Delete a task of a to-do list, the indexes of the other tasks do not change
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param index path int true "Task index"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/tasks/{index} [delete]
+func (receiver *ToDoListController) DeleteTask(context *gin.Context) {
+	index, ok := taskIndexParam(context)
+	if !ok {
+		return
+	}
+
+	todo, err := receiver.ToDoUseCase.DeleteTask(accessScope(context), context.Param("id"), index)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.task.delete", *todo, map[string]interface{}{
+		"index": index,
+	})
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "ToDo task deleted",
+	})
+}
+
+// Get ToDo Sheet Syncs godoc
+// @Summary Get the sheet syncs of a to-do list
+// @Description Get the changes of a to-do list mirrored or waiting to be mirrored to its spreadsheets, newest first
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param status query string false "pending, syncing, succeeded or failed"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} response.ToDoSheetSyncListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/sheet-syncs [get]
+func (receiver *ToDoListController) GetSheetSyncs(context *gin.Context) {
+	var req request.GetToDoSheetSyncsRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	syncs, paging, err := receiver.ToDoUseCase.GetSheetSyncs(accessScope(context), context.Param("id"), req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	data := make([]response.ToDoSheetSyncResponseData, 0, len(syncs))
+	for _, sync := range syncs {
+		data = append(data, toToDoSheetSyncResponse(sync))
+	}
+	context.JSON(http.StatusOK, response.ToDoSheetSyncListResponse{Data: data, Paging: *paging})
+}
+
+// Retry ToDo Sheet Sync godoc
+// @Summary Retry a failed sheet sync of a to-do list
+// @Description Put a sheet sync that gave up back in the queue and try it right away
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param sync_id path int true "Sheet sync ID"
+// @Success 200 {object} response.ToDoSheetSyncResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/sheet-syncs/{sync_id}/retry [post]
+func (receiver *ToDoListController) RetrySheetSync(context *gin.Context) {
+	syncId, ok := uintParam(context, "sync_id")
+	if !ok {
+		return
+	}
+
+	sync, err := receiver.ToDoUseCase.RetrySheetSync(accessScope(context), context.Param("id"), syncId)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.ToDoSheetSyncResponse{Data: toToDoSheetSyncResponse(*sync)})
+}
+
+// Get ToDo Assignees godoc
+// @Summary Get the assignees of a to-do list
+// @Description Get the users, devices and device groups a to-do list is assigned to, the reminders of its tasks go to their devices
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Success 200 {object} response.ToDoAssigneeListResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/assignees [get]
+func (receiver *ToDoListController) GetAssignees(context *gin.Context) {
+	assignees, err := receiver.ToDoUseCase.GetAssignees(accessScope(context), context.Param("id"))
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.ToDoAssigneeListResponse{Data: toToDoAssigneeResponses(assignees)})
+}
+
+// Replace ToDo Assignees godoc
+// @Summary Assign a to-do list
+// @Description Assign a to-do list to users, devices and device groups, the assignees not given are removed
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Param request body request.ReplaceToDoAssigneesRequest true "Assignees"
+// @Success 200 {object} response.ToDoAssigneeListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/assignees [put]
+func (receiver *ToDoListController) ReplaceAssignees(context *gin.Context) {
+	var req request.ReplaceToDoAssigneesRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	todo, assignees, err := receiver.ToDoUseCase.ReplaceAssignees(accessScope(context), context.Param("id"), req)
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	receiver.audit(context, "todo.assign", *todo, req)
+
+	context.JSON(http.StatusOK, response.ToDoAssigneeListResponse{Data: toToDoAssigneeResponses(assignees)})
+}
+
+// Get ToDo Progress godoc
+// @Summary Get the completion matrix of a to-do list
+// @Description Get where each assignee of a to-do list is with each task, the users and the devices of the groups have a row of their own
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path string true "ToDo ID"
+// @Success 200 {object} response.ToDoProgressResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/todo/{id}/progress [get]
+func (receiver *ToDoListController) GetProgress(context *gin.Context) {
+	progress, err := receiver.ToDoUseCase.GetProgress(accessScope(context), context.Param("id"))
+	if err != nil {
+		toDoFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.ToDoProgressResponse{Data: *progress})
+}
+
+func (receiver *ToDoListController) audit(context *gin.Context, action string, todo entity.SToDo, details interface{}) {
+	organizationId := int64(0)
+	if todo.OrganizationId != nil {
+		organizationId = *todo.OrganizationId
+	}
+	receiver.AccessControl.Audit(accessScope(context), context.ClientIP(), action, organizationId, "todo", todo.ID, details)
+}
+
+func taskIndexParam(context *gin.Context) (int, bool) {
+	index, err := strconv.Atoi(context.Param("index"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return 0, false
+	}
+
+	return index, true
+}
+
+func toDoFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, usecase.ErrToDoTaskNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidToDo), errors.Is(err, usecase.ErrInvalidToDoSheetSync):
+		code = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrOutOfScope), errors.Is(err, usecase.ErrToDoNotAssigned):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrToDoExists):
+		code = http.StatusConflict
+	}
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+func toToDoResponse(todo entity.SToDo) response.ToDoResponseData {
+	tasks := make([]response.ToDoTaskResponseData, 0, len(todo.Tasks.Data.Tasks))
+	for _, task := range todo.Tasks.Data.Tasks {
+		tasks = append(tasks, toToDoTaskResponse(task))
+	}
+
+	return response.ToDoResponseData{
+		Id:                   todo.ID,
+		OrganizationId:       todo.OrganizationId,
+		Name:                 todo.Name,
+		Type:                 string(todo.Type),
+		SpreadsheetId:        todo.SpreadsheetID,
+		SheetName:            todo.SheetName,
+		HistorySpreadsheetId: todo.HistorySpreadsheetID,
+		HistorySheetName:     todo.HistorySheetName,
+		SheetSyncDisabled:    todo.SheetSyncDisabled,
+		TasksEditedAt:        todo.TasksEditedAt,
+		Tasks:                tasks,
+		CreatedAt:            todo.CreatedAt,
+		UpdatedAt:            todo.UpdatedAt,
+	}
+}
+
+func toToDoTaskResponse(task entity.Task) response.ToDoTaskResponseData {
+	assignees := make([]response.ToDoTaskAssigneeData, 0, len(task.Assignees))
+	for _, assignee := range task.Assignees {
+		assignees = append(assignees, response.ToDoTaskAssigneeData{Type: string(assignee.Type), Id: assignee.Id})
+	}
+
+	return response.ToDoTaskResponseData{
+		Index:        task.Index,
+		Name:         task.Name,
+		DueDate:      task.DueDate,
+		DueAt:        task.DueAt,
+		Overdue:      task.IsOverdue(time.Now()),
+		Value:        task.Value,
+		Selection:    task.Selection,
+		Selected:     task.Selected,
+		CompletedAt:  task.CompletedAt,
+		CompletedBy:  task.CompletedBy,
+		Recurrence:   task.Recurrence,
+		Occurrence:   task.Occurrence,
+		NextIndex:    task.NextIndex,
+		RemindBefore: task.RemindBefore,
+		RemindedAt:   task.RemindedAt,
+		Assignees:    assignees,
+	}
+}
+
+func toToDoAssigneeResponses(assignees []entity.SToDoAssignee) []response.ToDoAssigneeResponseData {
+	data := make([]response.ToDoAssigneeResponseData, 0, len(assignees))
+	for _, assignee := range assignees {
+		data = append(data, response.ToDoAssigneeResponseData{
+			Type:      string(assignee.AssigneeType),
+			Id:        assignee.AssigneeId,
+			CreatedAt: assignee.CreatedAt,
+		})
+	}
+
+	return data
+}
+
+func toToDoSheetSyncResponse(sync entity.SToDoSheetSync) response.ToDoSheetSyncResponseData {
+	return response.ToDoSheetSyncResponseData{
+		Id:            sync.ID,
+		ToDoId:        sync.ToDoId,
+		Kind:          string(sync.Kind),
+		Status:        string(sync.Status),
+		Attempts:      sync.Attempts,
+		NextAttemptAt: sync.NextAttemptAt,
+		LastError:     sync.LastError,
+		SyncedAt:      sync.SyncedAt,
+		CreatedAt:     sync.CreatedAt,
+	}
+}
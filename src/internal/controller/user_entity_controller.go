@@ -19,6 +19,7 @@ type UserEntityController struct {
 	*usecase.UpdateUserEntityUseCase
 	*usecase.UpdateUserRoleUseCase
 	*usecase.AuthorizeUseCase
+	AccessControl *usecase.AccessControlUseCase
 }
 
 func (receiver *UserEntityController) GetAllUserEntity(context *gin.Context) {
@@ -42,6 +43,10 @@ func (receiver *UserEntityController) GetAllUserEntity(context *gin.Context) {
 
 		return
 	}
+	users, err = receiver.AccessControl.FilterUsers(accessScope(context), users)
+	if !authorized(context, err) {
+		return
+	}
 
 	var userResponse []response.UserEntityResponseData
 	for _, user := range users {
@@ -84,6 +89,10 @@ func (receiver *UserEntityController) GetChildrenOfGuardian(context *gin.Context
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeUser(accessScope(context), userId); !authorized(context, err) {
+		return
+	}
+
 	users, err := receiver.GetUserEntityUseCase.GetChildrenOfGuardian(userId)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -111,6 +120,10 @@ func (receiver *UserEntityController) GetUserEntityById(context *gin.Context) {
 		return
 	}
 
+	if _, err := receiver.AccessControl.AuthorizeUser(accessScope(context), userId); !authorized(context, err) {
+		return
+	}
+
 	userEntity, err := receiver.GetUserById(request.GetUserEntityByIdRequest{ID: userId})
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, response.FailedResponse{
@@ -196,6 +209,9 @@ func (receiver *UserEntityController) GetUserEntityByName(context *gin.Context)
 
 		return
 	}
+	if _, err := receiver.AccessControl.AuthorizeUser(accessScope(context), userEntity.ID.String()); !authorized(context, err) {
+		return
+	}
 
 	var roleListResponse []response.RoleListResponseData
 	if len(userEntity.Roles) > 0 {
@@ -277,6 +293,15 @@ func (receiver *UserEntityController) CreateUserEntity(context *gin.Context) {
 		return
 	}
 
+	// Signing up is open, assigning roles on sign up takes the permission to
+	// manage the organizations of the roles
+	scope := accessScope(context)
+	if req.Roles != nil && len(*req.Roles) > 0 {
+		if _, err := receiver.AccessControl.ScopeUserRoleNames(scope, "", *req.Roles); !authorized(context, err) {
+			return
+		}
+	}
+
 	err := receiver.CreateUserEntityUseCase.CreateUserEntity(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
@@ -285,11 +310,19 @@ func (receiver *UserEntityController) CreateUserEntity(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "user.create", 0, "user", req.Username, map[string]interface{}{
+		"username":    req.Username,
+		"roles":       req.Roles,
+		"guardians":   req.Guardians,
+		"device_uuid": req.DeviceUUID,
+	})
 
 	data, err := receiver.UserLoginUsecase(request.UserLoginFromDeviceReqest{
 		Username:   req.Username,
 		Password:   req.Password,
 		DeviceUUID: req.DeviceUUID,
+		UserAgent:  context.Request.UserAgent(),
+		IpAddress:  context.ClientIP(),
 	})
 
 	if err != nil {
@@ -338,7 +371,20 @@ func (receiver *UserEntityController) UpdateUserEntity(context *gin.Context) {
 		return
 	}
 
-	err := receiver.UpdateUserEntityUseCase.UpdateUserEntity(req)
+	scope := accessScope(context)
+	organizationIds, err := receiver.AccessControl.AuthorizeUser(scope, req.ID)
+	if !authorized(context, err) {
+		return
+	}
+	if req.Roles != nil {
+		roles, err := receiver.AccessControl.ScopeUserRoleNames(scope, req.ID, *req.Roles)
+		if !authorized(context, err) {
+			return
+		}
+		req.Roles = &roles
+	}
+
+	err = receiver.UpdateUserEntityUseCase.UpdateUserEntity(req)
 	if err != nil {
 		log.Error(err)
 
@@ -349,6 +395,8 @@ func (receiver *UserEntityController) UpdateUserEntity(context *gin.Context) {
 		return
 	}
 
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "user.update", firstOrganization(organizationIds), "user", req.ID, req)
+
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
 		Message: "user was update successfully",
@@ -365,7 +413,18 @@ func (receiver *UserEntityController) UpdateUserRole(context *gin.Context) {
 		return
 	}
 
-	err := receiver.UpdateUserRoleUseCase.UpdateUserRole(req)
+	scope := accessScope(context)
+	organizationIds, err := receiver.AccessControl.AuthorizeUser(scope, req.UserId)
+	if !authorized(context, err) {
+		return
+	}
+	requested := req.Roles
+	req.Roles, err = receiver.AccessControl.ScopeUserRoles(scope, req.UserId, req.Roles)
+	if !authorized(context, err) {
+		return
+	}
+
+	err = receiver.UpdateUserRoleUseCase.UpdateUserRole(req)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, response.FailedResponse{
 			Code:  http.StatusBadRequest,
@@ -373,9 +432,20 @@ func (receiver *UserEntityController) UpdateUserRole(context *gin.Context) {
 		})
 		return
 	}
+	receiver.AccessControl.Audit(scope, context.ClientIP(), "user.role_update", firstOrganization(organizationIds), "user", req.UserId, map[string]interface{}{
+		"roles": requested,
+	})
 
 	context.JSON(http.StatusOK, response.SucceedResponse{
 		Code:    http.StatusOK,
 		Message: "user role was updated successfully",
 	})
 }
+
+func firstOrganization(organizationIds []int64) int64 {
+	if len(organizationIds) == 0 {
+		return 0
+	}
+
+	return organizationIds[0]
+}
@@ -0,0 +1,401 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookController struct {
+	WebhookUseCase *usecase.WebhookUseCase
+}
+
+// Get Webhooks godoc
+// @Summary Get webhooks
+// @Description Get every registered webhook with the events it subscribes to
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} response.WebhookListResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks [get]
+func (receiver *WebhookController) GetWebhooks(context *gin.Context) {
+	webhooks, err := receiver.WebhookUseCase.GetWebhooks()
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	data := make([]response.WebhookResponseData, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		data = append(data, toWebhookResponse(webhook))
+	}
+
+	context.JSON(http.StatusOK, response.WebhookListResponse{Data: data})
+}
+
+// Get Webhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.WebhookResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks/{id} [get]
+func (receiver *WebhookController) GetWebhook(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := receiver.WebhookUseCase.GetWebhook(id)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookResponse{Data: toWebhookResponse(*webhook)})
+}
+
+// Create Webhook godoc
+// @Summary Register a webhook
+// @Description Register a webhook for events such as form.submitted, todo.task_completed, device.status_changed, code_counting.incremented and redirect_url.scanned, or * for all of them. A secret is generated when none is given. The secret is only returned here and by the secret rotation.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body request.SaveWebhookRequest true "Save Webhook Request"
+// @Success 200 {object} response.WebhookResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks [post]
+func (receiver *WebhookController) CreateWebhook(context *gin.Context) {
+	var req request.SaveWebhookRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	webhook, err := receiver.WebhookUseCase.CreateWebhook(req)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookResponse{Data: toWebhookResponseWithSecret(*webhook)})
+}
+
+// Update Webhook godoc
+// @Summary Update a webhook
+// @Description Update a webhook, the secret is kept when none is given and is not returned
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Webhook ID"
+// @Param request body request.SaveWebhookRequest true "Save Webhook Request"
+// @Success 200 {object} response.WebhookResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks/{id} [put]
+func (receiver *WebhookController) UpdateWebhook(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	var req request.SaveWebhookRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	webhook, err := receiver.WebhookUseCase.UpdateWebhook(id, req)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookResponse{Data: toWebhookResponse(*webhook)})
+}
+
+// Rotate Webhook Secret godoc
+// @Summary Rotate the secret of a webhook
+// @Description Replace the secret of a webhook with a generated one and return it. Deliveries are signed with the new secret from then on.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.WebhookResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks/{id}/secret [post]
+func (receiver *WebhookController) RotateWebhookSecret(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := receiver.WebhookUseCase.RotateWebhookSecret(id)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookResponse{Data: toWebhookResponseWithSecret(*webhook)})
+}
+
+// Delete Webhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook together with its delivery log
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.SucceedResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks/{id} [delete]
+func (receiver *WebhookController) DeleteWebhook(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	err := receiver.WebhookUseCase.DeleteWebhook(id)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.SucceedResponse{
+		Code:    http.StatusOK,
+		Message: "Webhook deleted",
+	})
+}
+
+// Get Webhook Deliveries godoc
+// @Summary Get the delivery log of a webhook
+// @Description Get the deliveries of a webhook newest first, optionally only those with the given status
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, delivering, succeeded or failed"
+// @Param page query int false "Page, starting at 0"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.WebhookDeliveryListResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhooks/{id}/deliveries [get]
+func (receiver *WebhookController) GetDeliveries(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	var req request.GetWebhookDeliveriesRequest
+	if err := context.ShouldBindQuery(&req); err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return
+	}
+	if req.Page < 0 {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: "invalid page number",
+		})
+		return
+	}
+
+	deliveries, paging, err := receiver.WebhookUseCase.GetDeliveries(id, req)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	data := make([]response.WebhookDeliveryResponseData, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		data = append(data, toWebhookDeliveryResponse(delivery))
+	}
+
+	context.JSON(http.StatusOK, response.WebhookDeliveryListResponse{Data: data, Paging: *paging})
+}
+
+// Get Webhook Delivery godoc
+// @Summary Get a webhook delivery
+// @Description Get a logged webhook delivery with its payload and last response
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} response.WebhookDeliveryResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhook-deliveries/{id} [get]
+func (receiver *WebhookController) GetDelivery(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	delivery, err := receiver.WebhookUseCase.GetDelivery(id)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookDeliveryResponse{Data: toWebhookDeliveryResponse(*delivery)})
+}
+
+// Replay Webhook Delivery godoc
+// @Summary Replay a webhook delivery
+// @Description Send the payload of a logged delivery again, as a new delivery that is tried right away
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} response.WebhookDeliveryResponse
+// @Failure 400 {object} response.FailedResponse
+// @Failure 401 {object} response.FailedResponse
+// @Failure 403 {object} response.FailedResponse
+// @Failure 404 {object} response.FailedResponse
+// @Failure 500 {object} response.FailedResponse
+// @Router /v1/admin/webhook-deliveries/{id}/replay [post]
+func (receiver *WebhookController) ReplayDelivery(context *gin.Context) {
+	id, ok := uintParam(context, "id")
+	if !ok {
+		return
+	}
+
+	delivery, err := receiver.WebhookUseCase.ReplayDelivery(id)
+	if err != nil {
+		webhookFailure(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response.WebhookDeliveryResponse{Data: toWebhookDeliveryResponse(*delivery)})
+}
+
+func uintParam(context *gin.Context, name string) (uint64, bool) {
+	id, err := strconv.ParseUint(context.Param(name), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, response.FailedResponse{
+			Code:  http.StatusBadRequest,
+			Error: err.Error(),
+		})
+		return 0, false
+	}
+
+	return id, true
+}
+
+func webhookFailure(context *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		code = http.StatusBadRequest
+	}
+
+	context.JSON(code, response.FailedResponse{
+		Code:  code,
+		Error: err.Error(),
+	})
+}
+
+// toWebhookResponseWithSecret is the response of the requests that set the
+// secret. Every other response leaves it out.
+func toWebhookResponseWithSecret(webhook entity.SWebhook) response.WebhookResponseData {
+	data := toWebhookResponse(webhook)
+	data.Secret = webhook.Secret
+
+	return data
+}
+
+func toWebhookResponse(webhook entity.SWebhook) response.WebhookResponseData {
+	events := make([]string, 0)
+	for _, event := range usecase.WebhookEventsOf(webhook) {
+		events = append(events, string(event))
+	}
+
+	return response.WebhookResponseData{
+		Id:        webhook.ID,
+		Name:      webhook.Name,
+		Url:       webhook.Url,
+		Events:    events,
+		Status:    value.GetRawStatusValue(webhook.Status),
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery entity.SWebhookDelivery) response.WebhookDeliveryResponseData {
+	return response.WebhookDeliveryResponseData{
+		Id:             delivery.ID,
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.EventId,
+		Event:          string(delivery.Event),
+		Payload:        json.RawMessage(delivery.Payload),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		ReplayOf:       delivery.ReplayOf,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
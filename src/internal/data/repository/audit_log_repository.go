@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func (receiver *AuditLogRepository) CreateAuditLog(auditLog *entity.SAuditLog) error {
+	return receiver.DBConn.Create(auditLog).Error
+}
+
+// GetAuditLogs lists audit records newest first, page is 0-based. When
+// organizationIds is not nil only the records of those organizations are listed.
+func (receiver *AuditLogRepository) GetAuditLogs(req request.GetAuditLogsRequest, organizationIds []int64) ([]entity.SAuditLog, *response.Pagination, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = receiver.DefaultRequestPageSize
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if req.Page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	query := receiver.DBConn.Model(&entity.SAuditLog{})
+	if organizationIds != nil {
+		query = query.Where("organization_id IN ?", organizationIds)
+	}
+	if req.OrganizationId != 0 {
+		query = query.Where("organization_id = ?", req.OrganizationId)
+	}
+	if req.ActorId != "" {
+		query = query.Where("actor_id = ?", req.ActorId)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if req.TargetId != "" {
+		query = query.Where("target_id = ?", req.TargetId)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auditLogs := make([]entity.SAuditLog, 0)
+	err = query.Order("id DESC").Offset(req.Page * limit).Limit(limit).Find(&auditLogs).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return auditLogs, &response.Pagination{
+		Page:      req.Page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
@@ -13,6 +13,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// CodeCountingRepository keeps one counter per token and organization, the
+// counters of the forms without an organization are shared
 type CodeCountingRepository struct {
 }
 
@@ -34,7 +36,13 @@ func (receiver *CodeCountingRepository) FindByToken(token string, db *gorm.DB) (
 	return &codeCounting, err
 }
 
-func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuestion, db *gorm.DB) (string, error) {
+func (receiver *CodeCountingRepository) FindById(id uint, db *gorm.DB) (*entity.SCodeCounting, error) {
+	var codeCounting entity.SCodeCounting
+	err := db.Where("id = ?", id).First(&codeCounting).Error
+	return &codeCounting, err
+}
+
+func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuestion, organizationId *int64, db *gorm.DB) (string, error) {
 	var att response.QuestionAttributes
 	err := json.Unmarshal(question.Attributes, &att)
 	if err != nil {
@@ -43,15 +51,16 @@ func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuest
 	}
 
 	var existing entity.SCodeCounting
-	result := db.Where("token = ? AND deleted_at IS NULL", att.Value).
+	result := ownedBy(db, organizationId).Where("token = ? AND deleted_at IS NULL", att.Value).
 		Order("current_value desc").
 		First(&existing)
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		// insert new
 		codeCounting := entity.SCodeCounting{
-			Token:        att.Value,
-			CurrentValue: 1,
+			OrganizationId: organizationId,
+			Token:          att.Value,
+			CurrentValue:   1,
 		}
 
 		err = db.Create(&codeCounting).Error
@@ -64,8 +73,9 @@ func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuest
 	}
 
 	codeCounting := entity.SCodeCounting{
-		Token:        att.Value,
-		CurrentValue: existing.CurrentValue + 1,
+		OrganizationId: organizationId,
+		Token:          att.Value,
+		CurrentValue:   existing.CurrentValue + 1,
 	}
 
 	err = db.Create(&codeCounting).Error
@@ -75,7 +85,7 @@ func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuest
 	}
 
 	//Remove other records
-	err = db.Where("token = ? AND id != ?", att.Value, codeCounting.ID).Delete(&entity.SCodeCounting{}).Error
+	err = ownedBy(db, organizationId).Where("token = ? AND id != ?", att.Value, codeCounting.ID).Delete(&entity.SCodeCounting{}).Error
 	if err != nil {
 		log.Error(err)
 		return "", err
@@ -84,7 +94,7 @@ func (receiver *CodeCountingRepository) CreateForQuestion(question entity.SQuest
 	return att.Value + strconv.Itoa(codeCounting.CurrentValue), nil
 }
 
-func (receiver *CodeCountingRepository) CreateForQuestionWithID(questionId string, db *gorm.DB) (string, error) {
+func (receiver *CodeCountingRepository) CreateForQuestionWithID(questionId string, organizationId *int64, db *gorm.DB) (string, error) {
 	var q entity.SQuestion
 
 	err := db.Where("question_id = ?", questionId).First(&q).Error
@@ -93,12 +103,12 @@ func (receiver *CodeCountingRepository) CreateForQuestionWithID(questionId strin
 		return "", err
 	}
 
-	return receiver.CreateForQuestion(q, db)
+	return receiver.CreateForQuestion(q, organizationId, db)
 }
 
-func (receiver *CodeCountingRepository) ResetCodeCounting(req request.ResetCodeCountingRequest, db *gorm.DB) error {
+func (receiver *CodeCountingRepository) ResetCodeCounting(req request.ResetCodeCountingRequest, organizationId *int64, db *gorm.DB) error {
 	//Delete all
-	err := db.Where("token = ?", req.Prefix).Delete(&entity.SCodeCounting{}).Error
+	err := ownedBy(db, organizationId).Where("token = ?", req.Prefix).Delete(&entity.SCodeCounting{}).Error
 	if err != nil {
 		log.Error(err)
 		return err
@@ -106,15 +116,16 @@ func (receiver *CodeCountingRepository) ResetCodeCounting(req request.ResetCodeC
 
 	//Insert new
 	codeCounting := entity.SCodeCounting{
-		Token:        req.Prefix,
-		CurrentValue: req.ResetTo,
+		OrganizationId: organizationId,
+		Token:          req.Prefix,
+		CurrentValue:   req.ResetTo,
 	}
 
 	return db.Create(&codeCounting).Error
 }
 
 func (receiver *CodeCountingRepository) GetCodeCountings(conn *gorm.DB, rq request.GetCodeCountingsRequest) ([]entity.SCodeCounting, response.Pagination, error) {
-	//Select records in s_code_counting table where token starts with rq.Prefix and the value is maximum group by token
+	//Select records in s_code_counting table where token starts with rq.Prefix and the value is maximum group by organization and token
 	var result []entity.SCodeCounting
 	var paging response.Pagination
 	if rq.PerPage == 0 {
@@ -131,7 +142,7 @@ func (receiver *CodeCountingRepository) GetCodeCountings(conn *gorm.DB, rq reque
 			Limit(rq.PerPage).
 			Offset(rq.PerPage * (rq.PageNo - 1)).
 			Order("id desc").
-			Group("token").
+			Group("organization_id, token").
 			Find(&result).
 			Error
 		if err != nil {
@@ -139,7 +150,7 @@ func (receiver *CodeCountingRepository) GetCodeCountings(conn *gorm.DB, rq reque
 			return []entity.SCodeCounting{}, paging, err
 		}
 		// Count total records
-		err = conn.Model(&entity.SCodeCounting{}).Where("deleted_at IS NULL").Group("token").Count(&paging.Total).Error
+		err = conn.Model(&entity.SCodeCounting{}).Where("deleted_at IS NULL").Group("organization_id, token").Count(&paging.Total).Error
 		if err != nil {
 			log.Error(err)
 			return []entity.SCodeCounting{}, paging, err
@@ -148,13 +159,13 @@ func (receiver *CodeCountingRepository) GetCodeCountings(conn *gorm.DB, rq reque
 		paging.TotalPage = int(math.Ceil(float64(paging.Total) / float64(rq.PerPage)))
 	} else {
 		err := conn.
-			Limit(rq.PerPage).Offset(rq.PerPage*(rq.PageNo-1)).Where("token like ? AND deleted_at IS NULL", "%"+rq.Keyword+"%").Order("id desc").Group("token").Find(&result).Error
+			Limit(rq.PerPage).Offset(rq.PerPage*(rq.PageNo-1)).Where("token like ? AND deleted_at IS NULL", "%"+rq.Keyword+"%").Order("id desc").Group("organization_id, token").Find(&result).Error
 		if err != nil {
 			log.Error(err)
 			return []entity.SCodeCounting{}, paging, err
 		}
 		// Count total records
-		err = conn.Model(&entity.SCodeCounting{}).Where("token like ? and deleted_at IS NULL", "%"+rq.Keyword+"%").Group("token").Count(&paging.Total).Error
+		err = conn.Model(&entity.SCodeCounting{}).Where("token like ? and deleted_at IS NULL", "%"+rq.Keyword+"%").Group("organization_id, token").Count(&paging.Total).Error
 		if err != nil {
 			log.Error(err)
 			return []entity.SCodeCounting{}, paging, err
@@ -171,18 +182,20 @@ func (receiver *CodeCountingRepository) GetCodeCountings(conn *gorm.DB, rq reque
 
 func (receiver *CodeCountingRepository) UpdateCodeCounting(conn *gorm.DB, rq request.UpdateCodeCountingRequest) error {
 	//Update records in s_code_counting table where token = rq.Prefix
-	var codeCounting entity.SCodeCounting
-	codeCounting.ID = rq.ID
+	codeCounting, err := receiver.FindById(rq.ID, conn)
+	if err != nil {
+		return err
+	}
 	codeCounting.CurrentValue = rq.ResetTo
 
-	err := conn.Exec("UPDATE s_code_counting SET current_value = ? WHERE id = ?", codeCounting.CurrentValue, codeCounting.ID).Error
+	err = conn.Exec("UPDATE s_code_counting SET current_value = ? WHERE id = ?", codeCounting.CurrentValue, codeCounting.ID).Error
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
 	//Delete records in s_code_counting table where token = rq.Prefix except rq.ID
-	err = conn.Where("token = ?", codeCounting.Token).Not("id = ?", rq.ID).Delete(&entity.SCodeCounting{}).Error
+	err = ownedBy(conn, codeCounting.OrganizationId).Where("token = ?", codeCounting.Token).Not("id = ?", rq.ID).Delete(&entity.SCodeCounting{}).Error
 	if err != nil {
 		log.Error(err)
 		return err
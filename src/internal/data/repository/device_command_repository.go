@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DeviceCommandRepository struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func (receiver *DeviceCommandRepository) CreateCommand(command *entity.SDeviceCommand) error {
+	return receiver.DBConn.Create(command).Error
+}
+
+func (receiver *DeviceCommandRepository) GetCommand(id string) (*entity.SDeviceCommand, error) {
+	var command entity.SDeviceCommand
+	err := receiver.DBConn.Where("id = ?", id).First(&command).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &command, nil
+}
+
+// GetCommands lists the commands of a device newest first, page is 0-based
+func (receiver *DeviceCommandRepository) GetCommands(deviceId string, status string, page int, limit int) ([]entity.SDeviceCommand, *response.Pagination, error) {
+	if limit <= 0 {
+		limit = receiver.DefaultRequestPageSize
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	query := receiver.DBConn.Model(&entity.SDeviceCommand{}).Where("device_id = ?", deviceId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commands := make([]entity.SDeviceCommand, 0)
+	err = query.Order("created_at DESC").Offset(page * limit).Limit(limit).Find(&commands).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return commands, &response.Pagination{
+		Page:      page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
+
+// TakeOpenCommands returns the commands of a device that are neither acknowledged,
+// done nor expired, oldest first, and marks the pending ones as delivered
+func (receiver *DeviceCommandRepository) TakeOpenCommands(deviceId string, now time.Time) ([]entity.SDeviceCommand, error) {
+	commands := make([]entity.SDeviceCommand, 0)
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("device_id = ? AND status IN ? AND expires_at > ?", deviceId, []value.DeviceCommandStatus{value.DeviceCommandStatus_Pending, value.DeviceCommandStatus_Delivered}, now).
+			Order("created_at ASC").
+			Find(&commands).Error
+		if err != nil {
+			return err
+		}
+
+		pendingIds := make([]string, 0)
+		for i := range commands {
+			if commands[i].Status == value.DeviceCommandStatus_Pending {
+				pendingIds = append(pendingIds, commands[i].ID)
+				commands[i].Status = value.DeviceCommandStatus_Delivered
+				commands[i].DeliveredAt = &now
+			}
+		}
+		if len(pendingIds) == 0 {
+			return nil
+		}
+
+		return tx.Model(&entity.SDeviceCommand{}).
+			Where("id IN ? AND status = ?", pendingIds, value.DeviceCommandStatus_Pending).
+			Updates(map[string]interface{}{"status": value.DeviceCommandStatus_Delivered, "delivered_at": now, "updated_at": now}).Error
+	})
+
+	return commands, err
+}
+
+// UpdateOpenCommand applies the updates to a command that is still in one of the
+// given statuses and not expired at now. It returns false when the command moved
+// on in the meantime.
+func (receiver *DeviceCommandRepository) UpdateOpenCommand(id string, statuses []value.DeviceCommandStatus, now time.Time, updates map[string]interface{}) (bool, error) {
+	updates["updated_at"] = now
+	result := receiver.DBConn.Model(&entity.SDeviceCommand{}).
+		Where("id = ? AND status IN ? AND expires_at > ?", id, statuses, now).
+		Updates(updates)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (receiver *DeviceCommandRepository) SavePushResult(id string, pushedAt *time.Time, pushError string) error {
+	return receiver.DBConn.Model(&entity.SDeviceCommand{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"pushed_at": pushedAt, "push_error": pushError}).Error
+}
+
+// ExpireCommands moves the open commands whose expiry passed to expired
+func (receiver *DeviceCommandRepository) ExpireCommands(now time.Time) (int64, error) {
+	result := receiver.DBConn.Model(&entity.SDeviceCommand{}).
+		Where("status IN ? AND expires_at <= ?", []value.DeviceCommandStatus{value.DeviceCommandStatus_Pending, value.DeviceCommandStatus_Delivered, value.DeviceCommandStatus_Acknowledged}, now).
+		Updates(map[string]interface{}{"status": value.DeviceCommandStatus_Expired, "updated_at": now})
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceConfigurationRepository struct {
+	DBConn *gorm.DB
+}
+
+// GetConfiguration returns the configuration set at a scope, nil when nothing is set there
+func (receiver *DeviceConfigurationRepository) GetConfiguration(scope value.DeviceConfigurationScope, scopeId string) (*entity.SDeviceConfiguration, error) {
+	configurations := make([]entity.SDeviceConfiguration, 0, 1)
+	err := receiver.DBConn.Where("scope = ? AND scope_id = ?", scope, scopeId).Limit(1).Find(&configurations).Error
+	if err != nil || len(configurations) == 0 {
+		return nil, err
+	}
+
+	return &configurations[0], nil
+}
+
+// SaveConfiguration creates or replaces the configuration of its scope
+func (receiver *DeviceConfigurationRepository) SaveConfiguration(configuration *entity.SDeviceConfiguration) error {
+	return receiver.DBConn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "form_ids", "device_component_values_id", "updated_at"}),
+	}).Create(configuration).Error
+}
+
+func (receiver *DeviceConfigurationRepository) DeleteConfiguration(scope value.DeviceConfigurationScope, scopeId string) error {
+	return receiver.DBConn.Where("scope = ? AND scope_id = ?", scope, scopeId).Delete(&entity.SDeviceConfiguration{}).Error
+}
+
+// GetDeviceOrganizationId returns the organization a device belongs to through
+// its users, the first one when its users are in several organizations
+func (receiver *DeviceConfigurationRepository) GetDeviceOrganizationId(deviceId string) (*int64, error) {
+	var organizationId sql.NullInt64
+	err := receiver.DBConn.Raw("SELECT MIN(suo.organization_id) FROM s_user_devices sud JOIN s_users_organization suo ON suo.user_id = sud.user_id WHERE sud.device_id = ?", deviceId).
+		Scan(&organizationId).Error
+	if err != nil || !organizationId.Valid {
+		return nil, err
+	}
+
+	return &organizationId.Int64, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceEnrollmentRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *DeviceEnrollmentRepository) CreateCode(code *entity.SDeviceEnrollmentCode) error {
+	return receiver.DBConn.Create(code).Error
+}
+
+func (receiver *DeviceEnrollmentRepository) GetCode(id uint64) (*entity.SDeviceEnrollmentCode, error) {
+	var code entity.SDeviceEnrollmentCode
+	err := receiver.DBConn.Where("id = ?", id).First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// GetCodes lists the enrollment codes, only those of an organization when one is
+// given and only those that can still be used when active is set
+func (receiver *DeviceEnrollmentRepository) GetCodes(organizationId *int64, active bool) ([]entity.SDeviceEnrollmentCode, error) {
+	query := receiver.DBConn.Model(&entity.SDeviceEnrollmentCode{})
+	if organizationId != nil {
+		query = query.Where("organization_id = ?", *organizationId)
+	}
+	if active {
+		query = query.Where("revoked_at IS NULL AND uses < max_uses AND expires_at > ?", time.Now())
+	}
+
+	codes := make([]entity.SDeviceEnrollmentCode, 0)
+	err := query.Order("id DESC").Find(&codes).Error
+
+	return codes, err
+}
+
+func (receiver *DeviceEnrollmentRepository) RevokeCode(id uint64) error {
+	result := receiver.DBConn.Model(&entity.SDeviceEnrollmentCode{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RedeemCode takes one use of the enrollment code with the hash and returns the
+// code, gorm.ErrRecordNotFound when there is no such code or it is revoked,
+// expired or used up. The use is taken with a conditional update so concurrent
+// enrollments cannot go over the uses of the code.
+func (receiver *DeviceEnrollmentRepository) RedeemCode(codeHash string) (*entity.SDeviceEnrollmentCode, error) {
+	now := time.Now()
+	result := receiver.DBConn.Model(&entity.SDeviceEnrollmentCode{}).
+		Where("code_hash = ? AND revoked_at IS NULL AND uses < max_uses AND expires_at > ?", codeHash, now).
+		Updates(map[string]interface{}{
+			"uses":       gorm.Expr("uses + 1"),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var code entity.SDeviceEnrollmentCode
+	err := receiver.DBConn.Where("code_hash = ?", codeHash).First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (receiver *DeviceEnrollmentRepository) CreateEnrollment(enrollment *entity.SDeviceEnrollment) error {
+	return receiver.DBConn.Create(enrollment).Error
+}
+
+func (receiver *DeviceEnrollmentRepository) GetEnrollments(codeId uint64) ([]entity.SDeviceEnrollment, error) {
+	enrollments := make([]entity.SDeviceEnrollment, 0)
+	err := receiver.DBConn.Where("code_id = ?", codeId).Order("id ASC").Find(&enrollments).Error
+
+	return enrollments, err
+}
+
+// EnrollDevice binds the device to the organization and the group of the code,
+// puts it in the mode of the code and makes it a device of the user of the code
+func (receiver *DeviceEnrollmentRepository) EnrollDevice(device *entity.SDevice, code entity.SDeviceEnrollmentCode, userId uuid.UUID) error {
+	updates := map[string]interface{}{
+		"organization_id": code.OrganizationId,
+		"updated_at":      time.Now(),
+	}
+	if code.GroupId != nil {
+		updates["group_id"] = *code.GroupId
+	}
+	if code.Mode != nil {
+		updates["status"] = *code.Mode
+	}
+	err := receiver.DBConn.Model(device).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+
+	return receiver.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.SUserDevices{
+		UserId:   userId,
+		DeviceId: device.ID,
+	}).Error
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceGroupRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *DeviceGroupRepository) CreateGroup(group *entity.SDeviceGroup) error {
+	return receiver.DBConn.Create(group).Error
+}
+
+func (receiver *DeviceGroupRepository) UpdateGroup(group *entity.SDeviceGroup) error {
+	return receiver.DBConn.Save(group).Error
+}
+
+// DeleteGroup deletes a group together with its configuration, schedules and
+// overrides, its devices are left without a group
+func (receiver *DeviceGroupRepository) DeleteGroup(id uint64) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&entity.SDeviceGroup{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Model(&entity.SDevice{}).Where("group_id = ?", id).Update("group_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		scopeId := strconv.FormatUint(id, 10)
+		err = tx.Where("scope = ? AND scope_id = ?", value.DeviceConfigurationScope_Group, scopeId).Delete(&entity.SDeviceConfiguration{}).Error
+		if err != nil {
+			return err
+		}
+
+		return (&DeviceScheduleRepository{DBConn: tx}).DeleteScopeSchedules(value.DeviceConfigurationScope_Group, scopeId)
+	})
+}
+
+func (receiver *DeviceGroupRepository) GetGroup(id uint64) (*entity.SDeviceGroup, error) {
+	var group entity.SDeviceGroup
+	err := receiver.DBConn.Where("id = ?", id).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// GetGroups lists the groups by name, only those of an organization when one is given
+func (receiver *DeviceGroupRepository) GetGroups(organizationId *int64) ([]entity.SDeviceGroup, error) {
+	query := receiver.DBConn.Model(&entity.SDeviceGroup{})
+	if organizationId != nil {
+		query = query.Where("organization_id = ?", *organizationId)
+	}
+
+	groups := make([]entity.SDeviceGroup, 0)
+	err := query.Order("name ASC").Find(&groups).Error
+
+	return groups, err
+}
+
+// CountDevices returns the number of devices of each group by group ID
+func (receiver *DeviceGroupRepository) CountDevices(groupIds []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(groupIds))
+	if len(groupIds) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		GroupId uint64
+		Count   int64
+	}
+	err := receiver.DBConn.Model(&entity.SDevice{}).
+		Select("group_id, COUNT(*) AS count").
+		Where("group_id IN ?", groupIds).
+		Group("group_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.GroupId] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (receiver *DeviceGroupRepository) GetGroupDeviceIds(groupId uint64) ([]string, error) {
+	deviceIds := make([]string, 0)
+	err := receiver.DBConn.Model(&entity.SDevice{}).Where("group_id = ?", groupId).Order("id ASC").Pluck("id", &deviceIds).Error
+
+	return deviceIds, err
+}
+
+// SetDevicesGroup moves the devices to a group, or out of their group when
+// groupId is nil. It returns the number of devices found.
+func (receiver *DeviceGroupRepository) SetDevicesGroup(deviceIds []string, groupId *uint64) (int64, error) {
+	result := receiver.DBConn.Model(&entity.SDevice{}).
+		Where("id IN ?", deviceIds).
+		Updates(map[string]interface{}{"group_id": groupId, "updated_at": time.Now()})
+
+	return result.RowsAffected, result.Error
+}
+
+func (receiver *DeviceGroupRepository) GetTags(deviceId string) ([]string, error) {
+	tags := make([]string, 0)
+	err := receiver.DBConn.Model(&entity.SDeviceTag{}).Where("device_id = ?", deviceId).Order("tag ASC").Pluck("tag", &tags).Error
+
+	return tags, err
+}
+
+// ReplaceTags sets the tags of a device to exactly the given ones
+func (receiver *DeviceGroupRepository) ReplaceTags(deviceId string, tags []string) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("device_id = ?", deviceId).Delete(&entity.SDeviceTag{}).Error
+		if err != nil || len(tags) == 0 {
+			return err
+		}
+
+		deviceTags := make([]entity.SDeviceTag, 0, len(tags))
+		for _, tag := range tags {
+			deviceTags = append(deviceTags, entity.SDeviceTag{DeviceId: deviceId, Tag: tag})
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deviceTags).Error
+	})
+}
+
+// GetTagCounts returns every tag in use with the number of devices it is on
+func (receiver *DeviceGroupRepository) GetTagCounts() (map[string]int64, error) {
+	var rows []struct {
+		Tag   string
+		Count int64
+	}
+	err := receiver.DBConn.Model(&entity.SDeviceTag{}).
+		Select("tag, COUNT(*) AS count").
+		Group("tag").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Tag] = row.Count
+	}
+
+	return counts, nil
+}
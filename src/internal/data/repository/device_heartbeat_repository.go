@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DeviceHeartbeatRepository struct {
+	DBConn *gorm.DB
+}
+
+// CreateHeartbeat records a heartbeat and moves the last heartbeat of its device
+// to it. It returns when the device was reported offline, nil when it was not.
+func (receiver *DeviceHeartbeatRepository) CreateHeartbeat(heartbeat *entity.SDeviceHeartbeat) (*time.Time, error) {
+	var offlineAlertedAt *time.Time
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		var device entity.SDevice
+		err := tx.Select("id", "offline_alerted_at").Where("id = ?", heartbeat.DeviceId).First(&device).Error
+		if err != nil {
+			return err
+		}
+		offlineAlertedAt = device.OfflineAlertedAt
+
+		err = tx.Create(heartbeat).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"last_heartbeat_at":  heartbeat.CreatedAt,
+			"offline_alerted_at": nil,
+		}
+		if heartbeat.AppVersion != "" {
+			updates["app_version"] = heartbeat.AppVersion
+		}
+
+		return tx.Model(&entity.SDevice{}).Where("id = ?", heartbeat.DeviceId).Updates(updates).Error
+	})
+
+	return offlineAlertedAt, err
+}
+
+// GetHeartbeats lists the heartbeats of a device newest first, from and to are
+// optional
+func (receiver *DeviceHeartbeatRepository) GetHeartbeats(deviceId string, from *time.Time, to *time.Time, limit int) ([]entity.SDeviceHeartbeat, error) {
+	heartbeats := make([]entity.SDeviceHeartbeat, 0)
+	query := receiver.DBConn.Where("device_id = ?", deviceId)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	err := query.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&heartbeats).Error
+
+	return heartbeats, err
+}
+
+// GetLatestHeartbeats returns the last heartbeat of each of the devices that
+// sent one
+func (receiver *DeviceHeartbeatRepository) GetLatestHeartbeats(deviceIds []string) (map[string]entity.SDeviceHeartbeat, error) {
+	latest := make(map[string]entity.SDeviceHeartbeat, len(deviceIds))
+	if len(deviceIds) == 0 {
+		return latest, nil
+	}
+
+	heartbeats := make([]entity.SDeviceHeartbeat, 0, len(deviceIds))
+	err := receiver.DBConn.
+		Where("id IN (?)", receiver.DBConn.Model(&entity.SDeviceHeartbeat{}).
+			Select("MAX(id)").
+			Where("device_id IN ?", deviceIds).
+			Group("device_id")).
+		Find(&heartbeats).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, heartbeat := range heartbeats {
+		latest[heartbeat.DeviceId] = heartbeat
+	}
+
+	return latest, nil
+}
+
+// GetSilentDevices lists the devices whose last heartbeat is not after the time
+// and that were not reported offline yet. Devices that never sent a heartbeat
+// are left out.
+func (receiver *DeviceHeartbeatRepository) GetSilentDevices(lastHeartbeatBefore time.Time) ([]entity.SDevice, error) {
+	devices := make([]entity.SDevice, 0)
+	err := receiver.DBConn.
+		Where("last_heartbeat_at <= ? AND offline_alerted_at IS NULL", lastHeartbeatBefore).
+		Order("last_heartbeat_at").
+		Find(&devices).Error
+
+	return devices, err
+}
+
+func (receiver *DeviceHeartbeatRepository) MarkOfflineAlerted(deviceIds []string, alertedAt time.Time) error {
+	if len(deviceIds) == 0 {
+		return nil
+	}
+
+	return receiver.DBConn.Model(&entity.SDevice{}).
+		Where("id IN ? AND offline_alerted_at IS NULL", deviceIds).
+		Update("offline_alerted_at", alertedAt).Error
+}
+
+func (receiver *DeviceHeartbeatRepository) DeleteHeartbeatsBefore(before time.Time) (int64, error) {
+	result := receiver.DBConn.Where("created_at < ?", before).Delete(&entity.SDeviceHeartbeat{})
+
+	return result.RowsAffected, result.Error
+}
@@ -7,8 +7,10 @@ import (
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/internal/domain/value"
+	"strings"
 	"time"
 
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -29,7 +31,12 @@ func (receiver *DeviceRepository) FindDeviceById(id string) (*entity.SDevice, er
 	return &device, err
 }
 
-func (receiver *DeviceRepository) GetDeviceList(request request.GetListDeviceRequest) ([]entity.SDevice, *response.Pagination, error) {
+// GetDeviceList lists the devices newest first, page is 1-based. With a
+// presence in the request only the devices of that presence under the
+// thresholds are listed.
+// GetDeviceList lists a page of the devices of organizationIds, see
+// FindDevicesByFilter, every device when it is nil
+func (receiver *DeviceRepository) GetDeviceList(request request.GetListDeviceRequest, organizationIds []int64, thresholds value.DevicePresenceThresholds) ([]entity.SDevice, *response.Pagination, error) {
 	var devices []entity.SDevice
 	limit := receiver.DefaultRequestPageSize
 	if request.Limit != 0 {
@@ -38,44 +45,135 @@ func (receiver *DeviceRepository) GetDeviceList(request request.GetListDeviceReq
 	if request.Page <= 0 {
 		request.Page = 1
 	}
-	var err error
-	var count int64
-	if request.Keyword != "" {
-		err = receiver.DBConn.Raw("SELECT * FROM s_device WHERE device_name LIKE ? OR id LIKE ? AND row_no != ?"+
-			"ORDER BY created_at DESC LIMIT ? OFFSET ?", "%"+request.Keyword+"%", "%"+request.Keyword+"%", 0,
-			limit, (request.Page-1)*limit).
-			Find(&devices).Error
-		if err == nil {
-			err = receiver.DBConn.Model(&entity.SDevice{}).
-				Where("id LIKE ? AND row_no != ?", "%"+request.Keyword+"%", 0).
-				Or("id LIKE ?", "%"+request.Keyword+"%").
-				Count(&count).Error
-		}
-	} else {
-		err = receiver.DBConn.Where("row_no != ?", 0).Order("created_at desc").Limit(limit).Offset((request.Page - 1) * limit).Find(&devices).Error
-		if err == nil {
-			err = receiver.DBConn.Model(&entity.SDevice{}).Count(&count).Error
-		}
-	}
 
-	if int64(request.Page) > count {
-		return []entity.SDevice{}, &response.Pagination{
-			Page:      request.Page,
-			Limit:     limit,
-			TotalPage: int(math.Ceil(float64(count) / float64(limit))),
-			Total:     count,
-		}, errors.New("invalid page number")
+	var count int64
+	query, err := receiver.filterDevices(request, organizationIds, thresholds)
+	if err != nil {
+		return nil, nil, err
 	}
-
+	err = query.Model(&entity.SDevice{}).Count(&count).Error
 	if err != nil {
 		return nil, nil, err
 	}
-	return devices, &response.Pagination{
+
+	pagination := &response.Pagination{
 		Page:      request.Page,
 		Limit:     limit,
 		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
 		Total:     count,
-	}, err
+	}
+	if count > 0 && request.Page > pagination.TotalPage {
+		return []entity.SDevice{}, pagination, errors.New("invalid page number")
+	}
+
+	query, _ = receiver.filterDevices(request, organizationIds, thresholds)
+	err = query.Order("created_at desc").Limit(limit).Offset((request.Page - 1) * limit).Find(&devices).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return devices, pagination, nil
+}
+
+func (receiver *DeviceRepository) filterDevices(request request.GetListDeviceRequest, organizationIds []int64, thresholds value.DevicePresenceThresholds) (*gorm.DB, error) {
+	query := receiver.DBConn.Where("row_no != ?", 0)
+	if organizationIds != nil {
+		query = inOrganizations(query, organizationIds)
+	}
+	if request.Keyword != "" {
+		query = query.Where("device_name LIKE ? OR id LIKE ?", "%"+request.Keyword+"%", "%"+request.Keyword+"%")
+	}
+	if request.Presence == "" {
+		return query, nil
+	}
+
+	presence, err := value.GetDevicePresenceFromString(request.Presence)
+	if err != nil {
+		return nil, err
+	}
+	after, until := thresholds.Bounds(presence, time.Now())
+	if after != nil {
+		query = query.Where("last_heartbeat_at > ?", *after)
+	}
+	if until != nil && presence == value.DevicePresence_Offline {
+		query = query.Where("last_heartbeat_at IS NULL OR last_heartbeat_at <= ?", *until)
+	} else if until != nil {
+		query = query.Where("last_heartbeat_at <= ?", *until)
+	}
+
+	return query, nil
+}
+
+// FindDevicesByFilter returns the devices picked by a bulk filter, at most limit
+// of them. A device belongs to the organization it was enrolled in, or to the
+// organizations of its users. Only the devices of organizationIds are picked,
+// every device when it is nil.
+func (receiver *DeviceRepository) FindDevicesByFilter(filter request.BulkDeviceFilter, organizationIds []int64, limit int) ([]entity.SDevice, error) {
+	query := receiver.DBConn.Model(&entity.SDevice{})
+	if len(filter.DeviceIds) > 0 {
+		query = query.Where("id IN ?", filter.DeviceIds)
+	}
+	if filter.OrganizationId != nil {
+		query = inOrganizations(query, []int64{*filter.OrganizationId})
+	}
+	if organizationIds != nil {
+		query = inOrganizations(query, organizationIds)
+	}
+	if filter.Mode != "" {
+		query = query.Where("status = ?", filter.Mode)
+	}
+	if filter.AppVersion != "" {
+		query = query.Where("app_version = ?", filter.AppVersion)
+	}
+	if filter.NamePattern != "" {
+		pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%").Replace(filter.NamePattern)
+		query = query.Where("device_name LIKE ?", pattern)
+	}
+	if filter.GroupId != nil {
+		query = query.Where("group_id = ?", *filter.GroupId)
+	}
+	if filter.Tag != "" {
+		query = query.Where("id IN (SELECT device_id FROM s_device_tag WHERE tag = ?)", filter.Tag)
+	}
+
+	devices := make([]entity.SDevice, 0)
+	err := query.Order("id ASC").Limit(limit).Find(&devices).Error
+
+	return devices, err
+}
+
+// GetDeviceOrganizationIds returns the organization the device was enrolled in
+// and the organizations of its users
+func (receiver *DeviceRepository) GetDeviceOrganizationIds(deviceId string) ([]int64, error) {
+	var device entity.SDevice
+	err := receiver.DBConn.Select("id", "organization_id").Where("id = ?", deviceId).First(&device).Error
+	if err != nil {
+		return nil, err
+	}
+
+	organizationIds := make([]int64, 0)
+	err = receiver.DBConn.Table("s_user_devices sud").
+		Joins("JOIN s_users_organization suo ON suo.user_id = sud.user_id").
+		Where("sud.device_id = ?", deviceId).
+		Distinct().
+		Pluck("suo.organization_id", &organizationIds).Error
+	if err != nil {
+		return nil, err
+	}
+	if device.OrganizationId != nil && !lo.Contains(organizationIds, *device.OrganizationId) {
+		organizationIds = append(organizationIds, *device.OrganizationId)
+	}
+
+	return organizationIds, nil
+}
+
+// inOrganizations limits a device query to the devices of the organizations
+func inOrganizations(query *gorm.DB, organizationIds []int64) *gorm.DB {
+	if len(organizationIds) == 0 {
+		return query.Where("1 = 0")
+	}
+
+	return query.Where("(organization_id IN ? OR id IN (SELECT sud.device_id FROM s_user_devices sud JOIN s_users_organization suo ON suo.user_id = sud.user_id WHERE suo.organization_id IN ?))", organizationIds, organizationIds)
 }
 
 func (receiver *DeviceRepository) DeactivateDevice(id string, deactivateMessage string) error {
@@ -191,6 +289,8 @@ func (receiver *DeviceRepository) SaveOrUpdateDevices(devices []entity.SDevice)
 					"note",
 					"app_version",
 					"row_no",
+					"device_component_values_id",
+					"updated_at",
 				}),
 			}).Create(&device).Error
 			if err != nil {
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceScheduleRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *DeviceScheduleRepository) CreateSchedule(schedule *entity.SDeviceModeSchedule) error {
+	return receiver.DBConn.Create(schedule).Error
+}
+
+func (receiver *DeviceScheduleRepository) UpdateSchedule(schedule *entity.SDeviceModeSchedule) error {
+	return receiver.DBConn.Save(schedule).Error
+}
+
+func (receiver *DeviceScheduleRepository) DeleteSchedule(id uint64) error {
+	result := receiver.DBConn.Delete(&entity.SDeviceModeSchedule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (receiver *DeviceScheduleRepository) GetSchedule(id uint64) (*entity.SDeviceModeSchedule, error) {
+	var schedule entity.SDeviceModeSchedule
+	err := receiver.DBConn.Where("id = ?", id).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// GetSchedules lists the schedules, only those of a scope when one is given
+func (receiver *DeviceScheduleRepository) GetSchedules(scope value.DeviceConfigurationScope, scopeId string) ([]entity.SDeviceModeSchedule, error) {
+	query := receiver.DBConn.Model(&entity.SDeviceModeSchedule{})
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if scopeId != "" {
+		query = query.Where("scope_id = ?", scopeId)
+	}
+
+	schedules := make([]entity.SDeviceModeSchedule, 0)
+	err := query.Order("id ASC").Find(&schedules).Error
+
+	return schedules, err
+}
+
+func (receiver *DeviceScheduleRepository) GetEnabledSchedules() ([]entity.SDeviceModeSchedule, error) {
+	schedules := make([]entity.SDeviceModeSchedule, 0)
+	err := receiver.DBConn.Where("enabled = ?", true).Order("id ASC").Find(&schedules).Error
+
+	return schedules, err
+}
+
+func (receiver *DeviceScheduleRepository) CreateOverride(override *entity.SDeviceModeOverride) error {
+	return receiver.DBConn.Create(override).Error
+}
+
+func (receiver *DeviceScheduleRepository) DeleteOverride(id uint64) error {
+	result := receiver.DBConn.Delete(&entity.SDeviceModeOverride{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (receiver *DeviceScheduleRepository) GetOverride(id uint64) (*entity.SDeviceModeOverride, error) {
+	var override entity.SDeviceModeOverride
+	err := receiver.DBConn.Where("id = ?", id).First(&override).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &override, nil
+}
+
+// GetOverrides lists the overrides newest first, only those of a scope when one
+// is given and only those not over yet at now when now is given
+func (receiver *DeviceScheduleRepository) GetOverrides(scope value.DeviceConfigurationScope, scopeId string, now *time.Time) ([]entity.SDeviceModeOverride, error) {
+	query := receiver.DBConn.Model(&entity.SDeviceModeOverride{})
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if scopeId != "" {
+		query = query.Where("scope_id = ?", scopeId)
+	}
+	if now != nil {
+		query = query.Where("ends_at > ?", *now)
+	}
+
+	overrides := make([]entity.SDeviceModeOverride, 0)
+	err := query.Order("id DESC").Find(&overrides).Error
+
+	return overrides, err
+}
+
+// GetActiveOverrides returns the overrides running at now
+func (receiver *DeviceScheduleRepository) GetActiveOverrides(now time.Time) ([]entity.SDeviceModeOverride, error) {
+	overrides := make([]entity.SDeviceModeOverride, 0)
+	err := receiver.DBConn.Where("starts_at <= ? AND ends_at > ?", now, now).Order("id ASC").Find(&overrides).Error
+
+	return overrides, err
+}
+
+// DeleteScopeSchedules deletes the schedules and overrides of a scope
+func (receiver *DeviceScheduleRepository) DeleteScopeSchedules(scope value.DeviceConfigurationScope, scopeId string) error {
+	err := receiver.DBConn.Where("scope = ? AND scope_id = ?", scope, scopeId).Delete(&entity.SDeviceModeSchedule{}).Error
+	if err != nil {
+		return err
+	}
+
+	return receiver.DBConn.Where("scope = ? AND scope_id = ?", scope, scopeId).Delete(&entity.SDeviceModeOverride{}).Error
+}
+
+func (receiver *DeviceScheduleRepository) GetScheduledMode(deviceId string) (*entity.SDeviceScheduledMode, error) {
+	modes := make([]entity.SDeviceScheduledMode, 0, 1)
+	err := receiver.DBConn.Where("device_id = ?", deviceId).Limit(1).Find(&modes).Error
+	if err != nil || len(modes) == 0 {
+		return nil, err
+	}
+
+	return &modes[0], nil
+}
+
+func (receiver *DeviceScheduleRepository) GetScheduledModes() ([]entity.SDeviceScheduledMode, error) {
+	modes := make([]entity.SDeviceScheduledMode, 0)
+	err := receiver.DBConn.Find(&modes).Error
+
+	return modes, err
+}
+
+func (receiver *DeviceScheduleRepository) SaveScheduledModes(modes []entity.SDeviceScheduledMode) error {
+	if len(modes) == 0 {
+		return nil
+	}
+
+	return receiver.DBConn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "source", "source_id", "since"}),
+	}).Create(&modes).Error
+}
+
+func (receiver *DeviceScheduleRepository) DeleteScheduledModes(deviceIds []string) error {
+	if len(deviceIds) == 0 {
+		return nil
+	}
+
+	return receiver.DBConn.Where("device_id IN ?", deviceIds).Delete(&entity.SDeviceScheduledMode{}).Error
+}
+
+// GetGroupsDeviceIds returns the IDs of the devices of the groups by group ID
+func (receiver *DeviceScheduleRepository) GetGroupsDeviceIds(groupIds []uint64) (map[uint64][]string, error) {
+	deviceIds := make(map[uint64][]string, len(groupIds))
+	if len(groupIds) == 0 {
+		return deviceIds, nil
+	}
+
+	var rows []struct {
+		ID      string
+		GroupId uint64
+	}
+	err := receiver.DBConn.Model(&entity.SDevice{}).Select("id", "group_id").Where("group_id IN ?", groupIds).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		deviceIds[row.GroupId] = append(deviceIds[row.GroupId], row.ID)
+	}
+
+	return deviceIds, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -81,3 +82,57 @@ func (receiver *FormQuestionRepository) GetFormQuestionsByForm(form entity.SForm
 func (receiver *FormQuestionRepository) DeleteByFormID(formID uint64) error {
 	return receiver.DBConn.Where("form_id = ?", formID).Delete(&entity.SFormQuestion{}).Error
 }
+
+func (receiver *FormQuestionRepository) GetFormQuestion(formID uint64, questionID string) (*entity.SFormQuestion, error) {
+	var formQuestion entity.SFormQuestion
+	err := receiver.DBConn.Where("form_id = ? AND question_id = ?", formID, questionID).First(&formQuestion).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &formQuestion, nil
+}
+
+func (receiver *FormQuestionRepository) UpdateAnswerRequired(formID uint64, questionID string, answerRequired bool) error {
+	return receiver.DBConn.Model(&entity.SFormQuestion{}).
+		Where("form_id = ? AND question_id = ?", formID, questionID).
+		Update("answer_required", answerRequired).Error
+}
+
+// UpdateOrders numbers the questions of a form 1..n following the given question ids
+func (receiver *FormQuestionRepository) UpdateOrders(formID uint64, questionIDs []string) error {
+	for index, questionID := range questionIDs {
+		err := receiver.DBConn.Model(&entity.SFormQuestion{}).
+			Where("form_id = ? AND question_id = ?", formID, questionID).
+			Update("order", index+1).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (receiver *FormQuestionRepository) UpdateRules(formID uint64, questionID string, rules datatypes.JSON) error {
+	return receiver.DBConn.Model(&entity.SFormQuestion{}).
+		Where("form_id = ? AND question_id = ?", formID, questionID).
+		Update("rules", rules).Error
+}
+
+// ReplaceQuestion moves the form question row of questionID over to replacementID
+func (receiver *FormQuestionRepository) ReplaceQuestion(formID uint64, questionID string, replacementID string) error {
+	return receiver.DBConn.Model(&entity.SFormQuestion{}).
+		Where("form_id = ? AND question_id = ?", formID, questionID).
+		Update("question_id", replacementID).Error
+}
+
+func (receiver *FormQuestionRepository) Delete(formID uint64, questionID string) error {
+	return receiver.DBConn.Where("form_id = ? AND question_id = ?", formID, questionID).Delete(&entity.SFormQuestion{}).Error
+}
+
+func (receiver *FormQuestionRepository) CountFormsOfQuestion(questionID string) (int64, error) {
+	var count int64
+	err := receiver.DBConn.Model(&entity.SFormQuestion{}).Where("question_id = ?", questionID).Count(&count).Error
+
+	return count, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/parameters"
@@ -13,12 +14,35 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// FormRepository reads the forms of its scope and the shared forms, the forms
+// it creates belong to OrganizationId
 type FormRepository struct {
 	DBConn                 *gorm.DB
 	DefaultRequestPageSize int
+	OrganizationId         *int64
+}
+
+// ForOrganization returns a repository over the forms of the organization, over
+// every form when organizationId is nil
+func (receiver *FormRepository) ForOrganization(organizationId *int64) *FormRepository {
+	return &FormRepository{
+		DBConn:                 ScopeOrganization(receiver.DBConn, organizationId),
+		DefaultRequestPageSize: receiver.DefaultRequestPageSize,
+		OrganizationId:         organizationId,
+	}
+}
+
+// Scoped returns a repository over the forms of the organizations of the scope
+func (receiver *FormRepository) Scoped(scope value.AccessScope) *FormRepository {
+	return &FormRepository{
+		DBConn:                 ScopeAccess(receiver.DBConn, scope),
+		DefaultRequestPageSize: receiver.DefaultRequestPageSize,
+		OrganizationId:         receiver.OrganizationId,
+	}
 }
 
 func (receiver *FormRepository) Create(form *entity.SForm) (*entity.SForm, error) {
+	form.OrganizationId = receiver.OrganizationId
 	err := receiver.DBConn.Create(form).Error
 	if err != nil {
 		return nil, err
@@ -38,7 +62,13 @@ func (receiver *FormRepository) GetFormById(id uint64) (*entity.SForm, error) {
 }
 
 func (receiver *FormRepository) SaveForm(request parameters.SaveFormParams) (*entity.SForm, error) {
+	err := receiver.checkNote(request.Note)
+	if err != nil {
+		return nil, err
+	}
+
 	form := entity.SForm{
+		OrganizationId: receiver.OrganizationId,
 		Note:           request.Note,
 		Name:           request.Name,
 		SpreadsheetUrl: request.SpreadsheetUrl,
@@ -47,7 +77,7 @@ func (receiver *FormRepository) SaveForm(request parameters.SaveFormParams) (*en
 		Status:         value.Active,
 		SheetName:      request.SheetName,
 	}
-	err := receiver.DBConn.Table("s_form").Clauses(
+	err = receiver.DBConn.Table("s_form").Clauses(
 		clause.OnConflict{
 			Columns: []clause.Column{{Name: "note"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -61,6 +91,25 @@ func (receiver *FormRepository) SaveForm(request parameters.SaveFormParams) (*en
 	return &form, err
 }
 
+// checkNote fails with ErrOtherOrganization when the note of a form is already
+// the note of a form of another organization, the notes are the QR codes the
+// devices scan and stay unique across the organizations
+func (receiver *FormRepository) checkNote(note string) error {
+	var form entity.SForm
+	err := unscoped(receiver.DBConn).Where("note = ?", note).First(&form).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !sameOrganization(form.OrganizationId, receiver.OrganizationId) {
+		return fmt.Errorf("%w: form %s", ErrOtherOrganization, note)
+	}
+
+	return nil
+}
+
 func (receiver *FormRepository) DeleteForm(formId uint64) error {
 	form := entity.SForm{}
 	err := receiver.DBConn.Where("id = ?", formId).First(&form).Error
@@ -153,7 +202,7 @@ func (receiver *FormRepository) GetMatchFormByNote(keyword string) ([]entity.SFo
 }
 
 func (receiver *FormRepository) DeleteFormByNote(note string) error {
-	err := receiver.DBConn.Where("note = ?", note).Delete(&entity.SForm{}).Error
+	err := ownedBy(receiver.DBConn, receiver.OrganizationId).Where("note = ?", note).Delete(&entity.SForm{}).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FormRevisionRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *FormRevisionRepository) Create(revision *entity.SFormRevision) error {
+	return receiver.DBConn.Create(revision).Error
+}
+
+func (receiver *FormRevisionRepository) GetRevisions(formID uint64) ([]entity.SFormRevision, error) {
+	var revisions []entity.SFormRevision
+	err := receiver.DBConn.Where("form_id = ?", formID).Order("revision DESC").Find(&revisions).Error
+
+	return revisions, err
+}
+
+func (receiver *FormRevisionRepository) GetRevision(formID uint64, revision int) (*entity.SFormRevision, error) {
+	var result entity.SFormRevision
+	err := receiver.DBConn.Where("form_id = ? AND revision = ?", formID, revision).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (receiver *FormRevisionRepository) GetPublishedRevision(formID uint64) (*entity.SFormRevision, error) {
+	var result entity.SFormRevision
+	err := receiver.DBConn.Where("form_id = ? AND status = ?", formID, value.FormRevisionStatus_Published).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// NextRevisionNumber locks the form row so concurrent callers number revisions one
+// after the other, call it inside a transaction
+func (receiver *FormRevisionRepository) NextRevisionNumber(formID uint64) (int, error) {
+	var form entity.SForm
+	err := receiver.DBConn.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", formID).First(&form).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var last int
+	err = receiver.DBConn.Model(&entity.SFormRevision{}).
+		Select("COALESCE(MAX(revision), 0)").
+		Where("form_id = ?", formID).
+		Scan(&last).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return last + 1, nil
+}
+
+// UpdateSnapshot rewrites the snapshot of a draft revision
+func (receiver *FormRevisionRepository) UpdateSnapshot(revision *entity.SFormRevision) error {
+	return receiver.DBConn.Model(revision).
+		Where("status = ?", value.FormRevisionStatus_Draft).
+		Update("snapshot", revision.Snapshot).Error
+}
+
+// Publish marks the revision as published and archives the previously published one
+func (receiver *FormRevisionRepository) Publish(revision *entity.SFormRevision) error {
+	err := receiver.DBConn.Model(&entity.SFormRevision{}).
+		Where("form_id = ? AND status = ? AND id <> ?", revision.FormId, value.FormRevisionStatus_Published, revision.ID).
+		Update("status", value.FormRevisionStatus_Archived).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	revision.Status = value.FormRevisionStatus_Published
+	revision.PublishedAt = &now
+
+	return receiver.DBConn.Model(revision).Updates(map[string]interface{}{
+		"status":       revision.Status,
+		"published_at": revision.PublishedAt,
+	}).Error
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GuardianRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *GuardianRepository) CreateLink(link *entity.SGuardianLink) error {
+	return receiver.DBConn.Create(link).Error
+}
+
+func (receiver *GuardianRepository) GetLink(id uint64) (*entity.SGuardianLink, error) {
+	var link entity.SGuardianLink
+	err := receiver.DBConn.Where("id = ?", id).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// GetOpenLink returns the pending or active link between the child and the
+// guardian
+func (receiver *GuardianRepository) GetOpenLink(userId string, guardianId string) (*entity.SGuardianLink, error) {
+	var link entity.SGuardianLink
+	err := receiver.DBConn.
+		Where("user_id = ? AND guardian_id = ? AND status IN ?", userId, guardianId, []value.GuardianLinkStatus{value.GuardianLinkStatus_Pending, value.GuardianLinkStatus_Active}).
+		Order("id DESC").
+		First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// GetLinks lists the links of a child, userId, or of a guardian, guardianId,
+// newest first and only those in the status when one is given
+func (receiver *GuardianRepository) GetLinks(userId string, guardianId string, status *value.GuardianLinkStatus) ([]entity.SGuardianLink, error) {
+	query := receiver.DBConn.Model(&entity.SGuardianLink{})
+	if userId != "" {
+		query = query.Where("user_id = ?", userId)
+	}
+	if guardianId != "" {
+		query = query.Where("guardian_id = ?", guardianId)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	links := make([]entity.SGuardianLink, 0)
+	err := query.Order("id DESC").Find(&links).Error
+
+	return links, err
+}
+
+// ConfirmLink makes a pending link active and the guardian an active guardian of
+// the child, gorm.ErrRecordNotFound when the link is not pending anymore
+func (receiver *GuardianRepository) ConfirmLink(link entity.SGuardianLink) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.SGuardianLink{}).
+			Where("id = ? AND status = ?", link.ID, value.GuardianLinkStatus_Pending).
+			Updates(map[string]interface{}{
+				"status":       value.GuardianLinkStatus_Active,
+				"confirmed_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return addGuardian(tx, link.UserId, link.GuardianId)
+	})
+}
+
+// EndLink closes an open link in the status, the guardian loses access to the
+// child when the link was active. gorm.ErrRecordNotFound when the link is not
+// open anymore.
+func (receiver *GuardianRepository) EndLink(link entity.SGuardianLink, status value.GuardianLinkStatus, endedBy string) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.SGuardianLink{}).
+			Where("id = ? AND status = ?", link.ID, link.Status).
+			Updates(map[string]interface{}{
+				"status":     status,
+				"ended_at":   now,
+				"ended_by":   endedBy,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if link.Status != value.GuardianLinkStatus_Active {
+			return nil
+		}
+
+		return tx.Where("user_id = ? AND guardian_id = ?", link.UserId, link.GuardianId).Delete(&entity.SUserGuardians{}).Error
+	})
+}
+
+// IsGuardian reports whether the guardian is an active guardian of the child
+func (receiver *GuardianRepository) IsGuardian(userId string, guardianId string) (bool, error) {
+	var count int64
+	err := receiver.DBConn.Model(&entity.SUserGuardians{}).
+		Where("user_id = ? AND guardian_id = ?", userId, guardianId).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// GetChildren lists the children of the guardian with the active links
+func (receiver *GuardianRepository) GetChildren(guardianId string) ([]entity.SUserEntity, error) {
+	children := make([]entity.SUserEntity, 0)
+	err := receiver.DBConn.
+		Where("id IN (SELECT user_id FROM s_user_guardians WHERE guardian_id = ?)", guardianId).
+		Order("username ASC").
+		Find(&children).Error
+
+	return children, err
+}
+
+// GetChildrenDevices lists the devices of the children of the guardian, of the
+// child userId only when it is given
+func (receiver *GuardianRepository) GetChildrenDevices(guardianId string, userId string) ([]entity.SDevice, error) {
+	subQuery := receiver.DBConn.Table("s_user_devices sud").
+		Select("sud.device_id").
+		Joins("JOIN s_user_guardians sug ON sug.user_id = sud.user_id").
+		Where("sug.guardian_id = ?", guardianId)
+	if userId != "" {
+		subQuery = subQuery.Where("sud.user_id = ?", userId)
+	}
+
+	devices := make([]entity.SDevice, 0)
+	err := receiver.DBConn.Where("id IN (?)", subQuery).Order("device_name ASC").Find(&devices).Error
+
+	return devices, err
+}
+
+// addGuardian makes the guardian an active guardian of the child
+func addGuardian(tx *gorm.DB, userId string, guardianId string) error {
+	childId, err := uuid.Parse(userId)
+	if err != nil {
+		return err
+	}
+	parentId, err := uuid.Parse(guardianId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.SUserGuardians{
+		UserId:     childId,
+		GuardianId: parentId,
+	}).Error
+}
+
+// syncGuardianLinks records the guardians a user was given directly, without an
+// invitation, as active links: the pending links of the guardians are confirmed,
+// the guardians without a link get a new one and the open links of the guardians
+// left out are closed. The links changed this way have no InvitedBy or EndedBy.
+func syncGuardianLinks(tx *gorm.DB, userId string, guardianIds []string) error {
+	links := make([]entity.SGuardianLink, 0)
+	err := tx.Where("user_id = ? AND status IN ?", userId, []value.GuardianLinkStatus{value.GuardianLinkStatus_Pending, value.GuardianLinkStatus_Active}).
+		Find(&links).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	kept := make(map[string]bool, len(guardianIds))
+	for _, guardianId := range guardianIds {
+		kept[guardianId] = true
+	}
+	linked := make(map[string]bool, len(links))
+	for _, link := range links {
+		updates := map[string]interface{}{"updated_at": now}
+		switch {
+		case kept[link.GuardianId] && link.Status == value.GuardianLinkStatus_Active:
+			linked[link.GuardianId] = true
+			continue
+		case kept[link.GuardianId]:
+			linked[link.GuardianId] = true
+			updates["status"] = value.GuardianLinkStatus_Active
+			updates["confirmed_at"] = now
+		case link.Status == value.GuardianLinkStatus_Active:
+			updates["status"] = value.GuardianLinkStatus_Detached
+			updates["ended_at"] = now
+		default:
+			updates["status"] = value.GuardianLinkStatus_Cancelled
+			updates["ended_at"] = now
+		}
+		err = tx.Model(&entity.SGuardianLink{}).Where("id = ?", link.ID).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, guardianId := range guardianIds {
+		if linked[guardianId] {
+			continue
+		}
+		linked[guardianId] = true
+		err = tx.Create(&entity.SGuardianLink{
+			UserId:      userId,
+			GuardianId:  guardianId,
+			Status:      value.GuardianLinkStatus_Active,
+			ConfirmedAt: &now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,16 +5,30 @@ import (
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
 )
 
+// ImageRepository reads the images of its organization and the shared ones, the
+// images it creates belong to OrganizationId
 type ImageRepository struct {
-	DBConn *gorm.DB
+	DBConn         *gorm.DB
+	OrganizationId *int64
 }
 
 func NewImageRepository(dbConn *gorm.DB) *ImageRepository {
 	return &ImageRepository{DBConn: dbConn}
 }
 
+// ForOrganization returns a repository over the images of the organization
+func (receiver *ImageRepository) ForOrganization(organizationId *int64) *ImageRepository {
+	return &ImageRepository{DBConn: ScopeOrganization(receiver.DBConn, organizationId), OrganizationId: organizationId}
+}
+
+// Scoped returns a repository over the images of the organizations of the scope
+func (receiver *ImageRepository) Scoped(scope value.AccessScope) *ImageRepository {
+	return &ImageRepository{DBConn: ScopeAccess(receiver.DBConn, scope), OrganizationId: receiver.OrganizationId}
+}
+
 func (receiver *ImageRepository) GetAllByIds(ids []int) ([]entity.SImage, error) {
 	var images []entity.SImage
 	err := receiver.DBConn.Table(entity.SImage{}.TableName()).Find(&images).Where("id IN (?)", ids).Error
@@ -56,6 +70,9 @@ func (receiver *ImageRepository) GetByID(id uint64) (*entity.SImage, error) {
 func (receiver *ImageRepository) GetByKey(key string) (*entity.SImage, error) {
 	var images entity.SImage
 	err := receiver.DBConn.Model(&entity.SImage{}).Where("`key` = ?", key).First(&images).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 	if err != nil {
 		log.Error("ImageRepository.GetByKey: " + err.Error())
 		return nil, errors.New("failed to get image")
@@ -64,6 +81,9 @@ func (receiver *ImageRepository) GetByKey(key string) (*entity.SImage, error) {
 }
 
 func (receiver *ImageRepository) CreateImages(images []entity.SImage) error {
+	for i := range images {
+		images[i].OrganizationId = receiver.OrganizationId
+	}
 	db := receiver.DBConn.Begin()
 	if err := db.Table(entity.SImage{}.TableName()).Create(&images).Error; err != nil {
 		db.Rollback()
@@ -81,6 +101,7 @@ func (receiver *ImageRepository) CreateImages(images []entity.SImage) error {
 }
 
 func (receiver *ImageRepository) CreateImage(image entity.SImage) error {
+	image.OrganizationId = receiver.OrganizationId
 	db := receiver.DBConn.Begin()
 	if err := db.Table(entity.SImage{}.TableName()).Create(&image).Error; err != nil {
 		db.Rollback()
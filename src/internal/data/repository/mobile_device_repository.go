@@ -28,3 +28,13 @@ func (receiver *MobileDeviceRepository) FindByDeviceID(deviceId string, db *gorm
 
 	return d, err
 }
+
+func (receiver *MobileDeviceRepository) FindByDeviceIDs(deviceIds []string, db *gorm.DB) ([]entity.SMobileDevice, error) {
+	devices := make([]entity.SMobileDevice, 0)
+	if len(deviceIds) == 0 {
+		return devices, nil
+	}
+	err := db.Where("device_id IN ?", deviceIds).Find(&devices).Error
+
+	return devices, err
+}
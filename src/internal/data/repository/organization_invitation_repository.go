@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationInvitationRepository struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *OrganizationInvitationRepository) CreateInvitation(invitation *entity.SOrganizationInvitation) error {
+	return receiver.DBConn.Create(invitation).Error
+}
+
+func (receiver *OrganizationInvitationRepository) GetInvitation(id uint64) (*entity.SOrganizationInvitation, error) {
+	var invitation entity.SOrganizationInvitation
+	err := receiver.DBConn.Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (receiver *OrganizationInvitationRepository) GetInvitationByHash(codeHash string) (*entity.SOrganizationInvitation, error) {
+	var invitation entity.SOrganizationInvitation
+	err := receiver.DBConn.Where("code_hash = ?", codeHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// GetInvitations lists the invitations of an organization, only those that can
+// still be used when active is set
+func (receiver *OrganizationInvitationRepository) GetInvitations(organizationId int64, active bool) ([]entity.SOrganizationInvitation, error) {
+	query := receiver.DBConn.Model(&entity.SOrganizationInvitation{}).Where("organization_id = ?", organizationId)
+	if active {
+		query = query.Where("revoked_at IS NULL AND declined_at IS NULL AND uses < max_uses AND expires_at > ?", time.Now())
+	}
+
+	invitations := make([]entity.SOrganizationInvitation, 0)
+	err := query.Order("id DESC").Find(&invitations).Error
+
+	return invitations, err
+}
+
+func (receiver *OrganizationInvitationRepository) RevokeInvitation(id uint64) error {
+	result := receiver.DBConn.Model(&entity.SOrganizationInvitation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RedeemInvitation takes one use of the invitation, gorm.ErrRecordNotFound when
+// it is revoked, declined, expired or used up. The use is taken with a
+// conditional update so concurrent users cannot go over the uses of the
+// invitation.
+func (receiver *OrganizationInvitationRepository) RedeemInvitation(id uint64) error {
+	now := time.Now()
+	result := receiver.DBConn.Model(&entity.SOrganizationInvitation{}).
+		Where("id = ? AND revoked_at IS NULL AND declined_at IS NULL AND uses < max_uses AND expires_at > ?", id, now).
+		Updates(map[string]interface{}{
+			"uses":       gorm.Expr("uses + 1"),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CloseInvitation marks an invitation for one user as declined, it cannot be
+// used anymore
+func (receiver *OrganizationInvitationRepository) CloseInvitation(id uint64) error {
+	return receiver.DBConn.Model(&entity.SOrganizationInvitation{}).
+		Where("id = ? AND declined_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"declined_at": time.Now(),
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+// SaveReply records the answer of a user to an invitation, replacing the
+// previous one
+func (receiver *OrganizationInvitationRepository) SaveReply(invitationId uint64, userId string, reply value.InvitationReply) error {
+	return receiver.DBConn.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"reply", "replied_at"}),
+	}).Create(&entity.SOrganizationInvitationReply{
+		InvitationId: invitationId,
+		UserId:       userId,
+		Reply:        reply,
+		RepliedAt:    time.Now(),
+	}).Error
+}
+
+func (receiver *OrganizationInvitationRepository) GetReplies(invitationId uint64) ([]entity.SOrganizationInvitationReply, error) {
+	replies := make([]entity.SOrganizationInvitationReply, 0)
+	err := receiver.DBConn.Where("invitation_id = ?", invitationId).Order("replied_at ASC").Find(&replies).Error
+
+	return replies, err
+}
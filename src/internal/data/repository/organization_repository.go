@@ -4,9 +4,13 @@ import (
 	"errors"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"time"
 
+	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrganizationRepository struct {
@@ -143,3 +147,127 @@ func (receiver *OrganizationRepository) GetAllUserByOrganization(organizationID
 
 	return users, nil
 }
+
+// FindByID is GetByID keeping the error of the lookup, gorm.ErrRecordNotFound
+// when there is no such organization
+func (receiver *OrganizationRepository) FindByID(id int64) (*entity.SOrganization, error) {
+	var organization entity.SOrganization
+	err := receiver.DBConn.Where("id = ?", id).First(&organization).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+// RetirePassword clears the shared password of an organization, it can only be
+// joined with an invitation from then on
+func (receiver *OrganizationRepository) RetirePassword(organizationId int64) error {
+	return receiver.DBConn.Model(&entity.SOrganization{}).
+		Where("id = ?", organizationId).
+		Updates(map[string]interface{}{
+			"password":   "",
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (receiver *OrganizationRepository) SetOwner(organizationId int64, userId string) error {
+	return receiver.DBConn.Model(&entity.SOrganization{}).
+		Where("id = ?", organizationId).
+		Updates(map[string]interface{}{
+			"owner_id":   userId,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (receiver *OrganizationRepository) GetMember(organizationId int64, userId string) (*entity.SUsersOrganization, error) {
+	var member entity.SUsersOrganization
+	err := receiver.DBConn.Preload("User").
+		Where("organization_id = ? AND user_id = ?", organizationId, userId).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// GetMembers lists the members of an organization with their user, only those
+// in the status when one is given
+func (receiver *OrganizationRepository) GetMembers(organizationId int64, status *value.MembershipStatus) ([]entity.SUsersOrganization, error) {
+	query := receiver.DBConn.Preload("User").Where("organization_id = ?", organizationId)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	members := make([]entity.SUsersOrganization, 0)
+	err := query.Order("created_at ASC").Find(&members).Error
+
+	return members, err
+}
+
+// AddMember makes the user an active member of the organization and gives them
+// the roles, which must be roles of the organization
+func (receiver *OrganizationRepository) AddMember(organizationId int64, userId uuid.UUID, invitationId *uint64, roleIds []int64) error {
+	err := receiver.DBConn.Create(&entity.SUsersOrganization{
+		UserId:         userId,
+		OrganizationID: organizationId,
+		Status:         value.MembershipStatus_Active,
+		InvitationId:   invitationId,
+		CreatedAt:      time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, roleId := range roleIds {
+		err = receiver.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.SUserRoles{
+			UserId: userId,
+			RoleId: roleId,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (receiver *OrganizationRepository) SetMemberStatus(organizationId int64, userId string, status value.MembershipStatus) error {
+	updates := map[string]interface{}{
+		"status":       status,
+		"suspended_at": nil,
+	}
+	if status == value.MembershipStatus_Suspended {
+		updates["suspended_at"] = time.Now()
+	}
+
+	result := receiver.DBConn.Model(&entity.SUsersOrganization{}).
+		Where("organization_id = ? AND user_id = ?", organizationId, userId).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember takes the user out of the organization together with their roles
+// of the organization
+func (receiver *OrganizationRepository) RemoveMember(organizationId int64, userId string) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("organization_id = ? AND user_id = ?", organizationId, userId).Delete(&entity.SUsersOrganization{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("user_id = ? AND role_id IN (SELECT id FROM s_role WHERE organization_id = ?)", userId, organizationId).
+			Delete(&entity.SUserRoles{}).Error
+	})
+}
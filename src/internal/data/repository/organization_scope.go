@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"sen-global-api/internal/domain/value"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOtherOrganization is returned when a code or a token that must be unique
+// is already used by a row of another organization
+var ErrOtherOrganization = errors.New("already used by another organization")
+
+// ScopeOrganizations limits every query run on the returned connection to the
+// rows of the organizations and to the shared rows, which belong to no
+// organization. It only filters, the callers check that a row they change is
+// theirs with value.AccessScope.Owns.
+func ScopeOrganizations(db *gorm.DB, organizationIds []int64) *gorm.DB {
+	return db.Scopes(func(tx *gorm.DB) *gorm.DB {
+		column := clause.Column{Table: clause.CurrentTable, Name: "organization_id"}
+		conditions := []clause.Expression{clause.Eq{Column: column, Value: nil}}
+		if len(organizationIds) > 0 {
+			values := make([]interface{}, 0, len(organizationIds))
+			for _, organizationId := range organizationIds {
+				values = append(values, organizationId)
+			}
+			conditions = append(conditions, clause.IN{Column: column, Values: values})
+		}
+
+		return tx.Where(clause.Or(conditions...))
+	}).Session(&gorm.Session{})
+}
+
+// ScopeAccess limits the queries to the organizations of the scope, a scope
+// over every organization sees the rows of every organization
+func ScopeAccess(db *gorm.DB, scope value.AccessScope) *gorm.DB {
+	if scope.AllOrganizations {
+		return db
+	}
+
+	return ScopeOrganizations(db, scope.OrganizationIds)
+}
+
+// ScopeOrganization limits the queries to one organization and the shared rows,
+// it does not limit them when organizationId is nil
+func ScopeOrganization(db *gorm.DB, organizationId *int64) *gorm.DB {
+	if organizationId == nil {
+		return db
+	}
+
+	return ScopeOrganizations(db, []int64{*organizationId})
+}
+
+// ownedBy limits a query to the rows of the organization, or to the shared rows
+// when organizationId is nil
+func ownedBy(db *gorm.DB, organizationId *int64) *gorm.DB {
+	if organizationId == nil {
+		return db.Where("organization_id IS NULL")
+	}
+
+	return db.Where("organization_id = ?", *organizationId)
+}
+
+// unscoped returns the connection without the organization scope, to look for
+// the rows of other organizations
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
+func sameOrganization(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository struct {
+	DBConn *gorm.DB
+}
+
+// PermissionGrant is a permission held through a role, OrganizationId is 0 for
+// roles without an organization
+type PermissionGrant struct {
+	Permission     string
+	OrganizationId int64
+}
+
+type grantedPermission struct {
+	ClaimName      string
+	PermissionName string
+	OrganizationId *int64
+}
+
+// GetUserPermissions resolves the effective permissions of a user through the
+// claims of their roles. Roles of an organization only count while the user is an
+// active member of it, roles without an organization apply everywhere. A
+// permission name without a resource, such as "write" under the claim "form", is
+// qualified with the claim name to "form:write". The owner of an organization
+// holds every permission in it while they are an active member.
+func (receiver *PermissionRepository) GetUserPermissions(userId string) ([]PermissionGrant, error) {
+	rows := make([]grantedPermission, 0)
+	err := receiver.DBConn.Table("s_role_claim_permission").
+		Select("s_role_claim.claim_name, s_role_claim_permission.permission_name, s_role.organization_id").
+		Joins("JOIN s_role_claim ON s_role_claim.id = s_role_claim_permission.role_claim_id").
+		Joins("JOIN s_role ON s_role.id = s_role_claim.role_id").
+		Joins("JOIN s_user_roles ON s_user_roles.role_id = s_role.id").
+		Where("s_user_roles.user_id = ?", userId).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	activeIds := make([]int64, 0)
+	err = receiver.DBConn.Table("s_users_organization").
+		Where("user_id = ? AND status = ?", userId, value.MembershipStatus_Active).
+		Pluck("organization_id", &activeIds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ownedIds := make([]int64, 0)
+	err = receiver.DBConn.Table("s_organization").
+		Where("owner_id = ?", userId).
+		Pluck("id", &ownedIds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionGrants(rows, activeIds, ownedIds), nil
+}
+
+// permissionGrants qualifies the permissions of the roles of a user, drops the
+// ones of organizations the user is not an active member of and adds "*" for
+// the organizations they own and are an active member of
+func permissionGrants(rows []grantedPermission, activeIds []int64, ownedIds []int64) []PermissionGrant {
+	active := make(map[int64]bool, len(activeIds))
+	for _, organizationId := range activeIds {
+		active[organizationId] = true
+	}
+
+	grants := make([]PermissionGrant, 0, len(rows)+len(ownedIds))
+	for _, row := range rows {
+		permission := strings.ToLower(strings.TrimSpace(row.PermissionName))
+		if permission == "" {
+			continue
+		}
+		if permission != "*" && !strings.Contains(permission, ":") {
+			permission = strings.ToLower(strings.TrimSpace(row.ClaimName)) + ":" + permission
+		}
+		grant := PermissionGrant{Permission: permission}
+		if row.OrganizationId != nil {
+			grant.OrganizationId = *row.OrganizationId
+		}
+		if grant.OrganizationId != 0 && !active[grant.OrganizationId] {
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	for _, organizationId := range ownedIds {
+		if active[organizationId] {
+			grants = append(grants, PermissionGrant{Permission: string(value.Permission_All), OrganizationId: organizationId})
+		}
+	}
+
+	return grants
+}
+
+// IsSuperAdmin reports whether the user holds the SuperAdmin role without an
+// organization. Roles of that name within an organization do not count.
+func (receiver *PermissionRepository) IsSuperAdmin(userId string) (bool, error) {
+	var count int64
+	err := receiver.DBConn.Table("s_user_roles").
+		Joins("JOIN s_role ON s_role.id = s_user_roles.role_id").
+		Where("s_user_roles.user_id = ? AND s_role.role_name = ?", userId, value.SuperAdminRoleName).
+		Where("s_role.organization_id IS NULL OR s_role.organization_id = 0").
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// GetUserOrganizationIds returns the organizations where the user is an active
+// member, suspended members are left out
+func (receiver *PermissionRepository) GetUserOrganizationIds(userId string) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := receiver.DBConn.Table("s_users_organization").Where("user_id = ? AND status = ?", userId, value.MembershipStatus_Active).Pluck("organization_id", &ids).Error
+
+	return ids, err
+}
+
+// GetUserOrganizationId returns the organization of a user of a single
+// organization, nil for the users of none or several organizations
+func (receiver *PermissionRepository) GetUserOrganizationId(userId string) (*int64, error) {
+	ids, err := receiver.GetUserOrganizationIds(userId)
+	if err != nil || len(ids) != 1 {
+		return nil, err
+	}
+
+	return &ids[0], nil
+}
+
+// GetRoleOrganizationId returns the organization of a role, 0 for roles without one
+func (receiver *PermissionRepository) GetRoleOrganizationId(roleId int64) (int64, error) {
+	var organizationIds []*int64
+	err := receiver.DBConn.Table("s_role").Where("id = ?", roleId).Pluck("organization_id", &organizationIds).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(organizationIds) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if organizationIds[0] == nil {
+		return 0, nil
+	}
+
+	return *organizationIds[0], nil
+}
+
+func (receiver *PermissionRepository) GetRoleClaimOrganizationId(roleClaimId int64) (int64, error) {
+	var roleIds []int64
+	err := receiver.DBConn.Table("s_role_claim").Where("id = ?", roleClaimId).Pluck("role_id", &roleIds).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(roleIds) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return receiver.GetRoleOrganizationId(roleIds[0])
+}
+
+func (receiver *PermissionRepository) GetRoleClaimPermissionOrganizationId(roleClaimPermissionId int64) (int64, error) {
+	var roleClaimIds []int64
+	err := receiver.DBConn.Table("s_role_claim_permission").Where("id = ?", roleClaimPermissionId).Pluck("role_claim_id", &roleClaimIds).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(roleClaimIds) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return receiver.GetRoleClaimOrganizationId(roleClaimIds[0])
+}
+
+func (receiver *PermissionRepository) GetRoleOrganizationIdByName(roleName string) (int64, int64, error) {
+	var role entity.SRole
+	err := receiver.DBConn.Table("s_role").Where("role_name = ?", roleName).First(&role).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return role.ID, role.OrganizationId, nil
+}
+
+func (receiver *PermissionRepository) GetUserRoles(userId string) ([]entity.SRole, error) {
+	roles := make([]entity.SRole, 0)
+	err := receiver.DBConn.Table("s_role").
+		Joins("JOIN s_user_roles ON s_user_roles.role_id = s_role.id").
+		Where("s_user_roles.user_id = ?", userId).
+		Find(&roles).Error
+
+	return roles, err
+}
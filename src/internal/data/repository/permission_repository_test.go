@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPermissionGrants(t *testing.T) {
+	organization := func(id int64) *int64 {
+		return &id
+	}
+
+	tests := []struct {
+		name      string
+		rows      []grantedPermission
+		activeIds []int64
+		ownedIds  []int64
+		want      []PermissionGrant
+	}{
+		{
+			name: "permission qualified with the claim",
+			rows: []grantedPermission{
+				{ClaimName: " Form ", PermissionName: "Write", OrganizationId: organization(1)},
+				{ClaimName: "form", PermissionName: "device:read", OrganizationId: organization(1)},
+				{ClaimName: "form", PermissionName: " ", OrganizationId: organization(1)},
+			},
+			activeIds: []int64{1},
+			want:      []PermissionGrant{{Permission: "form:write", OrganizationId: 1}, {Permission: "device:read", OrganizationId: 1}},
+		},
+		{
+			name: "roles without an organization apply everywhere",
+			rows: []grantedPermission{
+				{ClaimName: "role", PermissionName: "read"},
+				{ClaimName: "role", PermissionName: "write", OrganizationId: organization(0)},
+			},
+			want: []PermissionGrant{{Permission: "role:read"}, {Permission: "role:write"}},
+		},
+		{
+			name: "roles of a suspended membership are dropped",
+			rows: []grantedPermission{
+				{ClaimName: "form", PermissionName: "read", OrganizationId: organization(1)},
+				{ClaimName: "form", PermissionName: "read", OrganizationId: organization(2)},
+			},
+			activeIds: []int64{2},
+			want:      []PermissionGrant{{Permission: "form:read", OrganizationId: 2}},
+		},
+		{
+			name:      "owner holds every permission",
+			activeIds: []int64{1, 2},
+			ownedIds:  []int64{2},
+			want:      []PermissionGrant{{Permission: "*", OrganizationId: 2}},
+		},
+		{
+			name:      "suspended owner holds nothing",
+			activeIds: []int64{1},
+			ownedIds:  []int64{2},
+			want:      []PermissionGrant{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := permissionGrants(test.rows, test.activeIds, test.ownedIds)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("permissionGrants = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
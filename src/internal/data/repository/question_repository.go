@@ -610,7 +610,7 @@ func (receiver *QuestionRepository) GetQuestionsByFormId(id uint64) ([]model.For
 	rows, err := receiver.DBConn.Raw("SELECT s_question.question_id as question_id, s_question.question_name as question_name, "+
 		"s_question.question_type as question_type, s_question.attributes as attributes, s_question.status as status, "+
 		"s_question.created_at as created_at, s_question.updated_at as updated_at, s_form_question.order as `order`, s_form_question.answer_required as answer_required, s_question.question as question,"+
-		"s_question.enable_on_mobile as enable_on_mobile, s_question.question_unique_id as question_unique_id "+
+		"s_question.enable_on_mobile as enable_on_mobile, s_question.question_unique_id as question_unique_id, s_form_question.rules as rules "+
 		"FROM s_question RIGHT JOIN s_form_question ON s_form_question.question_id = s_question.question_id WHERE s_form_question.form_id = ? AND s_question.status = ? ORDER BY `order` ASC", id, value.Active).Rows()
 
 	if err != nil {
@@ -1114,3 +1114,70 @@ func (receiver *QuestionRepository) unmarshalQRCodeGeneratorQuestion(param Creat
 
 	return &question, nil
 }
+
+// ValidateQuestion checks the type and attributes of a question with the same rules
+// used when importing questions from a spreadsheet and returns the question to save.
+func (receiver *QuestionRepository) ValidateQuestion(param CreateQuestionParams) (*entity.SQuestion, error) {
+	if _, err := uuid.Parse(param.QuestionId); err != nil {
+		return nil, err
+	}
+
+	return receiver.unmarshalQuestion(param)
+}
+
+func (receiver *QuestionRepository) CreateQuestion(question *entity.SQuestion) error {
+	return receiver.DBConn.Create(question).Error
+}
+
+func (receiver *QuestionRepository) UpdateQuestion(question *entity.SQuestion) error {
+	return receiver.DBConn.Model(&entity.SQuestion{}).
+		Where("question_id = ?", question.QuestionId).
+		Updates(map[string]interface{}{
+			"question_name":      question.QuestionName,
+			"question_type":      question.QuestionType,
+			"question":           question.Question,
+			"attributes":         question.Attributes,
+			"status":             question.Status,
+			"enable_on_mobile":   question.EnableOnMobile,
+			"question_unique_id": question.QuestionUniqueId,
+		}).Error
+}
+
+func (receiver *QuestionRepository) DeleteQuestion(id string) error {
+	return receiver.DBConn.Where("question_id = ?", id).Delete(&entity.SQuestion{}).Error
+}
+
+// GetAllQuestionsByFormId returns every question of a form in order, including the inactive ones
+func (receiver *QuestionRepository) GetAllQuestionsByFormId(id uint64) ([]model.FormQuestionItem, error) {
+	var questions []model.FormQuestionItem
+	err := receiver.DBConn.Table("s_form_question").
+		Select("s_question.question_id as question_id, s_question.question_name as question_name, " +
+			"s_question.question_type as question_type, s_question.attributes as attributes, s_question.status as status, " +
+			"s_question.created_at as created_at, s_question.updated_at as updated_at, s_form_question.order as `order`, " +
+			"s_form_question.answer_required as answer_required, s_question.question as question, " +
+			"s_question.enable_on_mobile as enable_on_mobile, s_question.question_unique_id as question_unique_id, " +
+			"s_form_question.rules as rules").
+		Joins("INNER JOIN s_question ON s_question.question_id = s_form_question.question_id").
+		Where("s_form_question.form_id = ?", id).
+		Order("`order` ASC").
+		Scan(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// RestoreQuestions writes the questions back with their original ids, overwriting
+// questions that still exist
+func (receiver *QuestionRepository) RestoreQuestions(questions []entity.SQuestion) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	return receiver.DBConn.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "question_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"question_name", "question_type", "question", "attributes",
+			"status", "enable_on_mobile", "question_unique_id", "updated_at"}),
+	}).Create(&questions).Error
+}
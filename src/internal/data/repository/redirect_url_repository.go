@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
@@ -12,13 +13,41 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// RedirectUrlRepository reads the redirect URLs of its scope and the shared
+// ones, the redirect URLs it creates belong to OrganizationId
 type RedirectUrlRepository struct {
 	DBConn                 *gorm.DB
 	DefaultRequestPageSize int
+	OrganizationId         *int64
+}
+
+// ForOrganization returns a repository over the redirect URLs of the
+// organization, over every redirect URL when organizationId is nil
+func (receiver *RedirectUrlRepository) ForOrganization(organizationId *int64) *RedirectUrlRepository {
+	return &RedirectUrlRepository{
+		DBConn:                 ScopeOrganization(receiver.DBConn, organizationId),
+		DefaultRequestPageSize: receiver.DefaultRequestPageSize,
+		OrganizationId:         organizationId,
+	}
+}
+
+// Scoped returns a repository over the redirect URLs of the organizations of
+// the scope
+func (receiver *RedirectUrlRepository) Scoped(scope value.AccessScope) *RedirectUrlRepository {
+	return &RedirectUrlRepository{
+		DBConn:                 ScopeAccess(receiver.DBConn, scope),
+		DefaultRequestPageSize: receiver.DefaultRequestPageSize,
+		OrganizationId:         receiver.OrganizationId,
+	}
 }
 
 func (receiver *RedirectUrlRepository) Save(redirectUrl entity.SRedirectUrl) (*entity.SRedirectUrl, error) {
-	err := receiver.DBConn.Create(&redirectUrl).Error
+	err := receiver.checkQRCode(redirectUrl.QRCode)
+	if err != nil {
+		return nil, err
+	}
+	redirectUrl.OrganizationId = receiver.OrganizationId
+	err = receiver.DBConn.Create(&redirectUrl).Error
 	if err != nil {
 		return nil, err
 	}
@@ -100,16 +129,18 @@ func (receiver *RedirectUrlRepository) GetByQRCode(qrCode string) (*entity.SRedi
 	return &url, nil
 }
 
-func (receiver *RedirectUrlRepository) SaveRedirectUrl(qrCode string, targetUrl string, password string, status string, hint string, hashPwd *string) error {
+// SaveRedirectUrl applies a row of the redirect URL spreadsheet with the status,
+// a new row replaces the redirect URL of its QR code
+func (receiver *RedirectUrlRepository) SaveRedirectUrl(redirectUrl entity.SRedirectUrl, status string) error {
 	spreadSheetStatus, err := value.GetImportSpreadsheetStatusFromString(status)
 	if err != nil {
 		return err
 	}
 	switch spreadSheetStatus {
 	case value.ImportSpreadsheetStatusNew:
-		return receiver.saveNewRedirectUrl(qrCode, targetUrl, password, hint, hashPwd)
+		return receiver.saveNewRedirectUrl(redirectUrl)
 	case value.ImportSpreadsheetStatusDeleted:
-		return receiver.DBConn.Where("qr_code = ?", qrCode).Delete(&entity.SRedirectUrl{}).Error
+		return ownedBy(receiver.DBConn, receiver.OrganizationId).Where("qr_code = ?", redirectUrl.QRCode).Delete(&entity.SRedirectUrl{}).Error
 	case value.ImportSpreadsheetStatusSkip:
 		return nil
 	default:
@@ -117,20 +148,33 @@ func (receiver *RedirectUrlRepository) SaveRedirectUrl(qrCode string, targetUrl
 	}
 }
 
-func (receiver *RedirectUrlRepository) saveNewRedirectUrl(code string, url string, password string, hint string, hashPwd *string) error {
-	var pwd *string = nil
-	if password != "" {
-		pwd = &password
-	}
-	redirectUrl := entity.SRedirectUrl{
-		QRCode:       code,
-		TargetUrl:    url,
-		Password:     pwd,
-		Hint:         hint,
-		HashPassword: hashPwd,
+func (receiver *RedirectUrlRepository) saveNewRedirectUrl(redirectUrl entity.SRedirectUrl) error {
+	err := receiver.checkQRCode(redirectUrl.QRCode)
+	if err != nil {
+		return err
 	}
+	redirectUrl.OrganizationId = receiver.OrganizationId
 	return receiver.DBConn.Table("s_redirect_url").Clauses(
 		clause.OnConflict{Columns: []clause.Column{{Name: "qr_code"}},
-			DoUpdates: clause.AssignmentColumns([]string{"target_url", "password", "hint", "hash_password"}),
+			DoUpdates: clause.AssignmentColumns([]string{"target_url", "password", "hint", "hash_password", "active_from", "active_until", "fallback_url", "schedule"}),
 		}).Create(&redirectUrl).Error
 }
+
+// checkQRCode fails with ErrOtherOrganization when the QR code is already the
+// code of a redirect URL of another organization, the devices scan the codes of
+// every organization
+func (receiver *RedirectUrlRepository) checkQRCode(qrCode string) error {
+	var url entity.SRedirectUrl
+	err := unscoped(receiver.DBConn).Where("qr_code = ?", qrCode).First(&url).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !sameOrganization(url.OrganizationId, receiver.OrganizationId) {
+		return fmt.Errorf("%w: redirect url %s", ErrOtherOrganization, qrCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RedirectUrlScanRepository reads the scans of the redirect URLs of its scope
+// and of the shared ones
+type RedirectUrlScanRepository struct {
+	DBConn *gorm.DB
+}
+
+// RedirectUrlScanFilter narrows the scans down to a QR code and to the scans
+// from From and before To, each is optional
+type RedirectUrlScanFilter struct {
+	QRCode string
+	From   *time.Time
+	To     *time.Time
+}
+
+// RedirectUrlScanCount is the number of scans of a code with an outcome, on a
+// day for the counts by day
+type RedirectUrlScanCount struct {
+	QRCode        string
+	TargetUrl     string
+	Day           string
+	Outcome       value.RedirectUrlScanOutcome
+	Count         int64
+	LastScanId    uint64
+	LastScannedAt *time.Time
+}
+
+// ForOrganization returns a repository over the scans of the organization, over
+// every scan when organizationId is nil
+func (receiver *RedirectUrlScanRepository) ForOrganization(organizationId *int64) *RedirectUrlScanRepository {
+	return &RedirectUrlScanRepository{DBConn: ScopeOrganization(receiver.DBConn, organizationId)}
+}
+
+// Scoped returns a repository over the scans of the organizations of the scope
+func (receiver *RedirectUrlScanRepository) Scoped(scope value.AccessScope) *RedirectUrlScanRepository {
+	return &RedirectUrlScanRepository{DBConn: ScopeAccess(receiver.DBConn, scope)}
+}
+
+func (receiver *RedirectUrlScanRepository) Create(scan *entity.SRedirectUrlScan) error {
+	scan.QRCode = truncate(scan.QRCode, 255)
+	scan.UserAgent = truncate(scan.UserAgent, 512)
+	scan.IpAddress = truncate(scan.IpAddress, 64)
+
+	return receiver.DBConn.Create(scan).Error
+}
+
+// CountByDay counts the scans of each code by day and outcome, the days are
+// read as YYYY-MM-DD in the timezone of the database
+func (receiver *RedirectUrlScanRepository) CountByDay(filter RedirectUrlScanFilter) ([]RedirectUrlScanCount, error) {
+	counts := make([]RedirectUrlScanCount, 0)
+	err := receiver.filterScans(filter).
+		Select("qr_code, DATE(created_at) AS day, outcome, COUNT(*) AS count").
+		Group("qr_code, DATE(created_at), outcome").
+		Order("day, qr_code").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// CountByCode counts the scans of each code by outcome with the time of the
+// last of them
+func (receiver *RedirectUrlScanRepository) CountByCode(filter RedirectUrlScanFilter) ([]RedirectUrlScanCount, error) {
+	counts := make([]RedirectUrlScanCount, 0)
+	err := receiver.filterScans(filter).
+		Select("qr_code, outcome, COUNT(*) AS count, MAX(id) AS last_scan_id").
+		Group("qr_code, outcome").
+		Scan(&counts).Error
+	if err != nil || len(counts) == 0 {
+		return counts, err
+	}
+
+	ids := make([]uint64, 0, len(counts))
+	for _, count := range counts {
+		ids = append(ids, count.LastScanId)
+	}
+	scans := make([]entity.SRedirectUrlScan, 0, len(ids))
+	err = receiver.DBConn.Select("id", "created_at").Where("id IN ?", ids).Find(&scans).Error
+	if err != nil {
+		return nil, err
+	}
+	scannedAt := make(map[uint64]time.Time, len(scans))
+	for _, scan := range scans {
+		scannedAt[scan.ID] = scan.CreatedAt
+	}
+	for i := range counts {
+		if at, ok := scannedAt[counts[i].LastScanId]; ok {
+			counts[i].LastScannedAt = &at
+		}
+	}
+
+	return counts, nil
+}
+
+// CountByTarget counts the scans of each code sent to each target URL
+func (receiver *RedirectUrlScanRepository) CountByTarget(filter RedirectUrlScanFilter) ([]RedirectUrlScanCount, error) {
+	counts := make([]RedirectUrlScanCount, 0)
+	err := receiver.filterScans(filter).
+		Select("qr_code, target_url, COUNT(*) AS count").
+		Where("target_url <> ''").
+		Group("qr_code, target_url").
+		Order("qr_code, count DESC").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// GetScans lists the filtered scans with the outcome newest first, all of them
+// when outcome is empty
+func (receiver *RedirectUrlScanRepository) GetScans(filter RedirectUrlScanFilter, outcome value.RedirectUrlScanOutcome, limit int) ([]entity.SRedirectUrlScan, error) {
+	scans := make([]entity.SRedirectUrlScan, 0)
+	query := receiver.filterScans(filter)
+	if outcome != "" {
+		query = query.Where("outcome = ?", outcome)
+	}
+	err := query.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&scans).Error
+
+	return scans, err
+}
+
+// FindScansInBatches walks the filtered scans oldest first, handing them to fn
+// batchSize at a time
+func (receiver *RedirectUrlScanRepository) FindScansInBatches(filter RedirectUrlScanFilter, batchSize int, fn func(scans []entity.SRedirectUrlScan) error) error {
+	scans := make([]entity.SRedirectUrlScan, 0, batchSize)
+	return receiver.filterScans(filter).
+		FindInBatches(&scans, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(scans)
+		}).Error
+}
+
+func (receiver *RedirectUrlScanRepository) filterScans(filter RedirectUrlScanFilter) *gorm.DB {
+	query := receiver.DBConn.Model(&entity.SRedirectUrlScan{})
+	if filter.QRCode != "" {
+		query = query.Where("qr_code = ?", filter.QRCode)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
@@ -1,41 +1,99 @@
 package repository
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/response"
+	"sen-global-api/pkg/jwtkeys"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	gofn "github.com/tiendc/gofn"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// deviceRefreshTokenType is the typ claim of the device refresh tokens, which
+// are not accepted as access tokens
+const deviceRefreshTokenType = "refresh"
+
+var (
+	ErrSessionRevoked      = errors.New("session has been revoked or has expired")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrNoSessionStore      = errors.New("sessions need a database")
+	errRefreshTokenReused  = errors.New("refresh token was already exchanged")
+)
+
+// SessionRepository issues the tokens of the users and keeps their sessions.
+// Without DBConn no session is persisted and tokens cannot be revoked. Tokens are
+// signed with the signing key of KeySet, or with HS256 and AuthorizeEncryptKey
+// when there is no KeySet. HS256 tokens are only accepted along a KeySet with
+// AcceptHS256, for the tokens issued before the keys were set up. Every token
+// carries the session it was issued for, the tokens issued before sessions
+// existed are only accepted until SessionlessTokensUntil.
 type SessionRepository struct {
-	AuthorizeEncryptKey   string
-	TokenExpireTimeInHour time.Duration
+	DBConn                       *gorm.DB
+	AuthorizeEncryptKey          string
+	TokenExpireTimeInHour        time.Duration
+	RefreshTokenExpireTimeInHour time.Duration
+	KeySet                       *jwtkeys.KeySet
+	AcceptHS256                  bool
+	SessionlessTokensUntil       time.Time
+}
+
+func (receiver *SessionRepository) keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if receiver.KeySet != nil && !receiver.AcceptHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(receiver.AuthorizeEncryptKey), nil
+	}
+	if receiver.KeySet == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return receiver.KeySet.Keyfunc(token)
+}
+
+func (receiver *SessionRepository) sign(claims jwt.MapClaims) (string, error) {
+	if receiver.KeySet != nil {
+		return receiver.KeySet.Sign(claims)
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(receiver.AuthorizeEncryptKey))
 }
 
 func (receiver *SessionRepository) VerifyPassword(password string, hashed string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
 }
 
-func (receiver *SessionRepository) GenerateToken(user entity.SUserEntity) (*response.LoginResponseData, error) {
+// GenerateToken signs an access token of the user for the session, the token is
+// only accepted while the session is active
+func (receiver *SessionRepository) GenerateToken(user entity.SUserEntity, sessionId string) (*response.LoginResponseData, error) {
+	if sessionId == "" {
+		return nil, ErrNoSessionStore
+	}
+
 	roles := gofn.MapSliceToMap(user.Roles, func(role entity.SRole) (int64, string) {
 		return role.ID, role.RoleName
 	})
 
 	expirationTime := time.Now().Add(receiver.TokenExpireTimeInHour * time.Hour)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"user_id":  user.ID.String(),
 		"username": user.Username,
 		"roles":    strings.Join(gofn.MapValues(roles), ", "),
 		"exp":      expirationTime.Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(receiver.AuthorizeEncryptKey))
+		"sid":      sessionId,
+	}
+	tokenString, err := receiver.sign(claims)
 
 	if err != nil {
 		return nil, err
@@ -49,61 +107,89 @@ func (receiver *SessionRepository) GenerateToken(user entity.SUserEntity) (*resp
 }
 
 func (receiver *SessionRepository) ValidateToken(encodedToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(receiver.AuthorizeEncryptKey), nil
-	})
+	token, err := jwt.Parse(encodedToken, receiver.keyfunc)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		sessionId, _ := claims["sid"].(string)
+		if sessionId == "" {
+			if !time.Now().Before(receiver.SessionlessTokensUntil) {
+				return nil, ErrSessionRevoked
+			}
+			return token, nil
+		}
+		if err = receiver.checkSession(sessionId); err != nil {
+			return nil, err
+		}
 		return token, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
 
-func (receiver *SessionRepository) ExtractUserIdFromToken(tokenString string) (*string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// checkSession is the revocation check of the tokens, a token of a revoked or
+// expired session is rejected before its own expiry
+func (receiver *SessionRepository) checkSession(sessionId string) error {
+	if receiver.DBConn == nil {
+		return nil
+	}
+
+	session, err := receiver.GetSession(sessionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkSessionActive(*session, time.Now())
+}
+
+func checkSessionActive(session entity.SSession, now time.Time) error {
+	if !session.IsActive(now) {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// GetSessionIdFromToken returns the session of an access token, empty for the
+// tokens issued before sessions existed
+func (receiver *SessionRepository) GetSessionIdFromToken(token *jwt.Token) string {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if sessionId, ok := claims["sid"].(string); ok {
+			return sessionId
 		}
+	}
 
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(receiver.AuthorizeEncryptKey), nil
-	})
+	return ""
+}
 
+func (receiver *SessionRepository) ExtractUserIdFromToken(tokenString string) (*string, error) {
+	token, err := receiver.ValidateToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
+	return receiver.GetUserIdFromToken(token)
+}
+
+// GetUserIdFromToken reads the user of a token ValidateToken already checked
+func (receiver *SessionRepository) GetUserIdFromToken(token *jwt.Token) (*string, error) {
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		if userId, ok := claims["user_id"].(string); ok {
 			return &userId, nil
 		}
 	}
 
-	return nil, err
+	return nil, errors.New("token has no user")
 }
 
 func (receiver *SessionRepository) GetRoleFromToken(token *jwt.Token) ([]string, string, error) {
-	token, err := jwt.Parse(token.Raw, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(receiver.AuthorizeEncryptKey), nil
-	})
+	token, err := jwt.Parse(token.Raw, receiver.keyfunc)
 
 	if err != nil {
 		return make([]string, 0), "", err
@@ -119,21 +205,36 @@ func (receiver *SessionRepository) GetRoleFromToken(token *jwt.Token) ([]string,
 	return make([]string, 0), "", err
 }
 
-func (receiver *SessionRepository) GenerateTokenByDevice(device entity.SDevice) (string, string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	tokenClaims := token.Claims.(jwt.MapClaims)
-	tokenClaims["device_uuid"] = device.ID
-	tokenClaims["sub"] = 1
-	tokenString, err := token.SignedString([]byte(receiver.AuthorizeEncryptKey))
+// GenerateTokenByDevice starts a session of the device, without a user, and
+// signs its access and refresh tokens for it. A device has one session, the
+// tokens of its previous session stop being accepted.
+func (receiver *SessionRepository) GenerateTokenByDevice(device entity.SDevice, userAgent string, ipAddress string) (string, string, error) {
+	if receiver.DBConn == nil {
+		return "", "", ErrNoSessionStore
+	}
+
+	session, _, err := receiver.CreateSession("", device.ID, userAgent, ipAddress)
+	if err != nil {
+		return "", "", err
+	}
+
+	tokenString, err := receiver.sign(jwt.MapClaims{
+		"device_uuid": device.ID,
+		"sub":         1,
+		"sid":         session.ID,
+		"exp":         time.Now().Add(receiver.TokenExpireTimeInHour * time.Hour).Unix(),
+	})
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	rtClaims := refreshToken.Claims.(jwt.MapClaims)
-	rtClaims["sub"] = 1
-	rtClaims["device_uuid"] = device.ID
-	rt, err := refreshToken.SignedString([]byte(receiver.AuthorizeEncryptKey))
+	rt, err := receiver.sign(jwt.MapClaims{
+		"sub":         1,
+		"device_uuid": device.ID,
+		"sid":         session.ID,
+		"typ":         deviceRefreshTokenType,
+		"exp":         session.ExpiresAt.Unix(),
+	})
 	if err != nil {
 		return "", "", err
 	}
@@ -141,44 +242,258 @@ func (receiver *SessionRepository) GenerateTokenByDevice(device entity.SDevice)
 	return tokenString, rt, nil
 }
 
+// ExtractDeviceIdFromToken returns the device of a device access token
 func (receiver *SessionRepository) ExtractDeviceIdFromToken(tokenString string) (*string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+	return receiver.extractDeviceId(tokenString, false)
+}
 
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(receiver.AuthorizeEncryptKey), nil
-	})
+// GetDeviceIDFromRefreshToken returns the device of a device refresh token. The
+// refresh tokens issued before sessions existed are told apart by their lack of
+// a session.
+func (receiver *SessionRepository) GetDeviceIDFromRefreshToken(refreshToken string) (string, error) {
+	deviceId, err := receiver.extractDeviceId(refreshToken, true)
+	if err != nil {
+		return "", err
+	}
+
+	return *deviceId, nil
+}
 
+func (receiver *SessionRepository) extractDeviceId(tokenString string, refresh bool) (*string, error) {
+	token, err := receiver.ValidateToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if userId, ok := claims["device_uuid"].(string); ok {
-			return &userId, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	deviceId, ok := claims["device_uuid"].(string)
+	if !ok {
+		return nil, errors.New("token has no device")
+	}
+	if receiver.GetSessionIdFromToken(token) != "" {
+		tokenType, _ := claims["typ"].(string)
+		if (tokenType == deviceRefreshTokenType) != refresh {
+			return nil, errors.New("invalid token")
 		}
 	}
 
-	return nil, err
+	return &deviceId, nil
 }
 
-func (receiver *SessionRepository) GetDeviceIDFromRefreshToken(accessToken string) (string, error) {
-	uuid, err := receiver.ExtractDeviceIdFromToken(accessToken)
+func (receiver *SessionRepository) GeneratePassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return "", err
 	}
 
-	return *uuid, nil
+	return string(hashedPassword), nil
 }
 
-func (receiver *SessionRepository) GeneratePassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// CreateSession starts a session of the user on the device and returns it with
+// its refresh token. A user has one session per device, logging in again on a
+// device revokes the previous session on it.
+func (receiver *SessionRepository) CreateSession(userId string, deviceId string, userAgent string, ipAddress string) (*entity.SSession, string, error) {
+	secret, err := generateRefreshSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := entity.SSession{
+		ID:               uuid.NewString(),
+		UserId:           userId,
+		DeviceId:         deviceId,
+		RefreshTokenHash: hashRefreshSecret(secret),
+		UserAgent:        truncate(userAgent, 512),
+		IpAddress:        truncate(ipAddress, 64),
+		ExpiresAt:        now.Add(receiver.refreshTokenLifetime()),
+		LastUsedAt:       now,
+	}
+	err = receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		if deviceId != "" {
+			err := tx.Model(&entity.SSession{}).
+				Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userId, deviceId).
+				Updates(map[string]interface{}{
+					"revoked_at":     now,
+					"revoked_reason": "replaced",
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, session.ID + "." + secret, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same
+// session. A refresh token that was already exchanged revokes the session, as it
+// means that the token has been copied.
+func (receiver *SessionRepository) RotateRefreshToken(refreshToken string, userAgent string, ipAddress string) (*entity.SSession, string, error) {
+	sessionId, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := receiver.GetSession(sessionId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	now := time.Now()
+	err = checkRefreshSecret(*session, secret, now)
+	if errors.Is(err, errRefreshTokenReused) {
+		err = receiver.RevokeSession(session.ID, "refresh_token_reuse")
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	newSecret, err := generateRefreshSecret()
 	if err != nil {
+		return nil, "", err
+	}
+
+	// The hash is compared again in the update so that two concurrent refreshes
+	// with the same token cannot both succeed
+	result := receiver.DBConn.Model(&entity.SSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, session.RefreshTokenHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": hashRefreshSecret(newSecret),
+			"user_agent":         truncate(userAgent, 512),
+			"ip_address":         truncate(ipAddress, 64),
+			"last_used_at":       now,
+			"expires_at":         now.Add(receiver.refreshTokenLifetime()),
+		})
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	session, err = receiver.GetSession(session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, session.ID + "." + newSecret, nil
+}
+
+func parseRefreshToken(refreshToken string) (string, string, error) {
+	sessionId, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionId == "" || secret == "" || strings.Contains(secret, ".") {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	return sessionId, secret, nil
+}
+
+// checkRefreshSecret accepts the current refresh secret of an active session.
+// Any other secret of the session is one that was already exchanged.
+func checkRefreshSecret(session entity.SSession, secret string, now time.Time) error {
+	if err := checkSessionActive(session, now); err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.RefreshTokenHash), []byte(hashRefreshSecret(secret))) != 1 {
+		return errRefreshTokenReused
+	}
+
+	return nil
+}
+
+// IsSessionRefreshToken tells the refresh tokens of the sessions apart from the
+// refresh tokens of the devices, which are JWTs
+func (receiver *SessionRepository) IsSessionRefreshToken(refreshToken string) bool {
+	return strings.Count(refreshToken, ".") == 1
+}
+
+func (receiver *SessionRepository) GetSession(sessionId string) (*entity.SSession, error) {
+	var session entity.SSession
+	err := receiver.DBConn.Where("id = ?", sessionId).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveSessions lists the sessions of the user that are neither revoked nor
+// expired, the most recently used first
+func (receiver *SessionRepository) GetActiveSessions(userId string) ([]entity.SSession, error) {
+	sessions := make([]entity.SSession, 0)
+	err := receiver.DBConn.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+
+	return sessions, err
+}
+
+func (receiver *SessionRepository) RevokeSession(sessionId string, reason string) error {
+	return receiver.DBConn.Model(&entity.SSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionId).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
+
+// RevokeUserSessions revokes every active session of the user and returns how
+// many were revoked
+func (receiver *SessionRepository) RevokeUserSessions(userId string, reason string) (int64, error) {
+	result := receiver.DBConn.Model(&entity.SSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+func (receiver *SessionRepository) refreshTokenLifetime() time.Duration {
+	if receiver.RefreshTokenExpireTimeInHour <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return receiver.RefreshTokenExpireTimeInHour * time.Hour
+}
+
+func generateRefreshSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
 		return "", err
 	}
 
-	return string(hashedPassword), nil
+	return hex.EncodeToString(secret), nil
+}
+
+func hashRefreshSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
 }
@@ -10,14 +10,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// SettingRepository reads and writes the settings of one organization, the
+// global settings when OrganizationId is nil. An organization without its own
+// row of a setting reads the global one.
 type SettingRepository struct {
-	DBConn *gorm.DB
+	DBConn         *gorm.DB
+	OrganizationId *int64
 }
 
 func NewSettingRepository(dbConn *gorm.DB) *SettingRepository {
 	return &SettingRepository{DBConn: dbConn}
 }
 
+// ForOrganization returns a repository over the settings of the organization
+func (receiver *SettingRepository) ForOrganization(organizationId *int64) *SettingRepository {
+	return &SettingRepository{DBConn: receiver.DBConn, OrganizationId: organizationId}
+}
+
 func (receiver *SettingRepository) GetFormSettings() (*entity.SSetting, error) {
 	return receiver.getSettingsByType(value.SettingTypeImportForms)
 }
@@ -47,8 +56,26 @@ func (receiver *SettingRepository) GetSummarySettings() (*entity.SSetting, error
 }
 
 func (receiver *SettingRepository) getSettingsByType(settingType value.SettingType) (*entity.SSetting, error) {
+	if receiver.OrganizationId == nil {
+		return receiver.getOwnSettingsByType(settingType)
+	}
+
+	var setting entity.SSetting
+	err := receiver.DBConn.
+		Where("type = ? AND (organization_id = ? OR organization_id IS NULL)", settingType, *receiver.OrganizationId).
+		Order("organization_id IS NULL").
+		First(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// getOwnSettingsByType returns the row of the organization of the repository
+// only, the updates must not change the global row an organization falls back to
+func (receiver *SettingRepository) getOwnSettingsByType(settingType value.SettingType) (*entity.SSetting, error) {
 	var setting entity.SSetting
-	err := receiver.DBConn.Where("type = ?", settingType).First(&setting).Error
+	err := ownedBy(receiver.DBConn, receiver.OrganizationId).Where("type = ?", settingType).First(&setting).Error
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +123,7 @@ func (receiver *SettingRepository) UpdateFormSetting4(req request.ImportFormRequ
 }
 
 func (receiver *SettingRepository) updateFormSettingByType(req request.ImportFormRequest, formUploaderType value.SettingType) error {
-	setting, err := receiver.getSettingsByType(formUploaderType)
+	setting, err := receiver.getOwnSettingsByType(formUploaderType)
 	if setting == nil {
 		importSetting := ImportSetting{
 			SpreadSheetUrl: req.SpreadsheetUrl,
@@ -108,8 +135,9 @@ func (receiver *SettingRepository) updateFormSettingByType(req request.ImportFor
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     formUploaderType,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           formUploaderType,
 		})
 
 		return result.Error
@@ -134,7 +162,7 @@ func (receiver *SettingRepository) updateFormSettingByType(req request.ImportFor
 }
 
 func (receiver *SettingRepository) UpdateUrlSetting(req request.ImportRedirectUrlsRequest) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeImportUrls)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeImportUrls)
 	if setting == nil {
 		importSetting := ImportSetting{
 			SpreadSheetUrl: req.SpreadsheetUrl,
@@ -146,8 +174,9 @@ func (receiver *SettingRepository) UpdateUrlSetting(req request.ImportRedirectUr
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeImportUrls,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeImportUrls,
 		})
 
 		return result.Error
@@ -172,7 +201,7 @@ func (receiver *SettingRepository) UpdateUrlSetting(req request.ImportRedirectUr
 }
 
 func (receiver *SettingRepository) UpdateSubmissionSetting(id string, name string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSubmission)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSubmission)
 	if setting == nil {
 		importSetting := OutputSetting{
 			FolderId: id,
@@ -182,8 +211,9 @@ func (receiver *SettingRepository) UpdateSubmissionSetting(id string, name strin
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSubmission,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSubmission,
 		})
 
 		return result.Error
@@ -219,7 +249,7 @@ func (receiver *SettingRepository) GetSubmissionSetting() (OutputSetting, error)
 }
 
 func (receiver *SettingRepository) UpdateOutputSummarySetting(spreadsheetId string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSummary)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSummary)
 	if setting == nil {
 		importSetting := SummarySetting{
 			SpreadSheetId: spreadsheetId,
@@ -229,8 +259,9 @@ func (receiver *SettingRepository) UpdateOutputSummarySetting(spreadsheetId stri
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSummary,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSummary,
 		})
 
 		return result.Error
@@ -261,7 +292,7 @@ func (receiver *SettingRepository) GetSyncToDosSettings() (*entity.SSetting, err
 }
 
 func (receiver *SettingRepository) UpdateSyncToDoSetting(req request.ImportFormRequest) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSyncToDos)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSyncToDos)
 	if setting == nil {
 		importSetting := ImportSetting{
 			SpreadSheetUrl: req.SpreadsheetUrl,
@@ -273,8 +304,9 @@ func (receiver *SettingRepository) UpdateSyncToDoSetting(req request.ImportFormR
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSyncToDos,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSyncToDos,
 		})
 
 		return result.Error
@@ -303,7 +335,7 @@ func (receiver *SettingRepository) GetEmailSettings() (*entity.SSetting, error)
 }
 
 func (receiver *SettingRepository) UpdateEmaiHistorySetting(spreadsheetId string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeEmailHistory)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeEmailHistory)
 	if setting == nil {
 		importSetting := SummarySetting{
 			SpreadSheetId: spreadsheetId,
@@ -313,8 +345,9 @@ func (receiver *SettingRepository) UpdateEmaiHistorySetting(spreadsheetId string
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeEmailHistory,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeEmailHistory,
 		})
 
 		return result.Error
@@ -337,7 +370,7 @@ func (receiver *SettingRepository) UpdateEmaiHistorySetting(spreadsheetId string
 }
 
 func (receiver *SettingRepository) UpdateOutputTemplateSetting(spreadsheetId string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeOutputTemplate)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeOutputTemplate)
 	if setting == nil {
 		importSetting := SummarySetting{
 			SpreadSheetId: spreadsheetId,
@@ -347,8 +380,9 @@ func (receiver *SettingRepository) UpdateOutputTemplateSetting(spreadsheetId str
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeOutputTemplate,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeOutputTemplate,
 		})
 
 		return result.Error
@@ -379,7 +413,7 @@ func (receiver *SettingRepository) GetOutputTemplateSettingsForTeacher() (*entit
 }
 
 func (receiver *SettingRepository) UpdateOutputTemplateSettingForTeacher(spreadsheetId string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeOutputTemplateTeacher)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeOutputTemplateTeacher)
 	if setting == nil {
 		importSetting := SummarySetting{
 			SpreadSheetId: spreadsheetId,
@@ -389,8 +423,9 @@ func (receiver *SettingRepository) UpdateOutputTemplateSettingForTeacher(spreads
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeOutputTemplateTeacher,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeOutputTemplateTeacher,
 		})
 
 		return result.Error
@@ -449,7 +484,7 @@ func (receiver *SettingRepository) GetRegistrationPreset2Setting() (*entity.SSet
 }
 
 func (receiver *SettingRepository) UpdateSignUpButton1(name string, v string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButton1)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButton1)
 	if setting == nil {
 		importSetting := SignUpTextButtonSetting{
 			Name:  name,
@@ -460,8 +495,9 @@ func (receiver *SettingRepository) UpdateSignUpButton1(name string, v string) (*
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButton1,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButton1,
 		})
 
 		return nil, result.Error
@@ -485,7 +521,7 @@ func (receiver *SettingRepository) UpdateSignUpButton1(name string, v string) (*
 }
 
 func (receiver *SettingRepository) UpdateSignUpButton2(name string, v string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButton2)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButton2)
 	if setting == nil {
 		importSetting := SignUpTextButtonSetting{
 			Name:  name,
@@ -496,8 +532,9 @@ func (receiver *SettingRepository) UpdateSignUpButton2(name string, v string) (*
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButton2,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButton2,
 		})
 
 		return nil, result.Error
@@ -521,7 +558,7 @@ func (receiver *SettingRepository) UpdateSignUpButton2(name string, v string) (*
 }
 
 func (receiver *SettingRepository) UpdateSignUpButton3(name string, v string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButton3)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButton3)
 	if setting == nil {
 		importSetting := SignUpTextButtonSetting{
 			Name:  name,
@@ -532,8 +569,9 @@ func (receiver *SettingRepository) UpdateSignUpButton3(name string, v string) (*
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButton3,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButton3,
 		})
 
 		return nil, result.Error
@@ -557,7 +595,7 @@ func (receiver *SettingRepository) UpdateSignUpButton3(name string, v string) (*
 }
 
 func (receiver *SettingRepository) UpdateSignUpButton4(name string, v string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButton4)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButton4)
 	if setting == nil {
 		importSetting := SignUpTextButtonSetting{
 			Name:  name,
@@ -568,8 +606,9 @@ func (receiver *SettingRepository) UpdateSignUpButton4(name string, v string) (*
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButton4,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButton4,
 		})
 
 		return nil, result.Error
@@ -593,7 +632,7 @@ func (receiver *SettingRepository) UpdateSignUpButton4(name string, v string) (*
 }
 
 func (receiver *SettingRepository) UpdateSignUpButton5(name string, v string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButton5)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButton5)
 	if setting == nil {
 		importSetting := SignUpTextButtonSetting{
 			Name:  name,
@@ -604,8 +643,9 @@ func (receiver *SettingRepository) UpdateSignUpButton5(name string, v string) (*
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButton5,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButton5,
 		})
 
 		return nil, result.Error
@@ -629,7 +669,7 @@ func (receiver *SettingRepository) UpdateSignUpButton5(name string, v string) (*
 }
 
 func (receiver *SettingRepository) UpdateRegistrationForm(formId uint64, url string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpForm)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpForm)
 	if setting == nil {
 		importSetting := SignUpFormSetting{
 			FormId:        formId,
@@ -640,8 +680,9 @@ func (receiver *SettingRepository) UpdateRegistrationForm(formId uint64, url str
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpForm,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpForm,
 		})
 
 		return nil, result.Error
@@ -664,7 +705,7 @@ func (receiver *SettingRepository) UpdateRegistrationForm(formId uint64, url str
 }
 
 func (receiver *SettingRepository) UpdateSignUpButtonConfiguration(formId uint64, url string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpButtonConfiguration)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpButtonConfiguration)
 	type SignUpButton struct {
 		FormId        uint64 `json:"form_id"`
 		SpreadSheetId string `json:"spreadsheet_id"`
@@ -679,8 +720,9 @@ func (receiver *SettingRepository) UpdateSignUpButtonConfiguration(formId uint64
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpButtonConfiguration,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpButtonConfiguration,
 		})
 
 		return nil, result.Error
@@ -703,7 +745,7 @@ func (receiver *SettingRepository) UpdateSignUpButtonConfiguration(formId uint64
 }
 
 func (receiver *SettingRepository) UpdateRegistrationSubmission(url string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpOutput)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpOutput)
 	if setting == nil {
 		importSetting := SummarySetting{
 			SpreadSheetId: url,
@@ -713,8 +755,9 @@ func (receiver *SettingRepository) UpdateRegistrationSubmission(url string) (*en
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpOutput,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpOutput,
 		})
 
 		return nil, result.Error
@@ -736,7 +779,7 @@ func (receiver *SettingRepository) UpdateRegistrationSubmission(url string) (*en
 }
 
 func (receiver *SettingRepository) UpdateRegistrationPreset2(url string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpPresetValue2)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpPresetValue2)
 	if setting == nil {
 		importSetting := SignUpFormSetting{
 			SpreadSheetId: url,
@@ -746,8 +789,9 @@ func (receiver *SettingRepository) UpdateRegistrationPreset2(url string) (*entit
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpPresetValue2,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpPresetValue2,
 		})
 
 		return nil, result.Error
@@ -770,8 +814,7 @@ func (receiver *SettingRepository) UpdateRegistrationPreset2(url string) (*entit
 }
 
 func (receiver *SettingRepository) SetName(name string, settingType value.SettingType) error {
-	return receiver.DBConn.
-		Model(&entity.SSetting{}).
+	return ownedBy(receiver.DBConn.Model(&entity.SSetting{}), receiver.OrganizationId).
 		Where("type = ?", settingType).
 		Update("setting_name", name).
 		Error
@@ -783,7 +826,7 @@ type APIDistributorSetting struct {
 }
 
 func (receiver *SettingRepository) UpdateAPIDistributerSetting(spreadsheetId string, url string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeAPIDistributer)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeAPIDistributer)
 	if setting == nil {
 		importSetting := APIDistributorSetting{
 			Url:           url,
@@ -794,8 +837,9 @@ func (receiver *SettingRepository) UpdateAPIDistributerSetting(spreadsheetId str
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeAPIDistributer,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeAPIDistributer,
 		})
 
 		return result.Error
@@ -827,7 +871,7 @@ func (receiver *SettingRepository) GetCodeCountingDataSetting() (*entity.SSettin
 }
 
 func (receiver *SettingRepository) UpdateCodeCountingDataSetting(spreadsheetId string, url string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeCodeCountingData)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeCodeCountingData)
 	if setting == nil {
 		importSetting := APIDistributorSetting{
 			Url:           url,
@@ -838,8 +882,9 @@ func (receiver *SettingRepository) UpdateCodeCountingDataSetting(spreadsheetId s
 			return err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: b,
-			Type:     value.SettingTypeCodeCountingData,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       b,
+			Type:           value.SettingTypeCodeCountingData,
 		})
 
 		return result.Error
@@ -863,16 +908,17 @@ func (receiver *SettingRepository) UpdateCodeCountingDataSetting(spreadsheetId s
 }
 
 func (receiver *SettingRepository) UpdateLogoRefreshInterval(interval uint64) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeLogoRefreshInterval)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeLogoRefreshInterval)
 	if err != nil {
 		if setting == nil {
 			result := receiver.DBConn.Create(&entity.SSetting{
-				Type:         value.SettingTypeLogoRefreshInterval,
-				IntegerValue: interval,
+				OrganizationId: receiver.OrganizationId,
+				Type:           value.SettingTypeLogoRefreshInterval,
+				IntegerValue:   interval,
 			})
 			return result.Error
 		} else {
-			result := receiver.DBConn.Model(&entity.SSetting{}).Where("type = ?", value.SettingTypeLogoRefreshInterval).Update("settings", interval)
+			result := ownedBy(receiver.DBConn.Model(&entity.SSetting{}), receiver.OrganizationId).Where("type = ?", value.SettingTypeLogoRefreshInterval).Update("settings", interval)
 			return result.Error
 		}
 	} else {
@@ -884,12 +930,13 @@ func (receiver *SettingRepository) UpdateLogoRefreshInterval(interval uint64) er
 }
 
 func (receiver *SettingRepository) UpdateLogoRefreshTitle(title string) error {
-	setting, err := receiver.getSettingsByType(value.SettingTypeLogoRefreshInterval)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeLogoRefreshInterval)
 	if err != nil {
 		err = receiver.DBConn.Create(&entity.SSetting{
-			Type:         value.SettingTypeLogoRefreshInterval,
-			SettingName:  title,
-			IntegerValue: 10,
+			OrganizationId: receiver.OrganizationId,
+			Type:           value.SettingTypeLogoRefreshInterval,
+			SettingName:    title,
+			IntegerValue:   10,
 		}).Error
 	} else {
 		setting.SettingName = title
@@ -901,7 +948,7 @@ func (receiver *SettingRepository) UpdateLogoRefreshTitle(title string) error {
 
 func (receiver *SettingRepository) GetLogoRefreshInterval() (entity.SSetting, error) {
 	var setting entity.SSetting
-	err := receiver.DBConn.Where("type = ?", value.SettingTypeLogoRefreshInterval).First(&setting).Error
+	err := ownedBy(receiver.DBConn, receiver.OrganizationId).Where("type = ?", value.SettingTypeLogoRefreshInterval).First(&setting).Error
 	if err != nil {
 		return setting, err
 	}
@@ -927,7 +974,7 @@ func (receiver *SettingRepository) GetRegistrationPreset1Setting() (*entity.SSet
 }
 
 func (receiver *SettingRepository) UpdateRegistrationPreset1(url string) (*entity.SSetting, error) {
-	setting, err := receiver.getSettingsByType(value.SettingTypeSignUpPresetValue1)
+	setting, err := receiver.getOwnSettingsByType(value.SettingTypeSignUpPresetValue1)
 	if setting == nil {
 		importSetting := SignUpFormSetting{
 			SpreadSheetId: url,
@@ -937,8 +984,9 @@ func (receiver *SettingRepository) UpdateRegistrationPreset1(url string) (*entit
 			return nil, err
 		}
 		result := receiver.DBConn.Create(&entity.SSetting{
-			Settings: datatypes.JSON(string(b)),
-			Type:     value.SettingTypeSignUpPresetValue1,
+			OrganizationId: receiver.OrganizationId,
+			Settings:       datatypes.JSON(string(b)),
+			Type:           value.SettingTypeSignUpPresetValue1,
 		})
 
 		return nil, result.Error
@@ -962,7 +1010,7 @@ func (receiver *SettingRepository) UpdateRegistrationPreset1(url string) (*entit
 
 func FindDeviceSyncSetting(conn *gorm.DB) (entity.SSetting, error) {
 	var setting entity.SSetting
-	err := conn.Where("type = ?", value.SettingTypeSyncDevices).First(&setting).Error
+	err := ownedBy(conn, nil).Where("type = ?", value.SettingTypeSyncDevices).First(&setting).Error
 	if err != nil {
 		return setting, err
 	}
@@ -2,14 +2,19 @@ package repository
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type SubmissionRepository struct {
-	DBConn *gorm.DB
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
 }
 
 type Messaging struct {
@@ -31,6 +36,7 @@ type SubmissionData struct {
 }
 type CreateSubmissionParams struct {
 	FormId         uint64
+	FormRevisionId *uint64
 	UserId         string
 	SubmissionData SubmissionData
 	OpenedAt       time.Time
@@ -67,6 +73,7 @@ func (receiver *SubmissionRepository) CreateSubmission(params CreateSubmissionPa
 
 	submission := entity.SSubmission{
 		FormId:         params.FormId,
+		FormRevisionId: params.FormRevisionId,
 		UserId:         params.UserId,
 		SubmissionData: dataInJSON,
 		OpenedAt:       params.OpenedAt,
@@ -116,6 +123,7 @@ func (receiver *SubmissionRepository) DublicateSubmissions(params CreateSubmissi
 
 	submission := entity.SSubmission{
 		FormId:         params.FormId,
+		FormRevisionId: params.FormRevisionId,
 		UserId:         params.UserId,
 		SubmissionData: dataInJSON,
 		OpenedAt:       params.OpenedAt,
@@ -123,3 +131,81 @@ func (receiver *SubmissionRepository) DublicateSubmissions(params CreateSubmissi
 
 	return receiver.DBConn.Create(&submission).Error
 }
+
+func (receiver *SubmissionRepository) GetSubmissionList(req request.GetSubmissionListRequest) ([]entity.SSubmission, *response.Pagination, error) {
+	limit := receiver.DefaultRequestPageSize
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if req.Page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	var count int64
+	err := receiver.filterSubmissions(req).Model(&entity.SSubmission{}).Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	submissions := make([]entity.SSubmission, 0)
+	err = receiver.filterSubmissions(req).
+		Preload("User").
+		Order("s_submission.created_at DESC, s_submission.id DESC").
+		Offset(req.Page * limit).
+		Limit(limit).
+		Find(&submissions).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return submissions, &response.Pagination{
+		Page:      req.Page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
+
+// FindSubmissionsInBatches walks the filtered submissions oldest first, handing
+// them to fn batchSize at a time
+func (receiver *SubmissionRepository) FindSubmissionsInBatches(req request.GetSubmissionListRequest, batchSize int, fn func(submissions []entity.SSubmission) error) error {
+	submissions := make([]entity.SSubmission, 0, batchSize)
+	return receiver.filterSubmissions(req).
+		Preload("User").
+		FindInBatches(&submissions, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(submissions)
+		}).Error
+}
+
+// filterSubmissions applies the filters of req. Submissions do not record their
+// device or organization, those filters go through the user who submitted, as
+// does the guardian filter through the active guardians of the user.
+func (receiver *SubmissionRepository) filterSubmissions(req request.GetSubmissionListRequest) *gorm.DB {
+	query := receiver.DBConn.Model(&entity.SSubmission{})
+	if req.FormId != 0 {
+		query = query.Where("s_submission.form_id = ?", req.FormId)
+	}
+	if req.UserId != "" {
+		query = query.Where("s_submission.user_id = ?", req.UserId)
+	}
+	if req.GuardianId != "" {
+		query = query.Where("s_submission.user_id IN (SELECT user_id FROM s_user_guardians WHERE guardian_id = ?)", req.GuardianId)
+	}
+	if req.DeviceId != "" {
+		query = query.Where("s_submission.user_id IN (SELECT user_id FROM s_user_devices WHERE device_id = ?)", req.DeviceId)
+	}
+	if req.OrganizationId != 0 {
+		query = query.Where("s_submission.user_id IN (SELECT user_id FROM s_users_organization WHERE organization_id = ?)", req.OrganizationId)
+	}
+	if !req.From.IsZero() {
+		query = query.Where("s_submission.created_at >= ?", req.From)
+	}
+	if !req.To.IsZero() {
+		query = query.Where("s_submission.created_at < ?", req.To.AddDate(0, 0, 1))
+	}
+
+	return query
+}
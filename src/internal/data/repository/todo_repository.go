@@ -1,22 +1,315 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"math"
 	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
 )
 
+// ToDoRepository saves the to-do lists of OrganizationId, the shared lists when
+// it is nil
 type ToDoRepository struct {
+	OrganizationId *int64
 }
 
 func (r *ToDoRepository) Save(conn *gorm.DB, list *entity.SToDo) (entity.SToDo, error) {
+	list.NextReminderAt = nextReminderAt(list.Tasks.Data.Tasks)
 	conn.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"name", "spreadsheet_id", "sheet_name", "tasks", "history_spreadsheet_id", "history_sheet_name", "updated_at", "start_row"}),
+		DoUpdates: clause.AssignmentColumns([]string{"name", "spreadsheet_id", "sheet_name", "tasks", "history_spreadsheet_id", "history_sheet_name", "updated_at", "start_row", "next_reminder_at"}),
 	}).Create(list)
 	return *list, nil
 }
 
+// Import saves an imported to-do list as a list of OrganizationId, it fails with
+// ErrOtherOrganization when the QR code is the code of a list of another
+// organization. The database holds the tasks: the tasks of a list edited through
+// the API are kept as they are, and the completions of the tasks the sheet does
+// not show as done yet are kept. The task sheet made for a compose list is kept.
+func (r *ToDoRepository) Import(conn *gorm.DB, list *entity.SToDo) (entity.SToDo, error) {
+	var existing entity.SToDo
+	err := conn.Where("id = ?", list.ID).First(&existing).Error
+	if err == nil && !sameOrganization(existing.OrganizationId, r.OrganizationId) {
+		return *list, fmt.Errorf("%w: todo %s", ErrOtherOrganization, list.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return *list, err
+	}
+	if err == nil {
+		if list.SpreadsheetID == "" {
+			list.SpreadsheetID = existing.SpreadsheetID
+		}
+		if existing.TasksEditedAt != nil {
+			list.Name = existing.Name
+			list.Tasks = existing.Tasks
+		} else {
+			list.Tasks.Data.Tasks = keepCompletions(list.Tasks.Data.Tasks, existing.Tasks.Data.Tasks)
+		}
+	}
+
+	list.OrganizationId = r.OrganizationId
+	return r.Save(conn, list)
+}
+
+// keepCompletions carries the completion of the saved tasks over to the same
+// imported tasks when the sheet does not show them as done
+func keepCompletions(imported []entity.Task, saved []entity.Task) []entity.Task {
+	completed := make(map[int]entity.Task, len(saved))
+	for _, task := range saved {
+		if task.Selected != "" || task.CompletedAt != nil {
+			completed[task.Index] = task
+		}
+	}
+	for i, task := range imported {
+		done, ok := completed[task.Index]
+		if !ok || task.Selected != "" || done.Name != task.Name {
+			continue
+		}
+		imported[i].Selected = done.Selected
+		imported[i].CompletedAt = done.CompletedAt
+		imported[i].CompletedBy = done.CompletedBy
+	}
+
+	return imported
+}
+
+// Create saves a new to-do list of OrganizationId
+func (r *ToDoRepository) Create(conn *gorm.DB, list *entity.SToDo) error {
+	list.OrganizationId = r.OrganizationId
+	list.NextReminderAt = nextReminderAt(list.Tasks.Data.Tasks)
+	return conn.Create(list).Error
+}
+
+// UpdateToDo applies update to the list with the row locked, so that concurrent
+// changes to the tasks do not overwrite each other, then saves the name, the
+// tasks, the next reminder and the sheet sync of the list
+func (r *ToDoRepository) UpdateToDo(conn *gorm.DB, id string, update func(list *entity.SToDo) error) (*entity.SToDo, error) {
+	var list entity.SToDo
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&list).Error
+		if err != nil {
+			return err
+		}
+		err = update(&list)
+		if err != nil {
+			return err
+		}
+
+		list.UpdatedAt = time.Now()
+		list.NextReminderAt = nextReminderAt(list.Tasks.Data.Tasks)
+		return tx.Model(&list).Select("name", "tasks", "sheet_sync_disabled", "tasks_edited_at", "next_reminder_at", "updated_at").Updates(&list).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// Delete removes the list, its assignees and their progress and the changes not
+// mirrored to its spreadsheets yet, the completions of its tasks are kept
+func (r *ToDoRepository) Delete(conn *gorm.DB, id string) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.SToDo{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		err := tx.Where("todo_id = ?", id).Delete(&entity.SToDoAssignee{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("todo_id = ?", id).Delete(&entity.SToDoTaskProgress{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("todo_id = ? AND status IN ?", id, []value.ToDoSheetSyncStatus{value.ToDoSheetSyncStatus_Pending, value.ToDoSheetSyncStatus_Failed}).
+			Delete(&entity.SToDoSheetSync{}).Error
+	})
+}
+
+// nextReminderAt returns the first reminder of the tasks still to send
+func nextReminderAt(tasks []entity.Task) *time.Time {
+	var next *time.Time
+	for _, task := range tasks {
+		at := task.ReminderAt()
+		if at != nil && (next == nil || at.Before(*next)) {
+			next = at
+		}
+	}
+
+	return next
+}
+
+// GetDueReminderIDs returns the lists with a reminder due by now, the earliest
+// first
+func (r *ToDoRepository) GetDueReminderIDs(conn *gorm.DB, now time.Time, limit int) ([]string, error) {
+	ids := make([]string, 0)
+	err := conn.Model(&entity.SToDo{}).
+		Where("next_reminder_at <= ?", now).
+		Order("next_reminder_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+
+	return ids, err
+}
+
+func (r *ToDoRepository) GetAssignees(conn *gorm.DB, id string) ([]entity.SToDoAssignee, error) {
+	assignees := make([]entity.SToDoAssignee, 0)
+	err := conn.Where("todo_id = ?", id).Order("assignee_type ASC, assignee_id ASC").Find(&assignees).Error
+
+	return assignees, err
+}
+
+// ReplaceAssignees makes the assignees the only assignees of the list
+func (r *ToDoRepository) ReplaceAssignees(conn *gorm.DB, id string, assignees []entity.SToDoAssignee) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("todo_id = ?", id).Delete(&entity.SToDoAssignee{}).Error
+		if err != nil || len(assignees) == 0 {
+			return err
+		}
+
+		return tx.Create(&assignees).Error
+	})
+}
+
+// GetUserDeviceIds returns the devices of the users, by user
+func (r *ToDoRepository) GetUserDeviceIds(conn *gorm.DB, userIds []string) (map[string][]string, error) {
+	devices := make(map[string][]string, len(userIds))
+	if len(userIds) == 0 {
+		return devices, nil
+	}
+
+	var rows []struct {
+		UserId   string
+		DeviceId string
+	}
+	err := conn.Table("s_user_devices").Select("user_id", "device_id").Where("user_id IN ?", userIds).Order("device_id ASC").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		devices[row.UserId] = append(devices[row.UserId], row.DeviceId)
+	}
+
+	return devices, nil
+}
+
+// GetAssignableDevices returns the id, name and group of the devices, and of
+// the devices in the groups
+func (r *ToDoRepository) GetAssignableDevices(conn *gorm.DB, deviceIds []string, groupIds []string) ([]entity.SDevice, error) {
+	devices := make([]entity.SDevice, 0)
+	if len(deviceIds) == 0 && len(groupIds) == 0 {
+		return devices, nil
+	}
+
+	query := conn.Model(&entity.SDevice{}).Select("id", "device_name", "group_id")
+	switch {
+	case len(deviceIds) == 0:
+		query = query.Where("group_id IN ?", groupIds)
+	case len(groupIds) == 0:
+		query = query.Where("id IN ?", deviceIds)
+	default:
+		query = query.Where("id IN ? OR group_id IN ?", deviceIds, groupIds)
+	}
+	err := query.Order("id ASC").Find(&devices).Error
+
+	return devices, err
+}
+
+// GetUserNames returns the usernames of the users, by user
+func (r *ToDoRepository) GetUserNames(conn *gorm.DB, userIds []string) (map[string]string, error) {
+	names := make(map[string]string, len(userIds))
+	if len(userIds) == 0 {
+		return names, nil
+	}
+
+	var rows []struct {
+		Id       string
+		Username string
+	}
+	err := conn.Model(&entity.SUserEntity{}).Select("id", "username").Where("id IN ?", userIds).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		names[row.Id] = row.Username
+	}
+
+	return names, nil
+}
+
+// SaveProgress records that an assignee completed a task, a new completion
+// replaces the one before
+func (r *ToDoRepository) SaveProgress(conn *gorm.DB, progress *entity.SToDoTaskProgress) error {
+	return conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "todo_id"}, {Name: "task_index"}, {Name: "assignee_type"}, {Name: "assignee_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"selected", "device_id", "completed_at"}),
+	}).Create(progress).Error
+}
+
+func (r *ToDoRepository) GetProgress(conn *gorm.DB, id string) ([]entity.SToDoTaskProgress, error) {
+	progress := make([]entity.SToDoTaskProgress, 0)
+	err := conn.Where("todo_id = ?", id).Order("task_index ASC").Find(&progress).Error
+
+	return progress, err
+}
+
+// DeleteProgress forgets who completed a task, its index may be given to a new
+// task
+func (r *ToDoRepository) DeleteProgress(conn *gorm.DB, id string, taskIndex int) error {
+	return conn.Where("todo_id = ? AND task_index = ?", id, taskIndex).Delete(&entity.SToDoTaskProgress{}).Error
+}
+
+// GetToDos lists the to-do lists of conn by QR code, those whose QR code or name
+// contains the keyword when one is given
+func (r *ToDoRepository) GetToDos(conn *gorm.DB, req request.GetToDoListRequest, defaultLimit int) ([]entity.SToDo, *response.Pagination, error) {
+	limit := defaultLimit
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if req.Page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	query := conn.Model(&entity.SToDo{})
+	if req.Keyword != "" {
+		query = query.Where("id LIKE ? OR name LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
+	}
+
+	var count int64
+	err := query.Session(&gorm.Session{}).Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lists := make([]entity.SToDo, 0)
+	err = query.Order("id ASC").Offset(req.Page * limit).Limit(limit).Find(&lists).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lists, &response.Pagination{
+		Page:      req.Page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
+
 func (r *ToDoRepository) GetToDoListByQRCode(code string, dbConn *gorm.DB) (entity.SToDo, error) {
 	var todo entity.SToDo
 	dbConn.Where("id = ?", code).First(&todo)
@@ -34,3 +327,59 @@ func (r *ToDoRepository) FindById(id string, db *gorm.DB) (*entity.SToDo, error)
 
 	return &todo, err
 }
+
+func (r *ToDoRepository) CreateCompletion(conn *gorm.DB, completion *entity.SToDoCompletion) error {
+	return conn.Create(completion).Error
+}
+
+// GetCompletions lists the completed tasks, newest first. The user filter matches
+// the tasks completed from the devices of the user, the guardian filter those
+// completed from the devices of the children of the guardian.
+func (r *ToDoRepository) GetCompletions(conn *gorm.DB, req request.GetToDoCompletionsRequest, defaultLimit int) ([]entity.SToDoCompletion, *response.Pagination, error) {
+	limit := defaultLimit
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if req.Page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	query := conn.Model(&entity.SToDoCompletion{})
+	if req.ToDoId != "" {
+		query = query.Where("todo_id = ?", req.ToDoId)
+	}
+	if req.UserId != "" {
+		query = query.Where("device_id IN (SELECT device_id FROM s_user_devices WHERE user_id = ?)", req.UserId)
+	}
+	if req.GuardianId != "" {
+		query = query.Where("device_id IN (SELECT sud.device_id FROM s_user_devices sud JOIN s_user_guardians sug ON sug.user_id = sud.user_id WHERE sug.guardian_id = ?)", req.GuardianId)
+	}
+	if !req.From.IsZero() {
+		query = query.Where("completed_at >= ?", req.From)
+	}
+	if !req.To.IsZero() {
+		query = query.Where("completed_at < ?", req.To.AddDate(0, 0, 1))
+	}
+
+	var count int64
+	err := query.Session(&gorm.Session{}).Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	completions := make([]entity.SToDoCompletion, 0)
+	err = query.Order("completed_at DESC, id DESC").Offset(req.Page * limit).Limit(limit).Find(&completions).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return completions, &response.Pagination{
+		Page:      req.Page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
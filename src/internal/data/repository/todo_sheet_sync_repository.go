@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"math"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ToDoSheetSyncRepository struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func (receiver *ToDoSheetSyncRepository) CreateSync(sync *entity.SToDoSheetSync) error {
+	return receiver.DBConn.Create(sync).Error
+}
+
+func (receiver *ToDoSheetSyncRepository) GetSync(id uint64) (*entity.SToDoSheetSync, error) {
+	var sync entity.SToDoSheetSync
+	err := receiver.DBConn.Where("id = ?", id).First(&sync).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &sync, nil
+}
+
+// GetSyncs lists the sheet syncs of a to-do list, newest first, only those in the
+// status when one is given
+func (receiver *ToDoSheetSyncRepository) GetSyncs(todoId string, status *value.ToDoSheetSyncStatus, page int, limit int) ([]entity.SToDoSheetSync, *response.Pagination, error) {
+	if limit <= 0 {
+		limit = receiver.DefaultRequestPageSize
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := receiver.DBConn.Model(&entity.SToDoSheetSync{}).Where("todo_id = ?", todoId)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var count int64
+	err := query.Session(&gorm.Session{}).Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	syncs := make([]entity.SToDoSheetSync, 0)
+	err = query.Order("id DESC").Offset(page * limit).Limit(limit).Find(&syncs).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return syncs, &response.Pagination{
+		Page:      page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
+
+// HasPendingSync reports whether a sync of the kind is waiting for its first
+// attempt or for a retry
+func (receiver *ToDoSheetSyncRepository) HasPendingSync(todoId string, kind value.ToDoSheetSyncKind) (bool, error) {
+	var count int64
+	err := receiver.DBConn.Model(&entity.SToDoSheetSync{}).
+		Where("todo_id = ? AND kind = ? AND status = ?", todoId, kind, value.ToDoSheetSyncStatus_Pending).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// ClaimSync marks a pending sync as syncing. It returns false when another
+// worker got to it first.
+func (receiver *ToDoSheetSyncRepository) ClaimSync(id uint64) (bool, error) {
+	result := receiver.DBConn.Model(&entity.SToDoSheetSync{}).
+		Where("id = ? AND status = ?", id, value.ToDoSheetSyncStatus_Pending).
+		Updates(map[string]interface{}{"status": value.ToDoSheetSyncStatus_Syncing, "updated_at": time.Now()})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (receiver *ToDoSheetSyncRepository) SaveSyncAttempt(sync *entity.SToDoSheetSync) error {
+	return receiver.DBConn.Model(sync).Select("status", "attempts", "next_attempt_at", "last_error", "synced_at", "updated_at").Updates(sync).Error
+}
+
+// RetrySync puts a failed sync back in the queue for a new round of attempts,
+// gorm.ErrRecordNotFound when it did not fail
+func (receiver *ToDoSheetSyncRepository) RetrySync(id uint64) error {
+	now := time.Now()
+	result := receiver.DBConn.Model(&entity.SToDoSheetSync{}).
+		Where("id = ? AND status = ?", id, value.ToDoSheetSyncStatus_Failed).
+		Updates(map[string]interface{}{
+			"status":          value.ToDoSheetSyncStatus_Pending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDueSyncIDs returns the pending syncs whose next attempt is due, and the
+// syncs stuck in syncing since before staleBefore. The syncs of a list are
+// returned in the order they were made.
+func (receiver *ToDoSheetSyncRepository) GetDueSyncIDs(now time.Time, staleBefore time.Time, limit int) ([]uint64, error) {
+	err := receiver.DBConn.Model(&entity.SToDoSheetSync{}).
+		Where("status = ? AND updated_at < ?", value.ToDoSheetSyncStatus_Syncing, staleBefore).
+		Update("status", value.ToDoSheetSyncStatus_Pending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0)
+	err = receiver.DBConn.Model(&entity.SToDoSheetSync{}).
+		Where("status = ? AND next_attempt_at <= ?", value.ToDoSheetSyncStatus_Pending, now).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+
+	return ids, err
+}
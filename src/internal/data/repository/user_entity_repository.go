@@ -138,7 +138,7 @@ func (receiver *UserEntityRepository) CreateUser(req request.CreateUserEntityReq
 	}
 
 	var setting entity.SSetting
-	err = tx.Table("s_setting").Where("type = ?", value.SettingTypeSignUpPresetValue1).First(&setting).Error
+	err = tx.Table("s_setting").Where("type = ? AND organization_id IS NULL", value.SettingTypeSignUpPresetValue1).First(&setting).Error
 
 	if err != nil {
 		log.Error("UserRepository.CreateUser: " + err.Error())
@@ -181,6 +181,12 @@ func (receiver *UserEntityRepository) CreateUser(req request.CreateUserEntityReq
 				return errors.New("failed to create user guardian")
 			}
 		}
+		err = syncGuardianLinks(tx, userReq.ID.String(), *req.Guardians)
+		if err != nil {
+			log.Error("UserRepository.CreateUser: " + err.Error())
+			tx.Rollback()
+			return errors.New("failed to create user guardian")
+		}
 	}
 
 	if req.Roles != nil && len(*req.Roles) > 0 {
@@ -344,6 +350,7 @@ func (receiver *UserEntityRepository) UpdateUserGuardian(req request.UpdateUserG
 		return errors.New("failed to delete user guardian")
 	}
 
+	guardianIds := make([]string, 0, len(req.Guardians))
 	for _, guardianId := range req.Guardians {
 		// check guardian user is not exist
 		guardian, err := receiver.GetByID(request.GetUserEntityByIdRequest{ID: guardianId})
@@ -362,6 +369,13 @@ func (receiver *UserEntityRepository) UpdateUserGuardian(req request.UpdateUserG
 			log.Errorf("UserEntityRepository.UpdateUser: %v", userGuardianResult.Error)
 			return errors.New("failed to create user guardian")
 		}
+		guardianIds = append(guardianIds, guardian.ID.String())
+	}
+
+	err = syncGuardianLinks(receiver.DBConn, user.ID.String(), guardianIds)
+	if err != nil {
+		log.Errorf("UserEntityRepository.UpdateUser: %v", err)
+		return errors.New("failed to update guardian links")
 	}
 
 	return nil
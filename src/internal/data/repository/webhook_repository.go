@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func (receiver *WebhookRepository) CreateWebhook(webhook *entity.SWebhook) error {
+	return receiver.DBConn.Create(webhook).Error
+}
+
+func (receiver *WebhookRepository) UpdateWebhook(webhook *entity.SWebhook) error {
+	return receiver.DBConn.Save(webhook).Error
+}
+
+// DeleteWebhook deletes a webhook together with its delivery log
+func (receiver *WebhookRepository) DeleteWebhook(id uint64) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&entity.SWebhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("webhook_id = ?", id).Delete(&entity.SWebhookDelivery{}).Error
+	})
+}
+
+func (receiver *WebhookRepository) GetWebhook(id uint64) (*entity.SWebhook, error) {
+	var webhook entity.SWebhook
+	err := receiver.DBConn.Where("id = ?", id).First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (receiver *WebhookRepository) GetWebhooks() ([]entity.SWebhook, error) {
+	webhooks := make([]entity.SWebhook, 0)
+	err := receiver.DBConn.Order("id ASC").Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (receiver *WebhookRepository) GetActiveWebhooks() ([]entity.SWebhook, error) {
+	webhooks := make([]entity.SWebhook, 0)
+	err := receiver.DBConn.Where("status = ?", value.Active).Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (receiver *WebhookRepository) CreateDelivery(delivery *entity.SWebhookDelivery) error {
+	return receiver.DBConn.Create(delivery).Error
+}
+
+func (receiver *WebhookRepository) GetDelivery(id uint64) (*entity.SWebhookDelivery, error) {
+	var delivery entity.SWebhookDelivery
+	err := receiver.DBConn.Preload("Webhook").Where("id = ?", id).First(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// GetDeliveries lists the deliveries of a webhook newest first, page is 0-based
+func (receiver *WebhookRepository) GetDeliveries(webhookID uint64, status string, page int, limit int) ([]entity.SWebhookDelivery, *response.Pagination, error) {
+	if limit <= 0 {
+		limit = receiver.DefaultRequestPageSize
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if page < 0 {
+		return nil, nil, errors.New("invalid page number")
+	}
+
+	query := receiver.DBConn.Model(&entity.SWebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries := make([]entity.SWebhookDelivery, 0)
+	err = query.Order("id DESC").Offset(page * limit).Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deliveries, &response.Pagination{
+		Page:      page,
+		Limit:     limit,
+		TotalPage: int(math.Ceil(float64(count) / float64(limit))),
+		Total:     count,
+	}, nil
+}
+
+// ClaimDelivery marks a pending delivery as being delivered. It returns false when
+// another worker got to it first.
+func (receiver *WebhookRepository) ClaimDelivery(id uint64) (bool, error) {
+	result := receiver.DBConn.Model(&entity.SWebhookDelivery{}).
+		Where("id = ? AND status = ?", id, value.WebhookDeliveryStatus_Pending).
+		Updates(map[string]interface{}{"status": value.WebhookDeliveryStatus_Delivering, "updated_at": time.Now()})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (receiver *WebhookRepository) SaveDeliveryAttempt(delivery *entity.SWebhookDelivery) error {
+	return receiver.DBConn.Model(delivery).Select("status", "attempts", "next_attempt_at", "response_status", "response_body", "last_error", "delivered_at", "updated_at").Updates(delivery).Error
+}
+
+// GetDueDeliveryIDs returns the pending deliveries whose next attempt is due, and
+// the deliveries stuck in delivering since before staleBefore
+func (receiver *WebhookRepository) GetDueDeliveryIDs(now time.Time, staleBefore time.Time, limit int) ([]uint64, error) {
+	err := receiver.DBConn.Model(&entity.SWebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", value.WebhookDeliveryStatus_Delivering, staleBefore).
+		Update("status", value.WebhookDeliveryStatus_Pending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0)
+	err = receiver.DBConn.Model(&entity.SWebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", value.WebhookDeliveryStatus_Pending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+
+	return ids, err
+}
@@ -35,6 +35,7 @@ func Seed(db *gorm.DB, config *common.Config, seedSQLFile string) error {
 		&entity.SToDo{},
 		&entity.SSubmission{},
 		&entity.SFormQuestion{},
+		&entity.SFormRevision{},
 		&entity.SMobileDevice{},
 		&entity.SCodeCounting{},
 		&entity.SDevice{},
@@ -49,6 +50,28 @@ func Seed(db *gorm.DB, config *common.Config, seedSQLFile string) error {
 		&entity.SUserDevices{},
 		&entity.SUsersOrganization{},
 		&entity.SImage{},
+		&entity.SWebhook{},
+		&entity.SWebhookDelivery{},
+		&entity.SAuditLog{},
+		&entity.SSession{},
+		&entity.SDeviceHeartbeat{},
+		&entity.SDeviceCommand{},
+		&entity.SDeviceGroup{},
+		&entity.SDeviceTag{},
+		&entity.SDeviceConfiguration{},
+		&entity.SDeviceModeSchedule{},
+		&entity.SDeviceModeOverride{},
+		&entity.SDeviceScheduledMode{},
+		&entity.SDeviceEnrollmentCode{},
+		&entity.SDeviceEnrollment{},
+		&entity.SOrganizationInvitation{},
+		&entity.SOrganizationInvitationReply{},
+		&entity.SGuardianLink{},
+		&entity.SToDoCompletion{},
+		&entity.SToDoSheetSync{},
+		&entity.SToDoAssignee{},
+		&entity.SToDoTaskProgress{},
+		&entity.SRedirectUrlScan{},
 	)
 
 	// Seed
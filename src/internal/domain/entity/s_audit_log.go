@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SAuditLog records a change made through the user, role, role claim and role
+// policy management routes
+type SAuditLog struct {
+	ID             uint64         `gorm:"primary_key;auto_increment"`
+	ActorId        string         `gorm:"type:char(36);index;not null"`
+	OrganizationId *int64         `gorm:"index;default:null"`
+	Action         string         `gorm:"type:varchar(64);index;not null"`
+	TargetType     string         `gorm:"type:varchar(64);not null"`
+	TargetId       string         `gorm:"type:varchar(64);not null;default:''"`
+	Details        datatypes.JSON `gorm:"type:json"`
+	IpAddress      string         `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt      time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null;index"`
+}
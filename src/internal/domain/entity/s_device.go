@@ -18,6 +18,10 @@ type SDevice struct {
 	RowNo                   int                    `gorm:"type:int;not null;default:0"`
 	DeviceComponentValuesID int64                  `gorm:"column:device_component_values_id;default:1"`
 	DeviceComponentValues   SDeviceComponentValues `gorm:"foreignKey:DeviceComponentValuesID;references:id;constraint:OnDelete:CASCADE"`
+	OrganizationId          *int64                 `gorm:"default:null;index"`
+	GroupId                 *uint64                `gorm:"default:null;index"`
+	LastHeartbeatAt         *time.Time             `gorm:"default:null;index"`
+	OfflineAlertedAt        *time.Time             `gorm:"default:null"`
 	CreatedAt               time.Time              `gorm:"default:CURRENT_TIMESTAMP;not null"`
 	UpdatedAt               time.Time              `gorm:"default:CURRENT_TIMESTAMP;not null"`
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SDeviceCommand is a command queued for a device. It is pushed through FCM when
+// queued and handed out again by the pull endpoint until the device acknowledges
+// it, a command not done by ExpiresAt expires.
+type SDeviceCommand struct {
+	ID             string                    `gorm:"type:char(36);primary_key"`
+	DeviceId       string                    `gorm:"type:varchar(36);not null;index:idx_device_command_device_status,priority:1"`
+	Type           value.DeviceCommandType   `gorm:"type:varchar(32);not null"`
+	Payload        datatypes.JSON            `gorm:"type:json"`
+	Status         value.DeviceCommandStatus `gorm:"type:varchar(16);not null;default:'pending';index:idx_device_command_device_status,priority:2;index:idx_device_command_status_expires,priority:1"`
+	IssuedBy       string                    `gorm:"type:varchar(36);not null;default:''"`
+	ExpiresAt      time.Time                 `gorm:"not null;index:idx_device_command_status_expires,priority:2"`
+	PushedAt       *time.Time                `gorm:"default:null"`
+	PushError      string                    `gorm:"type:text"`
+	DeliveredAt    *time.Time                `gorm:"default:null"`
+	AcknowledgedAt *time.Time                `gorm:"default:null"`
+	CompletedAt    *time.Time                `gorm:"default:null"`
+	Result         string                    `gorm:"type:text"`
+	CreatedAt      time.Time                 `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time                 `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SDeviceConfiguration is the device configuration set at one scope: a device, a
+// device group, an organization or globally (ScopeId is empty). The fields left
+// null are inherited from the scope below, see DeviceConfigurationUseCase.
+type SDeviceConfiguration struct {
+	ID                      uint64                         `gorm:"primary_key;auto_increment"`
+	Scope                   value.DeviceConfigurationScope `gorm:"type:varchar(16);not null;uniqueIndex:idx_device_configuration_scope,priority:1"`
+	ScopeId                 string                         `gorm:"type:varchar(36);not null;default:'';uniqueIndex:idx_device_configuration_scope,priority:2"`
+	Mode                    *value.DeviceMode              `gorm:"type:varchar(32);default:null"`
+	FormIds                 datatypes.JSON                 `gorm:"type:json"`
+	DeviceComponentValuesId *int64                         `gorm:"default:null"`
+	CreatedAt               time.Time                      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt               time.Time                      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
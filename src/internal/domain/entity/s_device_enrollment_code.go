@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+// SDeviceEnrollmentCode lets up to MaxUses devices enroll themselves until
+// ExpiresAt. Enrolled devices join the organization and the group of the code,
+// start in its mode and sign in as its user. Only the hash of the code is kept,
+// CodeHint is the end of the code to tell codes apart.
+type SDeviceEnrollmentCode struct {
+	ID             uint64            `gorm:"primary_key;auto_increment"`
+	CodeHash       string            `gorm:"type:char(64);not null;uniqueIndex"`
+	CodeHint       string            `gorm:"type:varchar(8);not null;default:''"`
+	Name           string            `gorm:"type:varchar(255);not null;default:''"`
+	OrganizationId int64             `gorm:"not null;index"`
+	GroupId        *uint64           `gorm:"default:null"`
+	Mode           *value.DeviceMode `gorm:"type:varchar(32);default:null"`
+	UserId         string            `gorm:"type:varchar(36);not null"`
+	MaxUses        uint              `gorm:"not null;default:1"`
+	Uses           uint              `gorm:"not null;default:0"`
+	ExpiresAt      time.Time         `gorm:"not null"`
+	RevokedAt      *time.Time        `gorm:"default:null"`
+	CreatedBy      string            `gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt      time.Time         `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time         `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+func (receiver SDeviceEnrollmentCode) IsActive(now time.Time) bool {
+	return receiver.RevokedAt == nil && receiver.Uses < receiver.MaxUses && now.Before(receiver.ExpiresAt)
+}
+
+// SDeviceEnrollment records a device enrolled with an enrollment code
+type SDeviceEnrollment struct {
+	ID         uint64    `gorm:"primary_key;auto_increment"`
+	CodeId     uint64    `gorm:"not null;index"`
+	DeviceId   string    `gorm:"type:varchar(36);not null;index"`
+	AppVersion string    `gorm:"type:varchar(255);not null;default:''"`
+	IpAddress  string    `gorm:"type:varchar(64);not null;default:''"`
+	EnrolledAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// SDeviceGroup is a named set of devices of an organization, such as a
+// classroom. A device is in one group at most, see SDevice.GroupId.
+type SDeviceGroup struct {
+	ID             uint64    `gorm:"primary_key;auto_increment"`
+	OrganizationId *int64    `gorm:"default:null;index"`
+	Name           string    `gorm:"type:varchar(255);not null"`
+	Description    string    `gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// SDeviceTag is a free-form tag of a device, such as "front-desk"
+type SDeviceTag struct {
+	DeviceId string `gorm:"type:varchar(36);primary_key"`
+	Tag      string `gorm:"type:varchar(64);primary_key;index"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// SDeviceHeartbeat is one heartbeat reported by a device, the time-series behind
+// the presence of the devices
+type SDeviceHeartbeat struct {
+	ID               uint64    `gorm:"primary_key;auto_increment"`
+	DeviceId         string    `gorm:"type:varchar(36);not null;index:idx_device_heartbeat_device_created,priority:1"`
+	BatteryLevel     *int      `gorm:"type:tinyint;default:null"`
+	IsCharging       *bool     `gorm:"default:null"`
+	AppVersion       string    `gorm:"type:varchar(255);not null;default:''"`
+	IpAddress        string    `gorm:"type:varchar(64);not null;default:''"`
+	CurrentFormId    *uint64   `gorm:"default:null"`
+	FreeStorageBytes *int64    `gorm:"default:null"`
+	CreatedAt        time.Time `gorm:"default:CURRENT_TIMESTAMP;not null;index:idx_device_heartbeat_device_created,priority:2;index"`
+}
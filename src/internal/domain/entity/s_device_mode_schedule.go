@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+// SDeviceModeSchedule puts the devices of its scope, a device or a device group,
+// in a mode during a recurring window. The window is in the timezone of the
+// organization of the devices.
+type SDeviceModeSchedule struct {
+	ID        uint64                         `gorm:"primary_key;auto_increment"`
+	Scope     value.DeviceConfigurationScope `gorm:"type:varchar(16);not null;index:idx_device_mode_schedule_scope,priority:1"`
+	ScopeId   string                         `gorm:"type:varchar(36);not null;index:idx_device_mode_schedule_scope,priority:2"`
+	Name      string                         `gorm:"type:varchar(255);not null;default:''"`
+	Mode      value.DeviceMode               `gorm:"type:varchar(32);not null"`
+	Days      value.Weekdays                 `gorm:"not null"`
+	StartTime string                         `gorm:"type:varchar(5);not null"`
+	EndTime   string                         `gorm:"type:varchar(5);not null"`
+	Enabled   bool                           `gorm:"not null;default:true"`
+	CreatedBy string                         `gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt time.Time                      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt time.Time                      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// SDeviceModeOverride is a one-off exception to the schedules of a device or a
+// device group between StartsAt and EndsAt. It puts the devices in Mode, or
+// leaves them in their configured mode when Mode is null.
+type SDeviceModeOverride struct {
+	ID        uint64                         `gorm:"primary_key;auto_increment"`
+	Scope     value.DeviceConfigurationScope `gorm:"type:varchar(16);not null;index:idx_device_mode_override_scope,priority:1"`
+	ScopeId   string                         `gorm:"type:varchar(36);not null;index:idx_device_mode_override_scope,priority:2"`
+	Mode      *value.DeviceMode              `gorm:"type:varchar(32);default:null"`
+	StartsAt  time.Time                      `gorm:"not null"`
+	EndsAt    time.Time                      `gorm:"not null;index"`
+	Reason    string                         `gorm:"type:varchar(255);not null;default:''"`
+	CreatedBy string                         `gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt time.Time                      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// SDeviceScheduledMode is the mode the schedules and overrides currently put a
+// device in, a device without one runs with its configured mode
+type SDeviceScheduledMode struct {
+	DeviceId string                         `gorm:"type:varchar(36);primary_key"`
+	Mode     value.DeviceMode               `gorm:"type:varchar(32);not null"`
+	Source   value.DeviceConfigurationScope `gorm:"type:varchar(16);not null"`
+	SourceId uint64                         `gorm:"not null"`
+	Since    time.Time                      `gorm:"not null"`
+}
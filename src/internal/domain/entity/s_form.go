@@ -10,6 +10,7 @@ import (
 
 type SForm struct {
 	ID             uint64         `gorm:"primary_key;AUTO_INCREMENT"`
+	OrganizationId *int64         `gorm:"default:null;index"`
 	Note           string         `gorm:"type:varchar(255);not null;unique"`
 	Name           string         `gorm:"type:varchar(1000);not null;default:''"`
 	SpreadsheetUrl string         `gorm:"type:varchar(255);not null"`
@@ -1,14 +1,31 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"sen-global-api/internal/domain/value"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// QuestionRule controls whether a question is shown. A show_if rule hides its
+// question unless the answer of Question matches, a skip_to rule jumps from its
+// question to Target (the end of the form when empty) when its own answer matches.
+type QuestionRule struct {
+	Action   value.QuestionRuleAction   `json:"action"`
+	Question string                     `json:"question,omitempty"`
+	Operator value.QuestionRuleOperator `json:"operator"`
+	Value    string                     `json:"value,omitempty"`
+	Target   string                     `json:"target,omitempty"`
+}
 
 type SFormQuestion struct {
-	FormId         uint64    `gorm:"not null;primary_key"`
-	QuestionId     uuid.UUID `gorm:"type:char(36);primary_key"`
-	CreatedAt      string    `gorm:"default:CURRENT_TIMESTAMP;not null"`
-	UpdatedAt      string    `gorm:"default:CURRENT_TIMESTAMP;not null"`
-	Order          int       `gorm:"type:int;not null;default:0"`
-	AnswerRequired bool      `gorm:"type:tinyint(1);not null;default:0"`
-	Form           SForm     `gorm:"constraint:OnDelete:CASCADE;"`
-	Question       SQuestion `gorm:"constraint:OnDelete:CASCADE;"`
+	FormId         uint64         `gorm:"not null;primary_key"`
+	QuestionId     uuid.UUID      `gorm:"type:char(36);primary_key"`
+	CreatedAt      string         `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      string         `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	Order          int            `gorm:"type:int;not null;default:0"`
+	AnswerRequired bool           `gorm:"type:tinyint(1);not null;default:0"`
+	Rules          datatypes.JSON `gorm:"type:json;default:null"`
+	Form           SForm          `gorm:"constraint:OnDelete:CASCADE;"`
+	Question       SQuestion      `gorm:"constraint:OnDelete:CASCADE;"`
 }
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"encoding/json"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type FormRevisionQuestion struct {
+	QuestionId       string                  `json:"question_id"`
+	QuestionName     string                  `json:"question_name"`
+	QuestionType     string                  `json:"question_type"`
+	Question         string                  `json:"question"`
+	Attributes       json.RawMessage         `json:"attributes"`
+	Status           string                  `json:"status"`
+	Order            int                     `json:"order"`
+	AnswerRequired   bool                    `json:"answer_required"`
+	EnableOnMobile   value.QuestionForMobile `json:"enable_on_mobile"`
+	QuestionUniqueId *string                 `json:"question_unique_id"`
+	Rules            json.RawMessage         `json:"rules,omitempty"`
+}
+
+type FormRevisionSnapshot struct {
+	Questions []FormRevisionQuestion `json:"questions"`
+}
+
+// SFormRevision is an immutable snapshot of the questions of a form. Only the
+// status of a revision changes once it is created.
+type SFormRevision struct {
+	ID          uint64                   `gorm:"primary_key;auto_increment"`
+	FormId      uint64                   `gorm:"not null;uniqueIndex:idx_form_revision"`
+	Form        SForm                    `gorm:"foreignKey:FormId;references:id;constraint:OnDelete:CASCADE"`
+	Revision    int                      `gorm:"type:int;not null;uniqueIndex:idx_form_revision"`
+	Status      value.FormRevisionStatus `gorm:"type:varchar(16);not null;default:'draft'"`
+	Snapshot    datatypes.JSON           `gorm:"type:json;not null"`
+	Note        string                   `gorm:"type:varchar(255);not null;default:''"`
+	PublishedAt *time.Time               `gorm:"default:null"`
+	CreatedAt   time.Time                `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt   time.Time                `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
@@ -7,17 +7,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// SOrganization is an organization, OwnerId is the member holding every
+// permission in it. Password is the retired shared password of the legacy join,
+// empty for the organizations that only take invitations.
 type SOrganization struct {
 	ID               int64     `gorm:"column:id;primary_key;AUTO_INCREMENT"`
 	OrganizationName string    `gorm:"type:varchar(255);not null;"`
 	Password         string    `gorm:"type:varchar(255);not null;default:''"`
 	Address          string    `gorm:"type:varchar(255);not null;default:''"`
 	Description      string    `gorm:"type:varchar(255);not null;default:''"`
+	Timezone         string    `gorm:"type:varchar(64);not null;default:''"`
+	OwnerId          string    `gorm:"type:varchar(36);not null;default:''"`
 	CreatedAt        time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
 	UpdatedAt        time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
 }
 
 func (organization *SOrganization) BeforeCreate(tx *gorm.DB) (err error) {
+	if organization.Password == "" {
+		return nil
+	}
 	encryptedPwdData, err := bcrypt.GenerateFromPassword([]byte(organization.Password), bcrypt.DefaultCost)
 	if err == nil {
 		organization.Password = string(encryptedPwdData)
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SOrganizationInvitation lets up to MaxUses users join an organization until
+// ExpiresAt, with the roles of RoleIds. An invitation with an Email is for the
+// user with that email only and is closed when they decline it. Only the hash of
+// the code is kept, CodeHint is the end of the code to tell invitations apart.
+type SOrganizationInvitation struct {
+	ID             uint64         `gorm:"primary_key;auto_increment"`
+	CodeHash       string         `gorm:"type:char(64);not null;uniqueIndex"`
+	CodeHint       string         `gorm:"type:varchar(8);not null;default:''"`
+	OrganizationId int64          `gorm:"not null;index"`
+	Email          string         `gorm:"type:varchar(255);not null;default:''"`
+	RoleIds        datatypes.JSON `gorm:"type:json"`
+	MaxUses        uint           `gorm:"not null;default:1"`
+	Uses           uint           `gorm:"not null;default:0"`
+	ExpiresAt      time.Time      `gorm:"not null"`
+	RevokedAt      *time.Time     `gorm:"default:null"`
+	DeclinedAt     *time.Time     `gorm:"default:null"`
+	CreatedBy      string         `gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt      time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+func (receiver SOrganizationInvitation) IsActive(now time.Time) bool {
+	return receiver.RevokedAt == nil && receiver.DeclinedAt == nil && receiver.Uses < receiver.MaxUses && now.Before(receiver.ExpiresAt)
+}
+
+// SOrganizationInvitationReply records a user accepting or declining an
+// invitation, the last answer of a user is kept
+type SOrganizationInvitationReply struct {
+	ID           uint64                `gorm:"primary_key;auto_increment"`
+	InvitationId uint64                `gorm:"not null;uniqueIndex:idx_organization_invitation_reply,priority:1"`
+	UserId       string                `gorm:"type:varchar(36);not null;uniqueIndex:idx_organization_invitation_reply,priority:2"`
+	Reply        value.InvitationReply `gorm:"type:varchar(16);not null"`
+	RepliedAt    time.Time             `gorm:"not null"`
+}
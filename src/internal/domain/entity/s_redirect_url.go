@@ -1,14 +1,60 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"sen-global-api/internal/domain/value"
+	"time"
 
+	"gorm.io/datatypes"
+)
+
+// SRedirectUrl sends the scans of a QR code to TargetUrl, or to the target its
+// Schedule picks. Out of ActiveFrom and ActiveUntil the scans go to FallbackUrl,
+// nowhere when it is empty.
 type SRedirectUrl struct {
-	ID           uint64    `gorm:"primary_key;auto_increment;not null"`
-	QRCode       string    `gorm:"type:varchar(255);not null;unique"`
-	TargetUrl    string    `gorm:"type:varchar(255);not null"`
-	Password     *string   `gorm:"type:varchar(32);"`
-	Hint         string    `gorm:"type:varchar(255);default:''"`
-	HashPassword *string   `gorm:"type:varchar(255);default:null"`
-	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
-	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	ID             uint64         `gorm:"primary_key;auto_increment;not null"`
+	OrganizationId *int64         `gorm:"default:null;index"`
+	QRCode         string         `gorm:"type:varchar(255);not null;unique"`
+	TargetUrl      string         `gorm:"type:varchar(255);not null"`
+	Password       *string        `gorm:"type:varchar(32);"`
+	Hint           string         `gorm:"type:varchar(255);default:''"`
+	HashPassword   *string        `gorm:"type:varchar(255);default:null"`
+	ActiveFrom     *time.Time     `gorm:"default:null"`
+	ActiveUntil    *time.Time     `gorm:"default:null"`
+	FallbackUrl    string         `gorm:"type:varchar(255);not null;default:''"`
+	Schedule       datatypes.JSON `gorm:"type:json"`
+	CreatedAt      time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// IsActive tells whether the redirect URL sends its scans to its targets at the
+// time
+func (redirectUrl SRedirectUrl) IsActive(at time.Time) bool {
+	return (redirectUrl.ActiveFrom == nil || !at.Before(*redirectUrl.ActiveFrom)) &&
+		(redirectUrl.ActiveUntil == nil || at.Before(*redirectUrl.ActiveUntil))
+}
+
+// GetSchedule reads the schedule, empty for the redirect URLs with none
+func (redirectUrl SRedirectUrl) GetSchedule() value.RedirectUrlSchedule {
+	var schedule value.RedirectUrlSchedule
+	if len(redirectUrl.Schedule) > 0 {
+		_ = json.Unmarshal(redirectUrl.Schedule, &schedule)
+	}
+
+	return schedule
+}
+
+// SetSchedule saves the schedule, none when it is empty
+func (redirectUrl *SRedirectUrl) SetSchedule(schedule value.RedirectUrlSchedule) error {
+	if len(schedule.TimeRules) == 0 && len(schedule.Targets) == 0 {
+		redirectUrl.Schedule = nil
+		return nil
+	}
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	redirectUrl.Schedule = datatypes.JSON(data)
+
+	return nil
 }
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+// SRedirectUrlScan is one resolution of a redirect URL QR code. The device and
+// the user are set when the request was authenticated, the redirect URL is nil
+// for the codes that resolve to nothing. TargetUrl is where the scan was sent.
+type SRedirectUrlScan struct {
+	ID             uint64                       `gorm:"primary_key;auto_increment"`
+	RedirectUrlId  *uint64                      `gorm:"default:null;index"`
+	OrganizationId *int64                       `gorm:"default:null;index"`
+	QRCode         string                       `gorm:"type:varchar(255);not null;index:idx_redirect_url_scan_code_created,priority:1"`
+	Outcome        value.RedirectUrlScanOutcome `gorm:"type:varchar(32);not null"`
+	TargetUrl      string                       `gorm:"type:varchar(255);not null;default:''"`
+	DeviceId       string                       `gorm:"type:varchar(36);not null;default:''"`
+	UserId         string                       `gorm:"type:varchar(36);not null;default:''"`
+	UserAgent      string                       `gorm:"type:varchar(512);not null;default:''"`
+	IpAddress      string                       `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt      time.Time                    `gorm:"default:CURRENT_TIMESTAMP;not null;index:idx_redirect_url_scan_code_created,priority:2;index"`
+}
@@ -6,9 +6,9 @@ import (
 
 type SRole struct {
 	ID             int64         `gorm:"column:id;primary_key;AUTO_INCREMENT"`
-	RoleName       string        `gorm:"type:varchar(255);not null;default:''"`
+	RoleName       string        `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_role_organization_name,priority:2"`
 	Description    string        `gorm:"type:varchar(255);not null;default:''"`
-	OrganizationId int64         `gorm:"column:organization_id;"`
+	OrganizationId int64         `gorm:"column:organization_id;uniqueIndex:idx_role_organization_name,priority:1"`
 	Organization   SOrganization `gorm:"foreignKey:OrganizationId;references:id;constraint:OnDelete:CASCADE;default:NULL"`
 	CreatedAt      time.Time     `gorm:"default:CURRENT_TIMESTAMP;not null"`
 	UpdatedAt      time.Time     `gorm:"default:CURRENT_TIMESTAMP;not null"`
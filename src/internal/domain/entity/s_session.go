@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// SSession is a login of a user, on a device when DeviceId is set. The access
+// tokens of the session carry its ID and stop being accepted once the session is
+// revoked. Only the hash of the current refresh token is kept, every refresh
+// replaces it.
+type SSession struct {
+	ID               string     `gorm:"type:char(36);primary_key"`
+	UserId           string     `gorm:"type:char(36);not null;index"`
+	DeviceId         string     `gorm:"type:varchar(255);not null;default:'';index"`
+	RefreshTokenHash string     `gorm:"type:char(64);not null"`
+	UserAgent        string     `gorm:"type:varchar(512);not null;default:''"`
+	IpAddress        string     `gorm:"type:varchar(64);not null;default:''"`
+	ExpiresAt        time.Time  `gorm:"not null"`
+	LastUsedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	RevokedAt        *time.Time `gorm:"default:null;index"`
+	RevokedReason    string     `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+func (receiver SSession) IsActive(now time.Time) bool {
+	return receiver.RevokedAt == nil && now.Before(receiver.ExpiresAt)
+}
@@ -9,13 +9,14 @@ import (
 )
 
 type SSetting struct {
-	ID           int               `gorm:"column:id;primaryKey;autoIncrement"`
-	SettingName  string            `gorm:"type:text;not null;default:''"`
-	Settings     datatypes.JSON    `gorm:"column:settings;type:json;not null;default:'{}'"`
-	Type         value.SettingType `gorm:"type:int;not null;default:0;unique"`
-	IntegerValue uint64            `gorm:"column:integer_value;type:int;not null;default:0"`
-	CreatedAt    time.Time         `gorm:"column:created_at;default:CURRENT_TIMESTAMP;not null"`
-	UpdatedAt    time.Time         `gorm:"column:updated_at;default:CURRENT_TIMESTAMP;not null"`
+	ID             int               `gorm:"column:id;primaryKey;autoIncrement"`
+	OrganizationId *int64            `gorm:"default:null;uniqueIndex:idx_setting_organization_type,priority:1"`
+	SettingName    string            `gorm:"type:text;not null;default:''"`
+	Settings       datatypes.JSON    `gorm:"column:settings;type:json;not null;default:'{}'"`
+	Type           value.SettingType `gorm:"type:int;not null;default:0;uniqueIndex:idx_setting_organization_type,priority:2"`
+	IntegerValue   uint64            `gorm:"column:integer_value;type:int;not null;default:0"`
+	CreatedAt      time.Time         `gorm:"column:created_at;default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time         `gorm:"column:updated_at;default:CURRENT_TIMESTAMP;not null"`
 }
 
 func (s *SSetting) BeforeSave(tx *gorm.DB) (err error) {
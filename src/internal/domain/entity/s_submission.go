@@ -28,6 +28,7 @@ type SSubmission struct {
 	ID             uint64         `gorm:"primary_key;auto_increment;"`
 	FormId         uint64         `gorm:"column:form_id;"`
 	Form           SForm          `gorm:"foreignKey:FormId;references:id;constraint:OnDelete:CASCADE"`
+	FormRevisionId *uint64        `gorm:"column:form_revision_id;index;default:null"`
 	UserId         string         `gorm:"column:user_id;"`
 	User           SUserEntity    `gorm:"foreignKey:UserId;references:id;constraint:OnDelete:CASCADE"`
 	SubmissionData datatypes.JSON `gorm:"type:json;not null;default:'{}'"`
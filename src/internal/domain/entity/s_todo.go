@@ -6,21 +6,66 @@ import (
 	"time"
 )
 
+// Task is a task of a to-do list. DueDate is the due date as the sheet shows it,
+// DueAt the same time once it is known in the timezone of the organization. A
+// recurring task gets its next occurrence, NextIndex, when it is completed. The
+// reminder is sent RemindBefore minutes before it is due. A task with Assignees
+// is assigned to them instead of the assignees of the list.
 type Task struct {
-	Index     int    `json:"index"`
-	Name      string `json:"name"`
-	DueDate   string `json:"due_date"`
-	Value     string `json:"value"`
-	Selection string `json:"selection"`
-	Selected  string `json:"selected"`
+	Index        int                   `json:"index"`
+	Name         string                `json:"name"`
+	DueDate      string                `json:"due_date"`
+	Value        string                `json:"value"`
+	Selection    string                `json:"selection"`
+	Selected     string                `json:"selected"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"`
+	CompletedBy  string                `json:"completed_by,omitempty"`
+	DueAt        *time.Time            `json:"due_at,omitempty"`
+	Recurrence   *value.ToDoRecurrence `json:"recurrence,omitempty"`
+	Occurrence   int                   `json:"occurrence,omitempty"`
+	NextIndex    *int                  `json:"next_index,omitempty"`
+	RemindBefore int                   `json:"remind_before,omitempty"`
+	RemindedAt   *time.Time            `json:"reminded_at,omitempty"`
+	Assignees    []TaskAssignee        `json:"assignees,omitempty"`
+}
+
+type TaskAssignee struct {
+	Type value.ToDoAssigneeType `json:"type"`
+	Id   string                 `json:"id"`
+}
+
+func (task Task) IsDone() bool {
+	return task.Selected != "" || task.CompletedAt != nil
+}
+
+// IsOverdue tells whether the task is not done and was due before now
+func (task Task) IsOverdue(now time.Time) bool {
+	return !task.IsDone() && task.DueAt != nil && task.DueAt.Before(now)
+}
+
+// ReminderAt returns when the reminder of the task is due, nil when it has none
+// or it was sent
+func (task Task) ReminderAt() *time.Time {
+	if task.DueAt == nil || task.RemindBefore <= 0 || task.RemindedAt != nil || task.IsDone() {
+		return nil
+	}
+	at := task.DueAt.Add(-time.Duration(task.RemindBefore) * time.Minute)
+
+	return &at
 }
 
 type STasks struct {
 	Tasks []Task `json:"tasks"`
 }
 
+// SToDo is a to-do list, the database holds its tasks and their completions.
+// The task sheet and the history sheet are a mirror kept up to date by the sheet
+// sync jobs, see SToDoSheetSync, unless SheetSyncDisabled is set. The import
+// stops replacing the tasks once they were edited through the API,
+// TasksEditedAt. NextReminderAt is the first reminder of its tasks still to send.
 type SToDo struct {
 	ID                   string                     `gorm:"primary_key;type:varchar(255);not null" json:"id"`
+	OrganizationId       *int64                     `gorm:"default:null;index" json:"organization_id"`
 	Name                 string                     `gorm:"type:varchar(255);" json:"name"`
 	Type                 value.ToDoType             `gorm:"type:varchar(32);default:'assign'" json:"type"`
 	SpreadsheetID        string                     `gorm:"type:varchar(255);not null" json:"spreadsheet_id"`
@@ -29,6 +74,61 @@ type SToDo struct {
 	HistorySpreadsheetID string                     `gorm:"type:varchar(255);not null" json:"history_spreadsheet_id"`
 	HistorySheetName     string                     `gorm:"type:varchar(255);not null;default:Answers" json:"history_sheet_name"`
 	StartRow             int                        `gorm:"type:int;not null;default:13" json:"start_row"`
+	SheetSyncDisabled    bool                       `gorm:"not null;default:false" json:"sheet_sync_disabled"`
+	TasksEditedAt        *time.Time                 `gorm:"default:null" json:"tasks_edited_at"`
+	NextReminderAt       *time.Time                 `gorm:"default:null;index" json:"next_reminder_at"`
 	CreatedAt            time.Time                  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt            time.Time                  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
+
+// SToDoCompletion records a task of a to-do list marked as done from a device,
+// for the assignee the device completed it for
+type SToDoCompletion struct {
+	ID           uint64                 `gorm:"primary_key;auto_increment" json:"id"`
+	ToDoId       string                 `gorm:"column:todo_id;type:varchar(255);not null;index" json:"todo_id"`
+	TaskIndex    int                    `gorm:"not null" json:"task_index"`
+	TaskName     string                 `gorm:"type:varchar(255);not null;default:''" json:"task_name"`
+	Selected     string                 `gorm:"type:varchar(255);not null;default:''" json:"selected"`
+	DeviceId     string                 `gorm:"type:varchar(36);not null;index" json:"device_id"`
+	AssigneeType value.ToDoAssigneeType `gorm:"type:varchar(16);not null;default:''" json:"assignee_type"`
+	AssigneeId   string                 `gorm:"type:varchar(64);not null;default:''" json:"assignee_id"`
+	CompletedAt  time.Time              `gorm:"not null;index" json:"completed_at"`
+}
+
+// SToDoTaskProgress is where an assignee of a to-do list is with a task, each
+// user and each device completes the tasks of an assigned list on their own. A
+// device assigned through its group is an assignee of its own.
+type SToDoTaskProgress struct {
+	ToDoId       string                 `gorm:"column:todo_id;type:varchar(255);primary_key"`
+	TaskIndex    int                    `gorm:"primary_key;autoIncrement:false"`
+	AssigneeType value.ToDoAssigneeType `gorm:"type:varchar(16);primary_key"`
+	AssigneeId   string                 `gorm:"type:varchar(64);primary_key"`
+	Selected     string                 `gorm:"type:varchar(255);not null;default:''"`
+	DeviceId     string                 `gorm:"type:varchar(36);not null;default:''"`
+	CompletedAt  time.Time              `gorm:"not null"`
+}
+
+// SToDoSheetSync is a change of a to-do list to mirror to its spreadsheets, it
+// is retried with a backoff until it goes through
+type SToDoSheetSync struct {
+	ID            uint64                    `gorm:"primary_key;auto_increment"`
+	ToDoId        string                    `gorm:"column:todo_id;type:varchar(255);not null;index"`
+	Kind          value.ToDoSheetSyncKind   `gorm:"type:varchar(32);not null"`
+	Payload       datatypes.JSON            `gorm:"type:json"`
+	Status        value.ToDoSheetSyncStatus `gorm:"type:varchar(16);not null;default:'pending';index:idx_todo_sheet_sync_due"`
+	Attempts      int                       `gorm:"type:int;not null;default:0"`
+	NextAttemptAt *time.Time                `gorm:"default:null;index:idx_todo_sheet_sync_due"`
+	LastError     string                    `gorm:"type:text"`
+	SyncedAt      *time.Time                `gorm:"default:null"`
+	CreatedAt     time.Time                 `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt     time.Time                 `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// SToDoAssignee assigns a to-do list to a user, a device or a device group, the
+// tasks with no assignees of their own are assigned to them
+type SToDoAssignee struct {
+	ToDoId       string                 `gorm:"column:todo_id;type:varchar(255);primary_key"`
+	AssigneeType value.ToDoAssigneeType `gorm:"type:varchar(16);primary_key"`
+	AssigneeId   string                 `gorm:"type:varchar(64);primary_key"`
+	CreatedAt    time.Time              `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
@@ -1,12 +1,38 @@
 package entity
 
 import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// SUserGuardians holds the active guardians of a user, see SGuardianLink
 type SUserGuardians struct {
 	UserId     uuid.UUID   `gorm:"column:user_id;primary_key"`
 	User       SUserEntity `gorm:"foreignKey:UserId;references:id;constraint:OnDelete:CASCADE;"`
 	GuardianId uuid.UUID   `gorm:"column:guardian_id;primary_key"`
 	Guardian   SUserEntity `gorm:"foreignKey:GuardianId;references:id;constraint:OnDelete:CASCADE;"`
 }
+
+// SGuardianLink is a guardian relationship between a child, UserId, and a
+// guardian from the invitation on. It is active once the guardian confirms it and
+// is kept as history once it is declined, cancelled or detached; a new invitation
+// starts a new link.
+type SGuardianLink struct {
+	ID           uint64                     `gorm:"primary_key;auto_increment"`
+	UserId       string                     `gorm:"type:varchar(36);not null;index"`
+	GuardianId   string                     `gorm:"type:varchar(36);not null;index"`
+	Relationship value.GuardianRelationship `gorm:"type:varchar(32);not null;default:''"`
+	Status       value.GuardianLinkStatus   `gorm:"type:varchar(16);not null"`
+	InvitedBy    string                     `gorm:"type:varchar(36);not null;default:''"`
+	ConfirmedAt  *time.Time                 `gorm:"default:null"`
+	EndedAt      *time.Time                 `gorm:"default:null"`
+	EndedBy      string                     `gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt    time.Time                  `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt    time.Time                  `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+func (receiver SGuardianLink) IsOpen() bool {
+	return receiver.Status == value.GuardianLinkStatus_Pending || receiver.Status == value.GuardianLinkStatus_Active
+}
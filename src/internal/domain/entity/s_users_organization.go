@@ -1,12 +1,22 @@
 package entity
 
 import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// SUsersOrganization makes a user a member of an organization. A suspended member
+// stays in the organization but the roles of the organization stop counting for
+// them. InvitationId is the invitation the user joined with, if any.
 type SUsersOrganization struct {
-	UserId         uuid.UUID     `gorm:"column:user_id;primary_key"`
-	User           SUserEntity   `gorm:"foreignKey:UserId;references:id;constraint:OnDelete:CASCADE;"`
-	OrganizationID int64         `gorm:"column:organization_id;primary_key"`
-	Organization   SOrganization `gorm:"foreignKey:OrganizationID;references:id;constraint:OnDelete:CASCADE"`
+	UserId         uuid.UUID              `gorm:"column:user_id;primary_key"`
+	User           SUserEntity            `gorm:"foreignKey:UserId;references:id;constraint:OnDelete:CASCADE;"`
+	OrganizationID int64                  `gorm:"column:organization_id;primary_key"`
+	Organization   SOrganization          `gorm:"foreignKey:OrganizationID;references:id;constraint:OnDelete:CASCADE"`
+	Status         value.MembershipStatus `gorm:"type:varchar(16);not null;default:'active'"`
+	InvitationId   *uint64                `gorm:"default:null"`
+	SuspendedAt    *time.Time             `gorm:"default:null"`
+	CreatedAt      time.Time              `gorm:"default:CURRENT_TIMESTAMP;not null"`
 }
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SWebhook is an endpoint that receives the events it subscribes to. Every
+// delivery is signed with the secret of the webhook.
+type SWebhook struct {
+	ID        uint64         `gorm:"primary_key;auto_increment"`
+	Name      string         `gorm:"type:varchar(255);not null;default:''"`
+	Url       string         `gorm:"type:varchar(2048);not null"`
+	Secret    string         `gorm:"type:varchar(255);not null"`
+	Events    datatypes.JSON `gorm:"type:json;not null"`
+	Status    value.Status   `gorm:"type:tinyint;not null;default:1"`
+	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
+
+// SWebhookDelivery records one event sent to one webhook, including its retries
+type SWebhookDelivery struct {
+	ID             uint64                      `gorm:"primary_key;auto_increment"`
+	WebhookId      uint64                      `gorm:"not null;index"`
+	Webhook        SWebhook                    `gorm:"foreignKey:WebhookId;references:id;constraint:OnDelete:CASCADE"`
+	EventId        string                      `gorm:"type:varchar(36);not null;index"`
+	Event          value.WebhookEvent          `gorm:"type:varchar(64);not null"`
+	Payload        datatypes.JSON              `gorm:"type:json;not null"`
+	Status         value.WebhookDeliveryStatus `gorm:"type:varchar(16);not null;default:'pending';index:idx_webhook_delivery_due"`
+	Attempts       int                         `gorm:"type:int;not null;default:0"`
+	NextAttemptAt  *time.Time                  `gorm:"default:null;index:idx_webhook_delivery_due"`
+	ResponseStatus int                         `gorm:"type:int;not null;default:0"`
+	ResponseBody   string                      `gorm:"type:text"`
+	LastError      string                      `gorm:"type:text"`
+	DeliveredAt    *time.Time                  `gorm:"default:null"`
+	ReplayOf       *uint64                     `gorm:"default:null"`
+	CreatedAt      time.Time                   `gorm:"default:CURRENT_TIMESTAMP;not null"`
+	UpdatedAt      time.Time                   `gorm:"default:CURRENT_TIMESTAMP;not null"`
+}
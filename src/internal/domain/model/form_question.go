@@ -17,6 +17,7 @@ type FormQuestionItem struct {
 	AnswerRequired bool                    `gorm:"type:tinyint(1);not null;default:0"`
 	EnableOnMobile value.QuestionForMobile `gorm:"type:varchar(16);not null;default:'enabled'"`
 	QuestionUniqueId *string        `gorm:"type:varchar(255);default:null"`
+	Rules          datatypes.JSON          `gorm:"type:json;default:null"`
 	CreatedAt      time.Time               `gorm:"default:CURRENT_TIMESTAMP;not null"`
 	UpdatedAt      time.Time               `gorm:"default:CURRENT_TIMESTAMP;not null"`
 }
@@ -13,6 +13,7 @@ type RawQuestion struct {
 	RowNumber         int                     `json:"row_number"`
 	EnableOnMobile    value.QuestionForMobile `json:"enable_on_mobile"`
 	QuestionUniqueId  *string                 `json:"question_unique_id"`
+	Rules             string                  `json:"rules"`
 }
 
 type SaveFormParams struct {
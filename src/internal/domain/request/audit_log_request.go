@@ -0,0 +1,11 @@
+package request
+
+type GetAuditLogsRequest struct {
+	OrganizationId int64  `form:"organization_id"`
+	ActorId        string `form:"actor_id"`
+	Action         string `form:"action"`
+	TargetType     string `form:"target_type"`
+	TargetId       string `form:"target_id"`
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit"`
+}
@@ -0,0 +1,25 @@
+package request
+
+// BulkDeviceFilter picks the devices of a bulk operation, either by DeviceIds or
+// by the other criteria, which all have to match. NamePattern matches the device
+// name with * as wildcard.
+type BulkDeviceFilter struct {
+	DeviceIds      []string `json:"device_ids"`
+	OrganizationId *int64   `json:"organization_id"`
+	Mode           string   `json:"mode"`
+	AppVersion     string   `json:"app_version"`
+	NamePattern    string   `json:"name_pattern"`
+	GroupId        *uint64  `json:"group_id"`
+	Tag            string   `json:"tag"`
+}
+
+// BulkUpdateDevicesRequest applies the given fields to every device of the
+// filter, the fields left out are kept
+type BulkUpdateDevicesRequest struct {
+	Filter                  BulkDeviceFilter `json:"filter" binding:"required"`
+	Status                  *string          `json:"status"`
+	DeactivateMessage       *string          `json:"deactivate_message"`
+	InputMode               *string          `json:"input_mode"`
+	ScreenButtonType        *string          `json:"screen_button_type"`
+	DeviceComponentValuesId *int64           `json:"device_component_values_id"`
+}
@@ -1,8 +1,10 @@
 package request
 
+// CreateOrganizationRequest creates an organization, password is the shared
+// password of the legacy join and can be left out, users join with invitations
 type CreateOrganizationRequest struct {
 	OrganizationName string `json:"organization_name" binding:"required"`
-	Password         string `json:"password" binding:"required"`
+	Password         string `json:"password"`
 	Address          string `json:"address" default:""`
 	Description      string `json:"description" default:""`
 }
@@ -0,0 +1,26 @@
+package request
+
+import "encoding/json"
+
+// IssueDeviceCommandRequest queues a command for a device. change_mode takes the
+// mode as {"mode": "mode t"} and show_message the text as {"message": "..."}, the
+// other commands take no payload. A command expires after ExpiresInMinutes, a day
+// when it is not given.
+type IssueDeviceCommandRequest struct {
+	Type             string          `json:"type" binding:"required"`
+	Payload          json.RawMessage `json:"payload"`
+	ExpiresInMinutes int             `json:"expires_in_minutes" binding:"min=0"`
+}
+
+type GetDeviceCommandsRequest struct {
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// DeviceCommandResultRequest reports whether a device executed a command, Result
+// holds the output or the error of the command
+type DeviceCommandResultRequest struct {
+	Status string `json:"status" binding:"required,oneof=succeeded failed"`
+	Result string `json:"result"`
+}
@@ -0,0 +1,32 @@
+package request
+
+import "time"
+
+// CreateDeviceEnrollmentCodeRequest issues an enrollment code for the devices of
+// an organization. The enrolled devices sign in as user_id, the admin issuing the
+// code when it is left out. A code can be used max_uses times, once by default,
+// until expires_at, 72 hours from now by default.
+type CreateDeviceEnrollmentCodeRequest struct {
+	Name           string     `json:"name"`
+	OrganizationId int64      `json:"organization_id" binding:"required"`
+	GroupId        *uint64    `json:"group_id"`
+	Mode           *string    `json:"mode"`
+	UserId         string     `json:"user_id"`
+	MaxUses        uint       `json:"max_uses"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type GetDeviceEnrollmentCodesRequest struct {
+	OrganizationId *int64 `form:"organization_id"`
+	// Active leaves out the codes that are revoked, expired or used up
+	Active bool `form:"active"`
+}
+
+// EnrollDeviceRequest enrolls a device with an enrollment code, code is the code
+// or the payload of its QR code
+type EnrollDeviceRequest struct {
+	Code       string `json:"code" binding:"required"`
+	DeviceUUID string `json:"device_uuid" binding:"required"`
+	InputMode  string `json:"input_mode"`
+	AppVersion string `json:"app_version"`
+}
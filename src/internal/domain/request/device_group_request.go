@@ -0,0 +1,29 @@
+package request
+
+type SaveDeviceGroupRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Description    string `json:"description"`
+	OrganizationId *int64 `json:"organization_id"`
+}
+
+type GetDeviceGroupsRequest struct {
+	OrganizationId *int64 `form:"organization_id"`
+}
+
+type DeviceGroupDevicesRequest struct {
+	DeviceIds []string `json:"device_ids" binding:"required"`
+}
+
+// ReplaceDeviceTagsRequest sets the tags of a device, an empty list removes them all
+type ReplaceDeviceTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// SaveDeviceConfigurationRequest replaces the configuration of a scope. The
+// fields left null are inherited from the scope below, an empty form_ids shows
+// no form at all.
+type SaveDeviceConfigurationRequest struct {
+	Mode                    *string  `json:"mode"`
+	FormIds                 []uint64 `json:"form_ids"`
+	DeviceComponentValuesId *int64   `json:"device_component_values_id"`
+}
@@ -0,0 +1,19 @@
+package request
+
+import "time"
+
+type DeviceHeartbeatRequest struct {
+	DeviceId         string  `json:"device_id" binding:"required"`
+	BatteryLevel     *int    `json:"battery_level" binding:"omitempty,min=0,max=100"`
+	IsCharging       *bool   `json:"is_charging"`
+	AppVersion       string  `json:"app_version"`
+	IpAddress        string  `json:"ip_address"`
+	CurrentFormId    *uint64 `json:"current_form_id"`
+	FreeStorageBytes *int64  `json:"free_storage_bytes" binding:"omitempty,min=0"`
+}
+
+type GetDeviceHeartbeatsRequest struct {
+	From  time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To    time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit int       `form:"limit"`
+}
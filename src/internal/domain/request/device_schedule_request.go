@@ -0,0 +1,46 @@
+package request
+
+import "time"
+
+// SaveDeviceModeScheduleRequest creates or replaces a device mode schedule of a
+// device or a device group. start_time and end_time are HH:MM in the timezone of
+// the organization, a window ending before it starts runs overnight. They
+// default to outside of the working hours.
+type SaveDeviceModeScheduleRequest struct {
+	Scope     string   `json:"scope" binding:"required"`
+	ScopeId   string   `json:"scope_id" binding:"required"`
+	Name      string   `json:"name"`
+	Mode      string   `json:"mode" binding:"required"`
+	Days      []string `json:"days" binding:"required"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	Enabled   *bool    `json:"enabled"`
+}
+
+type GetDeviceModeSchedulesRequest struct {
+	Scope   string `form:"scope"`
+	ScopeId string `form:"scope_id"`
+}
+
+// CreateDeviceModeOverrideRequest makes a one-off exception to the schedules of
+// a device or a device group. It starts now when starts_at is left out, a null
+// mode leaves the devices in their configured mode.
+type CreateDeviceModeOverrideRequest struct {
+	Scope    string     `json:"scope" binding:"required"`
+	ScopeId  string     `json:"scope_id" binding:"required"`
+	Mode     *string    `json:"mode"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   time.Time  `json:"ends_at" binding:"required"`
+	Reason   string     `json:"reason"`
+}
+
+type GetDeviceModeOverridesRequest struct {
+	Scope   string `form:"scope"`
+	ScopeId string `form:"scope_id"`
+	// Upcoming leaves out the overrides that are over
+	Upcoming bool `form:"upcoming"`
+}
+
+type UpdateOrganizationTimezoneRequest struct {
+	Timezone string `json:"timezone"`
+}
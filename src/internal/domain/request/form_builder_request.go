@@ -0,0 +1,37 @@
+package request
+
+import (
+	"encoding/json"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+)
+
+type CreateNativeFormRequest struct {
+	Note           string `json:"note" binding:"required"`
+	Name           string `json:"name"`
+	Password       string `json:"password"`
+	OrganizationId *int64 `json:"organization_id"`
+}
+
+// SaveFormQuestionRequest describes a question built without a spreadsheet. Attributes
+// are given in their stored JSON form, eg {"options": [{"name": "red"}]} for a
+// single choice question or {"number": 10, "steps": 1} for a scale question.
+type SaveFormQuestionRequest struct {
+	QuestionType     string                  `json:"question_type" binding:"required"`
+	Question         string                  `json:"question" binding:"required"`
+	Attributes       json.RawMessage         `json:"attributes"`
+	AnswerRequired   bool                    `json:"answer_required"`
+	Status           string                  `json:"status"`
+	EnableOnMobile   value.QuestionForMobile `json:"enable_on_mobile"`
+	QuestionUniqueId *string                 `json:"question_unique_id"`
+	// Position is the 1-based place of the question in the form, the question is
+	// appended when it is not given
+	Position *int `json:"position"`
+	// Rules replace the show_if/skip_to rules of the question, they are kept as they
+	// are when not given
+	Rules *[]entity.QuestionRule `json:"rules"`
+}
+
+type ReorderFormQuestionsRequest struct {
+	QuestionIds []string `json:"question_ids" binding:"required"`
+}
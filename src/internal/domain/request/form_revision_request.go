@@ -0,0 +1,10 @@
+package request
+
+type CreateFormRevisionRequest struct {
+	Note string `json:"note"`
+}
+
+type DiffFormRevisionsRequest struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
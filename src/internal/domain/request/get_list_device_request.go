@@ -1,7 +1,8 @@
 package request
 
 type GetListDeviceRequest struct {
-	Page    int    `form:"page"`
-	Keyword string `form:"keyword"`
-	Limit   int    `form:"limit"`
+	Page     int    `form:"page"`
+	Keyword  string `form:"keyword"`
+	Limit    int    `form:"limit"`
+	Presence string `form:"presence"`
 }
@@ -1,7 +1,8 @@
 package request
 
 type GetRedirectUrlListRequest struct {
-	Keyword string `form:"keyword"`
-	Page    int    `form:"page"`
-	Limit   int    `form:"limit"`
+	Keyword        string `form:"keyword"`
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit"`
+	OrganizationId *int64 `form:"organization_id"`
 }
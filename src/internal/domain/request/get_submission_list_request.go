@@ -0,0 +1,27 @@
+package request
+
+import "time"
+
+// GetSubmissionListRequest filters submissions, dates are inclusive and given as
+// YYYY-MM-DD. GuardianId is set from the token on the guardian routes.
+type GetSubmissionListRequest struct {
+	FormId         uint64    `form:"form_id"`
+	UserId         string    `form:"user_id"`
+	GuardianId     string    `form:"-"`
+	DeviceId       string    `form:"device_id"`
+	OrganizationId int64     `form:"organization_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02"`
+	To             time.Time `form:"to" time_format:"2006-01-02"`
+	Page           int       `form:"page"`
+	Limit          int       `form:"limit"`
+}
+
+type ExportSubmissionsRequest struct {
+	UserId         string    `form:"user_id"`
+	DeviceId       string    `form:"device_id"`
+	OrganizationId int64     `form:"organization_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02"`
+	To             time.Time `form:"to" time_format:"2006-01-02"`
+	Format         string    `form:"format"`
+	Template       string    `form:"template"`
+}
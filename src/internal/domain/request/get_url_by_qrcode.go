@@ -1,5 +1,24 @@
 package request
 
+import "time"
+
+// GetRedirectUrlByQRCodeRequest resolves a QR code, Password is required for the
+// redirect URLs with a password
 type GetRedirectUrlByQRCodeRequest struct {
-	QRCode string `form:"qr_code"`
+	QRCode   string `form:"qr_code"`
+	Password string `form:"password"`
+}
+
+type GetRedirectUrlAnalyticsRequest struct {
+	QRCode         string    `form:"qr_code"`
+	OrganizationId *int64    `form:"organization_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02"`
+	To             time.Time `form:"to" time_format:"2006-01-02"`
+	Interval       string    `form:"interval"`
+	Limit          int       `form:"limit" binding:"min=0"`
+}
+
+type ExportRedirectUrlAnalyticsRequest struct {
+	GetRedirectUrlAnalyticsRequest
+	Report string `form:"report"`
 }
@@ -0,0 +1,27 @@
+package request
+
+import "time"
+
+// InviteGuardianRequest invites a user to be the guardian of a child, the
+// guardian gets read access once they confirm
+type InviteGuardianRequest struct {
+	GuardianId   string `json:"guardian_id" binding:"required"`
+	Relationship string `json:"relationship" binding:"required"`
+}
+
+type GetGuardianLinksRequest struct {
+	Status string `form:"status"`
+}
+
+// GetToDoCompletionsRequest filters the completed tasks of the to-do lists, dates
+// are inclusive and given as YYYY-MM-DD. GuardianId is set from the token on the
+// guardian routes.
+type GetToDoCompletionsRequest struct {
+	ToDoId     string    `form:"todo_id"`
+	UserId     string    `form:"user_id"`
+	GuardianId string    `form:"-"`
+	From       time.Time `form:"from" time_format:"2006-01-02"`
+	To         time.Time `form:"to" time_format:"2006-01-02"`
+	Page       int       `form:"page"`
+	Limit      int       `form:"limit"`
+}
@@ -4,4 +4,5 @@ type ImportFormRequest struct {
 	SpreadsheetUrl string `json:"spreadsheet_url" binding:"required"`
 	AutoImport     bool   `json:"auto"`
 	Interval       uint64 `json:"interval_in_minutes"`
+	OrganizationId *int64 `json:"organization_id"`
 }
@@ -4,9 +4,13 @@ type UserLoginFromDeviceReqest struct {
 	Username   string `json:"username" binding:"required"`
 	Password   string `json:"password" binding:"required"`
 	DeviceUUID string `json:"device_uuid" binding:"required"`
+	UserAgent  string `json:"-"`
+	IpAddress  string `json:"-"`
 }
 
 type UserLoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-"`
 }
@@ -0,0 +1,34 @@
+package request
+
+import "time"
+
+// CreateOrganizationInvitationRequest invites users to an organization with the
+// roles of role_ids, roles of the organization. An invitation with an email is
+// for the user with that email only. An invitation can be used max_uses times,
+// once by default, until expires_at, 7 days from now by default.
+type CreateOrganizationInvitationRequest struct {
+	Email     string     `json:"email"`
+	RoleIds   []int64    `json:"role_ids"`
+	MaxUses   uint       `json:"max_uses"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type GetOrganizationInvitationsRequest struct {
+	// Active leaves out the invitations that are revoked, declined, expired or
+	// used up
+	Active bool `form:"active"`
+}
+
+// OrganizationInvitationCodeRequest answers an invitation, code is the code or
+// the invitation link
+type OrganizationInvitationCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type GetOrganizationMembersRequest struct {
+	Status string `form:"status"`
+}
+
+type TransferOrganizationOwnershipRequest struct {
+	UserId string `json:"user_id" binding:"required"`
+}
@@ -4,4 +4,5 @@ type SaveFormRequest struct {
 	Note           string `json:"note" binding:"required"`
 	SpreadsheetUrl string `json:"spreadsheet_url" binding:"required"`
 	Password       string `json:"password"`
+	OrganizationId *int64 `json:"organization_id"`
 }
@@ -1,7 +1,25 @@
 package request
 
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
 type SaveRedirectUrlRequest struct {
-	QRCode    string `json:"qr_code" binding:"required"`
-	TargetUrl string `json:"target_url" binding:"required"`
-	Password  string `json:"password"`
+	QRCode         string `json:"qr_code" binding:"required"`
+	TargetUrl      string `json:"target_url" binding:"required"`
+	Password       string `json:"password"`
+	OrganizationId *int64 `json:"organization_id"`
+	RedirectUrlScheduleRequest
+}
+
+// RedirectUrlScheduleRequest is when and where a redirect URL sends its scans.
+// Out of active_from and active_until the scans go to fallback_url, the time
+// rules and the weighted targets replace target_url while it is active.
+type RedirectUrlScheduleRequest struct {
+	ActiveFrom  *time.Time                  `json:"active_from"`
+	ActiveUntil *time.Time                  `json:"active_until"`
+	FallbackUrl string                      `json:"fallback_url"`
+	TimeRules   []value.RedirectUrlTimeRule `json:"time_rules"`
+	Targets     []value.RedirectUrlTarget   `json:"targets"`
 }
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+type GetToDoListRequest struct {
+	Keyword        string `form:"keyword"`
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit"`
+	OrganizationId *int64 `form:"organization_id"`
+}
+
+// CreateToDoRequest creates a to-do list kept in the database, Id is the QR
+// code of the list. The spreadsheets are optional, a list without them is not
+// mirrored.
+type CreateToDoRequest struct {
+	Id                   string         `json:"id" binding:"required"`
+	Name                 string         `json:"name" binding:"required"`
+	Type                 string         `json:"type"`
+	SpreadsheetID        string         `json:"spreadsheet_id"`
+	SheetName            string         `json:"sheet_name"`
+	HistorySpreadsheetID string         `json:"history_spreadsheet_id"`
+	HistorySheetName     string         `json:"history_sheet_name"`
+	Tasks                []SaveToDoTask `json:"tasks"`
+	OrganizationId       *int64         `json:"organization_id"`
+}
+
+type UpdateToDoRequest struct {
+	Name              *string `json:"name"`
+	SheetSyncDisabled *bool   `json:"sheet_sync_disabled"`
+}
+
+// SaveToDoTask is a task of a list. The due time is DueAt, or DueDate as
+// "2006-01-02 15:04:05" in the timezone of the organization. RemindBefore is how
+// many minutes before it is due the reminder is sent, none when 0. A task with
+// Assignees is assigned to them instead of the assignees of the list.
+type SaveToDoTask struct {
+	Name         string                `json:"name" binding:"required"`
+	DueDate      string                `json:"due_date"`
+	DueAt        *time.Time            `json:"due_at"`
+	Value        string                `json:"value"`
+	Selection    string                `json:"selection"`
+	Recurrence   *value.ToDoRecurrence `json:"recurrence"`
+	RemindBefore int                   `json:"remind_before" binding:"min=0"`
+	Assignees    []ToDoAssignee        `json:"assignees" binding:"dive"`
+}
+
+type ToDoAssignee struct {
+	Type string `json:"type" binding:"required"`
+	Id   string `json:"id" binding:"required"`
+}
+
+// ReplaceToDoAssigneesRequest assigns a list to users, devices and device
+// groups, the assignees not given are removed
+type ReplaceToDoAssigneesRequest struct {
+	Assignees []ToDoAssignee `json:"assignees" binding:"dive"`
+}
+
+type GetToDoSheetSyncsRequest struct {
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
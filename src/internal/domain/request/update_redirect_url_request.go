@@ -1,5 +1,9 @@
 package request
 
+// UpdateRedirectUrlRequest changes the fields given, Schedule replaces the
+// whole schedule of the redirect URL
 type UpdateRedirectUrlRequest struct {
-	Password *string `json:"password"`
+	Password  *string                     `json:"password"`
+	TargetUrl *string                     `json:"target_url"`
+	Schedule  *RedirectUrlScheduleRequest `json:"schedule"`
 }
@@ -0,0 +1,17 @@
+package request
+
+// SaveWebhookRequest creates or updates a webhook. A secret is generated when none
+// is given on creation, and kept when none is given on update.
+type SaveWebhookRequest struct {
+	Name   string   `json:"name"`
+	Url    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Secret string   `json:"secret"`
+	Status string   `json:"status"`
+}
+
+type GetWebhookDeliveriesRequest struct {
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
@@ -0,0 +1,23 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogResponseData struct {
+	Id             uint64          `json:"id"`
+	ActorId        string          `json:"actor_id"`
+	OrganizationId *int64          `json:"organization_id"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type"`
+	TargetId       string          `json:"target_id"`
+	Details        json.RawMessage `json:"details"`
+	IpAddress      string          `json:"ip_address"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	Data   []AuditLogResponseData `json:"data"`
+	Paging Pagination             `json:"paging"`
+}
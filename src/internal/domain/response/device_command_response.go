@@ -0,0 +1,39 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeviceCommandResponseData struct {
+	Id             string          `json:"id"`
+	DeviceId       string          `json:"device_id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	IssuedBy       string          `json:"issued_by"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	PushedAt       *time.Time      `json:"pushed_at"`
+	PushError      string          `json:"push_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at"`
+	CompletedAt    *time.Time      `json:"completed_at"`
+	Result         string          `json:"result"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type DeviceCommandResponse struct {
+	Data DeviceCommandResponseData `json:"data"`
+}
+
+type DeviceCommandListResponse struct {
+	Data   []DeviceCommandResponseData `json:"data"`
+	Paging Pagination                  `json:"paging"`
+}
+
+// DeviceCommandQueueResponse is the queue of a device, the commands it still has
+// to acknowledge oldest first
+type DeviceCommandQueueResponse struct {
+	Data []DeviceCommandResponseData `json:"data"`
+}
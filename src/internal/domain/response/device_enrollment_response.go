@@ -0,0 +1,57 @@
+package response
+
+import "time"
+
+type DeviceEnrollmentCodeResponseData struct {
+	Id             uint64     `json:"id"`
+	Name           string     `json:"name"`
+	CodeHint       string     `json:"code_hint"`
+	OrganizationId int64      `json:"organization_id"`
+	GroupId        *uint64    `json:"group_id"`
+	Mode           *string    `json:"mode"`
+	UserId         string     `json:"user_id"`
+	MaxUses        uint       `json:"max_uses"`
+	Uses           uint       `json:"uses"`
+	Active         bool       `json:"active"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Code and QrPayload are only returned when the code is issued
+	Code      string `json:"code,omitempty"`
+	QrPayload string `json:"qr_payload,omitempty"`
+}
+
+type DeviceEnrollmentCodeResponse struct {
+	Data DeviceEnrollmentCodeResponseData `json:"data"`
+}
+
+type DeviceEnrollmentCodeListResponse struct {
+	Data []DeviceEnrollmentCodeResponseData `json:"data"`
+}
+
+type DeviceEnrollmentResponseData struct {
+	Id         uint64    `json:"id"`
+	DeviceId   string    `json:"device_id"`
+	AppVersion string    `json:"app_version"`
+	IpAddress  string    `json:"ip_address"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+type DeviceEnrollmentListResponse struct {
+	Data []DeviceEnrollmentResponseData `json:"data"`
+}
+
+// EnrolledDeviceResponseData is what a device gets back from enrolling: the
+// session it signs in with and the configuration it runs with
+type EnrolledDeviceResponseData struct {
+	DeviceId       string                                   `json:"device_id"`
+	OrganizationId int64                                    `json:"organization_id"`
+	GroupId        *uint64                                  `json:"group_id"`
+	Session        LoginResponseData                        `json:"session"`
+	Configuration  EffectiveDeviceConfigurationResponseData `json:"configuration"`
+}
+
+type EnrolledDeviceResponse struct {
+	Data EnrolledDeviceResponseData `json:"data"`
+}
@@ -0,0 +1,94 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeviceGroupResponseData struct {
+	Id             uint64    `json:"id"`
+	OrganizationId *int64    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Devices        int64     `json:"devices"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type DeviceGroupResponse struct {
+	Data DeviceGroupResponseData `json:"data"`
+}
+
+type DeviceGroupListResponse struct {
+	Data []DeviceGroupResponseData `json:"data"`
+}
+
+type DeviceGroupDevicesResponseData struct {
+	GroupId   uint64   `json:"group_id"`
+	DeviceIds []string `json:"device_ids"`
+}
+
+type DeviceGroupDevicesResponse struct {
+	Data DeviceGroupDevicesResponseData `json:"data"`
+}
+
+type DeviceTagsResponseData struct {
+	DeviceId string   `json:"device_id"`
+	Tags     []string `json:"tags"`
+}
+
+type DeviceTagsResponse struct {
+	Data DeviceTagsResponseData `json:"data"`
+}
+
+type DeviceTagCount struct {
+	Tag     string `json:"tag"`
+	Devices int64  `json:"devices"`
+}
+
+type DeviceTagListResponse struct {
+	Data []DeviceTagCount `json:"data"`
+}
+
+// DeviceConfigurationResponseData is the configuration set at one scope, the
+// null fields are inherited
+type DeviceConfigurationResponseData struct {
+	Scope                   string     `json:"scope"`
+	ScopeId                 string     `json:"scope_id"`
+	Mode                    *string    `json:"mode"`
+	FormIds                 []uint64   `json:"form_ids"`
+	DeviceComponentValuesId *int64     `json:"device_component_values_id"`
+	UpdatedAt               *time.Time `json:"updated_at"`
+}
+
+type DeviceConfigurationResponse struct {
+	Data DeviceConfigurationResponseData `json:"data"`
+}
+
+// DeviceConfigurationSources names the scope each effective field comes from:
+// device, group, organization, global or default, and schedule or override for
+// the mode
+type DeviceConfigurationSources struct {
+	Mode                  string `json:"mode"`
+	FormIds               string `json:"form_ids"`
+	DeviceComponentValues string `json:"device_component_values"`
+}
+
+// EffectiveDeviceConfigurationResponseData is the configuration a device runs
+// with once its scopes are merged. FormIds is null when no scope restricts the
+// forms.
+type EffectiveDeviceConfigurationResponseData struct {
+	DeviceId                string                     `json:"device_id"`
+	OrganizationId          *int64                     `json:"organization_id"`
+	GroupId                 *uint64                    `json:"group_id"`
+	Tags                    []string                   `json:"tags"`
+	Mode                    string                     `json:"mode"`
+	FormIds                 []uint64                   `json:"form_ids"`
+	DeviceComponentValuesId int64                      `json:"device_component_values_id"`
+	DeviceComponentValues   json.RawMessage            `json:"device_component_values"`
+	Sources                 DeviceConfigurationSources `json:"sources"`
+}
+
+type EffectiveDeviceConfigurationResponse struct {
+	Data EffectiveDeviceConfigurationResponseData `json:"data"`
+}
@@ -1,6 +1,9 @@
 package response
 
-import "sen-global-api/internal/domain/value"
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
 
 type DeviceListSettingResponse struct {
 	MacAddress   string `json:"macAddress"`
@@ -91,3 +94,53 @@ type DeviceResponseV2 struct {
 	ID         string `json:"id"`
 	DeviceName string `json:"device_name"`
 }
+
+type DeviceHeartbeatResponseData struct {
+	BatteryLevel     *int      `json:"battery_level"`
+	IsCharging       *bool     `json:"is_charging"`
+	AppVersion       string    `json:"app_version"`
+	IpAddress        string    `json:"ip_address"`
+	CurrentFormId    *uint64   `json:"current_form_id"`
+	FreeStorageBytes *int64    `json:"free_storage_bytes"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type DeviceHeartbeatListResponse struct {
+	Data []DeviceHeartbeatResponseData `json:"data"`
+}
+
+type DevicePresenceResponseData struct {
+	DeviceResponseDataV2
+	Presence        value.DevicePresence         `json:"presence"`
+	LastHeartbeatAt *time.Time                   `json:"last_heartbeat_at"`
+	LastHeartbeat   *DeviceHeartbeatResponseData `json:"last_heartbeat"`
+}
+
+type DevicePresenceListResponse struct {
+	Data   []DevicePresenceResponseData `json:"data"`
+	Paging Pagination                   `json:"pagination"`
+}
+
+// BulkDeviceOutcome is what a bulk operation did to one device. Outcome is
+// updated, unchanged or not_found, NotificationError is set when the device
+// could not be notified.
+type BulkDeviceOutcome struct {
+	DeviceId          string `json:"device_id"`
+	Outcome           string `json:"outcome"`
+	PreviousStatus    string `json:"previous_status,omitempty"`
+	Status            string `json:"status,omitempty"`
+	Notified          bool   `json:"notified"`
+	NotificationError string `json:"notification_error,omitempty"`
+}
+
+type BulkUpdateDevicesResponseData struct {
+	Matched   int                 `json:"matched"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	NotFound  int                 `json:"not_found"`
+	Devices   []BulkDeviceOutcome `json:"devices"`
+}
+
+type BulkUpdateDevicesResponse struct {
+	Data BulkUpdateDevicesResponseData `json:"data"`
+}
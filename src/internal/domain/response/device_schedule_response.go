@@ -0,0 +1,46 @@
+package response
+
+import "time"
+
+type DeviceModeScheduleResponseData struct {
+	Id        uint64    `json:"id"`
+	Scope     string    `json:"scope"`
+	ScopeId   string    `json:"scope_id"`
+	Name      string    `json:"name"`
+	Mode      string    `json:"mode"`
+	Days      []string  `json:"days"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Enabled   bool      `json:"enabled"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DeviceModeScheduleResponse struct {
+	Data DeviceModeScheduleResponseData `json:"data"`
+}
+
+type DeviceModeScheduleListResponse struct {
+	Data []DeviceModeScheduleResponseData `json:"data"`
+}
+
+type DeviceModeOverrideResponseData struct {
+	Id        uint64    `json:"id"`
+	Scope     string    `json:"scope"`
+	ScopeId   string    `json:"scope_id"`
+	Mode      *string   `json:"mode"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeviceModeOverrideResponse struct {
+	Data DeviceModeOverrideResponseData `json:"data"`
+}
+
+type DeviceModeOverrideListResponse struct {
+	Data []DeviceModeOverrideResponseData `json:"data"`
+}
@@ -5,3 +5,15 @@ type FailedResponse struct {
 	Message string `json:"message"`
 	Error   string `json:"error"`
 }
+
+type QuestionAnswerError struct {
+	QuestionId string `json:"question_id"`
+	Reason     string `json:"reason"`
+}
+
+type InvalidAnswersResponse struct {
+	Code    int                   `json:"status_code"`
+	Message string                `json:"message"`
+	Error   string                `json:"error"`
+	Errors  []QuestionAnswerError `json:"errors"`
+}
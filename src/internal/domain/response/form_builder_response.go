@@ -0,0 +1,40 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type FormQuestionResponseData struct {
+	QuestionId       string          `json:"question_id"`
+	QuestionType     string          `json:"question_type"`
+	Question         string          `json:"question"`
+	Attributes       json.RawMessage `json:"attributes"`
+	Status           string          `json:"status"`
+	Order            int             `json:"order"`
+	AnswerRequired   bool            `json:"answer_required"`
+	EnableOnMobile   string          `json:"enable_on_mobile"`
+	QuestionUniqueId *string         `json:"question_unique_id"`
+	Rules            json.RawMessage `json:"rules"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+type FormBuilderResponseData struct {
+	Id             uint64                     `json:"id"`
+	OrganizationId *int64                     `json:"organization_id"`
+	Note           string                     `json:"note"`
+	Name           string                     `json:"name"`
+	Password       string                     `json:"password"`
+	Questions      []FormQuestionResponseData `json:"questions"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at"`
+}
+
+type FormBuilderResponse struct {
+	Data FormBuilderResponseData `json:"data"`
+}
+
+type FormQuestionResponse struct {
+	Data FormQuestionResponseData `json:"data"`
+}
@@ -14,12 +14,13 @@ type CreateFormSucceedResponse struct {
 }
 
 type GetFormListResponseData struct {
-	Id          uint64    `json:"id"`
-	Spreadsheet string    `json:"spreadsheet_url"`
-	Password    string    `json:"password"`
-	Note        string    `json:"note"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id             uint64    `json:"id"`
+	OrganizationId *int64    `json:"organization_id"`
+	Spreadsheet    string    `json:"spreadsheet_url"`
+	Password       string    `json:"password"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type GetFormListResponse struct {
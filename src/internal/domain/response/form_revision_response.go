@@ -0,0 +1,52 @@
+package response
+
+import (
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+type FormRevisionResponseData struct {
+	Id          uint64                        `json:"id"`
+	FormId      uint64                        `json:"form_id"`
+	Revision    int                           `json:"revision"`
+	Status      value.FormRevisionStatus      `json:"status"`
+	Note        string                        `json:"note"`
+	Questions   []entity.FormRevisionQuestion `json:"questions,omitempty"`
+	PublishedAt *time.Time                    `json:"published_at"`
+	CreatedAt   time.Time                     `json:"created_at"`
+}
+
+type FormRevisionResponse struct {
+	Data FormRevisionResponseData `json:"data"`
+}
+
+type FormRevisionListResponse struct {
+	Data []FormRevisionResponseData `json:"data"`
+}
+
+type FormRevisionChange string
+
+const (
+	FormRevisionChangeAdded   FormRevisionChange = "added"
+	FormRevisionChangeRemoved FormRevisionChange = "removed"
+	FormRevisionChangeChanged FormRevisionChange = "changed"
+)
+
+type FormRevisionQuestionChange struct {
+	Change FormRevisionChange           `json:"change"`
+	Fields []string                     `json:"fields,omitempty"`
+	From   *entity.FormRevisionQuestion `json:"from,omitempty"`
+	To     *entity.FormRevisionQuestion `json:"to,omitempty"`
+}
+
+type FormRevisionDiffData struct {
+	FormId  uint64                       `json:"form_id"`
+	From    int                          `json:"from"`
+	To      int                          `json:"to"`
+	Changes []FormRevisionQuestionChange `json:"changes"`
+}
+
+type FormRevisionDiffResponse struct {
+	Data FormRevisionDiffData `json:"data"`
+}
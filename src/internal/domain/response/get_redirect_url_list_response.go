@@ -1,16 +1,30 @@
 package response
 
-import "time"
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
 
 type GetRedirectUrlListResponseData struct {
-	Id           uint64    `json:"id" binding:"required"`
-	QRCode       string    `json:"qr_code" binding:"required"`
-	TargetUrl    string    `json:"target_url" binding:"required"`
-	Password     *string   `json:"password" binding:"required"`
-	Hint         string    `json:"hint" binding:"required"`
-	HashPassword *string   `json:"hash_password" binding:"required"`
-	CreatedAt    time.Time `json:"created_at" binding:"required"`
-	UpdatedAt    time.Time `json:"updated_at" binding:"required"`
+	Id             uint64    `json:"id" binding:"required"`
+	OrganizationId *int64    `json:"organization_id"`
+	QRCode         string    `json:"qr_code" binding:"required"`
+	TargetUrl      string    `json:"target_url" binding:"required"`
+	Password       *string   `json:"password" binding:"required"`
+	Hint           string    `json:"hint" binding:"required"`
+	HashPassword   *string   `json:"hash_password" binding:"required"`
+	CreatedAt      time.Time `json:"created_at" binding:"required"`
+	UpdatedAt      time.Time `json:"updated_at" binding:"required"`
+	RedirectUrlScheduleData
+}
+
+// RedirectUrlScheduleData is when and where a redirect URL sends its scans
+type RedirectUrlScheduleData struct {
+	ActiveFrom  *time.Time                  `json:"active_from"`
+	ActiveUntil *time.Time                  `json:"active_until"`
+	FallbackUrl string                      `json:"fallback_url"`
+	TimeRules   []value.RedirectUrlTimeRule `json:"time_rules"`
+	Targets     []value.RedirectUrlTarget   `json:"targets"`
 }
 
 type GetRedirectUrlListResponse struct {
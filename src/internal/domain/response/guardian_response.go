@@ -0,0 +1,43 @@
+package response
+
+import "time"
+
+type GuardianLinkResponseData struct {
+	Id           uint64     `json:"id"`
+	UserId       string     `json:"user_id"`
+	GuardianId   string     `json:"guardian_id"`
+	Relationship string     `json:"relationship"`
+	Status       string     `json:"status"`
+	InvitedBy    string     `json:"invited_by"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+	EndedBy      string     `json:"ended_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type GuardianLinkResponse struct {
+	Data GuardianLinkResponseData `json:"data"`
+}
+
+type GuardianLinkListResponse struct {
+	Data []GuardianLinkResponseData `json:"data"`
+}
+
+type GuardianDeviceListResponse struct {
+	Data []DeviceResponseV2 `json:"data"`
+}
+
+type ToDoCompletionResponseData struct {
+	Id          uint64    `json:"id"`
+	ToDoId      string    `json:"todo_id"`
+	TaskIndex   int       `json:"task_index"`
+	TaskName    string    `json:"task_name"`
+	Selected    string    `json:"selected"`
+	DeviceId    string    `json:"device_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+type ToDoCompletionListResponse struct {
+	Data   []ToDoCompletionResponseData `json:"data"`
+	Paging Pagination                   `json:"paging"`
+}
@@ -7,6 +7,10 @@ type LoginResponseData struct {
 	Username string    `json:"username"`
 	Token    string    `json:"token"`
 	Expired  time.Time `json:"expired"`
+
+	SessionId           string    `json:"session_id,omitempty"`
+	RefreshToken        string    `json:"refresh_token,omitempty"`
+	RefreshTokenExpired time.Time `json:"refresh_token_expired,omitempty"`
 }
 
 type LoginResponse struct {
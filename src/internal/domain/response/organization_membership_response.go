@@ -0,0 +1,75 @@
+package response
+
+import "time"
+
+type OrganizationInvitationResponseData struct {
+	Id             uint64     `json:"id"`
+	CodeHint       string     `json:"code_hint"`
+	OrganizationId int64      `json:"organization_id"`
+	Email          string     `json:"email"`
+	RoleIds        []int64    `json:"role_ids"`
+	MaxUses        uint       `json:"max_uses"`
+	Uses           uint       `json:"uses"`
+	Active         bool       `json:"active"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	DeclinedAt     *time.Time `json:"declined_at"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Code and Link are only returned when an invitation without an email is
+	// created, the ones of an invitation with an email go to that email
+	Code string `json:"code,omitempty"`
+	Link string `json:"link,omitempty"`
+}
+
+type OrganizationInvitationResponse struct {
+	Data OrganizationInvitationResponseData `json:"data"`
+}
+
+type OrganizationInvitationListResponse struct {
+	Data []OrganizationInvitationResponseData `json:"data"`
+}
+
+type OrganizationInvitationReplyResponseData struct {
+	UserId    string    `json:"user_id"`
+	Reply     string    `json:"reply"`
+	RepliedAt time.Time `json:"replied_at"`
+}
+
+type OrganizationInvitationReplyListResponse struct {
+	Data []OrganizationInvitationReplyResponseData `json:"data"`
+}
+
+// OrganizationInvitationPreviewResponseData is what a user is invited to, shown
+// before they accept or decline
+type OrganizationInvitationPreviewResponseData struct {
+	OrganizationId   int64     `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Roles            []string  `json:"roles"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type OrganizationInvitationPreviewResponse struct {
+	Data OrganizationInvitationPreviewResponseData `json:"data"`
+}
+
+type OrganizationMemberResponseData struct {
+	UserId       string     `json:"user_id"`
+	Username     string     `json:"username"`
+	Fullname     string     `json:"fullname"`
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	Owner        bool       `json:"owner"`
+	InvitationId *uint64    `json:"invitation_id"`
+	SuspendedAt  *time.Time `json:"suspended_at"`
+	JoinedAt     time.Time  `json:"joined_at"`
+}
+
+type OrganizationMemberResponse struct {
+	Data OrganizationMemberResponseData `json:"data"`
+}
+
+type OrganizationMemberListResponse struct {
+	Data []OrganizationMemberResponseData `json:"data"`
+}
@@ -5,4 +5,6 @@ type OrganizationResponse struct {
 	OrganizationName string `json:"organization_name"`
 	Address     string `json:"address"`
 	Description string `json:"description"`
+	Timezone    string `json:"timezone"`
+	OwnerId     string `json:"owner_id"`
 }
@@ -1,6 +1,9 @@
 package response
 
-import "time"
+import (
+	"sen-global-api/internal/domain/entity"
+	"time"
+)
 
 type Messaging struct {
 	Email          []string `json:"email"`
@@ -18,13 +21,14 @@ type QuestionAttributes struct {
 }
 
 type QuestionListData struct {
-	QuestionId     string             `json:"question_id"`
-	QuestionType   string             `json:"question_type"`
-	Question       string             `json:"question"`
-	Attributes     QuestionAttributes `json:"attributes"`
-	Order          int                `json:"order"`
-	AnswerRequired bool               `json:"answer_required"`
-	Enabled        bool               `json:"enabled"`
+	QuestionId     string                `json:"question_id"`
+	QuestionType   string                `json:"question_type"`
+	Question       string                `json:"question"`
+	Attributes     QuestionAttributes    `json:"attributes"`
+	Order          int                   `json:"order"`
+	AnswerRequired bool                  `json:"answer_required"`
+	Enabled        bool                  `json:"enabled"`
+	Rules          []entity.QuestionRule `json:"rules"`
 }
 
 type QuestionListResponseData struct {
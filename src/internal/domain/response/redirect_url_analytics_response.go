@@ -0,0 +1,61 @@
+package response
+
+import "time"
+
+type RedirectUrlScanCountData struct {
+	Scans           int64 `json:"scans"`
+	Successes       int64 `json:"successes"`
+	FailedPasswords int64 `json:"failed_passwords"`
+	NotFound        int64 `json:"not_found"`
+	Inactive        int64 `json:"inactive"`
+}
+
+type RedirectUrlScanPeriodData struct {
+	Period string `json:"period"`
+	QRCode string `json:"qr_code"`
+	RedirectUrlScanCountData
+}
+
+type RedirectUrlTopCodeData struct {
+	QRCode        string     `json:"qr_code"`
+	LastScannedAt *time.Time `json:"last_scanned_at"`
+	RedirectUrlScanCountData
+}
+
+type RedirectUrlScanData struct {
+	Id            uint64    `json:"id"`
+	RedirectUrlId *uint64   `json:"redirect_url_id"`
+	QRCode        string    `json:"qr_code"`
+	Outcome       string    `json:"outcome"`
+	TargetUrl     string    `json:"target_url"`
+	DeviceId      string    `json:"device_id"`
+	UserId        string    `json:"user_id"`
+	UserAgent     string    `json:"user_agent"`
+	IpAddress     string    `json:"ip_address"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RedirectUrlAnalyticsResponseData reports the scans from From and before To:
+// the scans of each code by period, the most scanned codes and the last failed
+// password attempts. Targets counts the scans sent to each target of the codes,
+// to compare the targets of an A/B test.
+type RedirectUrlAnalyticsResponseData struct {
+	From            time.Time                   `json:"from"`
+	To              time.Time                   `json:"to"`
+	Interval        string                      `json:"interval"`
+	Totals          RedirectUrlScanCountData    `json:"totals"`
+	Timeline        []RedirectUrlScanPeriodData `json:"timeline"`
+	TopCodes        []RedirectUrlTopCodeData    `json:"top_codes"`
+	FailedPasswords []RedirectUrlScanData       `json:"failed_passwords"`
+	Targets         []RedirectUrlTargetData     `json:"targets"`
+}
+
+type RedirectUrlTargetData struct {
+	QRCode    string `json:"qr_code"`
+	TargetUrl string `json:"target_url"`
+	Scans     int64  `json:"scans"`
+}
+
+type RedirectUrlAnalyticsResponse struct {
+	Data RedirectUrlAnalyticsResponseData `json:"data"`
+}
@@ -3,12 +3,13 @@ package response
 import "time"
 
 type SaveFormResponseData struct {
-	Id          uint64    `json:"id"`
-	Spreadsheet string    `json:"spreadsheet_url"`
-	Password    string    `json:"password"`
-	Note        string    `json:"note"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id             uint64    `json:"id"`
+	OrganizationId *int64    `json:"organization_id"`
+	Spreadsheet    string    `json:"spreadsheet_url"`
+	Password       string    `json:"password"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type SaveFormResponse struct {
@@ -3,12 +3,14 @@ package response
 import "time"
 
 type SaveRedirectUrlResponseData struct {
-	Id        uint64    `json:"id" binding:"required"`
-	QRCode    string    `json:"qr_code" binding:"required"`
-	TargetUrl string    `json:"target_url" binding:"required"`
-	Password  *string   `json:"password"`
-	CreatedAt time.Time `json:"created_at" binding:"required"`
-	UpdatedAt time.Time `json:"updated_at" binding:"required"`
+	Id             uint64    `json:"id" binding:"required"`
+	OrganizationId *int64    `json:"organization_id"`
+	QRCode         string    `json:"qr_code" binding:"required"`
+	TargetUrl      string    `json:"target_url" binding:"required"`
+	Password       *string   `json:"password"`
+	CreatedAt      time.Time `json:"created_at" binding:"required"`
+	UpdatedAt      time.Time `json:"updated_at" binding:"required"`
+	RedirectUrlScheduleData
 }
 
 type SaveRedirectUrlResponse struct {
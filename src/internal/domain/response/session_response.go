@@ -0,0 +1,26 @@
+package response
+
+import "time"
+
+type SessionResponseData struct {
+	Id         string    `json:"id"`
+	UserId     string    `json:"user_id"`
+	DeviceId   string    `json:"device_id"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type SessionListResponse struct {
+	Data []SessionResponseData `json:"data"`
+}
+
+type RevokedSessionsResponseData struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type RevokedSessionsResponse struct {
+	Data RevokedSessionsResponseData `json:"data"`
+}
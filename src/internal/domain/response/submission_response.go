@@ -0,0 +1,26 @@
+package response
+
+import "time"
+
+type SubmissionAnswerResponseData struct {
+	QuestionId string `json:"question_id"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+type SubmissionResponseData struct {
+	Id             uint64                         `json:"id"`
+	FormId         uint64                         `json:"form_id"`
+	FormRevisionId *uint64                        `json:"form_revision_id"`
+	UserId         string                         `json:"user_id"`
+	Username       string                         `json:"username"`
+	Fullname       string                         `json:"fullname"`
+	Answers        []SubmissionAnswerResponseData `json:"answers"`
+	OpenedAt       time.Time                      `json:"opened_at"`
+	CreatedAt      time.Time                      `json:"created_at"`
+}
+
+type SubmissionListResponse struct {
+	Data   []SubmissionResponseData `json:"data"`
+	Paging Pagination               `json:"paging"`
+}
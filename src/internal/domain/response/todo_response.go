@@ -0,0 +1,130 @@
+package response
+
+import (
+	"sen-global-api/internal/domain/value"
+	"time"
+)
+
+type ToDoTaskResponseData struct {
+	Index        int                    `json:"index"`
+	Name         string                 `json:"name"`
+	DueDate      string                 `json:"due_date"`
+	DueAt        *time.Time             `json:"due_at"`
+	Overdue      bool                   `json:"overdue"`
+	Value        string                 `json:"value"`
+	Selection    string                 `json:"selection"`
+	Selected     string                 `json:"selected"`
+	CompletedAt  *time.Time             `json:"completed_at"`
+	CompletedBy  string                 `json:"completed_by"`
+	Recurrence   *value.ToDoRecurrence  `json:"recurrence"`
+	Occurrence   int                    `json:"occurrence"`
+	NextIndex    *int                   `json:"next_index"`
+	RemindBefore int                    `json:"remind_before"`
+	RemindedAt   *time.Time             `json:"reminded_at"`
+	Assignees    []ToDoTaskAssigneeData `json:"assignees"`
+}
+
+type ToDoTaskAssigneeData struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type ToDoResponseData struct {
+	Id                   string                 `json:"id"`
+	OrganizationId       *int64                 `json:"organization_id"`
+	Name                 string                 `json:"name"`
+	Type                 string                 `json:"type"`
+	SpreadsheetId        string                 `json:"spreadsheet_id"`
+	SheetName            string                 `json:"sheet_name"`
+	HistorySpreadsheetId string                 `json:"history_spreadsheet_id"`
+	HistorySheetName     string                 `json:"history_sheet_name"`
+	SheetSyncDisabled    bool                   `json:"sheet_sync_disabled"`
+	TasksEditedAt        *time.Time             `json:"tasks_edited_at"`
+	Tasks                []ToDoTaskResponseData `json:"tasks"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+}
+
+type ToDoResponse struct {
+	Data ToDoResponseData `json:"data"`
+}
+
+type ToDoListResponse struct {
+	Data   []ToDoResponseData `json:"data"`
+	Paging Pagination         `json:"paging"`
+}
+
+type ToDoTaskResponse struct {
+	Data ToDoTaskResponseData `json:"data"`
+}
+
+type ToDoAssigneeResponseData struct {
+	Type      string    `json:"type"`
+	Id        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ToDoAssigneeListResponse struct {
+	Data []ToDoAssigneeResponseData `json:"data"`
+}
+
+type ToDoProgressTaskData struct {
+	Index int        `json:"index"`
+	Name  string     `json:"name"`
+	DueAt *time.Time `json:"due_at"`
+}
+
+// ToDoProgressCellData is where an assignee is with a task. Assigned is false
+// for a task assigned to others only.
+type ToDoProgressCellData struct {
+	TaskIndex   int        `json:"task_index"`
+	Assigned    bool       `json:"assigned"`
+	Done        bool       `json:"done"`
+	Overdue     bool       `json:"overdue"`
+	Selected    string     `json:"selected"`
+	CompletedAt *time.Time `json:"completed_at"`
+	DeviceId    string     `json:"device_id"`
+}
+
+type ToDoProgressRowData struct {
+	AssigneeType string                 `json:"assignee_type"`
+	AssigneeId   string                 `json:"assignee_id"`
+	Name         string                 `json:"name"`
+	Assigned     int                    `json:"assigned"`
+	Completed    int                    `json:"completed"`
+	Tasks        []ToDoProgressCellData `json:"tasks"`
+}
+
+// ToDoProgressResponseData is the completion matrix of a list, a row per
+// assignee and a cell per task in the order of Tasks
+type ToDoProgressResponseData struct {
+	ToDoId    string                 `json:"todo_id"`
+	Name      string                 `json:"name"`
+	Tasks     []ToDoProgressTaskData `json:"tasks"`
+	Assignees []ToDoProgressRowData  `json:"assignees"`
+}
+
+type ToDoProgressResponse struct {
+	Data ToDoProgressResponseData `json:"data"`
+}
+
+type ToDoSheetSyncResponseData struct {
+	Id            uint64     `json:"id"`
+	ToDoId        string     `json:"todo_id"`
+	Kind          string     `json:"kind"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	SyncedAt      *time.Time `json:"synced_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type ToDoSheetSyncResponse struct {
+	Data ToDoSheetSyncResponseData `json:"data"`
+}
+
+type ToDoSheetSyncListResponse struct {
+	Data   []ToDoSheetSyncResponseData `json:"data"`
+	Paging Pagination                  `json:"paging"`
+}
@@ -0,0 +1,52 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookResponseData struct {
+	Id        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookResponse struct {
+	Data WebhookResponseData `json:"data"`
+}
+
+type WebhookListResponse struct {
+	Data []WebhookResponseData `json:"data"`
+}
+
+type WebhookDeliveryResponseData struct {
+	Id             uint64          `json:"id"`
+	WebhookId      uint64          `json:"webhook_id"`
+	EventId        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	ResponseStatus int             `json:"response_status"`
+	ResponseBody   string          `json:"response_body"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	ReplayOf       *uint64         `json:"replay_of"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	Data WebhookDeliveryResponseData `json:"data"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Data   []WebhookDeliveryResponseData `json:"data"`
+	Paging Pagination                    `json:"paging"`
+}
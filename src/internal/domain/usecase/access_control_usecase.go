@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var ErrOutOfScope = errors.New("not allowed to manage this organization")
+var ErrOrganizationRequired = errors.New("organization_id is required for a user of several organizations")
+
+// AccessControlUseCase checks that the targets of the user, role, role claim and
+// role policy routes belong to the organizations of the value.AccessScope of the
+// request, and keeps the audit log of the changes made through those routes.
+// Roles without an organization can only be managed with a scope over every
+// organization.
+type AccessControlUseCase struct {
+	PermissionRepository  *repository.PermissionRepository
+	AuditLogRepository    *repository.AuditLogRepository
+	DeviceRepository      *repository.DeviceRepository
+	DeviceGroupRepository *repository.DeviceGroupRepository
+}
+
+func NewAccessControlUseCase(db *gorm.DB, defaultRequestPageSize int) *AccessControlUseCase {
+	return &AccessControlUseCase{
+		PermissionRepository:  &repository.PermissionRepository{DBConn: db},
+		AuditLogRepository:    &repository.AuditLogRepository{DBConn: db, DefaultRequestPageSize: defaultRequestPageSize},
+		DeviceRepository:      &repository.DeviceRepository{DBConn: db},
+		DeviceGroupRepository: &repository.DeviceGroupRepository{DBConn: db},
+	}
+}
+
+func (receiver *AccessControlUseCase) AuthorizeOrganization(scope value.AccessScope, organizationId int64) error {
+	if !scope.Allows(organizationId) {
+		return ErrOutOfScope
+	}
+
+	return nil
+}
+
+// OwningOrganization returns the organization the forms, redirect URLs, to-dos
+// and settings of a request belong to: the requested one when the scope covers
+// it, otherwise the only organization of the scope. A scope over every
+// organization without a requested one works on the shared rows, nil.
+func OwningOrganization(scope value.AccessScope, organizationId *int64) (*int64, error) {
+	if organizationId != nil {
+		if !scope.Allows(*organizationId) {
+			return nil, ErrOutOfScope
+		}
+
+		return organizationId, nil
+	}
+	if scope.AllOrganizations {
+		return nil, nil
+	}
+	if len(scope.OrganizationIds) == 1 {
+		id := scope.OrganizationIds[0]
+		return &id, nil
+	}
+	if len(scope.OrganizationIds) == 0 {
+		return nil, ErrOutOfScope
+	}
+
+	return nil, ErrOrganizationRequired
+}
+
+// AuthorizeRole returns the organization of the role when the scope covers it
+func (receiver *AccessControlUseCase) AuthorizeRole(scope value.AccessScope, roleId int64) (int64, error) {
+	organizationId, err := receiver.PermissionRepository.GetRoleOrganizationId(roleId)
+	if err != nil {
+		return 0, err
+	}
+
+	return organizationId, receiver.AuthorizeOrganization(scope, organizationId)
+}
+
+func (receiver *AccessControlUseCase) AuthorizeRoleClaim(scope value.AccessScope, roleClaimId int64) (int64, error) {
+	organizationId, err := receiver.PermissionRepository.GetRoleClaimOrganizationId(roleClaimId)
+	if err != nil {
+		return 0, err
+	}
+
+	return organizationId, receiver.AuthorizeOrganization(scope, organizationId)
+}
+
+func (receiver *AccessControlUseCase) AuthorizeRoleClaimPermission(scope value.AccessScope, roleClaimPermissionId int64) (int64, error) {
+	organizationId, err := receiver.PermissionRepository.GetRoleClaimPermissionOrganizationId(roleClaimPermissionId)
+	if err != nil {
+		return 0, err
+	}
+
+	return organizationId, receiver.AuthorizeOrganization(scope, organizationId)
+}
+
+// AuthorizeDevice returns an organization of the device within the scope, see
+// DeviceRepository.GetDeviceOrganizationIds, 0 for a device of no organization,
+// which only a scope over every organization manages
+func (receiver *AccessControlUseCase) AuthorizeDevice(scope value.AccessScope, deviceId string) (int64, error) {
+	organizationIds, err := receiver.DeviceRepository.GetDeviceOrganizationIds(deviceId)
+	if err != nil {
+		return 0, err
+	}
+	for _, organizationId := range organizationIds {
+		if scope.Allows(organizationId) {
+			return organizationId, nil
+		}
+	}
+	if scope.AllOrganizations {
+		return 0, nil
+	}
+
+	return 0, ErrOutOfScope
+}
+
+// AuthorizeDeviceScope returns the organization of the device, group or
+// organization a device configuration, schedule or override is set for when the
+// scope covers it, 0 when there is none. The global scope and the shared groups
+// can be read by every scope but only changed by a scope over every
+// organization. Invalid scope ids are left to the use cases to report.
+func (receiver *AccessControlUseCase) AuthorizeDeviceScope(scope value.AccessScope, deviceScope value.DeviceConfigurationScope, scopeId string, change bool) (int64, error) {
+	switch deviceScope {
+	case value.DeviceConfigurationScope_Device:
+		return receiver.AuthorizeDevice(scope, scopeId)
+	case value.DeviceConfigurationScope_Group:
+		id, err := strconv.ParseUint(scopeId, 10, 64)
+		if err != nil {
+			return 0, nil
+		}
+		group, err := receiver.DeviceGroupRepository.GetGroup(id)
+		if err != nil {
+			return 0, err
+		}
+		if (change || group.OrganizationId != nil) && !scope.Owns(group.OrganizationId) {
+			return 0, ErrOutOfScope
+		}
+		if group.OrganizationId == nil {
+			return 0, nil
+		}
+		return *group.OrganizationId, nil
+	case value.DeviceConfigurationScope_Organization:
+		id, err := strconv.ParseInt(scopeId, 10, 64)
+		if err != nil {
+			return 0, nil
+		}
+		return id, receiver.AuthorizeOrganization(scope, id)
+	case value.DeviceConfigurationScope_Global:
+		if change && !scope.AllOrganizations {
+			return 0, ErrOutOfScope
+		}
+	}
+
+	return 0, nil
+}
+
+// AuthorizeUser checks that the user is the user of the scope or a member of one
+// of the organizations of the scope, and returns the organizations of the user
+// within the scope
+func (receiver *AccessControlUseCase) AuthorizeUser(scope value.AccessScope, userId string) ([]int64, error) {
+	organizationIds, err := receiver.PermissionRepository.GetUserOrganizationIds(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsAny(organizationIds) && (scope.UserId == "" || scope.UserId != userId) {
+		return nil, ErrOutOfScope
+	}
+
+	scoped := make([]int64, 0, len(organizationIds))
+	for _, organizationId := range organizationIds {
+		if scope.Allows(organizationId) {
+			scoped = append(scoped, organizationId)
+		}
+	}
+
+	return scoped, nil
+}
+
+// ScopeUserRoles returns the roles a user ends up with when the roles within the
+// scope are replaced by roleIds. Every role of roleIds must be within the scope,
+// the roles of the user in other organizations are kept.
+func (receiver *AccessControlUseCase) ScopeUserRoles(scope value.AccessScope, userId string, roleIds []uint) ([]uint, error) {
+	for _, roleId := range roleIds {
+		if _, err := receiver.AuthorizeRole(scope, int64(roleId)); err != nil {
+			return nil, err
+		}
+	}
+	if scope.AllOrganizations {
+		return roleIds, nil
+	}
+
+	current, err := receiver.PermissionRepository.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := append(make([]uint, 0, len(roleIds)+len(current)), roleIds...)
+	for _, role := range current {
+		if !scope.Allows(role.OrganizationId) {
+			scoped = append(scoped, uint(role.ID))
+		}
+	}
+
+	return scoped, nil
+}
+
+// ScopeUserRoleNames is ScopeUserRoles for roles given by name
+func (receiver *AccessControlUseCase) ScopeUserRoleNames(scope value.AccessScope, userId string, roleNames []string) ([]string, error) {
+	for _, roleName := range roleNames {
+		_, organizationId, err := receiver.PermissionRepository.GetRoleOrganizationIdByName(roleName)
+		if err != nil {
+			return nil, err
+		}
+		if err = receiver.AuthorizeOrganization(scope, organizationId); err != nil {
+			return nil, err
+		}
+	}
+	if scope.AllOrganizations || userId == "" {
+		return roleNames, nil
+	}
+
+	current, err := receiver.PermissionRepository.GetUserRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := append(make([]string, 0, len(roleNames)+len(current)), roleNames...)
+	for _, role := range current {
+		if !scope.Allows(role.OrganizationId) {
+			scoped = append(scoped, role.RoleName)
+		}
+	}
+
+	return scoped, nil
+}
+
+// FilterUsers keeps the users that are a member of one of the organizations of
+// the scope
+func (receiver *AccessControlUseCase) FilterUsers(scope value.AccessScope, users []entity.SUserEntity) ([]entity.SUserEntity, error) {
+	if scope.AllOrganizations {
+		return users, nil
+	}
+
+	filtered := make([]entity.SUserEntity, 0, len(users))
+	for _, user := range users {
+		organizationIds, err := receiver.PermissionRepository.GetUserOrganizationIds(user.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		if scope.AllowsAny(organizationIds) {
+			filtered = append(filtered, user)
+		}
+	}
+
+	return filtered, nil
+}
+
+// AllowsRole is AuthorizeRole for filtering lists, it caches the organization of
+// every role it looks up
+func (receiver *AccessControlUseCase) AllowsRole(scope value.AccessScope, roleId int64, organizations map[int64]int64) (bool, error) {
+	if scope.AllOrganizations {
+		return true, nil
+	}
+
+	organizationId, ok := organizations[roleId]
+	if !ok {
+		var err error
+		organizationId, err = receiver.PermissionRepository.GetRoleOrganizationId(roleId)
+		if err != nil {
+			return false, err
+		}
+		organizations[roleId] = organizationId
+	}
+
+	return scope.Allows(organizationId), nil
+}
+
+// AllowsRoleClaim is AllowsRole for role claims
+func (receiver *AccessControlUseCase) AllowsRoleClaim(scope value.AccessScope, roleClaimId int64, organizations map[int64]int64) (bool, error) {
+	if scope.AllOrganizations {
+		return true, nil
+	}
+
+	organizationId, ok := organizations[roleClaimId]
+	if !ok {
+		var err error
+		organizationId, err = receiver.PermissionRepository.GetRoleClaimOrganizationId(roleClaimId)
+		if err != nil {
+			return false, err
+		}
+		organizations[roleClaimId] = organizationId
+	}
+
+	return scope.Allows(organizationId), nil
+}
+
+// Audit records a change, a failure to write the record is only logged
+func (receiver *AccessControlUseCase) Audit(scope value.AccessScope, ipAddress string, action string, organizationId int64, targetType string, targetId string, details interface{}) {
+	auditLog := entity.SAuditLog{
+		ActorId:    scope.UserId,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		IpAddress:  ipAddress,
+	}
+	if organizationId != 0 {
+		auditLog.OrganizationId = &organizationId
+	}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			log.Error("AccessControlUseCase.Audit ", err)
+		} else {
+			auditLog.Details = data
+		}
+	}
+
+	err := receiver.AuditLogRepository.CreateAuditLog(&auditLog)
+	if err != nil {
+		log.Error("AccessControlUseCase.Audit ", action, " ", targetType, " ", targetId, " ", err)
+	}
+}
+
+// GetAuditLogs lists the audit records of the organizations of the scope
+func (receiver *AccessControlUseCase) GetAuditLogs(scope value.AccessScope, req request.GetAuditLogsRequest) ([]entity.SAuditLog, *response.Pagination, error) {
+	var organizationIds []int64 = nil
+	if !scope.AllOrganizations {
+		organizationIds = make([]int64, 0, len(scope.OrganizationIds))
+		organizationIds = append(organizationIds, scope.OrganizationIds...)
+	}
+
+	return receiver.AuditLogRepository.GetAuditLogs(req, organizationIds)
+}
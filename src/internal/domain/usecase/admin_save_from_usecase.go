@@ -21,7 +21,7 @@ type SaveFormUseCase struct {
 	*repository.FormRepository
 	*repository.QuestionRepository
 	*repository.FormQuestionRepository
-	SpreadsheetReader *sheet.Reader
+	SpreadsheetReader sheet.SpreadsheetReader
 }
 
 func (receiver *SaveFormUseCase) SaveForm(req request.SaveFormRequest) (*entity.SForm, error) {
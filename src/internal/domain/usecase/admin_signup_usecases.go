@@ -19,7 +19,7 @@ import (
 type AdminSignUpUseCases struct {
 	SettingRepository *repository.SettingRepository
 	FormRepository    *repository.FormRepository
-	SpreadsheetReader *sheet.Reader
+	SpreadsheetReader sheet.SpreadsheetReader
 	config.AppConfig
 	ImportFormsUseCase *ImportFormsUseCase
 }
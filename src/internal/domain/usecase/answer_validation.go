@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// answerValidator checks a non empty answer against the attributes of its question
+type answerValidator func(answer string, attributes []byte) error
+
+var answerValidators = map[value.QuestionType]answerValidator{
+	value.QuestionDate:             validateDateAnswer,
+	value.QuestionTime:             validateTimeAnswer,
+	value.QuestionDateTime:         validateDateTimeAnswer,
+	value.QuestionDurationForward:  validateDurationAnswer,
+	value.QuestionDurationBackward: validateDurationAnswer,
+	value.QuestionScale:            validateScaleAnswer,
+	value.QuestionNumber:           validateNumberAnswer,
+	value.QuestionCount:            validateCountAnswer,
+	value.QuestionButtonCount:      validateCountAnswer,
+	value.QuestionSelection:        validateSingleChoiceAnswer,
+	value.QuestionSingleChoice:     validateSingleChoiceAnswer,
+	value.QuestionChoiceToggle:     validateSingleChoiceAnswer,
+	value.QuestionMultipleChoice:   validateMultipleChoiceAnswer,
+
+	value.QuestionDateUser:             validateDateAnswer,
+	value.QuestionTimeUser:             validateTimeAnswer,
+	value.QuestionDateTimeUser:         validateDateTimeAnswer,
+	value.QuestionDurationForwardUser:  validateDurationAnswer,
+	value.QuestionDurationBackwardUser: validateDurationAnswer,
+	value.QuestionScaleUser:            validateScaleAnswer,
+	value.QuestionNumberUser:           validateNumberAnswer,
+	value.QuestionCountUser:            validateCountAnswer,
+	value.QuestionButtonCountUser:      validateCountAnswer,
+	value.QuestionSelectionUser:        validateSingleChoiceAnswer,
+	value.QuestionSingleChoiceUser:     validateSingleChoiceAnswer,
+	value.QuestionChoiceToggleUser:     validateSingleChoiceAnswer,
+	value.QuestionMultipleChoiceUser:   validateMultipleChoiceAnswer,
+}
+
+var (
+	answerDateLayouts = []string{"2006-01-02", "2006/01/02", "02/01/2006", "02-01-2006", "02.01.2006"}
+	answerTimeLayouts = []string{"15:04", "15:04:05", "3:04 PM", "03:04 PM", "3:04PM", "03:04PM"}
+	clockDuration     = regexp.MustCompile(`^\d+:[0-5]\d(:[0-5]\d)?$`)
+)
+
+// ValidateAnswer checks an answer against the type and attributes of its question.
+// Empty answers and question types without a validator are accepted.
+func ValidateAnswer(question model.FormQuestionItem, answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil
+	}
+
+	questionType, err := value.GetQuestionType(question.QuestionType)
+	if err != nil {
+		return nil
+	}
+
+	validator, ok := answerValidators[questionType]
+	if !ok {
+		return nil
+	}
+
+	return validator(answer, question.Attributes)
+}
+
+func validateDateAnswer(answer string, _ []byte) error {
+	if parseAnswerTime(answer, answerDateLayouts) {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a valid date, use YYYY-MM-DD", answer)
+}
+
+func validateTimeAnswer(answer string, _ []byte) error {
+	if parseAnswerTime(strings.ToUpper(answer), answerTimeLayouts) {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a valid time, use HH:MM", answer)
+}
+
+func validateDateTimeAnswer(answer string, _ []byte) error {
+	layouts := []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02T15:04:05"}
+	for _, dateLayout := range answerDateLayouts {
+		for _, timeLayout := range answerTimeLayouts {
+			layouts = append(layouts, dateLayout+" "+timeLayout)
+		}
+	}
+	if parseAnswerTime(strings.ToUpper(answer), layouts) {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a valid date and time, use YYYY-MM-DD HH:MM", answer)
+}
+
+func validateDurationAnswer(answer string, _ []byte) error {
+	if number, err := strconv.ParseFloat(answer, 64); err == nil && number >= 0 {
+		return nil
+	}
+	if clockDuration.MatchString(answer) {
+		return nil
+	}
+	if duration, err := time.ParseDuration(answer); err == nil && duration >= 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a valid duration", answer)
+}
+
+func validateScaleAnswer(answer string, attributes []byte) error {
+	number, err := strconv.ParseFloat(answer, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", answer)
+	}
+
+	var attr struct {
+		Number int `json:"number"`
+	}
+	if json.Unmarshal(attributes, &attr) != nil || attr.Number <= 0 {
+		return nil
+	}
+	if number < 0 || number > float64(attr.Number) {
+		return fmt.Errorf("%s is outside of the scale 0 to %d", answer, attr.Number)
+	}
+
+	return nil
+}
+
+func validateNumberAnswer(answer string, _ []byte) error {
+	number, err := strconv.ParseFloat(answer, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return fmt.Errorf("%q is not a number", answer)
+	}
+
+	return nil
+}
+
+func validateCountAnswer(answer string, _ []byte) error {
+	count, err := strconv.Atoi(answer)
+	if err != nil || count < 0 {
+		return fmt.Errorf("%q is not a whole number of 0 or more", answer)
+	}
+
+	return nil
+}
+
+func validateSingleChoiceAnswer(answer string, attributes []byte) error {
+	options, ok := answerOptions(attributes)
+	if !ok || options[strings.ToLower(answer)] {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not one of the options", answer)
+}
+
+// validateMultipleChoiceAnswer accepts one option or several options joined by ","
+// or ";". An option whose name contains a separator still matches as a whole.
+func validateMultipleChoiceAnswer(answer string, attributes []byte) error {
+	options, ok := answerOptions(attributes)
+	if !ok || options[strings.ToLower(answer)] {
+		return nil
+	}
+
+	for _, separator := range []string{",", ";"} {
+		matched := true
+		for _, part := range strings.Split(answer, separator) {
+			if !options[strings.ToLower(strings.TrimSpace(part))] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q is not made of the options", answer)
+}
+
+// answerOptions returns the lower cased option names, ok is false when the question
+// has no options to check against
+func answerOptions(attributes []byte) (map[string]bool, bool) {
+	var attr struct {
+		Options []struct {
+			Name string `json:"name"`
+		} `json:"options"`
+	}
+	if json.Unmarshal(attributes, &attr) != nil || len(attr.Options) == 0 {
+		return nil, false
+	}
+
+	options := make(map[string]bool, len(attr.Options))
+	for _, option := range attr.Options {
+		options[strings.ToLower(strings.TrimSpace(option.Name))] = true
+	}
+
+	return options, true
+}
+
+func parseAnswerTime(answer string, layouts []string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, answer); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
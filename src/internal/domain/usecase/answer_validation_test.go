@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"sen-global-api/internal/domain/model"
+	"testing"
+)
+
+func TestValidateAnswer(t *testing.T) {
+	const (
+		scale   = `{"number": 10}`
+		colors  = `{"options": [{"name": "Red"}, {"name": "Green"}, {"name": "Blue"}]}`
+		options = `{"options": [{"name": "Salt, pepper"}, {"name": "Oil; vinegar"}, {"name": "None"}]}`
+	)
+
+	tests := []struct {
+		name         string
+		questionType string
+		attributes   string
+		answer       string
+		valid        bool
+	}{
+		{"empty answer", "number", "", "  ", true},
+		{"type without a validator", "text", "", "anything", true},
+		{"unknown type", "hologram", "", "anything", true},
+
+		{"iso date", "date", "", "2026-10-18", true},
+		{"european date", "date_user", "", "18.10.2026", true},
+		{"impossible date", "date", "", "2026-02-30", false},
+		{"date with words", "date", "", "tomorrow", false},
+
+		{"24 hour time", "time", "", "23:59", true},
+		{"12 hour time in lower case", "time", "", "7:05 pm", true},
+		{"time out of range", "time", "", "24:10", false},
+
+		{"rfc3339 date time", "datetime", "", "2026-10-18T08:30:00+02:00", true},
+		{"date and 12 hour time", "datetime", "", "18/10/2026 8:30 am", true},
+		{"date without time", "datetime", "", "2026-10-18", false},
+
+		{"duration in minutes", "duration_forward", "", "90", true},
+		{"clock duration", "duration_backward", "", "1:30:00", true},
+		{"go duration", "duration_forward", "", "1h30m", true},
+		{"negative duration", "duration_forward", "", "-5", false},
+		{"clock duration with 60 minutes", "duration_forward", "", "1:60", false},
+
+		{"scale within range", "scale", scale, "10", true},
+		{"scale above range", "scale", scale, "11", false},
+		{"scale below range", "scale", scale, "-1", false},
+		{"scale without a size", "scale", "{}", "42", true},
+		{"scale not a number", "scale", scale, "ten", false},
+
+		{"decimal number", "number", "", "-3.25", true},
+		{"not a number", "number_user", "", "NaN", false},
+		{"infinite number", "number", "", "Inf", false},
+
+		{"count", "count", "", "3", true},
+		{"negative count", "button_count", "", "-1", false},
+		{"decimal count", "count_user", "", "1.5", false},
+
+		{"single choice ignores case", "single_choice", colors, "green", true},
+		{"single choice not an option", "single_choice_user", colors, "Purple", false},
+		{"single choice without options", "selection", "{}", "Purple", true},
+		{"choice toggle", "choice_toggle", colors, "Blue", true},
+
+		{"multiple choice one option", "multiple_choice", colors, "Red", true},
+		{"multiple choice joined by commas", "multiple_choice", colors, "Red, blue", true},
+		{"multiple choice joined by semicolons", "multiple_choice_user", colors, "Red;Green;Blue", true},
+		{"multiple choice with an unknown option", "multiple_choice", colors, "Red, Purple", false},
+		{"multiple choice mixing separators", "multiple_choice", colors, "Red, Green; Blue", false},
+		{"option containing a comma alone", "multiple_choice", options, "Salt, pepper", true},
+		{"option containing a comma joined by semicolons", "multiple_choice", options, "Salt, pepper;None", true},
+		{"option containing a semicolon joined by commas", "multiple_choice", options, "Oil; vinegar,None", true},
+		{"option containing a semicolon alone", "multiple_choice", options, "oil; VINEGAR", true},
+		{"parts of an option", "multiple_choice", options, "Salt;pepper", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			question := model.FormQuestionItem{QuestionType: test.questionType, Attributes: []byte(test.attributes)}
+			err := ValidateAnswer(question, test.answer)
+			if test.valid && err != nil {
+				t.Errorf("ValidateAnswer(%s, %q) returned %v", test.questionType, test.answer, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("ValidateAnswer(%s, %q) accepted the answer", test.questionType, test.answer)
+			}
+		})
+	}
+}
@@ -30,8 +30,9 @@ func (receiver AuthorizeUseCase) LoginInputDao(req request.UserLoginRequest) (re
 		return response.LoginResponseData{}, errors.New("invalid username or password")
 	}
 
-	token, err := receiver.GenerateToken(*user)
+	token, err := StartSession(&receiver.SessionRepository, *user, "", req.UserAgent, req.IpAddress)
 	if err != nil {
+		log.Error("AuthorizeUseCase.LoginInputDao ", err)
 		return response.LoginResponseData{}, errors.New("cannot generate token")
 	}
 
@@ -70,8 +71,9 @@ func (receiver AuthorizeUseCase) UserLoginUsecase(req request.UserLoginFromDevic
 		return response.LoginResponseData{}, errors.New("invalid username or password")
 	}
 
-	token, err := receiver.GenerateToken(*user)
+	token, err := StartSession(&receiver.SessionRepository, *user, req.DeviceUUID, req.UserAgent, req.IpAddress)
 	if err != nil {
+		log.Error("AuthorizeUseCase.UserLoginUsecase ", err)
 		return response.LoginResponseData{}, errors.New("cannot generate token")
 	}
 
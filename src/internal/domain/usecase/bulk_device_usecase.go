@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/messaging"
+	"strings"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// bulkDeviceLimit is the number of devices one bulk operation changes at most
+const bulkDeviceLimit = 1000
+
+const (
+	BulkDeviceOutcome_Updated   = "updated"
+	BulkDeviceOutcome_Unchanged = "unchanged"
+	BulkDeviceOutcome_NotFound  = "not_found"
+)
+
+var ErrInvalidBulkDeviceRequest = errors.New("invalid bulk device request")
+
+// BulkDeviceUseCase changes many devices at once. The devices of a filter are
+// saved in one transaction and every changed device is notified through FCM.
+type BulkDeviceUseCase struct {
+	DeviceRepository       *repository.DeviceRepository
+	MobileDeviceRepository *repository.MobileDeviceRepository
+	FirebaseApp            *firebase.App
+	DB                     *gorm.DB
+}
+
+func NewBulkDeviceUseCase(db *gorm.DB, app *firebase.App) *BulkDeviceUseCase {
+	return &BulkDeviceUseCase{
+		DeviceRepository:       &repository.DeviceRepository{DBConn: db},
+		MobileDeviceRepository: repository.NewMobileDeviceRepository(),
+		FirebaseApp:            app,
+		DB:                     db,
+	}
+}
+
+// UpdateDevices applies the fields of the request to the devices of its filter
+// within the organizations of the scope and reports the outcome of each device,
+// the devices out of the scope are reported as not found
+func (receiver *BulkDeviceUseCase) UpdateDevices(scope value.AccessScope, req request.BulkUpdateDevicesRequest) (*response.BulkUpdateDevicesResponseData, error) {
+	err := receiver.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Filter.OrganizationId != nil && !scope.Allows(*req.Filter.OrganizationId) {
+		return nil, ErrOutOfScope
+	}
+
+	var organizationIds []int64 = nil
+	if !scope.AllOrganizations {
+		organizationIds = append(make([]int64, 0, len(scope.OrganizationIds)), scope.OrganizationIds...)
+	}
+	devices, err := receiver.DeviceRepository.FindDevicesByFilter(req.Filter, organizationIds, bulkDeviceLimit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) > bulkDeviceLimit {
+		return nil, fmt.Errorf("%w: the filter matches more than %d devices", ErrInvalidBulkDeviceRequest, bulkDeviceLimit)
+	}
+
+	result := &response.BulkUpdateDevicesResponseData{
+		Matched: len(devices),
+		Devices: make([]response.BulkDeviceOutcome, 0, len(devices)),
+	}
+	now := time.Now()
+	changed := make([]entity.SDevice, 0, len(devices))
+	found := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		found[device.ID] = true
+		previousStatus := device.Status
+		if !applyBulkDeviceRequest(&device, req) {
+			result.Unchanged++
+			result.Devices = append(result.Devices, response.BulkDeviceOutcome{
+				DeviceId: device.ID,
+				Outcome:  BulkDeviceOutcome_Unchanged,
+				Status:   string(device.Status),
+			})
+			continue
+		}
+
+		device.UpdatedAt = now
+		changed = append(changed, device)
+		result.Updated++
+		result.Devices = append(result.Devices, response.BulkDeviceOutcome{
+			DeviceId:       device.ID,
+			Outcome:        BulkDeviceOutcome_Updated,
+			PreviousStatus: string(previousStatus),
+			Status:         string(device.Status),
+		})
+	}
+	for _, deviceId := range req.Filter.DeviceIds {
+		if !found[deviceId] {
+			found[deviceId] = true
+			result.NotFound++
+			result.Devices = append(result.Devices, response.BulkDeviceOutcome{
+				DeviceId: deviceId,
+				Outcome:  BulkDeviceOutcome_NotFound,
+			})
+		}
+	}
+
+	err = receiver.DeviceRepository.SaveOrUpdateDevices(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, outcome := range result.Devices {
+		if outcome.Outcome == BulkDeviceOutcome_Updated && outcome.Status != outcome.PreviousStatus {
+			PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+				"device_id":       outcome.DeviceId,
+				"status":          outcome.Status,
+				"previous_status": outcome.PreviousStatus,
+			})
+		}
+	}
+	notificationErrors := receiver.notify(changed)
+	for i, outcome := range result.Devices {
+		if outcome.Outcome != BulkDeviceOutcome_Updated {
+			continue
+		}
+		if message, failed := notificationErrors[outcome.DeviceId]; failed {
+			result.Devices[i].NotificationError = message
+		} else {
+			result.Devices[i].Notified = true
+		}
+	}
+
+	return result, nil
+}
+
+func (receiver *BulkDeviceUseCase) validate(req request.BulkUpdateDevicesRequest) error {
+	filter := req.Filter
+	if len(filter.DeviceIds) == 0 && filter.OrganizationId == nil && filter.Mode == "" && filter.AppVersion == "" && strings.Trim(filter.NamePattern, "*") == "" && filter.GroupId == nil && filter.Tag == "" {
+		return fmt.Errorf("%w: the filter needs device_ids, organization_id, mode, app_version, name_pattern, group_id or tag", ErrInvalidBulkDeviceRequest)
+	}
+	if len(filter.DeviceIds) > bulkDeviceLimit {
+		return fmt.Errorf("%w: at most %d device_ids", ErrInvalidBulkDeviceRequest, bulkDeviceLimit)
+	}
+	if filter.Mode != "" {
+		if _, err := value.GetDeviceModeFromString(filter.Mode); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidBulkDeviceRequest, err.Error())
+		}
+	}
+
+	if req.Status == nil && req.DeactivateMessage == nil && req.InputMode == nil && req.ScreenButtonType == nil && req.DeviceComponentValuesId == nil {
+		return fmt.Errorf("%w: nothing to update", ErrInvalidBulkDeviceRequest)
+	}
+	if req.Status != nil {
+		if _, err := value.GetDeviceModeFromString(*req.Status); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidBulkDeviceRequest, err.Error())
+		}
+	}
+	if req.InputMode != nil {
+		switch value.InfoInputType(*req.InputMode) {
+		case value.InfoInputTypeKeyboard, value.InfoInputTypeBarcode, value.InfoInputTypeBackOffice:
+		default:
+			return fmt.Errorf("%w: input_mode is keyboard, scanned or back_office", ErrInvalidBulkDeviceRequest)
+		}
+	}
+	if req.ScreenButtonType != nil {
+		switch value.ScreenButtonType(*req.ScreenButtonType) {
+		case value.ScreenButtonType_Scan, value.ScreenButtonType_List:
+		default:
+			return fmt.Errorf("%w: screen_button_type is scan or list", ErrInvalidBulkDeviceRequest)
+		}
+	}
+	if req.DeviceComponentValuesId != nil {
+		var count int64
+		err := receiver.DB.Model(&entity.SDeviceComponentValues{}).Where("id = ?", *req.DeviceComponentValuesId).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: no device component values %d", ErrInvalidBulkDeviceRequest, *req.DeviceComponentValuesId)
+		}
+	}
+
+	return nil
+}
+
+// notify sends the new state of the changed devices through FCM, it returns why
+// a device could not be notified by device ID
+func (receiver *BulkDeviceUseCase) notify(devices []entity.SDevice) map[string]string {
+	notificationErrors := make(map[string]string, len(devices))
+	if len(devices) == 0 {
+		return notificationErrors
+	}
+
+	deviceIds := make([]string, 0, len(devices))
+	for _, device := range devices {
+		deviceIds = append(deviceIds, device.ID)
+		notificationErrors[device.ID] = "no FCM token registered for the device"
+	}
+	if receiver.FirebaseApp == nil {
+		for _, deviceId := range deviceIds {
+			notificationErrors[deviceId] = "FCM is not configured"
+		}
+		return notificationErrors
+	}
+
+	mobileDevices, err := receiver.MobileDeviceRepository.FindByDeviceIDs(deviceIds, receiver.DB)
+	if err != nil {
+		log.Error("BulkDeviceUseCase.notify ", err)
+		for _, deviceId := range deviceIds {
+			notificationErrors[deviceId] = err.Error()
+		}
+		return notificationErrors
+	}
+	tokens := make(map[string]string, len(mobileDevices))
+	for _, mobileDevice := range mobileDevices {
+		tokens[mobileDevice.DeviceId] = mobileDevice.FCMToken
+	}
+
+	params := make([]messaging.DataMessageParams, 0, len(mobileDevices))
+	targets := make([]string, 0, len(mobileDevices))
+	for _, device := range devices {
+		token, ok := tokens[device.ID]
+		if !ok {
+			continue
+		}
+		params = append(params, messaging.DataMessageParams{
+			DeviceToken: token,
+			Type:        value.NotificationType_DeviceStatusChanged,
+			Data: map[string]string{
+				"device_id":          device.ID,
+				"status":             string(device.Status),
+				"deactivate_message": device.DeactivateMessage,
+				"input_mode":         string(device.InputMode),
+				"screen_button_type": string(device.ScreenButtonType),
+			},
+		})
+		targets = append(targets, device.ID)
+	}
+
+	errs, err := messaging.SendDataMessages(receiver.FirebaseApp, params)
+	if err != nil {
+		log.Error("BulkDeviceUseCase.notify ", err)
+		for _, deviceId := range targets {
+			notificationErrors[deviceId] = err.Error()
+		}
+		return notificationErrors
+	}
+	for i, deviceId := range targets {
+		if errs[i] != nil {
+			notificationErrors[deviceId] = errs[i].Error()
+		} else {
+			delete(notificationErrors, deviceId)
+		}
+	}
+
+	return notificationErrors
+}
+
+// applyBulkDeviceRequest applies the fields of the request to a device and
+// reports whether anything changed
+func applyBulkDeviceRequest(device *entity.SDevice, req request.BulkUpdateDevicesRequest) bool {
+	changed := false
+	if req.Status != nil {
+		status, _ := value.GetDeviceModeFromString(*req.Status)
+		if device.Status != status {
+			device.Status = status
+			changed = true
+		}
+	}
+	if req.DeactivateMessage != nil && device.DeactivateMessage != *req.DeactivateMessage {
+		device.DeactivateMessage = *req.DeactivateMessage
+		changed = true
+	}
+	if req.InputMode != nil && device.InputMode != value.InfoInputType(*req.InputMode) {
+		device.InputMode = value.InfoInputType(*req.InputMode)
+		changed = true
+	}
+	if req.ScreenButtonType != nil && device.ScreenButtonType != value.ScreenButtonType(*req.ScreenButtonType) {
+		device.ScreenButtonType = value.ScreenButtonType(*req.ScreenButtonType)
+		changed = true
+	}
+	if req.DeviceComponentValuesId != nil && device.DeviceComponentValuesID != *req.DeviceComponentValuesId {
+		device.DeviceComponentValuesID = *req.DeviceComponentValuesId
+		changed = true
+	}
+
+	return changed
+}
@@ -6,6 +6,7 @@ import (
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
 )
 
 type GetCodeCountingsResult struct {
@@ -24,8 +25,18 @@ func GetCodeCountings(conn *gorm.DB, rq request.GetCodeCountingsRequest) (GetCod
 	}, err
 }
 
-func UpdateCodeCounting(conn *gorm.DB, rq request.UpdateCodeCountingRequest) error {
+// UpdateCodeCounting resets a counter of the organizations of the scope, the
+// shared counters are only reset with a scope over every organization
+func UpdateCodeCounting(conn *gorm.DB, scope value.AccessScope, rq request.UpdateCodeCountingRequest) error {
 	repo := repository.NewCodeCountingRepository()
 
+	codeCounting, err := repo.FindById(rq.ID, repository.ScopeAccess(conn, scope))
+	if err != nil {
+		return err
+	}
+	if !scope.Owns(codeCounting.OrganizationId) {
+		return ErrOutOfScope
+	}
+
 	return repo.UpdateCodeCounting(conn, rq)
 }
@@ -10,3 +10,5 @@ import (
 var AdminSpreadsheetClient *sheet.Spreadsheet
 var TheTimeMachine *job.TimeMachine = nil
 var ConsulClient *api.Client = nil
+var TheWebhookUseCase *WebhookUseCase = nil
+var TheToDoSheetSyncUseCase *ToDoSheetSyncUseCase = nil
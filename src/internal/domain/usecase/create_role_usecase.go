@@ -1,14 +1,22 @@
 package usecase
 
 import (
+	"errors"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
 )
 
+var ErrReservedRoleName = errors.New("role name " + value.SuperAdminRoleName + " is reserved")
+
 type CreateRoleUseCase struct {
 	*repository.RoleRepository
 }
 
 func (receiver *CreateRoleUseCase) Create(req request.CreateRoleRequest) error {
+	if value.IsReservedRoleName(req.RoleName) {
+		return ErrReservedRoleName
+	}
+
 	return receiver.CreateRole(req)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/pkg/uploader"
 )
 
@@ -12,12 +13,17 @@ type DeleteImageUseCase struct {
 	*repository.ImageRepository
 }
 
-func (receiver *DeleteImageUseCase) DeleteImage(key string) error {
+// DeleteImage deletes an image of the organizations of the scope, the shared
+// images are only deleted by a scope over every organization
+func (receiver *DeleteImageUseCase) DeleteImage(scope value.AccessScope, key string) error {
 	// Fetch image metadata from DB
-	imageData, err := receiver.ImageRepository.GetByKey(key)
+	imageData, err := receiver.ImageRepository.Scoped(scope).GetByKey(key)
 	if err != nil {
 		return fmt.Errorf("failed to get image by key: %w", err)
 	}
+	if !scope.Owns(imageData.OrganizationId) {
+		return ErrOutOfScope
+	}
 
 	// Delete from S3
 	err = receiver.UploadProvider.DeleteFileUploaded(context.Background(), imageData.Key)
@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/messaging"
+	"strings"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	deviceCommandDefaultExpiry = 24 * time.Hour
+	deviceCommandMaxExpiry     = 7 * 24 * time.Hour
+	deviceCommandResultLimit   = 65535
+)
+
+var (
+	ErrInvalidDeviceCommand = errors.New("invalid device command")
+	// ErrDeviceCommandClosed is returned for a command that is already done,
+	// cancelled or expired
+	ErrDeviceCommandClosed = errors.New("device command is no longer open")
+)
+
+// DeviceCommandUseCase queues commands for the devices. A queued command is
+// pushed through FCM right away and handed out by the pull endpoint to the
+// devices that missed the push, until they acknowledge it. The time machine
+// expires the commands left open past their expiry, see
+// ExecuteDeviceCommandExpiry.
+type DeviceCommandUseCase struct {
+	DeviceCommandRepository *repository.DeviceCommandRepository
+	DeviceRepository        *repository.DeviceRepository
+	MobileDeviceRepository  *repository.MobileDeviceRepository
+	FirebaseApp             *firebase.App
+	DB                      *gorm.DB
+}
+
+func NewDeviceCommandUseCase(db *gorm.DB, defaultRequestPageSize int, app *firebase.App) *DeviceCommandUseCase {
+	return &DeviceCommandUseCase{
+		DeviceCommandRepository: &repository.DeviceCommandRepository{DBConn: db, DefaultRequestPageSize: defaultRequestPageSize},
+		DeviceRepository:        &repository.DeviceRepository{DBConn: db, DefaultRequestPageSize: defaultRequestPageSize},
+		MobileDeviceRepository:  repository.NewMobileDeviceRepository(),
+		FirebaseApp:             app,
+		DB:                      db,
+	}
+}
+
+// IssueCommand queues a command for a device and pushes it in the background
+func (receiver *DeviceCommandUseCase) IssueCommand(deviceId string, req request.IssueDeviceCommandRequest, issuedBy string) (*entity.SDeviceCommand, error) {
+	commandType, err := value.GetDeviceCommandTypeFromString(req.Type)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDeviceCommand, err.Error())
+	}
+	payload, err := deviceCommandPayload(commandType, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := deviceCommandDefaultExpiry
+	if req.ExpiresInMinutes > 0 {
+		expiry = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	if expiry > deviceCommandMaxExpiry {
+		return nil, fmt.Errorf("%w: a command expires after %d minutes at most", ErrInvalidDeviceCommand, int(deviceCommandMaxExpiry.Minutes()))
+	}
+
+	_, err = receiver.DeviceRepository.FindDeviceById(deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	command := entity.SDeviceCommand{
+		ID:        uuid.NewString(),
+		DeviceId:  deviceId,
+		Type:      commandType,
+		Payload:   datatypes.JSON(payload),
+		Status:    value.DeviceCommandStatus_Pending,
+		IssuedBy:  issuedBy,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = receiver.DeviceCommandRepository.CreateCommand(&command)
+	if err != nil {
+		return nil, err
+	}
+
+	go receiver.push(command)
+
+	return &command, nil
+}
+
+func (receiver *DeviceCommandUseCase) GetCommand(id string) (*entity.SDeviceCommand, error) {
+	return receiver.DeviceCommandRepository.GetCommand(id)
+}
+
+func (receiver *DeviceCommandUseCase) GetCommands(deviceId string, req request.GetDeviceCommandsRequest) ([]entity.SDeviceCommand, *response.Pagination, error) {
+	status := ""
+	if req.Status != "" {
+		s, err := value.GetDeviceCommandStatusFromString(req.Status)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidDeviceCommand, err.Error())
+		}
+		status = string(s)
+	}
+
+	return receiver.DeviceCommandRepository.GetCommands(deviceId, status, req.Page, req.Limit)
+}
+
+// CancelCommand withdraws a command the device has not reported a result for
+func (receiver *DeviceCommandUseCase) CancelCommand(id string) (*entity.SDeviceCommand, error) {
+	now := time.Now()
+	return receiver.transition(id, "", []value.DeviceCommandStatus{value.DeviceCommandStatus_Pending, value.DeviceCommandStatus_Delivered, value.DeviceCommandStatus_Acknowledged}, now, map[string]interface{}{
+		"status":       value.DeviceCommandStatus_Cancelled,
+		"completed_at": now,
+	})
+}
+
+// PullCommands returns the commands a device still has to acknowledge, for the
+// devices the FCM push did not reach
+func (receiver *DeviceCommandUseCase) PullCommands(deviceId string) ([]entity.SDeviceCommand, error) {
+	return receiver.DeviceCommandRepository.TakeOpenCommands(deviceId, time.Now())
+}
+
+// AcknowledgeCommand records that the device received a command and starts
+// executing it
+func (receiver *DeviceCommandUseCase) AcknowledgeCommand(deviceId string, id string) (*entity.SDeviceCommand, error) {
+	now := time.Now()
+	return receiver.transition(id, deviceId, []value.DeviceCommandStatus{value.DeviceCommandStatus_Pending, value.DeviceCommandStatus_Delivered}, now, map[string]interface{}{
+		"status":          value.DeviceCommandStatus_Acknowledged,
+		"delivered_at":    gorm.Expr("COALESCE(delivered_at, ?)", now),
+		"acknowledged_at": now,
+	})
+}
+
+// ReportResult records whether the device executed a command. A change_mode
+// command that succeeded moves the device to its mode.
+func (receiver *DeviceCommandUseCase) ReportResult(deviceId string, id string, req request.DeviceCommandResultRequest) (*entity.SDeviceCommand, error) {
+	status, err := value.GetDeviceCommandStatusFromString(req.Status)
+	if err != nil || (status != value.DeviceCommandStatus_Succeeded && status != value.DeviceCommandStatus_Failed) {
+		return nil, fmt.Errorf("%w: the result is either succeeded or failed", ErrInvalidDeviceCommand)
+	}
+	result := req.Result
+	if len(result) > deviceCommandResultLimit {
+		result = result[:deviceCommandResultLimit]
+	}
+
+	now := time.Now()
+	command, err := receiver.transition(id, deviceId, []value.DeviceCommandStatus{value.DeviceCommandStatus_Pending, value.DeviceCommandStatus_Delivered, value.DeviceCommandStatus_Acknowledged}, now, map[string]interface{}{
+		"status":          status,
+		"delivered_at":    gorm.Expr("COALESCE(delivered_at, ?)", now),
+		"acknowledged_at": gorm.Expr("COALESCE(acknowledged_at, ?)", now),
+		"completed_at":    now,
+		"result":          result,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if command.Type == value.DeviceCommandType_ChangeMode && command.Status == value.DeviceCommandStatus_Succeeded {
+		receiver.applyMode(*command)
+	}
+
+	return command, nil
+}
+
+// ExecuteDeviceCommandExpiry expires the commands left open past their expiry
+func (receiver *DeviceCommandUseCase) ExecuteDeviceCommandExpiry() {
+	expired, err := receiver.DeviceCommandRepository.ExpireCommands(time.Now())
+	if err != nil {
+		log.Error("DeviceCommandUseCase.ExecuteDeviceCommandExpiry ", err)
+		return
+	}
+	if expired > 0 {
+		log.Info("DeviceCommandUseCase.ExecuteDeviceCommandExpiry expired ", expired, " commands")
+	}
+}
+
+// transition applies the updates to a command of the device that is still in
+// one of the statuses, deviceId is not checked when empty
+func (receiver *DeviceCommandUseCase) transition(id string, deviceId string, statuses []value.DeviceCommandStatus, now time.Time, updates map[string]interface{}) (*entity.SDeviceCommand, error) {
+	command, err := receiver.DeviceCommandRepository.GetCommand(id)
+	if err != nil {
+		return nil, err
+	}
+	if deviceId != "" && command.DeviceId != deviceId {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	updated, err := receiver.DeviceCommandRepository.UpdateOpenCommand(id, statuses, now, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrDeviceCommandClosed
+	}
+
+	return receiver.DeviceCommandRepository.GetCommand(id)
+}
+
+func (receiver *DeviceCommandUseCase) push(command entity.SDeviceCommand) {
+	if receiver.FirebaseApp == nil {
+		return
+	}
+
+	pushError := ""
+	var pushedAt *time.Time
+	mobileDevice, err := receiver.MobileDeviceRepository.FindByDeviceID(command.DeviceId, receiver.DB)
+	if err != nil {
+		pushError = "no FCM token registered for the device"
+	} else {
+		data := map[string]string{
+			"command_id":   command.ID,
+			"command_type": string(command.Type),
+		}
+		if len(command.Payload) > 0 {
+			data["payload"] = string(command.Payload)
+		}
+		err = messaging.SendDataMessage(receiver.FirebaseApp, messaging.DataMessageParams{
+			DeviceToken: mobileDevice.FCMToken,
+			Type:        value.NotificationType_DeviceCommand,
+			Data:        data,
+		})
+		if err != nil {
+			pushError = err.Error()
+		} else {
+			now := time.Now()
+			pushedAt = &now
+		}
+	}
+
+	err = receiver.DeviceCommandRepository.SavePushResult(command.ID, pushedAt, pushError)
+	if err != nil {
+		log.Error("DeviceCommandUseCase.push ", command.ID, " ", err)
+	}
+}
+
+func (receiver *DeviceCommandUseCase) applyMode(command entity.SDeviceCommand) {
+	var payload struct {
+		Mode string `json:"mode"`
+	}
+	err := json.Unmarshal(command.Payload, &payload)
+	if err != nil {
+		log.Error("DeviceCommandUseCase.applyMode ", command.ID, " ", err)
+		return
+	}
+
+	device, err := receiver.DeviceRepository.FindDeviceById(command.DeviceId)
+	if err != nil {
+		log.Error("DeviceCommandUseCase.applyMode ", command.ID, " ", err)
+		return
+	}
+	previousStatus := device.Status
+	if string(previousStatus) == payload.Mode {
+		return
+	}
+
+	err = receiver.DeviceRepository.UpdateDeviceMode(command.DeviceId, payload.Mode)
+	if err != nil {
+		log.Error("DeviceCommandUseCase.applyMode ", command.ID, " ", err)
+		return
+	}
+
+	PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+		"device_id":       command.DeviceId,
+		"status":          payload.Mode,
+		"previous_status": string(previousStatus),
+		"message":         device.DeactivateMessage,
+	})
+}
+
+// deviceCommandPayload checks the payload of a command and normalizes it
+func deviceCommandPayload(commandType value.DeviceCommandType, raw json.RawMessage) ([]byte, error) {
+	switch commandType {
+	case value.DeviceCommandType_ChangeMode:
+		var payload struct {
+			Mode string `json:"mode"`
+		}
+		if len(raw) == 0 || json.Unmarshal(raw, &payload) != nil {
+			return nil, fmt.Errorf("%w: change_mode takes {\"mode\": \"<mode>\"}", ErrInvalidDeviceCommand)
+		}
+		mode, err := value.GetDeviceModeFromString(payload.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDeviceCommand, err.Error())
+		}
+		return json.Marshal(map[string]string{"mode": string(mode)})
+	case value.DeviceCommandType_ShowMessage:
+		var payload struct {
+			Message string `json:"message"`
+		}
+		if len(raw) == 0 || json.Unmarshal(raw, &payload) != nil || strings.TrimSpace(payload.Message) == "" {
+			return nil, fmt.Errorf("%w: show_message takes {\"message\": \"<text>\"}", ErrInvalidDeviceCommand)
+		}
+		return json.Marshal(map[string]string{"message": payload.Message})
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("%w: the payload is not valid JSON", ErrInvalidDeviceCommand)
+	}
+
+	return raw, nil
+}
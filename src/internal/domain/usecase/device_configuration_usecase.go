@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidDeviceConfiguration = errors.New("invalid device configuration")
+
+type deviceConfigurationScope struct {
+	scope   value.DeviceConfigurationScope
+	scopeId string
+}
+
+// DeviceConfigurationUseCase resolves the configuration a device runs with from
+// the configuration set for the device, its group, its organization and
+// globally, in that order of precedence. A field no scope sets falls back to
+// the device itself: its status and its device component values. An
+// organization that saved device component values without naming them in its
+// configuration uses them at the organization level. The mode a device mode
+// schedule or override currently puts the device in comes before every scope.
+type DeviceConfigurationUseCase struct {
+	DeviceConfigurationRepository *repository.DeviceConfigurationRepository
+	DeviceGroupRepository         *repository.DeviceGroupRepository
+	DeviceScheduleRepository      *repository.DeviceScheduleRepository
+	DeviceRepository              *repository.DeviceRepository
+	DB                            *gorm.DB
+}
+
+func NewDeviceConfigurationUseCase(db *gorm.DB) *DeviceConfigurationUseCase {
+	return &DeviceConfigurationUseCase{
+		DeviceConfigurationRepository: &repository.DeviceConfigurationRepository{DBConn: db},
+		DeviceGroupRepository:         &repository.DeviceGroupRepository{DBConn: db},
+		DeviceScheduleRepository:      &repository.DeviceScheduleRepository{DBConn: db},
+		DeviceRepository:              &repository.DeviceRepository{DBConn: db},
+		DB:                            db,
+	}
+}
+
+// GetConfiguration returns the configuration set at a scope, with every field
+// null when nothing is set there
+func (receiver *DeviceConfigurationUseCase) GetConfiguration(scope value.DeviceConfigurationScope, scopeId string) (*response.DeviceConfigurationResponseData, error) {
+	err := receiver.validateScope(scope, scopeId)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := receiver.DeviceConfigurationRepository.GetConfiguration(scope, scopeId)
+	if err != nil {
+		return nil, err
+	}
+	if configuration == nil {
+		return &response.DeviceConfigurationResponseData{Scope: string(scope), ScopeId: scopeId}, nil
+	}
+
+	return toDeviceConfigurationResponse(*configuration), nil
+}
+
+// SaveConfiguration replaces the configuration of a scope, a request with every
+// field null clears it
+func (receiver *DeviceConfigurationUseCase) SaveConfiguration(scope value.DeviceConfigurationScope, scopeId string, req request.SaveDeviceConfigurationRequest) (*response.DeviceConfigurationResponseData, error) {
+	err := receiver.validateScope(scope, scopeId)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := entity.SDeviceConfiguration{
+		Scope:                   scope,
+		ScopeId:                 scopeId,
+		DeviceComponentValuesId: req.DeviceComponentValuesId,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
+	}
+	if req.Mode != nil {
+		mode, err := value.GetDeviceModeFromString(*req.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDeviceConfiguration, err.Error())
+		}
+		configuration.Mode = &mode
+	}
+	if req.FormIds != nil {
+		err = receiver.validateFormIds(req.FormIds)
+		if err != nil {
+			return nil, err
+		}
+		formIds, err := json.Marshal(req.FormIds)
+		if err != nil {
+			return nil, err
+		}
+		configuration.FormIds = datatypes.JSON(formIds)
+	}
+	if req.DeviceComponentValuesId != nil {
+		err = receiver.exists(&entity.SDeviceComponentValues{}, *req.DeviceComponentValuesId, "device component values")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if configuration.Mode == nil && configuration.FormIds == nil && configuration.DeviceComponentValuesId == nil {
+		err = receiver.DeviceConfigurationRepository.DeleteConfiguration(scope, scopeId)
+		if err != nil {
+			return nil, err
+		}
+		return &response.DeviceConfigurationResponseData{Scope: string(scope), ScopeId: scopeId}, nil
+	}
+
+	err = receiver.DeviceConfigurationRepository.SaveConfiguration(&configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GetConfiguration(scope, scopeId)
+}
+
+// ResolveConfiguration merges the scopes of a device into the configuration it
+// runs with and names the scope each field comes from
+func (receiver *DeviceConfigurationUseCase) ResolveConfiguration(deviceId string) (*response.EffectiveDeviceConfigurationResponseData, error) {
+	device, err := receiver.DeviceRepository.GetDeviceById(deviceId)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := receiver.DeviceGroupRepository.GetTags(device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationId, err := receiver.DeviceOrganizationId(*device)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []deviceConfigurationScope{{value.DeviceConfigurationScope_Device, device.ID}}
+	if device.GroupId != nil {
+		scopes = append(scopes, deviceConfigurationScope{value.DeviceConfigurationScope_Group, strconv.FormatUint(*device.GroupId, 10)})
+	}
+	if organizationId != nil {
+		scopes = append(scopes, deviceConfigurationScope{value.DeviceConfigurationScope_Organization, strconv.FormatInt(*organizationId, 10)})
+	}
+	scopes = append(scopes, deviceConfigurationScope{value.DeviceConfigurationScope_Global, ""})
+
+	data := &response.EffectiveDeviceConfigurationResponseData{
+		DeviceId:       device.ID,
+		OrganizationId: organizationId,
+		GroupId:        device.GroupId,
+		Tags:           tags,
+	}
+	var mode *value.DeviceMode
+	var formIds datatypes.JSON
+	var componentValuesId *int64
+	for _, scope := range scopes {
+		configuration, err := receiver.DeviceConfigurationRepository.GetConfiguration(scope.scope, scope.scopeId)
+		if err != nil {
+			return nil, err
+		}
+		if configuration != nil {
+			if mode == nil && configuration.Mode != nil {
+				mode = configuration.Mode
+				data.Sources.Mode = string(scope.scope)
+			}
+			if formIds == nil && configuration.FormIds != nil {
+				formIds = configuration.FormIds
+				data.Sources.FormIds = string(scope.scope)
+			}
+			if componentValuesId == nil && configuration.DeviceComponentValuesId != nil {
+				componentValuesId = configuration.DeviceComponentValuesId
+				data.Sources.DeviceComponentValues = string(scope.scope)
+			}
+		}
+
+		if scope.scope == value.DeviceConfigurationScope_Organization && componentValuesId == nil {
+			componentValues := make([]entity.SDeviceComponentValues, 0, 1)
+			err = receiver.DB.Where("organization_id = ?", *organizationId).Order("id ASC").Limit(1).Find(&componentValues).Error
+			if err != nil {
+				return nil, err
+			}
+			if len(componentValues) > 0 {
+				componentValuesId = &componentValues[0].ID
+				data.Sources.DeviceComponentValues = string(scope.scope)
+			}
+		}
+	}
+
+	scheduled, err := receiver.DeviceScheduleRepository.GetScheduledMode(device.ID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled != nil {
+		mode = &scheduled.Mode
+		data.Sources.Mode = string(scheduled.Source)
+	}
+
+	data.Mode = string(device.Status)
+	data.Sources.Mode = sourceOrDefault(data.Sources.Mode)
+	if mode != nil {
+		data.Mode = string(*mode)
+	}
+	data.Sources.FormIds = sourceOrDefault(data.Sources.FormIds)
+	if formIds != nil {
+		err = json.Unmarshal(formIds, &data.FormIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data.DeviceComponentValuesId = device.DeviceComponentValuesID
+	data.Sources.DeviceComponentValues = sourceOrDefault(data.Sources.DeviceComponentValues)
+	if componentValuesId != nil {
+		data.DeviceComponentValuesId = *componentValuesId
+	}
+
+	componentValues := make([]entity.SDeviceComponentValues, 0, 1)
+	err = receiver.DB.Where("id = ?", data.DeviceComponentValuesId).Limit(1).Find(&componentValues).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(componentValues) > 0 {
+		data.DeviceComponentValues = json.RawMessage(componentValues[0].Setting)
+	}
+
+	return data, nil
+}
+
+// DeviceOrganizationId returns the organization of the group of a device, or
+// else the organization it was enrolled in, or else the organization of its
+// users
+func (receiver *DeviceConfigurationUseCase) DeviceOrganizationId(device entity.SDevice) (*int64, error) {
+	if device.GroupId != nil {
+		group, err := receiver.DeviceGroupRepository.GetGroup(*device.GroupId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if group != nil && group.OrganizationId != nil {
+			return group.OrganizationId, nil
+		}
+	}
+	if device.OrganizationId != nil {
+		return device.OrganizationId, nil
+	}
+
+	return receiver.DeviceConfigurationRepository.GetDeviceOrganizationId(device.ID)
+}
+
+// EffectiveMode returns the mode a device runs with
+func (receiver *DeviceConfigurationUseCase) EffectiveMode(deviceId string) (value.DeviceMode, error) {
+	configuration, err := receiver.ResolveConfiguration(deviceId)
+	if err != nil {
+		return "", err
+	}
+
+	return value.DeviceMode(configuration.Mode), nil
+}
+
+func (receiver *DeviceConfigurationUseCase) validateScope(scope value.DeviceConfigurationScope, scopeId string) error {
+	switch scope {
+	case value.DeviceConfigurationScope_Device:
+		_, err := receiver.DeviceRepository.GetDeviceById(scopeId)
+		return err
+	case value.DeviceConfigurationScope_Group:
+		id, err := strconv.ParseUint(scopeId, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid group id %s", ErrInvalidDeviceConfiguration, scopeId)
+		}
+		_, err = receiver.DeviceGroupRepository.GetGroup(id)
+		return err
+	case value.DeviceConfigurationScope_Organization:
+		id, err := strconv.ParseInt(scopeId, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid organization id %s", ErrInvalidDeviceConfiguration, scopeId)
+		}
+		var organization entity.SOrganization
+		return receiver.DB.Select("id").Where("id = ?", id).First(&organization).Error
+	case value.DeviceConfigurationScope_Global:
+		if scopeId != "" {
+			return fmt.Errorf("%w: the global scope has no id", ErrInvalidDeviceConfiguration)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown scope %s", ErrInvalidDeviceConfiguration, scope)
+}
+
+func (receiver *DeviceConfigurationUseCase) validateFormIds(formIds []uint64) error {
+	if len(formIds) == 0 {
+		return nil
+	}
+
+	found := make([]uint64, 0, len(formIds))
+	err := receiver.DB.Model(&entity.SForm{}).Where("id IN ?", formIds).Pluck("id", &found).Error
+	if err != nil {
+		return err
+	}
+	exists := make(map[uint64]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	for _, id := range formIds {
+		if !exists[id] {
+			return fmt.Errorf("%w: no form %d", ErrInvalidDeviceConfiguration, id)
+		}
+	}
+
+	return nil
+}
+
+func (receiver *DeviceConfigurationUseCase) exists(model interface{}, id int64, name string) error {
+	var count int64
+	err := receiver.DB.Model(model).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no %s %d", ErrInvalidDeviceConfiguration, name, id)
+	}
+
+	return nil
+}
+
+// sourceOrDefault is the source of a field no scope set
+func sourceOrDefault(source string) string {
+	if source == "" {
+		return string(value.DeviceConfigurationScope_Default)
+	}
+
+	return source
+}
+
+func toDeviceConfigurationResponse(configuration entity.SDeviceConfiguration) *response.DeviceConfigurationResponseData {
+	data := &response.DeviceConfigurationResponseData{
+		Scope:                   string(configuration.Scope),
+		ScopeId:                 configuration.ScopeId,
+		DeviceComponentValuesId: configuration.DeviceComponentValuesId,
+		UpdatedAt:               &configuration.UpdatedAt,
+	}
+	if configuration.Mode != nil {
+		mode := string(*configuration.Mode)
+		data.Mode = &mode
+	}
+	if configuration.FormIds != nil {
+		_ = json.Unmarshal(configuration.FormIds, &data.FormIds)
+	}
+
+	return data
+}
@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/randx"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	deviceEnrollmentCodeLength      = 16
+	deviceEnrollmentCodeLifetime    = 72 * time.Hour
+	deviceEnrollmentCodeMaxLifetime = 90 * 24 * time.Hour
+	deviceEnrollmentCodeMaxUses     = 1000
+	// DeviceEnrollmentQrPrefix starts the payload of the QR code of an
+	// enrollment code, the code is its code query parameter
+	DeviceEnrollmentQrPrefix = "senbox://enroll"
+)
+
+var (
+	ErrInvalidEnrollmentCode     = errors.New("invalid enrollment code")
+	ErrEnrollmentCodeUnavailable = errors.New("the enrollment code is unknown, revoked, expired or used up")
+	ErrDeviceEnrolledElsewhere   = errors.New("the device belongs to another organization")
+)
+
+// enrollmentCodeRunes leaves out the letters and digits that are easily mixed up
+// when a code is typed in
+var enrollmentCodeRunes = []rune("ABCDEFGHJKLMNPQRSTUVWXYZ23456789")
+
+// DeviceEnrollmentUseCase issues the enrollment codes of the organizations and
+// enrolls the devices that present them. An enrolled device is bound to the
+// organization and the group of the code and signs in as the user of the code,
+// without anyone logging in on it.
+type DeviceEnrollmentUseCase struct {
+	DeviceEnrollmentRepository *repository.DeviceEnrollmentRepository
+	DeviceGroupRepository      *repository.DeviceGroupRepository
+	OrganizationRepository     *repository.OrganizationRepository
+	UserEntityRepository       *repository.UserEntityRepository
+	SessionRepository          *repository.SessionRepository
+	DeviceConfigurationUseCase *DeviceConfigurationUseCase
+	DB                         *gorm.DB
+}
+
+func NewDeviceEnrollmentUseCase(db *gorm.DB, sessionRepository *repository.SessionRepository) *DeviceEnrollmentUseCase {
+	return &DeviceEnrollmentUseCase{
+		DeviceEnrollmentRepository: &repository.DeviceEnrollmentRepository{DBConn: db},
+		DeviceGroupRepository:      &repository.DeviceGroupRepository{DBConn: db},
+		OrganizationRepository:     &repository.OrganizationRepository{DBConn: db},
+		UserEntityRepository:       &repository.UserEntityRepository{DBConn: db},
+		SessionRepository:          sessionRepository,
+		DeviceConfigurationUseCase: NewDeviceConfigurationUseCase(db),
+		DB:                         db,
+	}
+}
+
+// CreateCode issues an enrollment code and returns it with the code in clear,
+// which is not kept and cannot be shown again
+func (receiver *DeviceEnrollmentUseCase) CreateCode(req request.CreateDeviceEnrollmentCodeRequest, createdBy string) (*entity.SDeviceEnrollmentCode, string, error) {
+	now := time.Now()
+	code := entity.SDeviceEnrollmentCode{
+		Name:           strings.TrimSpace(req.Name),
+		OrganizationId: req.OrganizationId,
+		GroupId:        req.GroupId,
+		UserId:         strings.TrimSpace(req.UserId),
+		MaxUses:        req.MaxUses,
+		ExpiresAt:      now.Add(deviceEnrollmentCodeLifetime),
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if code.UserId == "" {
+		code.UserId = createdBy
+	}
+	if code.MaxUses == 0 {
+		code.MaxUses = 1
+	}
+	if code.MaxUses > deviceEnrollmentCodeMaxUses {
+		return nil, "", fmt.Errorf("%w: max_uses is %d at most", ErrInvalidEnrollmentCode, deviceEnrollmentCodeMaxUses)
+	}
+	if req.ExpiresAt != nil {
+		code.ExpiresAt = *req.ExpiresAt
+	}
+	if !code.ExpiresAt.After(now) || code.ExpiresAt.Sub(now) > deviceEnrollmentCodeMaxLifetime {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the next %d days", ErrInvalidEnrollmentCode, int(deviceEnrollmentCodeMaxLifetime.Hours()/24))
+	}
+	if req.Mode != nil {
+		mode, err := value.GetDeviceModeFromString(*req.Mode)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidEnrollmentCode, err.Error())
+		}
+		code.Mode = &mode
+	}
+
+	_, err := receiver.OrganizationRepository.GetByID(uint(req.OrganizationId))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("%w: organization %d not found", ErrInvalidEnrollmentCode, req.OrganizationId)
+		}
+		return nil, "", err
+	}
+	if code.GroupId != nil {
+		group, err := receiver.DeviceGroupRepository.GetGroup(*code.GroupId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "", fmt.Errorf("%w: device group %d not found", ErrInvalidEnrollmentCode, *code.GroupId)
+			}
+			return nil, "", err
+		}
+		if group.OrganizationId != nil && *group.OrganizationId != code.OrganizationId {
+			return nil, "", fmt.Errorf("%w: the device group belongs to another organization", ErrInvalidEnrollmentCode)
+		}
+	}
+	if code.UserId == "" {
+		return nil, "", fmt.Errorf("%w: user_id is required", ErrInvalidEnrollmentCode)
+	}
+	_, err = receiver.UserEntityRepository.GetByID(request.GetUserEntityByIdRequest{ID: code.UserId})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("%w: user %s not found", ErrInvalidEnrollmentCode, code.UserId)
+		}
+		return nil, "", err
+	}
+
+	secret := randx.MustString(deviceEnrollmentCodeLength, enrollmentCodeRunes)
+	code.CodeHash = hashEnrollmentCode(secret)
+	code.CodeHint = secret[len(secret)-4:]
+	err = receiver.DeviceEnrollmentRepository.CreateCode(&code)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &code, formatEnrollmentCode(secret), nil
+}
+
+func (receiver *DeviceEnrollmentUseCase) GetCode(id uint64) (*entity.SDeviceEnrollmentCode, error) {
+	return receiver.DeviceEnrollmentRepository.GetCode(id)
+}
+
+func (receiver *DeviceEnrollmentUseCase) GetCodes(req request.GetDeviceEnrollmentCodesRequest) ([]entity.SDeviceEnrollmentCode, error) {
+	return receiver.DeviceEnrollmentRepository.GetCodes(req.OrganizationId, req.Active)
+}
+
+func (receiver *DeviceEnrollmentUseCase) RevokeCode(id uint64) (*entity.SDeviceEnrollmentCode, error) {
+	err := receiver.DeviceEnrollmentRepository.RevokeCode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.DeviceEnrollmentRepository.GetCode(id)
+}
+
+func (receiver *DeviceEnrollmentUseCase) GetEnrollments(codeId uint64) ([]entity.SDeviceEnrollment, error) {
+	_, err := receiver.DeviceEnrollmentRepository.GetCode(codeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.DeviceEnrollmentRepository.GetEnrollments(codeId)
+}
+
+// Enroll takes one use of the enrollment code, registers the device when it is
+// new and binds it to the organization, the group and the mode of the code. It
+// starts a session of the user of the code on the device and returns it with the
+// configuration the device runs with. A device of another organization has to
+// be taken out of it before it can be enrolled.
+func (receiver *DeviceEnrollmentUseCase) Enroll(req request.EnrollDeviceRequest, userAgent string, ipAddress string) (*response.EnrolledDeviceResponseData, error) {
+	secret := parseEnrollmentCode(req.Code)
+	if len(secret) != deviceEnrollmentCodeLength {
+		return nil, fmt.Errorf("%w: malformed code", ErrInvalidEnrollmentCode)
+	}
+	deviceId := strings.TrimSpace(req.DeviceUUID)
+	if deviceId == "" || len(deviceId) > 36 {
+		return nil, fmt.Errorf("%w: invalid device_uuid", ErrInvalidEnrollmentCode)
+	}
+
+	var code *entity.SDeviceEnrollmentCode
+	var user *entity.SUserEntity
+	var device *entity.SDevice
+	err := receiver.DB.Transaction(func(tx *gorm.DB) error {
+		enrollmentRepository := &repository.DeviceEnrollmentRepository{DBConn: tx}
+		deviceRepository := &repository.DeviceRepository{DBConn: tx}
+
+		var err error
+		code, err = enrollmentRepository.RedeemCode(hashEnrollmentCode(secret))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEnrollmentCodeUnavailable
+			}
+			return err
+		}
+		user, err = (&repository.UserEntityRepository{DBConn: tx}).GetByID(request.GetUserEntityByIdRequest{ID: code.UserId})
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEnrollmentCodeUnavailable
+			}
+			return err
+		}
+
+		device, err = deviceRepository.GetDeviceById(deviceId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if device == nil {
+			device, err = deviceRepository.CreateDevice(request.RegisterDeviceRequest{
+				UserID:     code.UserId,
+				DeviceUUID: deviceId,
+				InputMode:  req.InputMode,
+				AppVersion: req.AppVersion,
+			})
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrInvalidEnrollmentCode, err.Error())
+			}
+		} else {
+			organizationId, err := (&DeviceConfigurationUseCase{
+				DeviceConfigurationRepository: &repository.DeviceConfigurationRepository{DBConn: tx},
+				DeviceGroupRepository:         &repository.DeviceGroupRepository{DBConn: tx},
+			}).DeviceOrganizationId(*device)
+			if err != nil {
+				return err
+			}
+			if organizationId != nil && *organizationId != code.OrganizationId {
+				return ErrDeviceEnrolledElsewhere
+			}
+			if req.AppVersion != "" {
+				device.AppVersion = req.AppVersion
+				err = tx.Model(device).Update("app_version", req.AppVersion).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = enrollmentRepository.EnrollDevice(device, *code, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return enrollmentRepository.CreateEnrollment(&entity.SDeviceEnrollment{
+			CodeId:     code.ID,
+			DeviceId:   device.ID,
+			AppVersion: device.AppVersion,
+			IpAddress:  ipAddress,
+			EnrolledAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := StartSession(receiver.SessionRepository, *user, device.ID, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	configuration, err := receiver.DeviceConfigurationUseCase.ResolveConfiguration(device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.EnrolledDeviceResponseData{
+		DeviceId:       device.ID,
+		OrganizationId: code.OrganizationId,
+		GroupId:        configuration.GroupId,
+		Session:        *session,
+		Configuration:  *configuration,
+	}, nil
+}
+
+// DeviceEnrollmentQrPayload returns what the QR code of an enrollment code
+// encodes
+func DeviceEnrollmentQrPayload(code string) string {
+	return DeviceEnrollmentQrPrefix + "?" + url.Values{"code": {code}}.Encode()
+}
+
+// parseEnrollmentCode returns the code of a QR code payload or of a code typed
+// in, without its separators
+func parseEnrollmentCode(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.HasPrefix(code, DeviceEnrollmentQrPrefix) {
+		payload, err := url.Parse(code)
+		if err != nil {
+			return ""
+		}
+		code = payload.Query().Get("code")
+	}
+
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// formatEnrollmentCode splits a code in groups of four to be read out and typed in
+func formatEnrollmentCode(code string) string {
+	groups := make([]string, 0, len(code)/4+1)
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+
+	return strings.Join(append(groups, code), "-")
+}
+
+func hashEnrollmentCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(hash[:])
+}
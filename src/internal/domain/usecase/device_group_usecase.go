@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	deviceGroupDevicesLimit = 1000
+	deviceTagsLimit         = 32
+	deviceTagLength         = 64
+)
+
+var ErrInvalidDeviceGroup = errors.New("invalid device group")
+
+// DeviceGroupUseCase manages the device groups of the organizations and the tags
+// of the devices. A device is in one group at most.
+type DeviceGroupUseCase struct {
+	DeviceGroupRepository *repository.DeviceGroupRepository
+	DeviceRepository      *repository.DeviceRepository
+	DB                    *gorm.DB
+}
+
+func NewDeviceGroupUseCase(db *gorm.DB) *DeviceGroupUseCase {
+	return &DeviceGroupUseCase{
+		DeviceGroupRepository: &repository.DeviceGroupRepository{DBConn: db},
+		DeviceRepository:      &repository.DeviceRepository{DBConn: db},
+		DB:                    db,
+	}
+}
+
+func (receiver *DeviceGroupUseCase) GetGroups(req request.GetDeviceGroupsRequest) ([]response.DeviceGroupResponseData, error) {
+	groups, err := receiver.DeviceGroupRepository.GetGroups(req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.toResponses(groups)
+}
+
+func (receiver *DeviceGroupUseCase) GetGroup(id uint64) (*response.DeviceGroupResponseData, error) {
+	group, err := receiver.DeviceGroupRepository.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := receiver.toResponses([]entity.SDeviceGroup{*group})
+	if err != nil {
+		return nil, err
+	}
+
+	return &data[0], nil
+}
+
+func (receiver *DeviceGroupUseCase) CreateGroup(req request.SaveDeviceGroupRequest) (*response.DeviceGroupResponseData, error) {
+	group := entity.SDeviceGroup{}
+	err := receiver.apply(&group, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.DeviceGroupRepository.CreateGroup(&group)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GetGroup(group.ID)
+}
+
+func (receiver *DeviceGroupUseCase) UpdateGroup(id uint64, req request.SaveDeviceGroupRequest) (*response.DeviceGroupResponseData, error) {
+	group, err := receiver.DeviceGroupRepository.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.apply(group, req)
+	if err != nil {
+		return nil, err
+	}
+	group.UpdatedAt = time.Now()
+
+	err = receiver.DeviceGroupRepository.UpdateGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GetGroup(group.ID)
+}
+
+// DeleteGroup deletes a group with its configuration, schedules and overrides,
+// its devices are left without a group
+func (receiver *DeviceGroupUseCase) DeleteGroup(id uint64) error {
+	return receiver.DeviceGroupRepository.DeleteGroup(id)
+}
+
+func (receiver *DeviceGroupUseCase) GetGroupDevices(id uint64) (*response.DeviceGroupDevicesResponseData, error) {
+	_, err := receiver.DeviceGroupRepository.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceIds, err := receiver.DeviceGroupRepository.GetGroupDeviceIds(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.DeviceGroupDevicesResponseData{GroupId: id, DeviceIds: deviceIds}, nil
+}
+
+// AddDevices moves the devices to a group, out of the group they were in
+func (receiver *DeviceGroupUseCase) AddDevices(id uint64, req request.DeviceGroupDevicesRequest) (*response.DeviceGroupDevicesResponseData, error) {
+	_, err := receiver.DeviceGroupRepository.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	err = receiver.validateDeviceIds(req.DeviceIds)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = receiver.DeviceGroupRepository.SetDevicesGroup(req.DeviceIds, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GetGroupDevices(id)
+}
+
+// RemoveDevices takes the devices out of a group, the devices of other groups are left alone
+func (receiver *DeviceGroupUseCase) RemoveDevices(id uint64, req request.DeviceGroupDevicesRequest) (*response.DeviceGroupDevicesResponseData, error) {
+	members, err := receiver.GetGroupDevices(id)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := make(map[string]bool, len(members.DeviceIds))
+	for _, deviceId := range members.DeviceIds {
+		isMember[deviceId] = true
+	}
+	deviceIds := make([]string, 0, len(req.DeviceIds))
+	for _, deviceId := range req.DeviceIds {
+		if isMember[deviceId] {
+			deviceIds = append(deviceIds, deviceId)
+		}
+	}
+	if len(deviceIds) > 0 {
+		_, err = receiver.DeviceGroupRepository.SetDevicesGroup(deviceIds, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return receiver.GetGroupDevices(id)
+}
+
+func (receiver *DeviceGroupUseCase) GetDeviceTags(deviceId string) (*response.DeviceTagsResponseData, error) {
+	_, err := receiver.DeviceRepository.GetDeviceById(deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := receiver.DeviceGroupRepository.GetTags(deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.DeviceTagsResponseData{DeviceId: deviceId, Tags: tags}, nil
+}
+
+// ReplaceDeviceTags sets the tags of a device. Tags are trimmed and lower cased
+// so that "Front Desk" and "front desk" are the same tag.
+func (receiver *DeviceGroupUseCase) ReplaceDeviceTags(deviceId string, req request.ReplaceDeviceTagsRequest) (*response.DeviceTagsResponseData, error) {
+	_, err := receiver.DeviceRepository.GetDeviceById(deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > deviceTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidDeviceGroup, tag, deviceTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > deviceTagsLimit {
+		return nil, fmt.Errorf("%w: at most %d tags per device", ErrInvalidDeviceGroup, deviceTagsLimit)
+	}
+
+	err = receiver.DeviceGroupRepository.ReplaceTags(deviceId, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GetDeviceTags(deviceId)
+}
+
+// GetTags lists every tag in use with its number of devices
+func (receiver *DeviceGroupUseCase) GetTags() ([]response.DeviceTagCount, error) {
+	counts, err := receiver.DeviceGroupRepository.GetTagCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]response.DeviceTagCount, 0, len(counts))
+	for tag, devices := range counts {
+		tags = append(tags, response.DeviceTagCount{Tag: tag, Devices: devices})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags, nil
+}
+
+func (receiver *DeviceGroupUseCase) apply(group *entity.SDeviceGroup, req request.SaveDeviceGroupRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDeviceGroup)
+	}
+	if req.OrganizationId != nil {
+		var count int64
+		err := receiver.DB.Model(&entity.SOrganization{}).Where("id = ?", *req.OrganizationId).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: no organization %d", ErrInvalidDeviceGroup, *req.OrganizationId)
+		}
+	}
+
+	group.Name = name
+	group.Description = strings.TrimSpace(req.Description)
+	group.OrganizationId = req.OrganizationId
+
+	return nil
+}
+
+func (receiver *DeviceGroupUseCase) validateDeviceIds(deviceIds []string) error {
+	if len(deviceIds) == 0 {
+		return fmt.Errorf("%w: device_ids is empty", ErrInvalidDeviceGroup)
+	}
+	if len(deviceIds) > deviceGroupDevicesLimit {
+		return fmt.Errorf("%w: at most %d device_ids", ErrInvalidDeviceGroup, deviceGroupDevicesLimit)
+	}
+
+	devices, err := receiver.DeviceRepository.FindDevicesByFilter(request.BulkDeviceFilter{DeviceIds: deviceIds}, nil, len(deviceIds))
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		found[device.ID] = true
+	}
+	for _, deviceId := range deviceIds {
+		if !found[deviceId] {
+			return fmt.Errorf("%w: no device %s", ErrInvalidDeviceGroup, deviceId)
+		}
+	}
+
+	return nil
+}
+
+func (receiver *DeviceGroupUseCase) toResponses(groups []entity.SDeviceGroup) ([]response.DeviceGroupResponseData, error) {
+	groupIds := make([]uint64, 0, len(groups))
+	for _, group := range groups {
+		groupIds = append(groupIds, group.ID)
+	}
+	counts, err := receiver.DeviceGroupRepository.CountDevices(groupIds)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]response.DeviceGroupResponseData, 0, len(groups))
+	for _, group := range groups {
+		data = append(data, response.DeviceGroupResponseData{
+			Id:             group.ID,
+			OrganizationId: group.OrganizationId,
+			Name:           group.Name,
+			Description:    group.Description,
+			Devices:        counts[group.ID],
+			CreatedAt:      group.CreatedAt,
+			UpdatedAt:      group.UpdatedAt,
+		})
+	}
+
+	return data, nil
+}
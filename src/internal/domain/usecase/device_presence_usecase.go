@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/config"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/monitor"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	deviceHeartbeatPageSize    = 100
+	deviceHeartbeatMaxPageSize = 1000
+	// deviceOfflineAlertLimit is the number of devices named in one alert
+	deviceOfflineAlertLimit = 50
+)
+
+// DevicePresenceUseCase records the heartbeats of the devices and reports the
+// devices that stopped sending them through the monitor channel. It is run by
+// the time machine, see ExecuteDevicePresenceCheck.
+type DevicePresenceUseCase struct {
+	DeviceHeartbeatRepository *repository.DeviceHeartbeatRepository
+	PresenceThresholds        value.DevicePresenceThresholds
+	AlertAfter                time.Duration
+	HeartbeatRetention        time.Duration
+}
+
+func NewDevicePresenceUseCase(db *gorm.DB, cfg config.DevicePresenceConfig) *DevicePresenceUseCase {
+	return &DevicePresenceUseCase{
+		DeviceHeartbeatRepository: &repository.DeviceHeartbeatRepository{DBConn: db},
+		PresenceThresholds:        DevicePresenceThresholds(cfg),
+		AlertAfter:                time.Duration(cfg.AlertAfterMinutes) * time.Minute,
+		HeartbeatRetention:        time.Duration(cfg.HeartbeatRetentionDays) * 24 * time.Hour,
+	}
+}
+
+func DevicePresenceThresholds(cfg config.DevicePresenceConfig) value.DevicePresenceThresholds {
+	return value.DevicePresenceThresholds{
+		StaleAfter:   time.Duration(cfg.StaleAfterMinutes) * time.Minute,
+		OfflineAfter: time.Duration(cfg.OfflineAfterMinutes) * time.Minute,
+	}
+}
+
+// RecordHeartbeat stores a heartbeat of the device, ipAddress is used when the
+// device does not report its own address
+func (receiver *DevicePresenceUseCase) RecordHeartbeat(req request.DeviceHeartbeatRequest, ipAddress string) (*entity.SDeviceHeartbeat, error) {
+	heartbeat := entity.SDeviceHeartbeat{
+		DeviceId:         req.DeviceId,
+		BatteryLevel:     req.BatteryLevel,
+		IsCharging:       req.IsCharging,
+		AppVersion:       strings.TrimSpace(req.AppVersion),
+		IpAddress:        strings.TrimSpace(req.IpAddress),
+		CurrentFormId:    req.CurrentFormId,
+		FreeStorageBytes: req.FreeStorageBytes,
+		CreatedAt:        time.Now(),
+	}
+	if heartbeat.IpAddress == "" {
+		heartbeat.IpAddress = ipAddress
+	}
+
+	offlineAlertedAt, err := receiver.DeviceHeartbeatRepository.CreateHeartbeat(&heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	if offlineAlertedAt != nil {
+		monitor.SendMessageViaTelegram(fmt.Sprintf("Device %s is back online after being reported offline at %s", heartbeat.DeviceId, offlineAlertedAt.Format(time.RFC3339)))
+	}
+
+	return &heartbeat, nil
+}
+
+func (receiver *DevicePresenceUseCase) GetHeartbeats(deviceId string, req request.GetDeviceHeartbeatsRequest) ([]response.DeviceHeartbeatResponseData, error) {
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, errors.New("from must be before to")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = deviceHeartbeatPageSize
+	}
+	if limit > deviceHeartbeatMaxPageSize {
+		limit = deviceHeartbeatMaxPageSize
+	}
+	var from, to *time.Time
+	if !req.From.IsZero() {
+		from = &req.From
+	}
+	if !req.To.IsZero() {
+		to = &req.To
+	}
+
+	heartbeats, err := receiver.DeviceHeartbeatRepository.GetHeartbeats(deviceId, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]response.DeviceHeartbeatResponseData, 0, len(heartbeats))
+	for _, heartbeat := range heartbeats {
+		data = append(data, toDeviceHeartbeatResponse(heartbeat))
+	}
+
+	return data, nil
+}
+
+// ExecuteDevicePresenceCheck alerts once about every device silent for longer
+// than AlertAfter, a device is alerted about again after it came back online.
+// It also drops the heartbeats older than HeartbeatRetention.
+func (receiver *DevicePresenceUseCase) ExecuteDevicePresenceCheck() {
+	now := time.Now()
+	if receiver.AlertAfter > 0 {
+		receiver.alertSilentDevices(now)
+	}
+
+	if receiver.HeartbeatRetention > 0 {
+		deleted, err := receiver.DeviceHeartbeatRepository.DeleteHeartbeatsBefore(now.Add(-receiver.HeartbeatRetention))
+		if err != nil {
+			log.Error("DevicePresenceUseCase.ExecuteDevicePresenceCheck ", err)
+		} else if deleted > 0 {
+			log.Debug("Deleted ", deleted, " device heartbeats")
+		}
+	}
+}
+
+func (receiver *DevicePresenceUseCase) alertSilentDevices(now time.Time) {
+	devices, err := receiver.DeviceHeartbeatRepository.GetSilentDevices(now.Add(-receiver.AlertAfter))
+	if err != nil {
+		log.Error("DevicePresenceUseCase.alertSilentDevices ", err)
+		return
+	}
+	if len(devices) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, deviceOfflineAlertLimit+2)
+	lines = append(lines, fmt.Sprintf("%d device(s) sent no heartbeat for %s:", len(devices), receiver.AlertAfter))
+	deviceIds := make([]string, 0, len(devices))
+	for i, device := range devices {
+		deviceIds = append(deviceIds, device.ID)
+		if i < deviceOfflineAlertLimit {
+			lines = append(lines, fmt.Sprintf("- %s %s, last heartbeat %s", device.ID, device.DeviceName, device.LastHeartbeatAt.Format(time.RFC3339)))
+		}
+	}
+	if len(devices) > deviceOfflineAlertLimit {
+		lines = append(lines, fmt.Sprintf("and %d more", len(devices)-deviceOfflineAlertLimit))
+	}
+
+	err = receiver.DeviceHeartbeatRepository.MarkOfflineAlerted(deviceIds, now)
+	if err != nil {
+		log.Error("DevicePresenceUseCase.alertSilentDevices ", err)
+		return
+	}
+	monitor.SendMessageViaTelegram(lines...)
+}
+
+func toDeviceHeartbeatResponse(heartbeat entity.SDeviceHeartbeat) response.DeviceHeartbeatResponseData {
+	return response.DeviceHeartbeatResponseData{
+		BatteryLevel:     heartbeat.BatteryLevel,
+		IsCharging:       heartbeat.IsCharging,
+		AppVersion:       heartbeat.AppVersion,
+		IpAddress:        heartbeat.IpAddress,
+		CurrentFormId:    heartbeat.CurrentFormId,
+		FreeStorageBytes: heartbeat.FreeStorageBytes,
+		CreatedAt:        heartbeat.CreatedAt,
+	}
+}
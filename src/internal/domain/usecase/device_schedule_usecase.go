@@ -0,0 +1,580 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/messaging"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// deviceModeOverrideMaxDuration is how long a one-off override lasts at most
+const deviceModeOverrideMaxDuration = 31 * 24 * time.Hour
+
+var ErrInvalidDeviceSchedule = errors.New("invalid device schedule")
+
+// DeviceScheduleUseCase manages the device mode schedules and overrides and, on
+// every run of ExecuteDeviceSchedules, records the mode they put each device in.
+// For a device the overrides come before the schedules and the device scope
+// before the group scope, the most recent one wins within a scope. A device
+// whose mode changes is notified through FCM.
+type DeviceScheduleUseCase struct {
+	DeviceScheduleRepository   *repository.DeviceScheduleRepository
+	DeviceGroupRepository      *repository.DeviceGroupRepository
+	DeviceRepository           *repository.DeviceRepository
+	MobileDeviceRepository     *repository.MobileDeviceRepository
+	DeviceConfigurationUseCase *DeviceConfigurationUseCase
+	FirebaseApp                *firebase.App
+	DB                         *gorm.DB
+	executing                  sync.Mutex
+}
+
+func NewDeviceScheduleUseCase(db *gorm.DB, app *firebase.App) *DeviceScheduleUseCase {
+	return &DeviceScheduleUseCase{
+		DeviceScheduleRepository:   &repository.DeviceScheduleRepository{DBConn: db},
+		DeviceGroupRepository:      &repository.DeviceGroupRepository{DBConn: db},
+		DeviceRepository:           &repository.DeviceRepository{DBConn: db},
+		MobileDeviceRepository:     repository.NewMobileDeviceRepository(),
+		DeviceConfigurationUseCase: NewDeviceConfigurationUseCase(db),
+		FirebaseApp:                app,
+		DB:                         db,
+	}
+}
+
+func (receiver *DeviceScheduleUseCase) GetSchedules(req request.GetDeviceModeSchedulesRequest) ([]entity.SDeviceModeSchedule, error) {
+	return receiver.DeviceScheduleRepository.GetSchedules(value.DeviceConfigurationScope(req.Scope), req.ScopeId)
+}
+
+func (receiver *DeviceScheduleUseCase) GetSchedule(id uint64) (*entity.SDeviceModeSchedule, error) {
+	return receiver.DeviceScheduleRepository.GetSchedule(id)
+}
+
+func (receiver *DeviceScheduleUseCase) CreateSchedule(req request.SaveDeviceModeScheduleRequest, createdBy string) (*entity.SDeviceModeSchedule, error) {
+	schedule := entity.SDeviceModeSchedule{CreatedBy: createdBy}
+	err := receiver.apply(&schedule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.DeviceScheduleRepository.CreateSchedule(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	go receiver.ExecuteDeviceSchedules()
+
+	return &schedule, nil
+}
+
+func (receiver *DeviceScheduleUseCase) UpdateSchedule(id uint64, req request.SaveDeviceModeScheduleRequest) (*entity.SDeviceModeSchedule, error) {
+	schedule, err := receiver.DeviceScheduleRepository.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.apply(schedule, req)
+	if err != nil {
+		return nil, err
+	}
+	schedule.UpdatedAt = time.Now()
+
+	err = receiver.DeviceScheduleRepository.UpdateSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	go receiver.ExecuteDeviceSchedules()
+
+	return schedule, nil
+}
+
+func (receiver *DeviceScheduleUseCase) DeleteSchedule(id uint64) error {
+	err := receiver.DeviceScheduleRepository.DeleteSchedule(id)
+	if err != nil {
+		return err
+	}
+	go receiver.ExecuteDeviceSchedules()
+
+	return nil
+}
+
+func (receiver *DeviceScheduleUseCase) GetOverrides(req request.GetDeviceModeOverridesRequest) ([]entity.SDeviceModeOverride, error) {
+	var now *time.Time
+	if req.Upcoming {
+		at := time.Now()
+		now = &at
+	}
+
+	return receiver.DeviceScheduleRepository.GetOverrides(value.DeviceConfigurationScope(req.Scope), req.ScopeId, now)
+}
+
+// CreateOverride makes a one-off exception to the schedules, it applies right away
+// when it starts now
+func (receiver *DeviceScheduleUseCase) CreateOverride(req request.CreateDeviceModeOverrideRequest, createdBy string) (*entity.SDeviceModeOverride, error) {
+	scope, err := receiver.validateScope(req.Scope, req.ScopeId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	override := entity.SDeviceModeOverride{
+		Scope:     scope,
+		ScopeId:   req.ScopeId,
+		StartsAt:  now,
+		EndsAt:    req.EndsAt,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if req.StartsAt != nil {
+		override.StartsAt = *req.StartsAt
+	}
+	if req.Mode != nil {
+		mode, err := value.GetDeviceModeFromString(*req.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDeviceSchedule, err.Error())
+		}
+		override.Mode = &mode
+	}
+	if !override.EndsAt.After(override.StartsAt) || !override.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at and in the future", ErrInvalidDeviceSchedule)
+	}
+	if override.EndsAt.Sub(override.StartsAt) > deviceModeOverrideMaxDuration {
+		return nil, fmt.Errorf("%w: an override lasts %d days at most", ErrInvalidDeviceSchedule, int(deviceModeOverrideMaxDuration.Hours()/24))
+	}
+
+	err = receiver.DeviceScheduleRepository.CreateOverride(&override)
+	if err != nil {
+		return nil, err
+	}
+	go receiver.ExecuteDeviceSchedules()
+
+	return &override, nil
+}
+
+func (receiver *DeviceScheduleUseCase) GetOverride(id uint64) (*entity.SDeviceModeOverride, error) {
+	return receiver.DeviceScheduleRepository.GetOverride(id)
+}
+
+func (receiver *DeviceScheduleUseCase) DeleteOverride(id uint64) error {
+	err := receiver.DeviceScheduleRepository.DeleteOverride(id)
+	if err != nil {
+		return err
+	}
+	go receiver.ExecuteDeviceSchedules()
+
+	return nil
+}
+
+// UpdateOrganizationTimezone sets the IANA timezone the device mode schedules of
+// an organization run in, empty for UTC
+func (receiver *DeviceScheduleUseCase) UpdateOrganizationTimezone(organizationId int64, req request.UpdateOrganizationTimezoneRequest) (*entity.SOrganization, error) {
+	timezone := strings.TrimSpace(req.Timezone)
+	_, err := value.LoadTimezone(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidDeviceSchedule, timezone)
+	}
+
+	var organization entity.SOrganization
+	err = receiver.DB.Where("id = ?", organizationId).First(&organization).Error
+	if err != nil {
+		return nil, err
+	}
+	err = receiver.DB.Model(&entity.SOrganization{}).Where("id = ?", organizationId).Updates(map[string]interface{}{"timezone": timezone, "updated_at": time.Now()}).Error
+	if err != nil {
+		return nil, err
+	}
+	organization.Timezone = timezone
+	go receiver.ExecuteDeviceSchedules()
+
+	return &organization, nil
+}
+
+// scheduledMode is a mode a schedule or an override puts a device in, Mode is
+// nil for an override that leaves the device in its configured mode
+type scheduledMode struct {
+	mode     *value.DeviceMode
+	source   value.DeviceConfigurationScope
+	sourceId uint64
+	rank     int
+}
+
+func (mode scheduledMode) before(other scheduledMode) bool {
+	if mode.rank != other.rank {
+		return mode.rank > other.rank
+	}
+
+	return mode.sourceId > other.sourceId
+}
+
+// ExecuteDeviceSchedules records the mode the schedules and overrides put each
+// device in at now and notifies the devices whose mode changed
+func (receiver *DeviceScheduleUseCase) ExecuteDeviceSchedules() {
+	receiver.executing.Lock()
+	defer receiver.executing.Unlock()
+
+	now := time.Now()
+	desired, err := receiver.desiredModes(now)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.ExecuteDeviceSchedules ", err)
+		return
+	}
+	current, err := receiver.DeviceScheduleRepository.GetScheduledModes()
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.ExecuteDeviceSchedules ", err)
+		return
+	}
+
+	currentModes := make(map[string]entity.SDeviceScheduledMode, len(current))
+	for _, mode := range current {
+		currentModes[mode.DeviceId] = mode
+	}
+	saved := make([]entity.SDeviceScheduledMode, 0)
+	cleared := make([]string, 0)
+	changed := make([]string, 0)
+	for deviceId, mode := range desired {
+		existing, exists := currentModes[deviceId]
+		if mode.mode == nil {
+			if exists {
+				cleared = append(cleared, deviceId)
+				changed = append(changed, deviceId)
+			}
+			continue
+		}
+		if exists && existing.Mode == *mode.mode && existing.Source == mode.source && existing.SourceId == mode.sourceId {
+			continue
+		}
+
+		saved = append(saved, entity.SDeviceScheduledMode{
+			DeviceId: deviceId,
+			Mode:     *mode.mode,
+			Source:   mode.source,
+			SourceId: mode.sourceId,
+			Since:    now,
+		})
+		if !exists || existing.Mode != *mode.mode {
+			changed = append(changed, deviceId)
+		}
+	}
+	for deviceId := range currentModes {
+		if _, ok := desired[deviceId]; !ok {
+			cleared = append(cleared, deviceId)
+			changed = append(changed, deviceId)
+		}
+	}
+	if len(saved) == 0 && len(cleared) == 0 {
+		return
+	}
+
+	previousModes := make(map[string]string, len(changed))
+	for _, deviceId := range changed {
+		mode, err := receiver.DeviceConfigurationUseCase.EffectiveMode(deviceId)
+		if err == nil {
+			previousModes[deviceId] = string(mode)
+		}
+	}
+
+	err = receiver.DeviceScheduleRepository.SaveScheduledModes(saved)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.ExecuteDeviceSchedules ", err)
+		return
+	}
+	err = receiver.DeviceScheduleRepository.DeleteScheduledModes(cleared)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.ExecuteDeviceSchedules ", err)
+		return
+	}
+
+	receiver.notify(changed, previousModes)
+}
+
+// desiredModes returns the mode of the first schedule or override of each device
+// it applies to at now
+func (receiver *DeviceScheduleUseCase) desiredModes(now time.Time) (map[string]scheduledMode, error) {
+	schedules, err := receiver.DeviceScheduleRepository.GetEnabledSchedules()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := receiver.DeviceScheduleRepository.GetActiveOverrides(now)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIds := make([]uint64, 0)
+	for _, schedule := range schedules {
+		if id, err := strconv.ParseUint(schedule.ScopeId, 10, 64); err == nil && schedule.Scope == value.DeviceConfigurationScope_Group {
+			groupIds = append(groupIds, id)
+		}
+	}
+	for _, override := range overrides {
+		if id, err := strconv.ParseUint(override.ScopeId, 10, 64); err == nil && override.Scope == value.DeviceConfigurationScope_Group {
+			groupIds = append(groupIds, id)
+		}
+	}
+	groupDevices, err := receiver.DeviceScheduleRepository.GetGroupsDeviceIds(groupIds)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := make(map[string]scheduledMode)
+	propose := func(deviceIds []string, mode scheduledMode) {
+		for _, deviceId := range deviceIds {
+			if existing, ok := desired[deviceId]; !ok || mode.before(existing) {
+				desired[deviceId] = mode
+			}
+		}
+	}
+
+	locations := newScheduleLocations(receiver)
+	for _, schedule := range schedules {
+		deviceIds, location, err := receiver.scopeDevices(schedule.Scope, schedule.ScopeId, groupDevices, locations)
+		if err != nil {
+			log.Error("DeviceScheduleUseCase.desiredModes ", err)
+			continue
+		}
+		start, startErr := value.ParseClock(schedule.StartTime)
+		end, endErr := value.ParseClock(schedule.EndTime)
+		if startErr != nil || endErr != nil {
+			continue
+		}
+		window := value.DeviceScheduleWindow{Days: schedule.Days, Start: start, End: end}
+		if !window.Contains(now.In(location)) {
+			continue
+		}
+
+		mode := schedule.Mode
+		rank := 1
+		if schedule.Scope == value.DeviceConfigurationScope_Device {
+			rank = 2
+		}
+		propose(deviceIds, scheduledMode{mode: &mode, source: value.DeviceConfigurationScope_Schedule, sourceId: schedule.ID, rank: rank})
+	}
+	for _, override := range overrides {
+		deviceIds, _, err := receiver.scopeDevices(override.Scope, override.ScopeId, groupDevices, nil)
+		if err != nil {
+			log.Error("DeviceScheduleUseCase.desiredModes ", err)
+			continue
+		}
+
+		rank := 3
+		if override.Scope == value.DeviceConfigurationScope_Device {
+			rank = 4
+		}
+		propose(deviceIds, scheduledMode{mode: override.Mode, source: value.DeviceConfigurationScope_Override, sourceId: override.ID, rank: rank})
+	}
+
+	return desired, nil
+}
+
+// scopeDevices returns the devices of a device or group scope and, when locations
+// is given, the timezone of their organization
+func (receiver *DeviceScheduleUseCase) scopeDevices(scope value.DeviceConfigurationScope, scopeId string, groupDevices map[uint64][]string, locations *scheduleLocations) ([]string, *time.Location, error) {
+	switch scope {
+	case value.DeviceConfigurationScope_Group:
+		id, err := strconv.ParseUint(scopeId, 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		if locations == nil {
+			return groupDevices[id], nil, nil
+		}
+		location, err := locations.ofGroup(id)
+		return groupDevices[id], location, err
+	case value.DeviceConfigurationScope_Device:
+		if locations == nil {
+			return []string{scopeId}, nil, nil
+		}
+		location, err := locations.ofDevice(scopeId)
+		return []string{scopeId}, location, err
+	}
+
+	return nil, nil, fmt.Errorf("unknown scope %s", scope)
+}
+
+// notify sends the new mode of the devices through FCM and publishes their
+// status change
+func (receiver *DeviceScheduleUseCase) notify(deviceIds []string, previousModes map[string]string) {
+	modes := make(map[string]string, len(deviceIds))
+	for _, deviceId := range deviceIds {
+		configuration, err := receiver.DeviceConfigurationUseCase.ResolveConfiguration(deviceId)
+		if err != nil {
+			log.Error("DeviceScheduleUseCase.notify ", err)
+			continue
+		}
+		modes[deviceId] = configuration.Mode
+		if previousModes[deviceId] == configuration.Mode {
+			continue
+		}
+
+		PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+			"device_id":       deviceId,
+			"status":          configuration.Mode,
+			"previous_status": previousModes[deviceId],
+			"source":          configuration.Sources.Mode,
+		})
+	}
+	if receiver.FirebaseApp == nil || len(modes) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(modes))
+	for deviceId := range modes {
+		ids = append(ids, deviceId)
+	}
+	mobileDevices, err := receiver.MobileDeviceRepository.FindByDeviceIDs(ids, receiver.DB)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.notify ", err)
+		return
+	}
+
+	params := make([]messaging.DataMessageParams, 0, len(mobileDevices))
+	for _, mobileDevice := range mobileDevices {
+		params = append(params, messaging.DataMessageParams{
+			DeviceToken: mobileDevice.FCMToken,
+			Type:        value.NotificationType_DeviceStatusChanged,
+			Data: map[string]string{
+				"device_id": mobileDevice.DeviceId,
+				"status":    modes[mobileDevice.DeviceId],
+			},
+		})
+	}
+	errs, err := messaging.SendDataMessages(receiver.FirebaseApp, params)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase.notify ", err)
+		return
+	}
+	for i, err := range errs {
+		if err != nil {
+			log.Error("DeviceScheduleUseCase.notify ", params[i].Data["device_id"], " ", err)
+		}
+	}
+}
+
+func (receiver *DeviceScheduleUseCase) apply(schedule *entity.SDeviceModeSchedule, req request.SaveDeviceModeScheduleRequest) error {
+	scope, err := receiver.validateScope(req.Scope, req.ScopeId)
+	if err != nil {
+		return err
+	}
+	mode, err := value.GetDeviceModeFromString(req.Mode)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDeviceSchedule, err.Error())
+	}
+	days, err := value.GetWeekdaysFromStrings(req.Days)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDeviceSchedule, err.Error())
+	}
+
+	startTime := req.StartTime
+	if startTime == "" {
+		startTime = value.WorkingHoursEnd
+	}
+	endTime := req.EndTime
+	if endTime == "" {
+		endTime = value.WorkingHoursStart
+	}
+	start, err := value.ParseClock(startTime)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDeviceSchedule, err.Error())
+	}
+	end, err := value.ParseClock(endTime)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDeviceSchedule, err.Error())
+	}
+
+	schedule.Scope = scope
+	schedule.ScopeId = req.ScopeId
+	schedule.Name = strings.TrimSpace(req.Name)
+	schedule.Mode = mode
+	schedule.Days = days
+	schedule.StartTime = value.FormatClock(start)
+	schedule.EndTime = value.FormatClock(end)
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+
+	return nil
+}
+
+func (receiver *DeviceScheduleUseCase) validateScope(scope string, scopeId string) (value.DeviceConfigurationScope, error) {
+	switch value.DeviceConfigurationScope(scope) {
+	case value.DeviceConfigurationScope_Device:
+		_, err := receiver.DeviceRepository.GetDeviceById(scopeId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: no device %s", ErrInvalidDeviceSchedule, scopeId)
+		}
+		return value.DeviceConfigurationScope_Device, err
+	case value.DeviceConfigurationScope_Group:
+		id, err := strconv.ParseUint(scopeId, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid group id %s", ErrInvalidDeviceSchedule, scopeId)
+		}
+		_, err = receiver.DeviceGroupRepository.GetGroup(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: no device group %s", ErrInvalidDeviceSchedule, scopeId)
+		}
+		return value.DeviceConfigurationScope_Group, err
+	}
+
+	return "", fmt.Errorf("%w: scope is device or group", ErrInvalidDeviceSchedule)
+}
+
+// scheduleLocations caches the timezones of the organizations during a run
+type scheduleLocations struct {
+	useCase       *DeviceScheduleUseCase
+	organizations map[int64]*time.Location
+}
+
+func newScheduleLocations(useCase *DeviceScheduleUseCase) *scheduleLocations {
+	return &scheduleLocations{useCase: useCase, organizations: make(map[int64]*time.Location)}
+}
+
+func (locations *scheduleLocations) ofGroup(groupId uint64) (*time.Location, error) {
+	group, err := locations.useCase.DeviceGroupRepository.GetGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return locations.ofOrganization(group.OrganizationId)
+}
+
+func (locations *scheduleLocations) ofDevice(deviceId string) (*time.Location, error) {
+	device, err := locations.useCase.DeviceRepository.GetDeviceById(deviceId)
+	if err != nil {
+		return nil, err
+	}
+	organizationId, err := locations.useCase.DeviceConfigurationUseCase.DeviceOrganizationId(*device)
+	if err != nil {
+		return nil, err
+	}
+
+	return locations.ofOrganization(organizationId)
+}
+
+func (locations *scheduleLocations) ofOrganization(organizationId *int64) (*time.Location, error) {
+	if organizationId == nil {
+		return time.UTC, nil
+	}
+	if location, ok := locations.organizations[*organizationId]; ok {
+		return location, nil
+	}
+
+	var organization entity.SOrganization
+	err := locations.useCase.DB.Select("id", "timezone").Where("id = ?", *organizationId).First(&organization).Error
+	if err != nil {
+		return nil, err
+	}
+	location, err := value.LoadTimezone(organization.Timezone)
+	if err != nil {
+		log.Error("DeviceScheduleUseCase: organization ", organization.ID, " has an unknown timezone ", organization.Timezone)
+		location = time.UTC
+	}
+	locations.organizations[*organizationId] = location
+
+	return location, nil
+}
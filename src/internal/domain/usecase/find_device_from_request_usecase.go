@@ -6,7 +6,6 @@ import (
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -27,7 +26,11 @@ func (receiver *FindDeviceFromRequestCase) FindDevice(context *gin.Context) (*en
 		return nil, errors.New("no authorization header")
 	}
 
-	tokenString := strings.Split(authorization, " ")[1]
+	parts := strings.Split(authorization, " ")
+	if len(parts) < 2 {
+		return nil, errors.New("invalid authorization header")
+	}
+	tokenString := parts[1]
 
 	deviceId, err := receiver.ExtractDeviceIdFromToken(tokenString)
 	if err != nil || deviceId == nil {
@@ -43,15 +46,13 @@ func (receiver *FindDeviceFromRequestCase) FindDevice(context *gin.Context) (*en
 }
 
 func NewFindDeviceFromRequestCase(cfg config.AppConfig, dbConn *gorm.DB) *FindDeviceFromRequestCase {
+	sessionRepository := NewSessionRepository(cfg, dbConn)
 	return &FindDeviceFromRequestCase{
 		DeviceRepository: &repository.DeviceRepository{
 			DBConn:                      dbConn,
 			DefaultRequestPageSize:      cfg.DefaultRequestPageSize,
 			DefaultOutputSpreadsheetUrl: cfg.OutputSpreadsheetUrl,
 		},
-		SessionRepository: &repository.SessionRepository{
-			AuthorizeEncryptKey:   cfg.AuthorizeEncryptKey,
-			TokenExpireTimeInHour: time.Duration(cfg.TokenExpireDurationInHour),
-		},
+		SessionRepository: &sessionRepository,
 	}
 }
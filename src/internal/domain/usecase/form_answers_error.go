@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"fmt"
+	"sen-global-api/internal/domain/response"
+)
+
+// FormAnswersError lists every answer of a submission that was rejected
+type FormAnswersError struct {
+	Errors []response.QuestionAnswerError
+}
+
+func (receiver *FormAnswersError) Error() string {
+	if len(receiver.Errors) == 1 {
+		return fmt.Sprintf("invalid answer for question %s: %s", receiver.Errors[0].QuestionId, receiver.Errors[0].Reason)
+	}
+
+	return fmt.Sprintf("%d answers are invalid", len(receiver.Errors))
+}
+
+func (receiver *FormAnswersError) add(questionId string, reason string) {
+	receiver.Errors = append(receiver.Errors, response.QuestionAnswerError{
+		QuestionId: questionId,
+		Reason:     reason,
+	})
+}
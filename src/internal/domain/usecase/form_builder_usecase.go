@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidFormQuestion = errors.New("invalid form question")
+	ErrFormAlreadyExists   = errors.New("form already exists")
+)
+
+// FormBuilderUseCase builds forms directly in the database. Importing from a
+// spreadsheet stays available and simply replaces the questions of the form.
+type FormBuilderUseCase struct {
+	DBConn *gorm.DB
+}
+
+func (receiver *FormBuilderUseCase) CreateForm(req request.CreateNativeFormRequest) (*entity.SForm, error) {
+	formRepository := &repository.FormRepository{DBConn: receiver.DBConn, OrganizationId: req.OrganizationId}
+	_, err := formRepository.GetFormByQRCode(req.Note)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFormAlreadyExists, req.Note)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return formRepository.Create(&entity.SForm{
+		Note:     req.Note,
+		Name:     req.Name,
+		Password: req.Password,
+		Status:   value.Active,
+	})
+}
+
+func (receiver *FormBuilderUseCase) GetForm(formID uint64) (*entity.SForm, []model.FormQuestionItem, error) {
+	form, err := (&repository.FormRepository{DBConn: receiver.DBConn}).GetFormById(formID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	questions, err := (&repository.QuestionRepository{DBConn: receiver.DBConn}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return form, questions, nil
+}
+
+func (receiver *FormBuilderUseCase) AddQuestion(formID uint64, req request.SaveFormQuestionRequest) (*model.FormQuestionItem, error) {
+	question, err := buildFormQuestion(uuid.NewString(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		_, err := (&repository.FormRepository{DBConn: tx}).GetFormById(formID)
+		if err != nil {
+			return err
+		}
+
+		questionIDs, err := formQuestionIDs(tx, formID)
+		if err != nil {
+			return err
+		}
+
+		questionRepository := &repository.QuestionRepository{DBConn: tx}
+		err = questionRepository.CreateQuestion(question)
+		if err != nil {
+			return err
+		}
+
+		formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+		_, err = formQuestionRepository.CreateFormQuestions(formID, []request.CreateFormQuestionItem{{
+			QuestionId:     question.QuestionId.String(),
+			Order:          len(questionIDs) + 1,
+			AnswerRequired: req.AnswerRequired,
+		}})
+		if err != nil {
+			return err
+		}
+
+		questionIDs = moveQuestion(questionIDs, question.QuestionId.String(), req.Position)
+
+		err = formQuestionRepository.UpdateOrders(formID, questionIDs)
+		if err != nil {
+			return err
+		}
+
+		err = saveFormQuestionRules(tx, formID, question.QuestionId.String(), req.Rules)
+		if err != nil {
+			return err
+		}
+
+		return publishBuilderChange(tx, formID, "Added question "+question.QuestionId.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.findFormQuestion(formID, question.QuestionId.String())
+}
+
+// UpdateQuestion edits a question of a form. A question shared with other forms is
+// forked first, so the edit only applies to this form.
+func (receiver *FormBuilderUseCase) UpdateQuestion(formID uint64, questionID string, req request.SaveFormQuestionRequest) (*model.FormQuestionItem, error) {
+	question, err := buildFormQuestion(questionID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+		_, err := formQuestionRepository.GetFormQuestion(formID, questionID)
+		if err != nil {
+			return err
+		}
+
+		usages, err := formQuestionRepository.CountFormsOfQuestion(questionID)
+		if err != nil {
+			return err
+		}
+
+		questionRepository := &repository.QuestionRepository{DBConn: tx}
+		if usages > 1 {
+			question.QuestionId = uuid.New()
+			err = questionRepository.CreateQuestion(question)
+			if err != nil {
+				return err
+			}
+
+			err = forkFormQuestion(tx, formID, questionID, question.QuestionId.String())
+			questionID = question.QuestionId.String()
+		} else {
+			err = questionRepository.UpdateQuestion(question)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = formQuestionRepository.UpdateAnswerRequired(formID, questionID, req.AnswerRequired)
+		if err != nil {
+			return err
+		}
+
+		if req.Position != nil {
+			questionIDs, err := formQuestionIDs(tx, formID)
+			if err != nil {
+				return err
+			}
+
+			err = formQuestionRepository.UpdateOrders(formID, moveQuestion(questionIDs, questionID, req.Position))
+			if err != nil {
+				return err
+			}
+		}
+
+		err = saveFormQuestionRules(tx, formID, questionID, req.Rules)
+		if err != nil {
+			return err
+		}
+
+		return publishBuilderChange(tx, formID, "Updated question "+questionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.findFormQuestion(formID, questionID)
+}
+
+// ReorderQuestions puts the questions of a form in the given order. The list has to
+// name every question of the form exactly once.
+func (receiver *FormBuilderUseCase) ReorderQuestions(formID uint64, req request.ReorderFormQuestionsRequest) ([]model.FormQuestionItem, error) {
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		questionIDs, err := formQuestionIDs(tx, formID)
+		if err != nil {
+			return err
+		}
+
+		if len(questionIDs) != len(req.QuestionIds) {
+			return fmt.Errorf("%w: expected %d question ids, got %d", ErrInvalidFormQuestion, len(questionIDs), len(req.QuestionIds))
+		}
+
+		existing := make(map[string]bool, len(questionIDs))
+		for _, id := range questionIDs {
+			existing[id] = true
+		}
+		for _, id := range req.QuestionIds {
+			if !existing[id] {
+				return fmt.Errorf("%w: question %s is not part of form %d or is listed twice", ErrInvalidFormQuestion, id, formID)
+			}
+			delete(existing, id)
+		}
+
+		err = (&repository.FormQuestionRepository{DBConn: tx}).UpdateOrders(formID, req.QuestionIds)
+		if err != nil {
+			return err
+		}
+
+		err = saveFormQuestionRules(tx, formID, "", nil)
+		if err != nil {
+			return err
+		}
+
+		return publishBuilderChange(tx, formID, "Reordered questions")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return (&repository.QuestionRepository{DBConn: receiver.DBConn}).GetAllQuestionsByFormId(formID)
+}
+
+// DeleteQuestion removes a question from a form, the question itself is deleted
+// once no other form uses it.
+func (receiver *FormBuilderUseCase) DeleteQuestion(formID uint64, questionID string) error {
+	return receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+		_, err := formQuestionRepository.GetFormQuestion(formID, questionID)
+		if err != nil {
+			return err
+		}
+
+		err = formQuestionRepository.Delete(formID, questionID)
+		if err != nil {
+			return err
+		}
+
+		usages, err := formQuestionRepository.CountFormsOfQuestion(questionID)
+		if err != nil {
+			return err
+		}
+		if usages == 0 {
+			err = (&repository.QuestionRepository{DBConn: tx}).DeleteQuestion(questionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		questionIDs, err := formQuestionIDs(tx, formID)
+		if err != nil {
+			return err
+		}
+
+		err = formQuestionRepository.UpdateOrders(formID, questionIDs)
+		if err != nil {
+			return err
+		}
+
+		err = removeRulesOfQuestion(tx, formID, questionID)
+		if err != nil {
+			return err
+		}
+
+		return publishBuilderChange(tx, formID, "Removed question "+questionID)
+	})
+}
+
+func (receiver *FormBuilderUseCase) findFormQuestion(formID uint64, questionID string) (*model.FormQuestionItem, error) {
+	questions, err := (&repository.QuestionRepository{DBConn: receiver.DBConn}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, question := range questions {
+		if question.QuestionId == questionID {
+			return &question, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func buildFormQuestion(questionID string, req request.SaveFormQuestionRequest) (*entity.SQuestion, error) {
+	attributes := strings.TrimSpace(string(req.Attributes))
+	if attributes == "" || attributes == "null" {
+		attributes = "{}"
+	}
+	if !json.Valid([]byte(attributes)) || !strings.HasPrefix(attributes, "{") {
+		return nil, fmt.Errorf("%w: attributes must be a JSON object", ErrInvalidFormQuestion)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = value.GetRawStatusValue(value.Active)
+	}
+
+	var enableOnMobile value.QuestionForMobile = value.QuestionForMobile_Enabled
+	if req.EnableOnMobile != "" {
+		enableOnMobile = req.EnableOnMobile
+	}
+	if enableOnMobile != value.QuestionForMobile_Enabled && enableOnMobile != value.QuestionForMobile_Disabled {
+		return nil, fmt.Errorf("%w: invalid enable_on_mobile value %s", ErrInvalidFormQuestion, enableOnMobile)
+	}
+
+	question, err := (&repository.QuestionRepository{}).ValidateQuestion(repository.CreateQuestionParams{
+		QuestionId:       questionID,
+		QuestionName:     req.Question,
+		QuestionType:     strings.ToLower(req.QuestionType),
+		Question:         req.Question,
+		Attributes:       attributes,
+		Status:           status,
+		EnableOnMobile:   enableOnMobile,
+		QuestionUniqueId: req.QuestionUniqueId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormQuestion, err.Error())
+	}
+
+	return question, nil
+}
+
+// publishBuilderChange publishes the working copy once the builder changed it, so
+// the published revision submissions record is always what devices show
+func publishBuilderChange(tx *gorm.DB, formID uint64, note string) error {
+	_, err := publishCurrentQuestions(tx, formID, note)
+
+	return err
+}
+
+// saveFormQuestionRules replaces the rules of the question when rules are given and
+// checks that every rule of the form still holds
+func saveFormQuestionRules(tx *gorm.DB, formID uint64, questionID string, rules *[]entity.QuestionRule) error {
+	if rules != nil {
+		data, err := MarshalQuestionRules(*rules)
+		if err != nil {
+			return err
+		}
+		err = (&repository.FormQuestionRepository{DBConn: tx}).UpdateRules(formID, questionID, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	questions, err := (&repository.QuestionRepository{DBConn: tx}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateQuestionRules(questions)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFormQuestion, err.Error())
+	}
+
+	return nil
+}
+
+// forkFormQuestion points the form at forkedID in place of questionID, keeping its
+// order, answer_required and rules, and renames the rules of the form that refer to it
+func forkFormQuestion(tx *gorm.DB, formID uint64, questionID string, forkedID string) error {
+	formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+	err := formQuestionRepository.ReplaceQuestion(formID, questionID, forkedID)
+	if err != nil {
+		return err
+	}
+
+	questions, err := (&repository.QuestionRepository{DBConn: tx}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return err
+	}
+
+	for _, question := range questions {
+		rules := UnmarshalQuestionRules(question.Rules)
+		renamed := false
+		for index := range rules {
+			if rules[index].Question == questionID {
+				rules[index].Question = forkedID
+				renamed = true
+			}
+			if rules[index].Target == questionID {
+				rules[index].Target = forkedID
+				renamed = true
+			}
+		}
+		if !renamed {
+			continue
+		}
+
+		data, err := MarshalQuestionRules(rules)
+		if err != nil {
+			return err
+		}
+		err = formQuestionRepository.UpdateRules(formID, question.QuestionId, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeRulesOfQuestion drops the rules of other questions that refer to a question
+// removed from the form
+func removeRulesOfQuestion(tx *gorm.DB, formID uint64, questionID string) error {
+	questions, err := (&repository.QuestionRepository{DBConn: tx}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return err
+	}
+
+	formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+	for _, question := range questions {
+		rules := UnmarshalQuestionRules(question.Rules)
+		kept := make([]entity.QuestionRule, 0, len(rules))
+		for _, rule := range rules {
+			if rule.Question != questionID && rule.Target != questionID {
+				kept = append(kept, rule)
+			}
+		}
+		if len(kept) == len(rules) {
+			continue
+		}
+
+		data, err := MarshalQuestionRules(kept)
+		if err != nil {
+			return err
+		}
+		err = formQuestionRepository.UpdateRules(formID, question.QuestionId, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formQuestionIDs(tx *gorm.DB, formID uint64) ([]string, error) {
+	questions, err := (&repository.QuestionRepository{DBConn: tx}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(questions))
+	for _, question := range questions {
+		ids = append(ids, question.QuestionId)
+	}
+
+	return ids, nil
+}
+
+// moveQuestion places questionID at the 1-based position, or keeps it where it is
+// (appending it when missing) when no position is given.
+func moveQuestion(questionIDs []string, questionID string, position *int) []string {
+	result := make([]string, 0, len(questionIDs)+1)
+	found := false
+	for _, id := range questionIDs {
+		if id == questionID {
+			found = true
+			if position != nil {
+				continue
+			}
+		}
+		result = append(result, id)
+	}
+
+	if position == nil {
+		if !found {
+			result = append(result, questionID)
+		}
+		return result
+	}
+
+	index := *position - 1
+	if index < 0 {
+		index = 0
+	}
+	if index > len(result) {
+		index = len(result)
+	}
+
+	result = append(result, "")
+	copy(result[index+1:], result[index:])
+	result[index] = questionID
+
+	return result
+}
@@ -0,0 +1,597 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidFormRevision = errors.New("invalid form revision")
+
+// FormRevisionUseCase keeps numbered snapshots of the questions of a form. The
+// s_form_question and s_question rows stay the working copy that devices read,
+// a revision records what that working copy looked like at a point in time.
+type FormRevisionUseCase struct {
+	DBConn *gorm.DB
+}
+
+func NewFormRevisionUseCase(db *gorm.DB) *FormRevisionUseCase {
+	return &FormRevisionUseCase{DBConn: db}
+}
+
+// PublishCurrentQuestions returns the published revision of the form, publishing a
+// new one first when the questions changed since the last publication.
+func (receiver *FormRevisionUseCase) PublishCurrentQuestions(formID uint64, note string) (*entity.SFormRevision, error) {
+	var result *entity.SFormRevision
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = publishCurrentQuestions(tx, formID, note)
+
+		return err
+	})
+
+	return result, err
+}
+
+// GetPublishedRevision returns the published revision of the form without
+// publishing the working copy, nil when the form was never published
+func (receiver *FormRevisionUseCase) GetPublishedRevision(formID uint64) (*entity.SFormRevision, error) {
+	revision, err := (&repository.FormRevisionRepository{DBConn: receiver.DBConn}).GetPublishedRevision(formID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return revision, err
+}
+
+// CreateDraft stores the current questions of the form as a draft revision
+func (receiver *FormRevisionUseCase) CreateDraft(formID uint64, req request.CreateFormRevisionRequest) (*entity.SFormRevision, error) {
+	var result *entity.SFormRevision
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		number, err := (&repository.FormRevisionRepository{DBConn: tx}).NextRevisionNumber(formID)
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := currentFormSnapshot(tx, formID)
+		if err != nil {
+			return err
+		}
+
+		result, err = createFormRevision(tx, formID, number, snapshot, req.Note)
+
+		return err
+	})
+
+	return result, err
+}
+
+// Publish makes a draft revision the live questions of the form
+func (receiver *FormRevisionUseCase) Publish(formID uint64, revision int) (*entity.SFormRevision, error) {
+	var result *entity.SFormRevision
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		revisionRepository := &repository.FormRevisionRepository{DBConn: tx}
+		_, err := revisionRepository.NextRevisionNumber(formID)
+		if err != nil {
+			return err
+		}
+
+		result, err = revisionRepository.GetRevision(formID, revision)
+		if err != nil {
+			return err
+		}
+		if result.Status != value.FormRevisionStatus_Draft {
+			return fmt.Errorf("%w: revision %d is %s, only drafts can be published", ErrInvalidFormRevision, revision, result.Status)
+		}
+
+		snapshot, err := unmarshalFormSnapshot(result.Snapshot)
+		if err != nil {
+			return err
+		}
+
+		restored, err := restoreFormSnapshot(tx, formID, snapshot)
+		if err != nil {
+			return err
+		}
+		if !sameFormSnapshot(restored, snapshot) {
+			result.Snapshot, err = json.Marshal(restored)
+			if err != nil {
+				return err
+			}
+			err = revisionRepository.UpdateSnapshot(result)
+			if err != nil {
+				return err
+			}
+		}
+
+		return revisionRepository.Publish(result)
+	})
+
+	return result, err
+}
+
+// Rollback publishes a copy of an earlier revision as a new revision. Published and
+// archived revisions are never modified.
+func (receiver *FormRevisionUseCase) Rollback(formID uint64, revision int) (*entity.SFormRevision, error) {
+	var result *entity.SFormRevision
+	err := receiver.DBConn.Transaction(func(tx *gorm.DB) error {
+		revisionRepository := &repository.FormRevisionRepository{DBConn: tx}
+		number, err := revisionRepository.NextRevisionNumber(formID)
+		if err != nil {
+			return err
+		}
+
+		target, err := revisionRepository.GetRevision(formID, revision)
+		if err != nil {
+			return err
+		}
+		if target.Status == value.FormRevisionStatus_Draft {
+			return fmt.Errorf("%w: revision %d is a draft, publish it instead", ErrInvalidFormRevision, revision)
+		}
+
+		snapshot, err := unmarshalFormSnapshot(target.Snapshot)
+		if err != nil {
+			return err
+		}
+
+		restored, err := restoreFormSnapshot(tx, formID, snapshot)
+		if err != nil {
+			return err
+		}
+
+		result, err = createFormRevision(tx, formID, number, restored, "Rollback to revision "+strconv.Itoa(revision))
+		if err != nil {
+			return err
+		}
+
+		return revisionRepository.Publish(result)
+	})
+
+	return result, err
+}
+
+func (receiver *FormRevisionUseCase) GetRevisions(formID uint64) ([]entity.SFormRevision, error) {
+	_, err := (&repository.FormRepository{DBConn: receiver.DBConn}).GetFormById(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&repository.FormRevisionRepository{DBConn: receiver.DBConn}).GetRevisions(formID)
+}
+
+func (receiver *FormRevisionUseCase) GetRevision(formID uint64, revision int) (*entity.SFormRevision, error) {
+	return (&repository.FormRevisionRepository{DBConn: receiver.DBConn}).GetRevision(formID, revision)
+}
+
+// Diff compares two revisions of a form. Questions are matched by question id, then
+// by question_unique_id and finally by position, since a spreadsheet import gives
+// every question a new id.
+func (receiver *FormRevisionUseCase) Diff(formID uint64, from int, to int) (*response.FormRevisionDiffData, error) {
+	revisionRepository := &repository.FormRevisionRepository{DBConn: receiver.DBConn}
+	fromRevision, err := revisionRepository.GetRevision(formID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := revisionRepository.GetRevision(formID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromSnapshot, err := unmarshalFormSnapshot(fromRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := unmarshalFormSnapshot(toRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.FormRevisionDiffData{
+		FormId:  formID,
+		From:    from,
+		To:      to,
+		Changes: diffFormSnapshots(fromSnapshot, toSnapshot),
+	}, nil
+}
+
+// publishCurrentQuestions is PublishCurrentQuestions inside the transaction of the
+// caller, so a change of the working copy and its revision are committed together
+func publishCurrentQuestions(tx *gorm.DB, formID uint64, note string) (*entity.SFormRevision, error) {
+	revisionRepository := &repository.FormRevisionRepository{DBConn: tx}
+	number, err := revisionRepository.NextRevisionNumber(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := currentFormSnapshot(tx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := revisionRepository.GetPublishedRevision(formID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if published != nil {
+		publishedSnapshot, err := unmarshalFormSnapshot(published.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if sameFormSnapshot(publishedSnapshot, snapshot) {
+			return published, nil
+		}
+	}
+
+	result, err := createFormRevision(tx, formID, number, snapshot, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, revisionRepository.Publish(result)
+}
+
+func createFormRevision(tx *gorm.DB, formID uint64, number int, snapshot entity.FormRevisionSnapshot, note string) (*entity.SFormRevision, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &entity.SFormRevision{
+		FormId:   formID,
+		Revision: number,
+		Status:   value.FormRevisionStatus_Draft,
+		Snapshot: data,
+		Note:     note,
+	}
+	err = (&repository.FormRevisionRepository{DBConn: tx}).Create(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func currentFormSnapshot(tx *gorm.DB, formID uint64) (entity.FormRevisionSnapshot, error) {
+	questions, err := (&repository.QuestionRepository{DBConn: tx}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return entity.FormRevisionSnapshot{}, err
+	}
+
+	snapshot := entity.FormRevisionSnapshot{Questions: make([]entity.FormRevisionQuestion, 0, len(questions))}
+	for _, question := range questions {
+		snapshot.Questions = append(snapshot.Questions, toFormRevisionQuestion(question))
+	}
+
+	return snapshot, nil
+}
+
+func toFormRevisionQuestion(question model.FormQuestionItem) entity.FormRevisionQuestion {
+	attributes := json.RawMessage(question.Attributes)
+	if len(attributes) == 0 {
+		attributes = json.RawMessage("{}")
+	}
+
+	return entity.FormRevisionQuestion{
+		QuestionId:       question.QuestionId,
+		QuestionName:     question.QuestionName,
+		QuestionType:     question.QuestionType,
+		Question:         question.Question,
+		Attributes:       attributes,
+		Status:           value.GetRawStatusValue(question.Status),
+		Order:            question.Order,
+		AnswerRequired:   question.AnswerRequired,
+		EnableOnMobile:   question.EnableOnMobile,
+		QuestionUniqueId: question.QuestionUniqueId,
+		Rules:            json.RawMessage(question.Rules),
+	}
+}
+
+func unmarshalFormSnapshot(data []byte) (entity.FormRevisionSnapshot, error) {
+	var snapshot entity.FormRevisionSnapshot
+	err := json.Unmarshal(data, &snapshot)
+
+	return snapshot, err
+}
+
+// restoreFormSnapshot replaces the working copy of the form with the snapshot and
+// returns the snapshot as restored. The questions keep their ids so older
+// submissions still resolve, except questions other forms use that changed since
+// the snapshot: those are restored under a new id, leaving the other forms alone.
+func restoreFormSnapshot(tx *gorm.DB, formID uint64, snapshot entity.FormRevisionSnapshot) (entity.FormRevisionSnapshot, error) {
+	previousIDs, err := formQuestionIDs(tx, formID)
+	if err != nil {
+		return snapshot, err
+	}
+
+	formQuestionRepository := &repository.FormQuestionRepository{DBConn: tx}
+	err = formQuestionRepository.DeleteByFormID(formID)
+	if err != nil {
+		return snapshot, err
+	}
+
+	restored := entity.FormRevisionSnapshot{Questions: make([]entity.FormRevisionQuestion, 0, len(snapshot.Questions))}
+	questions := make([]entity.SQuestion, 0, len(snapshot.Questions))
+	forkedIDs := make(map[string]string)
+	for _, question := range snapshot.Questions {
+		questionID, err := uuid.Parse(question.QuestionId)
+		if err != nil {
+			return snapshot, fmt.Errorf("%w: %s", ErrInvalidFormRevision, err.Error())
+		}
+		status, err := value.GetStatusFromString(question.Status)
+		if err != nil {
+			return snapshot, fmt.Errorf("%w: %s", ErrInvalidFormRevision, err.Error())
+		}
+
+		restoredQuestion := entity.SQuestion{
+			QuestionId:       questionID,
+			QuestionName:     question.QuestionName,
+			QuestionType:     question.QuestionType,
+			Question:         question.Question,
+			Attributes:       []byte(question.Attributes),
+			Status:           status,
+			EnableOnMobile:   question.EnableOnMobile,
+			QuestionUniqueId: question.QuestionUniqueId,
+		}
+		shared, err := sharedQuestionChanged(tx, restoredQuestion)
+		if err != nil {
+			return snapshot, err
+		}
+		if shared {
+			restoredQuestion.QuestionId = uuid.New()
+			forkedIDs[question.QuestionId] = restoredQuestion.QuestionId.String()
+			question.QuestionId = restoredQuestion.QuestionId.String()
+		}
+
+		questions = append(questions, restoredQuestion)
+		restored.Questions = append(restored.Questions, question)
+	}
+
+	questionRepository := &repository.QuestionRepository{DBConn: tx}
+	err = questionRepository.RestoreQuestions(questions)
+	if err != nil {
+		return snapshot, err
+	}
+
+	items := make([]request.CreateFormQuestionItem, 0, len(restored.Questions))
+	kept := make(map[string]bool, len(restored.Questions))
+	for i, question := range restored.Questions {
+		items = append(items, request.CreateFormQuestionItem{
+			QuestionId:     question.QuestionId,
+			Order:          question.Order,
+			AnswerRequired: question.AnswerRequired,
+		})
+		kept[question.QuestionId] = true
+
+		if len(forkedIDs) > 0 && len(question.Rules) > 0 {
+			rules, err := renameRuleQuestions(question.Rules, forkedIDs)
+			if err != nil {
+				return snapshot, err
+			}
+			restored.Questions[i].Rules = rules
+		}
+	}
+
+	if len(items) > 0 {
+		_, err = formQuestionRepository.CreateFormQuestions(formID, items)
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	for _, question := range restored.Questions {
+		if len(question.Rules) == 0 {
+			continue
+		}
+		err = formQuestionRepository.UpdateRules(formID, question.QuestionId, datatypes.JSON(question.Rules))
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	for _, id := range previousIDs {
+		if kept[id] {
+			continue
+		}
+		usages, err := formQuestionRepository.CountFormsOfQuestion(id)
+		if err != nil {
+			return snapshot, err
+		}
+		if usages == 0 {
+			err = questionRepository.DeleteQuestion(id)
+			if err != nil {
+				return snapshot, err
+			}
+		}
+	}
+
+	return restored, nil
+}
+
+// sharedQuestionChanged tells whether another form uses the question and its row
+// no longer matches question, in which case restoring it in place would change
+// the other forms. Call it once the questions of the restored form are removed.
+func sharedQuestionChanged(tx *gorm.DB, question entity.SQuestion) (bool, error) {
+	usages, err := (&repository.FormQuestionRepository{DBConn: tx}).CountFormsOfQuestion(question.QuestionId.String())
+	if err != nil || usages == 0 {
+		return false, err
+	}
+
+	current, err := (&repository.QuestionRepository{DBConn: tx}).FindById(question.QuestionId.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	same := current.QuestionName == question.QuestionName &&
+		strings.EqualFold(current.QuestionType, question.QuestionType) &&
+		current.Question == question.Question &&
+		sameJSON(json.RawMessage(current.Attributes), json.RawMessage(question.Attributes)) &&
+		current.Status == question.Status &&
+		current.EnableOnMobile == question.EnableOnMobile &&
+		reflect.DeepEqual(current.QuestionUniqueId, question.QuestionUniqueId)
+
+	return !same, nil
+}
+
+// renameRuleQuestions points the rules at the new ids of forked questions
+func renameRuleQuestions(data json.RawMessage, renamed map[string]string) (json.RawMessage, error) {
+	rules := UnmarshalQuestionRules(datatypes.JSON(data))
+	for i, rule := range rules {
+		if id, ok := renamed[rule.Question]; ok {
+			rules[i].Question = id
+		}
+		if id, ok := renamed[rule.Target]; ok {
+			rules[i].Target = id
+		}
+	}
+
+	result, err := MarshalQuestionRules(rules)
+
+	return json.RawMessage(result), err
+}
+
+func sameFormSnapshot(a, b entity.FormRevisionSnapshot) bool {
+	if len(a.Questions) != len(b.Questions) {
+		return false
+	}
+
+	for i := range a.Questions {
+		if a.Questions[i].QuestionId != b.Questions[i].QuestionId || len(changedQuestionFields(a.Questions[i], b.Questions[i])) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func changedQuestionFields(a, b entity.FormRevisionQuestion) []string {
+	fields := make([]string, 0)
+	if a.QuestionType != b.QuestionType {
+		fields = append(fields, "question_type")
+	}
+	if a.Question != b.Question {
+		fields = append(fields, "question")
+	}
+	if !sameJSON(a.Attributes, b.Attributes) {
+		fields = append(fields, "attributes")
+	}
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	if a.Order != b.Order {
+		fields = append(fields, "order")
+	}
+	if a.AnswerRequired != b.AnswerRequired {
+		fields = append(fields, "answer_required")
+	}
+	if a.EnableOnMobile != b.EnableOnMobile {
+		fields = append(fields, "enable_on_mobile")
+	}
+	if !reflect.DeepEqual(a.QuestionUniqueId, b.QuestionUniqueId) {
+		fields = append(fields, "question_unique_id")
+	}
+	if !sameJSON(a.Rules, b.Rules) {
+		fields = append(fields, "rules")
+	}
+
+	return fields
+}
+
+// sameJSON compares two JSON documents ignoring key order and formatting, MySQL
+// normalizes JSON columns when storing them
+func sameJSON(a, b json.RawMessage) bool {
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return string(a) == string(b)
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+func diffFormSnapshots(from, to entity.FormRevisionSnapshot) []response.FormRevisionQuestionChange {
+	matchedFrom := make([]bool, len(from.Questions))
+	pairs := make(map[int]int)
+
+	match := func(key func(entity.FormRevisionQuestion) string) {
+		index := make(map[string]int)
+		for i, question := range from.Questions {
+			if k := key(question); !matchedFrom[i] && k != "" {
+				if _, ok := index[k]; !ok {
+					index[k] = i
+				}
+			}
+		}
+		for j, question := range to.Questions {
+			if _, ok := pairs[j]; ok {
+				continue
+			}
+			i, ok := index[key(question)]
+			if !ok || key(question) == "" || matchedFrom[i] {
+				continue
+			}
+			matchedFrom[i] = true
+			pairs[j] = i
+		}
+	}
+	match(func(q entity.FormRevisionQuestion) string { return q.QuestionId })
+	match(func(q entity.FormRevisionQuestion) string {
+		if q.QuestionUniqueId == nil {
+			return ""
+		}
+		return *q.QuestionUniqueId
+	})
+	match(func(q entity.FormRevisionQuestion) string { return strconv.Itoa(q.Order) })
+
+	changes := make([]response.FormRevisionQuestionChange, 0)
+	for j := range to.Questions {
+		toQuestion := to.Questions[j]
+		i, ok := pairs[j]
+		if !ok {
+			changes = append(changes, response.FormRevisionQuestionChange{
+				Change: response.FormRevisionChangeAdded,
+				To:     &toQuestion,
+			})
+			continue
+		}
+
+		fromQuestion := from.Questions[i]
+		fields := changedQuestionFields(fromQuestion, toQuestion)
+		if len(fields) == 0 {
+			continue
+		}
+		changes = append(changes, response.FormRevisionQuestionChange{
+			Change: response.FormRevisionChangeChanged,
+			Fields: fields,
+			From:   &fromQuestion,
+			To:     &toQuestion,
+		})
+	}
+
+	for i := range from.Questions {
+		if matchedFrom[i] {
+			continue
+		}
+		fromQuestion := from.Questions[i]
+		changes = append(changes, response.FormRevisionQuestionChange{
+			Change: response.FormRevisionChangeRemoved,
+			From:   &fromQuestion,
+		})
+	}
+
+	return changes
+}
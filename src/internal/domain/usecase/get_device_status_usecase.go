@@ -8,7 +8,8 @@ import (
 )
 
 type GetDeviceStatusUseCase struct {
-	DeviceRepository *repository.DeviceRepository
+	DeviceRepository           *repository.DeviceRepository
+	DeviceConfigurationUseCase *DeviceConfigurationUseCase
 }
 
 func statusInStringFrom(status value.DeviceMode) string {
@@ -29,9 +30,20 @@ func statusInStringFrom(status value.DeviceMode) string {
 	return ""
 }
 
+// Execute returns the mode the device runs with, which its group, organization
+// or the global device configuration may set over the status of the device
 func (receiver *GetDeviceStatusUseCase) Execute(device entity.SDevice) (response.GetDeviceStatusResponseData, error) {
+	status := device.Status
+	if receiver != nil && receiver.DeviceConfigurationUseCase != nil {
+		mode, err := receiver.DeviceConfigurationUseCase.EffectiveMode(device.ID)
+		if err != nil {
+			return response.GetDeviceStatusResponseData{}, err
+		}
+		status = mode
+	}
+
 	return response.GetDeviceStatusResponseData{
-		Status:  statusInStringFrom(device.Status),
+		Status:  statusInStringFrom(status),
 		Message: device.DeactivateMessage,
 	}, nil
 }
@@ -5,12 +5,68 @@ import (
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
 )
 
 type GetDeviceListUseCase struct {
 	*repository.DeviceRepository
+	DeviceHeartbeatRepository *repository.DeviceHeartbeatRepository
+	PresenceThresholds        value.DevicePresenceThresholds
 }
 
-func (receiver *GetDeviceListUseCase) GetDeviceList(request request.GetListDeviceRequest) ([]entity.SDevice, *response.Pagination, error) {
-	return receiver.DeviceRepository.GetDeviceList(request)
+// GetDeviceList lists the devices of the organizations of the scope
+func (receiver *GetDeviceListUseCase) GetDeviceList(scope value.AccessScope, request request.GetListDeviceRequest) ([]entity.SDevice, *response.Pagination, error) {
+	var organizationIds []int64 = nil
+	if !scope.AllOrganizations {
+		organizationIds = append(make([]int64, 0, len(scope.OrganizationIds)), scope.OrganizationIds...)
+	}
+
+	return receiver.DeviceRepository.GetDeviceList(request, organizationIds, receiver.PresenceThresholds)
+}
+
+// GetDeviceListWithPresence lists the devices of the scope with their presence
+// and their last heartbeat
+func (receiver *GetDeviceListUseCase) GetDeviceListWithPresence(scope value.AccessScope, request request.GetListDeviceRequest) ([]response.DevicePresenceResponseData, *response.Pagination, error) {
+	devices, paging, err := receiver.GetDeviceList(scope, request)
+	if err != nil {
+		return nil, paging, err
+	}
+
+	deviceIds := make([]string, 0, len(devices))
+	for _, device := range devices {
+		deviceIds = append(deviceIds, device.ID)
+	}
+	heartbeats, err := receiver.DeviceHeartbeatRepository.GetLatestHeartbeats(deviceIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	data := make([]response.DevicePresenceResponseData, 0, len(devices))
+	for _, device := range devices {
+		item := response.DevicePresenceResponseData{
+			DeviceResponseDataV2: response.DeviceResponseDataV2{
+				Id:                device.ID,
+				DeviceName:        device.DeviceName,
+				InputMode:         string(device.InputMode),
+				Status:            string(device.Status),
+				DeactivateMessage: device.DeactivateMessage,
+				ButtonUrl:         device.ButtonUrl,
+				AppVersion:        device.AppVersion,
+				Note:              device.Note,
+				CreatedAt:         device.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdatedAt:         device.UpdatedAt.Format("2006-01-02 15:04:05"),
+			},
+			Presence:        receiver.PresenceThresholds.Of(device.LastHeartbeatAt, now),
+			LastHeartbeatAt: device.LastHeartbeatAt,
+		}
+		if heartbeat, ok := heartbeats[device.ID]; ok {
+			lastHeartbeat := toDeviceHeartbeatResponse(heartbeat)
+			item.LastHeartbeat = &lastHeartbeat
+		}
+		data = append(data, item)
+	}
+
+	return data, paging, nil
 }
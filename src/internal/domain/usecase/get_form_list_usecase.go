@@ -19,12 +19,13 @@ func (receiver *GetFormListUseCase) GetFormList(request request.GetFormListReque
 	var formList []response.GetFormListResponseData
 	for _, form := range forms {
 		formList = append(formList, response.GetFormListResponseData{
-			Id:          form.ID,
-			Spreadsheet: form.SpreadsheetUrl,
-			Password:    form.Password,
-			Note:        form.Note,
-			CreatedAt:   form.CreatedAt,
-			UpdatedAt:   form.UpdatedAt,
+			Id:             form.ID,
+			OrganizationId: form.OrganizationId,
+			Spreadsheet:    form.SpreadsheetUrl,
+			Password:       form.Password,
+			Note:           form.Note,
+			CreatedAt:      form.CreatedAt,
+			UpdatedAt:      form.UpdatedAt,
 		})
 	}
 
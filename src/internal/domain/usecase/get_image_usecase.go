@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/pkg/uploader"
 )
 
@@ -25,8 +26,10 @@ func (receiver *GetImageUseCase) GetImageById(id uint64) (*entity.SImage, error)
 	return receiver.ImageRepository.GetByID(id)
 }
 
-func (receiver *GetImageUseCase) GetUrlByKey(key string) (*string, error) {
-	img, err := receiver.ImageRepository.GetByKey(key)
+// GetUrlByKey signs the URL of an image of the organizations of the scope or of
+// a shared image
+func (receiver *GetImageUseCase) GetUrlByKey(scope value.AccessScope, key string) (*string, error) {
+	img, err := receiver.ImageRepository.Scoped(scope).GetByKey(key)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +51,7 @@ func (receiver *GetQuestionsByFormUseCase) GetQuestionByForm(form entity.SForm)
 			Order:          question.Order,
 			AnswerRequired: question.AnswerRequired,
 			Enabled:        question.EnableOnMobile == value.QuestionForMobile_Enabled,
+			Rules:          UnmarshalQuestionRules(question.Rules),
 		}
 
 		rawQuestions = append(rawQuestions, q)
@@ -71,7 +72,7 @@ func (receiver *GetQuestionsByFormUseCase) GetQuestionByForm(form entity.SForm)
 		// Check code counting & code generation
 		switch qType {
 		case value.QuestionCodeCounting:
-			q, err := receiver.BuildCodeCountingQuestion(rawQuestion)
+			q, err := receiver.BuildCodeCountingQuestion(form.OrganizationId, rawQuestion)
 			if err != nil {
 				return nil, &response.FailedResponse{
 					Code:  555,
@@ -123,6 +124,7 @@ func (receiver *GetQuestionsByFormUseCase) GetQuestionsByForm(form entity.SForm)
 			Order:          question.Order,
 			AnswerRequired: question.AnswerRequired,
 			Enabled:        question.EnableOnMobile == value.QuestionForMobile_Enabled,
+			Rules:          UnmarshalQuestionRules(question.Rules),
 		}
 
 		rawQuestions = append(rawQuestions, q)
@@ -147,15 +149,19 @@ func (receiver *GetQuestionsByFormUseCase) GetQuestionsByForm(form entity.SForm)
 	}
 }
 
-func (receiver *GetQuestionsByFormUseCase) BuildCodeCountingQuestion(question response.QuestionListData) (response.QuestionListData, error) {
+func (receiver *GetQuestionsByFormUseCase) BuildCodeCountingQuestion(organizationId *int64, question response.QuestionListData) (response.QuestionListData, error) {
 	var att response.QuestionAttributes
 	var attInJSONString string
 
-	newCodeCountingValue, err := receiver.CreateForQuestionWithID(question.QuestionId, receiver.DB)
+	newCodeCountingValue, err := receiver.CreateForQuestionWithID(question.QuestionId, organizationId, receiver.DB)
 	if err != nil {
 		log.Error(err)
 		return response.QuestionListData{}, err
 	}
+	PublishWebhookEvent(value.WebhookEvent_CodeCountingIncremented, map[string]interface{}{
+		"question_id": question.QuestionId,
+		"value":       newCodeCountingValue,
+	})
 	attInJSONString = `{"value": "` + newCodeCountingValue + `"}`
 
 	err = json.Unmarshal([]byte(attInJSONString), &att)
@@ -171,6 +177,7 @@ func (receiver *GetQuestionsByFormUseCase) BuildCodeCountingQuestion(question re
 		Order:          question.Order,
 		AnswerRequired: question.AnswerRequired,
 		Enabled:        question.Enabled,
+		Rules:          question.Rules,
 	}
 
 	return q, nil
@@ -196,6 +203,7 @@ func (receiver *GetQuestionsByFormUseCase) BuildRandomizerQuestion(question resp
 		Order:          question.Order,
 		AnswerRequired: question.AnswerRequired,
 		Enabled:        question.Enabled,
+		Rules:          question.Rules,
 	}
 
 	return q, nil
@@ -10,7 +10,7 @@ import (
 
 type GetRawQuestionFromSpreadsheetUseCase struct {
 	SpreadsheetId     string
-	SpreadsheetReader *sheet.Reader
+	SpreadsheetReader sheet.SpreadsheetReader
 }
 
 func (receiver *GetRawQuestionFromSpreadsheetUseCase) GetRawQuestions() ([]parameters.RawQuestion, error) {
@@ -1,14 +1,161 @@
 package usecase
 
 import (
+	"crypto/subtle"
+	"errors"
+	"hash/fnv"
+	"math/rand"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRedirectUrlPasswordRequired = errors.New("the redirect url requires a password")
+	ErrRedirectUrlWrongPassword    = errors.New("wrong redirect url password")
+	ErrRedirectUrlInactive         = errors.New("the redirect url is not active")
 )
 
 type GetRedirectUrlByQRCodeUseCase struct {
 	*repository.RedirectUrlRepository
+	RedirectUrlScanRepository *repository.RedirectUrlScanRepository
+	SessionRepository         *repository.SessionRepository
+	DB                        *gorm.DB
+}
+
+// RedirectUrlScanner is who resolves a QR code, the device and the user are
+// empty for the requests that are not authenticated
+type RedirectUrlScanner struct {
+	DeviceId  string
+	UserId    string
+	UserAgent string
+	IpAddress string
+}
+
+// pick chooses between the weighted targets of a code, a device or a user is
+// always sent to the same target
+func (scanner RedirectUrlScanner) pick(qrCode string) uint64 {
+	id := scanner.UserId
+	if id == "" {
+		id = scanner.DeviceId
+	}
+	if id == "" {
+		return rand.Uint64()
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(qrCode + ":" + id))
+	return hash.Sum64()
+}
+
+// Scanner reads the device or the user from the bearer token of authorization,
+// an invalid token counts as no token
+func (receiver *GetRedirectUrlByQRCodeUseCase) Scanner(authorization string, userAgent string, ipAddress string) RedirectUrlScanner {
+	scanner := RedirectUrlScanner{UserAgent: userAgent, IpAddress: ipAddress}
+	tokenString, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || receiver.SessionRepository == nil {
+		return scanner
+	}
+
+	if userId, err := receiver.SessionRepository.ExtractUserIdFromToken(tokenString); err == nil {
+		scanner.UserId = *userId
+	} else if deviceId, err := receiver.SessionRepository.ExtractDeviceIdFromToken(tokenString); err == nil && deviceId != nil {
+		scanner.DeviceId = *deviceId
+	}
+
+	return scanner
 }
 
-func (receiver *GetRedirectUrlByQRCodeUseCase) GetByQRCode(qrCode string) (*entity.SRedirectUrl, error) {
-	return receiver.RedirectUrlRepository.GetByQRCode(qrCode)
+// GetByQRCode resolves a QR code to the URL the scan goes to and records the
+// scan. Out of its validity window a redirect URL sends the scans to its
+// fallback URL, it fails with ErrRedirectUrlInactive when it has none. A redirect
+// URL with a password fails with ErrRedirectUrlPasswordRequired when no password
+// is given and with ErrRedirectUrlWrongPassword, recorded as a failed password,
+// when the password given is not its password.
+func (receiver *GetRedirectUrlByQRCodeUseCase) GetByQRCode(req request.GetRedirectUrlByQRCodeRequest, scanner RedirectUrlScanner) (*entity.SRedirectUrl, string, error) {
+	redirectUrl, err := receiver.RedirectUrlRepository.GetByQRCode(req.QRCode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		receiver.recordScan(req.QRCode, nil, value.RedirectUrlScanOutcome_NotFound, "", scanner)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !redirectUrl.IsActive(now) {
+		receiver.recordScan(req.QRCode, redirectUrl, value.RedirectUrlScanOutcome_Inactive, redirectUrl.FallbackUrl, scanner)
+		if redirectUrl.FallbackUrl == "" {
+			return nil, "", ErrRedirectUrlInactive
+		}
+		return redirectUrl, redirectUrl.FallbackUrl, nil
+	}
+
+	if err = checkRedirectUrlPassword(*redirectUrl, req.Password); err != nil {
+		if errors.Is(err, ErrRedirectUrlWrongPassword) {
+			receiver.recordScan(req.QRCode, redirectUrl, value.RedirectUrlScanOutcome_FailedPassword, "", scanner)
+		}
+		return redirectUrl, "", err
+	}
+
+	location := time.UTC
+	if receiver.DB != nil {
+		location = organizationLocation(receiver.DB, redirectUrl.OrganizationId)
+	}
+	targetUrl := redirectUrl.GetSchedule().Target(now.In(location), redirectUrl.TargetUrl, scanner.pick(redirectUrl.QRCode))
+	receiver.recordScan(req.QRCode, redirectUrl, value.RedirectUrlScanOutcome_Success, targetUrl, scanner)
+
+	PublishWebhookEvent(value.WebhookEvent_RedirectUrlScanned, map[string]interface{}{
+		"redirect_url_id": redirectUrl.ID,
+		"qr_code":         redirectUrl.QRCode,
+		"target_url":      targetUrl,
+	})
+
+	return redirectUrl, targetUrl, nil
+}
+
+// checkRedirectUrlPassword checks the password given against the password of
+// the redirect URL, a redirect URL without a password takes any
+func checkRedirectUrlPassword(redirectUrl entity.SRedirectUrl, password string) error {
+	if redirectUrl.Password == nil || *redirectUrl.Password == "" {
+		return nil
+	}
+	if password == "" {
+		return ErrRedirectUrlPasswordRequired
+	}
+	if subtle.ConstantTimeCompare([]byte(password), []byte(*redirectUrl.Password)) != 1 {
+		return ErrRedirectUrlWrongPassword
+	}
+
+	return nil
+}
+
+// recordScan logs the scan, a scan that cannot be recorded does not fail the
+// resolution
+func (receiver *GetRedirectUrlByQRCodeUseCase) recordScan(qrCode string, redirectUrl *entity.SRedirectUrl, outcome value.RedirectUrlScanOutcome, targetUrl string, scanner RedirectUrlScanner) {
+	if receiver.RedirectUrlScanRepository == nil {
+		return
+	}
+
+	scan := entity.SRedirectUrlScan{
+		QRCode:    qrCode,
+		Outcome:   outcome,
+		TargetUrl: targetUrl,
+		DeviceId:  scanner.DeviceId,
+		UserId:    scanner.UserId,
+		UserAgent: scanner.UserAgent,
+		IpAddress: scanner.IpAddress,
+	}
+	if redirectUrl != nil {
+		scan.RedirectUrlId = &redirectUrl.ID
+		scan.OrganizationId = redirectUrl.OrganizationId
+	}
+	if err := receiver.RedirectUrlScanRepository.Create(&scan); err != nil {
+		log.Error("GetRedirectUrlByQRCodeUseCase.recordScan ", qrCode, " ", err)
+	}
 }
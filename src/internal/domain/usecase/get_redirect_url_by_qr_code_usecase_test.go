@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"errors"
+	"sen-global-api/internal/domain/entity"
+	"testing"
+)
+
+func TestCheckRedirectUrlPassword(t *testing.T) {
+	password := "1234"
+	empty := ""
+
+	tests := []struct {
+		name     string
+		stored   *string
+		password string
+		want     error
+	}{
+		{"no password", nil, "", nil},
+		{"no password ignores the one given", nil, "1234", nil},
+		{"empty password", &empty, "", nil},
+		{"right password", &password, "1234", nil},
+		{"missing password", &password, "", ErrRedirectUrlPasswordRequired},
+		{"wrong password", &password, "4321", ErrRedirectUrlWrongPassword},
+		{"password prefix", &password, "123", ErrRedirectUrlWrongPassword},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkRedirectUrlPassword(entity.SRedirectUrl{Password: test.stored}, test.password)
+			if !errors.Is(err, test.want) {
+				t.Errorf("checkRedirectUrlPassword returned %v, want %v", err, test.want)
+			}
+		})
+	}
+}
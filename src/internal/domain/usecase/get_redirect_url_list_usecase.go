@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
 )
 
 type GetRedirectUrlListUseCase struct {
@@ -19,16 +21,37 @@ func (receiver *GetRedirectUrlListUseCase) GetList(req request.GetRedirectUrlLis
 	var urlListResponseData []response.GetRedirectUrlListResponseData
 	for _, url := range redirectUrls {
 		urlListResponseData = append(urlListResponseData, response.GetRedirectUrlListResponseData{
-			Id:           url.ID,
-			QRCode:       url.QRCode,
-			TargetUrl:    url.TargetUrl,
-			Password:     url.Password,
-			Hint:         url.Hint,
-			HashPassword: url.HashPassword,
-			CreatedAt:    url.CreatedAt,
-			UpdatedAt:    url.UpdatedAt,
+			Id:                      url.ID,
+			OrganizationId:          url.OrganizationId,
+			QRCode:                  url.QRCode,
+			TargetUrl:               url.TargetUrl,
+			Password:                url.Password,
+			Hint:                    url.Hint,
+			HashPassword:            url.HashPassword,
+			RedirectUrlScheduleData: ToRedirectUrlScheduleData(url),
+			CreatedAt:               url.CreatedAt,
+			UpdatedAt:               url.UpdatedAt,
 		})
 	}
 
 	return urlListResponseData, paging, nil
 }
+
+func ToRedirectUrlScheduleData(redirectUrl entity.SRedirectUrl) response.RedirectUrlScheduleData {
+	schedule := redirectUrl.GetSchedule()
+	data := response.RedirectUrlScheduleData{
+		ActiveFrom:  redirectUrl.ActiveFrom,
+		ActiveUntil: redirectUrl.ActiveUntil,
+		FallbackUrl: redirectUrl.FallbackUrl,
+		TimeRules:   schedule.TimeRules,
+		Targets:     schedule.Targets,
+	}
+	if data.TimeRules == nil {
+		data.TimeRules = make([]value.RedirectUrlTimeRule, 0)
+	}
+	if data.Targets == nil {
+		data.Targets = make([]value.RedirectUrlTarget, 0)
+	}
+
+	return data
+}
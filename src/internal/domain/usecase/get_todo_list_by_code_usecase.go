@@ -1,69 +1,75 @@
 package usecase
 
 import (
-	"errors"
-	"sen-global-api/config"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
-	"sen-global-api/internal/domain/value"
-	"sen-global-api/pkg/monitor"
-	"sen-global-api/pkg/sheet"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type GetToDoListByQRCodeUseCase struct {
 	*repository.ToDoRepository
-	r      *sheet.Reader
 	dbConn *gorm.DB
 }
 
-func NewGetToDoListByQRCodeUseCase(cfg config.AppConfig, db *gorm.DB, r *sheet.Reader) *GetToDoListByQRCodeUseCase {
+func NewGetToDoListByQRCodeUseCase(db *gorm.DB) *GetToDoListByQRCodeUseCase {
 	return &GetToDoListByQRCodeUseCase{
 		ToDoRepository: &repository.ToDoRepository{},
-		r:              r,
 		dbConn:         db,
 	}
 }
 
-func (c *GetToDoListByQRCodeUseCase) Execute(qrCode string) (entity.SToDo, error) {
-	todo, err := c.FindById(qrCode, c.dbConn)
+// Execute returns the list as the database holds it, the name of a compose list
+// is saved with its tasks. The tasks get their due time in the timezone of the
+// organization of the list. For a device the assigned tasks show as done when
+// they are done for the assignee the device completes them for, the tasks
+// assigned to others are left out.
+func (c *GetToDoListByQRCodeUseCase) Execute(qrCode string, deviceId string) (entity.SToDo, error) {
+	todo, err := c.GetToDoListByQRCode(qrCode, c.dbConn)
 	if err != nil {
-		return entity.SToDo{}, err
+		return todo, err
 	}
-
-	if todo == nil {
-		return entity.SToDo{}, errors.New("todo not found")
+	lists := []entity.SToDo{todo}
+	withDueTimes(c.dbConn, lists)
+	todo = lists[0]
+	if deviceId == "" {
+		return todo, nil
 	}
 
-	if todo.Type == value.ToDoTypeAssign || todo.SpreadsheetID == "" {
-		return c.GetToDoListByQRCode(qrCode, c.dbConn)
+	assignment, err := loadToDoAssignment(c.dbConn, c.ToDoRepository, todo)
+	if err != nil {
+		return todo, err
 	}
-
-	return c.getTasksByComposeTodo(*todo)
-}
-
-func (c *GetToDoListByQRCodeUseCase) getTasksByComposeTodo(todo entity.SToDo) (entity.SToDo, error) {
-	res, err := c.r.Get(sheet.ReadSpecificRangeParams{
-		SpreadsheetId: todo.SpreadsheetID,
-		ReadRange:     todo.SheetName + "!K11:K11",
-	})
-
+	progress, err := c.GetProgress(c.dbConn, todo.ID)
 	if err != nil {
-		monitor.SendMessageViaTelegram("Cannot retrieve todo name ", todo.ID, " err ", err.Error())
-		return c.GetToDoListByQRCode(todo.ID, c.dbConn)
+		return todo, err
 	}
+	done := toDoProgressByMember(progress)
 
-	todoName := ""
-	if len(res) > 0 {
-		todoName = res[0][0].(string)
-	}
+	tasks := make([]entity.Task, 0, len(todo.Tasks.Data.Tasks))
+	for _, task := range todo.Tasks.Data.Tasks {
+		if len(assignment.taskMembers(task)) == 0 {
+			tasks = append(tasks, task)
+			continue
+		}
+		member, ok := assignment.memberOfDevice(task, deviceId)
+		if !ok {
+			continue
+		}
 
-	todo.Name = todoName
-	_, err = c.Save(c.dbConn, &todo)
-	if err != nil {
-		return c.GetToDoListByQRCode(todo.ID, c.dbConn)
+		task.Selected = ""
+		task.CompletedAt = nil
+		task.CompletedBy = ""
+		if p, ok := done[toDoMemberKey(member.Type, member.Id)][task.Index]; ok {
+			completedAt := p.CompletedAt
+			task.Selected = p.Selected
+			task.CompletedAt = &completedAt
+			task.CompletedBy = p.DeviceId
+		}
+		tasks = append(tasks, task)
 	}
+	todo.Tasks = datatypes.JSONType[entity.STasks]{Data: entity.STasks{Tasks: tasks}}
 
-	return c.GetToDoListByQRCode(todo.ID, c.dbConn)
+	return todo, nil
 }
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidGuardianLink = errors.New("invalid guardian link")
+	ErrGuardianLinkExists  = errors.New("the guardian already has a pending or active link with the user")
+	ErrGuardianLinkClosed  = errors.New("the guardian link is not pending anymore")
+	ErrNotGuardian         = errors.New("the user is not an active guardian of the child")
+)
+
+// GuardianUseCase invites guardians, lets them confirm or decline and detaches
+// them, and gives the active guardians read access to the submissions, to-do
+// progress and devices of their children
+type GuardianUseCase struct {
+	GuardianRepository     *repository.GuardianRepository
+	ToDoRepository         *repository.ToDoRepository
+	DB                     *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func NewGuardianUseCase(db *gorm.DB, defaultRequestPageSize int) *GuardianUseCase {
+	return &GuardianUseCase{
+		GuardianRepository:     &repository.GuardianRepository{DBConn: db},
+		ToDoRepository:         &repository.ToDoRepository{},
+		DB:                     db,
+		DefaultRequestPageSize: defaultRequestPageSize,
+	}
+}
+
+// Invite creates a pending link between the child, userId, and the guardian of
+// req, the guardian has no access until they confirm it
+func (receiver *GuardianUseCase) Invite(userId string, req request.InviteGuardianRequest, invitedBy string) (*entity.SGuardianLink, error) {
+	relationship, err := value.GetGuardianRelationshipFromString(req.Relationship)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGuardianLink, err.Error())
+	}
+	if req.GuardianId == userId {
+		return nil, fmt.Errorf("%w: a user cannot be their own guardian", ErrInvalidGuardianLink)
+	}
+	if err := receiver.checkUser(userId); err != nil {
+		return nil, err
+	}
+	if err := receiver.checkUser(req.GuardianId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: guardian %s not found", ErrInvalidGuardianLink, req.GuardianId)
+		}
+		return nil, err
+	}
+
+	_, err = receiver.GuardianRepository.GetOpenLink(userId, req.GuardianId)
+	if err == nil {
+		return nil, ErrGuardianLinkExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	link := entity.SGuardianLink{
+		UserId:       userId,
+		GuardianId:   req.GuardianId,
+		Relationship: relationship,
+		Status:       value.GuardianLinkStatus_Pending,
+		InvitedBy:    invitedBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err = receiver.GuardianRepository.CreateLink(&link)
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// GetLinks lists the links of a child, userId, or of a guardian, guardianId
+func (receiver *GuardianUseCase) GetLinks(userId string, guardianId string, status *value.GuardianLinkStatus) ([]entity.SGuardianLink, error) {
+	return receiver.GuardianRepository.GetLinks(userId, guardianId, status)
+}
+
+// Confirm makes the pending link of the guardian active
+func (receiver *GuardianUseCase) Confirm(linkId uint64, guardianId string) (*entity.SGuardianLink, error) {
+	link, err := receiver.pendingLink(linkId, guardianId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.GuardianRepository.ConfirmLink(*link)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuardianLinkClosed
+		}
+		return nil, err
+	}
+
+	return receiver.GuardianRepository.GetLink(linkId)
+}
+
+// Decline closes the pending link of the guardian, the link is kept as declined
+func (receiver *GuardianUseCase) Decline(linkId uint64, guardianId string) (*entity.SGuardianLink, error) {
+	link, err := receiver.pendingLink(linkId, guardianId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.GuardianRepository.EndLink(*link, value.GuardianLinkStatus_Declined, guardianId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuardianLinkClosed
+		}
+		return nil, err
+	}
+
+	return receiver.GuardianRepository.GetLink(linkId)
+}
+
+// Detach closes the open link between the child and the guardian, a pending
+// link is cancelled and an active one detached. The link is kept as history.
+func (receiver *GuardianUseCase) Detach(userId string, guardianId string, endedBy string) (*entity.SGuardianLink, error) {
+	link, err := receiver.GuardianRepository.GetOpenLink(userId, guardianId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := value.GuardianLinkStatus_Detached
+	if link.Status == value.GuardianLinkStatus_Pending {
+		status = value.GuardianLinkStatus_Cancelled
+	}
+	err = receiver.GuardianRepository.EndLink(*link, status, endedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.GuardianRepository.GetLink(link.ID)
+}
+
+func (receiver *GuardianUseCase) GetChildren(guardianId string) ([]entity.SUserEntity, error) {
+	return receiver.GuardianRepository.GetChildren(guardianId)
+}
+
+// AuthorizeChild checks that the guardian is an active guardian of the child, an
+// empty child stands for all the children of the guardian
+func (receiver *GuardianUseCase) AuthorizeChild(guardianId string, userId string) error {
+	if userId == "" {
+		return nil
+	}
+	ok, err := receiver.GuardianRepository.IsGuardian(userId, guardianId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotGuardian
+	}
+
+	return nil
+}
+
+// GetSubmissions lists the submissions of the children of the guardian
+func (receiver *GuardianUseCase) GetSubmissions(guardianId string, req request.GetSubmissionListRequest) ([]response.SubmissionResponseData, *response.Pagination, error) {
+	if err := receiver.AuthorizeChild(guardianId, req.UserId); err != nil {
+		return nil, nil, err
+	}
+	req.GuardianId = guardianId
+
+	return NewSubmissionQueryUseCase(receiver.DB, receiver.DefaultRequestPageSize).GetSubmissions(req)
+}
+
+// GetToDoCompletions lists the tasks completed from the devices of the children
+// of the guardian
+func (receiver *GuardianUseCase) GetToDoCompletions(guardianId string, req request.GetToDoCompletionsRequest) ([]entity.SToDoCompletion, *response.Pagination, error) {
+	if err := receiver.AuthorizeChild(guardianId, req.UserId); err != nil {
+		return nil, nil, err
+	}
+	req.GuardianId = guardianId
+
+	return receiver.ToDoRepository.GetCompletions(receiver.DB, req, receiver.DefaultRequestPageSize)
+}
+
+// GetDevices lists the devices of the children of the guardian
+func (receiver *GuardianUseCase) GetDevices(guardianId string, userId string) ([]entity.SDevice, error) {
+	if err := receiver.AuthorizeChild(guardianId, userId); err != nil {
+		return nil, err
+	}
+
+	return receiver.GuardianRepository.GetChildrenDevices(guardianId, userId)
+}
+
+// pendingLink returns the link when it is a pending link of the guardian
+func (receiver *GuardianUseCase) pendingLink(linkId uint64, guardianId string) (*entity.SGuardianLink, error) {
+	link, err := receiver.GuardianRepository.GetLink(linkId)
+	if err != nil {
+		return nil, err
+	}
+	if link.GuardianId != guardianId {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if link.Status != value.GuardianLinkStatus_Pending {
+		return nil, ErrGuardianLinkClosed
+	}
+
+	return link, nil
+}
+
+func (receiver *GuardianUseCase) checkUser(userId string) error {
+	var user entity.SUserEntity
+	return receiver.DB.Select("id").Where("id = ?", userId).First(&user).Error
+}
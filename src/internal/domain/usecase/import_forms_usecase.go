@@ -37,14 +37,25 @@ type ImportFormsUseCase struct {
 	FormRepository                  *repository.FormRepository
 	QuestionRepository              *repository.QuestionRepository
 	FormQuestionRepository          *repository.FormQuestionRepository
-	SpreadsheetReader               *sheet.Reader
-	SpreadsheetWriter               *sheet.Writer
+	SpreadsheetReader               sheet.SpreadsheetReader
+	SpreadsheetWriter               sheet.SpreadsheetWriter
 	SettingRepository               *repository.SettingRepository
 	DefaultCronJobIntervalInMinutes uint8
 	TimeMachine                     *job.TimeMachine
+	FormRevisionUseCase             *FormRevisionUseCase
 	config.AppConfig
 }
 
+// ForOrganization returns the use case importing the forms of the organization
+// with its own form uploader settings
+func (receiver *ImportFormsUseCase) ForOrganization(organizationId *int64) *ImportFormsUseCase {
+	useCase := *receiver
+	useCase.FormRepository = receiver.FormRepository.ForOrganization(organizationId)
+	useCase.SettingRepository = receiver.SettingRepository.ForOrganization(organizationId)
+
+	return &useCase
+}
+
 func (receiver *ImportFormsUseCase) SyncForms(req request.ImportFormRequest) error {
 	monitor.SendMessageViaTelegram(fmt.Sprintf("[INFO][SYNC] Start sync Forms %s with interval %d", req.SpreadsheetUrl, req.Interval))
 	re := regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9-_]+)`)
@@ -335,6 +346,12 @@ func (receiver *ImportFormsUseCase) ImportForms(req request.ImportFormRequest, u
 		}
 	}
 
+	// The scheduled syncs run the global uploaders only, the uploaders of an
+	// organization are imported on request
+	if receiver.SettingRepository.OrganizationId != nil {
+		return nil
+	}
+
 	var interval uint64 = 0
 	if req.AutoImport {
 		interval = req.Interval
@@ -496,7 +513,7 @@ func (receiver *ImportFormsUseCase) importForm(code string, spreadsheetUrl strin
 	monitor.LogGoogleAPIRequestImportForm()
 	values, err := receiver.SpreadsheetReader.Get(sheet.ReadSpecificRangeParams{
 		SpreadsheetId: spreadsheetId,
-		ReadRange:     sheetNameToRead + `!I11:Q`,
+		ReadRange:     sheetNameToRead + `!I11:R`,
 	})
 	if err != nil || values == nil {
 		log.Error(err)
@@ -522,6 +539,10 @@ func (receiver *ImportFormsUseCase) importForm(code string, spreadsheetUrl strin
 			if strings.ToUpper(row[0].(string)) == "LOCK" {
 				enabled = value.QuestionForMobile_Disabled
 			}
+			rules := ""
+			if len(row) > 9 {
+				rules = row[9].(string)
+			}
 			item := parameters.RawQuestion{
 				// QuestionId:        strings.ToUpper(code) + "_" + spreadsheetId + "_" + row[2].(string),
 				QuestionId:        uuid.NewString(),
@@ -533,6 +554,7 @@ func (receiver *ImportFormsUseCase) importForm(code string, spreadsheetUrl strin
 				Status:            "1",
 				RowNumber:         index + 1,
 				EnableOnMobile:    enabled,
+				Rules:             rules,
 			}
 			rawQuestions = append(rawQuestions, item)
 		}
@@ -575,8 +597,31 @@ func (receiver *ImportFormsUseCase) saveForm(params parameters.SaveFormParams) (
 	log.Debug(questions)
 
 	form, err := receiver.createForm(questions, params)
+	if err != nil {
+		return form, reason, err
+	}
+
+	invalidRules, err := receiver.saveQuestionRules(form.ID, questions, params.RawQuestions)
+	if err != nil {
+		return form, "System Error: cannot save the rules of this form: " + params.Note, err
+	}
+	if len(invalidRules) > 0 {
+		reason += "Invalid rules: "
+		for _, rule := range invalidRules {
+			reason += "Row No:" + strconv.Itoa(rule.RowNumber) + ": " + rule.Reason + ", "
+		}
+	}
 
-	return form, reason, err
+	if receiver.FormRevisionUseCase == nil {
+		return form, reason, nil
+	}
+
+	_, err = receiver.FormRevisionUseCase.PublishCurrentQuestions(form.ID, "Imported from "+params.SpreadsheetUrl)
+	if err != nil {
+		return form, "System Error: cannot publish a revision of this form: " + params.Note, err
+	}
+
+	return form, reason, nil
 }
 
 type InvalidQuestionRow struct {
@@ -584,6 +629,82 @@ type InvalidQuestionRow struct {
 	Reason    string
 }
 
+// questionsFirstSheetRow is the sheet row of the first value read from a questions tab
+const questionsFirstSheetRow = 11
+
+// saveQuestionRules parses the rules column of the imported questions. Rules refer
+// to other questions by their sheet row, eg "14", or by their unique id.
+func (receiver *ImportFormsUseCase) saveQuestionRules(formID uint64, questions []entity.SQuestion, rawQuestions []parameters.RawQuestion) ([]InvalidQuestionRow, error) {
+	saved := make(map[string]bool, len(questions))
+	for _, question := range questions {
+		saved[question.QuestionId.String()] = true
+	}
+
+	references := make(map[string]string)
+	for _, rawQuestion := range rawQuestions {
+		if !saved[rawQuestion.QuestionId] {
+			continue
+		}
+		references[strconv.Itoa(rawQuestion.RowNumber+questionsFirstSheetRow-1)] = rawQuestion.QuestionId
+		if rawQuestion.QuestionUniqueId != nil && *rawQuestion.QuestionUniqueId != "" {
+			references[*rawQuestion.QuestionUniqueId] = rawQuestion.QuestionId
+		}
+	}
+	resolve := func(reference string) (string, bool) {
+		questionId, ok := references[reference]
+		return questionId, ok
+	}
+
+	invalidRules := make([]InvalidQuestionRow, 0)
+	hasRules := false
+	for _, rawQuestion := range rawQuestions {
+		if !saved[rawQuestion.QuestionId] || strings.TrimSpace(rawQuestion.Rules) == "" {
+			continue
+		}
+		sheetRow := rawQuestion.RowNumber + questionsFirstSheetRow - 1
+
+		rules, err := ParseQuestionRules(rawQuestion.Rules, resolve)
+		if err != nil {
+			invalidRules = append(invalidRules, InvalidQuestionRow{RowNumber: sheetRow, Reason: err.Error()})
+			continue
+		}
+
+		data, err := MarshalQuestionRules(rules)
+		if err != nil {
+			return nil, err
+		}
+		err = receiver.FormQuestionRepository.UpdateRules(formID, rawQuestion.QuestionId, data)
+		if err != nil {
+			return nil, err
+		}
+		hasRules = true
+	}
+
+	if !hasRules {
+		return invalidRules, nil
+	}
+
+	formQuestions, err := receiver.QuestionRepository.GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateQuestionRules(formQuestions)
+	if err != nil {
+		log.Info("Dropping the rules of form ", formID, ": ", err)
+		for _, question := range formQuestions {
+			if len(question.Rules) == 0 {
+				continue
+			}
+			if err := receiver.FormQuestionRepository.UpdateRules(formID, question.QuestionId, nil); err != nil {
+				return nil, err
+			}
+		}
+		invalidRules = append(invalidRules, InvalidQuestionRow{RowNumber: 0, Reason: err.Error()})
+	}
+
+	return invalidRules, nil
+}
+
 func (receiver *ImportFormsUseCase) saveQuestions(rawQuestions []parameters.RawQuestion) ([]entity.SQuestion, []InvalidQuestionRow, error) {
 	var params = make([]repository.CreateQuestionParams, 0)
 	var invalidQuestions = make([]InvalidQuestionRow, 0)
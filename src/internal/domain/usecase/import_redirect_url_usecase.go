@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"regexp"
 	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/pkg/job"
 	"sen-global-api/pkg/monitor"
 	"sen-global-api/pkg/sheet"
@@ -13,14 +15,26 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type ImportRedirectUrlsUseCase struct {
 	RedirectUrlRepository *repository.RedirectUrlRepository
-	SpreadsheetReader     *sheet.Reader
-	SpreadsheetWriter     *sheet.Writer
+	SpreadsheetReader     sheet.SpreadsheetReader
+	SpreadsheetWriter     sheet.SpreadsheetWriter
 	SettingRepository     *repository.SettingRepository
 	TimeMachine           *job.TimeMachine
+	DB                    *gorm.DB
+}
+
+// ForOrganization returns the use case importing the redirect URLs of the
+// organization with its own import setting
+func (receiver *ImportRedirectUrlsUseCase) ForOrganization(organizationId *int64) *ImportRedirectUrlsUseCase {
+	useCase := *receiver
+	useCase.RedirectUrlRepository = receiver.RedirectUrlRepository.ForOrganization(organizationId)
+	useCase.SettingRepository = receiver.SettingRepository.ForOrganization(organizationId)
+
+	return &useCase
 }
 
 func (receiver *ImportRedirectUrlsUseCase) SyncUrls(req request.ImportRedirectUrlsRequest) error {
@@ -39,15 +53,16 @@ func (receiver *ImportRedirectUrlsUseCase) SyncUrls(req request.ImportRedirectUr
 	spreadsheetId := match[1]
 	values, err := receiver.SpreadsheetReader.Get(sheet.ReadSpecificRangeParams{
 		SpreadsheetId: spreadsheetId,
-		ReadRange:     "URL_FORWARD!K12:O",
+		ReadRange:     "URL_FORWARD!K12:Y",
 	})
 	if err != nil {
 		log.Error(err)
 		return err
 	}
+	location := organizationLocation(receiver.DB, receiver.RedirectUrlRepository.OrganizationId)
 	for rowNo, row := range values {
 		if len(row) >= 5 && cap(row) >= 5 {
-			importErr := receiver.RedirectUrlRepository.SaveRedirectUrl(row[1].(string), row[2].(string), row[3].(string), row[4].(string), "", nil)
+			importErr := receiver.saveRow(row, location)
 			if importErr != nil {
 				log.Error(importErr)
 			} else {
@@ -87,24 +102,16 @@ func (receiver *ImportRedirectUrlsUseCase) Import(req request.ImportRedirectUrls
 	spreadsheetId := match[1]
 	values, err := receiver.SpreadsheetReader.Get(sheet.ReadSpecificRangeParams{
 		SpreadsheetId: spreadsheetId,
-		ReadRange:     "URL_FORWARD!K12:T",
+		ReadRange:     "URL_FORWARD!K12:Y",
 	})
 	if err != nil {
 		log.Error(err)
 		return err
 	}
+	location := organizationLocation(receiver.DB, receiver.RedirectUrlRepository.OrganizationId)
 	for rowNo, row := range values {
 		if len(row) >= 5 && cap(row) >= 5 && row[2].(string) != "" && strings.ToLower(row[4].(string)) == "upload" {
-			hint := ""
-			if len(row) > 8 {
-				hint = row[8].(string)
-			}
-			var hashPwd *string
-			if len(row) > 9 {
-				hash := row[9].(string)
-				hashPwd = &hash
-			}
-			importErr := receiver.RedirectUrlRepository.SaveRedirectUrl(row[1].(string), row[2].(string), row[3].(string), row[4].(string), hint, hashPwd)
+			importErr := receiver.saveRow(row, location)
 			if importErr != nil {
 				log.Error(importErr)
 			} else {
@@ -142,24 +149,16 @@ func (receiver *ImportRedirectUrlsUseCase) ImportPartially(url string, sheetName
 
 	values, err := receiver.SpreadsheetReader.Get(sheet.ReadSpecificRangeParams{
 		SpreadsheetId: spreadsheetId,
-		ReadRange:     sheetName + `!K12:T`,
+		ReadRange:     sheetName + `!K12:Y`,
 	})
 	if err != nil {
 		log.Error(err)
 		return err
 	}
+	location := organizationLocation(receiver.DB, receiver.RedirectUrlRepository.OrganizationId)
 	for rowNo, row := range values {
 		if len(row) >= 5 && cap(row) >= 5 {
-			hint := ""
-			if len(row) > 8 {
-				hint = row[8].(string)
-			}
-			var hashPwd *string
-			if len(row) > 9 {
-				hash := row[9].(string)
-				hashPwd = &hash
-			}
-			importErr := receiver.RedirectUrlRepository.SaveRedirectUrl(row[1].(string), row[2].(string), row[3].(string), row[4].(string), hint, hashPwd)
+			importErr := receiver.saveRow(row, location)
 			if importErr != nil {
 				log.Error(importErr)
 			} else {
@@ -178,3 +177,83 @@ func (receiver *ImportRedirectUrlsUseCase) ImportPartially(url string, sheetName
 
 	return nil
 }
+
+// saveRow saves a row of the redirect URL sheet read from column K: the QR code
+// in L, the target URL in M, the password in N, the status in O, the hint in S,
+// the hashed password in T, then the schedule: active from in U and until in V,
+// as "YYYY-MM-DD HH:MM" or "YYYY-MM-DD" in the timezone of the organization,
+// the fallback URL in W, the time rules in X and the weighted targets in Y.
+func (receiver *ImportRedirectUrlsUseCase) saveRow(row []interface{}, location *time.Location) error {
+	cell := func(i int) string {
+		if i >= len(row) {
+			return ""
+		}
+		text, _ := row[i].(string)
+		return text
+	}
+
+	redirectUrl := entity.SRedirectUrl{
+		QRCode:    cell(1),
+		TargetUrl: cell(2),
+		Hint:      cell(8),
+	}
+	if password := cell(3); password != "" {
+		redirectUrl.Password = &password
+	}
+	if len(row) > 9 {
+		hash := cell(9)
+		redirectUrl.HashPassword = &hash
+	}
+
+	activeFrom, err := parseRedirectUrlSheetTime(cell(10), location, false)
+	if err != nil {
+		return fmt.Errorf("%s: %w", redirectUrl.QRCode, err)
+	}
+	activeUntil, err := parseRedirectUrlSheetTime(cell(11), location, true)
+	if err != nil {
+		return fmt.Errorf("%s: %w", redirectUrl.QRCode, err)
+	}
+	timeRules, err := value.ParseRedirectUrlTimeRules(cell(13))
+	if err != nil {
+		return fmt.Errorf("%s: %w", redirectUrl.QRCode, err)
+	}
+	targets, err := value.ParseRedirectUrlTargets(cell(14))
+	if err != nil {
+		return fmt.Errorf("%s: %w", redirectUrl.QRCode, err)
+	}
+	err = applyRedirectUrlSchedule(&redirectUrl, request.RedirectUrlScheduleRequest{
+		ActiveFrom:  activeFrom,
+		ActiveUntil: activeUntil,
+		FallbackUrl: cell(12),
+		TimeRules:   timeRules,
+		Targets:     targets,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", redirectUrl.QRCode, err)
+	}
+
+	return receiver.RedirectUrlRepository.SaveRedirectUrl(redirectUrl, cell(4))
+}
+
+// parseRedirectUrlSheetTime reads a date of the sheet, a day with no time starts
+// at midnight or, for an end, ends at the next midnight
+func parseRedirectUrlSheetTime(cell string, location *time.Location, end bool) (*time.Time, error) {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if at, err := time.ParseInLocation(layout, cell, location); err == nil {
+			return &at, nil
+		}
+	}
+	at, err := time.ParseInLocation("2006-01-02", cell, location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD HH:MM", cell)
+	}
+	if end {
+		at = at.AddDate(0, 0, 1)
+	}
+
+	return &at, nil
+}
@@ -24,14 +24,14 @@ import (
 type ImportToDoListUseCase struct {
 	cfg               config.AppConfig
 	dbConn            *gorm.DB
-	reader            *sheet.Reader
-	writer            *sheet.Writer
+	reader            sheet.SpreadsheetReader
+	writer            sheet.SpreadsheetWriter
 	machine           *job.TimeMachine
 	settingRepository *repository.SettingRepository
 	todoRepository    *repository.ToDoRepository
 }
 
-func NewImportToDoListUseCase(cfg config.AppConfig, dbConn *gorm.DB, reader *sheet.Reader, writer *sheet.Writer, machine *job.TimeMachine) *ImportToDoListUseCase {
+func NewImportToDoListUseCase(cfg config.AppConfig, dbConn *gorm.DB, reader sheet.SpreadsheetReader, writer sheet.SpreadsheetWriter, machine *job.TimeMachine) *ImportToDoListUseCase {
 	return &ImportToDoListUseCase{
 		cfg:               cfg,
 		dbConn:            dbConn,
@@ -43,6 +43,16 @@ func NewImportToDoListUseCase(cfg config.AppConfig, dbConn *gorm.DB, reader *she
 	}
 }
 
+// ForOrganization returns the use case importing the to-do lists of the
+// organization with its own sync setting
+func (receiver *ImportToDoListUseCase) ForOrganization(organizationId *int64) *ImportToDoListUseCase {
+	useCase := *receiver
+	useCase.settingRepository = receiver.settingRepository.ForOrganization(organizationId)
+	useCase.todoRepository = &repository.ToDoRepository{OrganizationId: organizationId}
+
+	return &useCase
+}
+
 func (receiver *ImportToDoListUseCase) ImportToDoList(req request.ImportFormRequest) error {
 	monitor.SendMessageViaTelegram(fmt.Sprintf("[INFO][SYNC] Start sync ToDos with interval %d", req.Interval))
 	re := regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9-_]+)`)
@@ -252,9 +262,9 @@ func (receiver *ImportToDoListUseCase) importToDo(spreadsheetId string, tabName
 	todoList.StartRow = startRow
 	todoList.Tasks = datatypes.JSONType[entity.STasks]{Data: entity.STasks{Tasks: tasks}}
 
-	_, _ = receiver.todoRepository.Save(receiver.dbConn, todoList)
+	_, err = receiver.todoRepository.Import(receiver.dbConn, todoList)
 
-	return nil
+	return err
 }
 
 func (receiver *ImportToDoListUseCase) importToDoTypeCompose(qrCode, tabName, spreadsheetID string) error {
@@ -272,7 +282,7 @@ func (receiver *ImportToDoListUseCase) importToDoTypeCompose(qrCode, tabName, sp
 	todoList.StartRow = 13
 	todoList.Tasks = datatypes.JSONType[entity.STasks]{Data: entity.STasks{Tasks: tasks}}
 
-	_, err := receiver.todoRepository.Save(receiver.dbConn, todoList)
+	_, err := receiver.todoRepository.Import(receiver.dbConn, todoList)
 
 	return err
 }
@@ -1,20 +1,18 @@
 package infrastructure
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"sen-global-api/internal/domain/response"
 	"sen-global-api/pkg/monitor"
+	"sen-global-api/pkg/sheet"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/option"
 )
 
 // Helper function to check if an IP address is localhost
@@ -34,7 +32,9 @@ func isLocalhost(ip string) bool {
 	return false
 }
 
-func BackupDatabase() gin.HandlerFunc {
+// BackupDatabase dumps the database and uploads it to the file store of the
+// spreadsheets, in the SENBOX_BACKUP_GOOGLE_DRIVE_ID folder
+func BackupDatabase(files sheet.FileStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := c.ClientIP()
 		isLocal := isLocalhost(clientIP)
@@ -47,7 +47,7 @@ func BackupDatabase() gin.HandlerFunc {
 		}
 
 		defer func() {
-			Backup()
+			Backup(files)
 		}()
 
 		c.JSON(200, response.SucceedResponse{
@@ -57,7 +57,7 @@ func BackupDatabase() gin.HandlerFunc {
 	}
 }
 
-func Backup() {
+func Backup(files sheet.FileStore) {
 	//Remove old backup (SQL)
 	cmd := exec.Command("rm", "sen_master_db.sql")
 	err := cmd.Run()
@@ -104,20 +104,6 @@ func Backup() {
 		return
 	}
 
-	pwd, err := os.Getwd()
-	if err != nil {
-		monitor.SendMessageViaTelegram(fmt.Sprintf("Error getting current directory: %s", err))
-		return
-	}
-	srv, err := drive.NewService(context.Background(), option.WithCredentialsFile(pwd+"/credentials/google_service_account.json"))
-
-	if err != nil {
-		monitor.SendMessageViaTelegram(
-			"[URGENT] Error when establishing Google Drive service for uploading backup: " + err.Error(),
-		)
-		return
-	}
-
 	file, err := os.Open("sen_master_db.tar.gz")
 	if err != nil {
 		log.Errorf("Error: %v", err)
@@ -132,20 +118,11 @@ func Backup() {
 	nowInString := now.Format("2006-01-02 15:04:05")
 	driveID := os.Getenv("SENBOX_BACKUP_GOOGLE_DRIVE_ID")
 
-	if driveID == "" {
-		log.Error("Error: SENBOX_BACKUP_GOOGLE_DRIVE_ID is empty")
-		monitor.SendMessageViaTelegram(
-			"[URGENT] Error when backup database: SENBOX_BACKUP_GOOGLE_DRIVE_ID is empty",
-		)
-		return
-	}
-
-	f := &drive.File{
-		Name:    nowInString + "_sen_master_db.tar.gz",
-		Parents: []string{driveID},
-	}
-	_, err = srv.Files.Create(f).Media(file).Do()
+	err = files.Upload(nowInString+"_sen_master_db.tar.gz", driveID, file)
 	if err != nil {
+		if driveID == "" {
+			err = fmt.Errorf("SENBOX_BACKUP_GOOGLE_DRIVE_ID is empty: %w", err)
+		}
 		log.Errorf("Error: %v", err)
 		monitor.SendMessageViaTelegram(
 			"[URGENT] Error when upload database: " + err.Error(),
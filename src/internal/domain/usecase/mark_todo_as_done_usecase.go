@@ -2,13 +2,11 @@ package usecase
 
 import (
 	"errors"
-	"sen-global-api/config"
+	"fmt"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/value"
-	"sen-global-api/pkg/sheet"
-	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,128 +15,132 @@ import (
 
 type MarkToDoAsDoneUseCase struct {
 	*repository.ToDoRepository
-	*sheet.Reader
-	*sheet.Writer
 	dbConn *gorm.DB
-	cfg    config.AppConfig
 }
 
+// Execute marks the task as done in the database and adds the next occurrence of
+// a recurring task, the task sheet and the history sheet are written by sheet
+// syncs. An assigned task is done for the assignee the device completes it for
+// only, ErrToDoNotAssigned when the device has none; a task assigned to nobody
+// is done for everybody.
 func (c *MarkToDoAsDoneUseCase) Execute(device entity.SDevice, code string, index int, selectValue string) error {
-	todoList, err := c.GetToDoListByQRCode(code, c.dbConn)
-	if err != nil {
-		return err
-	}
-
-	values, err := c.Get(sheet.ReadSpecificRangeParams{
-		SpreadsheetId: todoList.SpreadsheetID,
-		ReadRange:     todoList.SheetName + `!K12:K1000`,
-	})
-
-	if err != nil {
-		log.Error(err)
-		return err
-	}
-
+	now := time.Now()
 	completedTask := entity.Task{}
-	updatedTasks := make([]entity.Task, 0)
-	for _, task := range todoList.Tasks.Data.Tasks {
-		if task.Index == index {
-			completedTask = task
-			task.Selected = selectValue
+	member := toDoMember{Type: value.ToDoAssigneeType_Device, Id: device.ID}
+	assigned := false
+	recurred := false
+	todoList, err := c.UpdateToDo(c.dbConn, code, func(list *entity.SToDo) error {
+		assignment, err := loadToDoAssignment(c.dbConn, c.ToDoRepository, *list)
+		if err != nil {
+			return err
 		}
-		updatedTasks = append(updatedTasks, task)
-	}
-	todoList.Tasks.Data.Tasks = updatedTasks
-	_, _ = c.Save(c.dbConn, &todoList)
 
-	log.Info("completedTask: ", completedTask)
+		tasks := list.Tasks.Data.Tasks
+		for i := range tasks {
+			if tasks[i].Index != index {
+				continue
+			}
+			assigned = len(assignment.taskMembers(tasks[i])) > 0
+			if assigned {
+				var ok bool
+				member, ok = assignment.memberOfDevice(tasks[i], device.ID)
+				if !ok {
+					return fmt.Errorf("%w: device %s, task %d", ErrToDoNotAssigned, device.ID, index)
+				}
+			} else {
+				tasks[i].Selected = selectValue
+				tasks[i].CompletedAt = &now
+				tasks[i].CompletedBy = device.ID
+			}
+			completedTask = tasks[i]
+
+			next, ok := nextOccurrence(tasks[i], nextTaskIndex(tasks), organizationLocation(c.dbConn, list.OrganizationId), now)
+			if ok {
+				tasks[i].NextIndex = &next.Index
+				list.Tasks.Data.Tasks = append(tasks, next)
+				list.TasksEditedAt = &now
+				recurred = true
+			}
+			return nil
+		}
 
-	completedRowNo, err := findFirstRow(strconv.Itoa(index), values, 12)
+		return fmt.Errorf("%w: %d", ErrToDoTaskNotFound, index)
+	})
 	if err != nil {
 		return err
 	}
 
-	completedData := make([][]interface{}, 0)
-	completedData = append(completedData, []interface{}{selectValue})
-	completedData = append(completedData, []interface{}{time.Now().Format("2006-01-02 15:04:05")})
-	completedData = append(completedData, []interface{}{selectValue})
-	completedData = append(completedData, []interface{}{nil})
-	completedData = append(completedData, []interface{}{nil})
-	completedData = append(completedData, []interface{}{nil})
-	completedData = append(completedData, []interface{}{device.ID})
-	_, err = c.UpdateRange(sheet.WriteRangeParams{
-		Range:     todoList.SheetName + "!P" + strconv.Itoa(completedRowNo) + ":W",
-		Dimension: "COLUMNS",
-		Rows:      completedData,
-	}, todoList.SpreadsheetID)
+	err = c.SaveProgress(c.dbConn, &entity.SToDoTaskProgress{
+		ToDoId:       todoList.ID,
+		TaskIndex:    index,
+		AssigneeType: member.Type,
+		AssigneeId:   member.Id,
+		Selected:     selectValue,
+		DeviceId:     device.ID,
+		CompletedAt:  now,
+	})
+	if err != nil {
+		log.Error("Unable to record ToDo progress: ", todoList.ID, err)
+	}
 
+	err = c.CreateCompletion(c.dbConn, &entity.SToDoCompletion{
+		ToDoId:       todoList.ID,
+		TaskIndex:    index,
+		TaskName:     completedTask.Name,
+		Selected:     selectValue,
+		DeviceId:     device.ID,
+		AssigneeType: member.Type,
+		AssigneeId:   member.Id,
+		CompletedAt:  now,
+	})
 	if err != nil {
-		log.Error("Unable to write to sheet ToDo: ", todoList.SpreadsheetID, err)
-		return err
+		log.Error("Unable to record ToDo completion: ", todoList.ID, err)
 	}
 
-	//TODO: Write Todo History
-	historyData := make([][]interface{}, 0)
-	historyData = append(historyData, []interface{}{time.Now().Format("2006-01-02 15:04:05")})
-	historyData = append(historyData, []interface{}{device.ID})
-	historyData = append(historyData, []interface{}{device.DeviceName})
-	historyData = append(historyData, []interface{}{device.Note})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{todoList.ID})
-	historyData = append(historyData, []interface{}{todoList.SheetName})
-	historyData = append(historyData, []interface{}{"https://docs.google.com/spreadsheets/d/" + todoList.SpreadsheetID})
-	historyData = append(historyData, []interface{}{completedTask.Name})
-	historyData = append(historyData, []interface{}{completedTask.DueDate})
-	historyData = append(historyData, []interface{}{completedTask.Value})
-	historyData = append(historyData, []interface{}{selectValue})
-
-	_, err = c.WriteRanges(sheet.WriteRangeParams{
-		Range:     todoList.HistorySheetName + "!K11",
-		Dimension: "COLUMNS",
-		Rows:      historyData,
-	}, todoList.HistorySpreadsheetID)
+	PublishWebhookEvent(value.WebhookEvent_TodoTaskCompleted, map[string]interface{}{
+		"todo_id":        todoList.ID,
+		"task_index":     index,
+		"task_name":      completedTask.Name,
+		"selected_value": selectValue,
+		"device_id":      device.ID,
+		"assignee_type":  member.Type,
+		"assignee_id":    member.Id,
+	})
 
-	if err != nil {
-		log.Error("Write Todo History Error: ", todoList.HistorySpreadsheetID, err)
+	EnqueueToDoSheetSync(*todoList, value.ToDoSheetSyncKind_Completion, ToDoCompletionSyncPayload{
+		TaskIndex:   index,
+		TaskName:    completedTask.Name,
+		DueDate:     completedTask.DueDate,
+		Value:       completedTask.Value,
+		Selected:    selectValue,
+		DeviceId:    device.ID,
+		DeviceName:  device.DeviceName,
+		DeviceNote:  device.Note,
+		CompletedAt: now,
+		HistoryOnly: assigned,
+	})
+	if recurred {
+		EnqueueToDoSheetSync(*todoList, value.ToDoSheetSyncKind_Tasks, nil)
 	}
 
 	return nil
 }
 
-func NewMarkToDoAsDoneUseCase(cfg config.AppConfig, dbConn *gorm.DB, reader *sheet.Reader, writer *sheet.Writer) *MarkToDoAsDoneUseCase {
+func NewMarkToDoAsDoneUseCase(dbConn *gorm.DB) *MarkToDoAsDoneUseCase {
 	return &MarkToDoAsDoneUseCase{
 		ToDoRepository: &repository.ToDoRepository{},
-		Reader:         reader,
-		Writer:         writer,
 		dbConn:         dbConn,
-		cfg:            cfg,
-	}
-}
-
-func findFirstRow(id string, values [][]interface{}, startRow int) (int, error) {
-	rowNo := 0
-	for rowindex, row := range values {
-		if len(row) > 0 {
-			if row[0].(string) == id {
-				return rowindex + startRow, nil
-			}
-		}
 	}
-	return rowNo, errors.New("Cannot determine row number for todo index: " + id)
 }
 
+// LogTask records a change of a task made on a device in the history sheet, by
+// a sheet sync
 func (c *MarkToDoAsDoneUseCase) LogTask(req request.LogTaskRequest, device entity.SDevice) error {
 	todo, err := c.FindById(req.ToDoID, c.dbConn)
 	if err != nil {
 		return err
 	}
 
-	if todo == nil {
-		return errors.New("todo not found")
-	}
-
 	if todo.Type != value.ToDoTypeCompose {
 		return errors.New("invalid todo type")
 	}
@@ -147,38 +149,16 @@ func (c *MarkToDoAsDoneUseCase) LogTask(req request.LogTaskRequest, device entit
 		return errors.New("todo's history sheet was not set up")
 	}
 
-	historyData := make([][]interface{}, 0)
-	historyData = append(historyData, []interface{}{time.Now().Format("2006-01-02 15:04:05")})
-	historyData = append(historyData, []interface{}{device.ID})
-	historyData = append(historyData, []interface{}{device.DeviceName})
-	historyData = append(historyData, []interface{}{device.Note})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{nil})
-	historyData = append(historyData, []interface{}{todo.ID})
-	historyData = append(historyData, []interface{}{todo.SheetName})
-	historyData = append(historyData, []interface{}{"https://docs.google.com/spreadsheets/d/" + todo.SpreadsheetID})
-	historyData = append(historyData, []interface{}{req.Name})
-	historyData = append(historyData, []interface{}{req.DueDate})
-	historyData = append(historyData, []interface{}{req.Value})
-	historyData = append(historyData, []interface{}{""})
-
-	switch req.LogType {
-	case request.LogTaskType_Create:
-		historyData = append(historyData, []interface{}{"created"})
-	case request.LogTaskType_Update:
-		historyData = append(historyData, []interface{}{"updated"})
-	case request.LogTaskType_Deleted:
-		historyData = append(historyData, []interface{}{"deleted"})
-	}
-
-	historyData = append(historyData, []interface{}{""})
-
-	_, err = c.WriteRanges(sheet.WriteRangeParams{
-		Range:     todo.HistorySheetName + "!K11",
-		Dimension: "COLUMNS",
-		Rows:      historyData,
-	}, todo.HistorySpreadsheetID)
+	EnqueueToDoSheetSync(*todo, value.ToDoSheetSyncKind_TaskLog, ToDoTaskLogSyncPayload{
+		Name:       req.Name,
+		DueDate:    req.DueDate,
+		Value:      req.Value,
+		LogType:    req.LogType,
+		DeviceId:   device.ID,
+		DeviceName: device.DeviceName,
+		DeviceNote: device.Note,
+		LoggedAt:   time.Now(),
+	})
 
-	return err
+	return nil
 }
@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sen-global-api/config"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/randx"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	organizationInvitationCodeLength  = 16
+	organizationInvitationLifetime    = 7 * 24 * time.Hour
+	organizationInvitationMaxLifetime = 90 * 24 * time.Hour
+	organizationInvitationMaxUses     = 1000
+	// OrganizationInvitationLinkPrefix starts the link of an invitation, the code
+	// is its code query parameter
+	OrganizationInvitationLinkPrefix = "senbox://invite"
+)
+
+var (
+	ErrInvalidInvitation     = errors.New("invalid invitation")
+	ErrInvitationUnavailable = errors.New("the invitation is unknown, revoked, declined, expired or used up")
+	ErrInvitationForSomeone  = errors.New("the invitation is for another email address")
+	ErrAlreadyMember         = errors.New("the user is already a member of the organization")
+	ErrOwnerMembership       = errors.New("the owner of the organization cannot be suspended or removed, transfer the ownership first")
+	ErrOwnershipRequired     = errors.New("only the owner of the organization can transfer it")
+	ErrInvalidOwner          = errors.New("the new owner must be an active member of the organization")
+)
+
+// OrganizationMembershipUseCase issues the invitations of the organizations, lets
+// users accept or decline them and manages the members and the owner of the
+// organizations
+type OrganizationMembershipUseCase struct {
+	OrganizationRepository           *repository.OrganizationRepository
+	OrganizationInvitationRepository *repository.OrganizationInvitationRepository
+	PermissionRepository             *repository.PermissionRepository
+	UserEntityRepository             *repository.UserEntityRepository
+	SendEmailUseCase                 *SendEmailUseCase
+	DB                               *gorm.DB
+}
+
+func NewOrganizationMembershipUseCase(db *gorm.DB, smtpConfig config.SMTPConfig) *OrganizationMembershipUseCase {
+	return &OrganizationMembershipUseCase{
+		OrganizationRepository:           &repository.OrganizationRepository{DBConn: db},
+		OrganizationInvitationRepository: &repository.OrganizationInvitationRepository{DBConn: db},
+		PermissionRepository:             &repository.PermissionRepository{DBConn: db},
+		UserEntityRepository:             &repository.UserEntityRepository{DBConn: db},
+		SendEmailUseCase:                 &SendEmailUseCase{SMTPConfig: smtpConfig},
+		DB:                               db,
+	}
+}
+
+// CreateInvitation issues an invitation to the organization and returns it with
+// the code in clear, which is not kept and cannot be shown again. The code of an
+// invitation for an email is only sent to that email, as the emails of the users
+// are not verified, and no code is returned for it
+func (receiver *OrganizationMembershipUseCase) CreateInvitation(organizationId int64, req request.CreateOrganizationInvitationRequest, createdBy string) (*entity.SOrganizationInvitation, string, error) {
+	now := time.Now()
+	invitation := entity.SOrganizationInvitation{
+		OrganizationId: organizationId,
+		Email:          strings.ToLower(strings.TrimSpace(req.Email)),
+		MaxUses:        req.MaxUses,
+		ExpiresAt:      now.Add(organizationInvitationLifetime),
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if invitation.Email != "" {
+		if _, err := mail.ParseAddress(invitation.Email); err != nil {
+			return nil, "", fmt.Errorf("%w: invalid email", ErrInvalidInvitation)
+		}
+		if invitation.MaxUses > 1 {
+			return nil, "", fmt.Errorf("%w: an invitation for an email can only be used once", ErrInvalidInvitation)
+		}
+	}
+	if invitation.MaxUses == 0 {
+		invitation.MaxUses = 1
+	}
+	if invitation.MaxUses > organizationInvitationMaxUses {
+		return nil, "", fmt.Errorf("%w: max_uses is %d at most", ErrInvalidInvitation, organizationInvitationMaxUses)
+	}
+	if req.ExpiresAt != nil {
+		invitation.ExpiresAt = *req.ExpiresAt
+	}
+	if !invitation.ExpiresAt.After(now) || invitation.ExpiresAt.Sub(now) > organizationInvitationMaxLifetime {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the next %d days", ErrInvalidInvitation, int(organizationInvitationMaxLifetime.Hours()/24))
+	}
+
+	organization, err := receiver.OrganizationRepository.FindByID(organizationId)
+	if err != nil {
+		return nil, "", err
+	}
+	roleIds := make([]int64, 0, len(req.RoleIds))
+	for _, roleId := range req.RoleIds {
+		roleOrganizationId, err := receiver.PermissionRepository.GetRoleOrganizationId(roleId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "", fmt.Errorf("%w: role %d not found", ErrInvalidInvitation, roleId)
+			}
+			return nil, "", err
+		}
+		if roleOrganizationId != organizationId {
+			return nil, "", fmt.Errorf("%w: role %d is not a role of the organization", ErrInvalidInvitation, roleId)
+		}
+		roleIds = append(roleIds, roleId)
+	}
+	invitation.RoleIds, err = json.Marshal(roleIds)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := randx.MustString(organizationInvitationCodeLength, enrollmentCodeRunes)
+	invitation.CodeHash = hashEnrollmentCode(secret)
+	invitation.CodeHint = secret[len(secret)-4:]
+	err = receiver.OrganizationInvitationRepository.CreateInvitation(&invitation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	code := formatEnrollmentCode(secret)
+	if invitation.Email == "" {
+		return &invitation, code, nil
+	}
+
+	subject, body := organizationInvitationEmail(organization.OrganizationName, code)
+	err = receiver.SendEmailUseCase.SendMessage(subject, []string{invitation.Email}, body)
+	if err != nil {
+		// Nobody holds the code of an invitation that was not sent
+		_ = receiver.OrganizationInvitationRepository.RevokeInvitation(invitation.ID)
+		return nil, "", err
+	}
+
+	return &invitation, "", nil
+}
+
+func (receiver *OrganizationMembershipUseCase) GetInvitation(id uint64) (*entity.SOrganizationInvitation, error) {
+	return receiver.OrganizationInvitationRepository.GetInvitation(id)
+}
+
+func (receiver *OrganizationMembershipUseCase) GetInvitations(organizationId int64, req request.GetOrganizationInvitationsRequest) ([]entity.SOrganizationInvitation, error) {
+	return receiver.OrganizationInvitationRepository.GetInvitations(organizationId, req.Active)
+}
+
+func (receiver *OrganizationMembershipUseCase) RevokeInvitation(id uint64) (*entity.SOrganizationInvitation, error) {
+	err := receiver.OrganizationInvitationRepository.RevokeInvitation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.OrganizationInvitationRepository.GetInvitation(id)
+}
+
+func (receiver *OrganizationMembershipUseCase) GetReplies(invitationId uint64) ([]entity.SOrganizationInvitationReply, error) {
+	return receiver.OrganizationInvitationRepository.GetReplies(invitationId)
+}
+
+// PreviewInvitation returns what the invitation of the code invites the user to
+func (receiver *OrganizationMembershipUseCase) PreviewInvitation(code string, userId string) (*response.OrganizationInvitationPreviewResponseData, error) {
+	user, err := receiver.UserEntityRepository.GetByID(request.GetUserEntityByIdRequest{ID: userId})
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := receiver.openInvitation(receiver.OrganizationInvitationRepository, code, *user)
+	if err != nil {
+		return nil, err
+	}
+	organization, err := receiver.OrganizationRepository.FindByID(invitation.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]string, 0)
+	roleIds := InvitationRoleIds(*invitation)
+	if len(roleIds) > 0 {
+		err = receiver.DB.Table("s_role").Where("id IN ?", roleIds).Order("role_name ASC").Pluck("role_name", &roles).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &response.OrganizationInvitationPreviewResponseData{
+		OrganizationId:   organization.ID,
+		OrganizationName: organization.OrganizationName,
+		Email:            invitation.Email,
+		Roles:            roles,
+		ExpiresAt:        invitation.ExpiresAt,
+	}, nil
+}
+
+// AcceptInvitation takes one use of the invitation of the code and makes the user
+// an active member of its organization with the roles of the invitation. The
+// roles that were deleted since the invitation was issued are left out.
+func (receiver *OrganizationMembershipUseCase) AcceptInvitation(code string, userId string) (*entity.SOrganizationInvitation, error) {
+	user, err := receiver.UserEntityRepository.GetByID(request.GetUserEntityByIdRequest{ID: userId})
+	if err != nil {
+		return nil, err
+	}
+
+	var invitation *entity.SOrganizationInvitation
+	err = receiver.DB.Transaction(func(tx *gorm.DB) error {
+		invitationRepository := &repository.OrganizationInvitationRepository{DBConn: tx}
+		organizationRepository := &repository.OrganizationRepository{DBConn: tx}
+
+		var err error
+		invitation, err = receiver.openInvitation(invitationRepository, code, *user)
+		if err != nil {
+			return err
+		}
+		_, err = organizationRepository.GetMember(invitation.OrganizationId, user.ID.String())
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		err = invitationRepository.RedeemInvitation(invitation.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvitationUnavailable
+			}
+			return err
+		}
+		roleIds := make([]int64, 0)
+		if invitationRoleIds := InvitationRoleIds(*invitation); len(invitationRoleIds) > 0 {
+			err = tx.Table("s_role").Where("id IN ? AND organization_id = ?", invitationRoleIds, invitation.OrganizationId).Pluck("id", &roleIds).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = organizationRepository.AddMember(invitation.OrganizationId, user.ID, &invitation.ID, roleIds)
+		if err != nil {
+			return err
+		}
+
+		return invitationRepository.SaveReply(invitation.ID, user.ID.String(), value.InvitationReply_Accepted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// DeclineInvitation records that the user declined the invitation of the code.
+// An invitation for the email of the user is closed, other invitations stay open
+// for the other users.
+func (receiver *OrganizationMembershipUseCase) DeclineInvitation(code string, userId string) (*entity.SOrganizationInvitation, error) {
+	user, err := receiver.UserEntityRepository.GetByID(request.GetUserEntityByIdRequest{ID: userId})
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := receiver.openInvitation(receiver.OrganizationInvitationRepository, code, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.OrganizationInvitationRepository.SaveReply(invitation.ID, user.ID.String(), value.InvitationReply_Declined)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Email != "" {
+		err = receiver.OrganizationInvitationRepository.CloseInvitation(invitation.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return invitation, nil
+}
+
+func (receiver *OrganizationMembershipUseCase) GetOrganization(organizationId int64) (*entity.SOrganization, error) {
+	return receiver.OrganizationRepository.FindByID(organizationId)
+}
+
+func (receiver *OrganizationMembershipUseCase) GetMember(organizationId int64, userId string) (*entity.SUsersOrganization, error) {
+	return receiver.OrganizationRepository.GetMember(organizationId, userId)
+}
+
+// GetMembers lists the members of the organization, only those in the status
+// when one is given
+func (receiver *OrganizationMembershipUseCase) GetMembers(organizationId int64, status *value.MembershipStatus) ([]entity.SUsersOrganization, error) {
+	return receiver.OrganizationRepository.GetMembers(organizationId, status)
+}
+
+// SetMemberStatus suspends or reinstates a member, the owner cannot be suspended
+func (receiver *OrganizationMembershipUseCase) SetMemberStatus(organizationId int64, userId string, status value.MembershipStatus) (*entity.SUsersOrganization, error) {
+	if status == value.MembershipStatus_Suspended {
+		if err := receiver.checkNotOwner(organizationId, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	err := receiver.OrganizationRepository.SetMemberStatus(organizationId, userId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.OrganizationRepository.GetMember(organizationId, userId)
+}
+
+// RemoveMember takes a member out of the organization with their roles of the
+// organization, the owner cannot be removed
+func (receiver *OrganizationMembershipUseCase) RemoveMember(organizationId int64, userId string) error {
+	if err := receiver.checkNotOwner(organizationId, userId); err != nil {
+		return err
+	}
+
+	return receiver.OrganizationRepository.RemoveMember(organizationId, userId)
+}
+
+// TransferOwnership makes an active member the owner of the organization. Only
+// the owner or a scope over every organization can transfer it.
+func (receiver *OrganizationMembershipUseCase) TransferOwnership(scope value.AccessScope, organizationId int64, userId string) (*entity.SOrganization, error) {
+	organization, err := receiver.OrganizationRepository.FindByID(organizationId)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllOrganizations && (organization.OwnerId == "" || organization.OwnerId != scope.UserId) {
+		return nil, ErrOwnershipRequired
+	}
+
+	member, err := receiver.OrganizationRepository.GetMember(organizationId, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOwner
+		}
+		return nil, err
+	}
+	if member.Status != value.MembershipStatus_Active {
+		return nil, ErrInvalidOwner
+	}
+
+	err = receiver.OrganizationRepository.SetOwner(organizationId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.OrganizationRepository.FindByID(organizationId)
+}
+
+// RetirePassword clears the shared password of the organization so it can only
+// be joined with an invitation
+func (receiver *OrganizationMembershipUseCase) RetirePassword(organizationId int64) error {
+	_, err := receiver.OrganizationRepository.FindByID(organizationId)
+	if err != nil {
+		return err
+	}
+
+	return receiver.OrganizationRepository.RetirePassword(organizationId)
+}
+
+// openInvitation returns the invitation of the code when it can still be used by
+// the user. The code of an invitation for an email only went to that email, the
+// email of the user must match it as well
+func (receiver *OrganizationMembershipUseCase) openInvitation(invitationRepository *repository.OrganizationInvitationRepository, code string, user entity.SUserEntity) (*entity.SOrganizationInvitation, error) {
+	secret := parseInvitationCode(code)
+	if len(secret) != organizationInvitationCodeLength {
+		return nil, fmt.Errorf("%w: malformed code", ErrInvalidInvitation)
+	}
+
+	invitation, err := invitationRepository.GetInvitationByHash(hashEnrollmentCode(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationUnavailable
+		}
+		return nil, err
+	}
+	if !invitation.IsActive(time.Now()) {
+		return nil, ErrInvitationUnavailable
+	}
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, strings.TrimSpace(user.Email)) {
+		return nil, ErrInvitationForSomeone
+	}
+
+	return invitation, nil
+}
+
+func (receiver *OrganizationMembershipUseCase) checkNotOwner(organizationId int64, userId string) error {
+	organization, err := receiver.OrganizationRepository.FindByID(organizationId)
+	if err != nil {
+		return err
+	}
+	if organization.OwnerId == userId {
+		return ErrOwnerMembership
+	}
+
+	return nil
+}
+
+// InvitationRoleIds returns the roles an invitation gives
+func InvitationRoleIds(invitation entity.SOrganizationInvitation) []int64 {
+	roleIds := make([]int64, 0)
+	if len(invitation.RoleIds) > 0 {
+		_ = json.Unmarshal(invitation.RoleIds, &roleIds)
+	}
+
+	return roleIds
+}
+
+// organizationInvitationEmail returns the subject and the body of the email that
+// sends the code of an invitation to its recipient
+func organizationInvitationEmail(organizationName string, code string) (string, string) {
+	subject := "Invitation to join " + organizationName
+	body := fmt.Sprintf("You are invited to join %s.\r\n\r\nOpen %s or enter the code %s in the app to answer the invitation.\r\n", organizationName, OrganizationInvitationLink(code), code)
+
+	return subject, body
+}
+
+// OrganizationInvitationLink returns the link users open to answer an invitation
+func OrganizationInvitationLink(code string) string {
+	return OrganizationInvitationLinkPrefix + "?" + url.Values{"code": {code}}.Encode()
+}
+
+// parseInvitationCode returns the code of an invitation link or of a code typed
+// in, without its separators
+func parseInvitationCode(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.HasPrefix(code, OrganizationInvitationLinkPrefix) {
+		link, err := url.Parse(code)
+		if err != nil {
+			return ""
+		}
+		code = link.Query().Get("code")
+	}
+
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/value"
+	"strings"
+
+	"gorm.io/datatypes"
+)
+
+var ErrInvalidQuestionRule = errors.New("invalid question rule")
+
+// questionRuleEnd is the skip_to target that jumps past the last question
+const questionRuleEnd = "end"
+
+// ParseQuestionRules reads the rules column of a questions sheet. Rules are
+// separated by ";" or new lines and look like
+//
+//	show_if 14 equals Yes
+//	skip_to 20 if not_answered
+//	skip_to end if in No,Never
+//
+// resolve turns the question reference (the sheet row or unique id of a question)
+// into a question id.
+func ParseQuestionRules(raw string, resolve func(reference string) (string, bool)) ([]entity.QuestionRule, error) {
+	rules := make([]entity.QuestionRule, 0)
+	for _, line := range strings.FieldsFunc(raw, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidQuestionRule, line)
+		}
+
+		rule := entity.QuestionRule{Action: value.QuestionRuleAction(strings.ToLower(fields[0]))}
+		reference := fields[1]
+		rest := fields[2:]
+
+		switch rule.Action {
+		case value.QuestionRuleAction_ShowIf:
+			questionId, ok := resolve(reference)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown question %s in %q", ErrInvalidQuestionRule, reference, line)
+			}
+			rule.Question = questionId
+		case value.QuestionRuleAction_SkipTo:
+			if !strings.EqualFold(reference, questionRuleEnd) {
+				questionId, ok := resolve(reference)
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown question %s in %q", ErrInvalidQuestionRule, reference, line)
+				}
+				rule.Target = questionId
+			}
+			if strings.EqualFold(rest[0], "if") {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return nil, fmt.Errorf("%w: missing operator in %q", ErrInvalidQuestionRule, line)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown action %s", ErrInvalidQuestionRule, fields[0])
+		}
+
+		operator, err := value.GetQuestionRuleOperatorFromString(rest[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidQuestionRule, err.Error())
+		}
+		rule.Operator = operator
+		rule.Value = strings.Join(rest[1:], " ")
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func UnmarshalQuestionRules(data datatypes.JSON) []entity.QuestionRule {
+	rules := make([]entity.QuestionRule, 0)
+	if len(data) == 0 {
+		return rules
+	}
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return make([]entity.QuestionRule, 0)
+	}
+
+	return rules
+}
+
+func MarshalQuestionRules(rules []entity.QuestionRule) (datatypes.JSON, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(rules)
+}
+
+// ValidateQuestionRules checks the rules of every question of a form. A show_if
+// rule may only look at an earlier question and a skip_to rule may only jump forward.
+func ValidateQuestionRules(questions []model.FormQuestionItem) error {
+	positions := make(map[string]int, len(questions))
+	for i, question := range questions {
+		positions[question.QuestionId] = i
+	}
+
+	for i, question := range questions {
+		for _, rule := range UnmarshalQuestionRules(question.Rules) {
+			if err := validateQuestionRule(rule, i, positions); err != nil {
+				return fmt.Errorf("question %s: %w", question.QuestionId, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateQuestionRule(rule entity.QuestionRule, position int, positions map[string]int) error {
+	if _, err := value.GetQuestionRuleOperatorFromString(string(rule.Operator)); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuestionRule, err.Error())
+	}
+
+	switch rule.Action {
+	case value.QuestionRuleAction_ShowIf:
+		source, ok := positions[rule.Question]
+		if !ok {
+			return fmt.Errorf("%w: show_if refers to question %s which is not part of the form", ErrInvalidQuestionRule, rule.Question)
+		}
+		if source >= position {
+			return fmt.Errorf("%w: show_if refers to question %s which does not come before it", ErrInvalidQuestionRule, rule.Question)
+		}
+	case value.QuestionRuleAction_SkipTo:
+		if rule.Target == "" {
+			return nil
+		}
+		target, ok := positions[rule.Target]
+		if !ok {
+			return fmt.Errorf("%w: skip_to refers to question %s which is not part of the form", ErrInvalidQuestionRule, rule.Target)
+		}
+		if target <= position {
+			return fmt.Errorf("%w: skip_to refers to question %s which does not come after it", ErrInvalidQuestionRule, rule.Target)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %s", ErrInvalidQuestionRule, rule.Action)
+	}
+
+	return nil
+}
+
+// HiddenQuestions walks the questions in form order and returns the ids of the
+// questions the rules hide for the given answers.
+func HiddenQuestions(questions []model.FormQuestionItem, answers map[string]string) map[string]bool {
+	hidden := make(map[string]bool)
+	skipping := false
+	skipTarget := ""
+
+	for _, question := range questions {
+		if skipping {
+			if skipTarget != "" && question.QuestionId == skipTarget {
+				skipping = false
+			} else {
+				hidden[question.QuestionId] = true
+				continue
+			}
+		}
+
+		rules := UnmarshalQuestionRules(question.Rules)
+		visible := true
+		for _, rule := range rules {
+			if rule.Action != value.QuestionRuleAction_ShowIf {
+				continue
+			}
+			answer, answered := visibleAnswer(rule.Question, answers, hidden)
+			if !matchesQuestionRule(rule, answer, answered) {
+				visible = false
+				break
+			}
+		}
+		if !visible {
+			hidden[question.QuestionId] = true
+			continue
+		}
+
+		answer, answered := visibleAnswer(question.QuestionId, answers, hidden)
+		for _, rule := range rules {
+			if rule.Action == value.QuestionRuleAction_SkipTo && matchesQuestionRule(rule, answer, answered) {
+				skipping = true
+				skipTarget = rule.Target
+				break
+			}
+		}
+	}
+
+	return hidden
+}
+
+func visibleAnswer(questionId string, answers map[string]string, hidden map[string]bool) (string, bool) {
+	if hidden[questionId] {
+		return "", false
+	}
+
+	answer, ok := answers[questionId]
+	answer = strings.TrimSpace(answer)
+
+	return answer, ok && answer != ""
+}
+
+func matchesQuestionRule(rule entity.QuestionRule, answer string, answered bool) bool {
+	expected := strings.TrimSpace(rule.Value)
+	switch rule.Operator {
+	case value.QuestionRuleOperator_Equals:
+		return answered && strings.EqualFold(answer, expected)
+	case value.QuestionRuleOperator_NotEquals:
+		return !answered || !strings.EqualFold(answer, expected)
+	case value.QuestionRuleOperator_Contains:
+		return answered && strings.Contains(strings.ToLower(answer), strings.ToLower(expected))
+	case value.QuestionRuleOperator_In:
+		if !answered {
+			return false
+		}
+		for _, option := range strings.Split(expected, ",") {
+			if strings.EqualFold(answer, strings.TrimSpace(option)) {
+				return true
+			}
+		}
+		return false
+	case value.QuestionRuleOperator_Answered:
+		return answered
+	case value.QuestionRuleOperator_NotAnswered:
+		return !answered
+	default:
+		return false
+	}
+}
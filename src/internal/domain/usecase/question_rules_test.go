@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/model"
+	"sen-global-api/internal/domain/value"
+	"testing"
+)
+
+func resolveRow(reference string) (string, bool) {
+	ids := map[string]string{"12": "q12", "14": "q14", "20": "q20", "intro": "q-intro"}
+	id, ok := ids[reference]
+	return id, ok
+}
+
+func TestParseQuestionRules(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []entity.QuestionRule
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: []entity.QuestionRule{},
+		},
+		{
+			name: "show_if with a value of several words",
+			raw:  "show_if 14 equals Not at all",
+			want: []entity.QuestionRule{
+				{Action: value.QuestionRuleAction_ShowIf, Question: "q14", Operator: value.QuestionRuleOperator_Equals, Value: "Not at all"},
+			},
+		},
+		{
+			name: "skip_to with and without if",
+			raw:  "skip_to 20 if not_answered;SKIP_TO intro answered",
+			want: []entity.QuestionRule{
+				{Action: value.QuestionRuleAction_SkipTo, Target: "q20", Operator: value.QuestionRuleOperator_NotAnswered},
+				{Action: value.QuestionRuleAction_SkipTo, Target: "q-intro", Operator: value.QuestionRuleOperator_Answered},
+			},
+		},
+		{
+			name: "skip_to end on new lines",
+			raw:  "show_if 12 answered\n\nskip_to END if in No,Never",
+			want: []entity.QuestionRule{
+				{Action: value.QuestionRuleAction_ShowIf, Question: "q12", Operator: value.QuestionRuleOperator_Answered},
+				{Action: value.QuestionRuleAction_SkipTo, Operator: value.QuestionRuleOperator_In, Value: "No,Never"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseQuestionRules(test.raw, resolveRow)
+			if err != nil {
+				t.Fatalf("ParseQuestionRules(%q) returned %v", test.raw, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseQuestionRules(%q) = %+v, want %+v", test.raw, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseQuestionRulesErrors(t *testing.T) {
+	tests := []string{
+		"show_if 14",
+		"hide_if 14 equals Yes",
+		"show_if 99 equals Yes",
+		"skip_to 99 if answered",
+		"skip_to end if",
+		"show_if 14 greater 3",
+	}
+
+	for _, raw := range tests {
+		t.Run(raw, func(t *testing.T) {
+			if got, err := ParseQuestionRules(raw, resolveRow); !errors.Is(err, ErrInvalidQuestionRule) {
+				t.Errorf("ParseQuestionRules(%q) = %+v, %v, want %v", raw, got, err, ErrInvalidQuestionRule)
+			}
+		})
+	}
+}
+
+func formQuestion(t *testing.T, id string, rules ...entity.QuestionRule) model.FormQuestionItem {
+	t.Helper()
+
+	data, err := MarshalQuestionRules(rules)
+	if err != nil {
+		t.Fatalf("MarshalQuestionRules returned %v", err)
+	}
+
+	return model.FormQuestionItem{QuestionId: id, Rules: data}
+}
+
+func TestValidateQuestionRules(t *testing.T) {
+	showIf := func(question string) entity.QuestionRule {
+		return entity.QuestionRule{Action: value.QuestionRuleAction_ShowIf, Question: question, Operator: value.QuestionRuleOperator_Answered}
+	}
+	skipTo := func(target string) entity.QuestionRule {
+		return entity.QuestionRule{Action: value.QuestionRuleAction_SkipTo, Target: target, Operator: value.QuestionRuleOperator_Answered}
+	}
+
+	tests := []struct {
+		name  string
+		rules [3][]entity.QuestionRule
+		valid bool
+	}{
+		{"no rules", [3][]entity.QuestionRule{}, true},
+		{"show_if on an earlier question", [3][]entity.QuestionRule{nil, nil, {showIf("q1")}}, true},
+		{"skip_to a later question and to the end", [3][]entity.QuestionRule{{skipTo("q3")}, {skipTo("")}}, true},
+		{"show_if on itself", [3][]entity.QuestionRule{nil, {showIf("q2")}}, false},
+		{"show_if on a later question", [3][]entity.QuestionRule{{showIf("q3")}}, false},
+		{"show_if on a question of another form", [3][]entity.QuestionRule{nil, {showIf("q9")}}, false},
+		{"skip_to backwards", [3][]entity.QuestionRule{nil, nil, {skipTo("q1")}}, false},
+		{"unknown operator", [3][]entity.QuestionRule{nil, {{Action: value.QuestionRuleAction_ShowIf, Question: "q1", Operator: "greater"}}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			questions := []model.FormQuestionItem{
+				formQuestion(t, "q1", test.rules[0]...),
+				formQuestion(t, "q2", test.rules[1]...),
+				formQuestion(t, "q3", test.rules[2]...),
+			}
+			err := ValidateQuestionRules(questions)
+			if test.valid && err != nil {
+				t.Errorf("ValidateQuestionRules returned %v", err)
+			}
+			if !test.valid && !errors.Is(err, ErrInvalidQuestionRule) {
+				t.Errorf("ValidateQuestionRules returned %v, want %v", err, ErrInvalidQuestionRule)
+			}
+		})
+	}
+}
+
+func TestHiddenQuestions(t *testing.T) {
+	questions := []model.FormQuestionItem{
+		formQuestion(t, "q1",
+			entity.QuestionRule{Action: value.QuestionRuleAction_SkipTo, Operator: value.QuestionRuleOperator_Equals, Value: "stop"},
+			entity.QuestionRule{Action: value.QuestionRuleAction_SkipTo, Target: "q4", Operator: value.QuestionRuleOperator_In, Value: "No, Never"},
+		),
+		formQuestion(t, "q2"),
+		formQuestion(t, "q3",
+			entity.QuestionRule{Action: value.QuestionRuleAction_ShowIf, Question: "q2", Operator: value.QuestionRuleOperator_Contains, Value: "pain"},
+		),
+		formQuestion(t, "q4",
+			entity.QuestionRule{Action: value.QuestionRuleAction_ShowIf, Question: "q3", Operator: value.QuestionRuleOperator_NotAnswered},
+		),
+		formQuestion(t, "q5",
+			entity.QuestionRule{Action: value.QuestionRuleAction_ShowIf, Question: "q2", Operator: value.QuestionRuleOperator_Answered},
+			entity.QuestionRule{Action: value.QuestionRuleAction_ShowIf, Question: "q3", Operator: value.QuestionRuleOperator_NotEquals, Value: "skip"},
+		),
+	}
+
+	tests := []struct {
+		name    string
+		answers map[string]string
+		want    map[string]bool
+	}{
+		{
+			name:    "nothing answered",
+			answers: map[string]string{},
+			want:    map[string]bool{"q3": true, "q5": true},
+		},
+		{
+			name:    "show_if matches case insensitively",
+			answers: map[string]string{"q1": "Yes", "q2": "Back PAIN", "q3": "skip"},
+			want:    map[string]bool{"q4": true, "q5": true},
+		},
+		{
+			name:    "skip_to a question from an in list",
+			answers: map[string]string{"q1": "never", "q2": "pain", "q3": "a lot"},
+			want:    map[string]bool{"q2": true, "q3": true, "q5": true},
+		},
+		{
+			name:    "skip_to end hides the rest",
+			answers: map[string]string{"q1": "STOP", "q2": "pain"},
+			want:    map[string]bool{"q2": true, "q3": true, "q4": true, "q5": true},
+		},
+		{
+			name:    "answers of hidden questions do not count",
+			answers: map[string]string{"q1": "No", "q2": "pain", "q3": "skip"},
+			want:    map[string]bool{"q2": true, "q3": true, "q5": true},
+		},
+		{
+			name:    "blank answers are not answered",
+			answers: map[string]string{"q2": "  "},
+			want:    map[string]bool{"q3": true, "q5": true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := HiddenQuestions(questions, test.answers)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("HiddenQuestions(%v) = %v, want %v", test.answers, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// redirectUrlAnalyticsDays is how far back the analytics go without a from
+	redirectUrlAnalyticsDays      = 30
+	redirectUrlAnalyticsTopCodes  = 10
+	redirectUrlAnalyticsMaxLimit  = 100
+	redirectUrlScanExportBatch    = 500
+	redirectUrlScanReportTimeline = "timeline"
+	redirectUrlScanReportScans    = "scans"
+)
+
+var ErrInvalidRedirectUrlAnalytics = errors.New("invalid redirect url analytics request")
+
+// RedirectUrlAnalyticsUseCase reports the scans of the redirect URLs recorded by
+// GetRedirectUrlByQRCodeUseCase
+type RedirectUrlAnalyticsUseCase struct {
+	RedirectUrlScanRepository *repository.RedirectUrlScanRepository
+}
+
+// GetAnalytics reports the scans of the repository: the scans of each code by
+// period, the most scanned codes and the last failed password attempts. Limit
+// bounds the top codes and the failed attempts.
+func (receiver *RedirectUrlAnalyticsUseCase) GetAnalytics(req request.GetRedirectUrlAnalyticsRequest) (*response.RedirectUrlAnalyticsResponseData, error) {
+	filter, interval, err := redirectUrlScanFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = redirectUrlAnalyticsTopCodes
+	}
+	if limit > redirectUrlAnalyticsMaxLimit {
+		limit = redirectUrlAnalyticsMaxLimit
+	}
+
+	timeline, err := receiver.timeline(filter, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode, err := receiver.RedirectUrlScanRepository.CountByCode(filter)
+	if err != nil {
+		return nil, err
+	}
+	var totals response.RedirectUrlScanCountData
+	codes := make(map[string]*response.RedirectUrlTopCodeData)
+	for _, count := range byCode {
+		code, ok := codes[count.QRCode]
+		if !ok {
+			code = &response.RedirectUrlTopCodeData{QRCode: count.QRCode}
+			codes[count.QRCode] = code
+		}
+		if count.LastScannedAt != nil && (code.LastScannedAt == nil || count.LastScannedAt.After(*code.LastScannedAt)) {
+			code.LastScannedAt = count.LastScannedAt
+		}
+		addScanCount(&code.RedirectUrlScanCountData, count)
+		addScanCount(&totals, count)
+	}
+	topCodes := make([]response.RedirectUrlTopCodeData, 0, len(codes))
+	for _, code := range codes {
+		topCodes = append(topCodes, *code)
+	}
+	sort.Slice(topCodes, func(i, j int) bool {
+		if topCodes[i].Scans != topCodes[j].Scans {
+			return topCodes[i].Scans > topCodes[j].Scans
+		}
+		return topCodes[i].QRCode < topCodes[j].QRCode
+	})
+	if len(topCodes) > limit {
+		topCodes = topCodes[:limit]
+	}
+
+	failed, err := receiver.RedirectUrlScanRepository.GetScans(filter, value.RedirectUrlScanOutcome_FailedPassword, limit)
+	if err != nil {
+		return nil, err
+	}
+	failedPasswords := make([]response.RedirectUrlScanData, 0, len(failed))
+	for _, scan := range failed {
+		failedPasswords = append(failedPasswords, toRedirectUrlScanData(scan))
+	}
+
+	byTarget, err := receiver.RedirectUrlScanRepository.CountByTarget(filter)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]response.RedirectUrlTargetData, 0, len(byTarget))
+	for _, count := range byTarget {
+		targets = append(targets, response.RedirectUrlTargetData{QRCode: count.QRCode, TargetUrl: count.TargetUrl, Scans: count.Count})
+	}
+
+	return &response.RedirectUrlAnalyticsResponseData{
+		From:            *filter.From,
+		To:              *filter.To,
+		Interval:        string(interval),
+		Totals:          totals,
+		Timeline:        timeline,
+		TopCodes:        topCodes,
+		FailedPasswords: failedPasswords,
+		Targets:         targets,
+	}, nil
+}
+
+// RedirectUrlScanExport is a CSV export of the scans, the timeline of the codes
+// or the scans one by one
+type RedirectUrlScanExport struct {
+	Report     string
+	filter     repository.RedirectUrlScanFilter
+	interval   value.ScanInterval
+	repository *repository.RedirectUrlScanRepository
+	analytics  *RedirectUrlAnalyticsUseCase
+}
+
+// PrepareExport checks the export request, report is timeline (the default) or
+// scans
+func (receiver *RedirectUrlAnalyticsUseCase) PrepareExport(req request.ExportRedirectUrlAnalyticsRequest) (*RedirectUrlScanExport, error) {
+	filter, interval, err := redirectUrlScanFilter(req.GetRedirectUrlAnalyticsRequest)
+	if err != nil {
+		return nil, err
+	}
+	report := req.Report
+	switch report {
+	case "":
+		report = redirectUrlScanReportTimeline
+	case redirectUrlScanReportTimeline, redirectUrlScanReportScans:
+	default:
+		return nil, fmt.Errorf("%w: invalid report %s, expected timeline or scans", ErrInvalidRedirectUrlAnalytics, report)
+	}
+
+	return &RedirectUrlScanExport{
+		Report:     report,
+		filter:     filter,
+		interval:   interval,
+		repository: receiver.RedirectUrlScanRepository,
+		analytics:  receiver,
+	}, nil
+}
+
+func (receiver *RedirectUrlScanExport) FileName() string {
+	return fmt.Sprintf("redirect-url-%s-%s-%s.csv", receiver.Report,
+		receiver.filter.From.Format("2006-01-02"), receiver.filter.To.AddDate(0, 0, -1).Format("2006-01-02"))
+}
+
+func (receiver *RedirectUrlScanExport) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+// Write streams the export to w, the scans report is flushed after every batch
+func (receiver *RedirectUrlScanExport) Write(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if receiver.Report == redirectUrlScanReportScans {
+		err := writer.Write([]string{"scanned_at", "qr_code", "outcome", "target_url", "redirect_url_id", "device_id", "user_id", "user_agent", "ip_address"})
+		if err != nil {
+			return err
+		}
+		err = receiver.repository.FindScansInBatches(receiver.filter, redirectUrlScanExportBatch, func(scans []entity.SRedirectUrlScan) error {
+			for _, scan := range scans {
+				redirectUrlId := ""
+				if scan.RedirectUrlId != nil {
+					redirectUrlId = strconv.FormatUint(*scan.RedirectUrlId, 10)
+				}
+				err := writer.Write([]string{scan.CreatedAt.Format(time.RFC3339), scan.QRCode, string(scan.Outcome), scan.TargetUrl, redirectUrlId, scan.DeviceId, scan.UserId, scan.UserAgent, scan.IpAddress})
+				if err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			flushExport(w)
+
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		timeline, err := receiver.analytics.timeline(receiver.filter, receiver.interval)
+		if err != nil {
+			return err
+		}
+		err = writer.Write([]string{"period", "qr_code", "scans", "successes", "failed_passwords", "not_found", "inactive"})
+		if err != nil {
+			return err
+		}
+		for _, period := range timeline {
+			err = writer.Write([]string{
+				period.Period,
+				period.QRCode,
+				strconv.FormatInt(period.Scans, 10),
+				strconv.FormatInt(period.Successes, 10),
+				strconv.FormatInt(period.FailedPasswords, 10),
+				strconv.FormatInt(period.NotFound, 10),
+				strconv.FormatInt(period.Inactive, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// timeline counts the scans of each code by period, the periods are named after
+// their first day
+func (receiver *RedirectUrlAnalyticsUseCase) timeline(filter repository.RedirectUrlScanFilter, interval value.ScanInterval) ([]response.RedirectUrlScanPeriodData, error) {
+	counts, err := receiver.RedirectUrlScanRepository.CountByDay(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make(map[string]*response.RedirectUrlScanPeriodData)
+	keys := make([]string, 0)
+	for _, count := range counts {
+		if len(count.Day) < 10 {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", count.Day[:10])
+		if err != nil {
+			continue
+		}
+		period := interval.Start(day).Format("2006-01-02")
+		key := period + "\x00" + count.QRCode
+		data, ok := periods[key]
+		if !ok {
+			data = &response.RedirectUrlScanPeriodData{Period: period, QRCode: count.QRCode}
+			periods[key] = data
+			keys = append(keys, key)
+		}
+		addScanCount(&data.RedirectUrlScanCountData, count)
+	}
+	sort.Strings(keys)
+
+	timeline := make([]response.RedirectUrlScanPeriodData, 0, len(keys))
+	for _, key := range keys {
+		timeline = append(timeline, *periods[key])
+	}
+
+	return timeline, nil
+}
+
+// redirectUrlScanFilter reads the period of the request, the last 30 days when
+// it has no from. To includes its whole day.
+func redirectUrlScanFilter(req request.GetRedirectUrlAnalyticsRequest) (repository.RedirectUrlScanFilter, value.ScanInterval, error) {
+	interval, err := value.GetScanIntervalFromString(req.Interval)
+	if err != nil {
+		return repository.RedirectUrlScanFilter{}, "", fmt.Errorf("%w: %s", ErrInvalidRedirectUrlAnalytics, err.Error())
+	}
+
+	to := req.To
+	if to.IsZero() {
+		now := time.Now()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	to = to.AddDate(0, 0, 1)
+	from := req.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -redirectUrlAnalyticsDays)
+	}
+	if !from.Before(to) {
+		return repository.RedirectUrlScanFilter{}, "", fmt.Errorf("%w: from must not be after to", ErrInvalidRedirectUrlAnalytics)
+	}
+
+	return repository.RedirectUrlScanFilter{QRCode: req.QRCode, From: &from, To: &to}, interval, nil
+}
+
+func addScanCount(data *response.RedirectUrlScanCountData, count repository.RedirectUrlScanCount) {
+	data.Scans += count.Count
+	switch count.Outcome {
+	case value.RedirectUrlScanOutcome_Success:
+		data.Successes += count.Count
+	case value.RedirectUrlScanOutcome_FailedPassword:
+		data.FailedPasswords += count.Count
+	case value.RedirectUrlScanOutcome_NotFound:
+		data.NotFound += count.Count
+	case value.RedirectUrlScanOutcome_Inactive:
+		data.Inactive += count.Count
+	}
+}
+
+func toRedirectUrlScanData(scan entity.SRedirectUrlScan) response.RedirectUrlScanData {
+	return response.RedirectUrlScanData{
+		Id:            scan.ID,
+		RedirectUrlId: scan.RedirectUrlId,
+		QRCode:        scan.QRCode,
+		Outcome:       string(scan.Outcome),
+		TargetUrl:     scan.TargetUrl,
+		DeviceId:      scan.DeviceId,
+		UserId:        scan.UserId,
+		UserAgent:     scan.UserAgent,
+		IpAddress:     scan.IpAddress,
+		CreatedAt:     scan.CreatedAt,
+	}
+}
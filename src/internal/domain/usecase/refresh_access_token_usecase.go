@@ -8,9 +8,23 @@ import (
 type RefreshAccessTokenUseCase struct {
 	*repository.SessionRepository
 	*repository.DeviceRepository
+	SessionUseCase *SessionUseCase
 }
 
-func (c *RefreshAccessTokenUseCase) Execute(refreshToken string) (string, string, error) {
+// Execute exchanges a refresh token for a new access token and refresh token.
+// The refresh tokens of the user sessions are rotated, the JWT refresh tokens
+// of the devices start a new session of the device, which revokes the one of
+// the exchanged token.
+func (c *RefreshAccessTokenUseCase) Execute(refreshToken string, userAgent string, ipAddress string) (string, string, error) {
+	if c.SessionUseCase != nil && c.IsSessionRefreshToken(refreshToken) {
+		token, err := c.SessionUseCase.Refresh(refreshToken, userAgent, ipAddress)
+		if err != nil {
+			return "", "", err
+		}
+
+		return token.Token, token.RefreshToken, nil
+	}
+
 	deviceID, err := c.GetDeviceIDFromRefreshToken(refreshToken)
 	if err != nil {
 		return "", "", err
@@ -25,7 +39,7 @@ func (c *RefreshAccessTokenUseCase) Execute(refreshToken string) (string, string
 		return "", "", err
 	}
 
-	accessToken, refreshToken, err := c.GenerateTokenByDevice(*device)
+	accessToken, refreshToken, err := c.GenerateTokenByDevice(*device, userAgent, ipAddress)
 	if err != nil {
 		return "", "", err
 	}
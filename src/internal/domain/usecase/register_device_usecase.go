@@ -14,8 +14,8 @@ type RegisterDeviceUseCase struct {
 	*repository.DeviceRepository
 	*repository.SessionRepository
 	*repository.SettingRepository
-	*sheet.Writer
-	*sheet.Reader
+	sheet.SpreadsheetWriter
+	sheet.SpreadsheetReader
 }
 
 func (receiver *RegisterDeviceUseCase) RegisterDevice(user *entity.SUserEntity, req request.RegisterDeviceRequest) (*string, error) {
@@ -19,6 +19,13 @@ func NewResetCodeCountingUseCase(db *gorm.DB) *ResetCodeCountingUseCase {
 	}
 }
 
-func (receiver *ResetCodeCountingUseCase) Execute(req request.ResetCodeCountingRequest) error {
-	return receiver.ResetCodeCounting(req, receiver.DB)
+// Execute resets the counter of the organization of the user, the shared counter
+// for a user of none or several organizations
+func (receiver *ResetCodeCountingUseCase) Execute(userId string, req request.ResetCodeCountingRequest) error {
+	organizationId, err := (&repository.PermissionRepository{DBConn: receiver.DB}).GetUserOrganizationId(userId)
+	if err != nil {
+		return err
+	}
+
+	return receiver.ResetCodeCounting(req, organizationId, receiver.DB)
 }
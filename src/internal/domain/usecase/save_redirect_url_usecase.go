@@ -1,11 +1,17 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"strings"
 )
 
+var ErrInvalidRedirectUrl = errors.New("invalid redirect url")
+
 type SaveRedirectUrlUseCase struct {
 	*repository.RedirectUrlRepository
 }
@@ -18,5 +24,27 @@ func (receiver *SaveRedirectUrlUseCase) Save(req request.SaveRedirectUrlRequest)
 	if req.Password != "" {
 		url.Password = &req.Password
 	}
+	err := applyRedirectUrlSchedule(&url, req.RedirectUrlScheduleRequest)
+	if err != nil {
+		return nil, err
+	}
 	return receiver.RedirectUrlRepository.Save(url)
 }
+
+// applyRedirectUrlSchedule validates the schedule and sets it on the redirect
+// URL
+func applyRedirectUrlSchedule(redirectUrl *entity.SRedirectUrl, req request.RedirectUrlScheduleRequest) error {
+	if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveFrom.Before(*req.ActiveUntil) {
+		return fmt.Errorf("%w: active_from must be before active_until", ErrInvalidRedirectUrl)
+	}
+	schedule, err := (value.RedirectUrlSchedule{TimeRules: req.TimeRules, Targets: req.Targets}).Normalized()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRedirectUrl, err.Error())
+	}
+
+	redirectUrl.ActiveFrom = req.ActiveFrom
+	redirectUrl.ActiveUntil = req.ActiveUntil
+	redirectUrl.FallbackUrl = strings.TrimSpace(req.FallbackUrl)
+
+	return redirectUrl.SetSchedule(schedule)
+}
@@ -18,12 +18,13 @@ func (receiver *SearchFormsUseCase) SearchForms(keyword string) ([]response.GetF
 	var formList []response.GetFormListResponseData
 	for _, form := range forms {
 		formList = append(formList, response.GetFormListResponseData{
-			Id:          form.ID,
-			Spreadsheet: form.SpreadsheetUrl,
-			Password:    form.Password,
-			Note:        form.Note,
-			CreatedAt:   form.CreatedAt,
-			UpdatedAt:   form.UpdatedAt,
+			Id:             form.ID,
+			OrganizationId: form.OrganizationId,
+			Spreadsheet:    form.SpreadsheetUrl,
+			Password:       form.Password,
+			Note:           form.Note,
+			CreatedAt:      form.CreatedAt,
+			UpdatedAt:      form.UpdatedAt,
 		})
 	}
 
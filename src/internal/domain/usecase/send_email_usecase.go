@@ -18,7 +18,7 @@ import (
 type SendEmailUseCase struct {
 	config.SMTPConfig
 	*repository.SettingRepository
-	*sheet.Writer
+	sheet.SpreadsheetWriter
 }
 
 func (receiver *SendEmailUseCase) SendEmail(target string, subject string, content string, device entity.SDevice) error {
@@ -68,7 +68,7 @@ func (receiver *SendEmailUseCase) SendEmail(target string, subject string, conte
 }
 
 func (receiver *SendEmailUseCase) logHistory(target string, subject string, device entity.SDevice) {
-	setting, err := receiver.GetEmailSettings()
+	setting, err := receiver.ForOrganization(device.OrganizationId).GetEmailSettings()
 	if err != nil {
 		monitor.SendMessageViaTelegram("Error when get email settings: " + err.Error())
 		return
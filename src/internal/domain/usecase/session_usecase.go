@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"sen-global-api/config"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/pkg/jwtkeys"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = repository.ErrInvalidRefreshToken
+	ErrSessionRevoked      = repository.ErrSessionRevoked
+)
+
+var (
+	tokenKeySet     *jwtkeys.KeySet
+	tokenKeySetOnce sync.Once
+)
+
+// TokenKeySet loads the keys of the JWT config once, it is nil when there are no
+// keys and tokens are signed with HS256
+func TokenKeySet(cfg config.AppConfig) *jwtkeys.KeySet {
+	tokenKeySetOnce.Do(func() {
+		keySet, err := jwtkeys.Load(cfg.JWT.KeysDirectory, cfg.JWT.SigningKeyId)
+		if err != nil {
+			log.Fatal("Unable to load the JWT keys: ", err)
+		}
+		if keySet == nil {
+			log.Warn("No JWT keys in ", cfg.JWT.KeysDirectory, ", tokens are signed with HS256")
+		} else {
+			log.Info("Signing tokens with the JWT key ", keySet.SigningKeyId())
+		}
+		tokenKeySet = keySet
+	})
+
+	return tokenKeySet
+}
+
+// sessionlessTokensUntil parses JWT.SessionlessTokensUntil, the zero time when it
+// is empty
+func sessionlessTokensUntil(cfg config.AppConfig) time.Time {
+	if cfg.JWT.SessionlessTokensUntil == "" {
+		return time.Time{}
+	}
+
+	until, err := time.Parse(time.RFC3339, cfg.JWT.SessionlessTokensUntil)
+	if err != nil {
+		log.Fatal("Invalid jwt.sessionless_tokens_until: ", err)
+	}
+
+	return until
+}
+
+func NewSessionRepository(cfg config.AppConfig, dbConn *gorm.DB) repository.SessionRepository {
+	return repository.SessionRepository{
+		DBConn:                       dbConn,
+		AuthorizeEncryptKey:          cfg.AuthorizeEncryptKey,
+		TokenExpireTimeInHour:        time.Duration(cfg.TokenExpireDurationInHour),
+		RefreshTokenExpireTimeInHour: time.Duration(cfg.RefreshTokenExpireDurationInHour),
+		KeySet:                       TokenKeySet(cfg),
+		AcceptHS256:                  cfg.JWT.AcceptHS256,
+		SessionlessTokensUntil:       sessionlessTokensUntil(cfg),
+	}
+}
+
+// SessionUseCase refreshes, lists and revokes the sessions started by the logins
+// of the users, see repository.SessionRepository
+type SessionUseCase struct {
+	SessionRepository    *repository.SessionRepository
+	UserEntityRepository *repository.UserEntityRepository
+}
+
+func NewSessionUseCase(db *gorm.DB, sessionRepository *repository.SessionRepository) *SessionUseCase {
+	return &SessionUseCase{
+		SessionRepository:    sessionRepository,
+		UserEntityRepository: &repository.UserEntityRepository{DBConn: db},
+	}
+}
+
+// StartSession persists a session of the user, on the device when deviceId is
+// set, and issues its access and refresh tokens
+func StartSession(sessionRepository *repository.SessionRepository, user entity.SUserEntity, deviceId string, userAgent string, ipAddress string) (*response.LoginResponseData, error) {
+	if sessionRepository.DBConn == nil {
+		return nil, repository.ErrNoSessionStore
+	}
+
+	session, refreshToken, err := sessionRepository.CreateSession(user.ID.String(), deviceId, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueSessionTokens(sessionRepository, user, *session, refreshToken)
+}
+
+func issueSessionTokens(sessionRepository *repository.SessionRepository, user entity.SUserEntity, session entity.SSession, refreshToken string) (*response.LoginResponseData, error) {
+	token, err := sessionRepository.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	token.SessionId = session.ID
+	token.RefreshToken = refreshToken
+	token.RefreshTokenExpired = session.ExpiresAt
+
+	return token, nil
+}
+
+// Refresh rotates the refresh token of a session and issues a new access token
+// with the current roles of the user
+func (receiver *SessionUseCase) Refresh(refreshToken string, userAgent string, ipAddress string) (*response.LoginResponseData, error) {
+	session, newRefreshToken, err := receiver.SessionRepository.RotateRefreshToken(refreshToken, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := receiver.UserEntityRepository.GetByID(request.GetUserEntityByIdRequest{ID: session.UserId})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = receiver.SessionRepository.RevokeSession(session.ID, "user_not_found")
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+
+	return issueSessionTokens(receiver.SessionRepository, *user, *session, newRefreshToken)
+}
+
+// Logout revokes the session of the access token of the user
+func (receiver *SessionUseCase) Logout(userId string, sessionId string) error {
+	if sessionId == "" {
+		return gorm.ErrRecordNotFound
+	}
+
+	return receiver.RevokeUserSession(userId, sessionId, "logout")
+}
+
+// LogoutEverywhere revokes every session of the user
+func (receiver *SessionUseCase) LogoutEverywhere(userId string, reason string) (int64, error) {
+	return receiver.SessionRepository.RevokeUserSessions(userId, reason)
+}
+
+func (receiver *SessionUseCase) GetActiveSessions(userId string) ([]entity.SSession, error) {
+	return receiver.SessionRepository.GetActiveSessions(userId)
+}
+
+// RevokeUserSession revokes one session of the user, the session must belong to
+// the user
+func (receiver *SessionUseCase) RevokeUserSession(userId string, sessionId string, reason string) error {
+	session, err := receiver.SessionRepository.GetSession(sessionId)
+	if err != nil {
+		return err
+	}
+	if session.UserId != userId {
+		return gorm.ErrRecordNotFound
+	}
+
+	return receiver.SessionRepository.RevokeSession(sessionId, reason)
+}
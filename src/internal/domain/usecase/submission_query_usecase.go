@@ -0,0 +1,385 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/sheet"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const submissionExportBatchSize = 500
+
+var ErrInvalidSubmissionExport = errors.New("invalid submission export")
+
+// submissionExportFixedColumns come before the one column per question
+var submissionExportFixedColumns = []string{"Submission ID", "Submitted At", "Opened At", "User ID", "Username", "Full Name", "Form Revision"}
+
+type SubmissionQueryUseCase struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func NewSubmissionQueryUseCase(db *gorm.DB, defaultRequestPageSize int) *SubmissionQueryUseCase {
+	return &SubmissionQueryUseCase{
+		DBConn:                 db,
+		DefaultRequestPageSize: defaultRequestPageSize,
+	}
+}
+
+func (receiver *SubmissionQueryUseCase) GetSubmissions(req request.GetSubmissionListRequest) ([]response.SubmissionResponseData, *response.Pagination, error) {
+	submissionRepository := &repository.SubmissionRepository{DBConn: receiver.DBConn, DefaultRequestPageSize: receiver.DefaultRequestPageSize}
+	submissions, paging, err := submissionRepository.GetSubmissionList(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]response.SubmissionResponseData, 0, len(submissions))
+	for _, submission := range submissions {
+		answers := make([]response.SubmissionAnswerResponseData, 0)
+		for _, item := range submissionItems(submission) {
+			answers = append(answers, response.SubmissionAnswerResponseData{
+				QuestionId: item.QuestionId,
+				Question:   item.Question,
+				Answer:     item.Answer,
+			})
+		}
+
+		result = append(result, response.SubmissionResponseData{
+			Id:             submission.ID,
+			FormId:         submission.FormId,
+			FormRevisionId: submission.FormRevisionId,
+			UserId:         submission.UserId,
+			Username:       submission.User.Username,
+			Fullname:       submission.User.Fullname,
+			Answers:        answers,
+			OpenedAt:       submission.OpenedAt,
+			CreatedAt:      submission.CreatedAt,
+		})
+	}
+
+	return result, paging, nil
+}
+
+// SubmissionExportColumn is a question column of an export
+type SubmissionExportColumn struct {
+	QuestionId string `json:"question_id"`
+	Question   string `json:"question"`
+}
+
+// SubmissionExport is a prepared export of the submissions of a form. Preparing
+// resolves everything that can fail before the first byte is streamed.
+type SubmissionExport struct {
+	Form         *entity.SForm
+	Format       value.ExportFormat
+	Columns      []SubmissionExportColumn
+	templatePath string
+	filter       request.GetSubmissionListRequest
+	revisions    map[uint64]int
+	repository   *repository.SubmissionRepository
+}
+
+// PrepareExport resolves the columns of an export, one per question of the form
+// in form order followed by the questions only older revisions had.
+func (receiver *SubmissionQueryUseCase) PrepareExport(formID uint64, req request.ExportSubmissionsRequest) (*SubmissionExport, error) {
+	format, err := value.GetExportFormatFromString(req.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubmissionExport, err.Error())
+	}
+
+	form, err := (&repository.FormRepository{DBConn: receiver.DBConn}).GetFormById(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := (&repository.QuestionRepository{DBConn: receiver.DBConn}).GetAllQuestionsByFormId(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := (&repository.FormRevisionRepository{DBConn: receiver.DBConn}).GetRevisions(formID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]SubmissionExportColumn, 0, len(questions))
+	known := make(map[string]bool, len(questions))
+	for _, question := range questions {
+		columns = append(columns, SubmissionExportColumn{QuestionId: question.QuestionId, Question: question.Question})
+		known[question.QuestionId] = true
+	}
+
+	revisionNumbers := make(map[uint64]int, len(revisions))
+	for _, revision := range revisions {
+		revisionNumbers[revision.ID] = revision.Revision
+		snapshot, err := unmarshalFormSnapshot(revision.Snapshot)
+		if err != nil {
+			continue
+		}
+		for _, question := range snapshot.Questions {
+			if known[question.QuestionId] {
+				continue
+			}
+			columns = append(columns, SubmissionExportColumn{QuestionId: question.QuestionId, Question: question.Question})
+			known[question.QuestionId] = true
+		}
+	}
+
+	templatePath := ""
+	if format == value.ExportFormat_XLSX {
+		templatePath, err = submissionExportTemplatePath(req.Template)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &SubmissionExport{
+		Form:         form,
+		Format:       format,
+		Columns:      columns,
+		templatePath: templatePath,
+		filter: request.GetSubmissionListRequest{
+			FormId:         formID,
+			UserId:         req.UserId,
+			DeviceId:       req.DeviceId,
+			OrganizationId: req.OrganizationId,
+			From:           req.From,
+			To:             req.To,
+		},
+		revisions:  revisionNumbers,
+		repository: &repository.SubmissionRepository{DBConn: receiver.DBConn},
+	}, nil
+}
+
+func (receiver *SubmissionExport) FileName() string {
+	name := strings.Map(func(r rune) rune {
+		if r == '"' || r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, receiver.Form.Note)
+	if name == "" {
+		name = strconv.FormatUint(receiver.Form.ID, 10)
+	}
+
+	return fmt.Sprintf("%s-submissions.%s", name, receiver.Format)
+}
+
+func (receiver *SubmissionExport) ContentType() string {
+	switch receiver.Format {
+	case value.ExportFormat_XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case value.ExportFormat_NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Write streams the export to w, flushing after every batch of submissions
+func (receiver *SubmissionExport) Write(w io.Writer) error {
+	switch receiver.Format {
+	case value.ExportFormat_XLSX:
+		return receiver.writeXLSX(w)
+	case value.ExportFormat_NDJSON:
+		return receiver.writeNDJSON(w)
+	default:
+		return receiver.writeCSV(w)
+	}
+}
+
+func (receiver *SubmissionExport) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(receiver.header()); err != nil {
+		return err
+	}
+
+	err := receiver.repository.FindSubmissionsInBatches(receiver.filter, submissionExportBatchSize, func(submissions []entity.SSubmission) error {
+		for _, submission := range submissions {
+			if err := writer.Write(receiver.row(submission)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		flushExport(w)
+
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (receiver *SubmissionExport) writeXLSX(w io.Writer) error {
+	writer, err := sheet.NewXLSXWriter(w, receiver.templatePath, "Answers")
+	if err != nil {
+		return err
+	}
+	if err = writer.WriteHeader(receiver.header()); err != nil {
+		return err
+	}
+
+	err = receiver.repository.FindSubmissionsInBatches(receiver.filter, submissionExportBatchSize, func(submissions []entity.SSubmission) error {
+		for _, submission := range submissions {
+			if err := writer.WriteRow(receiver.row(submission)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flushExport(w)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+type submissionExportLine struct {
+	SubmissionId uint64                         `json:"submission_id"`
+	SubmittedAt  time.Time                      `json:"submitted_at"`
+	OpenedAt     time.Time                      `json:"opened_at"`
+	UserId       string                         `json:"user_id"`
+	Username     string                         `json:"username"`
+	Fullname     string                         `json:"fullname"`
+	FormRevision *int                           `json:"form_revision"`
+	Answers      []submissionExportLineQuestion `json:"answers"`
+}
+
+type submissionExportLineQuestion struct {
+	SubmissionExportColumn
+	Answer string `json:"answer"`
+}
+
+func (receiver *SubmissionExport) writeNDJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return receiver.repository.FindSubmissionsInBatches(receiver.filter, submissionExportBatchSize, func(submissions []entity.SSubmission) error {
+		for _, submission := range submissions {
+			answers := submissionAnswers(submission)
+			line := submissionExportLine{
+				SubmissionId: submission.ID,
+				SubmittedAt:  submission.CreatedAt,
+				OpenedAt:     submission.OpenedAt,
+				UserId:       submission.UserId,
+				Username:     submission.User.Username,
+				Fullname:     submission.User.Fullname,
+				FormRevision: receiver.revisionNumber(submission),
+				Answers:      make([]submissionExportLineQuestion, 0, len(receiver.Columns)),
+			}
+			for _, column := range receiver.Columns {
+				line.Answers = append(line.Answers, submissionExportLineQuestion{SubmissionExportColumn: column, Answer: answers[column.QuestionId]})
+			}
+			if err := encoder.Encode(line); err != nil {
+				return err
+			}
+		}
+		flushExport(w)
+
+		return nil
+	})
+}
+
+func (receiver *SubmissionExport) header() []string {
+	header := append(make([]string, 0, len(submissionExportFixedColumns)+len(receiver.Columns)), submissionExportFixedColumns...)
+	for _, column := range receiver.Columns {
+		header = append(header, column.Question)
+	}
+
+	return header
+}
+
+func (receiver *SubmissionExport) row(submission entity.SSubmission) []string {
+	revision := ""
+	if number := receiver.revisionNumber(submission); number != nil {
+		revision = strconv.Itoa(*number)
+	}
+
+	row := []string{
+		strconv.FormatUint(submission.ID, 10),
+		submission.CreatedAt.Format(time.RFC3339),
+		submission.OpenedAt.Format(time.RFC3339),
+		submission.UserId,
+		submission.User.Username,
+		submission.User.Fullname,
+		revision,
+	}
+
+	answers := submissionAnswers(submission)
+	for _, column := range receiver.Columns {
+		row = append(row, answers[column.QuestionId])
+	}
+
+	return row
+}
+
+func (receiver *SubmissionExport) revisionNumber(submission entity.SSubmission) *int {
+	if submission.FormRevisionId == nil {
+		return nil
+	}
+	number, ok := receiver.revisions[*submission.FormRevisionId]
+	if !ok {
+		return nil
+	}
+
+	return &number
+}
+
+func submissionItems(submission entity.SSubmission) []entity.SubmissionDataItem {
+	var data entity.SubmissionData
+	if err := json.Unmarshal(submission.SubmissionData, &data); err != nil {
+		return nil
+	}
+
+	return data.Items
+}
+
+func submissionAnswers(submission entity.SSubmission) map[string]string {
+	answers := make(map[string]string)
+	for _, item := range submissionItems(submission) {
+		answers[item.QuestionId] = item.Answer
+	}
+
+	return answers
+}
+
+// submissionExportTemplatePath picks the workbook an xlsx export takes its styles from
+func submissionExportTemplatePath(template string) (string, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(template)) {
+	case "", "output":
+		return pwd + "/config/output_template.xlsx", nil
+	case "teacher":
+		return pwd + "/config/output_template_teacher.xlsx", nil
+	default:
+		return "", fmt.Errorf("%w: unknown template %s", ErrInvalidSubmissionExport, template)
+	}
+}
+
+func flushExport(w io.Writer) {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
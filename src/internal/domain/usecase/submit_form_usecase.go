@@ -12,10 +12,10 @@ import (
 	"sen-global-api/pkg/messaging"
 	"sen-global-api/pkg/monitor"
 	"sen-global-api/pkg/sheet"
+	"strings"
 
 	firebase "firebase.google.com/go/v4"
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/drive/v3"
 	"gorm.io/gorm"
 )
 
@@ -29,12 +29,12 @@ type SubmitFormUseCase struct {
 	*repository.MobileDeviceRepository
 	*repository.FormQuestionRepository
 	*repository.CodeCountingRepository
-	*sheet.Writer
-	*sheet.Reader
-	DriveService        *drive.Service
+	sheet.SpreadsheetWriter
+	sheet.SpreadsheetReader
 	OutputSpreadsheetId string
 	FirebaseApp         *firebase.App
 	DB                  *gorm.DB
+	FormRevisionUseCase *FormRevisionUseCase
 }
 
 func (receiver *SubmitFormUseCase) AnswerForm(id uint64, req request.SubmitFormRequest) error {
@@ -43,9 +43,78 @@ func (receiver *SubmitFormUseCase) AnswerForm(id uint64, req request.SubmitFormR
 		return err
 	}
 
+	err = receiver.checkAnswers(form, req)
+	if err != nil {
+		return err
+	}
+
 	return receiver.answerFormSaveToFormOutputSheet(form, req)
 }
 
+// checkAnswers rejects answers to questions of other forms, answers to questions
+// hidden by the form rules, answers that do not fit the type of their question and
+// missing answers to required questions that are visible
+func (receiver *SubmitFormUseCase) checkAnswers(form *entity.SForm, req request.SubmitFormRequest) error {
+	questions, err := receiver.GetQuestionsByFormId(form.ID)
+	if err != nil {
+		return fmt.Errorf("system cannot find questions for this form: %s", form.Name)
+	}
+
+	answers := make(map[string]string, len(req.Answers))
+	for _, answer := range req.Answers {
+		answers[answer.QuestionId] = answer.Answer
+	}
+
+	formQuestions := make(map[string]model.FormQuestionItem, len(questions))
+	for _, question := range questions {
+		formQuestions[question.QuestionId] = question
+	}
+
+	hidden := HiddenQuestions(questions, answers)
+	answersErr := &FormAnswersError{}
+	for _, answer := range req.Answers {
+		question, ok := formQuestions[answer.QuestionId]
+		if !ok {
+			answersErr.add(answer.QuestionId, "question is not part of the form")
+			continue
+		}
+		if strings.TrimSpace(answer.Answer) == "" {
+			continue
+		}
+		if hidden[answer.QuestionId] {
+			answersErr.add(answer.QuestionId, "question is hidden by the form rules")
+			continue
+		}
+		if err := ValidateAnswer(question, answer.Answer); err != nil {
+			answersErr.add(answer.QuestionId, err.Error())
+		}
+	}
+
+	for _, question := range questions {
+		if !question.AnswerRequired || hidden[question.QuestionId] || !isAskedOnDevice(question) {
+			continue
+		}
+		if strings.TrimSpace(answers[question.QuestionId]) == "" {
+			answersErr.add(question.QuestionId, "answer is required")
+		}
+	}
+
+	if len(answersErr.Errors) > 0 {
+		return answersErr
+	}
+
+	return nil
+}
+
+func isAskedOnDevice(question model.FormQuestionItem) bool {
+	questionType, err := value.GetQuestionType(question.QuestionType)
+	if err != nil || !value.IsGeneralQuestionType(questionType) || questionType == value.QuestionSendNotification {
+		return false
+	}
+
+	return question.EnableOnMobile == value.QuestionForMobile_Enabled
+}
+
 func Map[T, U any](ts []T, f func(T) U) []U {
 	us := make([]U, len(ts))
 	for i := range ts {
@@ -86,8 +155,19 @@ func (receiver *SubmitFormUseCase) answerFormSaveToFormOutputSheet(form *entity.
 	submissionData := repository.SubmissionData{
 		Items: submissionItems,
 	}
+	var formRevisionId *uint64 = nil
+	if receiver.FormRevisionUseCase != nil {
+		revision, err := receiver.FormRevisionUseCase.GetPublishedRevision(form.ID)
+		if err != nil {
+			log.Error("SubmitFormUseCase.answerFormSaveToFormOutputSheet cannot resolve form revision ", err)
+		} else if revision != nil {
+			formRevisionId = &revision.ID
+		}
+	}
+
 	createSubmissionParams := repository.CreateSubmissionParams{
 		FormId:         form.ID,
+		FormRevisionId: formRevisionId,
 		UserId:         req.UserId,
 		SubmissionData: submissionData,
 		OpenedAt:       req.OpenedAt,
@@ -98,6 +178,15 @@ func (receiver *SubmitFormUseCase) answerFormSaveToFormOutputSheet(form *entity.
 		return errors.New("system cannot handle the submission")
 	}
 
+	PublishWebhookEvent(value.WebhookEvent_FormSubmitted, map[string]interface{}{
+		"form_id":          form.ID,
+		"form_note":        form.Note,
+		"form_revision_id": formRevisionId,
+		"user_id":          req.UserId,
+		"answers":          submissionItems,
+		"opened_at":        req.OpenedAt,
+	})
+
 	defer func() {
 		receiver.sendNotification(form)
 	}()
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// toDoMember is an assignee that completes the tasks of a list on its own, a
+// user or a device. The devices of a group assigned a task are members of their
+// own.
+type toDoMember struct {
+	Type      value.ToDoAssigneeType
+	Id        string
+	Name      string
+	DeviceIds []string
+}
+
+func toDoMemberKey(assigneeType value.ToDoAssigneeType, id string) string {
+	return string(assigneeType) + ":" + id
+}
+
+// toDoAssignment resolves the assignees of a list and of its tasks to members
+type toDoAssignment struct {
+	assignees []entity.TaskAssignee
+	members   map[string]toDoMember
+	groups    map[string][]string
+	order     []string
+}
+
+func loadToDoAssignment(db *gorm.DB, todoRepository *repository.ToDoRepository, list entity.SToDo) (*toDoAssignment, error) {
+	saved, err := todoRepository.GetAssignees(db, list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := &toDoAssignment{
+		assignees: make([]entity.TaskAssignee, 0, len(saved)),
+		members:   make(map[string]toDoMember),
+		groups:    make(map[string][]string),
+	}
+	for _, a := range saved {
+		assignment.assignees = append(assignment.assignees, entity.TaskAssignee{Type: a.AssigneeType, Id: a.AssigneeId})
+	}
+
+	all := append([]entity.TaskAssignee{}, assignment.assignees...)
+	for _, task := range list.Tasks.Data.Tasks {
+		all = append(all, task.Assignees...)
+	}
+	ids := map[value.ToDoAssigneeType][]string{}
+	seen := make(map[string]bool, len(all))
+	for _, a := range all {
+		if !seen[toDoMemberKey(a.Type, a.Id)] {
+			seen[toDoMemberKey(a.Type, a.Id)] = true
+			ids[a.Type] = append(ids[a.Type], a.Id)
+		}
+	}
+	if len(seen) == 0 {
+		return assignment, nil
+	}
+
+	userDevices, err := todoRepository.GetUserDeviceIds(db, ids[value.ToDoAssigneeType_User])
+	if err != nil {
+		return nil, err
+	}
+	userNames, err := todoRepository.GetUserNames(db, ids[value.ToDoAssigneeType_User])
+	if err != nil {
+		return nil, err
+	}
+	devices, err := todoRepository.GetAssignableDevices(db, ids[value.ToDoAssigneeType_Device], ids[value.ToDoAssigneeType_Group])
+	if err != nil {
+		return nil, err
+	}
+	deviceNames := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceNames[device.ID] = device.DeviceName
+	}
+
+	for _, a := range all {
+		switch a.Type {
+		case value.ToDoAssigneeType_User:
+			assignment.add(toDoMember{Type: a.Type, Id: a.Id, Name: userNames[a.Id], DeviceIds: userDevices[a.Id]})
+		case value.ToDoAssigneeType_Device:
+			assignment.add(toDoMember{Type: a.Type, Id: a.Id, Name: deviceNames[a.Id], DeviceIds: []string{a.Id}})
+		case value.ToDoAssigneeType_Group:
+			if _, ok := assignment.groups[a.Id]; ok {
+				continue
+			}
+			keys := make([]string, 0)
+			for _, device := range devices {
+				if device.GroupId == nil || strconv.FormatUint(*device.GroupId, 10) != a.Id {
+					continue
+				}
+				member := toDoMember{Type: value.ToDoAssigneeType_Device, Id: device.ID, Name: device.DeviceName, DeviceIds: []string{device.ID}}
+				assignment.add(member)
+				keys = append(keys, toDoMemberKey(member.Type, member.Id))
+			}
+			assignment.groups[a.Id] = keys
+		}
+	}
+
+	return assignment, nil
+}
+
+func (assignment *toDoAssignment) add(member toDoMember) {
+	key := toDoMemberKey(member.Type, member.Id)
+	if _, ok := assignment.members[key]; ok {
+		return
+	}
+	assignment.members[key] = member
+	assignment.order = append(assignment.order, key)
+}
+
+// allMembers returns the members of the list and of its tasks, in the order
+// they were assigned
+func (assignment *toDoAssignment) allMembers() []toDoMember {
+	members := make([]toDoMember, 0, len(assignment.order))
+	for _, key := range assignment.order {
+		members = append(members, assignment.members[key])
+	}
+
+	return members
+}
+
+// taskMembers returns the members a task is assigned to, none for a task of a
+// list assigned to nobody: such a task is done once for everybody
+func (assignment *toDoAssignment) taskMembers(task entity.Task) []toDoMember {
+	assignees := task.Assignees
+	if len(assignees) == 0 {
+		assignees = assignment.assignees
+	}
+
+	members := make([]toDoMember, 0, len(assignees))
+	seen := make(map[string]bool, len(assignees))
+	for _, a := range assignees {
+		keys := []string{toDoMemberKey(a.Type, a.Id)}
+		if a.Type == value.ToDoAssigneeType_Group {
+			keys = assignment.groups[a.Id]
+		}
+		for _, key := range keys {
+			member, ok := assignment.members[key]
+			if ok && !seen[key] {
+				seen[key] = true
+				members = append(members, member)
+			}
+		}
+	}
+
+	return members
+}
+
+func (assignment *toDoAssignment) isTaskMember(task entity.Task, member toDoMember) bool {
+	for _, m := range assignment.taskMembers(task) {
+		if m.Type == member.Type && m.Id == member.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// memberOfDevice returns the member a device completes a task for, a user of the
+// device comes before the device itself
+func (assignment *toDoAssignment) memberOfDevice(task entity.Task, deviceId string) (toDoMember, bool) {
+	members := assignment.taskMembers(task)
+	for _, member := range members {
+		if member.Type != value.ToDoAssigneeType_User {
+			continue
+		}
+		for _, id := range member.DeviceIds {
+			if id == deviceId {
+				return member, true
+			}
+		}
+	}
+	for _, member := range members {
+		if member.Type == value.ToDoAssigneeType_Device && member.Id == deviceId {
+			return member, true
+		}
+	}
+
+	return toDoMember{}, false
+}
+
+// toDoProgressByMember indexes the progress of a list by member and task
+func toDoProgressByMember(progress []entity.SToDoTaskProgress) map[string]map[int]entity.SToDoTaskProgress {
+	byMember := make(map[string]map[int]entity.SToDoTaskProgress)
+	for _, p := range progress {
+		key := toDoMemberKey(p.AssigneeType, p.AssigneeId)
+		if byMember[key] == nil {
+			byMember[key] = make(map[int]entity.SToDoTaskProgress)
+		}
+		byMember[key][p.TaskIndex] = p
+	}
+
+	return byMember
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"fmt"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// toDoDueDateLayout is how the due date of a task is saved, in the timezone of
+// the organization of the list
+const toDoDueDateLayout = "2006-01-02 15:04:05"
+
+// toDoMaxSkippedOccurrences bounds the occurrences of a recurring task skipped
+// because they were already due when it was completed
+const toDoMaxSkippedOccurrences = 1000
+
+// organizationLocation returns the timezone of an organization, UTC for the
+// shared rows
+func organizationLocation(db *gorm.DB, organizationId *int64) *time.Location {
+	if organizationId == nil {
+		return time.UTC
+	}
+
+	var organization entity.SOrganization
+	err := db.Select("id", "timezone").Where("id = ?", *organizationId).First(&organization).Error
+	if err != nil {
+		log.Error("organizationLocation ", *organizationId, " ", err)
+		return time.UTC
+	}
+	location, err := value.LoadTimezone(organization.Timezone)
+	if err != nil {
+		log.Error("organizationLocation: organization ", organization.ID, " has an unknown timezone ", organization.Timezone)
+		return time.UTC
+	}
+
+	return location
+}
+
+// taskDueAt returns when the task is due, read from its due date for the tasks
+// imported from a sheet. It is nil for the tasks due "URGENT" or with no date.
+func taskDueAt(task entity.Task, location *time.Location) *time.Time {
+	if task.DueAt != nil {
+		return task.DueAt
+	}
+	for _, layout := range []string{toDoDueDateLayout, "2006-01-02 15:04"} {
+		if due, err := time.ParseInLocation(layout, task.DueDate, location); err == nil {
+			return &due
+		}
+	}
+
+	return nil
+}
+
+// withDueTimes sets the due time of the tasks of the lists imported with a due
+// date only, so that their overdue tasks show
+func withDueTimes(db *gorm.DB, lists []entity.SToDo) {
+	locations := make(map[int64]*time.Location)
+	for i := range lists {
+		location := time.UTC
+		if organizationId := lists[i].OrganizationId; organizationId != nil {
+			if _, ok := locations[*organizationId]; !ok {
+				locations[*organizationId] = organizationLocation(db, organizationId)
+			}
+			location = locations[*organizationId]
+		}
+
+		tasks := lists[i].Tasks.Data.Tasks
+		for j := range tasks {
+			tasks[j].DueAt = taskDueAt(tasks[j], location)
+		}
+	}
+}
+
+// applyToDoTask sets the fields of a task given through the API
+func applyToDoTask(task *entity.Task, req request.SaveToDoTask, location *time.Location) error {
+	var recurrence *value.ToDoRecurrence
+	if req.Recurrence != nil {
+		normalized, err := req.Recurrence.Normalized()
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidToDo, err.Error())
+		}
+		recurrence = &normalized
+	}
+
+	dueDate := req.DueDate
+	dueAt := req.DueAt
+	if dueAt != nil {
+		dueDate = dueAt.In(location).Format(toDoDueDateLayout)
+	} else {
+		dueAt = taskDueAt(entity.Task{DueDate: dueDate}, location)
+	}
+	if dueAt == nil && (recurrence != nil || req.RemindBefore > 0) {
+		return fmt.Errorf("%w: a recurring task or a task with a reminder needs a due date", ErrInvalidToDo)
+	}
+
+	if recurrence != nil && recurrence.Frequency == value.ToDoRecurrence_Monthly && recurrence.MonthDay == 0 {
+		recurrence.MonthDay = dueAt.In(location).Day()
+	}
+
+	task.Name = req.Name
+	task.DueDate = dueDate
+	task.DueAt = dueAt
+	task.Value = req.Value
+	task.Selection = req.Selection
+	task.Recurrence = recurrence
+	task.RemindBefore = req.RemindBefore
+	task.RemindedAt = nil
+	if recurrence != nil && task.Occurrence == 0 {
+		task.Occurrence = 1
+	}
+
+	return nil
+}
+
+// nextOccurrence returns the next occurrence of a recurring task completed at
+// now, with the index. It is due after the completed one, the occurrences
+// already due by now are skipped. It returns false when the task does not
+// repeat or its next occurrence was made already.
+func nextOccurrence(task entity.Task, index int, location *time.Location, now time.Time) (entity.Task, bool) {
+	if task.Recurrence == nil || task.NextIndex != nil {
+		return entity.Task{}, false
+	}
+
+	due := now.In(location)
+	if dueAt := taskDueAt(task, location); dueAt != nil {
+		due = dueAt.In(location)
+	}
+	occurrence := task.Occurrence
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	for i := 0; i == 0 || !due.After(now); i++ {
+		if i == toDoMaxSkippedOccurrences {
+			return entity.Task{}, false
+		}
+		next, ok := task.Recurrence.Next(due, occurrence)
+		if !ok {
+			return entity.Task{}, false
+		}
+		due = next
+		occurrence++
+	}
+
+	return entity.Task{
+		Index:        index,
+		Name:         task.Name,
+		DueDate:      due.Format(toDoDueDateLayout),
+		DueAt:        &due,
+		Value:        task.Value,
+		Selection:    task.Selection,
+		Recurrence:   task.Recurrence,
+		Occurrence:   occurrence,
+		RemindBefore: task.RemindBefore,
+		Assignees:    task.Assignees,
+	}, true
+}
+
+// nextTaskIndex returns the index after the last index of the tasks
+func nextTaskIndex(tasks []entity.Task) int {
+	index := 0
+	for _, t := range tasks {
+		if t.Index >= index {
+			index = t.Index + 1
+		}
+	}
+
+	return index
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/messaging"
+	"strconv"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// todoReminderBatchSize is how many lists get their reminders sent in a run
+const todoReminderBatchSize = 100
+
+// ToDoReminderUseCase sends the reminders of the to-do tasks through FCM to the
+// devices of the assignees that did not complete them yet. A reminder is sent
+// once, the reminders of the tasks that are already due when their turn comes
+// are dropped.
+type ToDoReminderUseCase struct {
+	ToDoRepository         *repository.ToDoRepository
+	MobileDeviceRepository *repository.MobileDeviceRepository
+	FirebaseApp            *firebase.App
+	DB                     *gorm.DB
+}
+
+func NewToDoReminderUseCase(db *gorm.DB, app *firebase.App) *ToDoReminderUseCase {
+	return &ToDoReminderUseCase{
+		ToDoRepository:         &repository.ToDoRepository{},
+		MobileDeviceRepository: repository.NewMobileDeviceRepository(),
+		FirebaseApp:            app,
+		DB:                     db,
+	}
+}
+
+func (receiver *ToDoReminderUseCase) ExecuteToDoReminders() {
+	now := time.Now()
+	ids, err := receiver.ToDoRepository.GetDueReminderIDs(receiver.DB, now, todoReminderBatchSize)
+	if err != nil {
+		log.Error("ToDoReminderUseCase.ExecuteToDoReminders ", err)
+		return
+	}
+
+	for _, id := range ids {
+		receiver.remind(id, now)
+	}
+}
+
+// remind marks the reminders of the list due by now as sent and sends them
+func (receiver *ToDoReminderUseCase) remind(id string, now time.Time) {
+	reminded := make([]entity.Task, 0)
+	list, err := receiver.ToDoRepository.UpdateToDo(receiver.DB, id, func(list *entity.SToDo) error {
+		tasks := list.Tasks.Data.Tasks
+		for i := range tasks {
+			at := tasks[i].ReminderAt()
+			if at == nil || at.After(now) {
+				continue
+			}
+			tasks[i].RemindedAt = &now
+			if tasks[i].DueAt.After(now) {
+				reminded = append(reminded, tasks[i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("ToDoReminderUseCase.remind ", id, " ", err)
+		return
+	}
+	if receiver.FirebaseApp == nil || len(reminded) == 0 {
+		return
+	}
+
+	recipients, err := receiver.recipients(*list, reminded)
+	if err != nil {
+		log.Error("ToDoReminderUseCase.remind ", id, " ", err)
+		return
+	}
+	deviceIds := make([]string, 0, len(recipients))
+	for deviceId := range recipients {
+		deviceIds = append(deviceIds, deviceId)
+	}
+	mobileDevices, err := receiver.MobileDeviceRepository.FindByDeviceIDs(deviceIds, receiver.DB)
+	if err != nil {
+		log.Error("ToDoReminderUseCase.remind ", id, " ", err)
+		return
+	}
+
+	params := make([]messaging.DataMessageParams, 0, len(mobileDevices))
+	for _, mobileDevice := range mobileDevices {
+		for _, task := range recipients[mobileDevice.DeviceId] {
+			params = append(params, messaging.DataMessageParams{
+				DeviceToken: mobileDevice.FCMToken,
+				Type:        value.NotificationType_ToDoReminder,
+				Data: map[string]string{
+					"device_id":  mobileDevice.DeviceId,
+					"todo_id":    list.ID,
+					"todo_name":  list.Name,
+					"task_index": strconv.Itoa(task.Index),
+					"task_name":  task.Name,
+					"due_at":     task.DueAt.UTC().Format(time.RFC3339),
+				},
+			})
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+	errs, err := messaging.SendDataMessages(receiver.FirebaseApp, params)
+	if err != nil {
+		log.Error("ToDoReminderUseCase.remind ", id, " ", err)
+		return
+	}
+	for i, err := range errs {
+		if err != nil {
+			log.Error("ToDoReminderUseCase.remind ", params[i].Data["device_id"], " ", err)
+		}
+	}
+}
+
+// recipients returns the tasks to remind each device of: the devices of the
+// members a task is assigned to, but those that completed it
+func (receiver *ToDoReminderUseCase) recipients(list entity.SToDo, tasks []entity.Task) (map[string][]entity.Task, error) {
+	assignment, err := loadToDoAssignment(receiver.DB, receiver.ToDoRepository, list)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := receiver.ToDoRepository.GetProgress(receiver.DB, list.ID)
+	if err != nil {
+		return nil, err
+	}
+	done := toDoProgressByMember(progress)
+
+	recipients := make(map[string][]entity.Task)
+	for _, task := range tasks {
+		reminded := make(map[string]bool)
+		for _, member := range assignment.taskMembers(task) {
+			if _, ok := done[toDoMemberKey(member.Type, member.Id)][task.Index]; ok {
+				continue
+			}
+			for _, deviceId := range member.DeviceIds {
+				if !reminded[deviceId] {
+					reminded[deviceId] = true
+					recipients[deviceId] = append(recipients[deviceId], task)
+				}
+			}
+		}
+	}
+
+	return recipients, nil
+}
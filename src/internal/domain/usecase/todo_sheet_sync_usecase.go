@@ -0,0 +1,663 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/monitor"
+	"sen-global-api/pkg/sheet"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	toDoSheetSyncMaxAttempts = 10
+	toDoSheetSyncBaseBackoff = time.Minute
+	toDoSheetSyncMaxBackoff  = 6 * time.Hour
+	toDoSheetSyncBatchSize   = 50
+	// toDoSheetSyncStaleAfter releases the syncs left in syncing by a restart
+	toDoSheetSyncStaleAfter = 5 * time.Minute
+)
+
+var ErrInvalidToDoSheetSync = errors.New("invalid to-do sheet sync")
+
+// ToDoCompletionSyncPayload is a task marked as done, to write to the task sheet
+// and the history sheet
+type ToDoCompletionSyncPayload struct {
+	TaskIndex   int       `json:"task_index"`
+	TaskName    string    `json:"task_name"`
+	DueDate     string    `json:"due_date"`
+	Value       string    `json:"value"`
+	Selected    string    `json:"selected"`
+	DeviceId    string    `json:"device_id"`
+	DeviceName  string    `json:"device_name"`
+	DeviceNote  string    `json:"device_note"`
+	CompletedAt time.Time `json:"completed_at"`
+	// HistoryOnly is set for an assigned task, the task sheet shows the tasks
+	// done for everybody only
+	HistoryOnly bool `json:"history_only,omitempty"`
+}
+
+// ToDoTaskLogSyncPayload is a change of a task made on a device, to append to
+// the history sheet
+type ToDoTaskLogSyncPayload struct {
+	Name       string              `json:"name"`
+	DueDate    string              `json:"due_date"`
+	Value      string              `json:"value"`
+	LogType    request.LogTaskType `json:"log_type"`
+	DeviceId   string              `json:"device_id"`
+	DeviceName string              `json:"device_name"`
+	DeviceNote string              `json:"device_note"`
+	LoggedAt   time.Time           `json:"logged_at"`
+}
+
+// ToDoSheetSyncUseCase mirrors the changes of the to-do lists to their
+// spreadsheets out of the request path. Each change is queued as a sync and
+// tried right away, failed ones are retried with an exponential backoff by the
+// time machine until toDoSheetSyncMaxAttempts is reached.
+type ToDoSheetSyncUseCase struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+	sheet.SpreadsheetReader
+	sheet.SpreadsheetWriter
+	Files sheet.FileStore
+}
+
+func NewToDoSheetSyncUseCase(db *gorm.DB, defaultRequestPageSize int, spreadsheet *sheet.Spreadsheet) *ToDoSheetSyncUseCase {
+	return &ToDoSheetSyncUseCase{
+		DBConn:                 db,
+		DefaultRequestPageSize: defaultRequestPageSize,
+		SpreadsheetReader:      spreadsheet.Reader,
+		SpreadsheetWriter:      spreadsheet.Writer,
+		Files:                  spreadsheet.Files,
+	}
+}
+
+// EnqueueToDoSheetSync hands a change of the list to TheToDoSheetSyncUseCase, it
+// never fails the caller
+func EnqueueToDoSheetSync(list entity.SToDo, kind value.ToDoSheetSyncKind, payload interface{}) {
+	if TheToDoSheetSyncUseCase == nil {
+		return
+	}
+
+	TheToDoSheetSyncUseCase.Enqueue(list, kind, payload)
+}
+
+// Enqueue queues a change of the list and tries it in the background. Nothing
+// is queued when the sync of the list is disabled or the list has no
+// spreadsheet for the change, and a rewrite of the tasks is not queued twice.
+func (receiver *ToDoSheetSyncUseCase) Enqueue(list entity.SToDo, kind value.ToDoSheetSyncKind, payload interface{}) {
+	if list.SheetSyncDisabled || !toDoMirrors(list, kind) {
+		return
+	}
+
+	syncRepository := receiver.repository()
+	if kind == value.ToDoSheetSyncKind_Tasks {
+		pending, err := syncRepository.HasPendingSync(list.ID, kind)
+		if err != nil {
+			log.Error("ToDoSheetSyncUseCase.Enqueue cannot check the syncs of ", list.ID, " ", err)
+		}
+		if pending {
+			return
+		}
+	}
+
+	var data []byte
+	if payload != nil {
+		var err error
+		data, err = json.Marshal(payload)
+		if err != nil {
+			log.Error("ToDoSheetSyncUseCase.Enqueue cannot encode ", kind, " of ", list.ID, " ", err)
+			return
+		}
+	}
+
+	now := time.Now()
+	sync := &entity.SToDoSheetSync{
+		ToDoId:        list.ID,
+		Kind:          kind,
+		Payload:       data,
+		Status:        value.ToDoSheetSyncStatus_Pending,
+		NextAttemptAt: &now,
+	}
+	err := syncRepository.CreateSync(sync)
+	if err != nil {
+		log.Error("ToDoSheetSyncUseCase.Enqueue cannot queue ", kind, " of ", list.ID, " ", err)
+		return
+	}
+
+	go receiver.Sync(sync.ID)
+}
+
+func (receiver *ToDoSheetSyncUseCase) GetSyncs(todoId string, req request.GetToDoSheetSyncsRequest) ([]entity.SToDoSheetSync, *response.Pagination, error) {
+	var status *value.ToDoSheetSyncStatus
+	if req.Status != "" {
+		syncStatus, err := value.GetToDoSheetSyncStatusFromString(req.Status)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidToDoSheetSync, err.Error())
+		}
+		status = &syncStatus
+	}
+
+	return receiver.repository().GetSyncs(todoId, status, req.Page, req.Limit)
+}
+
+// Retry puts a failed sync of the list back in the queue and tries it again
+func (receiver *ToDoSheetSyncUseCase) Retry(todoId string, id uint64) (*entity.SToDoSheetSync, error) {
+	syncRepository := receiver.repository()
+	sync, err := syncRepository.GetSync(id)
+	if err != nil {
+		return nil, err
+	}
+	if sync.ToDoId != todoId {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if sync.Status != value.ToDoSheetSyncStatus_Failed {
+		return nil, fmt.Errorf("%w: only a failed sync can be retried", ErrInvalidToDoSheetSync)
+	}
+
+	err = syncRepository.RetrySync(id)
+	if err != nil {
+		return nil, err
+	}
+	receiver.Sync(id)
+
+	return syncRepository.GetSync(id)
+}
+
+// ExecuteToDoSheetSyncs retries the syncs that are due, it is run by the time
+// machine
+func (receiver *ToDoSheetSyncUseCase) ExecuteToDoSheetSyncs() {
+	now := time.Now()
+	ids, err := receiver.repository().GetDueSyncIDs(now, now.Add(-toDoSheetSyncStaleAfter), toDoSheetSyncBatchSize)
+	if err != nil {
+		log.Error("ToDoSheetSyncUseCase.ExecuteToDoSheetSyncs ", err)
+		return
+	}
+
+	for _, id := range ids {
+		receiver.Sync(id)
+	}
+}
+
+// Sync makes one attempt at a pending sync and records the outcome
+func (receiver *ToDoSheetSyncUseCase) Sync(id uint64) {
+	syncRepository := receiver.repository()
+	claimed, err := syncRepository.ClaimSync(id)
+	if err != nil || !claimed {
+		if err != nil {
+			log.Error("ToDoSheetSyncUseCase.Sync cannot claim sync ", id, " ", err)
+		}
+		return
+	}
+
+	sync, err := syncRepository.GetSync(id)
+	if err != nil {
+		log.Error("ToDoSheetSyncUseCase.Sync cannot load sync ", id, " ", err)
+		return
+	}
+
+	err = receiver.mirror(*sync)
+
+	now := time.Now()
+	sync.Attempts++
+	sync.UpdatedAt = now
+	switch {
+	case err == nil:
+		sync.Status = value.ToDoSheetSyncStatus_Succeeded
+		sync.NextAttemptAt = nil
+		sync.LastError = ""
+		sync.SyncedAt = &now
+	case sync.Attempts >= toDoSheetSyncMaxAttempts:
+		sync.Status = value.ToDoSheetSyncStatus_Failed
+		sync.NextAttemptAt = nil
+		sync.LastError = err.Error()
+		monitor.SendMessageViaTelegram("ToDo ", sync.ToDoId, " sheet sync ", strconv.FormatUint(sync.ID, 10), " failed: ", err.Error())
+	default:
+		next := now.Add(toDoSheetSyncBackoff(sync.Attempts))
+		sync.Status = value.ToDoSheetSyncStatus_Pending
+		sync.NextAttemptAt = &next
+		sync.LastError = err.Error()
+	}
+
+	err = syncRepository.SaveSyncAttempt(sync)
+	if err != nil {
+		log.Error("ToDoSheetSyncUseCase.Sync cannot record sync ", id, " ", err)
+	}
+}
+
+// mirror writes the change of the sync to the spreadsheets of the list as the
+// list is now
+func (receiver *ToDoSheetSyncUseCase) mirror(sync entity.SToDoSheetSync) error {
+	todo, err := (&repository.ToDoRepository{}).FindById(sync.ToDoId, receiver.DBConn)
+	if err != nil {
+		return err
+	}
+	if todo.SheetSyncDisabled {
+		return nil
+	}
+
+	switch sync.Kind {
+	case value.ToDoSheetSyncKind_Completion:
+		var completion ToDoCompletionSyncPayload
+		err = json.Unmarshal(sync.Payload, &completion)
+		if err != nil {
+			return err
+		}
+		return receiver.writeCompletion(*todo, completion)
+	case value.ToDoSheetSyncKind_TaskLog:
+		var taskLog ToDoTaskLogSyncPayload
+		err = json.Unmarshal(sync.Payload, &taskLog)
+		if err != nil {
+			return err
+		}
+		return receiver.writeTaskLog(*todo, taskLog)
+	case value.ToDoSheetSyncKind_Tasks:
+		return receiver.writeTasks(*todo)
+	}
+
+	return fmt.Errorf("%w: unknown kind %s", ErrInvalidToDoSheetSync, sync.Kind)
+}
+
+// writeCompletion writes the completed task to its row of the task sheet, then
+// appends it to the history sheet
+func (receiver *ToDoSheetSyncUseCase) writeCompletion(todo entity.SToDo, completion ToDoCompletionSyncPayload) error {
+	if todo.SpreadsheetID != "" && !completion.HistoryOnly {
+		values, err := receiver.Get(sheet.ReadSpecificRangeParams{
+			SpreadsheetId: todo.SpreadsheetID,
+			ReadRange:     todo.SheetName + `!K12:K1000`,
+		})
+		if err != nil {
+			return err
+		}
+
+		completedRowNo, err := findFirstRow(strconv.Itoa(completion.TaskIndex), values, 12)
+		if err != nil {
+			return err
+		}
+
+		completedData := make([][]interface{}, 0)
+		completedData = append(completedData, []interface{}{completion.Selected})
+		completedData = append(completedData, []interface{}{completion.CompletedAt.Format("2006-01-02 15:04:05")})
+		completedData = append(completedData, []interface{}{completion.Selected})
+		completedData = append(completedData, []interface{}{nil})
+		completedData = append(completedData, []interface{}{nil})
+		completedData = append(completedData, []interface{}{nil})
+		completedData = append(completedData, []interface{}{completion.DeviceId})
+		_, err = receiver.UpdateRange(sheet.WriteRangeParams{
+			Range:     todo.SheetName + "!P" + strconv.Itoa(completedRowNo) + ":W",
+			Dimension: "COLUMNS",
+			Rows:      completedData,
+		}, todo.SpreadsheetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if todo.HistorySpreadsheetID == "" {
+		return nil
+	}
+
+	historyData := toDoHistoryRow(todo, completion.CompletedAt, completion.DeviceId, completion.DeviceName, completion.DeviceNote)
+	historyData = append(historyData, []interface{}{completion.TaskName})
+	historyData = append(historyData, []interface{}{completion.DueDate})
+	historyData = append(historyData, []interface{}{completion.Value})
+	historyData = append(historyData, []interface{}{completion.Selected})
+
+	_, err := receiver.WriteRanges(sheet.WriteRangeParams{
+		Range:     todo.HistorySheetName + "!K11",
+		Dimension: "COLUMNS",
+		Rows:      historyData,
+	}, todo.HistorySpreadsheetID)
+
+	return err
+}
+
+// writeTaskLog appends the change of a task made on a device to the history
+// sheet
+func (receiver *ToDoSheetSyncUseCase) writeTaskLog(todo entity.SToDo, taskLog ToDoTaskLogSyncPayload) error {
+	if todo.HistorySpreadsheetID == "" || todo.HistorySheetName == "" {
+		return nil
+	}
+
+	historyData := toDoHistoryRow(todo, taskLog.LoggedAt, taskLog.DeviceId, taskLog.DeviceName, taskLog.DeviceNote)
+	historyData = append(historyData, []interface{}{taskLog.Name})
+	historyData = append(historyData, []interface{}{taskLog.DueDate})
+	historyData = append(historyData, []interface{}{taskLog.Value})
+	historyData = append(historyData, []interface{}{""})
+	historyData = append(historyData, []interface{}{string(taskLog.LogType)})
+	historyData = append(historyData, []interface{}{""})
+
+	_, err := receiver.WriteRanges(sheet.WriteRangeParams{
+		Range:     todo.HistorySheetName + "!K11",
+		Dimension: "COLUMNS",
+		Rows:      historyData,
+	}, todo.HistorySpreadsheetID)
+
+	return err
+}
+
+// writeTasks rewrites the name and the tasks of the task sheet from the
+// database. A compose list without a spreadsheet gets one made from the
+// template, linked from the to-do uploader.
+func (receiver *ToDoSheetSyncUseCase) writeTasks(todo entity.SToDo) error {
+	var err error
+	switch {
+	case todo.Type == value.ToDoTypeAssign && todo.SpreadsheetID != "":
+		err = receiver.writeAssignTasks(todo)
+	case todo.Type == value.ToDoTypeCompose:
+		err = receiver.writeComposeTasks(&todo)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = receiver.UpdateRange(sheet.WriteRangeParams{
+		Range:     todo.SheetName + "!K11",
+		Dimension: "ROWS",
+		Rows:      [][]interface{}{{todo.Name}},
+	}, todo.SpreadsheetID)
+
+	return err
+}
+
+// writeComposeTasks replaces the tasks of the task sheet of a compose list, the
+// spreadsheet made for a list without one is set on todo
+func (receiver *ToDoSheetSyncUseCase) writeComposeTasks(todo *entity.SToDo) error {
+	if todo.SpreadsheetID == "" {
+		spreadsheetId, err := receiver.createToDoSpreadsheet(*todo)
+		if err != nil {
+			return err
+		}
+		todo.SpreadsheetID = spreadsheetId
+	} else {
+		_, err := receiver.ClearRange(sheet.ClearRangeParams{
+			SpreadsheetId: todo.SpreadsheetID,
+			Range:         todo.SheetName + "!I13:V500",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	todoItems := make([][]interface{}, 0)
+	for _, t := range todo.Tasks.Data.Tasks {
+		todoItems = append(todoItems, []interface{}{t.Index, t.Name, toDoSheetDueDate(t.DueDate), t.Value, t.Selection, t.Selected})
+	}
+	if len(todoItems) == 0 {
+		return nil
+	}
+
+	_, err := receiver.UpdateRange(sheet.WriteRangeParams{
+		Range:     todo.SheetName + "!K13",
+		Dimension: "ROWS",
+		Rows:      todoItems,
+	}, todo.SpreadsheetID)
+
+	return err
+}
+
+// writeAssignTasks updates the rows of the task sheet of an assign list in
+// place, so that the layout of the sheet is kept: the row of a task is found by
+// its index, the rows of the deleted tasks are emptied and the new tasks are
+// written after the last row
+func (receiver *ToDoSheetSyncUseCase) writeAssignTasks(todo entity.SToDo) error {
+	startRow := toDoStartRow(todo)
+	values, err := receiver.Get(sheet.ReadSpecificRangeParams{
+		SpreadsheetId: todo.SpreadsheetID,
+		ReadRange:     todo.SheetName + "!K" + strconv.Itoa(startRow) + ":V1000",
+	})
+	if err != nil {
+		return err
+	}
+
+	tasks := make(map[string]entity.Task, len(todo.Tasks.Data.Tasks))
+	for _, t := range todo.Tasks.Data.Tasks {
+		tasks[strconv.Itoa(t.Index)] = t
+	}
+	written := make(map[string]bool, len(tasks))
+	rows := make([][]interface{}, 0, len(values)+len(tasks))
+	for _, existing := range values {
+		row := emptyToDoSheetRow()
+		copy(row, existing)
+		index := ""
+		if len(existing) > 0 {
+			index = fmt.Sprint(existing[0])
+		}
+		if _, err := strconv.Atoi(index); err == nil {
+			task, ok := tasks[index]
+			if ok && !written[index] {
+				fillToDoSheetRow(row, task)
+				written[index] = true
+			} else {
+				row = emptyToDoSheetRow()
+			}
+		}
+		rows = append(rows, row)
+	}
+	for _, t := range todo.Tasks.Data.Tasks {
+		if written[strconv.Itoa(t.Index)] {
+			continue
+		}
+		row := emptyToDoSheetRow()
+		fillToDoSheetRow(row, t)
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err = receiver.UpdateRange(sheet.WriteRangeParams{
+		Range:     todo.SheetName + "!K" + strconv.Itoa(startRow),
+		Dimension: "ROWS",
+		Rows:      rows,
+	}, todo.SpreadsheetID)
+
+	return err
+}
+
+// createToDoSpreadsheet makes the task sheet of a compose list from the
+// template in the output folder, saves it to the list and links it from the row
+// of the list in the to-do uploader
+func (receiver *ToDoSheetSyncUseCase) createToDoSpreadsheet(todo entity.SToDo) (string, error) {
+	settingRepository := (&repository.SettingRepository{DBConn: receiver.DBConn}).ForOrganization(todo.OrganizationId)
+	importTodoSetting, err := settingRepository.GetSyncToDosSettings()
+	if err != nil {
+		return "", err
+	}
+	outputSettingsData, err := settingRepository.GetOutputSettings()
+	if err != nil {
+		return "", err
+	}
+
+	var outputSettings OutputSetting
+	if outputSettingsData != nil {
+		err = json.Unmarshal([]byte(outputSettingsData.Settings), &outputSettings)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(pwd + "/config/todo_template.xlsx")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	spreadsheetId, err := receiver.Files.ImportSpreadsheet(todo.ID+".xlsx", outputSettings.FolderId, file)
+	if err != nil {
+		return "", err
+	}
+
+	err = receiver.DBConn.Model(&entity.SToDo{}).Where("id = ?", todo.ID).Update("spreadsheet_id", spreadsheetId).Error
+	if err != nil {
+		return "", err
+	}
+
+	if importTodoSetting == nil {
+		return spreadsheetId, nil
+	}
+	var importSetting ImportSetting
+	err = json.Unmarshal([]byte(importTodoSetting.Settings), &importSetting)
+	if err != nil {
+		return "", err
+	}
+
+	match := regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9-_]+)`).FindStringSubmatch(importSetting.SpreadSheetUrl)
+	if len(match) < 2 {
+		log.Error("ToDo ", todo.ID, " cannot be linked from the TODO uploader, invalid spreadsheet url in import todo setting")
+		return spreadsheetId, nil
+	}
+	todoUploaderSpreadsheetId := match[1]
+
+	readColumnsK, err := receiver.Get(sheet.ReadSpecificRangeParams{
+		SpreadsheetId: todoUploaderSpreadsheetId,
+		ReadRange:     "TODOs!K12:K1000",
+	})
+	if err != nil {
+		return "", err
+	}
+	rowNo, err := findFirstRow(todo.ID, readColumnsK, 12)
+	if err != nil {
+		log.Error("TODO ", todo.ID, " does not exist from the TODO uploader")
+		return spreadsheetId, nil
+	}
+
+	_, err = receiver.UpdateRange(sheet.WriteRangeParams{
+		Range:     "TODOs!L" + strconv.Itoa(rowNo),
+		Dimension: "COLUMNS",
+		Rows:      [][]interface{}{{"https://docs.google.com/spreadsheets/d/" + spreadsheetId}},
+	}, todoUploaderSpreadsheetId)
+	if err != nil {
+		return "", err
+	}
+
+	return spreadsheetId, nil
+}
+
+func (receiver *ToDoSheetSyncUseCase) repository() *repository.ToDoSheetSyncRepository {
+	return &repository.ToDoSheetSyncRepository{DBConn: receiver.DBConn, DefaultRequestPageSize: receiver.DefaultRequestPageSize}
+}
+
+// toDoMirrors reports whether the list has the spreadsheet the change is
+// written to
+func toDoMirrors(list entity.SToDo, kind value.ToDoSheetSyncKind) bool {
+	switch kind {
+	case value.ToDoSheetSyncKind_Completion:
+		return list.SpreadsheetID != "" || list.HistorySpreadsheetID != ""
+	case value.ToDoSheetSyncKind_TaskLog:
+		return list.HistorySpreadsheetID != "" && list.HistorySheetName != ""
+	case value.ToDoSheetSyncKind_Tasks:
+		return list.SpreadsheetID != "" || list.Type == value.ToDoTypeCompose
+	}
+
+	return false
+}
+
+// toDoHistoryRow is the start of a row of the history sheet, the columns of the
+// task follow
+func toDoHistoryRow(todo entity.SToDo, at time.Time, deviceId string, deviceName string, deviceNote string) [][]interface{} {
+	historyData := make([][]interface{}, 0)
+	historyData = append(historyData, []interface{}{at.Format("2006-01-02 15:04:05")})
+	historyData = append(historyData, []interface{}{deviceId})
+	historyData = append(historyData, []interface{}{deviceName})
+	historyData = append(historyData, []interface{}{deviceNote})
+	historyData = append(historyData, []interface{}{nil})
+	historyData = append(historyData, []interface{}{nil})
+	historyData = append(historyData, []interface{}{nil})
+	historyData = append(historyData, []interface{}{todo.ID})
+	historyData = append(historyData, []interface{}{todo.SheetName})
+	historyData = append(historyData, []interface{}{"https://docs.google.com/spreadsheets/d/" + todo.SpreadsheetID})
+
+	return historyData
+}
+
+// toDoSheetColumns is the width of a task row of the task sheet, K to V
+const toDoSheetColumns = 12
+
+func emptyToDoSheetRow() []interface{} {
+	row := make([]interface{}, toDoSheetColumns)
+	for i := range row {
+		row[i] = ""
+	}
+
+	return row
+}
+
+// fillToDoSheetRow writes the task to K:P of the row and its completion to P:V,
+// as a completion sync does
+func fillToDoSheetRow(row []interface{}, task entity.Task) {
+	row[0] = task.Index
+	row[1] = task.Name
+	row[2] = toDoSheetDueDate(task.DueDate)
+	row[3] = task.Value
+	row[4] = task.Selection
+	row[5] = task.Selected
+	row[6] = ""
+	row[7] = ""
+	row[11] = ""
+	if task.CompletedAt != nil {
+		row[6] = task.CompletedAt.Format("2006-01-02 15:04:05")
+		row[7] = task.Selected
+		row[11] = task.CompletedBy
+	}
+}
+
+// toDoSheetDueDate formats a due date the way the import reads it back
+func toDoSheetDueDate(dueDate string) string {
+	date, err := time.Parse("2006-01-02 15:04:05", dueDate)
+	if err != nil {
+		return dueDate
+	}
+
+	return date.Format("1/2/2006 15:04")
+}
+
+func toDoStartRow(todo entity.SToDo) int {
+	if todo.StartRow > 0 {
+		return todo.StartRow
+	}
+
+	return 13
+}
+
+func findFirstRow(id string, values [][]interface{}, startRow int) (int, error) {
+	rowNo := 0
+	for rowindex, row := range values {
+		if len(row) > 0 {
+			if row[0].(string) == id {
+				return rowindex + startRow, nil
+			}
+		}
+	}
+	return rowNo, errors.New("Cannot determine row number for todo index: " + id)
+}
+
+// toDoSheetSyncBackoff is the wait before the attempt after the given one: 1m,
+// 2m, 4m... capped at toDoSheetSyncMaxBackoff
+func toDoSheetSyncBackoff(attempts int) time.Duration {
+	backoff := toDoSheetSyncBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= toDoSheetSyncMaxBackoff {
+			return toDoSheetSyncMaxBackoff
+		}
+	}
+
+	return backoff
+}
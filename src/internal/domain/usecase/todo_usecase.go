@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidToDo      = errors.New("invalid to-do list")
+	ErrToDoExists       = errors.New("a to-do list already has this QR code")
+	ErrToDoTaskNotFound = errors.New("the to-do list has no task with this index")
+	ErrToDoNotAssigned  = errors.New("the task is not assigned to the device or its users")
+)
+
+// ToDoUseCase manages the to-do lists and their tasks in the database, the
+// changes are mirrored to the spreadsheets of a list by the sheet syncs
+type ToDoUseCase struct {
+	ToDoRepository         *repository.ToDoRepository
+	SheetSync              *ToDoSheetSyncUseCase
+	DB                     *gorm.DB
+	DefaultRequestPageSize int
+}
+
+func NewToDoUseCase(db *gorm.DB, defaultRequestPageSize int, sheetSync *ToDoSheetSyncUseCase) *ToDoUseCase {
+	return &ToDoUseCase{
+		ToDoRepository:         &repository.ToDoRepository{},
+		SheetSync:              sheetSync,
+		DB:                     db,
+		DefaultRequestPageSize: defaultRequestPageSize,
+	}
+}
+
+// GetToDos lists the to-do lists the scope sees, those of the organization of
+// req and the shared ones when it is given
+func (receiver *ToDoUseCase) GetToDos(scope value.AccessScope, req request.GetToDoListRequest) ([]entity.SToDo, *response.Pagination, error) {
+	conn := repository.ScopeAccess(receiver.DB, scope)
+	if req.OrganizationId != nil {
+		conn = repository.ScopeOrganization(receiver.DB, req.OrganizationId)
+	}
+
+	todos, paging, err := receiver.ToDoRepository.GetToDos(conn, req, receiver.DefaultRequestPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	withDueTimes(receiver.DB, todos)
+
+	return todos, paging, nil
+}
+
+// GetToDo returns the list when the scope owns it
+func (receiver *ToDoUseCase) GetToDo(scope value.AccessScope, id string) (*entity.SToDo, error) {
+	todo, err := receiver.ToDoRepository.FindById(id, repository.ScopeAccess(receiver.DB, scope))
+	if err != nil {
+		return nil, err
+	}
+	if !scope.Owns(todo.OrganizationId) {
+		return nil, ErrOutOfScope
+	}
+	lists := []entity.SToDo{*todo}
+	withDueTimes(receiver.DB, lists)
+
+	return &lists[0], nil
+}
+
+// CreateToDo creates a list of the organization, its tasks are indexed in the
+// order they are given
+func (receiver *ToDoUseCase) CreateToDo(organizationId *int64, req request.CreateToDoRequest) (*entity.SToDo, error) {
+	id := strings.TrimSpace(req.Id)
+	if id == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidToDo)
+	}
+	todoType := value.ToDoTypeAssign
+	if req.Type != "" {
+		var err error
+		todoType, err = value.GetToDoTypeFromString(req.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToDo, err.Error())
+		}
+	}
+
+	_, err := receiver.ToDoRepository.FindById(id, receiver.DB)
+	if err == nil {
+		return nil, ErrToDoExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	location := organizationLocation(receiver.DB, organizationId)
+	tasks := make([]entity.Task, 0, len(req.Tasks))
+	for index, t := range req.Tasks {
+		task, err := receiver.toDoTask(index, t, location)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	now := time.Now()
+	todo := &entity.SToDo{
+		ID:                   id,
+		Name:                 req.Name,
+		Type:                 todoType,
+		SpreadsheetID:        req.SpreadsheetID,
+		SheetName:            defaultString(req.SheetName, "Tasks"),
+		Tasks:                datatypes.JSONType[entity.STasks]{Data: entity.STasks{Tasks: tasks}},
+		HistorySpreadsheetID: req.HistorySpreadsheetID,
+		HistorySheetName:     defaultString(req.HistorySheetName, "Answers"),
+		StartRow:             13,
+		TasksEditedAt:        &now,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	err = (&repository.ToDoRepository{OrganizationId: organizationId}).Create(receiver.DB, todo)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) > 0 || todo.Name != "" {
+		EnqueueToDoSheetSync(*todo, value.ToDoSheetSyncKind_Tasks, nil)
+	}
+
+	return todo, nil
+}
+
+// UpdateToDo renames the list and turns its sheet sync on or off, the task sheet
+// is brought up to date when the sync is turned back on
+func (receiver *ToDoUseCase) UpdateToDo(scope value.AccessScope, id string, req request.UpdateToDoRequest) (*entity.SToDo, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidToDo)
+	}
+
+	resumed := false
+	todo, err := receiver.updateOwned(scope, id, func(list *entity.SToDo) error {
+		if req.Name != nil {
+			list.Name = *req.Name
+		}
+		if req.SheetSyncDisabled != nil {
+			resumed = list.SheetSyncDisabled && !*req.SheetSyncDisabled
+			list.SheetSyncDisabled = *req.SheetSyncDisabled
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil || resumed {
+		EnqueueToDoSheetSync(*todo, value.ToDoSheetSyncKind_Tasks, nil)
+	}
+
+	return todo, nil
+}
+
+func (receiver *ToDoUseCase) DeleteToDo(scope value.AccessScope, id string) (*entity.SToDo, error) {
+	todo, err := receiver.GetToDo(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = receiver.ToDoRepository.Delete(receiver.DB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// AddTask adds a task at the end of the list, with the next index
+func (receiver *ToDoUseCase) AddTask(scope value.AccessScope, id string, req request.SaveToDoTask) (*entity.SToDo, *entity.Task, error) {
+	var added entity.Task
+	todo, err := receiver.updateTasks(scope, id, func(tasks []entity.Task, location *time.Location) ([]entity.Task, error) {
+		var err error
+		added, err = receiver.toDoTask(nextTaskIndex(tasks), req, location)
+		if err != nil {
+			return nil, err
+		}
+		return append(tasks, added), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return todo, &added, nil
+}
+
+// UpdateTask changes the task with the index, its completion is kept and its
+// reminder is sent again
+func (receiver *ToDoUseCase) UpdateTask(scope value.AccessScope, id string, index int, req request.SaveToDoTask) (*entity.SToDo, *entity.Task, error) {
+	var updated entity.Task
+	todo, err := receiver.updateTasks(scope, id, func(tasks []entity.Task, location *time.Location) ([]entity.Task, error) {
+		for i := range tasks {
+			if tasks[i].Index != index {
+				continue
+			}
+			assignees, err := receiver.toAssignees(req.Assignees)
+			if err != nil {
+				return nil, err
+			}
+			err = applyToDoTask(&tasks[i], req, location)
+			if err != nil {
+				return nil, err
+			}
+			tasks[i].Assignees = assignees
+			updated = tasks[i]
+			return tasks, nil
+		}
+		return nil, fmt.Errorf("%w: %d", ErrToDoTaskNotFound, index)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return todo, &updated, nil
+}
+
+// DeleteTask removes the task with the index and the progress of its assignees,
+// the indexes of the other tasks do not change
+func (receiver *ToDoUseCase) DeleteTask(scope value.AccessScope, id string, index int) (*entity.SToDo, error) {
+	todo, err := receiver.updateTasks(scope, id, func(tasks []entity.Task, _ *time.Location) ([]entity.Task, error) {
+		for i := range tasks {
+			if tasks[i].Index == index {
+				return append(tasks[:i], tasks[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %d", ErrToDoTaskNotFound, index)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, receiver.ToDoRepository.DeleteProgress(receiver.DB, id, index)
+}
+
+// GetAssignees lists the users, devices and device groups a list the scope owns
+// is assigned to
+func (receiver *ToDoUseCase) GetAssignees(scope value.AccessScope, id string) ([]entity.SToDoAssignee, error) {
+	if _, err := receiver.GetToDo(scope, id); err != nil {
+		return nil, err
+	}
+
+	return receiver.ToDoRepository.GetAssignees(receiver.DB, id)
+}
+
+// ReplaceAssignees assigns a list the scope owns to the users, devices and
+// device groups of req only
+func (receiver *ToDoUseCase) ReplaceAssignees(scope value.AccessScope, id string, req request.ReplaceToDoAssigneesRequest) (*entity.SToDo, []entity.SToDoAssignee, error) {
+	todo, err := receiver.GetToDo(scope, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskAssignees, err := receiver.toAssignees(req.Assignees)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	assignees := make([]entity.SToDoAssignee, 0, len(taskAssignees))
+	for _, a := range taskAssignees {
+		assignees = append(assignees, entity.SToDoAssignee{
+			ToDoId:       id,
+			AssigneeType: a.Type,
+			AssigneeId:   a.Id,
+			CreatedAt:    now,
+		})
+	}
+
+	err = receiver.ToDoRepository.ReplaceAssignees(receiver.DB, id, assignees)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return todo, assignees, nil
+}
+
+// GetProgress returns where each assignee of a list the scope owns is with each
+// task. The rows of a list assigned to nobody are the devices that completed
+// its tasks.
+func (receiver *ToDoUseCase) GetProgress(scope value.AccessScope, id string) (*response.ToDoProgressResponseData, error) {
+	todo, err := receiver.GetToDo(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := loadToDoAssignment(receiver.DB, receiver.ToDoRepository, *todo)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := receiver.ToDoRepository.GetProgress(receiver.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	done := toDoProgressByMember(progress)
+
+	members := assignment.allMembers()
+	others := make([]string, 0)
+	for _, p := range progress {
+		key := toDoMemberKey(p.AssigneeType, p.AssigneeId)
+		if _, ok := assignment.members[key]; !ok {
+			assignment.add(toDoMember{Type: p.AssigneeType, Id: p.AssigneeId})
+			members = append(members, assignment.members[key])
+			if p.AssigneeType == value.ToDoAssigneeType_Device {
+				others = append(others, p.AssigneeId)
+			}
+		}
+	}
+	devices, err := receiver.ToDoRepository.GetAssignableDevices(receiver.DB, others, nil)
+	if err != nil {
+		return nil, err
+	}
+	deviceNames := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceNames[device.ID] = device.DeviceName
+	}
+
+	now := time.Now()
+	tasks := make([]response.ToDoProgressTaskData, 0, len(todo.Tasks.Data.Tasks))
+	for _, task := range todo.Tasks.Data.Tasks {
+		tasks = append(tasks, response.ToDoProgressTaskData{Index: task.Index, Name: task.Name, DueAt: task.DueAt})
+	}
+	rows := make([]response.ToDoProgressRowData, 0, len(members))
+	for _, member := range members {
+		row := response.ToDoProgressRowData{
+			AssigneeType: string(member.Type),
+			AssigneeId:   member.Id,
+			Name:         member.Name,
+			Tasks:        make([]response.ToDoProgressCellData, 0, len(tasks)),
+		}
+		if row.Name == "" {
+			row.Name = deviceNames[member.Id]
+		}
+		for _, task := range todo.Tasks.Data.Tasks {
+			cell := response.ToDoProgressCellData{
+				TaskIndex: task.Index,
+				Assigned:  len(assignment.taskMembers(task)) == 0 || assignment.isTaskMember(task, member),
+			}
+			if p, ok := done[toDoMemberKey(member.Type, member.Id)][task.Index]; ok {
+				completedAt := p.CompletedAt
+				cell.Done = true
+				cell.Selected = p.Selected
+				cell.CompletedAt = &completedAt
+				cell.DeviceId = p.DeviceId
+			}
+			cell.Overdue = cell.Assigned && !cell.Done && task.DueAt != nil && task.DueAt.Before(now)
+			if cell.Assigned {
+				row.Assigned++
+			}
+			if cell.Done {
+				row.Completed++
+			}
+			row.Tasks = append(row.Tasks, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return &response.ToDoProgressResponseData{
+		ToDoId:    todo.ID,
+		Name:      todo.Name,
+		Tasks:     tasks,
+		Assignees: rows,
+	}, nil
+}
+
+// toAssignees validates the assignees given through the API, the repeated ones
+// are dropped
+func (receiver *ToDoUseCase) toAssignees(req []request.ToDoAssignee) ([]entity.TaskAssignee, error) {
+	seen := make(map[string]bool, len(req))
+	assignees := make([]entity.TaskAssignee, 0, len(req))
+	for _, a := range req {
+		assigneeType, err := value.GetToDoAssigneeTypeFromString(a.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToDo, err.Error())
+		}
+		assigneeId := strings.TrimSpace(a.Id)
+		if seen[toDoMemberKey(assigneeType, assigneeId)] {
+			continue
+		}
+		seen[toDoMemberKey(assigneeType, assigneeId)] = true
+
+		err = receiver.checkAssignee(assigneeType, assigneeId)
+		if err != nil {
+			return nil, err
+		}
+		assignees = append(assignees, entity.TaskAssignee{Type: assigneeType, Id: assigneeId})
+	}
+
+	return assignees, nil
+}
+
+func (receiver *ToDoUseCase) checkAssignee(assigneeType value.ToDoAssigneeType, assigneeId string) error {
+	var model interface{}
+	switch assigneeType {
+	case value.ToDoAssigneeType_User:
+		model = &entity.SUserEntity{}
+	case value.ToDoAssigneeType_Device:
+		model = &entity.SDevice{}
+	case value.ToDoAssigneeType_Group:
+		model = &entity.SDeviceGroup{}
+	}
+
+	var count int64
+	err := receiver.DB.Model(model).Where("id = ?", assigneeId).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no %s %s", ErrInvalidToDo, assigneeType, assigneeId)
+	}
+
+	return nil
+}
+
+// GetSheetSyncs lists the sheet syncs of a list the scope owns
+func (receiver *ToDoUseCase) GetSheetSyncs(scope value.AccessScope, id string, req request.GetToDoSheetSyncsRequest) ([]entity.SToDoSheetSync, *response.Pagination, error) {
+	if _, err := receiver.GetToDo(scope, id); err != nil {
+		return nil, nil, err
+	}
+
+	return receiver.SheetSync.GetSyncs(id, req)
+}
+
+// RetrySheetSync tries a failed sheet sync of a list the scope owns again
+func (receiver *ToDoUseCase) RetrySheetSync(scope value.AccessScope, id string, syncId uint64) (*entity.SToDoSheetSync, error) {
+	if _, err := receiver.GetToDo(scope, id); err != nil {
+		return nil, err
+	}
+
+	return receiver.SheetSync.Retry(id, syncId)
+}
+
+// updateTasks applies update to the tasks of a list the scope owns, marks them
+// as edited so that the import keeps them, and rewrites the task sheet. The
+// update gets the timezone of the organization of the list.
+func (receiver *ToDoUseCase) updateTasks(scope value.AccessScope, id string, update func(tasks []entity.Task, location *time.Location) ([]entity.Task, error)) (*entity.SToDo, error) {
+	todo, err := receiver.updateOwned(scope, id, func(list *entity.SToDo) error {
+		tasks, err := update(list.Tasks.Data.Tasks, organizationLocation(receiver.DB, list.OrganizationId))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		list.Tasks = datatypes.JSONType[entity.STasks]{Data: entity.STasks{Tasks: tasks}}
+		list.TasksEditedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	EnqueueToDoSheetSync(*todo, value.ToDoSheetSyncKind_Tasks, nil)
+
+	return todo, nil
+}
+
+func (receiver *ToDoUseCase) updateOwned(scope value.AccessScope, id string, update func(list *entity.SToDo) error) (*entity.SToDo, error) {
+	if _, err := receiver.GetToDo(scope, id); err != nil {
+		return nil, err
+	}
+
+	return receiver.ToDoRepository.UpdateToDo(receiver.DB, id, update)
+}
+
+func (receiver *ToDoUseCase) toDoTask(index int, req request.SaveToDoTask, location *time.Location) (entity.Task, error) {
+	assignees, err := receiver.toAssignees(req.Assignees)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	task := entity.Task{Index: index, Assignees: assignees}
+	err = applyToDoTask(&task, req, location)
+
+	return task, err
+}
+
+func defaultString(s string, fallback string) string {
+	if strings.TrimSpace(s) == "" {
+		return fallback
+	}
+
+	return s
+}
@@ -13,12 +13,12 @@ import (
 )
 
 type UpdateApiDistributorUseCase struct {
-	reader     *sheet.Reader
-	writer     *sheet.Writer
+	reader     sheet.SpreadsheetReader
+	writer     sheet.SpreadsheetWriter
 	repository *repository.SettingRepository
 }
 
-func NewUpdateApiDistributorUseCase(db *gorm.DB, r *sheet.Reader, w *sheet.Writer) *UpdateApiDistributorUseCase {
+func NewUpdateApiDistributorUseCase(db *gorm.DB, r sheet.SpreadsheetReader, w sheet.SpreadsheetWriter) *UpdateApiDistributorUseCase {
 	return &UpdateApiDistributorUseCase{
 		reader:     r,
 		writer:     w,
@@ -41,7 +41,7 @@ func (receiver *UpdateApiDistributorUseCase) Execute(url string) error {
 	return receiver.repository.UpdateAPIDistributerSetting(spreadsheetId, url)
 }
 
-func executeUploadAPIDistributor(repo *repository.SettingRepository, r *sheet.Reader, w *sheet.Writer) {
+func executeUploadAPIDistributor(repo *repository.SettingRepository, r sheet.SpreadsheetReader, w sheet.SpreadsheetWriter) {
 	s, err := repo.GetAPIDistributerSetting()
 	if err != nil {
 		log.Error("Could not find API Distributor setting")
@@ -68,7 +68,7 @@ func executeUploadAPIDistributor(repo *repository.SettingRepository, r *sheet.Re
 	}
 }
 
-func copyAPIDistributorAt(singleSheet sheet.SingleSheet, r *sheet.Reader, w *sheet.Writer, setting repository.APIDistributorSetting) {
+func copyAPIDistributorAt(singleSheet sheet.SingleSheet, r sheet.SpreadsheetReader, w sheet.SpreadsheetWriter, setting repository.APIDistributorSetting) {
 	targets, err := r.Get(sheet.ReadSpecificRangeParams{
 		SpreadsheetId: setting.SpreadSheetId,
 		ReadRange:     singleSheet.Title + "!M9:N",
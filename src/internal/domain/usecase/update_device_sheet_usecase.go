@@ -3,6 +3,7 @@ package usecase
 import (
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
 )
 
 type UpdateDeviceSheetUseCase struct {
@@ -10,9 +11,31 @@ type UpdateDeviceSheetUseCase struct {
 }
 
 func (receiver UpdateDeviceSheetUseCase) DeactivateDevice(deviceId string, req request.DeactivateDeviceRequest) error {
-	return receiver.DeviceRepository.DeactivateDevice(deviceId, req.Message)
+	err := receiver.DeviceRepository.DeactivateDevice(deviceId, req.Message)
+	if err != nil {
+		return err
+	}
+
+	PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+		"device_id": deviceId,
+		"status":    value.GetRawStatusValue(value.Inactive),
+		"message":   req.Message,
+	})
+
+	return nil
 }
 
 func (receiver UpdateDeviceSheetUseCase) ActivateDevice(deviceId string, req request.ReactivateDeviceRequest) error {
-	return receiver.DeviceRepository.ActivateDevice(deviceId, req.Message)
+	err := receiver.DeviceRepository.ActivateDevice(deviceId, req.Message)
+	if err != nil {
+		return err
+	}
+
+	PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+		"device_id": deviceId,
+		"status":    value.GetRawStatusValue(value.Active),
+		"message":   req.Message,
+	})
+
+	return nil
 }
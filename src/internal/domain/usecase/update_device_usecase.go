@@ -12,7 +12,7 @@ import (
 type UpdateDeviceUseCase struct {
 	*repository.DeviceRepository
 	*repository.SettingRepository
-	*sheet.Writer
+	sheet.SpreadsheetWriter
 }
 
 func (receiver *UpdateDeviceUseCase) UpdateDevice(deviceId string, req request.UpdateDeviceRequest) (*entity.SDevice, error) {
@@ -56,6 +56,7 @@ func (receiver *UpdateDeviceUseCase) UpdateDeviceV2(deviceId string, req request
 	if req.Note != nil {
 		device.Note = *req.Note
 	}
+	previousStatus := device.Status
 	if req.Status != nil {
 		_, err := value.GetDeviceModeFromString(*req.Status)
 		if err != nil {
@@ -91,5 +92,15 @@ func (receiver *UpdateDeviceUseCase) UpdateDeviceV2(deviceId string, req request
 	if err != nil {
 		return nil, err
 	}
+
+	if device.Status != previousStatus {
+		PublishWebhookEvent(value.WebhookEvent_DeviceStatusChanged, map[string]interface{}{
+			"device_id":       deviceId,
+			"status":          string(device.Status),
+			"previous_status": string(previousStatus),
+			"message":         device.DeactivateMessage,
+		})
+	}
+
 	return dv, nil
 }
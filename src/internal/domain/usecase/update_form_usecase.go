@@ -20,7 +20,7 @@ type UpdateFormUseCase struct {
 	*repository.FormRepository
 	*repository.QuestionRepository
 	*repository.FormQuestionRepository
-	SpreadsheetReader *sheet.Reader
+	SpreadsheetReader sheet.SpreadsheetReader
 }
 
 func (receiver *UpdateFormUseCase) UpdateForm(formId int, request request.UpdateFormRequest) (*entity.SForm, error) {
@@ -1,25 +1,24 @@
 package usecase
 
 import (
-	"context"
+	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"regexp"
 	"sen-global-api/config"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/pkg/monitor"
+	"sen-global-api/pkg/sheet"
 
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/option"
 )
 
 type UpdateOutputTemplateSettingForTeacherUseCase struct {
 	*repository.SettingRepository
 	config.AppConfig
+	Files sheet.FileStore
 }
 
 func (receiver *UpdateOutputTemplateSettingForTeacherUseCase) Execute(req request.UpdateOutputTemplateRequest) error {
@@ -29,10 +28,6 @@ func (receiver *UpdateOutputTemplateSettingForTeacherUseCase) Execute(req reques
 		monitor.SendMessageViaTelegram(fmt.Sprintf("Error getting current directory: %s", err))
 		return err
 	}
-	srv, err := drive.NewService(context.Background(), option.WithCredentialsFile(pwd+"/credentials/google_service_account.json"))
-	if err != nil {
-		log.Debug("Unable to access Drive API:", err)
-	}
 
 	re := regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9-_]+)`)
 	match := re.FindStringSubmatch(req.SpreadsheetUrl)
@@ -44,28 +39,20 @@ func (receiver *UpdateOutputTemplateSettingForTeacherUseCase) Execute(req reques
 
 	spreadsheetID := match[1]
 
-	// Export the Google Spreadsheet as a CSV file
-	resp, err := srv.Files.Export(spreadsheetID, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet").Download()
+	// Export the spreadsheet as an xlsx file
+	var template bytes.Buffer
+	err = receiver.Files.ExportSpreadsheet(spreadsheetID, &template)
 	if err != nil {
 		monitor.SendMessageViaTelegram(fmt.Sprintf("Error downloading spreadsheet: %s", err))
 		return err
 	}
-	defer resp.Body.Close()
 
-	// Create the output file
-	file, err := os.Create(pwd + "/config/output_template_teacher.xlsx")
+	// Replace the output file
+	err = os.WriteFile(pwd+"/config/output_template_teacher.xlsx", template.Bytes(), 0644)
 	if err != nil {
 		monitor.SendMessageViaTelegram(fmt.Sprintf("Error creating output file: %s", err))
 		return err
 	}
-	defer file.Close()
-
-	// Copy the response body to the output file
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		monitor.SendMessageViaTelegram(fmt.Sprintf("Error copying spreadsheet to output file: %s", err))
-		return err
-	}
 
 	//Update setting
 	err = receiver.UpdateOutputTemplateSettingForTeacher(spreadsheetID)
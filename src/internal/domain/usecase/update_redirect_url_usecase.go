@@ -1,9 +1,11 @@
 package usecase
 
 import (
+	"fmt"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/entity"
 	"sen-global-api/internal/domain/request"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -20,6 +22,18 @@ func (receiver *UpdateRedirectUrlUseCase) Update(id int, req request.UpdateRedir
 	if req.Password != nil {
 		form.Password = req.Password
 	}
+	if req.TargetUrl != nil {
+		if strings.TrimSpace(*req.TargetUrl) == "" {
+			return nil, fmt.Errorf("%w: target_url cannot be empty", ErrInvalidRedirectUrl)
+		}
+		form.TargetUrl = *req.TargetUrl
+	}
+	if req.Schedule != nil {
+		err = applyRedirectUrlSchedule(form, *req.Schedule)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	err = receiver.RedirectUrlRepository.Update(form)
 
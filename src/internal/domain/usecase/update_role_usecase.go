@@ -3,12 +3,23 @@ package usecase
 import (
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/value"
 )
 
 type UpdateRoleUseCase struct {
 	*repository.RoleRepository
 }
 
+// UpdateRole rejects renaming a role to SuperAdmin as well as renaming the
+// SuperAdmin role without an organization
 func (receiver *UpdateRoleUseCase) UpdateRole(req request.UpdateRoleRequest) error {
+	role, err := receiver.RoleRepository.GetByID(request.GetRoleByIdRequest{ID: req.ID})
+	if err != nil {
+		return err
+	}
+	if role.RoleName != req.RoleName && (value.IsReservedRoleName(req.RoleName) || (role.OrganizationId == 0 && value.IsReservedRoleName(role.RoleName))) {
+		return ErrReservedRoleName
+	}
+
 	return receiver.RoleRepository.UpdateRole(req)
 }
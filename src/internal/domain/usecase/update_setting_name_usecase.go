@@ -20,6 +20,14 @@ func NewUpdateSettingNameUseCase(db *gorm.DB) *UpdateSettingNameUseCase {
 	}
 }
 
+// ForOrganization returns the use case naming the settings of the organization
+func (u *UpdateSettingNameUseCase) ForOrganization(organizationId *int64) *UpdateSettingNameUseCase {
+	return &UpdateSettingNameUseCase{
+		db:         u.db,
+		repository: u.repository.ForOrganization(organizationId),
+	}
+}
+
 func (u *UpdateSettingNameUseCase) Execute(req request.UpdateSettingNameRequest) error {
 	if req.FormSetting != nil {
 		err := u.repository.SetName(*req.FormSetting, value.SettingTypeImportForms)
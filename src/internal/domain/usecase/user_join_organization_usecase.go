@@ -9,6 +9,11 @@ import (
 	"gorm.io/gorm"
 )
 
+var ErrPasswordRetired = errors.New("the organization only takes invitations")
+
+// UserJoinOrganizationUseCase is the legacy join with the shared password of an
+// organization, organizations that retired their password are joined with an
+// invitation, see OrganizationMembershipUseCase
 type UserJoinOrganizationUseCase struct {
 	*repository.OrganizationRepository
 	repository.SessionRepository
@@ -25,6 +30,10 @@ func (receiver *UserJoinOrganizationUseCase) UserJoinOrganization(req request.Us
 		return errors.New("failed to get organization")
 	}
 
+	if organization.Password == "" {
+		return ErrPasswordRetired
+	}
+
 	err = receiver.VerifyPassword(req.Password, organization.Password)
 	if err != nil {
 		return errors.New("invalid organization or password")
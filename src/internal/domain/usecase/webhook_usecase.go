@@ -0,0 +1,447 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/request"
+	"sen-global-api/internal/domain/response"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/pkg/randx"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookMaxAttempts    = 8
+	webhookBaseBackoff    = 30 * time.Second
+	webhookMaxBackoff     = 6 * time.Hour
+	webhookTimeout        = 10 * time.Second
+	webhookResponseLimit  = 2048
+	webhookRetryBatchSize = 100
+	// webhookStaleAfter releases deliveries left in delivering by a restart
+	webhookStaleAfter = 5 * time.Minute
+)
+
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// WebhookEventPayload is the body posted to a webhook
+type WebhookEventPayload struct {
+	Id        string             `json:"id"`
+	Event     value.WebhookEvent `json:"event"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      interface{}        `json:"data"`
+}
+
+// WebhookUseCase manages the webhook registry and delivers domain events to it.
+// Deliveries are tried right away, failed ones are retried with an exponential
+// backoff by the time machine until webhookMaxAttempts is reached.
+type WebhookUseCase struct {
+	DBConn                 *gorm.DB
+	DefaultRequestPageSize int
+	HTTPClient             *http.Client
+}
+
+func NewWebhookUseCase(db *gorm.DB, defaultRequestPageSize int) *WebhookUseCase {
+	return &WebhookUseCase{
+		DBConn:                 db,
+		DefaultRequestPageSize: defaultRequestPageSize,
+		HTTPClient:             &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// PublishWebhookEvent hands an event to TheWebhookUseCase in the background, it
+// never fails the caller
+func PublishWebhookEvent(event value.WebhookEvent, data interface{}) {
+	if TheWebhookUseCase == nil {
+		return
+	}
+
+	go TheWebhookUseCase.Publish(event, data)
+}
+
+func (receiver *WebhookUseCase) GetWebhooks() ([]entity.SWebhook, error) {
+	return receiver.repository().GetWebhooks()
+}
+
+func (receiver *WebhookUseCase) GetWebhook(id uint64) (*entity.SWebhook, error) {
+	return receiver.repository().GetWebhook(id)
+}
+
+func (receiver *WebhookUseCase) CreateWebhook(req request.SaveWebhookRequest) (*entity.SWebhook, error) {
+	webhook := &entity.SWebhook{Status: value.Active}
+	err := applyWebhookRequest(webhook, req)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret, err = newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = receiver.repository().CreateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (receiver *WebhookUseCase) UpdateWebhook(id uint64, req request.SaveWebhookRequest) (*entity.SWebhook, error) {
+	webhookRepository := receiver.repository()
+	webhook, err := webhookRepository.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyWebhookRequest(webhook, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = webhookRepository.UpdateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// RotateWebhookSecret replaces the secret of the webhook with a generated one.
+// Deliveries signed after the rotation use the new secret.
+func (receiver *WebhookUseCase) RotateWebhookSecret(id uint64) (*entity.SWebhook, error) {
+	webhookRepository := receiver.repository()
+	webhook, err := webhookRepository.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret, err = newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = webhookRepository.UpdateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (receiver *WebhookUseCase) DeleteWebhook(id uint64) error {
+	return receiver.repository().DeleteWebhook(id)
+}
+
+func (receiver *WebhookUseCase) GetDeliveries(webhookID uint64, req request.GetWebhookDeliveriesRequest) ([]entity.SWebhookDelivery, *response.Pagination, error) {
+	webhookRepository := receiver.repository()
+	_, err := webhookRepository.GetWebhook(webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return webhookRepository.GetDeliveries(webhookID, req.Status, req.Page, req.Limit)
+}
+
+func (receiver *WebhookUseCase) GetDelivery(id uint64) (*entity.SWebhookDelivery, error) {
+	return receiver.repository().GetDelivery(id)
+}
+
+// ReplayDelivery sends the payload of a logged delivery again as a new delivery,
+// the original entry of the log is left untouched
+func (receiver *WebhookUseCase) ReplayDelivery(id uint64) (*entity.SWebhookDelivery, error) {
+	webhookRepository := receiver.repository()
+	original, err := webhookRepository.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := &entity.SWebhookDelivery{
+		WebhookId:     original.WebhookId,
+		EventId:       original.EventId,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        value.WebhookDeliveryStatus_Pending,
+		NextAttemptAt: &now,
+		ReplayOf:      &original.ID,
+	}
+	err = webhookRepository.CreateDelivery(delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver.Deliver(delivery.ID)
+
+	return webhookRepository.GetDelivery(delivery.ID)
+}
+
+// Publish logs one delivery per active webhook subscribed to the event and tries
+// to send each of them
+func (receiver *WebhookUseCase) Publish(event value.WebhookEvent, data interface{}) {
+	webhookRepository := receiver.repository()
+	webhooks, err := webhookRepository.GetActiveWebhooks()
+	if err != nil {
+		log.Error("WebhookUseCase.Publish cannot load webhooks ", err)
+		return
+	}
+
+	subscribers := make([]entity.SWebhook, 0)
+	for _, webhook := range webhooks {
+		if webhookSubscribes(webhook, event) {
+			subscribers = append(subscribers, webhook)
+		}
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	now := time.Now()
+	eventId := uuid.NewString()
+	payload, err := json.Marshal(WebhookEventPayload{
+		Id:        eventId,
+		Event:     event,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		log.Error("WebhookUseCase.Publish cannot encode ", event, " ", err)
+		return
+	}
+
+	for _, webhook := range subscribers {
+		delivery := &entity.SWebhookDelivery{
+			WebhookId:     webhook.ID,
+			EventId:       eventId,
+			Event:         event,
+			Payload:       payload,
+			Status:        value.WebhookDeliveryStatus_Pending,
+			NextAttemptAt: &now,
+		}
+		err = webhookRepository.CreateDelivery(delivery)
+		if err != nil {
+			log.Error("WebhookUseCase.Publish cannot log delivery of ", event, " to webhook ", webhook.ID, " ", err)
+			continue
+		}
+
+		receiver.Deliver(delivery.ID)
+	}
+}
+
+// ExecuteWebhookDeliveries retries the deliveries that are due, it is run by the
+// time machine
+func (receiver *WebhookUseCase) ExecuteWebhookDeliveries() {
+	now := time.Now()
+	ids, err := receiver.repository().GetDueDeliveryIDs(now, now.Add(-webhookStaleAfter), webhookRetryBatchSize)
+	if err != nil {
+		log.Error("WebhookUseCase.ExecuteWebhookDeliveries ", err)
+		return
+	}
+
+	for _, id := range ids {
+		receiver.Deliver(id)
+	}
+}
+
+// Deliver makes one attempt at a pending delivery and records the outcome
+func (receiver *WebhookUseCase) Deliver(id uint64) {
+	webhookRepository := receiver.repository()
+	claimed, err := webhookRepository.ClaimDelivery(id)
+	if err != nil || !claimed {
+		if err != nil {
+			log.Error("WebhookUseCase.Deliver cannot claim delivery ", id, " ", err)
+		}
+		return
+	}
+
+	delivery, err := webhookRepository.GetDelivery(id)
+	if err != nil {
+		log.Error("WebhookUseCase.Deliver cannot load delivery ", id, " ", err)
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+
+	if delivery.Webhook.Status != value.Active {
+		delivery.Status = value.WebhookDeliveryStatus_Failed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook is inactive"
+	} else {
+		status, body, err := receiver.send(delivery.Webhook, delivery)
+		delivery.ResponseStatus = status
+		delivery.ResponseBody = body
+		delivery.LastError = ""
+		if err == nil && (status < 200 || status > 299) {
+			err = fmt.Errorf("webhook responded with status %d", status)
+		}
+
+		switch {
+		case err == nil:
+			delivery.Status = value.WebhookDeliveryStatus_Succeeded
+			delivery.NextAttemptAt = nil
+			delivery.DeliveredAt = &now
+		case delivery.Attempts >= webhookMaxAttempts:
+			delivery.Status = value.WebhookDeliveryStatus_Failed
+			delivery.NextAttemptAt = nil
+			delivery.LastError = err.Error()
+		default:
+			next := now.Add(webhookBackoff(delivery.Attempts))
+			delivery.Status = value.WebhookDeliveryStatus_Pending
+			delivery.NextAttemptAt = &next
+			delivery.LastError = err.Error()
+		}
+	}
+
+	err = webhookRepository.SaveDeliveryAttempt(delivery)
+	if err != nil {
+		log.Error("WebhookUseCase.Deliver cannot record delivery ", id, " ", err)
+	}
+}
+
+func (receiver *WebhookUseCase) send(webhook entity.SWebhook, delivery *entity.SWebhookDelivery) (int, string, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sen-global-api-webhook")
+	req.Header.Set(WebhookHeaderEvent, string(delivery.Event))
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	client := receiver.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+
+	return resp.StatusCode, string(body), nil
+}
+
+func (receiver *WebhookUseCase) repository() *repository.WebhookRepository {
+	return &repository.WebhookRepository{DBConn: receiver.DBConn, DefaultRequestPageSize: receiver.DefaultRequestPageSize}
+}
+
+// SignWebhookPayload signs "<timestamp>.<body>" with HMAC-SHA256. Receivers
+// recompute it from the X-Webhook-Timestamp header and the raw body.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait before the attempt after the given one: 30s, 1m, 2m...
+// capped at webhookMaxBackoff
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+func newWebhookSecret() (string, error) {
+	secret, err := randx.RuneSequence(40, randx.AlphaNum)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+func applyWebhookRequest(webhook *entity.SWebhook, req request.SaveWebhookRequest) error {
+	target, err := url.Parse(strings.TrimSpace(req.Url))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalidWebhook)
+	}
+
+	if len(req.Events) == 0 {
+		return fmt.Errorf("%w: subscribe to at least one event", ErrInvalidWebhook)
+	}
+	events := make([]value.WebhookEvent, 0, len(req.Events))
+	for _, raw := range req.Events {
+		event, err := value.GetWebhookEventFromString(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidWebhook, err.Error())
+		}
+		events = append(events, event)
+	}
+	eventsInJSON, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	if req.Status != "" {
+		status, err := value.GetStatusFromString(req.Status)
+		if err != nil || status == value.PendingDelete {
+			return fmt.Errorf("%w: status must be active or inactive", ErrInvalidWebhook)
+		}
+		webhook.Status = status
+	}
+
+	webhook.Name = strings.TrimSpace(req.Name)
+	webhook.Url = target.String()
+	webhook.Events = eventsInJSON
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+
+	return nil
+}
+
+func WebhookEventsOf(webhook entity.SWebhook) []value.WebhookEvent {
+	events := make([]value.WebhookEvent, 0)
+	_ = json.Unmarshal(webhook.Events, &events)
+
+	return events
+}
+
+func webhookSubscribes(webhook entity.SWebhook, event value.WebhookEvent) bool {
+	for _, subscribed := range WebhookEventsOf(webhook) {
+		if subscribed == event || subscribed == value.WebhookEvent_All {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"encoding/json"
+	"sen-global-api/internal/domain/entity"
+	"sen-global-api/internal/domain/value"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	const (
+		secret    = "secret"
+		timestamp = int64(1700000000)
+		want      = "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+	)
+	body := []byte(`{"id":"1"}`)
+
+	if got := SignWebhookPayload(secret, timestamp, body); got != want {
+		t.Errorf("SignWebhookPayload = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+	}{
+		{"another secret", "Secret", timestamp, body},
+		{"another timestamp", secret, timestamp + 1, body},
+		{"another body", secret, timestamp, []byte(`{"id":"2"}`)},
+		{"timestamp moved into the body", secret, 170000000, []byte(`0.{"id":"1"}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := SignWebhookPayload(test.secret, test.timestamp, test.body); got == want {
+				t.Errorf("SignWebhookPayload(%q, %d, %s) matches the original signature", test.secret, test.timestamp, test.body)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := webhookBackoff(test.attempts); got != test.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	subscribed := func(events ...value.WebhookEvent) entity.SWebhook {
+		data, err := json.Marshal(events)
+		if err != nil {
+			t.Fatalf("json.Marshal returned %v", err)
+		}
+		return entity.SWebhook{Events: data}
+	}
+
+	tests := []struct {
+		name    string
+		webhook entity.SWebhook
+		event   value.WebhookEvent
+		want    bool
+	}{
+		{"subscribed event", subscribed(value.WebhookEvent_FormSubmitted), value.WebhookEvent_FormSubmitted, true},
+		{"other event", subscribed(value.WebhookEvent_FormSubmitted), value.WebhookEvent_RedirectUrlScanned, false},
+		{"every event", subscribed(value.WebhookEvent_All), value.WebhookEvent_RedirectUrlScanned, true},
+		{"no events", entity.SWebhook{}, value.WebhookEvent_FormSubmitted, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := webhookSubscribes(test.webhook, test.event); got != test.want {
+				t.Errorf("webhookSubscribes(%s) = %t, want %t", test.event, got, test.want)
+			}
+		})
+	}
+}
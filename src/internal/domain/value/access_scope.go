@@ -0,0 +1,47 @@
+package value
+
+// AccessScope holds the organizations in which the user of a request holds the
+// permission of the route, it is set on the context by RequirePermission
+type AccessScope struct {
+	UserId           string
+	AllOrganizations bool
+	OrganizationIds  []int64
+}
+
+func (scope AccessScope) Allows(organizationId int64) bool {
+	if scope.AllOrganizations {
+		return true
+	}
+	for _, id := range scope.OrganizationIds {
+		if id == organizationId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsAny reports whether the scope covers at least one of the organizations
+func (scope AccessScope) AllowsAny(organizationIds []int64) bool {
+	if scope.AllOrganizations {
+		return true
+	}
+	for _, id := range organizationIds {
+		if scope.Allows(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Owns reports whether the scope may change a row of the organization. The
+// shared rows, which belong to no organization, are only changed by a scope over
+// every organization.
+func (scope AccessScope) Owns(organizationId *int64) bool {
+	if organizationId == nil {
+		return scope.AllOrganizations
+	}
+
+	return scope.Allows(*organizationId)
+}
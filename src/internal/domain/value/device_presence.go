@@ -0,0 +1,42 @@
+package value
+
+import "time"
+
+// DevicePresenceThresholds tells how long after its last heartbeat a device
+// turns stale and then offline. A device that never sent a heartbeat is offline.
+type DevicePresenceThresholds struct {
+	StaleAfter   time.Duration
+	OfflineAfter time.Duration
+}
+
+func (thresholds DevicePresenceThresholds) Of(lastHeartbeatAt *time.Time, now time.Time) DevicePresence {
+	if lastHeartbeatAt == nil {
+		return DevicePresence_Offline
+	}
+
+	silence := now.Sub(*lastHeartbeatAt)
+	switch {
+	case silence >= thresholds.OfflineAfter:
+		return DevicePresence_Offline
+	case silence >= thresholds.StaleAfter:
+		return DevicePresence_Stale
+	default:
+		return DevicePresence_Online
+	}
+}
+
+// Bounds returns the range of the last heartbeat of the devices of a presence,
+// after is exclusive and until inclusive, nil for no bound. Offline devices also
+// include the devices without a heartbeat.
+func (thresholds DevicePresenceThresholds) Bounds(presence DevicePresence, now time.Time) (*time.Time, *time.Time) {
+	staleAt := now.Add(-thresholds.StaleAfter)
+	offlineAt := now.Add(-thresholds.OfflineAfter)
+	switch presence {
+	case DevicePresence_Online:
+		return &staleAt, nil
+	case DevicePresence_Stale:
+		return &offlineAt, &staleAt
+	default:
+		return nil, &offlineAt
+	}
+}
@@ -0,0 +1,122 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// the organization timezones are loaded from the embedded database so that
+	// schedules work on hosts without zoneinfo
+	_ "time/tzdata"
+)
+
+// Weekdays is a set of days of the week, bit n is time.Weekday(n)
+type Weekdays uint8
+
+const (
+	AllWeekdays  Weekdays = 0b1111111
+	WorkWeekdays Weekdays = 0b0111110
+	Weekend      Weekdays = 0b1000001
+)
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// GetWeekdaysFromStrings reads days such as "mon" or "monday", and "weekdays",
+// "weekend" or "everyday" for several at once
+func GetWeekdaysFromStrings(days []string) (Weekdays, error) {
+	var weekdays Weekdays
+	for _, day := range days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		switch day {
+		case "weekdays":
+			weekdays |= WorkWeekdays
+			continue
+		case "weekend":
+			weekdays |= Weekend
+			continue
+		case "everyday":
+			weekdays |= AllWeekdays
+			continue
+		}
+
+		found := false
+		for i, name := range weekdayNames {
+			if day == name || day == strings.ToLower(time.Weekday(i).String()) {
+				weekdays |= 1 << i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid day %q", day)
+		}
+	}
+	if weekdays == 0 {
+		return 0, errors.New("no day given")
+	}
+
+	return weekdays, nil
+}
+
+func (weekdays Weekdays) Has(day time.Weekday) bool {
+	return weekdays&(1<<day) != 0
+}
+
+func (weekdays Weekdays) Strings() []string {
+	days := make([]string, 0, len(weekdayNames))
+	for i, name := range weekdayNames {
+		if weekdays.Has(time.Weekday(i)) {
+			days = append(days, name)
+		}
+	}
+
+	return days
+}
+
+// ParseClock reads an HH:MM time of day into minutes since midnight
+func ParseClock(clock string) (int, error) {
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+
+	return at.Hour()*60 + at.Minute(), nil
+}
+
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// DeviceScheduleWindow is a recurring window of a device mode schedule. A window
+// that ends before it starts runs overnight into the next day, Days are the days
+// it starts on. A window that ends when it starts lasts the whole day.
+type DeviceScheduleWindow struct {
+	Days  Weekdays
+	Start int
+	End   int
+}
+
+// Contains tells whether a time, in the timezone of the schedule, is in the window
+func (window DeviceScheduleWindow) Contains(at time.Time) bool {
+	minutes := at.Hour()*60 + at.Minute()
+	day := at.Weekday()
+	previousDay := (day + 6) % 7
+	switch {
+	case window.Start == window.End:
+		return window.Days.Has(day)
+	case window.Start < window.End:
+		return window.Days.Has(day) && minutes >= window.Start && minutes < window.End
+	default:
+		return (window.Days.Has(day) && minutes >= window.Start) || (window.Days.Has(previousDay) && minutes < window.End)
+	}
+}
+
+// LoadTimezone returns the location of an IANA timezone name, UTC for an empty name
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(name)
+}
@@ -3,6 +3,7 @@ package value
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
 type QuestionType int
@@ -931,6 +932,8 @@ const (
 	NotificationType_UserMessageChanged         NotificationType = "user_message_changed"
 	NotificationType_NoteChanged                NotificationType = "note_changed"
 	NotificationType_DeviceStatusChanged        NotificationType = "device_status_changed"
+	NotificationType_DeviceCommand              NotificationType = "device_command"
+	NotificationType_ToDoReminder               NotificationType = "todo_reminder"
 )
 
 type FcmTopics string
@@ -977,3 +980,484 @@ const (
 	DeviceModeP           DeviceMode = "mode p"
 	DeviceModeL           DeviceMode = "mode l"
 )
+
+type FormRevisionStatus string
+
+const (
+	FormRevisionStatus_Draft     FormRevisionStatus = "draft"
+	FormRevisionStatus_Published FormRevisionStatus = "published"
+	FormRevisionStatus_Archived  FormRevisionStatus = "archived"
+)
+
+type QuestionRuleAction string
+
+const (
+	QuestionRuleAction_ShowIf QuestionRuleAction = "show_if"
+	QuestionRuleAction_SkipTo QuestionRuleAction = "skip_to"
+)
+
+type QuestionRuleOperator string
+
+const (
+	QuestionRuleOperator_Equals      QuestionRuleOperator = "equals"
+	QuestionRuleOperator_NotEquals   QuestionRuleOperator = "not_equals"
+	QuestionRuleOperator_Contains    QuestionRuleOperator = "contains"
+	QuestionRuleOperator_In          QuestionRuleOperator = "in"
+	QuestionRuleOperator_Answered    QuestionRuleOperator = "answered"
+	QuestionRuleOperator_NotAnswered QuestionRuleOperator = "not_answered"
+)
+
+func GetQuestionRuleOperatorFromString(operator string) (QuestionRuleOperator, error) {
+	switch strings.ToLower(strings.TrimSpace(operator)) {
+	case "equals", "=", "==":
+		return QuestionRuleOperator_Equals, nil
+	case "not_equals", "!=", "<>":
+		return QuestionRuleOperator_NotEquals, nil
+	case "contains":
+		return QuestionRuleOperator_Contains, nil
+	case "in":
+		return QuestionRuleOperator_In, nil
+	case "answered":
+		return QuestionRuleOperator_Answered, nil
+	case "not_answered":
+		return QuestionRuleOperator_NotAnswered, nil
+	default:
+		return "", errors.New("invalid rule operator " + operator)
+	}
+}
+
+type ExportFormat string
+
+const (
+	ExportFormat_CSV    ExportFormat = "csv"
+	ExportFormat_XLSX   ExportFormat = "xlsx"
+	ExportFormat_NDJSON ExportFormat = "ndjson"
+)
+
+func GetExportFormatFromString(format string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "csv":
+		return ExportFormat_CSV, nil
+	case "xlsx", "excel":
+		return ExportFormat_XLSX, nil
+	case "ndjson", "jsonl":
+		return ExportFormat_NDJSON, nil
+	default:
+		return "", errors.New("invalid export format " + format)
+	}
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEvent_All                     WebhookEvent = "*"
+	WebhookEvent_FormSubmitted           WebhookEvent = "form.submitted"
+	WebhookEvent_TodoTaskCompleted       WebhookEvent = "todo.task_completed"
+	WebhookEvent_DeviceStatusChanged     WebhookEvent = "device.status_changed"
+	WebhookEvent_CodeCountingIncremented WebhookEvent = "code_counting.incremented"
+	WebhookEvent_RedirectUrlScanned      WebhookEvent = "redirect_url.scanned"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEvent_FormSubmitted,
+	WebhookEvent_TodoTaskCompleted,
+	WebhookEvent_DeviceStatusChanged,
+	WebhookEvent_CodeCountingIncremented,
+	WebhookEvent_RedirectUrlScanned,
+}
+
+func GetWebhookEventFromString(event string) (WebhookEvent, error) {
+	event = strings.ToLower(strings.TrimSpace(event))
+	if event == string(WebhookEvent_All) {
+		return WebhookEvent_All, nil
+	}
+	for _, e := range WebhookEvents {
+		if string(e) == event {
+			return e, nil
+		}
+	}
+
+	return "", errors.New("invalid webhook event " + event)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatus_Pending    WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatus_Delivering WebhookDeliveryStatus = "delivering"
+	WebhookDeliveryStatus_Succeeded  WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatus_Failed     WebhookDeliveryStatus = "failed"
+)
+
+type DevicePresence string
+
+const (
+	DevicePresence_Online  DevicePresence = "online"
+	DevicePresence_Stale   DevicePresence = "stale"
+	DevicePresence_Offline DevicePresence = "offline"
+)
+
+func GetDevicePresenceFromString(presence string) (DevicePresence, error) {
+	switch DevicePresence(strings.ToLower(strings.TrimSpace(presence))) {
+	case DevicePresence_Online:
+		return DevicePresence_Online, nil
+	case DevicePresence_Stale:
+		return DevicePresence_Stale, nil
+	case DevicePresence_Offline:
+		return DevicePresence_Offline, nil
+	default:
+		return "", errors.New("invalid device presence " + presence)
+	}
+}
+
+type DeviceCommandType string
+
+const (
+	DeviceCommandType_ReloadForms DeviceCommandType = "reload_forms"
+	DeviceCommandType_ClearCache  DeviceCommandType = "clear_cache"
+	DeviceCommandType_ChangeMode  DeviceCommandType = "change_mode"
+	DeviceCommandType_ShowMessage DeviceCommandType = "show_message"
+	DeviceCommandType_UploadLogs  DeviceCommandType = "upload_logs"
+	DeviceCommandType_RebootApp   DeviceCommandType = "reboot_app"
+)
+
+var DeviceCommandTypes = []DeviceCommandType{
+	DeviceCommandType_ReloadForms,
+	DeviceCommandType_ClearCache,
+	DeviceCommandType_ChangeMode,
+	DeviceCommandType_ShowMessage,
+	DeviceCommandType_UploadLogs,
+	DeviceCommandType_RebootApp,
+}
+
+func GetDeviceCommandTypeFromString(commandType string) (DeviceCommandType, error) {
+	commandType = strings.ToLower(strings.TrimSpace(commandType))
+	for _, t := range DeviceCommandTypes {
+		if string(t) == commandType {
+			return t, nil
+		}
+	}
+
+	return "", errors.New("invalid device command type " + commandType)
+}
+
+// DeviceCommandStatus is where a device command is, pending until the device
+// fetched it, delivered until it acknowledged it and acknowledged until it
+// reported its result
+type DeviceCommandStatus string
+
+const (
+	DeviceCommandStatus_Pending      DeviceCommandStatus = "pending"
+	DeviceCommandStatus_Delivered    DeviceCommandStatus = "delivered"
+	DeviceCommandStatus_Acknowledged DeviceCommandStatus = "acknowledged"
+	DeviceCommandStatus_Succeeded    DeviceCommandStatus = "succeeded"
+	DeviceCommandStatus_Failed       DeviceCommandStatus = "failed"
+	DeviceCommandStatus_Expired      DeviceCommandStatus = "expired"
+	DeviceCommandStatus_Cancelled    DeviceCommandStatus = "cancelled"
+)
+
+var DeviceCommandStatuses = []DeviceCommandStatus{
+	DeviceCommandStatus_Pending,
+	DeviceCommandStatus_Delivered,
+	DeviceCommandStatus_Acknowledged,
+	DeviceCommandStatus_Succeeded,
+	DeviceCommandStatus_Failed,
+	DeviceCommandStatus_Expired,
+	DeviceCommandStatus_Cancelled,
+}
+
+func GetDeviceCommandStatusFromString(status string) (DeviceCommandStatus, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	for _, s := range DeviceCommandStatuses {
+		if string(s) == status {
+			return s, nil
+		}
+	}
+
+	return "", errors.New("invalid device command status " + status)
+}
+
+// IsOpen reports whether a command in this status can still be executed
+func (receiver DeviceCommandStatus) IsOpen() bool {
+	switch receiver {
+	case DeviceCommandStatus_Pending, DeviceCommandStatus_Delivered, DeviceCommandStatus_Acknowledged:
+		return true
+	}
+
+	return false
+}
+
+// DeviceConfigurationScope is where a device configuration is set, from the
+// most to the least specific
+type DeviceConfigurationScope string
+
+const (
+	DeviceConfigurationScope_Device       DeviceConfigurationScope = "device"
+	DeviceConfigurationScope_Group        DeviceConfigurationScope = "group"
+	DeviceConfigurationScope_Organization DeviceConfigurationScope = "organization"
+	DeviceConfigurationScope_Global       DeviceConfigurationScope = "global"
+	// DeviceConfigurationScope_Default is the source of the fields no scope sets,
+	// taken from the device itself
+	DeviceConfigurationScope_Default DeviceConfigurationScope = "default"
+	// DeviceConfigurationScope_Schedule and DeviceConfigurationScope_Override are
+	// the source of a mode set by a device mode schedule or a one-off override,
+	// which come before every scope
+	DeviceConfigurationScope_Schedule DeviceConfigurationScope = "schedule"
+	DeviceConfigurationScope_Override DeviceConfigurationScope = "override"
+)
+
+type Permission string
+
+const (
+	Permission_All               Permission = "*"
+	Permission_FormRead          Permission = "form:read"
+	Permission_FormWrite         Permission = "form:write"
+	Permission_SubmissionRead    Permission = "submission:read"
+	Permission_WebhookRead       Permission = "webhook:read"
+	Permission_WebhookWrite      Permission = "webhook:write"
+	Permission_DeviceRead        Permission = "device:read"
+	Permission_DeviceWrite       Permission = "device:write"
+	Permission_RedirectUrlRead   Permission = "redirect_url:read"
+	Permission_RedirectUrlWrite  Permission = "redirect_url:write"
+	Permission_ToDoRead          Permission = "todo:read"
+	Permission_ToDoWrite         Permission = "todo:write"
+	Permission_SettingRead       Permission = "setting:read"
+	Permission_SettingWrite      Permission = "setting:write"
+	Permission_MonitorRead       Permission = "monitor:read"
+	Permission_CodeCountingRead  Permission = "code_counting:read"
+	Permission_CodeCountingWrite Permission = "code_counting:write"
+	Permission_UserRead          Permission = "user:read"
+	Permission_UserWrite         Permission = "user:write"
+	Permission_RoleRead          Permission = "role:read"
+	Permission_RoleWrite         Permission = "role:write"
+	Permission_AuditRead         Permission = "audit:read"
+	Permission_OrganizationRead  Permission = "organization:read"
+	Permission_OrganizationWrite Permission = "organization:write"
+)
+
+// SuperAdminRoleName is the name of the role without an organization that holds
+// every permission in every organization. Organizations cannot create a role of
+// that name or rename one to it.
+const SuperAdminRoleName = "SuperAdmin"
+
+func IsReservedRoleName(roleName string) bool {
+	return strings.EqualFold(strings.TrimSpace(roleName), SuperAdminRoleName)
+}
+
+// PermissionGranted reports whether one of the granted permissions covers the
+// required one, either exactly, through a "<resource>:*" wildcard or through "*"
+func PermissionGranted(granted []string, required Permission) bool {
+	resource, _, _ := strings.Cut(string(required), ":")
+	for _, permission := range granted {
+		switch strings.ToLower(strings.TrimSpace(permission)) {
+		case string(Permission_All), string(required), resource + ":*":
+			return true
+		}
+	}
+
+	return false
+}
+
+// MembershipStatus is where a user stands in an organization, the roles of an
+// organization only count for its active members
+type MembershipStatus string
+
+const (
+	MembershipStatus_Active    MembershipStatus = "active"
+	MembershipStatus_Suspended MembershipStatus = "suspended"
+)
+
+func GetMembershipStatusFromString(status string) (MembershipStatus, error) {
+	switch MembershipStatus(strings.ToLower(strings.TrimSpace(status))) {
+	case MembershipStatus_Active:
+		return MembershipStatus_Active, nil
+	case MembershipStatus_Suspended:
+		return MembershipStatus_Suspended, nil
+	}
+
+	return "", errors.New("invalid membership status " + status)
+}
+
+// InvitationReply is how a user answered an invitation to an organization
+type InvitationReply string
+
+const (
+	InvitationReply_Accepted InvitationReply = "accepted"
+	InvitationReply_Declined InvitationReply = "declined"
+)
+
+// GuardianRelationship is how a guardian relates to a child, empty for the
+// guardians set without an invitation
+type GuardianRelationship string
+
+const (
+	GuardianRelationship_Parent        GuardianRelationship = "parent"
+	GuardianRelationship_LegalGuardian GuardianRelationship = "legal_guardian"
+	GuardianRelationship_Carer         GuardianRelationship = "carer"
+)
+
+func GetGuardianRelationshipFromString(relationship string) (GuardianRelationship, error) {
+	switch GuardianRelationship(strings.ToLower(strings.TrimSpace(relationship))) {
+	case GuardianRelationship_Parent:
+		return GuardianRelationship_Parent, nil
+	case GuardianRelationship_LegalGuardian:
+		return GuardianRelationship_LegalGuardian, nil
+	case GuardianRelationship_Carer:
+		return GuardianRelationship_Carer, nil
+	}
+
+	return "", errors.New("invalid guardian relationship " + relationship)
+}
+
+// GuardianLinkStatus is where a guardian relationship is, pending until the
+// guardian confirms it. Declined, cancelled and detached links are kept as
+// history.
+type GuardianLinkStatus string
+
+const (
+	GuardianLinkStatus_Pending   GuardianLinkStatus = "pending"
+	GuardianLinkStatus_Active    GuardianLinkStatus = "active"
+	GuardianLinkStatus_Declined  GuardianLinkStatus = "declined"
+	GuardianLinkStatus_Cancelled GuardianLinkStatus = "cancelled"
+	GuardianLinkStatus_Detached  GuardianLinkStatus = "detached"
+)
+
+var GuardianLinkStatuses = []GuardianLinkStatus{
+	GuardianLinkStatus_Pending,
+	GuardianLinkStatus_Active,
+	GuardianLinkStatus_Declined,
+	GuardianLinkStatus_Cancelled,
+	GuardianLinkStatus_Detached,
+}
+
+func GetGuardianLinkStatusFromString(status string) (GuardianLinkStatus, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	for _, s := range GuardianLinkStatuses {
+		if string(s) == status {
+			return s, nil
+		}
+	}
+
+	return "", errors.New("invalid guardian link status " + status)
+}
+
+// ToDoSheetSyncKind is what a sheet sync job mirrors to the spreadsheets of a
+// to-do list
+type ToDoSheetSyncKind string
+
+const (
+	// ToDoSheetSyncKind_Completion writes a completed task to the task sheet and
+	// the history sheet
+	ToDoSheetSyncKind_Completion ToDoSheetSyncKind = "completion"
+	// ToDoSheetSyncKind_TaskLog appends a change made on a device to the history
+	// sheet
+	ToDoSheetSyncKind_TaskLog ToDoSheetSyncKind = "task_log"
+	// ToDoSheetSyncKind_Tasks rewrites the tasks of the task sheet
+	ToDoSheetSyncKind_Tasks ToDoSheetSyncKind = "tasks"
+)
+
+type ToDoSheetSyncStatus string
+
+const (
+	ToDoSheetSyncStatus_Pending   ToDoSheetSyncStatus = "pending"
+	ToDoSheetSyncStatus_Syncing   ToDoSheetSyncStatus = "syncing"
+	ToDoSheetSyncStatus_Succeeded ToDoSheetSyncStatus = "succeeded"
+	ToDoSheetSyncStatus_Failed    ToDoSheetSyncStatus = "failed"
+)
+
+func GetToDoSheetSyncStatusFromString(status string) (ToDoSheetSyncStatus, error) {
+	switch ToDoSheetSyncStatus(strings.ToLower(strings.TrimSpace(status))) {
+	case ToDoSheetSyncStatus_Pending:
+		return ToDoSheetSyncStatus_Pending, nil
+	case ToDoSheetSyncStatus_Syncing:
+		return ToDoSheetSyncStatus_Syncing, nil
+	case ToDoSheetSyncStatus_Succeeded:
+		return ToDoSheetSyncStatus_Succeeded, nil
+	case ToDoSheetSyncStatus_Failed:
+		return ToDoSheetSyncStatus_Failed, nil
+	}
+
+	return "", errors.New("invalid sheet sync status " + status)
+}
+
+// ToDoAssigneeType is what a to-do list or a task is assigned to, the tasks
+// are completed and reminded on the devices of the assignees
+type ToDoAssigneeType string
+
+const (
+	ToDoAssigneeType_User   ToDoAssigneeType = "user"
+	ToDoAssigneeType_Device ToDoAssigneeType = "device"
+	ToDoAssigneeType_Group  ToDoAssigneeType = "group"
+)
+
+func GetToDoAssigneeTypeFromString(assigneeType string) (ToDoAssigneeType, error) {
+	switch ToDoAssigneeType(strings.ToLower(strings.TrimSpace(assigneeType))) {
+	case ToDoAssigneeType_User:
+		return ToDoAssigneeType_User, nil
+	case ToDoAssigneeType_Device:
+		return ToDoAssigneeType_Device, nil
+	case ToDoAssigneeType_Group:
+		return ToDoAssigneeType_Group, nil
+	}
+
+	return "", errors.New("invalid to-do assignee type " + assigneeType + ", expected user, device or group")
+}
+
+func GetToDoTypeFromString(toDoType string) (ToDoType, error) {
+	switch ToDoType(strings.ToLower(strings.TrimSpace(toDoType))) {
+	case ToDoTypeAssign:
+		return ToDoTypeAssign, nil
+	case ToDoTypeCompose:
+		return ToDoTypeCompose, nil
+	}
+
+	return "", errors.New("invalid todo type " + toDoType)
+}
+
+// RedirectUrlScanOutcome is how the resolution of a redirect URL QR code ended
+type RedirectUrlScanOutcome string
+
+const (
+	RedirectUrlScanOutcome_Success        RedirectUrlScanOutcome = "success"
+	RedirectUrlScanOutcome_FailedPassword RedirectUrlScanOutcome = "failed_password"
+	RedirectUrlScanOutcome_NotFound       RedirectUrlScanOutcome = "not_found"
+	// RedirectUrlScanOutcome_Inactive is a scan out of the validity window of the
+	// redirect URL, sent to its fallback URL when it has one
+	RedirectUrlScanOutcome_Inactive RedirectUrlScanOutcome = "inactive"
+)
+
+// ScanInterval is the period the redirect URL scans are counted by
+type ScanInterval string
+
+const (
+	ScanInterval_Day   ScanInterval = "day"
+	ScanInterval_Week  ScanInterval = "week"
+	ScanInterval_Month ScanInterval = "month"
+)
+
+func GetScanIntervalFromString(interval string) (ScanInterval, error) {
+	switch ScanInterval(strings.ToLower(strings.TrimSpace(interval))) {
+	case "", ScanInterval_Day:
+		return ScanInterval_Day, nil
+	case ScanInterval_Week:
+		return ScanInterval_Week, nil
+	case ScanInterval_Month:
+		return ScanInterval_Month, nil
+	}
+
+	return "", errors.New("invalid interval " + interval + ", expected day, week or month")
+}
+
+// Start returns the first day of the period of the interval the day is in, weeks
+// start on Monday
+func (interval ScanInterval) Start(day time.Time) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	switch interval {
+	case ScanInterval_Week:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case ScanInterval_Month:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
@@ -0,0 +1,185 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedirectUrlTimeRule sends the scans of a redirect URL to TargetUrl on Days,
+// every day when none is given, from StartTime to EndTime. The times are HH:MM
+// in the timezone of the organization, a rule ending before it starts runs
+// overnight and a rule ending when it starts lasts the whole day.
+type RedirectUrlTimeRule struct {
+	Days      []string `json:"days,omitempty"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	TargetUrl string   `json:"target_url"`
+}
+
+// RedirectUrlTarget is one of the targets a redirect URL splits its scans
+// between, in proportion to Weight
+type RedirectUrlTarget struct {
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// RedirectUrlSchedule picks the target of a scan: the first time rule the scan
+// falls in, then one of the weighted targets, then the target URL of the
+// redirect URL
+type RedirectUrlSchedule struct {
+	TimeRules []RedirectUrlTimeRule `json:"time_rules,omitempty"`
+	Targets   []RedirectUrlTarget   `json:"targets,omitempty"`
+}
+
+// Normalized validates the schedule, the weights default to 1
+func (schedule RedirectUrlSchedule) Normalized() (RedirectUrlSchedule, error) {
+	normalized := RedirectUrlSchedule{
+		TimeRules: make([]RedirectUrlTimeRule, 0, len(schedule.TimeRules)),
+		Targets:   make([]RedirectUrlTarget, 0, len(schedule.Targets)),
+	}
+	for _, rule := range schedule.TimeRules {
+		rule.TargetUrl = strings.TrimSpace(rule.TargetUrl)
+		if rule.TargetUrl == "" {
+			return schedule, errors.New("a time rule has no target url")
+		}
+		window, err := rule.window()
+		if err != nil {
+			return schedule, err
+		}
+		rule.Days = window.Days.Strings()
+		rule.StartTime = FormatClock(window.Start)
+		rule.EndTime = FormatClock(window.End)
+		normalized.TimeRules = append(normalized.TimeRules, rule)
+	}
+	for _, target := range schedule.Targets {
+		target.Url = strings.TrimSpace(target.Url)
+		if target.Url == "" {
+			return schedule, errors.New("a target has no url")
+		}
+		if target.Weight < 0 {
+			return schedule, fmt.Errorf("the weight of %s cannot be negative", target.Url)
+		}
+		if target.Weight == 0 {
+			target.Weight = 1
+		}
+		normalized.Targets = append(normalized.Targets, target)
+	}
+
+	return normalized, nil
+}
+
+// Target returns the URL a scan at a time, in the timezone of the organization,
+// goes to. pick chooses between the weighted targets, the same pick always
+// chooses the same target.
+func (schedule RedirectUrlSchedule) Target(at time.Time, targetUrl string, pick uint64) string {
+	for _, rule := range schedule.TimeRules {
+		window, err := rule.window()
+		if err == nil && window.Contains(at) {
+			return rule.TargetUrl
+		}
+	}
+
+	total := 0
+	for _, target := range schedule.Targets {
+		total += target.Weight
+	}
+	if total > 0 {
+		n := int(pick % uint64(total))
+		for _, target := range schedule.Targets {
+			if n < target.Weight {
+				return target.Url
+			}
+			n -= target.Weight
+		}
+	}
+
+	return targetUrl
+}
+
+func (rule RedirectUrlTimeRule) window() (DeviceScheduleWindow, error) {
+	days := AllWeekdays
+	if len(rule.Days) > 0 {
+		var err error
+		days, err = GetWeekdaysFromStrings(rule.Days)
+		if err != nil {
+			return DeviceScheduleWindow{}, err
+		}
+	}
+	start, err := ParseClock(rule.StartTime)
+	if err != nil {
+		return DeviceScheduleWindow{}, err
+	}
+	end, err := ParseClock(rule.EndTime)
+	if err != nil {
+		return DeviceScheduleWindow{}, err
+	}
+
+	return DeviceScheduleWindow{Days: days, Start: start, End: end}, nil
+}
+
+// ParseRedirectUrlTimeRules reads the time rules of a spreadsheet cell, rules
+// such as "weekdays 08:00-15:00 https://..." separated by new lines or "|". The
+// days, comma separated, are optional.
+func ParseRedirectUrlTimeRules(cell string) ([]RedirectUrlTimeRule, error) {
+	rules := make([]RedirectUrlTimeRule, 0)
+	for _, line := range splitSheetList(cell) {
+		fields := strings.Fields(line)
+		var rule RedirectUrlTimeRule
+		switch len(fields) {
+		case 2:
+		case 3:
+			rule.Days = strings.Split(fields[0], ",")
+			fields = fields[1:]
+		default:
+			return nil, fmt.Errorf("invalid time rule %q, expected [days] HH:MM-HH:MM url", line)
+		}
+		start, end, found := strings.Cut(fields[0], "-")
+		if !found {
+			return nil, fmt.Errorf("invalid time rule %q, expected [days] HH:MM-HH:MM url", line)
+		}
+		rule.StartTime = start
+		rule.EndTime = end
+		rule.TargetUrl = fields[1]
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ParseRedirectUrlTargets reads the weighted targets of a spreadsheet cell,
+// targets such as "https://... 70" separated by new lines or "|". The weight is
+// optional.
+func ParseRedirectUrlTargets(cell string) ([]RedirectUrlTarget, error) {
+	targets := make([]RedirectUrlTarget, 0)
+	for _, line := range splitSheetList(cell) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid target %q, expected url [weight]", line)
+		}
+		target := RedirectUrlTarget{Url: fields[0]}
+		if len(fields) == 2 {
+			weight, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight %q of %s", fields[1], fields[0])
+			}
+			target.Weight = weight
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+func splitSheetList(cell string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.FieldsFunc(cell, func(r rune) bool { return r == '\n' || r == '|' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
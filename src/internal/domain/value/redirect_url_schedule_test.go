@@ -0,0 +1,167 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 2026-10-16 is a Friday
+func scanAt(day int, hour int, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestRedirectUrlScheduleTimeRules(t *testing.T) {
+	schedule := RedirectUrlSchedule{TimeRules: []RedirectUrlTimeRule{
+		{Days: []string{"weekdays"}, StartTime: "08:00", EndTime: "15:00", TargetUrl: "https://school"},
+		{Days: []string{"fri"}, StartTime: "22:00", EndTime: "02:00", TargetUrl: "https://night"},
+		{Days: []string{"sunday"}, StartTime: "00:00", EndTime: "00:00", TargetUrl: "https://sunday"},
+		{StartTime: "07:00", EndTime: "16:00", TargetUrl: "https://daytime"},
+		{Days: []string{"someday"}, StartTime: "00:00", EndTime: "00:00", TargetUrl: "https://invalid"},
+	}}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"start of a weekday window", scanAt(16, 8, 0), "https://school"},
+		{"end of a weekday window is excluded", scanAt(16, 15, 0), "https://daytime"},
+		{"weekday window on the weekend", scanAt(17, 9, 0), "https://daytime"},
+		{"overnight window before midnight", scanAt(16, 23, 30), "https://night"},
+		{"overnight window after midnight of the next day", scanAt(17, 1, 59), "https://night"},
+		{"overnight window ended", scanAt(17, 2, 0), "https://fallback"},
+		{"overnight window does not start on other days", scanAt(15, 23, 0), "https://fallback"},
+		{"overnight window of the day before only", scanAt(16, 1, 0), "https://fallback"},
+		{"whole day window", scanAt(18, 0, 0), "https://sunday"},
+		{"first matching rule wins", scanAt(19, 10, 0), "https://school"},
+		{"no rule matches", scanAt(19, 20, 0), "https://fallback"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := schedule.Target(test.at, "https://fallback", 0); got != test.want {
+				t.Errorf("Target(%s) = %s, want %s", test.at.Format("Mon 15:04"), got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedirectUrlScheduleWeightedTargets(t *testing.T) {
+	schedule := RedirectUrlSchedule{
+		TimeRules: []RedirectUrlTimeRule{{Days: []string{"sat"}, StartTime: "00:00", EndTime: "00:00", TargetUrl: "https://weekend"}},
+		Targets: []RedirectUrlTarget{
+			{Url: "https://a", Weight: 3},
+			{Url: "https://b", Weight: 0},
+			{Url: "https://c", Weight: 1},
+		},
+	}
+
+	counts := make(map[string]int)
+	for pick := uint64(0); pick < 400; pick++ {
+		counts[schedule.Target(scanAt(16, 12, 0), "https://fallback", pick)]++
+	}
+	want := map[string]int{"https://a": 300, "https://c": 100}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Target picked %v, want %v", counts, want)
+	}
+
+	if first, again := schedule.Target(scanAt(16, 12, 0), "", 7), schedule.Target(scanAt(19, 8, 0), "", 7); first != again {
+		t.Errorf("the same pick chose %s and %s", first, again)
+	}
+	if got := schedule.Target(scanAt(17, 12, 0), "https://fallback", 1); got != "https://weekend" {
+		t.Errorf("Target on Saturday = %s, want the time rule before the weighted targets", got)
+	}
+	if got := (RedirectUrlSchedule{Targets: []RedirectUrlTarget{{Url: "https://zero"}}}).Target(scanAt(16, 12, 0), "https://fallback", 5); got != "https://fallback" {
+		t.Errorf("Target without weights = %s, want the fallback", got)
+	}
+}
+
+func TestRedirectUrlScheduleNormalized(t *testing.T) {
+	schedule := RedirectUrlSchedule{
+		TimeRules: []RedirectUrlTimeRule{{Days: []string{"Monday", "tue"}, StartTime: "8:05", EndTime: "15:00", TargetUrl: " https://school "}},
+		Targets:   []RedirectUrlTarget{{Url: "https://a"}, {Url: "https://b", Weight: 4}},
+	}
+
+	got, err := schedule.Normalized()
+	if err != nil {
+		t.Fatalf("Normalized returned %v", err)
+	}
+	if got.TimeRules[0].TargetUrl != "https://school" || got.TimeRules[0].StartTime != "08:05" {
+		t.Errorf("Normalized time rule = %+v", got.TimeRules[0])
+	}
+	if got.Targets[0].Weight != 1 || got.Targets[1].Weight != 4 {
+		t.Errorf("Normalized targets = %+v, want the weights 1 and 4", got.Targets)
+	}
+
+	invalid := []RedirectUrlSchedule{
+		{TimeRules: []RedirectUrlTimeRule{{StartTime: "08:00", EndTime: "09:00"}}},
+		{TimeRules: []RedirectUrlTimeRule{{StartTime: "8am", EndTime: "09:00", TargetUrl: "https://a"}}},
+		{TimeRules: []RedirectUrlTimeRule{{Days: []string{"someday"}, StartTime: "08:00", EndTime: "09:00", TargetUrl: "https://a"}}},
+		{Targets: []RedirectUrlTarget{{Url: " "}}},
+		{Targets: []RedirectUrlTarget{{Url: "https://a", Weight: -1}}},
+	}
+	for _, schedule := range invalid {
+		if _, err := schedule.Normalized(); err == nil {
+			t.Errorf("Normalized accepted %+v", schedule)
+		}
+	}
+}
+
+func TestParseRedirectUrlTimeRules(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want []RedirectUrlTimeRule
+	}{
+		{
+			name: "empty cell",
+			cell: " ",
+			want: []RedirectUrlTimeRule{},
+		},
+		{
+			name: "rules on new lines and after a bar",
+			cell: "weekdays 08:00-15:00 https://school\n22:00-06:00 https://night | sat,sun 00:00-00:00 https://weekend",
+			want: []RedirectUrlTimeRule{
+				{Days: []string{"weekdays"}, StartTime: "08:00", EndTime: "15:00", TargetUrl: "https://school"},
+				{StartTime: "22:00", EndTime: "06:00", TargetUrl: "https://night"},
+				{Days: []string{"sat", "sun"}, StartTime: "00:00", EndTime: "00:00", TargetUrl: "https://weekend"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseRedirectUrlTimeRules(test.cell)
+			if err != nil {
+				t.Fatalf("ParseRedirectUrlTimeRules(%q) returned %v", test.cell, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseRedirectUrlTimeRules(%q) = %+v, want %+v", test.cell, got, test.want)
+			}
+		})
+	}
+
+	for _, cell := range []string{"https://school", "weekdays 08:00 https://school", "mon 08:00-09:00 https://a extra"} {
+		if got, err := ParseRedirectUrlTimeRules(cell); err == nil {
+			t.Errorf("ParseRedirectUrlTimeRules(%q) = %+v, want an error", cell, got)
+		}
+	}
+}
+
+func TestParseRedirectUrlTargets(t *testing.T) {
+	got, err := ParseRedirectUrlTargets("https://a 70\nhttps://b|https://c 0")
+	if err != nil {
+		t.Fatalf("ParseRedirectUrlTargets returned %v", err)
+	}
+	want := []RedirectUrlTarget{{Url: "https://a", Weight: 70}, {Url: "https://b"}, {Url: "https://c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRedirectUrlTargets = %+v, want %+v", got, want)
+	}
+
+	for _, cell := range []string{"https://a heavy", "https://a 1 2"} {
+		if got, err := ParseRedirectUrlTargets(cell); err == nil {
+			t.Errorf("ParseRedirectUrlTargets(%q) = %+v, want an error", cell, got)
+		}
+	}
+}
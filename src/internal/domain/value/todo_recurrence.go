@@ -0,0 +1,318 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ToDoRecurrenceFrequency string
+
+const (
+	ToDoRecurrence_Daily    ToDoRecurrenceFrequency = "daily"
+	ToDoRecurrence_Weekdays ToDoRecurrenceFrequency = "weekdays"
+	ToDoRecurrence_Weekly   ToDoRecurrenceFrequency = "weekly"
+	ToDoRecurrence_Monthly  ToDoRecurrenceFrequency = "monthly"
+	ToDoRecurrence_RRule    ToDoRecurrenceFrequency = "rrule"
+)
+
+// ToDoRecurrence repeats a to-do task, the next occurrence is due Interval days,
+// weeks or months after the current one. Weekly tasks are due on Days, the day
+// of the week of the current occurrence when none is given, and monthly tasks on
+// MonthDay, the last day of the shorter months. RRule holds a rule of the
+// rrule frequency, the DAILY, WEEKLY and MONTHLY subset of RFC 5545 with
+// INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL. The task stops repeating after
+// Count occurrences or Until.
+type ToDoRecurrence struct {
+	Frequency ToDoRecurrenceFrequency `json:"frequency"`
+	Interval  int                     `json:"interval,omitempty"`
+	Days      []string                `json:"days,omitempty"`
+	MonthDay  int                     `json:"month_day,omitempty"`
+	RRule     string                  `json:"rrule,omitempty"`
+	Until     *time.Time              `json:"until,omitempty"`
+	Count     int                     `json:"count,omitempty"`
+}
+
+// recurrenceRule is a recurrence reduced to a daily, weekly or monthly rule,
+// Days filters the days of a daily rule
+type recurrenceRule struct {
+	frequency ToDoRecurrenceFrequency
+	interval  int
+	days      Weekdays
+	monthDay  int
+	until     *time.Time
+	count     int
+}
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Normalized validates the recurrence and drops the fields its frequency does
+// not use
+func (recurrence ToDoRecurrence) Normalized() (ToDoRecurrence, error) {
+	recurrence.Frequency = ToDoRecurrenceFrequency(strings.ToLower(strings.TrimSpace(string(recurrence.Frequency))))
+	if recurrence.Interval < 0 {
+		return recurrence, errors.New("interval cannot be negative")
+	}
+	if recurrence.Count < 0 {
+		return recurrence, errors.New("count cannot be negative")
+	}
+
+	switch recurrence.Frequency {
+	case ToDoRecurrence_Daily, ToDoRecurrence_Weekdays:
+		recurrence.Days = nil
+		recurrence.MonthDay = 0
+		recurrence.RRule = ""
+	case ToDoRecurrence_Weekly:
+		if len(recurrence.Days) > 0 {
+			days, err := GetWeekdaysFromStrings(recurrence.Days)
+			if err != nil {
+				return recurrence, err
+			}
+			recurrence.Days = days.Strings()
+		}
+		recurrence.MonthDay = 0
+		recurrence.RRule = ""
+	case ToDoRecurrence_Monthly:
+		if recurrence.MonthDay < 0 || recurrence.MonthDay > 31 {
+			return recurrence, fmt.Errorf("invalid day of the month %d", recurrence.MonthDay)
+		}
+		recurrence.Days = nil
+		recurrence.RRule = ""
+	case ToDoRecurrence_RRule:
+		recurrence.RRule = strings.ToUpper(strings.TrimSpace(recurrence.RRule))
+		if _, err := parseRRule(recurrence.RRule); err != nil {
+			return recurrence, err
+		}
+		recurrence.Days = nil
+		recurrence.MonthDay = 0
+	default:
+		return recurrence, fmt.Errorf("invalid recurrence frequency %q, expected daily, weekdays, weekly, monthly or rrule", recurrence.Frequency)
+	}
+
+	return recurrence, nil
+}
+
+// Next returns when the occurrence after the current one is due, false when the
+// task does not repeat anymore. The due time of the current occurrence is in
+// the timezone the task repeats in, occurrence counts from 1.
+func (recurrence ToDoRecurrence) Next(due time.Time, occurrence int) (time.Time, bool) {
+	rule, err := recurrence.rule()
+	if err != nil {
+		return time.Time{}, false
+	}
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	if rule.count > 0 && occurrence >= rule.count {
+		return time.Time{}, false
+	}
+
+	next := rule.next(due)
+	if rule.until != nil && next.After(*rule.until) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+func (recurrence ToDoRecurrence) rule() (recurrenceRule, error) {
+	rule := recurrenceRule{
+		frequency: recurrence.Frequency,
+		interval:  recurrence.Interval,
+		monthDay:  recurrence.MonthDay,
+		until:     recurrence.Until,
+		count:     recurrence.Count,
+	}
+
+	switch recurrence.Frequency {
+	case ToDoRecurrence_Daily, ToDoRecurrence_Monthly:
+	case ToDoRecurrence_Weekdays:
+		rule.frequency = ToDoRecurrence_Daily
+		rule.interval = 1
+		rule.days = WorkWeekdays
+	case ToDoRecurrence_Weekly:
+		if len(recurrence.Days) > 0 {
+			days, err := GetWeekdaysFromStrings(recurrence.Days)
+			if err != nil {
+				return rule, err
+			}
+			rule.days = days
+		}
+	case ToDoRecurrence_RRule:
+		parsed, err := parseRRule(recurrence.RRule)
+		if err != nil {
+			return rule, err
+		}
+		if parsed.until == nil {
+			parsed.until = recurrence.Until
+		}
+		if parsed.count == 0 {
+			parsed.count = recurrence.Count
+		}
+		rule = parsed
+	default:
+		return rule, fmt.Errorf("invalid recurrence frequency %q", recurrence.Frequency)
+	}
+	if rule.interval < 1 {
+		rule.interval = 1
+	}
+
+	return rule, nil
+}
+
+// next returns the first time of the rule after due, at the same time of day
+func (rule recurrenceRule) next(due time.Time) time.Time {
+	switch rule.frequency {
+	case ToDoRecurrence_Weekly:
+		days := rule.days
+		if days == 0 {
+			days = 1 << due.Weekday()
+		}
+		week := startOfWeek(due)
+		for i := 1; i <= 7*(rule.interval+1); i++ {
+			at := addDays(due, i)
+			if days.Has(at.Weekday()) && daysBetween(week, startOfWeek(at))/7%rule.interval == 0 {
+				return at
+			}
+		}
+		return addDays(due, 7*rule.interval)
+	case ToDoRecurrence_Monthly:
+		day := rule.monthDay
+		if day == 0 {
+			day = due.Day()
+		}
+		if at := monthDay(due, 0, day); at.After(due) {
+			return at
+		}
+		return monthDay(due, rule.interval, day)
+	default:
+		at := addDays(due, rule.interval)
+		for i := 0; rule.days != 0 && !rule.days.Has(at.Weekday()) && i < 7; i++ {
+			at = addDays(at, rule.interval)
+		}
+		return at
+	}
+}
+
+// parseRRule reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"
+func parseRRule(rrule string) (recurrenceRule, error) {
+	var rule recurrenceRule
+	rrule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rrule)), "RRULE:")
+	if rrule == "" {
+		return rule, errors.New("rrule is required")
+	}
+
+	for _, part := range strings.Split(rrule, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			return rule, fmt.Errorf("invalid rrule part %q", part)
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY":
+				rule.frequency = ToDoRecurrence_Daily
+			case "WEEKLY":
+				rule.frequency = ToDoRecurrence_Weekly
+			case "MONTHLY":
+				rule.frequency = ToDoRecurrence_Monthly
+			default:
+				return rule, fmt.Errorf("unsupported rrule frequency %q, expected DAILY, WEEKLY or MONTHLY", val)
+			}
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(val)
+			if err != nil || rule.interval < 1 {
+				return rule, fmt.Errorf("invalid rrule interval %q", val)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := rruleDays[day]
+				if !ok {
+					return rule, fmt.Errorf("unsupported rrule day %q", day)
+				}
+				rule.days |= 1 << weekday
+			}
+		case "BYMONTHDAY":
+			rule.monthDay, err = strconv.Atoi(val)
+			if err != nil || rule.monthDay < 1 || rule.monthDay > 31 {
+				return rule, fmt.Errorf("unsupported rrule day of the month %q", val)
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(val)
+			if err != nil || rule.count < 1 {
+				return rule, fmt.Errorf("invalid rrule count %q", val)
+			}
+		case "UNTIL":
+			until, err := parseRRuleUntil(val)
+			if err != nil {
+				return rule, err
+			}
+			rule.until = &until
+		case "WKST":
+		default:
+			return rule, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	switch {
+	case rule.frequency == "":
+		return rule, errors.New("rrule has no FREQ")
+	case rule.frequency == ToDoRecurrence_Monthly && rule.days != 0:
+		return rule, errors.New("unsupported rrule BYDAY for a MONTHLY rule")
+	case rule.frequency != ToDoRecurrence_Monthly && rule.monthDay != 0:
+		return rule, errors.New("rrule BYMONTHDAY is only supported for a MONTHLY rule")
+	case rule.count > 0 && rule.until != nil:
+		return rule, errors.New("rrule has both COUNT and UNTIL")
+	}
+
+	return rule, nil
+}
+
+// parseRRuleUntil reads a date, which includes the whole day, or a UTC date-time
+func parseRRuleUntil(until string) (time.Time, error) {
+	if at, err := time.Parse("20060102", until); err == nil {
+		return at.Add(24*time.Hour - time.Second), nil
+	}
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if at, err := time.Parse(layout, until); err == nil {
+			return at, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid rrule until %q", until)
+}
+
+// addDays moves to another day keeping the time of day across DST changes
+func addDays(at time.Time, days int) time.Time {
+	return time.Date(at.Year(), at.Month(), at.Day()+days, at.Hour(), at.Minute(), at.Second(), 0, at.Location())
+}
+
+// monthDay returns the day of the month months after at, the last day of the
+// month when it is shorter
+func monthDay(at time.Time, months int, day int) time.Time {
+	first := time.Date(at.Year(), at.Month()+time.Month(months), 1, at.Hour(), at.Minute(), at.Second(), 0, at.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// startOfWeek returns the Monday of the week, weeks start on Monday as in RFC 5545
+func startOfWeek(at time.Time) time.Time {
+	return time.Date(at.Year(), at.Month(), at.Day()-(int(at.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,88 @@
+package value
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// 2026-10-12 is a Monday
+func dueAt(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestToDoRecurrenceNext(t *testing.T) {
+	until := func(at time.Time) *time.Time {
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		recurrence ToDoRecurrence
+		due        time.Time
+		occurrence int
+		want       time.Time
+	}{
+		{"daily", ToDoRecurrence{Frequency: ToDoRecurrence_Daily}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 17)},
+		{"every 3 days", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Interval: 3}, dueAt(2026, time.October, 30), 1, dueAt(2026, time.November, 2)},
+		{"weekdays skip the weekend", ToDoRecurrence{Frequency: ToDoRecurrence_Weekdays}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 19)},
+
+		{"weekly on the day of the task", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 23)},
+		{"every 2 weeks on the day of the task", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Interval: 2}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 30)},
+		{"weekly on the next day of the week", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Days: []string{"mon", "wed"}}, dueAt(2026, time.October, 12), 1, dueAt(2026, time.October, 14)},
+		{"weekly on the first day of the next week", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Days: []string{"mon", "wed"}}, dueAt(2026, time.October, 14), 1, dueAt(2026, time.October, 19)},
+		{"every 2 weeks within the week", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Interval: 2, Days: []string{"mon", "wed"}}, dueAt(2026, time.October, 12), 1, dueAt(2026, time.October, 14)},
+		{"every 2 weeks skips the week in between", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Interval: 2, Days: []string{"mon", "wed"}}, dueAt(2026, time.October, 14), 1, dueAt(2026, time.October, 26)},
+		{"every 3 weeks on Sunday, the end of the week", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Interval: 3, Days: []string{"sun"}}, dueAt(2026, time.October, 18), 1, dueAt(2026, time.November, 8)},
+		{"weekly across the year", ToDoRecurrence{Frequency: ToDoRecurrence_Weekly, Days: []string{"friday"}}, dueAt(2026, time.December, 25), 1, dueAt(2027, time.January, 1)},
+
+		{"monthly on the day of the task", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly}, dueAt(2026, time.October, 15), 1, dueAt(2026, time.November, 15)},
+		{"monthly later in the same month", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 20}, dueAt(2026, time.October, 15), 1, dueAt(2026, time.October, 20)},
+		{"every 3 months across the year", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, Interval: 3, MonthDay: 15}, dueAt(2026, time.October, 15), 1, dueAt(2027, time.January, 15)},
+		{"monthly on the 31st in February", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 31}, dueAt(2026, time.January, 31), 1, dueAt(2026, time.February, 28)},
+		{"monthly on the 31st after February", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 31}, dueAt(2026, time.February, 28), 1, dueAt(2026, time.March, 31)},
+		{"monthly on the 31st in a 30-day month", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 31}, dueAt(2026, time.March, 31), 1, dueAt(2026, time.April, 30)},
+		{"monthly on the 29th in a leap year", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 29}, dueAt(2028, time.January, 29), 1, dueAt(2028, time.February, 29)},
+		{"monthly from the 31st of December", ToDoRecurrence{Frequency: ToDoRecurrence_Monthly, MonthDay: 31}, dueAt(2026, time.December, 31), 1, dueAt(2027, time.January, 31)},
+
+		{"count left", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Count: 3}, dueAt(2026, time.October, 16), 2, dueAt(2026, time.October, 17)},
+		{"count reached", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Count: 3}, dueAt(2026, time.October, 16), 3, time.Time{}},
+		{"count of 1", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Count: 1}, dueAt(2026, time.October, 16), 0, time.Time{}},
+		{"until the next occurrence", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Until: until(dueAt(2026, time.October, 17))}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 17)},
+		{"until before the next occurrence", ToDoRecurrence{Frequency: ToDoRecurrence_Daily, Until: until(dueAt(2026, time.October, 17).Add(-time.Second))}, dueAt(2026, time.October, 16), 1, time.Time{}},
+
+		{"rrule every 2 weeks", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4"}, dueAt(2026, time.October, 14), 3, dueAt(2026, time.October, 26)},
+		{"rrule count reached", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4"}, dueAt(2026, time.October, 26), 4, time.Time{}},
+		{"rrule count over the count of the task", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=DAILY;COUNT=5", Count: 2}, dueAt(2026, time.October, 16), 2, dueAt(2026, time.October, 17)},
+		{"rrule month end", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "RRULE:FREQ=MONTHLY;BYMONTHDAY=31"}, dueAt(2026, time.April, 30), 1, dueAt(2026, time.May, 31)},
+		{"rrule until a date includes the day", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=DAILY;UNTIL=20261017"}, dueAt(2026, time.October, 16), 1, dueAt(2026, time.October, 17)},
+		{"rrule until a date passed", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=DAILY;UNTIL=20261017"}, dueAt(2026, time.October, 17), 1, time.Time{}},
+		{"rrule until a time", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=DAILY;UNTIL=20261017T090000Z"}, dueAt(2026, time.October, 16), 1, time.Time{}},
+		{"rrule until of the task", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=DAILY", Until: until(dueAt(2026, time.October, 16))}, dueAt(2026, time.October, 16), 1, time.Time{}},
+		{"invalid rrule", ToDoRecurrence{Frequency: ToDoRecurrence_RRule, RRule: "FREQ=YEARLY"}, dueAt(2026, time.October, 16), 1, time.Time{}},
+		{"invalid frequency", ToDoRecurrence{Frequency: "hourly"}, dueAt(2026, time.October, 16), 1, time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next, ok := test.recurrence.Next(test.due, test.occurrence)
+			if ok != !test.want.IsZero() || !next.Equal(test.want) {
+				t.Errorf("Next(%s, %d) = %s, %t, want %s", test.due, test.occurrence, next, ok, test.want)
+			}
+		})
+	}
+}
+
+func TestToDoRecurrenceNextKeepsTheTimeOfDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation returned %v", err)
+	}
+
+	// Clocks go back on 2026-10-25 in Berlin, the week after is 169 hours long
+	due := time.Date(2026, time.October, 24, 9, 30, 0, 0, berlin)
+	want := time.Date(2026, time.October, 31, 9, 30, 0, 0, berlin)
+	if next, ok := (ToDoRecurrence{Frequency: ToDoRecurrence_Weekly}).Next(due, 1); !ok || !next.Equal(want) {
+		t.Errorf("Next(%s) = %s, %t, want %s", due, next, ok, want)
+	}
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/value"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +13,8 @@ import (
 )
 
 type SecuredMiddleware struct {
-	SessionRepository repository.SessionRepository
+	SessionRepository    repository.SessionRepository
+	PermissionRepository *repository.PermissionRepository
 }
 
 func (receiver SecuredMiddleware) Secured() gin.HandlerFunc {
@@ -29,14 +32,18 @@ func (receiver SecuredMiddleware) Secured() gin.HandlerFunc {
 
 		tokenString := strings.Split(authorizationHeader, " ")[1]
 		token, err := receiver.SessionRepository.ValidateToken(tokenString)
-		if err != nil {
+		if errors.Is(err, repository.ErrSessionRevoked) {
+			context.AbortWithStatus(http.StatusUnauthorized)
+		} else if err != nil {
 			context.AbortWithStatus(http.StatusForbidden)
 		} else if token.Valid {
 			userId, err := receiver.SessionRepository.ExtractUserIdFromToken(tokenString)
 			if err != nil {
 				context.AbortWithStatus(http.StatusForbidden)
+				return
 			}
 			context.Set("user_id", *userId)
+			context.Set("session_id", receiver.SessionRepository.GetSessionIdFromToken(token))
 			context.Next()
 		} else {
 			context.AbortWithStatus(http.StatusUnauthorized)
@@ -57,12 +64,19 @@ func (receiver SecuredMiddleware) ValidateSuperAdminRole() gin.HandlerFunc {
 		if err != nil {
 			context.AbortWithStatus(http.StatusForbidden)
 		} else if token.Valid {
-			roles, userId, err := receiver.SessionRepository.GetRoleFromToken(token)
-			if err != nil {
+			userId, err := receiver.SessionRepository.GetUserIdFromToken(token)
+			if err != nil || receiver.PermissionRepository == nil {
 				context.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			superAdmin, err := receiver.PermissionRepository.IsSuperAdmin(*userId)
+			if err != nil {
+				log.Error("SecuredMiddleware.ValidateSuperAdminRole ", err)
+				context.AbortWithStatus(http.StatusInternalServerError)
+				return
 			}
-			if lo.Contains(roles, "SuperAdmin") {
-				context.Set("user_id", userId)
+			if superAdmin {
+				context.Set("user_id", *userId)
 				context.Next()
 			} else {
 				context.AbortWithStatus(http.StatusForbidden)
@@ -73,3 +87,140 @@ func (receiver SecuredMiddleware) ValidateSuperAdminRole() gin.HandlerFunc {
 		}
 	}
 }
+
+// RequirePermission lets a request through when the user holds the permission
+// through their roles, see PermissionRepository.GetUserPermissions. The
+// SuperAdmin role without an organization, see PermissionRepository.IsSuperAdmin,
+// holds every permission in every organization. The user id and the
+// value.AccessScope of the permission are set on the context for the handlers.
+func (receiver SecuredMiddleware) RequirePermission(permission value.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		scope, granted, status := receiver.resolveAccessScope(context, permission)
+		if status != http.StatusOK {
+			context.AbortWithStatus(status)
+			return
+		}
+		if !granted {
+			context.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		context.Set("user_id", scope.UserId)
+		context.Set("access_scope", scope)
+		context.Next()
+	}
+}
+
+// RequireGlobalPermission is RequirePermission for the routes of resources that
+// belong to no organization, such as webhooks, which see the events of every
+// organization. The permission must be granted over every organization.
+func (receiver SecuredMiddleware) RequireGlobalPermission(permission value.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		scope, granted, status := receiver.resolveAccessScope(context, permission)
+		if status != http.StatusOK {
+			context.AbortWithStatus(status)
+			return
+		}
+		if !granted || !scope.AllOrganizations {
+			context.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		context.Set("user_id", scope.UserId)
+		context.Set("access_scope", scope)
+		context.Next()
+	}
+}
+
+// OptionalPermission sets the value.AccessScope of the permission on the context
+// when the request carries a valid token, without rejecting the request. A user
+// without the permission gets a scope with no organization, which only lets them
+// act on themselves.
+func (receiver SecuredMiddleware) OptionalPermission(permission value.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if !strings.HasPrefix(context.GetHeader("Authorization"), "Bearer ") {
+			context.Next()
+			return
+		}
+
+		scope, _, status := receiver.resolveAccessScope(context, permission)
+		if status == http.StatusInternalServerError {
+			context.AbortWithStatus(status)
+			return
+		}
+		if status == http.StatusOK {
+			context.Set("user_id", scope.UserId)
+			context.Set("access_scope", scope)
+		}
+		context.Next()
+	}
+}
+
+func (receiver SecuredMiddleware) resolveAccessScope(context *gin.Context, permission value.Permission) (value.AccessScope, bool, int) {
+	authorizationHeader := context.GetHeader("Authorization")
+	if !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return value.AccessScope{}, false, http.StatusForbidden
+	}
+
+	tokenString := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	token, err := receiver.SessionRepository.ValidateToken(tokenString)
+	if errors.Is(err, repository.ErrSessionRevoked) {
+		return value.AccessScope{}, false, http.StatusUnauthorized
+	}
+	if err != nil {
+		return value.AccessScope{}, false, http.StatusForbidden
+	}
+	if !token.Valid {
+		log.Info("Token is not valid")
+		return value.AccessScope{}, false, http.StatusUnauthorized
+	}
+
+	userId, err := receiver.SessionRepository.GetUserIdFromToken(token)
+	if err != nil {
+		return value.AccessScope{}, false, http.StatusForbidden
+	}
+
+	scope := value.AccessScope{UserId: *userId}
+	if receiver.PermissionRepository == nil {
+		return scope, false, http.StatusOK
+	}
+
+	superAdmin, err := receiver.PermissionRepository.IsSuperAdmin(scope.UserId)
+	if err != nil {
+		log.Error("SecuredMiddleware.resolveAccessScope ", err)
+		return scope, false, http.StatusInternalServerError
+	}
+	if superAdmin {
+		scope.AllOrganizations = true
+		return scope, true, http.StatusOK
+	}
+
+	grants, err := receiver.PermissionRepository.GetUserPermissions(scope.UserId)
+	if err != nil {
+		log.Error("SecuredMiddleware.resolveAccessScope ", err)
+		return scope, false, http.StatusInternalServerError
+	}
+
+	scope, granted := grantedScope(scope, grants, permission)
+
+	return scope, granted, http.StatusOK
+}
+
+// grantedScope adds the organizations of the grants holding the permission to
+// the scope, a grant without an organization covers every organization
+func grantedScope(scope value.AccessScope, grants []repository.PermissionGrant, permission value.Permission) (value.AccessScope, bool) {
+	granted := false
+	for _, grant := range grants {
+		if !value.PermissionGranted([]string{grant.Permission}, permission) {
+			continue
+		}
+		granted = true
+		if grant.OrganizationId == 0 {
+			scope.AllOrganizations = true
+		} else if !lo.Contains(scope.OrganizationIds, grant.OrganizationId) {
+			scope.OrganizationIds = append(scope.OrganizationIds, grant.OrganizationId)
+		}
+	}
+
+	return scope, granted
+}
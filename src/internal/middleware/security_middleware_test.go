@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"reflect"
+	"sen-global-api/internal/data/repository"
+	"sen-global-api/internal/domain/value"
+	"testing"
+)
+
+func TestGrantedScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		grants     []repository.PermissionGrant
+		permission value.Permission
+		want       value.AccessScope
+		granted    bool
+	}{
+		{
+			name:       "no grants",
+			permission: value.Permission_FormRead,
+			want:       value.AccessScope{UserId: "user"},
+		},
+		{
+			name: "grants of other permissions",
+			grants: []repository.PermissionGrant{
+				{Permission: "form:write", OrganizationId: 1},
+				{Permission: "device:*", OrganizationId: 2},
+			},
+			permission: value.Permission_FormRead,
+			want:       value.AccessScope{UserId: "user"},
+		},
+		{
+			name: "organizations of the matching grants once each",
+			grants: []repository.PermissionGrant{
+				{Permission: "form:read", OrganizationId: 1},
+				{Permission: "form:*", OrganizationId: 2},
+				{Permission: "*", OrganizationId: 1},
+				{Permission: "form:write", OrganizationId: 3},
+			},
+			permission: value.Permission_FormRead,
+			want:       value.AccessScope{UserId: "user", OrganizationIds: []int64{1, 2}},
+			granted:    true,
+		},
+		{
+			name: "grant without an organization covers every organization",
+			grants: []repository.PermissionGrant{
+				{Permission: "form:read", OrganizationId: 1},
+				{Permission: "form:read"},
+			},
+			permission: value.Permission_FormRead,
+			want:       value.AccessScope{UserId: "user", AllOrganizations: true, OrganizationIds: []int64{1}},
+			granted:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, granted := grantedScope(value.AccessScope{UserId: "user"}, test.grants, test.permission)
+			if granted != test.granted || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("grantedScope = %+v, %t, want %+v, %t", got, granted, test.want, test.granted)
+			}
+		})
+	}
+}
@@ -148,3 +148,23 @@ func MigrateDevices(db *gorm.DB) error {
 
 	return db.Save(&newDevices).Error
 }
+
+// MigrateSettingOrganizations drops the unique index of s_setting on the type
+// alone, the settings are now unique per organization and type
+func MigrateSettingOrganizations(db *gorm.DB) error {
+	if !db.Migrator().HasTable("s_setting") {
+		return nil
+	}
+
+	for _, name := range []string{"type", "uni_s_setting_type", "idx_s_setting_type"} {
+		if !db.Migrator().HasIndex("s_setting", name) {
+			continue
+		}
+		if err := db.Migrator().DropIndex("s_setting", name); err != nil {
+			return err
+		}
+		log.Info("s_setting: dropped the unique index ", name)
+	}
+
+	return nil
+}
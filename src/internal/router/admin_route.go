@@ -8,12 +8,12 @@ import (
 	"sen-global-api/internal/domain/request"
 	"sen-global-api/internal/domain/usecase"
 	"sen-global-api/internal/domain/usecase/infrastructure"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/internal/middleware"
 	"sen-global-api/pkg/job"
 	"sen-global-api/pkg/monitor"
 	"sen-global-api/pkg/sheet"
 	"strconv"
-	"time"
 
 	firebase "firebase.google.com/go/v4"
 
@@ -25,16 +25,18 @@ import (
 func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfig, userSpreadsheet *sheet.Spreadsheet, uploaderSpreadsheet *sheet.Spreadsheet, fcm *firebase.App) {
 	usecase.AdminSpreadsheetClient = userSpreadsheet
 	usecase.TheTimeMachine = job.New()
-	sessionRepository := repository.SessionRepository{
-		AuthorizeEncryptKey: config.AuthorizeEncryptKey,
-
-		TokenExpireTimeInHour: time.Duration(config.TokenExpireDurationInHour),
-	}
+	usecase.TheWebhookUseCase = usecase.NewWebhookUseCase(dbConn, config.DefaultRequestPageSize)
+	usecase.TheToDoSheetSyncUseCase = usecase.NewToDoSheetSyncUseCase(dbConn, config.DefaultRequestPageSize, userSpreadsheet)
+	sessionRepository := usecase.NewSessionRepository(config, dbConn)
 	formRepo := &repository.FormRepository{DBConn: dbConn, DefaultRequestPageSize: config.DefaultRequestPageSize}
 
-	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository}
+	secureMiddleware := middleware.SecuredMiddleware{
+		SessionRepository:    sessionRepository,
+		PermissionRepository: &repository.PermissionRepository{DBConn: dbConn},
+	}
 	settingRepository := &repository.SettingRepository{DBConn: dbConn}
 
+	formRevisionUseCase := usecase.NewFormRevisionUseCase(dbConn)
 	importFormsUseCase := &usecase.ImportFormsUseCase{
 		FormRepository:                  formRepo,
 		QuestionRepository:              &repository.QuestionRepository{DBConn: dbConn},
@@ -44,6 +46,7 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 		SettingRepository:               settingRepository,
 		DefaultCronJobIntervalInMinutes: config.DefaultCronJobIntervalInMinutes,
 		TimeMachine:                     usecase.TheTimeMachine,
+		FormRevisionUseCase:             formRevisionUseCase,
 		AppConfig:                       config,
 	}
 	importUrlsUseCase := &usecase.ImportRedirectUrlsUseCase{
@@ -55,9 +58,13 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 		SpreadsheetWriter: uploaderSpreadsheet.Writer,
 		SettingRepository: settingRepository,
 		TimeMachine:       usecase.TheTimeMachine,
+		DB:                dbConn,
 	}
 
 	deviceRepository := &repository.DeviceRepository{DBConn: dbConn, DefaultRequestPageSize: config.DefaultRequestPageSize, DefaultOutputSpreadsheetUrl: config.OutputSpreadsheetUrl}
+	devicePresenceUseCase := usecase.NewDevicePresenceUseCase(dbConn, config.DevicePresence)
+	deviceCommandUseCase := usecase.NewDeviceCommandUseCase(dbConn, config.DefaultRequestPageSize, fcm)
+	deviceScheduleUseCase := usecase.NewDeviceScheduleUseCase(dbConn, fcm)
 
 	v1 := engine.Group("/v1/admin")
 	{
@@ -94,28 +101,91 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 			},
 			ImportFormsUseCase: importFormsUseCase,
 		}
-		v1.POST("/form/create", secureMiddleware.ValidateSuperAdminRole(), form.CreateForm)
+		v1.POST("/form/create", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.CreateForm)
 
-		v1.GET("/form/list", secureMiddleware.ValidateSuperAdminRole(), form.GetFormList)
+		v1.GET("/form/list", secureMiddleware.RequirePermission(value.Permission_FormRead), form.GetFormList)
 
-		v1.DELETE("/form/delete/:id", secureMiddleware.ValidateSuperAdminRole(), form.DeleteForm)
+		v1.DELETE("/form/delete/:id", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.DeleteForm)
 
-		v1.GET("/forms/search", secureMiddleware.ValidateSuperAdminRole(), form.SearchForms)
+		v1.GET("/forms/search", secureMiddleware.RequirePermission(value.Permission_FormRead), form.SearchForms)
 
-		v1.PUT("/form/:id", secureMiddleware.ValidateSuperAdminRole(), form.UpdateForm)
+		v1.PUT("/form/:id", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.UpdateForm)
 
-		v1.POST("/forms/import", secureMiddleware.ValidateSuperAdminRole(), form.ImportForms)
+		v1.POST("/forms/import", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.ImportForms)
 
-		v1.POST("/forms2/import", secureMiddleware.ValidateSuperAdminRole(), form.ImportForms2)
+		v1.POST("/forms2/import", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.ImportForms2)
 
-		v1.POST("/forms3/import", secureMiddleware.ValidateSuperAdminRole(), form.ImportForms3)
+		v1.POST("/forms3/import", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.ImportForms3)
 
-		v1.POST("/forms4/import", secureMiddleware.ValidateSuperAdminRole(), form.ImportForms4)
+		v1.POST("/forms4/import", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.ImportForms4)
 
 		v1.POST("/forms/partially/import", middleware.NewSecureAppMiddleware(dbConn).Secure(), form.ImportFormsPartially)
 
 		v1.POST("/forms/signup", form.ImportSignUpForms)
 
+		formBuilder := &controller.FormBuilderController{
+			FormBuilderUseCase: &usecase.FormBuilderUseCase{DBConn: dbConn},
+		}
+		v1.POST("/form/builder", secureMiddleware.RequirePermission(value.Permission_FormWrite), formBuilder.CreateForm)
+
+		v1.GET("/form/:id/questions", secureMiddleware.RequirePermission(value.Permission_FormRead), form.ReadableForm, formBuilder.GetForm)
+
+		v1.POST("/form/:id/question", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formBuilder.AddQuestion)
+
+		v1.PUT("/form/:id/question/:question_id", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formBuilder.UpdateQuestion)
+
+		v1.PUT("/form/:id/questions/order", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formBuilder.ReorderQuestions)
+
+		v1.DELETE("/form/:id/question/:question_id", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formBuilder.DeleteQuestion)
+
+		formRevision := &controller.FormRevisionController{
+			FormRevisionUseCase: formRevisionUseCase,
+		}
+		v1.GET("/form/:id/revisions", secureMiddleware.RequirePermission(value.Permission_FormRead), form.ReadableForm, formRevision.GetRevisions)
+
+		v1.POST("/form/:id/revisions", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formRevision.CreateDraft)
+
+		v1.GET("/form/:id/revisions/diff", secureMiddleware.RequirePermission(value.Permission_FormRead), form.ReadableForm, formRevision.Diff)
+
+		v1.GET("/form/:id/revisions/:revision", secureMiddleware.RequirePermission(value.Permission_FormRead), form.ReadableForm, formRevision.GetRevision)
+
+		v1.POST("/form/:id/revisions/:revision/publish", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formRevision.Publish)
+
+		v1.POST("/form/:id/revisions/:revision/rollback", secureMiddleware.RequirePermission(value.Permission_FormWrite), form.OwnedForm, formRevision.Rollback)
+
+		submission := &controller.SubmissionController{
+			SubmissionQueryUseCase: usecase.NewSubmissionQueryUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/submissions", secureMiddleware.RequirePermission(value.Permission_SubmissionRead), submission.GetSubmissions)
+
+		v1.GET("/form/:id/submissions/export", secureMiddleware.RequirePermission(value.Permission_SubmissionRead), form.ReadableForm, submission.ExportSubmissions)
+
+		webhook := &controller.WebhookController{
+			WebhookUseCase: usecase.TheWebhookUseCase,
+		}
+		v1.GET("/webhooks", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookRead), webhook.GetWebhooks)
+
+		v1.POST("/webhooks", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookWrite), webhook.CreateWebhook)
+
+		v1.GET("/webhooks/:id", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookRead), webhook.GetWebhook)
+
+		v1.PUT("/webhooks/:id", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookWrite), webhook.UpdateWebhook)
+
+		v1.DELETE("/webhooks/:id", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookWrite), webhook.DeleteWebhook)
+
+		v1.POST("/webhooks/:id/secret", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookWrite), webhook.RotateWebhookSecret)
+
+		v1.GET("/webhooks/:id/deliveries", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookRead), webhook.GetDeliveries)
+
+		v1.GET("/webhook-deliveries/:id", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookRead), webhook.GetDelivery)
+
+		v1.POST("/webhook-deliveries/:id/replay", secureMiddleware.RequireGlobalPermission(value.Permission_WebhookWrite), webhook.ReplayDelivery)
+
+		auditLog := &controller.AuditLogController{
+			AccessControl: usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/audit-logs", secureMiddleware.RequirePermission(value.Permission_AuditRead), auditLog.GetAuditLogs)
+
 		deviceController := &controller.DeviceController{
 			DBConn: dbConn,
 			UpdateDeviceSheetUseCase: &usecase.UpdateDeviceSheetUseCase{
@@ -124,29 +194,132 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 			RegisterDeviceUseCase: &usecase.RegisterDeviceUseCase{
 				DeviceRepository:  deviceRepository,
 				SessionRepository: &sessionRepository,
-				Writer:            userSpreadsheet.Writer,
-				Reader:            userSpreadsheet.Reader,
+				SpreadsheetWriter: userSpreadsheet.Writer,
+				SpreadsheetReader: userSpreadsheet.Reader,
 			},
 			GetDeviceByIdUseCase: &usecase.GetDeviceByIdUseCase{
 				DeviceRepository: deviceRepository,
 			},
 			GetDeviceListUseCase: &usecase.GetDeviceListUseCase{
-				DeviceRepository: deviceRepository,
+				DeviceRepository:          deviceRepository,
+				DeviceHeartbeatRepository: &repository.DeviceHeartbeatRepository{DBConn: dbConn},
+				PresenceThresholds:        usecase.DevicePresenceThresholds(config.DevicePresence),
 			},
 			UpdateDeviceUseCase: &usecase.UpdateDeviceUseCase{
 				DeviceRepository:  deviceRepository,
 				SettingRepository: settingRepository,
-				Writer:            userSpreadsheet.Writer,
+				SpreadsheetWriter: userSpreadsheet.Writer,
 			},
+			DevicePresenceUseCase: devicePresenceUseCase,
+			AccessControl:         usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+
+		v1.GET("/devices", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceController.ListDevices)
+
+		bulkDevice := &controller.BulkDeviceController{
+			BulkDeviceUseCase: usecase.NewBulkDeviceUseCase(dbConn, fcm),
+			AccessControl:     usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
 		}
+		v1.POST("/devices/bulk", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), bulkDevice.UpdateDevices)
 
-		v1.PUT("/device/deactivate/:device_id", secureMiddleware.ValidateSuperAdminRole(), deviceController.DeactivateDevice)
+		v1.GET("/device/:device_id/heartbeats", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceController.GetDeviceHeartbeats)
 
-		v1.PUT("/device/activate/:device_id", secureMiddleware.ValidateSuperAdminRole(), deviceController.ActivateDevice)
+		deviceCommand := &controller.DeviceCommandController{
+			DeviceCommandUseCase: deviceCommandUseCase,
+			AccessControl:        usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.POST("/device/:device_id/commands", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceCommand.IssueCommand)
+
+		v1.GET("/device/:device_id/commands", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceCommand.GetCommands)
+
+		v1.GET("/device-commands/:id", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceCommand.GetCommand)
 
-		v1.PUT("/device/:device_id/update", secureMiddleware.ValidateSuperAdminRole(), deviceController.UpdateDevice)
+		v1.POST("/device-commands/:id/cancel", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceCommand.CancelCommand)
+
+		deviceGroup := &controller.DeviceGroupController{
+			DeviceGroupUseCase: usecase.NewDeviceGroupUseCase(dbConn),
+			AccessControl:      usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/device-groups", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceGroup.GetGroups)
 
-		v1.PUT("/device/:device_id/updatev2", secureMiddleware.ValidateSuperAdminRole(), deviceController.UpdateDeviceV2)
+		v1.POST("/device-groups", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.CreateGroup)
+
+		v1.GET("/device-groups/:id", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceGroup.GetGroup)
+
+		v1.PUT("/device-groups/:id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.UpdateGroup)
+
+		v1.DELETE("/device-groups/:id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.DeleteGroup)
+
+		v1.GET("/device-groups/:id/devices", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceGroup.GetGroupDevices)
+
+		v1.POST("/device-groups/:id/devices", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.AddDevices)
+
+		v1.POST("/device-groups/:id/devices/remove", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.RemoveDevices)
+
+		v1.GET("/device-tags", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceGroup.GetTags)
+
+		v1.GET("/device/:device_id/tags", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceGroup.GetDeviceTags)
+
+		v1.PUT("/device/:device_id/tags", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceGroup.ReplaceDeviceTags)
+
+		deviceConfiguration := &controller.DeviceConfigurationController{
+			DeviceConfigurationUseCase: usecase.NewDeviceConfigurationUseCase(dbConn),
+			AccessControl:              usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/device/:device_id/configuration", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceConfiguration.GetEffectiveConfiguration)
+
+		// the global scope has no scope_id
+		v1.GET("/device-configuration/:scope", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceConfiguration.GetConfiguration)
+
+		v1.PUT("/device-configuration/:scope", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceConfiguration.SaveConfiguration)
+
+		v1.GET("/device-configuration/:scope/:scope_id", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceConfiguration.GetConfiguration)
+
+		v1.PUT("/device-configuration/:scope/:scope_id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceConfiguration.SaveConfiguration)
+
+		deviceSchedule := &controller.DeviceScheduleController{
+			DeviceScheduleUseCase: deviceScheduleUseCase,
+			AccessControl:         usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/device-schedules", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceSchedule.GetSchedules)
+
+		v1.POST("/device-schedules", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceSchedule.CreateSchedule)
+
+		v1.GET("/device-schedules/:id", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceSchedule.GetSchedule)
+
+		v1.PUT("/device-schedules/:id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceSchedule.UpdateSchedule)
+
+		v1.DELETE("/device-schedules/:id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceSchedule.DeleteSchedule)
+
+		v1.GET("/device-mode-overrides", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceSchedule.GetOverrides)
+
+		v1.POST("/device-mode-overrides", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceSchedule.CreateOverride)
+
+		v1.DELETE("/device-mode-overrides/:id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceSchedule.DeleteOverride)
+
+		v1.PUT("/organization/:id/timezone", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), deviceSchedule.UpdateOrganizationTimezone)
+
+		deviceEnrollment := &controller.DeviceEnrollmentController{
+			DeviceEnrollmentUseCase: usecase.NewDeviceEnrollmentUseCase(dbConn, &sessionRepository),
+			AccessControl:           usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		v1.GET("/enrollment-codes", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceEnrollment.GetCodes)
+
+		v1.POST("/enrollment-codes", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceEnrollment.CreateCode)
+
+		v1.GET("/enrollment-codes/:id", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceEnrollment.GetCode)
+
+		v1.POST("/enrollment-codes/:id/revoke", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceEnrollment.RevokeCode)
+
+		v1.GET("/enrollment-codes/:id/enrollments", secureMiddleware.RequirePermission(value.Permission_DeviceRead), deviceEnrollment.GetEnrollments)
+
+		v1.PUT("/device/deactivate/:device_id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceController.DeactivateDevice)
+
+		v1.PUT("/device/activate/:device_id", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceController.ActivateDevice)
+
+		v1.PUT("/device/:device_id/update", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceController.UpdateDevice)
+
+		v1.PUT("/device/:device_id/updatev2", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceController.UpdateDeviceV2)
 	}
 	redirectUrl := engine.Group("/v1/admin/redirect-url")
 	{
@@ -166,28 +339,53 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 			},
 			GetRedirectUrlByQRCodeUseCase: nil,
 			ImportRedirectUrlsUseCase:     importUrlsUseCase,
+			RedirectUrlAnalyticsUseCase: &usecase.RedirectUrlAnalyticsUseCase{
+				RedirectUrlScanRepository: &repository.RedirectUrlScanRepository{DBConn: dbConn},
+			},
 		}
-		redirectUrl.POST("/create", secureMiddleware.ValidateSuperAdminRole(), redirectController.CreateRedirectUrl)
+		redirectUrl.POST("/create", secureMiddleware.RequirePermission(value.Permission_RedirectUrlWrite), redirectController.CreateRedirectUrl)
 
-		redirectUrl.GET("/list", secureMiddleware.ValidateSuperAdminRole(), redirectController.GetRedirectUrlList)
+		redirectUrl.GET("/list", secureMiddleware.RequirePermission(value.Permission_RedirectUrlRead), redirectController.GetRedirectUrlList)
 
-		redirectUrl.DELETE("/:id", secureMiddleware.ValidateSuperAdminRole(), redirectController.DeleteRedirectUrl)
+		redirectUrl.DELETE("/:id", secureMiddleware.RequirePermission(value.Permission_RedirectUrlWrite), redirectController.DeleteRedirectUrl)
 
-		redirectUrl.PUT("/:id", secureMiddleware.ValidateSuperAdminRole(), redirectController.UpdateRedirectUrl)
+		redirectUrl.PUT("/:id", secureMiddleware.RequirePermission(value.Permission_RedirectUrlWrite), redirectController.UpdateRedirectUrl)
 
-		redirectUrl.POST("/import", secureMiddleware.ValidateSuperAdminRole(), redirectController.ImportRedirectUrls)
+		redirectUrl.POST("/import", secureMiddleware.RequirePermission(value.Permission_RedirectUrlWrite), redirectController.ImportRedirectUrls)
 		//Partially import
+		redirectUrl.GET("/analytics", secureMiddleware.RequirePermission(value.Permission_RedirectUrlRead), redirectController.GetRedirectUrlAnalytics)
+
+		redirectUrl.GET("/analytics/export", secureMiddleware.RequirePermission(value.Permission_RedirectUrlRead), redirectController.ExportRedirectUrlAnalytics)
+
 		redirectUrl.POST("/import/partially", middleware.NewSecureAppMiddleware(dbConn).Secure(), redirectController.ImportPartiallyRedirectUrls)
 	}
 
 	todo := engine.Group("/v1/admin/todo")
 	{
 		todoController := controller.NewImportToDoListController(config, dbConn, uploaderSpreadsheet.Reader, uploaderSpreadsheet.Writer, usecase.TheTimeMachine)
-		todo.POST("/import", secureMiddleware.ValidateSuperAdminRole(), todoController.ImportTodos)
+		todo.POST("/import", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoController.ImportTodos)
 		todo.POST("/import/partially", middleware.NewSecureAppMiddleware(dbConn).Secure(), todoController.ImportPartiallyTodos)
+
+		todoListController := &controller.ToDoListController{
+			ToDoUseCase:   usecase.NewToDoUseCase(dbConn, config.DefaultRequestPageSize, usecase.TheToDoSheetSyncUseCase),
+			AccessControl: usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+		}
+		todo.GET("", secureMiddleware.RequirePermission(value.Permission_ToDoRead), todoListController.GetToDos)
+		todo.POST("", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.CreateToDo)
+		todo.GET("/:id", secureMiddleware.RequirePermission(value.Permission_ToDoRead), todoListController.GetToDo)
+		todo.PUT("/:id", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.UpdateToDo)
+		todo.DELETE("/:id", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.DeleteToDo)
+		todo.POST("/:id/tasks", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.AddTask)
+		todo.PUT("/:id/tasks/:index", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.UpdateTask)
+		todo.DELETE("/:id/tasks/:index", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.DeleteTask)
+		todo.GET("/:id/sheet-syncs", secureMiddleware.RequirePermission(value.Permission_ToDoRead), todoListController.GetSheetSyncs)
+		todo.POST("/:id/sheet-syncs/:sync_id/retry", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.RetrySheetSync)
+		todo.GET("/:id/assignees", secureMiddleware.RequirePermission(value.Permission_ToDoRead), todoListController.GetAssignees)
+		todo.PUT("/:id/assignees", secureMiddleware.RequirePermission(value.Permission_ToDoWrite), todoListController.ReplaceAssignees)
+		todo.GET("/:id/progress", secureMiddleware.RequirePermission(value.Permission_ToDoRead), todoListController.GetProgress)
 	}
 
-	system := engine.Group("/v1/admin/settings", secureMiddleware.ValidateSuperAdminRole())
+	system := engine.Group("/v1/admin/settings")
 	{
 		systemController := &controller.SettingController{
 			GetSettingsUseCase: &usecase.GetSettingsUseCase{
@@ -205,10 +403,12 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 			UpdateOutputTemplateSettingUseCase: &usecase.UpdateOutputTemplateSettingUseCase{
 				SettingRepository: settingRepository,
 				AppConfig:         config,
+				Files:             userSpreadsheet.Files,
 			},
 			UpdateOutputTemplateSettingForTeacherUseCase: &usecase.UpdateOutputTemplateSettingForTeacherUseCase{
 				SettingRepository: settingRepository,
 				AppConfig:         config,
+				Files:             userSpreadsheet.Files,
 			},
 			AdminSignUpUseCases: &usecase.AdminSignUpUseCases{
 				SettingRepository: settingRepository,
@@ -224,68 +424,69 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 					SettingRepository:               settingRepository,
 					DefaultCronJobIntervalInMinutes: 0,
 					TimeMachine:                     nil,
+					FormRevisionUseCase:             formRevisionUseCase,
 					AppConfig:                       config,
 				},
 			},
 			UpdateSettingNameUseCase:    usecase.NewUpdateSettingNameUseCase(dbConn),
 			UpdateApiDistributorUseCase: usecase.NewUpdateApiDistributorUseCase(dbConn, userSpreadsheet.Reader, userSpreadsheet.Writer),
 		}
-		system.GET("/", systemController.GetSettings)
+		system.GET("/", secureMiddleware.RequirePermission(value.Permission_SettingRead), systemController.GetSettings)
 
-		system.POST("/output-sheet", systemController.UpdateOutputSubmissionSettings)
+		system.POST("/output-sheet", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateOutputSubmissionSettings)
 
-		system.POST("/output-summary", systemController.UpdateOutputSummarySettings)
+		system.POST("/output-summary", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateOutputSummarySettings)
 
-		system.POST("/email-history", systemController.UpdateEmailHistorySettings)
+		system.POST("/email-history", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateEmailHistorySettings)
 
-		system.POST("/output-template", systemController.UpdateOutputTemplateSettings)
+		system.POST("/output-template", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateOutputTemplateSettings)
 
-		system.POST("/output-template-teacher", systemController.UpdateOutputTemplateSettingsForTeacher)
+		system.POST("/output-template-teacher", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateOutputTemplateSettingsForTeacher)
 
-		system.POST("/sign-up-button-1", systemController.UpdateSignUpButton1)
+		system.POST("/sign-up-button-1", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButton1)
 
-		system.POST("/sign-up-button-2", systemController.UpdateSignUpButton2)
+		system.POST("/sign-up-button-2", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButton2)
 
-		system.POST("/sign-up-button-3", systemController.UpdateSignUpButton3)
+		system.POST("/sign-up-button-3", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButton3)
 
-		system.POST("/sign-up-button-4", systemController.UpdateSignUpButton4)
+		system.POST("/sign-up-button-4", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButton4)
 
-		system.POST("/sign-up-button-5", systemController.UpdateSignUpButton5)
+		system.POST("/sign-up-button-5", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButton5)
 
-		system.POST("/sign-up-button-configuration", systemController.UpdateSignUpButtonConfiguration)
+		system.POST("/sign-up-button-configuration", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateSignUpButtonConfiguration)
 
-		system.POST("/registration-form", systemController.UpdateRegistrationForm)
+		system.POST("/registration-form", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateRegistrationForm)
 
-		system.POST("/registration-submission", systemController.UpdateRegistrationSubmission)
+		system.POST("/registration-submission", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateRegistrationSubmission)
 
-		system.POST("/registration-preset-2", systemController.UpdateRegistrationPreset2)
+		system.POST("/registration-preset-2", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateRegistrationPreset2)
 
-		system.POST("/registration-preset-1", systemController.UpdateRegistrationPreset1)
+		system.POST("/registration-preset-1", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateRegistrationPreset1)
 
-		system.POST("/api-distributer", systemController.UpdateAPIDistributor)
+		system.POST("/api-distributer", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateAPIDistributor)
 
 		usecase.DBConn = dbConn
 		usecase.FirebaseApp = fcm
-		system.POST("/code-counting-data", systemController.UpdateCodeCountingData)
+		system.POST("/code-counting-data", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.UpdateCodeCountingData)
 
-		system.POST("/label/name", systemController.SetSettingNames)
+		system.POST("/label/name", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.SetSettingNames)
 
-		system.POST("/logo-refresh-interval", systemController.SetupLogoRefreshInterval)
-		system.GET("/logo-refresh-interval", systemController.GetLogoRefreshInterval)
+		system.POST("/logo-refresh-interval", secureMiddleware.RequirePermission(value.Permission_SettingWrite), systemController.SetupLogoRefreshInterval)
+		system.GET("/logo-refresh-interval", secureMiddleware.RequirePermission(value.Permission_SettingRead), systemController.GetLogoRefreshInterval)
 	}
 
 	monitoring := engine.Group("/v1/admin/monitor")
 	{
 		monitoringController := &controller.MonitoringController{}
 
-		monitoring.GET("/google-api", monitoringController.GetGoogleAPIMonitoring)
+		monitoring.GET("/google-api", secureMiddleware.RequirePermission(value.Permission_MonitorRead), monitoringController.GetGoogleAPIMonitoring)
 	}
 
 	controller.DBConn = dbConn
-	codeCounter := engine.Group("/v1/admin/code-counting", secureMiddleware.ValidateSuperAdminRole())
+	codeCounter := engine.Group("/v1/admin/code-counting")
 	{
-		codeCounter.GET("/list", controller.GetCodeCounterList)
-		codeCounter.PUT("/update", controller.UpdateCodeCounter)
+		codeCounter.GET("/list", secureMiddleware.RequirePermission(value.Permission_CodeCountingRead), controller.GetCodeCounterList)
+		codeCounter.PUT("/update", secureMiddleware.RequirePermission(value.Permission_CodeCountingWrite), controller.UpdateCodeCounter)
 	}
 
 	executor := &TimeMachineSubscriber{
@@ -342,7 +543,7 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 
 	infra := engine.Group("/infra")
 	{
-		infra.GET("/backup", infrastructure.BackupDatabase())
+		infra.GET("/backup", infrastructure.BackupDatabase(userSpreadsheet.Files))
 	}
 
 	deviceComponentValues := engine.Group("/v1/admin/device-component-values")
@@ -355,15 +556,16 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 			SaveDeviceComponentValuesUseCase: &usecase.SaveDeviceComponentValuesUseCase{
 				DeviceComponentValuesRepository: deviceComponentValuesRepository,
 			},
+			AccessControl: usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
 		}
 
 		deviceComponentValues.GET("/organization/:organization_id", deviceComponentValuesController.GetDeviceComponentValuesByOrganization)
 
 		deviceComponentValues.GET("/device/:organization_id", deviceComponentValuesController.GetDeviceComponentValuesByDevice)
 
-		deviceComponentValues.POST("/organization", secureMiddleware.ValidateSuperAdminRole(), deviceComponentValuesController.SaveDeviceComponentValuesByOrganization)
+		deviceComponentValues.POST("/organization", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceComponentValuesController.SaveDeviceComponentValuesByOrganization)
 
-		deviceComponentValues.POST("/device", secureMiddleware.ValidateSuperAdminRole(), deviceComponentValuesController.SaveDeviceComponentValuesByOrganization)
+		deviceComponentValues.POST("/device", secureMiddleware.RequirePermission(value.Permission_DeviceWrite), deviceComponentValuesController.SaveDeviceComponentValuesByOrganization)
 	}
 
 	usecase.TheTimeMachine.Start(formInterval, redirectInterval, toDosInterval, formInterval2, formInterval3, formInterval4)
@@ -376,6 +578,12 @@ func setupAdminRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConf
 	usecase.TheTimeMachine.SubscribeSyncDevicesExec(executor)
 	usecase.TheTimeMachine.SubscribeSyncToDosExec(executor)
 	usecase.TheTimeMachine.SubscribeGoogleAPIRequestMonitorExec(executor)
+	usecase.TheTimeMachine.SubscribeWebhookDeliveriesExec(usecase.TheWebhookUseCase)
+	usecase.TheTimeMachine.SubscribeDevicePresenceExec(devicePresenceUseCase)
+	usecase.TheTimeMachine.SubscribeDeviceCommandExec(deviceCommandUseCase)
+	usecase.TheTimeMachine.SubscribeDeviceScheduleExec(deviceScheduleUseCase)
+	usecase.TheTimeMachine.SubscribeToDoSheetSyncExec(usecase.TheToDoSheetSyncUseCase)
+	usecase.TheTimeMachine.SubscribeToDoReminderExec(usecase.NewToDoReminderUseCase(dbConn, fcm))
 }
 
 type TimeMachineSubscriber struct {
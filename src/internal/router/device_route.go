@@ -1,44 +1,24 @@
 package router
 
 import (
-	"context"
-	"fmt"
-	"os"
 	"sen-global-api/config"
 	"sen-global-api/internal/controller"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/internal/middleware"
-	"sen-global-api/pkg/monitor"
 	"sen-global-api/pkg/sheet"
 	"sen-global-api/pkg/uploader"
-	"time"
 
 	firebase "firebase.google.com/go/v4"
-	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/option"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *sheet.Spreadsheet, config config.AppConfig, fcm *firebase.App) {
-	sessionRepository := repository.SessionRepository{
-		AuthorizeEncryptKey: config.AuthorizeEncryptKey,
+	sessionRepository := usecase.NewSessionRepository(config, dbConn)
 
-		TokenExpireTimeInHour: time.Duration(config.TokenExpireDurationInHour),
-	}
-
-	pwd, err := os.Getwd()
-	if err != nil {
-		monitor.SendMessageViaTelegram(fmt.Sprintf("Error getting current directory: %s", err))
-		return
-	}
-	driveService, err := drive.NewService(context.Background(), option.WithCredentialsFile(pwd+"/credentials/google_service_account.json"))
-	if err != nil {
-		log.Fatal("Unable to access Drive API:", err)
-	}
 	formRepo := &repository.FormRepository{DBConn: dbConn, DefaultRequestPageSize: config.DefaultRequestPageSize}
 	deviceRepository := &repository.DeviceRepository{DBConn: dbConn, DefaultRequestPageSize: config.DefaultRequestPageSize, DefaultOutputSpreadsheetUrl: config.OutputSpreadsheetUrl}
 	userEntityRepository := repository.UserEntityRepository{DBConn: dbConn}
@@ -51,14 +31,16 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 			DeviceRepository:  deviceRepository,
 			SessionRepository: &sessionRepository,
 			SettingRepository: &repository.SettingRepository{DBConn: dbConn},
-			Writer:            userSpreadsheet.Writer,
-			Reader:            userSpreadsheet.Reader,
+			SpreadsheetWriter: userSpreadsheet.Writer,
+			SpreadsheetReader: userSpreadsheet.Reader,
 		},
 		GetDeviceByIdUseCase: &usecase.GetDeviceByIdUseCase{
 			DeviceRepository: deviceRepository,
 		},
 		GetDeviceListUseCase: &usecase.GetDeviceListUseCase{
-			DeviceRepository: deviceRepository,
+			DeviceRepository:          deviceRepository,
+			DeviceHeartbeatRepository: &repository.DeviceHeartbeatRepository{DBConn: dbConn},
+			PresenceThresholds:        usecase.DevicePresenceThresholds(config.DevicePresence),
 		},
 		UpdateDeviceUseCase: &usecase.UpdateDeviceUseCase{
 			DeviceRepository: deviceRepository,
@@ -82,16 +64,17 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 			DeviceRepository:       deviceRepository,
 			UserEntityRepository:   &userEntityRepository,
 			CodeCountingRepository: repository.NewCodeCountingRepository(),
-			Writer:                 userSpreadsheet.Writer,
-			Reader:                 userSpreadsheet.Reader,
+			SpreadsheetWriter:      userSpreadsheet.Writer,
+			SpreadsheetReader:      userSpreadsheet.Reader,
 			OutputSpreadsheetId:    config.Google.SpreadsheetId,
-			DriveService:           driveService,
 			FirebaseApp:            fcm,
 			DB:                     dbConn,
+			FormRevisionUseCase:    usecase.NewFormRevisionUseCase(dbConn),
 		},
 		RefreshAccessTokenUseCase: &usecase.RefreshAccessTokenUseCase{
 			SessionRepository: &sessionRepository,
 			DeviceRepository:  deviceRepository,
+			SessionUseCase:    usecase.NewSessionUseCase(dbConn, &sessionRepository),
 		},
 		DiscoverUseCase: &usecase.DiscoverUseCase{
 			DeviceRepository: deviceRepository,
@@ -119,6 +102,11 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 		GetUserDeviceUseCase: &usecase.GetUserDeviceUseCase{
 			UserEntityRepository: &userEntityRepository,
 		},
+		DevicePresenceUseCase: usecase.NewDevicePresenceUseCase(dbConn, config.DevicePresence),
+		GetDeviceStatusUseCase: &usecase.GetDeviceStatusUseCase{
+			DeviceRepository:           deviceRepository,
+			DeviceConfigurationUseCase: usecase.NewDeviceConfigurationUseCase(dbConn),
+		},
 	}
 
 	provider := uploader.NewS3Provider(
@@ -144,9 +132,10 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 			ImageRepository: &repository.ImageRepository{DBConn: dbConn},
 			UploadProvider:  provider,
 		},
+		PermissionRepository: &repository.PermissionRepository{DBConn: dbConn},
 	}
 
-	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository}
+	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository, PermissionRepository: &repository.PermissionRepository{DBConn: dbConn}}
 
 	v1 := engine.Group("v1/device")
 	{
@@ -154,14 +143,36 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 		v1.GET("/user/:user_id", deviceController.GetAllDeviceByUserId)
 		// Init for first setting
 		v1.POST("/init", secureMiddleware.Secured(), deviceController.InitDeviceV1)
+		// Enrollment with a code issued by an admin, without a login
+		deviceEnrollmentController := &controller.DeviceEnrollmentController{
+			DeviceEnrollmentUseCase: usecase.NewDeviceEnrollmentUseCase(dbConn, &sessionRepository),
+		}
+		v1.POST("/enroll", deviceEnrollmentController.Enroll)
 		v1.POST("/refresh-token", deviceController.RefreshAccessToken)
 		v1.POST("/messaging/fcm/register", deviceController.RegisterFCM)
 		v1.PUT("/note", secureMiddleware.Secured(), deviceController.TakeNote)
+		v1.POST("/heartbeat", secureMiddleware.Secured(), deviceController.Heartbeat)
+
+		deviceCommandController := &controller.DeviceCommandController{
+			DeviceCommandUseCase:    usecase.NewDeviceCommandUseCase(dbConn, config.DefaultRequestPageSize, fcm),
+			GetUserFromTokenUseCase: deviceController.GetUserFromTokenUseCase,
+			GetUserDeviceUseCase:    deviceController.GetUserDeviceUseCase,
+		}
+		v1.GET("/:device_id/commands", secureMiddleware.Secured(), deviceCommandController.PullCommands)
+		v1.POST("/command/:id/ack", secureMiddleware.Secured(), deviceCommandController.AcknowledgeCommand)
+		v1.POST("/command/:id/result", secureMiddleware.Secured(), deviceCommandController.ReportResult)
+
+		deviceConfigurationController := &controller.DeviceConfigurationController{
+			DeviceConfigurationUseCase: usecase.NewDeviceConfigurationUseCase(dbConn),
+			GetUserFromTokenUseCase:    deviceController.GetUserFromTokenUseCase,
+			GetUserDeviceUseCase:       deviceController.GetUserDeviceUseCase,
+		}
+		v1.GET("/:device_id/configuration", secureMiddleware.Secured(), deviceConfigurationController.GetDeviceConfiguration)
 		smtpController := &controller.SMTPController{
 			SendEmailUseCase: &usecase.SendEmailUseCase{
 				SMTPConfig:        config.SMTP,
 				SettingRepository: &repository.SettingRepository{DBConn: dbConn},
-				Writer:            userSpreadsheet.Writer,
+				SpreadsheetWriter: userSpreadsheet.Writer,
 			},
 			FindDeviceFromRequestCase: &usecase.FindDeviceFromRequestCase{
 				DeviceRepository:  deviceRepository,
@@ -194,11 +205,16 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 	redirectUrl := engine.Group("v1/redirect-url")
 	{
 		redirectController := &controller.RedirectUrlController{
-			SaveRedirectUrlUseCase:        nil,
-			GetRedirectUrlListUseCase:     nil,
-			DeleteRedirectUrlUseCase:      nil,
-			UpdateRedirectUrlUseCase:      nil,
-			GetRedirectUrlByQRCodeUseCase: &usecase.GetRedirectUrlByQRCodeUseCase{RedirectUrlRepository: &repository.RedirectUrlRepository{DBConn: dbConn}},
+			SaveRedirectUrlUseCase:    nil,
+			GetRedirectUrlListUseCase: nil,
+			DeleteRedirectUrlUseCase:  nil,
+			UpdateRedirectUrlUseCase:  nil,
+			GetRedirectUrlByQRCodeUseCase: &usecase.GetRedirectUrlByQRCodeUseCase{
+				RedirectUrlRepository:     &repository.RedirectUrlRepository{DBConn: dbConn},
+				RedirectUrlScanRepository: &repository.RedirectUrlScanRepository{DBConn: dbConn},
+				SessionRepository:         &sessionRepository,
+				DB:                        dbConn,
+			},
 		}
 		redirectUrl.GET("", redirectController.GetRedirectUrlByQRCode)
 	}
@@ -213,7 +229,7 @@ func setupDeviceRoutes(engine *gin.Engine, dbConn *gorm.DB, userSpreadsheet *she
 		codeCounting.PUT("/reset", deviceController.ResetCodeCounting)
 	}
 
-	image := engine.Group("v1/images", secureMiddleware.Secured())
+	image := engine.Group("v1/images", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_FormWrite))
 	{
 		image.POST("/", imageController.GetUrlByKey)
 		image.POST("/upload", imageController.CreateImage)
@@ -5,19 +5,15 @@ import (
 	"sen-global-api/internal/controller"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/usecase"
+	"sen-global-api/internal/domain/value"
 	"sen-global-api/internal/middleware"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 func setupOrganizationRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfig) {
-	sessionRepository := repository.SessionRepository{
-		AuthorizeEncryptKey: config.AuthorizeEncryptKey,
-
-		TokenExpireTimeInHour: time.Duration(config.TokenExpireDurationInHour),
-	}
+	sessionRepository := usecase.NewSessionRepository(config, dbConn)
 
 	userEntityRepository := repository.UserEntityRepository{DBConn: dbConn}
 
@@ -38,7 +34,15 @@ func setupOrganizationRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.
 		},
 	}
 
-	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository}
+	organizationMembershipController := &controller.OrganizationMembershipController{
+		OrganizationMembershipUseCase: usecase.NewOrganizationMembershipUseCase(dbConn, config.SMTP),
+		AccessControl:                 usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize),
+	}
+
+	secureMiddleware := middleware.SecuredMiddleware{
+		SessionRepository:    sessionRepository,
+		PermissionRepository: &repository.PermissionRepository{DBConn: dbConn},
+	}
 
 	user := engine.Group("/v1/organization")
 	{
@@ -46,6 +50,23 @@ func setupOrganizationRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.
 		user.GET("/:id", secureMiddleware.Secured(), organizationController.GetOrganizationById)
 		user.GET("/:id/users", secureMiddleware.Secured(), organizationController.GetAllUserByOrganization)
 		user.POST("/", secureMiddleware.Secured(), secureMiddleware.ValidateSuperAdminRole(), organizationController.CreateOrganization)
+		// Legacy join with the shared password, until the organization retires it
 		user.POST("/join", secureMiddleware.Secured(), organizationController.UserJoinOrganization)
+		user.DELETE("/:id/password", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.RetirePassword)
+
+		user.GET("/invitation", secureMiddleware.Secured(), organizationMembershipController.PreviewInvitation)
+		user.POST("/invitation/accept", secureMiddleware.Secured(), organizationMembershipController.AcceptInvitation)
+		user.POST("/invitation/decline", secureMiddleware.Secured(), organizationMembershipController.DeclineInvitation)
+
+		user.GET("/:id/invitations", secureMiddleware.RequirePermission(value.Permission_OrganizationRead), organizationMembershipController.GetInvitations)
+		user.POST("/:id/invitations", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.CreateInvitation)
+		user.POST("/:id/invitations/:invitation_id/revoke", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.RevokeInvitation)
+		user.GET("/:id/invitations/:invitation_id/replies", secureMiddleware.RequirePermission(value.Permission_OrganizationRead), organizationMembershipController.GetReplies)
+
+		user.GET("/:id/members", secureMiddleware.RequirePermission(value.Permission_OrganizationRead), organizationMembershipController.GetMembers)
+		user.POST("/:id/members/:user_id/suspend", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.SuspendMember)
+		user.POST("/:id/members/:user_id/reinstate", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.ReinstateMember)
+		user.DELETE("/:id/members/:user_id", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.RemoveMember)
+		user.POST("/:id/owner", secureMiddleware.RequirePermission(value.Permission_OrganizationWrite), organizationMembershipController.TransferOwnership)
 	}
 }
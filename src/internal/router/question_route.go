@@ -8,20 +8,15 @@ import (
 	"sen-global-api/internal/domain/usecase"
 	"sen-global-api/internal/middleware"
 	"sen-global-api/pkg/sheet"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 func setupQuestionRoutes(engine *gin.Engine, conn *gorm.DB, config config.AppConfig) {
-	sessionRepository := repository.SessionRepository{
-		AuthorizeEncryptKey: config.AuthorizeEncryptKey,
-
-		TokenExpireTimeInHour: time.Duration(config.TokenExpireDurationInHour),
-	}
+	sessionRepository := usecase.NewSessionRepository(config, conn)
 	userEntityRepository := repository.UserEntityRepository{DBConn: conn}
-	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository}
+	secureMiddleware := middleware.SecuredMiddleware{SessionRepository: sessionRepository, PermissionRepository: &repository.PermissionRepository{DBConn: conn}}
 	questionRepository := repository.QuestionRepository{DBConn: conn}
 	formRepo := &repository.FormRepository{DBConn: conn, DefaultRequestPageSize: config.DefaultRequestPageSize}
 	ctx := context.Background()
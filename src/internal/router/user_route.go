@@ -5,18 +5,20 @@ import (
 	"sen-global-api/internal/controller"
 	"sen-global-api/internal/data/repository"
 	"sen-global-api/internal/domain/usecase"
-	"time"
+	"sen-global-api/internal/domain/value"
+	"sen-global-api/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfig) {
-	sessionRepository := repository.SessionRepository{
-		AuthorizeEncryptKey: config.AuthorizeEncryptKey,
-
-		TokenExpireTimeInHour: time.Duration(config.TokenExpireDurationInHour),
+	sessionRepository := usecase.NewSessionRepository(config, dbConn)
+	secureMiddleware := middleware.SecuredMiddleware{
+		SessionRepository:    sessionRepository,
+		PermissionRepository: &repository.PermissionRepository{DBConn: dbConn},
 	}
+	accessControl := usecase.NewAccessControlUseCase(dbConn, config.DefaultRequestPageSize)
 
 	userEntityController := &controller.UserEntityController{
 		GetUserEntityUseCase: &usecase.GetUserEntityUseCase{
@@ -28,11 +30,15 @@ func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfi
 		UpdateUserEntityUseCase: &usecase.UpdateUserEntityUseCase{
 			UserEntityRepository: &repository.UserEntityRepository{DBConn: dbConn},
 		},
+		UpdateUserRoleUseCase: &usecase.UpdateUserRoleUseCase{
+			UserEntityRepository: &repository.UserEntityRepository{DBConn: dbConn},
+		},
 		AuthorizeUseCase: &usecase.AuthorizeUseCase{
 			UserEntityRepository: &repository.UserEntityRepository{DBConn: dbConn},
 			DeviceRepository:     &repository.DeviceRepository{DBConn: dbConn},
 			SessionRepository:    sessionRepository,
 		},
+		AccessControl: accessControl,
 	}
 
 	userRoleController := &controller.RoleController{
@@ -48,6 +54,7 @@ func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfi
 		DeleteRoleUseCase: &usecase.DeleteRoleUseCase{
 			RoleRepository: &repository.RoleRepository{DBConn: dbConn},
 		},
+		AccessControl: accessControl,
 	}
 
 	roleClaimController := &controller.RoleClaimController{
@@ -63,6 +70,7 @@ func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfi
 		DeleteRoleClaimUseCase: &usecase.DeleteRoleClaimUseCase{
 			RoleClaimRepository: &repository.RoleClaimRepository{DBConn: dbConn},
 		},
+		AccessControl: accessControl,
 	}
 
 	roleClaimPermissionController := &controller.RoleClaimPermissionController{
@@ -78,6 +86,17 @@ func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfi
 		DeleteRoleClaimPermissionUseCase: &usecase.DeleteRoleClaimPermissionUseCase{
 			RoleClaimPermissionRepository: &repository.RoleClaimPermissionRepository{DBConn: dbConn},
 		},
+		AccessControl: accessControl,
+	}
+
+	sessionController := &controller.SessionController{
+		SessionUseCase: usecase.NewSessionUseCase(dbConn, &sessionRepository),
+		AccessControl:  accessControl,
+	}
+
+	guardianController := &controller.GuardianController{
+		GuardianUseCase: usecase.NewGuardianUseCase(dbConn, config.DefaultRequestPageSize),
+		AccessControl:   accessControl,
 	}
 
 	userAccess := engine.Group("v1/")
@@ -90,54 +109,76 @@ func setupUserRoutes(engine *gin.Engine, dbConn *gorm.DB, config config.AppConfi
 			},
 		}
 		userAccess.POST("/login", loginController.UserLogin)
+		userAccess.POST("/logout", secureMiddleware.Secured(), sessionController.Logout)
+		userAccess.POST("/logout/all", secureMiddleware.Secured(), sessionController.LogoutEverywhere)
 	}
 
+	jwksController := &controller.JWKSController{KeySet: sessionRepository.KeySet}
+	engine.GET("/.well-known/jwks.json", jwksController.GetJWKS)
+
 	user := engine.Group("v1/user")
 	{
-		user.GET("/all", userEntityController.GetAllUserEntity)
-		user.GET("/:id", userEntityController.GetUserEntityById)
-		user.GET("/name/:username", userEntityController.GetUserEntityByName)
-		user.GET("/:id/children", userEntityController.GetChildrenOfGuardian)
-
-		user.POST("/init", userEntityController.CreateUserEntity)
-		user.POST("/update", userEntityController.UpdateUserEntity)
-		user.POST("/role/update", userEntityController.UpdateUserRole)
+		user.GET("/all", secureMiddleware.RequirePermission(value.Permission_UserRead), userEntityController.GetAllUserEntity)
+		user.GET("/:id", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserRead), userEntityController.GetUserEntityById)
+		user.GET("/name/:username", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserRead), userEntityController.GetUserEntityByName)
+		user.GET("/:id/children", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserRead), userEntityController.GetChildrenOfGuardian)
+		user.GET("/:id/guardians", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserRead), guardianController.GetGuardians)
+		user.POST("/:id/guardians", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserWrite), guardianController.InviteGuardian)
+		user.POST("/:id/guardians/:guardian_id/detach", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserWrite), guardianController.DetachGuardian)
+		user.GET("/:id/sessions", secureMiddleware.RequirePermission(value.Permission_UserRead), sessionController.GetUserSessions)
+		user.DELETE("/:id/sessions", secureMiddleware.RequirePermission(value.Permission_UserWrite), sessionController.RevokeUserSessions)
+		user.DELETE("/:id/sessions/:session_id", secureMiddleware.RequirePermission(value.Permission_UserWrite), sessionController.RevokeUserSession)
+
+		user.POST("/init", secureMiddleware.OptionalPermission(value.Permission_UserWrite), userEntityController.CreateUserEntity)
+		user.POST("/update", secureMiddleware.Secured(), secureMiddleware.OptionalPermission(value.Permission_UserWrite), userEntityController.UpdateUserEntity)
+		user.POST("/role/update", secureMiddleware.RequirePermission(value.Permission_UserWrite), userEntityController.UpdateUserRole)
+	}
+
+	guardian := engine.Group("v1/guardian")
+	{
+		guardian.GET("/links", secureMiddleware.Secured(), guardianController.GetLinks)
+		guardian.POST("/links/:id/confirm", secureMiddleware.Secured(), guardianController.ConfirmLink)
+		guardian.POST("/links/:id/decline", secureMiddleware.Secured(), guardianController.DeclineLink)
+		guardian.GET("/children", secureMiddleware.Secured(), guardianController.GetChildren)
+		guardian.GET("/submissions", secureMiddleware.Secured(), guardianController.GetSubmissions)
+		guardian.GET("/todo-completions", secureMiddleware.Secured(), guardianController.GetToDoCompletions)
+		guardian.GET("/devices", secureMiddleware.Secured(), guardianController.GetDevices)
 	}
 
 	userRole := engine.Group("v1/user-role")
 	{
-		userRole.GET("/:organization_id/all", userRoleController.GetAllRoleByOrganization)
-		userRole.GET("/:id", userRoleController.GetRoleById)
-		userRole.GET("/name/:role_name", userRoleController.GetRoleByName)
+		userRole.GET("/:organization_id/all", secureMiddleware.RequirePermission(value.Permission_RoleRead), userRoleController.GetAllRoleByOrganization)
+		userRole.GET("/:id", secureMiddleware.RequirePermission(value.Permission_RoleRead), userRoleController.GetRoleById)
+		userRole.GET("/name/:role_name", secureMiddleware.RequirePermission(value.Permission_RoleRead), userRoleController.GetRoleByName)
 
-		userRole.POST("/init", userRoleController.CreateRole)
-		userRole.POST("/", userRoleController.UpdateRole)
+		userRole.POST("/init", secureMiddleware.RequirePermission(value.Permission_RoleWrite), userRoleController.CreateRole)
+		userRole.POST("/", secureMiddleware.RequirePermission(value.Permission_RoleWrite), userRoleController.UpdateRole)
 
-		userRole.DELETE("/:id", userRoleController.DeleteRole)
+		userRole.DELETE("/:id", secureMiddleware.RequirePermission(value.Permission_RoleWrite), userRoleController.DeleteRole)
 	}
 
 	roleClaim := engine.Group("v1/role-claim")
 	{
-		roleClaim.GET("/all", roleClaimController.GetAllRoleClaim)
-		roleClaim.GET("/all/:role_id", roleClaimController.GetAllRoleClaimByRole)
-		roleClaim.GET("/:id", roleClaimController.GetRoleClaimById)
-		roleClaim.GET("/name/:claim_name", roleClaimController.GetRoleClaimByName)
+		roleClaim.GET("/all", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimController.GetAllRoleClaim)
+		roleClaim.GET("/all/:role_id", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimController.GetAllRoleClaimByRole)
+		roleClaim.GET("/:id", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimController.GetRoleClaimById)
+		roleClaim.GET("/name/:claim_name", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimController.GetRoleClaimByName)
 
-		roleClaim.POST("/init", roleClaimController.CreateRoleClaim)
-		roleClaim.POST("/", roleClaimController.UpdateRoleClaim)
+		roleClaim.POST("/init", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimController.CreateRoleClaim)
+		roleClaim.POST("/", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimController.UpdateRoleClaim)
 
-		roleClaim.DELETE("/:id", roleClaimController.DeleteRoleClaim)
+		roleClaim.DELETE("/:id", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimController.DeleteRoleClaim)
 	}
 
 	roleClaimPermission := engine.Group("v1/role-policy")
 	{
-		roleClaimPermission.GET("/all", roleClaimPermissionController.GetAllRoleClaimPermission)
-		roleClaimPermission.GET("/:id", roleClaimPermissionController.GetRoleClaimPermissionById)
-		roleClaimPermission.GET("/name/:policy_name", roleClaimPermissionController.GetRoleClaimPermissionByName)
+		roleClaimPermission.GET("/all", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimPermissionController.GetAllRoleClaimPermission)
+		roleClaimPermission.GET("/:id", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimPermissionController.GetRoleClaimPermissionById)
+		roleClaimPermission.GET("/name/:policy_name", secureMiddleware.RequirePermission(value.Permission_RoleRead), roleClaimPermissionController.GetRoleClaimPermissionByName)
 
-		roleClaimPermission.POST("/init", roleClaimPermissionController.CreateRoleClaimPermission)
-		roleClaimPermission.POST("/", roleClaimPermissionController.UpdateRoleClaimPermission)
+		roleClaimPermission.POST("/init", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimPermissionController.CreateRoleClaimPermission)
+		roleClaimPermission.POST("/", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimPermissionController.UpdateRoleClaimPermission)
 
-		roleClaimPermission.DELETE("/:id", roleClaimPermissionController.DeleteRoleClaimPermission)
+		roleClaimPermission.DELETE("/:id", secureMiddleware.RequirePermission(value.Permission_RoleWrite), roleClaimPermissionController.DeleteRoleClaimPermission)
 	}
 }
@@ -25,6 +25,12 @@ func New() *TimeMachine {
 		instantiated.todoExecutors = make([]IntervalTaskExecutor, 0)
 		instantiated.googleQPIRequestMonitor = make([]IntervalTaskExecutor, 0)
 		instantiated.submissionSyncExecutors = make([]IntervalTaskExecutor, 0)
+		instantiated.webhookExecutors = make([]WebhookDeliveryExecutor, 0)
+		instantiated.devicePresenceExecutors = make([]DevicePresenceExecutor, 0)
+		instantiated.deviceCommandExecutors = make([]DeviceCommandExecutor, 0)
+		instantiated.deviceScheduleExecutors = make([]DeviceScheduleExecutor, 0)
+		instantiated.todoSheetSyncExecutors = make([]ToDoSheetSyncExecutor, 0)
+		instantiated.todoReminderExecutors = make([]ToDoReminderExecutor, 0)
 		instantiated.formCron = gocron.NewScheduler(time.UTC)
 		instantiated.form2Cron = gocron.NewScheduler(time.UTC)
 		instantiated.form3Cron = gocron.NewScheduler(time.UTC)
@@ -34,6 +40,12 @@ func New() *TimeMachine {
 		instantiated.todoCron = gocron.NewScheduler(time.UTC)
 		instantiated.googleQPIRequestMonitorCron = gocron.NewScheduler(time.UTC)
 		instantiated.submissionSyncCron = gocron.NewScheduler(time.UTC)
+		instantiated.webhookCron = gocron.NewScheduler(time.UTC)
+		instantiated.devicePresenceCron = gocron.NewScheduler(time.UTC)
+		instantiated.deviceCommandCron = gocron.NewScheduler(time.UTC)
+		instantiated.deviceScheduleCron = gocron.NewScheduler(time.UTC)
+		instantiated.todoSheetSyncCron = gocron.NewScheduler(time.UTC)
+		instantiated.todoReminderCron = gocron.NewScheduler(time.UTC)
 	})
 	return instantiated
 }
@@ -48,6 +60,12 @@ type TimeMachine struct {
 	todoExecutors               []IntervalTaskExecutor
 	googleQPIRequestMonitor     []IntervalTaskExecutor
 	submissionSyncExecutors     []IntervalTaskExecutor
+	webhookExecutors            []WebhookDeliveryExecutor
+	devicePresenceExecutors     []DevicePresenceExecutor
+	deviceCommandExecutors      []DeviceCommandExecutor
+	deviceScheduleExecutors     []DeviceScheduleExecutor
+	todoSheetSyncExecutors      []ToDoSheetSyncExecutor
+	todoReminderExecutors       []ToDoReminderExecutor
 	formCron                    *gocron.Scheduler
 	form2Cron                   *gocron.Scheduler
 	form3Cron                   *gocron.Scheduler
@@ -57,6 +75,12 @@ type TimeMachine struct {
 	todoCron                    *gocron.Scheduler
 	googleQPIRequestMonitorCron *gocron.Scheduler
 	submissionSyncCron          *gocron.Scheduler
+	webhookCron                 *gocron.Scheduler
+	devicePresenceCron          *gocron.Scheduler
+	deviceCommandCron           *gocron.Scheduler
+	deviceScheduleCron          *gocron.Scheduler
+	todoSheetSyncCron           *gocron.Scheduler
+	todoReminderCron            *gocron.Scheduler
 }
 
 type IntervalTaskExecutor interface {
@@ -69,6 +93,51 @@ type IntervalTaskExecutor interface {
 	ExecuteGoogleAPIRequestMonitor()
 }
 
+// WebhookDeliveryExecutor sends the webhook deliveries that are due for a retry
+type WebhookDeliveryExecutor interface {
+	ExecuteWebhookDeliveries()
+}
+
+// webhookDeliveryInterval is how often due webhook deliveries are retried, in seconds
+const webhookDeliveryInterval = 30
+
+// DevicePresenceExecutor checks for the devices that stopped sending heartbeats
+type DevicePresenceExecutor interface {
+	ExecuteDevicePresenceCheck()
+}
+
+// devicePresenceInterval is how often the heartbeats of the devices are checked, in minutes
+const devicePresenceInterval = 1
+
+// DeviceCommandExecutor expires the device commands left open past their expiry
+type DeviceCommandExecutor interface {
+	ExecuteDeviceCommandExpiry()
+}
+
+// deviceCommandExpiryInterval is how often device commands are expired, in minutes
+const deviceCommandExpiryInterval = 1
+
+// DeviceScheduleExecutor applies the device mode schedules and overrides
+type DeviceScheduleExecutor interface {
+	ExecuteDeviceSchedules()
+}
+
+// ToDoSheetSyncExecutor mirrors the to-do changes that are due to the spreadsheets
+type ToDoSheetSyncExecutor interface {
+	ExecuteToDoSheetSyncs()
+}
+
+// todoSheetSyncInterval is how often due to-do sheet syncs are retried, in seconds
+const todoSheetSyncInterval = 30
+
+// ToDoReminderExecutor sends the reminders of the to-do tasks that are due
+type ToDoReminderExecutor interface {
+	ExecuteToDoReminders()
+}
+
+// todoReminderInterval is how often the reminders of the to-do tasks are sent, in minutes
+const todoReminderInterval = 1
+
 func (receiver *TimeMachine) Start(formInterval uint64, urlInterval uint64, todoInterval uint64, formInterval2 uint64, formInterval3 uint64, formInterval4 uint64) {
 	receiver.ScheduleSyncForms(formInterval)
 	receiver.ScheduleSyncForms2(formInterval2)
@@ -77,6 +146,12 @@ func (receiver *TimeMachine) Start(formInterval uint64, urlInterval uint64, todo
 	receiver.ScheduleSyncUrls(urlInterval)
 	receiver.ScheduleSyncToDos(todoInterval)
 	receiver.ScheduleGoogleAPIRequestMonitor()
+	receiver.ScheduleWebhookDeliveries()
+	receiver.ScheduleDevicePresenceCheck()
+	receiver.ScheduleDeviceCommandExpiry()
+	receiver.ScheduleDeviceSchedules()
+	receiver.ScheduleToDoSheetSyncs()
+	receiver.ScheduleToDoReminders()
 
 	monitor.SendMessageViaTelegram("Time machine started with ",
 		fmt.Sprint("formInterval: ", formInterval),
@@ -98,6 +173,12 @@ func (receiver *TimeMachine) Stop() {
 	receiver.todoCron.Clear()
 	receiver.googleQPIRequestMonitorCron.Clear()
 	receiver.submissionSyncCron.Clear()
+	receiver.webhookCron.Clear()
+	receiver.devicePresenceCron.Clear()
+	receiver.deviceCommandCron.Clear()
+	receiver.deviceScheduleCron.Clear()
+	receiver.todoSheetSyncCron.Clear()
+	receiver.todoReminderCron.Clear()
 
 	monitor.SendMessageViaTelegram("Time machine has been stopped")
 }
@@ -137,6 +218,36 @@ func (receiver *TimeMachine) SubscribeSyncToDosExec(exec IntervalTaskExecutor) {
 	log.Debug("Subscribe todo sync exec", receiver.todoExecutors)
 }
 
+func (receiver *TimeMachine) SubscribeWebhookDeliveriesExec(exec WebhookDeliveryExecutor) {
+	receiver.webhookExecutors = append(receiver.webhookExecutors, exec)
+	log.Debug("Subscribe webhook delivery executor", receiver.webhookExecutors)
+}
+
+func (receiver *TimeMachine) SubscribeDevicePresenceExec(exec DevicePresenceExecutor) {
+	receiver.devicePresenceExecutors = append(receiver.devicePresenceExecutors, exec)
+	log.Debug("Subscribe device presence executor", receiver.devicePresenceExecutors)
+}
+
+func (receiver *TimeMachine) SubscribeDeviceCommandExec(exec DeviceCommandExecutor) {
+	receiver.deviceCommandExecutors = append(receiver.deviceCommandExecutors, exec)
+	log.Debug("Subscribe device command executor", receiver.deviceCommandExecutors)
+}
+
+func (receiver *TimeMachine) SubscribeDeviceScheduleExec(exec DeviceScheduleExecutor) {
+	receiver.deviceScheduleExecutors = append(receiver.deviceScheduleExecutors, exec)
+	log.Debug("Subscribe device schedule executor", receiver.deviceScheduleExecutors)
+}
+
+func (receiver *TimeMachine) SubscribeToDoSheetSyncExec(exec ToDoSheetSyncExecutor) {
+	receiver.todoSheetSyncExecutors = append(receiver.todoSheetSyncExecutors, exec)
+	log.Debug("Subscribe todo sheet sync executor", receiver.todoSheetSyncExecutors)
+}
+
+func (receiver *TimeMachine) SubscribeToDoReminderExec(exec ToDoReminderExecutor) {
+	receiver.todoReminderExecutors = append(receiver.todoReminderExecutors, exec)
+	log.Debug("Subscribe todo reminder executor", receiver.todoReminderExecutors)
+}
+
 func (receiver *TimeMachine) SubscribeGoogleAPIRequestMonitorExec(exec IntervalTaskExecutor) {
 	receiver.googleQPIRequestMonitor = append(receiver.googleQPIRequestMonitor, exec)
 	log.Debug("Subscribe google api request monitor exec", receiver.googleQPIRequestMonitor)
@@ -320,3 +431,148 @@ func (receiver *TimeMachine) ScheduleGoogleAPIRequestMonitor() {
 	}
 	receiver.googleQPIRequestMonitorCron.StartAsync()
 }
+
+func (receiver *TimeMachine) ScheduleWebhookDeliveries() {
+	receiver.webhookCron.Clear()
+	receiver.webhookCron.SingletonModeAll()
+
+	now := time.Now()
+	startAt := now.Add(time.Duration(webhookDeliveryInterval) * time.Second)
+	task, err := receiver.webhookCron.Every(webhookDeliveryInterval).Seconds().StartAt(startAt).Do(func() {
+		log.Debug("Retry webhook deliveries")
+		for _, executor := range receiver.webhookExecutors {
+			executor.ExecuteWebhookDeliveries()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule webhook deliveries every ", webhookDeliveryInterval, " seconds [ERROR]? ", task.Error())
+	}
+	receiver.webhookCron.StartAsync()
+}
+
+func (receiver *TimeMachine) ScheduleDevicePresenceCheck() {
+	receiver.devicePresenceCron.Clear()
+	receiver.devicePresenceCron.SingletonModeAll()
+
+	now := time.Now()
+	startAt := now.Add(time.Duration(devicePresenceInterval) * time.Minute)
+	task, err := receiver.devicePresenceCron.Every(devicePresenceInterval).Minutes().StartAt(startAt).Do(func() {
+		log.Debug("Check device presence")
+		for _, executor := range receiver.devicePresenceExecutors {
+			executor.ExecuteDevicePresenceCheck()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule device presence check every ", devicePresenceInterval, " minutes [ERROR]? ", task.Error())
+	}
+	receiver.devicePresenceCron.StartAsync()
+}
+
+func (receiver *TimeMachine) ScheduleDeviceCommandExpiry() {
+	receiver.deviceCommandCron.Clear()
+	receiver.deviceCommandCron.SingletonModeAll()
+
+	now := time.Now()
+	startAt := now.Add(time.Duration(deviceCommandExpiryInterval) * time.Minute)
+	task, err := receiver.deviceCommandCron.Every(deviceCommandExpiryInterval).Minutes().StartAt(startAt).Do(func() {
+		log.Debug("Expire device commands")
+		for _, executor := range receiver.deviceCommandExecutors {
+			executor.ExecuteDeviceCommandExpiry()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule device command expiry every ", deviceCommandExpiryInterval, " minutes [ERROR]? ", task.Error())
+	}
+	receiver.deviceCommandCron.StartAsync()
+}
+
+// ScheduleDeviceSchedules applies the device mode schedules at the start of every
+// minute, so that a window starting at 20:00 applies at 20:00
+func (receiver *TimeMachine) ScheduleDeviceSchedules() {
+	receiver.deviceScheduleCron.Clear()
+	receiver.deviceScheduleCron.SingletonModeAll()
+
+	startAt := time.Now().Truncate(time.Minute).Add(time.Minute)
+	task, err := receiver.deviceScheduleCron.Every(1).Minutes().StartAt(startAt).Do(func() {
+		log.Debug("Apply device schedules")
+		for _, executor := range receiver.deviceScheduleExecutors {
+			executor.ExecuteDeviceSchedules()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule device schedules every minute [ERROR]? ", task.Error())
+	}
+	receiver.deviceScheduleCron.StartAsync()
+}
+
+func (receiver *TimeMachine) ScheduleToDoSheetSyncs() {
+	receiver.todoSheetSyncCron.Clear()
+	receiver.todoSheetSyncCron.SingletonModeAll()
+
+	now := time.Now()
+	startAt := now.Add(time.Duration(todoSheetSyncInterval) * time.Second)
+	task, err := receiver.todoSheetSyncCron.Every(todoSheetSyncInterval).Seconds().StartAt(startAt).Do(func() {
+		log.Debug("Retry todo sheet syncs")
+		for _, executor := range receiver.todoSheetSyncExecutors {
+			executor.ExecuteToDoSheetSyncs()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule todo sheet syncs every ", todoSheetSyncInterval, " seconds [ERROR]? ", task.Error())
+	}
+	receiver.todoSheetSyncCron.StartAsync()
+}
+
+func (receiver *TimeMachine) ScheduleToDoReminders() {
+	receiver.todoReminderCron.Clear()
+	receiver.todoReminderCron.SingletonModeAll()
+
+	now := time.Now()
+	startAt := now.Add(time.Duration(todoReminderInterval) * time.Minute)
+	task, err := receiver.todoReminderCron.Every(todoReminderInterval).Minutes().StartAt(startAt).Do(func() {
+		log.Debug("Send todo reminders")
+		for _, executor := range receiver.todoReminderExecutors {
+			executor.ExecuteToDoReminders()
+		}
+	})
+	if err != nil {
+		log.Error(err)
+		panic(err)
+	} else if task.Error() != nil {
+		log.Error(task.Error())
+		panic(task.Error())
+	} else if task != nil && task.Error() == nil {
+		log.Info("Schedule todo reminders every ", todoReminderInterval, " minutes [ERROR]? ", task.Error())
+	}
+	receiver.todoReminderCron.StartAsync()
+}
@@ -0,0 +1,268 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Key is one key of a KeySet, PrivateKey is nil for the keys that are only kept
+// to verify the tokens they signed before being retired
+type Key struct {
+	Id         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	ModTime    time.Time
+}
+
+// KeySet holds the keys tokens are signed and verified with. Every key of the
+// set verifies tokens, the signing key signs the new ones.
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Load reads the PEM keys of a directory, the name of a file without ".pem" is
+// the id of its key. A private key signs and verifies, a public key saved as
+// "<id>.pub.pem" only verifies. RSA keys sign with RS256 and EC keys with ES256,
+// ES384 or ES512 depending on their curve. The signing key is signingKeyId, or
+// the most recent private key when it is empty. Load returns nil without error
+// when the directory does not exist.
+func Load(directory string, signingKeyId string) (*KeySet, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	keySet := &KeySet{keys: make(map[string]*Key)}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := loadKey(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		key.Id = strings.TrimSuffix(strings.TrimSuffix(entry.Name(), ".pem"), ".pub")
+		key.ModTime = info.ModTime()
+
+		if existing, ok := keySet.keys[key.Id]; ok && existing.PrivateKey != nil {
+			continue
+		}
+		keySet.keys[key.Id] = key
+	}
+	if len(keySet.keys) == 0 {
+		return nil, nil
+	}
+
+	if signingKeyId != "" {
+		key, ok := keySet.keys[signingKeyId]
+		if !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("no private key %s in %s", signingKeyId, directory)
+		}
+		keySet.signing = key
+		return keySet, nil
+	}
+
+	for _, key := range keySet.sortedKeys() {
+		if key.PrivateKey != nil {
+			keySet.signing = key
+			break
+		}
+	}
+	if keySet.signing == nil {
+		return nil, fmt.Errorf("no private key in %s", directory)
+	}
+
+	return keySet, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	switch value := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.PrivateKey = value
+		key.PublicKey = &value.PublicKey
+	case *ecdsa.PrivateKey:
+		key.PrivateKey = value
+		key.PublicKey = &value.PublicKey
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		key.PublicKey = value
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	key.Method, err = signingMethod(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func signingMethod(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch value := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch value.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, errors.New("unsupported elliptic curve")
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T", publicKey)
+}
+
+// sortedKeys returns the keys newest first, by id for keys of the same time
+func (receiver *KeySet) sortedKeys() []*Key {
+	keys := make([]*Key, 0, len(receiver.keys))
+	for _, key := range receiver.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].ModTime.Equal(keys[j].ModTime) {
+			return keys[i].ModTime.After(keys[j].ModTime)
+		}
+		return keys[i].Id > keys[j].Id
+	})
+
+	return keys
+}
+
+func (receiver *KeySet) SigningKeyId() string {
+	return receiver.signing.Id
+}
+
+// Sign signs the claims with the signing key and sets its id as the kid header
+func (receiver *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(receiver.signing.Method, claims)
+	token.Header["kid"] = receiver.signing.Id
+
+	return token.SignedString(receiver.signing.PrivateKey)
+}
+
+// Keyfunc returns the public key of the kid header of a token, for jwt.Parse. The
+// algorithm of the token has to be the algorithm of the key.
+func (receiver *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key, ok := receiver.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %s", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public keys of the set as a JSON Web Key Set (RFC 7517), the
+// signing key first
+func (receiver *KeySet) JWKS() JSONWebKeySet {
+	keys := receiver.sortedKeys()
+	jwks := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+	jwks.Keys = append(jwks.Keys, jsonWebKey(receiver.signing))
+	for _, key := range keys {
+		if key != receiver.signing {
+			jwks.Keys = append(jwks.Keys, jsonWebKey(key))
+		}
+	}
+
+	return jwks
+}
+
+func jsonWebKey(key *Key) JSONWebKey {
+	jwk := JSONWebKey{Kid: key.Id, Use: "sig", Alg: key.Method.Alg()}
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = publicKey.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	}
+
+	return jwk
+}
@@ -0,0 +1,271 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ecKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+)
+
+// writeKey saves the PEM block as name in the directory, modified at the given
+// minute so that the newest key is known
+func writeKey(t *testing.T, directory string, name string, blockType string, der []byte, minute int) {
+	t.Helper()
+
+	path := filepath.Join(directory, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile returned %v", err)
+	}
+	modTime := time.Date(2026, time.October, 18, 12, minute, 0, 0, time.UTC)
+	if err = os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes returned %v", err)
+	}
+}
+
+func writeECKey(t *testing.T, directory string, id string, minute int) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey returned %v", err)
+	}
+	writeKey(t, directory, id+".pem", "PRIVATE KEY", der, minute)
+}
+
+func writeRSAKey(t *testing.T, directory string, id string, minute int) {
+	t.Helper()
+
+	writeKey(t, directory, id+".pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), minute)
+}
+
+func writeRSAPublicKey(t *testing.T, directory string, id string, minute int) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey returned %v", err)
+	}
+	writeKey(t, directory, id+".pub.pem", "PUBLIC KEY", der, minute)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         func(t *testing.T, directory string)
+		signingKeyId string
+		wantSigning  string
+		wantKeys     int
+		wantErr      bool
+	}{
+		{
+			name:        "no keys",
+			keys:        func(t *testing.T, directory string) {},
+			wantSigning: "",
+		},
+		{
+			name: "newest private key signs",
+			keys: func(t *testing.T, directory string) {
+				writeRSAKey(t, directory, "2026-04", 1)
+				writeECKey(t, directory, "2026-10", 2)
+				writeRSAPublicKey(t, directory, "2027-01", 3)
+			},
+			wantSigning: "2026-10",
+			wantKeys:    3,
+		},
+		{
+			name: "configured signing key",
+			keys: func(t *testing.T, directory string) {
+				writeRSAKey(t, directory, "2026-04", 1)
+				writeECKey(t, directory, "2026-10", 2)
+			},
+			signingKeyId: "2026-04",
+			wantSigning:  "2026-04",
+			wantKeys:     2,
+		},
+		{
+			name: "private key kept over its public key",
+			keys: func(t *testing.T, directory string) {
+				writeRSAKey(t, directory, "2026-04", 1)
+				writeRSAPublicKey(t, directory, "2026-04", 2)
+			},
+			wantSigning: "2026-04",
+			wantKeys:    1,
+		},
+		{
+			name: "configured signing key without a private key",
+			keys: func(t *testing.T, directory string) {
+				writeECKey(t, directory, "2026-10", 2)
+				writeRSAPublicKey(t, directory, "2026-04", 1)
+			},
+			signingKeyId: "2026-04",
+			wantErr:      true,
+		},
+		{
+			name: "only public keys",
+			keys: func(t *testing.T, directory string) {
+				writeRSAPublicKey(t, directory, "2026-04", 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not a PEM file",
+			keys: func(t *testing.T, directory string) {
+				if err := os.WriteFile(filepath.Join(directory, "broken.pem"), []byte("key"), 0600); err != nil {
+					t.Fatalf("WriteFile returned %v", err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			test.keys(t, directory)
+
+			keySet, err := Load(directory, test.signingKeyId)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("Load accepted the keys")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load returned %v", err)
+			}
+			if test.wantSigning == "" {
+				if keySet != nil {
+					t.Errorf("Load = %+v, want no key set", keySet)
+				}
+				return
+			}
+			if keySet.SigningKeyId() != test.wantSigning || len(keySet.keys) != test.wantKeys {
+				t.Errorf("Load signs with %s out of %d keys, want %s out of %d", keySet.SigningKeyId(), len(keySet.keys), test.wantSigning, test.wantKeys)
+			}
+		})
+	}
+
+	if keySet, err := Load(filepath.Join(t.TempDir(), "missing"), ""); keySet != nil || err != nil {
+		t.Errorf("Load of a missing directory = %+v, %v, want nil, nil", keySet, err)
+	}
+}
+
+func TestKeyfunc(t *testing.T) {
+	directory := t.TempDir()
+	writeRSAKey(t, directory, "rsa", 1)
+	writeECKey(t, directory, "ec", 2)
+	keySet, err := Load(directory, "")
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid interface{}, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"user_id": "user"})
+		if kid != nil {
+			token.Header["kid"] = kid
+		}
+		tokenString, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString returned %v", err)
+		}
+		return tokenString
+	}
+	signed, err := keySet.Sign(jwt.MapClaims{"user_id": "user"})
+	if err != nil {
+		t.Fatalf("Sign returned %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"signed by the set", signed, true},
+		{"EC key of its kid", sign(jwt.SigningMethodES256, "ec", ecKey), true},
+		{"RSA key of its kid", sign(jwt.SigningMethodRS256, "rsa", rsaKey), true},
+		{"no kid", sign(jwt.SigningMethodES256, nil, ecKey), false},
+		{"kid that is not a string", sign(jwt.SigningMethodES256, 7, ecKey), false},
+		{"unknown kid", sign(jwt.SigningMethodES256, "2025-01", ecKey), false},
+		{"RSA token with the kid of the EC key", sign(jwt.SigningMethodRS256, "ec", rsaKey), false},
+		{"RS512 token with the kid of the RS256 key", sign(jwt.SigningMethodRS512, "rsa", rsaKey), false},
+		{"HS256 token keyed with a kid", sign(jwt.SigningMethodHS256, "rsa", []byte("rsa")), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := jwt.Parse(test.token, keySet.Keyfunc)
+			if test.valid && err != nil {
+				t.Errorf("Parse returned %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("Parse accepted the token")
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	directory := t.TempDir()
+	writeRSAKey(t, directory, "2026-04", 1)
+	before, err := Load(directory, "")
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+	oldToken, err := before.Sign(jwt.MapClaims{"user_id": "user"})
+	if err != nil {
+		t.Fatalf("Sign returned %v", err)
+	}
+
+	// A new key signs, the old private key is replaced by its public key
+	writeECKey(t, directory, "2026-10", 2)
+	if err = os.Remove(filepath.Join(directory, "2026-04.pem")); err != nil {
+		t.Fatalf("Remove returned %v", err)
+	}
+	writeRSAPublicKey(t, directory, "2026-04", 1)
+	after, err := Load(directory, "")
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+	if after.SigningKeyId() != "2026-10" {
+		t.Errorf("SigningKeyId = %s after the rotation, want 2026-10", after.SigningKeyId())
+	}
+	if _, err = jwt.Parse(oldToken, after.Keyfunc); err != nil {
+		t.Errorf("the token of the retired key is rejected: %v", err)
+	}
+	newToken, err := after.Sign(jwt.MapClaims{"user_id": "user"})
+	if err != nil {
+		t.Fatalf("Sign returned %v", err)
+	}
+	if _, err = jwt.Parse(newToken, before.Keyfunc); err == nil {
+		t.Errorf("the key set before the rotation accepted the token of the new key")
+	}
+	if jwks := after.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2026-10" || jwks.Keys[0].Crv != "P-256" || jwks.Keys[1].Kty != "RSA" {
+		t.Errorf("JWKS = %+v, want the signing EC key then the RSA key", jwks)
+	}
+
+	// The public key is removed once the tokens of the old key have expired
+	if err = os.Remove(filepath.Join(directory, "2026-04.pub.pem")); err != nil {
+		t.Fatalf("Remove returned %v", err)
+	}
+	retired, err := Load(directory, "")
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+	if _, err = jwt.Parse(oldToken, retired.Keyfunc); err == nil {
+		t.Errorf("the token of the removed key is accepted")
+	}
+}
@@ -88,3 +88,103 @@ func SendNotification(app *firebase.App, params NotificationParams) error {
 
 	return err
 }
+
+// DataMessageParams is a silent message handled by the app itself, no
+// notification is shown
+type DataMessageParams struct {
+	DeviceToken string
+	Type        value.NotificationType
+	Data        map[string]string
+}
+
+func SendDataMessage(app *firebase.App, params DataMessageParams) error {
+	ctx := context.Background()
+	msgApp, err := app.Messaging(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := msgApp.Send(ctx, dataMessage(params))
+	if err != nil {
+		return err
+	}
+
+	log.Debug("FCM Sending Data Message Response ", res)
+
+	return nil
+}
+
+// fcmBatchSize is the number of messages FCM takes in one batch
+const fcmBatchSize = 500
+
+// SendDataMessages sends the messages in batches, it returns the error of each
+// message in the same order, nil for the messages that were sent
+func SendDataMessages(app *firebase.App, params []DataMessageParams) ([]error, error) {
+	errs := make([]error, len(params))
+	if len(params) == 0 {
+		return errs, nil
+	}
+
+	ctx := context.Background()
+	msgApp, err := app.Messaging(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(params); start += fcmBatchSize {
+		end := start + fcmBatchSize
+		if end > len(params) {
+			end = len(params)
+		}
+
+		messages := make([]*messaging.Message, 0, end-start)
+		for _, p := range params[start:end] {
+			messages = append(messages, dataMessage(p))
+		}
+
+		res, err := msgApp.SendEach(ctx, messages)
+		if err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = err
+			}
+			continue
+		}
+		for i, r := range res.Responses {
+			if !r.Success {
+				errs[start+i] = r.Error
+			}
+		}
+		log.Debug("FCM Sending Data Messages ", res.SuccessCount, " sent ", res.FailureCount, " failed")
+	}
+
+	return errs, nil
+}
+
+func dataMessage(params DataMessageParams) *messaging.Message {
+	data := map[string]string{"type": string(params.Type)}
+	customData := map[string]interface{}{"type": string(params.Type)}
+	for key, datum := range params.Data {
+		data[key] = datum
+		customData[key] = datum
+	}
+
+	return &messaging.Message{
+		Token: params.DeviceToken,
+		Data:  data,
+		Android: &messaging.AndroidConfig{
+			Priority: "high",
+			Data:     data,
+		},
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				"apns-priority": "10",
+			},
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					ContentAvailable: true,
+				},
+				CustomData: customData,
+			},
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package sheet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// unbounded marks an open end of a range, eg the row end of "Devices!L:L"
+const unbounded = -1
+
+// / a1Range is a parsed A1 notation range. Rows and columns are 0-based,
+// / ends are exclusive and may be unbounded.
+type a1Range struct {
+	Sheet    string
+	StartRow int
+	StartCol int
+	EndRow   int
+	EndCol   int
+	// SingleCell is set for ranges like "K11" that name only an anchor cell
+	SingleCell bool
+}
+
+var a1CellPattern = regexp.MustCompile(`^\$?([A-Za-z]*)\$?([0-9]*)$`)
+
+var plainSheetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// / parseA1Range parses ranges such as "Sheet1!A1:B2", "'My Sheet'!P12:W",
+// / "Devices!L:L", "K12:K1000" or a bare sheet name like "History".
+// / isSheet tells whether a bare token names an existing sheet, which wins over
+// / reading it as a cell reference, the same way Google Sheets resolves it.
+func parseA1Range(s string, isSheet func(string) bool) (a1Range, error) {
+	s = strings.TrimSpace(s)
+	sheetName, cells := splitSheetName(s)
+	if cells == "" {
+		return a1Range{Sheet: sheetName, EndRow: unbounded, EndCol: unbounded}, nil
+	}
+
+	if sheetName == "" && isSheet != nil && isSheet(cells) {
+		return a1Range{Sheet: cells, EndRow: unbounded, EndCol: unbounded}, nil
+	}
+
+	parts := strings.Split(cells, ":")
+	if len(parts) > 2 {
+		return a1Range{}, fmt.Errorf("invalid range %q", s)
+	}
+
+	startCol, hasStartCol, startRow, hasStartRow, ok := parseA1Cell(parts[0])
+	if !ok || (len(parts) == 1 && !hasStartRow) {
+		if len(parts) == 1 && sheetName == "" {
+			// A lone token that is not a cell reference is a sheet name
+			return a1Range{Sheet: cells, EndRow: unbounded, EndCol: unbounded}, nil
+		}
+		return a1Range{}, fmt.Errorf("invalid range %q", s)
+	}
+
+	r := a1Range{Sheet: sheetName}
+	if hasStartCol {
+		r.StartCol = startCol
+	}
+	if hasStartRow {
+		r.StartRow = startRow
+	}
+
+	if len(parts) == 1 {
+		r.SingleCell = hasStartCol && hasStartRow
+		r.EndCol, r.EndRow = unbounded, unbounded
+		if r.SingleCell {
+			r.EndCol, r.EndRow = r.StartCol+1, r.StartRow+1
+		} else {
+			r.EndRow = r.StartRow + 1
+		}
+		return r, nil
+	}
+
+	endCol, hasEndCol, endRow, hasEndRow, ok := parseA1Cell(parts[1])
+	if !ok {
+		return a1Range{}, fmt.Errorf("invalid range %q", s)
+	}
+	r.EndCol, r.EndRow = unbounded, unbounded
+	if hasEndCol {
+		r.EndCol = endCol + 1
+	}
+	if hasEndRow {
+		r.EndRow = endRow + 1
+	}
+	if (r.EndCol != unbounded && r.EndCol <= r.StartCol) || (r.EndRow != unbounded && r.EndRow <= r.StartRow) {
+		return a1Range{}, fmt.Errorf("invalid range %q", s)
+	}
+
+	return r, nil
+}
+
+func splitSheetName(s string) (string, string) {
+	if strings.HasPrefix(s, "'") {
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			name := strings.ReplaceAll(s[1:i], "''", "'")
+			return name, strings.TrimPrefix(s[i+1:], "!")
+		}
+		return s, ""
+	}
+
+	if idx := strings.LastIndex(s, "!"); idx >= 0 {
+		return s[:idx], s[idx+1:]
+	}
+
+	return "", s
+}
+
+func parseA1Cell(s string) (col int, hasCol bool, row int, hasRow bool, ok bool) {
+	match := a1CellPattern.FindStringSubmatch(s)
+	if match == nil || (match[1] == "" && match[2] == "") || len(match[1]) > 3 {
+		return 0, false, 0, false, false
+	}
+
+	if match[1] != "" {
+		hasCol = true
+		for _, ch := range strings.ToUpper(match[1]) {
+			col = col*26 + int(ch-'A'+1)
+		}
+		col--
+	}
+
+	if match[2] != "" {
+		n, err := strconv.Atoi(match[2])
+		if err != nil || n < 1 {
+			return 0, false, 0, false, false
+		}
+		hasRow = true
+		row = n - 1
+	}
+
+	return col, hasCol, row, hasRow, true
+}
+
+// / columnName converts a 0-based column index to its letters, eg 0 -> A, 27 -> AB
+func columnName(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+func formatA1Range(sheetName string, startRow, startCol, rows, cols int) string {
+	if rows <= 0 || cols <= 0 {
+		return quoteSheetName(sheetName)
+	}
+
+	return fmt.Sprintf("%s!%s%d:%s%d",
+		quoteSheetName(sheetName),
+		columnName(startCol), startRow+1,
+		columnName(startCol+cols-1), startRow+rows,
+	)
+}
+
+func quoteSheetName(name string) string {
+	if plainSheetNamePattern.MatchString(name) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
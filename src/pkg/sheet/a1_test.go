@@ -0,0 +1,140 @@
+package sheet
+
+import (
+	"testing"
+)
+
+func TestParseA1Range(t *testing.T) {
+	sheets := map[string]bool{"History": true, "AB1": true}
+	isSheet := func(name string) bool { return sheets[name] }
+
+	tests := []struct {
+		name  string
+		input string
+		want  a1Range
+	}{
+		{
+			name:  "bounded range with sheet",
+			input: "Sheet1!A1:B2",
+			want:  a1Range{Sheet: "Sheet1", StartRow: 0, StartCol: 0, EndRow: 2, EndCol: 2},
+		},
+		{
+			name:  "quoted sheet name with open row end",
+			input: "'My Sheet'!P12:W",
+			want:  a1Range{Sheet: "My Sheet", StartRow: 11, StartCol: 15, EndRow: unbounded, EndCol: 23},
+		},
+		{
+			name:  "quoted sheet name with escaped quote",
+			input: "'Bob''s'!A1",
+			want:  a1Range{Sheet: "Bob's", StartRow: 0, StartCol: 0, EndRow: 1, EndCol: 1, SingleCell: true},
+		},
+		{
+			name:  "whole column",
+			input: "Devices!L:L",
+			want:  a1Range{Sheet: "Devices", StartRow: 0, StartCol: 11, EndRow: unbounded, EndCol: 12},
+		},
+		{
+			name:  "range without sheet",
+			input: "K12:K1000",
+			want:  a1Range{StartRow: 11, StartCol: 10, EndRow: 1000, EndCol: 11},
+		},
+		{
+			name:  "single cell",
+			input: "K11",
+			want:  a1Range{StartRow: 10, StartCol: 10, EndRow: 11, EndCol: 11, SingleCell: true},
+		},
+		{
+			name:  "single row",
+			input: "Sheet1!3:3",
+			want:  a1Range{Sheet: "Sheet1", StartRow: 2, StartCol: 0, EndRow: 3, EndCol: unbounded},
+		},
+		{
+			name:  "absolute references",
+			input: "$A$1:$C$4",
+			want:  a1Range{StartRow: 0, StartCol: 0, EndRow: 4, EndCol: 3},
+		},
+		{
+			name:  "bare sheet name",
+			input: "History",
+			want:  a1Range{Sheet: "History", EndRow: unbounded, EndCol: unbounded},
+		},
+		{
+			name:  "existing sheet wins over a cell reference",
+			input: "AB1",
+			want:  a1Range{Sheet: "AB1", EndRow: unbounded, EndCol: unbounded},
+		},
+		{
+			name:  "sheet name alone with a bang",
+			input: "Sheet1!",
+			want:  a1Range{Sheet: "Sheet1", EndRow: unbounded, EndCol: unbounded},
+		},
+		{
+			name:  "multi letter columns",
+			input: "AA10:AC",
+			want:  a1Range{StartRow: 9, StartCol: 26, EndRow: unbounded, EndCol: 29},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseA1Range(test.input, isSheet)
+			if err != nil {
+				t.Fatalf("parseA1Range(%q) returned %v", test.input, err)
+			}
+			if got != test.want {
+				t.Errorf("parseA1Range(%q) = %+v, want %+v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseA1RangeErrors(t *testing.T) {
+	tests := []string{
+		"Sheet1!A1:B2:C3",
+		"Sheet1!B2:A1",
+		"Sheet1!A0",
+		"Sheet1!ABCD1",
+		"Sheet1!A1:?",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if got, err := parseA1Range(input, nil); err == nil {
+				t.Errorf("parseA1Range(%q) = %+v, want an error", input, got)
+			}
+		})
+	}
+}
+
+func TestFormatA1Range(t *testing.T) {
+	tests := []struct {
+		sheet    string
+		startRow int
+		startCol int
+		rows     int
+		cols     int
+		want     string
+	}{
+		{"Sheet1", 0, 0, 2, 2, "Sheet1!A1:B2"},
+		{"My Sheet", 11, 15, 1, 8, "'My Sheet'!P12:W12"},
+		{"Bob's", 0, 25, 1, 2, "'Bob''s'!Z1:AA1"},
+		{"Sheet1", 4, 0, 0, 3, "Sheet1"},
+	}
+
+	for _, test := range tests {
+		got := formatA1Range(test.sheet, test.startRow, test.startCol, test.rows, test.cols)
+		if got != test.want {
+			t.Errorf("formatA1Range(%q, %d, %d, %d, %d) = %q, want %q", test.sheet, test.startRow, test.startCol, test.rows, test.cols, got, test.want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+
+	for col, want := range tests {
+		if got := columnName(col); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", col, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package sheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	xlsxMimeType        = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	spreadsheetMimeType = "application/vnd.google-apps.spreadsheet"
+)
+
+// / localFilesDirectory holds the uploads of the local file store, next to the
+// / spreadsheet directories
+const localFilesDirectory = "files"
+
+// FileStore keeps the files that live next to the spreadsheets: spreadsheets
+// made from an xlsx template, xlsx exports of spreadsheets and plain uploads
+// such as database backups.
+type FileStore interface {
+	ImportSpreadsheet(name string, folderId string, xlsx io.Reader) (string, error)
+	ExportSpreadsheet(spreadsheetId string, w io.Writer) error
+	Upload(name string, folderId string, content io.Reader) error
+}
+
+type GoogleFiles struct {
+	driveService *drive.Service
+}
+
+var _ FileStore = (*GoogleFiles)(nil)
+
+func newGoogleFiles(credentialsFilePath string, contex context.Context) (*GoogleFiles, error) {
+	driveService, err := drive.NewService(contex, option.WithCredentialsFile(credentialsFilePath))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoogleFiles{driveService: driveService}, nil
+}
+
+// / ImportSpreadsheet converts the xlsx into a Google spreadsheet in the folder
+func (receiver GoogleFiles) ImportSpreadsheet(name string, folderId string, xlsx io.Reader) (string, error) {
+	file, err := receiver.driveService.Files.Create(&drive.File{
+		Name:     name,
+		Parents:  []string{folderId},
+		MimeType: spreadsheetMimeType,
+	}).Media(xlsx, googleapi.ContentType(spreadsheetMimeType)).Do()
+	if err != nil {
+		return "", err
+	}
+
+	return file.Id, nil
+}
+
+func (receiver GoogleFiles) ExportSpreadsheet(spreadsheetId string, w io.Writer) error {
+	resp, err := receiver.driveService.Files.Export(spreadsheetId, xlsxMimeType).Download()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (receiver GoogleFiles) Upload(name string, folderId string, content io.Reader) error {
+	if folderId == "" {
+		return fmt.Errorf("no folder to upload %s to", name)
+	}
+
+	_, err := receiver.driveService.Files.Create(&drive.File{
+		Name:    name,
+		Parents: []string{folderId},
+	}).Media(content).Do()
+	return err
+}
+
+// / LocalFiles is the file store of the local spreadsheet driver. Uploads are
+// / kept under root/files/<folderId>/, the root itself without a folder.
+type LocalFiles struct {
+	store *localStore
+}
+
+var _ FileStore = (*LocalFiles)(nil)
+
+// / ImportSpreadsheet starts an empty local spreadsheet named after the xlsx.
+// / The cells of the template are not converted, its sheets are created by the
+// / first write like in any local spreadsheet.
+func (receiver LocalFiles) ImportSpreadsheet(name string, _ string, _ io.Reader) (string, error) {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	spreadsheetId := uuid.NewString()
+	err := receiver.store.saveManifest(spreadsheetId, &localManifest{Title: strings.TrimSuffix(name, ".xlsx")})
+	if err != nil {
+		return "", err
+	}
+
+	return spreadsheetId, nil
+}
+
+// / ExportSpreadsheet writes the first sheet of the spreadsheet as an xlsx
+// / without styles
+func (receiver LocalFiles) ExportSpreadsheet(spreadsheetId string, w io.Writer) error {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	manifest, err := receiver.store.load(spreadsheetId)
+	if err != nil {
+		return err
+	}
+
+	title := manifest.Title
+	grid := [][]string{}
+	if len(manifest.Sheets) > 0 {
+		title = manifest.Sheets[0].Title
+		grid, err = receiver.store.readGrid(spreadsheetId, manifest.Sheets[0].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err := NewXLSXWriter(w, "", title)
+	if err != nil {
+		return err
+	}
+	for _, row := range grid {
+		err = writer.WriteRow(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func (receiver LocalFiles) Upload(name string, folderId string, content io.Reader) error {
+	dir := filepath.Join(receiver.store.root, localFilesDirectory)
+	if folderId != "" {
+		if !isLocalFileName(folderId) {
+			return fmt.Errorf("invalid folder id %q", folderId)
+		}
+		dir = filepath.Join(dir, folderId)
+	}
+	if !isLocalFileName(name) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, content)
+	return errors.Join(err, file.Close())
+}
+
+func isLocalFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
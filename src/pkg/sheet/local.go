@@ -0,0 +1,846 @@
+package sheet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	DriverGoogle = "google"
+	DriverLocal  = "local"
+)
+
+const localManifestFileName = "spreadsheet.json"
+
+var ErrLocalSpreadsheetNotFound = errors.New("spreadsheet not found")
+var ErrLocalSheetNotFound = errors.New("sheet not found")
+
+// / NewLocalSpreadsheet opens a spreadsheet store kept on the local file system
+// / so the API and its sync jobs can run without Google credentials.
+// /
+// / Every spreadsheet is a directory named after its id under root. The directory
+// / holds a spreadsheet.json manifest with the title and the sheets, and one CSV
+// / file per sheet named after the sheet id:
+// /
+// /	root/<spreadsheetId>/spreadsheet.json
+// /	root/<spreadsheetId>/0.csv
+// /
+// / Writes create missing spreadsheets and sheets on the fly so a fresh directory
+// / is usable straight away, reads of missing spreadsheets fail like Google does.
+func NewLocalSpreadsheet(root string) (*Spreadsheet, error) {
+	if root == "" {
+		return nil, errors.New("local spreadsheet directory is not configured")
+	}
+
+	err := os.MkdirAll(root, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &localStore{root: root}
+
+	return &Spreadsheet{
+		Reader: &LocalReader{store: store},
+		Writer: &LocalWriter{store: store},
+		Files:  &LocalFiles{store: store},
+	}, nil
+}
+
+type LocalReader struct {
+	store *localStore
+}
+
+type LocalWriter struct {
+	store *localStore
+}
+
+var _ SpreadsheetReader = (*LocalReader)(nil)
+var _ SpreadsheetWriter = (*LocalWriter)(nil)
+
+type localSheet struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type localManifest struct {
+	Title       string       `json:"title"`
+	NextSheetID int64        `json:"next_sheet_id"`
+	Sheets      []localSheet `json:"sheets"`
+}
+
+func (m *localManifest) findByTitle(title string) (localSheet, bool) {
+	for _, s := range m.Sheets {
+		if s.Title == title {
+			return s, true
+		}
+	}
+	return localSheet{}, false
+}
+
+func (m *localManifest) findByID(id int64) (localSheet, bool) {
+	for _, s := range m.Sheets {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return localSheet{}, false
+}
+
+func (m *localManifest) addSheet(title string) localSheet {
+	s := localSheet{ID: m.NextSheetID, Title: title}
+	m.NextSheetID++
+	m.Sheets = append(m.Sheets, s)
+	return s
+}
+
+type localStore struct {
+	root string
+	mu   sync.Mutex
+}
+
+func (s *localStore) dir(spreadsheetId string) (string, error) {
+	if spreadsheetId == "" || spreadsheetId == "." || spreadsheetId == ".." || strings.ContainsAny(spreadsheetId, `/\`) {
+		return "", fmt.Errorf("invalid spreadsheet id %q", spreadsheetId)
+	}
+	return filepath.Join(s.root, spreadsheetId), nil
+}
+
+func (s *localStore) load(spreadsheetId string) (*localManifest, error) {
+	dir, err := s.dir(spreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, localManifestFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrLocalSpreadsheetNotFound, spreadsheetId)
+		}
+		return nil, err
+	}
+
+	var manifest localManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+func (s *localStore) loadOrCreate(spreadsheetId string) (*localManifest, error) {
+	manifest, err := s.load(spreadsheetId)
+	if errors.Is(err, ErrLocalSpreadsheetNotFound) {
+		manifest = &localManifest{Title: spreadsheetId}
+		return manifest, s.saveManifest(spreadsheetId, manifest)
+	}
+	return manifest, err
+}
+
+func (s *localStore) saveManifest(spreadsheetId string, manifest *localManifest) error {
+	dir, err := s.dir(spreadsheetId)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(filepath.Join(dir, localManifestFileName), data)
+}
+
+// / resolve finds the sheet a range points at. An empty sheet name means the
+// / first sheet, the same as a range without a sheet prefix in Google Sheets.
+func (s *localStore) resolve(spreadsheetId string, manifest *localManifest, sheetName string) (localSheet, error) {
+	if sheetName == "" {
+		if len(manifest.Sheets) == 0 {
+			return localSheet{}, fmt.Errorf("%w: spreadsheet %s has no sheets", ErrLocalSheetNotFound, spreadsheetId)
+		}
+		return manifest.Sheets[0], nil
+	}
+
+	found, ok := manifest.findByTitle(sheetName)
+	if !ok {
+		return localSheet{}, fmt.Errorf("%w: unable to parse range %s in spreadsheet %s", ErrLocalSheetNotFound, sheetName, spreadsheetId)
+	}
+	return found, nil
+}
+
+func (s *localStore) resolveOrCreate(spreadsheetId string, manifest *localManifest, sheetName string) (localSheet, error) {
+	found, err := s.resolve(spreadsheetId, manifest, sheetName)
+	if err == nil {
+		return found, nil
+	}
+
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+	created := manifest.addSheet(sheetName)
+	return created, s.saveManifest(spreadsheetId, manifest)
+}
+
+func (s *localStore) parseRange(manifest *localManifest, readRange string) (a1Range, error) {
+	return parseA1Range(readRange, func(name string) bool {
+		_, ok := manifest.findByTitle(name)
+		return ok
+	})
+}
+
+func (s *localStore) sheetPath(spreadsheetId string, sheetId int64) (string, error) {
+	dir, err := s.dir(spreadsheetId)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, strconv.FormatInt(sheetId, 10)+".csv"), nil
+}
+
+func (s *localStore) readGrid(spreadsheetId string, sheetId int64) ([][]string, error) {
+	path, err := s.sheetPath(spreadsheetId, sheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return [][]string{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	return reader.ReadAll()
+}
+
+func (s *localStore) writeGrid(spreadsheetId string, sheetId int64, grid [][]string) error {
+	path, err := s.sheetPath(spreadsheetId, sheetId)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	for _, row := range trimGrid(grid) {
+		if len(row) == 0 {
+			// csv.Reader skips blank lines, keep empty rows as a quoted empty field
+			writer.Flush()
+			builder.WriteString("\"\"\n")
+			continue
+		}
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(path, []byte(builder.String()))
+}
+
+func (s *localStore) deleteGrid(spreadsheetId string, sheetId int64) error {
+	path, err := s.sheetPath(spreadsheetId, sheetId)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	tmp := path + ".tmp"
+	err := os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (receiver LocalReader) Get(params ReadSpecificRangeParams) ([][]interface{}, error) {
+	values, err := receiver.store.readRange(params)
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet:", err)
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		log.Info("No data found.")
+		return nil, nil
+	}
+
+	return values, nil
+}
+
+func (receiver LocalReader) GetFirstRow(params ReadSpecificRangeParams) ([][]interface{}, error) {
+	values, err := receiver.store.readRange(params)
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet:", err)
+		return nil, err
+	}
+
+	columns := toInterfaces(trimGrid(transpose(toStrings(values, "ROWS"))))
+	if len(columns) == 0 {
+		log.Info("No data found.")
+		return nil, nil
+	}
+
+	return columns, nil
+}
+
+// / FindFirstRow looks up the 1-based row of deviceID in column L of the Devices
+// / sheet. The Google implementation evaluates a MATCH formula on LOOKUP_SHEET,
+// / the local one searches the column directly.
+func (receiver LocalReader) FindFirstRow(params ReadSpecificRangeParams, deviceID string) (int, error) {
+	values, err := receiver.store.readRange(ReadSpecificRangeParams{
+		SpreadsheetId: params.SpreadsheetId,
+		ReadRange:     "Devices!L:L",
+	})
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet:", err)
+		return 0, err
+	}
+
+	for rowIndex, row := range values {
+		if len(row) > 0 && row[0] == deviceID {
+			return rowIndex + 1, nil
+		}
+	}
+
+	return 0, errors.New("Unable to find row number for device " + deviceID)
+}
+
+func (receiver LocalReader) GetSheets(spreadsheetId string) ([]string, error) {
+	allSheets, err := receiver.GetAllSheets(spreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetNames := make([]string, len(allSheets))
+	for i, singleSheet := range allSheets {
+		sheetNames[i] = singleSheet.Title
+	}
+
+	return sheetNames, nil
+}
+
+func (receiver LocalReader) GetAllSheets(spreadsheetId string) ([]SingleSheet, error) {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	manifest, err := receiver.store.load(spreadsheetId)
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet:", err)
+		return nil, err
+	}
+
+	singleSheets := make([]SingleSheet, 0, len(manifest.Sheets))
+	for _, s := range manifest.Sheets {
+		singleSheets = append(singleSheets, SingleSheet{ID: s.ID, Title: s.Title})
+	}
+
+	return singleSheets, nil
+}
+
+func (s *localStore) readRange(params ReadSpecificRangeParams) ([][]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manifest, err := s.load(params.SpreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := s.parseRange(manifest, params.ReadRange)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolve(params.SpreadsheetId, manifest, rng.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := s.readGrid(params.SpreadsheetId, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInterfaces(trimGrid(extract(grid, rng))), nil
+}
+
+func (receiver LocalWriter) WriteRanges(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
+	resp, err := receiver.store.append(params.Range, params.Dimension, params.Rows, spreadsheetId)
+	if err != nil {
+		log.Error("Unable to append data from sheet: ", err)
+		return nil, err
+	}
+
+	log.Debug("Wrote: ", resp)
+
+	return resp, nil
+}
+
+// / WriteRangesAsUserEntered stores values exactly as given, formulas are kept as
+// / text because the local backend does not evaluate them.
+func (receiver LocalWriter) WriteRangesAsUserEntered(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
+	return receiver.WriteRanges(params, spreadsheetId)
+}
+
+func (receiver LocalWriter) UpdateRange(params WriteRangeParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	s := receiver.store
+	manifest, err := s.loadOrCreate(spreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := s.parseRange(manifest, params.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveOrCreate(spreadsheetId, manifest, rng.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	values := toStrings(params.Rows, params.Dimension)
+	if !rng.SingleCell {
+		rows, cols := gridSize(values)
+		if (rng.EndRow != unbounded && rng.StartRow+rows > rng.EndRow) || (rng.EndCol != unbounded && rng.StartCol+cols > rng.EndCol) {
+			return nil, fmt.Errorf("requested writing within range %s, but tried writing %d rows and %d columns", params.Range, rows, cols)
+		}
+	}
+
+	grid, err := s.readGrid(spreadsheetId, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	grid = place(grid, values, rng.StartRow, rng.StartCol)
+	err = s.writeGrid(spreadsheetId, target.ID, grid)
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet: ", err)
+		return nil, err
+	}
+
+	resp := updateResponse(spreadsheetId, target.Title, rng.StartRow, rng.StartCol, values)
+	log.Debug("Wrote: ", resp)
+
+	return resp, nil
+}
+
+// / UpdateRanges mirrors the Google implementation, which currently sends an
+// / empty batch, so it only checks that the spreadsheet exists.
+func (receiver LocalWriter) UpdateRanges(spreadsheetId string, params []WriteRangeParams) error {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	_, err := receiver.store.load(spreadsheetId)
+
+	return err
+}
+
+func (receiver LocalWriter) CreateSheet(sheetName string, spreadsheetId string) error {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	manifest, err := receiver.store.loadOrCreate(spreadsheetId)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := manifest.findByTitle(sheetName); ok {
+		return fmt.Errorf("a sheet with the name %q already exists", sheetName)
+	}
+
+	created := manifest.addSheet(sheetName)
+	log.Debug("Create new Sheet", created)
+
+	return receiver.store.saveManifest(spreadsheetId, manifest)
+}
+
+func (receiver LocalWriter) AppendSheet(params AppendParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
+	log.Debug("AppendSheet: ", params)
+	log.Debug("AppendSheet: ", spreadsheetId)
+
+	resp, err := receiver.store.append(quoteSheetName(params.SheetName), params.Dimension, params.Rows, spreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Append Sheet", resp)
+
+	return resp.Updates, nil
+}
+
+func (receiver LocalWriter) ClearRange(params ClearRangeParams) (*sheets.ClearValuesResponse, error) {
+	log.Debug("ClearRange: ", params)
+
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	s := receiver.store
+	manifest, err := s.load(params.SpreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := s.parseRange(manifest, params.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolve(params.SpreadsheetId, manifest, rng.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := s.readGrid(params.SpreadsheetId, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for r := rng.StartRow; r < len(grid) && (rng.EndRow == unbounded || r < rng.EndRow); r++ {
+		for c := rng.StartCol; c < len(grid[r]) && (rng.EndCol == unbounded || c < rng.EndCol); c++ {
+			grid[r][c] = ""
+		}
+	}
+
+	err = s.writeGrid(params.SpreadsheetId, target.ID, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sheets.ClearValuesResponse{
+		SpreadsheetId: params.SpreadsheetId,
+		ClearedRange:  params.Range,
+	}
+	log.Debug("Clear Ranges: ", resp)
+
+	return resp, nil
+}
+
+func (receiver LocalWriter) CopySingleSheet(params CopySingleSheetParam) error {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	return receiver.store.copySheet(params.FromSpreadsheetId, params.SingleSheet.ID, params.ToSpreadsheetId, params.SingleSheet.Title)
+}
+
+func (s *localStore) copySheet(fromSpreadsheetId string, sheetId int64, toSpreadsheetId string, title string) error {
+	source, err := s.load(fromSpreadsheetId)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := source.findByID(sheetId); !ok {
+		return fmt.Errorf("%w: sheet id %d in spreadsheet %s", ErrLocalSheetNotFound, sheetId, fromSpreadsheetId)
+	}
+
+	grid, err := s.readGrid(fromSpreadsheetId, sheetId)
+	if err != nil {
+		return err
+	}
+
+	destination, err := s.loadOrCreate(toSpreadsheetId)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := destination.findByTitle(title); ok {
+		return fmt.Errorf("a sheet with the name %q already exists", title)
+	}
+
+	copied := destination.addSheet(title)
+	err = s.writeGrid(toSpreadsheetId, copied.ID, grid)
+	if err != nil {
+		return err
+	}
+
+	return s.saveManifest(toSpreadsheetId, destination)
+}
+
+func (receiver LocalWriter) DeleteSheet(params DeleteSheetParams) error {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	s := receiver.store
+	manifest, err := s.load(params.SpreadsheetID)
+	if err != nil {
+		log.Error("Unable to retrieve data from sheet:", err)
+		return err
+	}
+
+	remaining := make([]localSheet, 0, len(manifest.Sheets))
+	for _, existing := range manifest.Sheets {
+		if existing.Title != params.SheetTitle {
+			remaining = append(remaining, existing)
+			continue
+		}
+		err = s.deleteGrid(params.SpreadsheetID, existing.ID)
+		if err != nil {
+			return err
+		}
+	}
+	manifest.Sheets = remaining
+
+	return s.saveManifest(params.SpreadsheetID, manifest)
+}
+
+func (receiver LocalWriter) DuplicateSpreadsheet(params DuplicateSpreadsheetParams) (DuplicateSpreadsheetResult, error) {
+	receiver.store.mu.Lock()
+	defer receiver.store.mu.Unlock()
+
+	s := receiver.store
+	source, err := s.load(params.SourceSpreadsheetId)
+	if err != nil {
+		log.WithError(err).Error("Failed to retrieve spreadsheet")
+		return DuplicateSpreadsheetResult{}, err
+	}
+
+	signUpSheet, ok := source.findByTitle(params.TargetSheetName)
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrLocalSheetNotFound, params.TargetSheetName)
+		log.WithError(err).Error("Failed to find sheet")
+		return DuplicateSpreadsheetResult{}, err
+	}
+
+	destinationSpreadsheetId := uuid.NewString()
+	err = s.saveManifest(destinationSpreadsheetId, &localManifest{Title: params.TargetSpreadsheetName})
+	if err != nil {
+		return DuplicateSpreadsheetResult{}, err
+	}
+
+	err = s.copySheet(params.SourceSpreadsheetId, signUpSheet.ID, destinationSpreadsheetId, params.TargetSheetName)
+	if err != nil {
+		log.WithError(err).Error("Failed to copy single sheet")
+		return DuplicateSpreadsheetResult{}, err
+	}
+
+	return DuplicateSpreadsheetResult{
+		SpreadsheetId: destinationSpreadsheetId,
+	}, nil
+}
+
+// / append writes rows after the last non-empty row of the table found in
+// / appendRange, starting at the first column of the range. This follows the
+// / Values.Append behaviour of Google Sheets closely enough for our sheets,
+// / which always append below a fixed header block.
+func (s *localStore) append(appendRange string, dimension string, rows [][]interface{}, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manifest, err := s.loadOrCreate(spreadsheetId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := s.parseRange(manifest, appendRange)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveOrCreate(spreadsheetId, manifest, rng.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := s.readGrid(spreadsheetId, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := toStrings(rows, dimension)
+	_, cols := gridSize(values)
+	lastCol := rng.StartCol + cols
+	searchCol := lastCol
+	if !rng.SingleCell {
+		if rng.EndCol == unbounded {
+			_, searchCol = gridSize(grid)
+		}
+		searchCol = max(searchCol, lastCol, rng.EndCol)
+	}
+
+	tableEnd := rng.StartRow
+	for r := rng.StartRow; r < len(grid); r++ {
+		for c := rng.StartCol; c < len(grid[r]) && c < searchCol; c++ {
+			if grid[r][c] != "" {
+				tableEnd = r + 1
+				break
+			}
+		}
+	}
+
+	grid = place(grid, values, tableEnd, rng.StartCol)
+	err = s.writeGrid(spreadsheetId, target.ID, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sheets.AppendValuesResponse{
+		SpreadsheetId: spreadsheetId,
+		TableRange:    formatA1Range(target.Title, rng.StartRow, rng.StartCol, tableEnd-rng.StartRow, lastCol-rng.StartCol),
+		Updates:       updateResponse(spreadsheetId, target.Title, tableEnd, rng.StartCol, values),
+	}, nil
+}
+
+func updateResponse(spreadsheetId string, sheetTitle string, startRow, startCol int, values [][]string) *sheets.UpdateValuesResponse {
+	rows, cols := gridSize(values)
+	return &sheets.UpdateValuesResponse{
+		SpreadsheetId:  spreadsheetId,
+		UpdatedRange:   formatA1Range(sheetTitle, startRow, startCol, rows, cols),
+		UpdatedRows:    int64(rows),
+		UpdatedColumns: int64(cols),
+		UpdatedCells:   int64(rows * cols),
+	}
+}
+
+func extract(grid [][]string, rng a1Range) [][]string {
+	result := make([][]string, 0)
+	for r := rng.StartRow; r < len(grid) && (rng.EndRow == unbounded || r < rng.EndRow); r++ {
+		row := make([]string, 0)
+		for c := rng.StartCol; c < len(grid[r]) && (rng.EndCol == unbounded || c < rng.EndCol); c++ {
+			row = append(row, grid[r][c])
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+// / place writes values into grid with the top left cell at (startRow, startCol),
+// / growing the grid as needed. Nil cells are written as empty strings.
+func place(grid [][]string, values [][]string, startRow, startCol int) [][]string {
+	for r, row := range values {
+		target := startRow + r
+		for len(grid) <= target {
+			grid = append(grid, []string{})
+		}
+		for c, cell := range row {
+			col := startCol + c
+			for len(grid[target]) <= col {
+				grid[target] = append(grid[target], "")
+			}
+			grid[target][col] = cell
+		}
+	}
+	return grid
+}
+
+// / trimGrid drops trailing empty cells and rows the way the Sheets API omits them
+func trimGrid(grid [][]string) [][]string {
+	trimmed := make([][]string, len(grid))
+	for r, row := range grid {
+		end := len(row)
+		for end > 0 && row[end-1] == "" {
+			end--
+		}
+		trimmed[r] = row[:end]
+	}
+
+	end := len(trimmed)
+	for end > 0 && len(trimmed[end-1]) == 0 {
+		end--
+	}
+	return trimmed[:end]
+}
+
+func transpose(grid [][]string) [][]string {
+	_, cols := gridSize(grid)
+	result := make([][]string, cols)
+	for c := 0; c < cols; c++ {
+		result[c] = make([]string, len(grid))
+		for r, row := range grid {
+			if c < len(row) {
+				result[c][r] = row[c]
+			}
+		}
+	}
+	return result
+}
+
+func gridSize(grid [][]string) (int, int) {
+	cols := 0
+	for _, row := range grid {
+		cols = max(cols, len(row))
+	}
+	return len(grid), cols
+}
+
+// / toStrings renders the values the way they are stored, transposing them
+// / first when they are given column by column.
+func toStrings(rows [][]interface{}, dimension string) [][]string {
+	result := make([][]string, len(rows))
+	for r, row := range rows {
+		result[r] = make([]string, len(row))
+		for c, cell := range row {
+			result[r][c] = formatCell(cell)
+		}
+	}
+
+	if dimension == "COLUMNS" {
+		return transpose(result)
+	}
+	return result
+}
+
+func toInterfaces(grid [][]string) [][]interface{} {
+	result := make([][]interface{}, len(grid))
+	for r, row := range grid {
+		result[r] = make([]interface{}, len(row))
+		for c, cell := range row {
+			result[r][c] = cell
+		}
+	}
+	return result
+}
+
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return fmt.Sprint(v)
+	}
+}
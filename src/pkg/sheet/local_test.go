@@ -0,0 +1,351 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestLocalSpreadsheet(t *testing.T) *Spreadsheet {
+	t.Helper()
+
+	spreadsheet, err := NewLocalSpreadsheet(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalSpreadsheet returned %v", err)
+	}
+
+	return spreadsheet
+}
+
+func rows(values ...[]interface{}) [][]interface{} {
+	return values
+}
+
+func TestLocalSpreadsheetUpdateAndGet(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "Forms!K12",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"q1", "text", 3.5}, []interface{}{"q2", nil, true}),
+	}, "forms")
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		readRange string
+		want      [][]interface{}
+	}{
+		{
+			name:      "written block",
+			readRange: "Forms!K12:M13",
+			want:      rows([]interface{}{"q1", "text", "3.5"}, []interface{}{"q2", "", "TRUE"}),
+		},
+		{
+			name:      "open ended range drops trailing empty cells",
+			readRange: "Forms!K12:L",
+			want:      rows([]interface{}{"q1", "text"}, []interface{}{"q2"}),
+		},
+		{
+			name:      "single column",
+			readRange: "Forms!K:K",
+			want: rows(
+				[]interface{}{}, []interface{}{}, []interface{}{}, []interface{}{}, []interface{}{}, []interface{}{},
+				[]interface{}{}, []interface{}{}, []interface{}{}, []interface{}{}, []interface{}{},
+				[]interface{}{"q1"}, []interface{}{"q2"},
+			),
+		},
+		{
+			name:      "range without sheet reads the first sheet",
+			readRange: "M13",
+			want:      rows([]interface{}{"TRUE"}),
+		},
+		{
+			name:      "empty range",
+			readRange: "Forms!A1:B2",
+			want:      nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "forms", ReadRange: test.readRange})
+			if err != nil {
+				t.Fatalf("Get(%q) returned %v", test.readRange, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Get(%q) = %v, want %v", test.readRange, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLocalSpreadsheetUpdateRangeOverflow(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "Sheet1!A1:B1",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"a", "b", "c"}),
+	}, "overflow")
+	if err == nil {
+		t.Error("UpdateRange wrote 3 columns into a 2 column range")
+	}
+}
+
+func TestLocalSpreadsheetAppend(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "TODOs!A1",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"header", "value"}),
+	}, "todo")
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+
+	appends := []struct {
+		rows      [][]interface{}
+		dimension string
+		updated   string
+	}{
+		{rows([]interface{}{"task 1", "done"}), "ROWS", "TODOs!A2:B2"},
+		{rows([]interface{}{"task 2", "task 3"}, []interface{}{"open", "done"}), "COLUMNS", "TODOs!A3:B4"},
+	}
+	for _, step := range appends {
+		resp, err := spreadsheet.Writer.WriteRanges(WriteRangeParams{Range: "TODOs!A1:B", Dimension: step.dimension, Rows: step.rows}, "todo")
+		if err != nil {
+			t.Fatalf("WriteRanges returned %v", err)
+		}
+		if resp.Updates.UpdatedRange != step.updated {
+			t.Errorf("WriteRanges updated %q, want %q", resp.Updates.UpdatedRange, step.updated)
+		}
+	}
+
+	got, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "todo", ReadRange: "TODOs"})
+	if err != nil {
+		t.Fatalf("Get returned %v", err)
+	}
+	want := rows(
+		[]interface{}{"header", "value"},
+		[]interface{}{"task 1", "done"},
+		[]interface{}{"task 2", "open"},
+		[]interface{}{"task 3", "done"},
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %v, want %v", got, want)
+	}
+}
+
+func TestLocalSpreadsheetClearRange(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "Sheet1!A1",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"a", "b", "c"}, []interface{}{"d", "e", "f"}),
+	}, "clear")
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+
+	_, err = spreadsheet.Writer.ClearRange(ClearRangeParams{SpreadsheetId: "clear", Range: "Sheet1!B1:C"})
+	if err != nil {
+		t.Fatalf("ClearRange returned %v", err)
+	}
+
+	got, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "clear", ReadRange: "Sheet1!A1:C2"})
+	if err != nil {
+		t.Fatalf("Get returned %v", err)
+	}
+	want := rows([]interface{}{"a"}, []interface{}{"d"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %v, want %v", got, want)
+	}
+}
+
+func TestLocalSpreadsheetSheets(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	for _, name := range []string{"Devices", "History"} {
+		if err := spreadsheet.Writer.CreateSheet(name, "sheets"); err != nil {
+			t.Fatalf("CreateSheet(%q) returned %v", name, err)
+		}
+	}
+	if err := spreadsheet.Writer.CreateSheet("Devices", "sheets"); err == nil {
+		t.Error("CreateSheet created a second Devices sheet")
+	}
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "Devices!L3",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"device-1"}),
+	}, "sheets")
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+	row, err := spreadsheet.Reader.FindFirstRow(ReadSpecificRangeParams{SpreadsheetId: "sheets"}, "device-1")
+	if err != nil || row != 3 {
+		t.Errorf("FindFirstRow = %d, %v, want 3", row, err)
+	}
+
+	allSheets, err := spreadsheet.Reader.GetAllSheets("sheets")
+	if err != nil {
+		t.Fatalf("GetAllSheets returned %v", err)
+	}
+	err = spreadsheet.Writer.CopySingleSheet(CopySingleSheetParam{FromSpreadsheetId: "sheets", SingleSheet: allSheets[0], ToSpreadsheetId: "copy"})
+	if err != nil {
+		t.Fatalf("CopySingleSheet returned %v", err)
+	}
+	copied, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "copy", ReadRange: "Devices!L3"})
+	if err != nil || !reflect.DeepEqual(copied, rows([]interface{}{"device-1"})) {
+		t.Errorf("Get of the copy = %v, %v", copied, err)
+	}
+
+	err = spreadsheet.Writer.DeleteSheet(DeleteSheetParams{SpreadsheetID: "sheets", SheetTitle: "Devices"})
+	if err != nil {
+		t.Fatalf("DeleteSheet returned %v", err)
+	}
+	names, err := spreadsheet.Reader.GetSheets("sheets")
+	if err != nil || !reflect.DeepEqual(names, []string{"History"}) {
+		t.Errorf("GetSheets = %v, %v, want [History]", names, err)
+	}
+}
+
+func TestLocalSpreadsheetDuplicate(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	_, err := spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "SignUp!A1",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"name", "email"}),
+	}, "template")
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+
+	result, err := spreadsheet.Writer.DuplicateSpreadsheet(DuplicateSpreadsheetParams{
+		SourceSpreadsheetId:   "template",
+		TargetSpreadsheetName: "Copy",
+		TargetSheetName:       "SignUp",
+	})
+	if err != nil {
+		t.Fatalf("DuplicateSpreadsheet returned %v", err)
+	}
+
+	got, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: result.SpreadsheetId, ReadRange: "SignUp!A1:B1"})
+	if err != nil || !reflect.DeepEqual(got, rows([]interface{}{"name", "email"})) {
+		t.Errorf("Get of the duplicate = %v, %v", got, err)
+	}
+}
+
+func TestLocalSpreadsheetMissing(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	tests := []struct {
+		name string
+		read func() error
+		want error
+	}{
+		{
+			name: "missing spreadsheet",
+			read: func() error {
+				_, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "missing", ReadRange: "A1"})
+				return err
+			},
+			want: ErrLocalSpreadsheetNotFound,
+		},
+		{
+			name: "missing sheet",
+			read: func() error {
+				if err := spreadsheet.Writer.CreateSheet("Sheet1", "present"); err != nil {
+					return err
+				}
+				_, err := spreadsheet.Reader.Get(ReadSpecificRangeParams{SpreadsheetId: "present", ReadRange: "Other!A1"})
+				return err
+			},
+			want: ErrLocalSheetNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.read(); !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+
+	if _, err := spreadsheet.Reader.GetAllSheets("../escape"); err == nil {
+		t.Error("GetAllSheets accepted a spreadsheet id outside of the store")
+	}
+}
+
+func TestLocalFiles(t *testing.T) {
+	spreadsheet := newTestLocalSpreadsheet(t)
+
+	spreadsheetId, err := spreadsheet.Files.ImportSpreadsheet("todo-1.xlsx", "folder", strings.NewReader("template"))
+	if err != nil {
+		t.Fatalf("ImportSpreadsheet returned %v", err)
+	}
+	_, err = spreadsheet.Writer.UpdateRange(WriteRangeParams{
+		Range:     "Tasks!A1",
+		Dimension: "ROWS",
+		Rows:      rows([]interface{}{"task", "<done & checked>"}),
+	}, spreadsheetId)
+	if err != nil {
+		t.Fatalf("UpdateRange returned %v", err)
+	}
+
+	var export bytes.Buffer
+	if err = spreadsheet.Files.ExportSpreadsheet(spreadsheetId, &export); err != nil {
+		t.Fatalf("ExportSpreadsheet returned %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(export.Bytes()), int64(export.Len()))
+	if err != nil {
+		t.Fatalf("ExportSpreadsheet wrote an invalid xlsx: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		data, err := readZipFile(file)
+		if err != nil {
+			t.Fatalf("reading %s returned %v", file.Name, err)
+		}
+		parts[file.Name] = string(data)
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Tasks"`) || !strings.Contains(parts["xl/worksheets/sheet1.xml"], "&lt;done &amp; checked&gt;") {
+		t.Errorf("ExportSpreadsheet = %v, want the Tasks sheet", parts)
+	}
+	if err = spreadsheet.Files.ExportSpreadsheet("missing", &export); !errors.Is(err, ErrLocalSpreadsheetNotFound) {
+		t.Errorf("ExportSpreadsheet of a missing spreadsheet returned %v", err)
+	}
+
+	uploads := []struct {
+		name     string
+		folderId string
+		path     string
+	}{
+		{"backup.tar.gz", "backups", "files/backups/backup.tar.gz"},
+		{"backup.tar.gz", "", "files/backup.tar.gz"},
+	}
+	for _, upload := range uploads {
+		if err = spreadsheet.Files.Upload(upload.name, upload.folderId, strings.NewReader("dump")); err != nil {
+			t.Fatalf("Upload(%q, %q) returned %v", upload.name, upload.folderId, err)
+		}
+		data, err := os.ReadFile(filepath.Join(spreadsheet.Files.(*LocalFiles).store.root, upload.path))
+		if err != nil || string(data) != "dump" {
+			t.Errorf("Upload(%q, %q) stored %q, %v", upload.name, upload.folderId, data, err)
+		}
+	}
+	if err = spreadsheet.Files.Upload("backup", "../escape", strings.NewReader("dump")); err == nil {
+		t.Error("Upload accepted a folder outside of the store")
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"google.golang.org/api/sheets/v4"
 )
 
-type Reader struct {
+type GoogleReader struct {
 	sheetsService *sheets.Service
 }
 
+var _ SpreadsheetReader = (*GoogleReader)(nil)
+
 // / ReadSpecificRangeParams is the params for reading a specific range of a spreadsheet
 // / SpreadsheetId is the id of the spreadsheet
 // / ReadRange is the range to read. Eg, "Sheet1!A1:B2"
@@ -23,7 +25,7 @@ type ReadSpecificRangeParams struct {
 // / ReadSpecificRange reads a specific range of a spreadsheet
 // / params is the params for reading a specific range of a spreadsheet
 // / returns the rows of the spreadsheet
-func (receiver Reader) Get(params ReadSpecificRangeParams) ([][]interface{}, error) {
+func (receiver GoogleReader) Get(params ReadSpecificRangeParams) ([][]interface{}, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Reading sheet " + params.SpreadsheetId + " " + params.ReadRange)
 	resp, err := receiver.sheetsService.Spreadsheets.Values.Get(params.SpreadsheetId, params.ReadRange).
 		ValueRenderOption("FORMATTED_VALUE").
@@ -44,7 +46,7 @@ func (receiver Reader) Get(params ReadSpecificRangeParams) ([][]interface{}, err
 // / ReadSpecificRange reads a specific range of a spreadsheet
 // / params is the params for reading a specific range of a spreadsheet
 // / returns the rows of the spreadsheet
-func (receiver Reader) GetFirstRow(params ReadSpecificRangeParams) ([][]interface{}, error) {
+func (receiver GoogleReader) GetFirstRow(params ReadSpecificRangeParams) ([][]interface{}, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]GetFirstRow " + params.SpreadsheetId + " " + params.ReadRange)
 	resp, err := receiver.sheetsService.Spreadsheets.Values.Get(params.SpreadsheetId, params.ReadRange).
 		MajorDimension("COLUMNS").
@@ -63,7 +65,7 @@ func (receiver Reader) GetFirstRow(params ReadSpecificRangeParams) ([][]interfac
 	}
 }
 
-func (receiver Reader) FindFirstRow(params ReadSpecificRangeParams, deviceID string) (int, error) {
+func (receiver GoogleReader) FindFirstRow(params ReadSpecificRangeParams, deviceID string) (int, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]FindFirstRow " + params.SpreadsheetId + " " + params.ReadRange)
 	resp, err := receiver.sheetsService.Spreadsheets.Values.Update(params.SpreadsheetId, params.ReadRange, &sheets.ValueRange{
 		MajorDimension: "ROWS",
@@ -97,7 +99,7 @@ func (receiver Reader) FindFirstRow(params ReadSpecificRangeParams, deviceID str
 	return rowNo, err
 }
 
-func (receiver Reader) GetSheets(spreadsheetId string) ([]string, error) {
+func (receiver GoogleReader) GetSheets(spreadsheetId string) ([]string, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]GetSheets " + spreadsheetId)
 	resp, err := receiver.sheetsService.Spreadsheets.Get(spreadsheetId).Do()
 	if err != nil {
@@ -118,7 +120,7 @@ type SingleSheet struct {
 	Title string
 }
 
-func (receiver Reader) GetAllSheets(spreadsheetId string) ([]SingleSheet, error) {
+func (receiver GoogleReader) GetAllSheets(spreadsheetId string) ([]SingleSheet, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]GetSheets " + spreadsheetId)
 	resp, err := receiver.sheetsService.Spreadsheets.Get(spreadsheetId).Do()
 	if err != nil {
@@ -2,6 +2,7 @@ package sheet
 
 import (
 	"context"
+	"errors"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/sheets/v4"
@@ -9,14 +10,46 @@ import (
 	"sen-global-api/config"
 )
 
+// SpreadsheetReader reads values and sheet metadata from a spreadsheet backend.
+// Ranges use A1 notation, eg "Sheet1!A1:B2", "Devices!L:L" or "K12:K1000".
+type SpreadsheetReader interface {
+	Get(params ReadSpecificRangeParams) ([][]interface{}, error)
+	GetFirstRow(params ReadSpecificRangeParams) ([][]interface{}, error)
+	FindFirstRow(params ReadSpecificRangeParams, deviceID string) (int, error)
+	GetSheets(spreadsheetId string) ([]string, error)
+	GetAllSheets(spreadsheetId string) ([]SingleSheet, error)
+}
+
+// SpreadsheetWriter writes values and manages sheets of a spreadsheet backend.
+type SpreadsheetWriter interface {
+	WriteRanges(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error)
+	WriteRangesAsUserEntered(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error)
+	UpdateRange(params WriteRangeParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error)
+	UpdateRanges(spreadsheetId string, params []WriteRangeParams) error
+	CreateSheet(sheetName string, spreadsheetId string) error
+	AppendSheet(params AppendParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error)
+	ClearRange(params ClearRangeParams) (*sheets.ClearValuesResponse, error)
+	CopySingleSheet(params CopySingleSheetParam) error
+	DeleteSheet(params DeleteSheetParams) error
+	DuplicateSpreadsheet(params DuplicateSpreadsheetParams) (DuplicateSpreadsheetResult, error)
+}
+
 type Spreadsheet struct {
-	Reader *Reader
-	Writer *Writer
+	Reader SpreadsheetReader
+	Writer SpreadsheetWriter
+	Files  FileStore
 }
 
 func NewUserSpreadsheet(config config.AppConfig, contex context.Context) (*Spreadsheet, error) {
+	if config.Spreadsheet.Driver == DriverLocal {
+		return NewLocalSpreadsheet(config.Spreadsheet.LocalDirectory)
+	}
+
 	log.Debug(config.Google.UserCredentialsFilePath)
 
+	if config.Google.UserCredentialsFilePath == "" {
+		return nil, errors.New("google.user_credentials_file_path is required with the google spreadsheet driver")
+	}
 	credentialsInByte, err := os.ReadFile(config.Google.UserCredentialsFilePath)
 	if err != nil {
 		return nil, err
@@ -31,16 +64,28 @@ func NewUserSpreadsheet(config config.AppConfig, contex context.Context) (*Sprea
 	if err != nil {
 		return nil, err
 	}
+	files, err := newGoogleFiles(config.Google.UserCredentialsFilePath, contex)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Spreadsheet{
-		Reader: &Reader{sheetsService: sheetsService},
-		Writer: &Writer{sheetsService: sheetsService},
+		Reader: &GoogleReader{sheetsService: sheetsService},
+		Writer: &GoogleWriter{sheetsService: sheetsService},
+		Files:  files,
 	}, nil
 }
 
 func NewUploaderSpreadsheet(config config.AppConfig, contex context.Context) (*Spreadsheet, error) {
+	if config.Spreadsheet.Driver == DriverLocal {
+		return NewLocalSpreadsheet(config.Spreadsheet.LocalDirectory)
+	}
+
 	log.Debug(config.Google.UserCredentialsFilePath)
 
+	if config.Google.UploaderCredentialsFilePath == "" {
+		return nil, errors.New("google.uploader_credentials_file_path is required with the google spreadsheet driver")
+	}
 	credentialsInByte, err := os.ReadFile(config.Google.UploaderCredentialsFilePath)
 	if err != nil {
 		return nil, err
@@ -55,9 +100,14 @@ func NewUploaderSpreadsheet(config config.AppConfig, contex context.Context) (*S
 	if err != nil {
 		return nil, err
 	}
+	files, err := newGoogleFiles(config.Google.UploaderCredentialsFilePath, contex)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Spreadsheet{
-		Reader: &Reader{sheetsService: sheetsService},
-		Writer: &Writer{sheetsService: sheetsService},
+		Reader: &GoogleReader{sheetsService: sheetsService},
+		Writer: &GoogleWriter{sheetsService: sheetsService},
+		Files:  files,
 	}, nil
 }
@@ -7,10 +7,12 @@ import (
 	"google.golang.org/api/sheets/v4"
 )
 
-type Writer struct {
+type GoogleWriter struct {
 	sheetsService *sheets.Service
 }
 
+var _ SpreadsheetWriter = (*GoogleWriter)(nil)
+
 type WriteRangeParams struct {
 	Range     string
 	Dimension string
@@ -28,7 +30,7 @@ type AppendParams struct {
 	Rows      [][]interface{}
 }
 
-func (receiver Writer) WriteRanges(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
+func (receiver GoogleWriter) WriteRanges(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Writing sheet " + spreadsheetId + " - Append at range " + params.Range)
 	var updateValues = &sheets.ValueRange{
 		MajorDimension: params.Dimension,
@@ -46,7 +48,7 @@ func (receiver Writer) WriteRanges(params WriteRangeParams, spreadsheetId string
 	return resp, nil
 }
 
-func (receiver Writer) WriteRangesAsUserEntered(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
+func (receiver GoogleWriter) WriteRangesAsUserEntered(params WriteRangeParams, spreadsheetId string) (*sheets.AppendValuesResponse, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Writing sheet " + spreadsheetId + " - Append at range " + params.Range)
 	var updateValues = &sheets.ValueRange{
 		MajorDimension: params.Dimension,
@@ -64,7 +66,7 @@ func (receiver Writer) WriteRangesAsUserEntered(params WriteRangeParams, spreads
 	return resp, nil
 }
 
-func (receiver Writer) UpdateRange(params WriteRangeParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
+func (receiver GoogleWriter) UpdateRange(params WriteRangeParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Writing sheet " + spreadsheetId + " - Update at range " + params.Range)
 	var updateValues = &sheets.ValueRange{
 		MajorDimension: params.Dimension,
@@ -82,7 +84,7 @@ func (receiver Writer) UpdateRange(params WriteRangeParams, spreadsheetId string
 	return resp, nil
 }
 
-func (receiver Writer) UpdateRanges(spreadsheetId string, params []WriteRangeParams) error {
+func (receiver GoogleWriter) UpdateRanges(spreadsheetId string, params []WriteRangeParams) error {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Writing sheet " + spreadsheetId + " - Update at ranges ")
 	rbb := &sheets.BatchUpdateSpreadsheetRequest{}
 	//for _, p := range params {
@@ -118,7 +120,7 @@ func (receiver Writer) UpdateRanges(spreadsheetId string, params []WriteRangePar
 	return err
 }
 
-func (receiver Writer) CreateSheet(sheetName string, spreadsheetId string) error {
+func (receiver GoogleWriter) CreateSheet(sheetName string, spreadsheetId string) error {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Creating sheet " + spreadsheetId + " - sheet name " + sheetName)
 	req := sheets.Request{
 		AddSheet: &sheets.AddSheetRequest{
@@ -142,7 +144,7 @@ func (receiver Writer) CreateSheet(sheetName string, spreadsheetId string) error
 	return nil
 }
 
-func (receiver Writer) AppendSheet(params AppendParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
+func (receiver GoogleWriter) AppendSheet(params AppendParams, spreadsheetId string) (*sheets.UpdateValuesResponse, error) {
 	//monitor.SendMessageViaTelegram("[GOOGLE API]Writing sheet " + spreadsheetId + " - Append at sheet " + params.SheetName)
 	log.Debug("AppendSheet: ", params)
 	log.Debug("AppendSheet: ", spreadsheetId)
@@ -162,7 +164,7 @@ func (receiver Writer) AppendSheet(params AppendParams, spreadsheetId string) (*
 	return resp.Updates, nil
 }
 
-func (receiver Writer) ClearRange(params ClearRangeParams) (*sheets.ClearValuesResponse, error) {
+func (receiver GoogleWriter) ClearRange(params ClearRangeParams) (*sheets.ClearValuesResponse, error) {
 	log.Debug("ClearRange: ", params)
 	resp, err := receiver.sheetsService.Spreadsheets.Values.
 		Clear(params.SpreadsheetId, params.Range, &sheets.ClearValuesRequest{}).
@@ -183,7 +185,7 @@ type CopySingleSheetParam struct {
 	ToSpreadsheetId   string
 }
 
-func (receiver Writer) CopySingleSheet(params CopySingleSheetParam) error {
+func (receiver GoogleWriter) CopySingleSheet(params CopySingleSheetParam) error {
 	copyRequest := &sheets.CopySheetToAnotherSpreadsheetRequest{
 		DestinationSpreadsheetId: params.ToSpreadsheetId,
 	}
@@ -228,7 +230,7 @@ type DeleteSheetParams struct {
 	SheetTitle    string
 }
 
-func (receiver Writer) DeleteSheet(params DeleteSheetParams) error {
+func (receiver GoogleWriter) DeleteSheet(params DeleteSheetParams) error {
 	resp, err := receiver.sheetsService.Spreadsheets.Get(params.SpreadsheetID).Do()
 	if err != nil {
 		log.Error("Unable to retrieve data from sheet:", err)
@@ -271,7 +273,7 @@ type DuplicateSpreadsheetResult struct {
 	SpreadsheetId string
 }
 
-func (receiver Writer) DuplicateSpreadsheet(params DuplicateSpreadsheetParams) (DuplicateSpreadsheetResult, error) {
+func (receiver GoogleWriter) DuplicateSpreadsheet(params DuplicateSpreadsheetParams) (DuplicateSpreadsheetResult, error) {
 	ctx := context.Background()
 	resp, err := receiver.sheetsService.Spreadsheets.
 		Get(params.SourceSpreadsheetId).
@@ -0,0 +1,233 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const xlsxMaxSheetNameLength = 31
+
+// / xlsxTemplateParts are copied from the template so an export keeps its look
+var xlsxTemplateParts = []string{"xl/styles.xml", "xl/theme/theme1.xml"}
+
+var xlsxFirstStringCellPattern = regexp.MustCompile(`<c r="[A-Z]+[0-9]+" s="([0-9]+)" t="s">`)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>%s</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>%s</Relationships>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// / XLSXWriter streams a workbook with a single sheet. The styles and theme come
+// / from a template workbook such as config/output_template.xlsx, the header row
+// / takes the style of the first header cell of the template.
+type XLSXWriter struct {
+	archive     *zip.Writer
+	sheet       *bufio.Writer
+	headerStyle int
+	rows        int
+}
+
+// / NewXLSXWriter starts a workbook on w, without a templatePath the workbook
+// / has no styles.
+func NewXLSXWriter(w io.Writer, templatePath string, sheetName string) (*XLSXWriter, error) {
+	parts, err := readXLSXTemplate(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &XLSXWriter{archive: zip.NewWriter(w)}
+	if match := xlsxFirstStringCellPattern.FindSubmatch(parts["xl/worksheets/sheet1.xml"]); match != nil {
+		writer.headerStyle, _ = strconv.Atoi(string(match[1]))
+	}
+
+	overrides := ""
+	relationships := ""
+	if _, ok := parts["xl/styles.xml"]; ok {
+		overrides += `<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+		relationships += `<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`
+	} else {
+		writer.headerStyle = 0
+	}
+	if _, ok := parts["xl/theme/theme1.xml"]; ok {
+		overrides += `<Override PartName="/xl/theme/theme1.xml" ContentType="application/vnd.openxmlformats-officedocument.theme+xml"/>`
+		relationships += `<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme" Target="theme/theme1.xml"/>`
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(fmt.Sprintf(xlsxContentTypes, overrides))},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(xlsxWorkbook, escapeXML(xlsxSheetName(sheetName))))},
+		{"xl/_rels/workbook.xml.rels", []byte(fmt.Sprintf(xlsxWorkbookRels, relationships))},
+	}
+	for _, name := range xlsxTemplateParts {
+		if data, ok := parts[name]; ok {
+			files = append(files, struct {
+				name string
+				data []byte
+			}{name, data})
+		}
+	}
+
+	for _, file := range files {
+		part, err := writer.archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = part.Write(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := writer.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	writer.sheet = bufio.NewWriter(sheet)
+	if _, err = writer.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func readXLSXTemplate(templatePath string) (map[string][]byte, error) {
+	parts := make(map[string][]byte)
+	if templatePath == "" {
+		return parts, nil
+	}
+
+	template, err := zip.OpenReader(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	defer template.Close()
+
+	for _, file := range template.File {
+		if !containsString(xlsxTemplateParts, file.Name) && file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		parts[file.Name] = data
+	}
+
+	return parts, nil
+}
+
+// / WriteHeader writes a row in the header style of the template
+func (receiver *XLSXWriter) WriteHeader(cells []string) error {
+	return receiver.writeRow(cells, receiver.headerStyle)
+}
+
+func (receiver *XLSXWriter) WriteRow(cells []string) error {
+	return receiver.writeRow(cells, 0)
+}
+
+// / Flush pushes the buffered rows to the underlying writer
+func (receiver *XLSXWriter) Flush() error {
+	if err := receiver.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return receiver.archive.Flush()
+}
+
+// / Close finishes the sheet and the archive, it does not close the underlying writer
+func (receiver *XLSXWriter) Close() error {
+	if _, err := receiver.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := receiver.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return receiver.archive.Close()
+}
+
+func (receiver *XLSXWriter) writeRow(cells []string, style int) error {
+	receiver.rows++
+	row := strconv.Itoa(receiver.rows)
+
+	var builder strings.Builder
+	builder.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		builder.WriteString(`<c r="` + columnName(i) + row + `" t="inlineStr"`)
+		if style != 0 {
+			builder.WriteString(` s="` + strconv.Itoa(style) + `"`)
+		}
+		builder.WriteString(`><is><t xml:space="preserve">`)
+		builder.WriteString(escapeXML(cell))
+		builder.WriteString(`</t></is></c>`)
+	}
+	builder.WriteString(`</row>`)
+
+	_, err := receiver.sheet.WriteString(builder.String())
+	return err
+}
+
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > xlsxMaxSheetNameLength {
+		name = string(runes[:xlsxMaxSheetNameLength])
+	}
+
+	return name
+}
+
+func escapeXML(s string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(s))
+
+	return builder.String()
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}